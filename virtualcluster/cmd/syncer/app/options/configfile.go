@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/pflag"
+	cliflag "k8s.io/component-base/cli/flag"
+
+	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	syncerconfigscheme "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/scheme"
+	syncerconfigv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/v1alpha1"
+)
+
+// newDefaultComponentConfig returns the syncer configuration of an empty config file, i.e. the
+// defaults of the versioned SyncerConfiguration converted to the internal one.
+func newDefaultComponentConfig() (syncerconfig.SyncerConfiguration, error) {
+	versioned := &syncerconfigv1alpha1.SyncerConfiguration{}
+	syncerconfigscheme.Scheme.Default(versioned)
+	config := syncerconfig.SyncerConfiguration{}
+	if err := syncerconfigscheme.Scheme.Convert(versioned, &config, nil); err != nil {
+		return config, fmt.Errorf("failed to convert the default syncer config: %v", err)
+	}
+	return config, nil
+}
+
+// loadConfigFile loads the ComponentConfig from the YAML or JSON file at path, a serialized
+// versioned SyncerConfiguration of the syncer config scheme, defaulted and converted to the
+// internal one. Its apiVersion and kind default to the ones of the v1alpha1 SyncerConfiguration if
+// missing. The values take precedence in the order defaults < file < flags: the fields missing in
+// the file get their default values, and the flags set on the command line override the values of
+// the file. Unknown fields are rejected, so a misspelled key is not silently ignored.
+func (o *ResourceSyncerOptions) loadConfigFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %q: %v", path, err)
+	}
+	defaultGVK := syncerconfigv1alpha1.SchemeGroupVersion.WithKind("SyncerConfiguration")
+	obj, gvk, err := syncerconfigscheme.Codecs.UniversalDecoder().Decode(data, &defaultGVK, nil)
+	if err != nil {
+		return fmt.Errorf("failed to decode config file %q: %v", path, err)
+	}
+	config, ok := obj.(*syncerconfig.SyncerConfiguration)
+	if !ok {
+		return fmt.Errorf("failed to decode config file %q: unexpected kind %v", path, gvk)
+	}
+	fileOptions, err := NewResourceSyncerOptions()
+	if err != nil {
+		return err
+	}
+	fileOptions.ComponentConfig = *config
+
+	// Set the flags of the command line again, bound to the config of the file. The flags are parsed
+	// by the command flag set, so the named flag sets only know they changed from the flags themselves.
+	fileFlagSets := fileOptions.Flags()
+	for name, fs := range o.flagSets.FlagSets {
+		fileFlagSet, ok := fileFlagSets.FlagSets[name]
+		if !ok {
+			continue
+		}
+		fs.VisitAll(func(f *pflag.Flag) {
+			fileFlag := fileFlagSet.Lookup(f.Name)
+			if err != nil || !f.Changed || fileFlag == nil {
+				return
+			}
+			if setErr := copyFlagValue(fileFlag.Value, f.Value); setErr != nil {
+				err = fmt.Errorf("failed to override config file %q with flag --%s: %v", path, f.Name, setErr)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// The dns-options and dns-option flags are not bound to the ComponentConfig, Config converts them if
+	// the file does not set the DNS options.
+	if o.flagChanged("dns-options") || o.flagChanged("dns-option") {
+		fileOptions.ComponentConfig.DNSOptions = nil
+	}
+
+	o.ComponentConfig = fileOptions.ComponentConfig
+	return nil
+}
+
+// flagChanged returns whether the flag name is set on the command line.
+func (o *ResourceSyncerOptions) flagChanged(name string) bool {
+	for _, fs := range o.flagSets.FlagSets {
+		if f := fs.Lookup(name); f != nil && f.Changed {
+			return true
+		}
+	}
+	return false
+}
+
+// copyFlagValue sets dst to the value of src. The list and map values replace the ones of dst, setting
+// them from their string form would append to or merge with them instead.
+func copyFlagValue(dst, src pflag.Value) error {
+	switch v := src.(type) {
+	case pflag.SliceValue:
+		if d, ok := dst.(pflag.SliceValue); ok {
+			return d.Replace(v.GetSlice())
+		}
+	case *cliflag.MapStringString:
+		if d, ok := dst.(*cliflag.MapStringString); ok {
+			m := make(map[string]string, len(*v.Map))
+			for k, val := range *v.Map {
+				m[k] = val
+			}
+			*d.Map = m
+			return nil
+		}
+	case *cliflag.MapStringBool:
+		if d, ok := dst.(*cliflag.MapStringBool); ok {
+			m := make(map[string]bool, len(*v.Map))
+			for k, val := range *v.Map {
+				m[k] = val
+			}
+			*d.Map = m
+			return nil
+		}
+	}
+	return dst.Set(src.String())
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -27,7 +28,6 @@ import (
 
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
@@ -40,6 +40,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	cliflag "k8s.io/component-base/cli/flag"
 	componentbaseconfig "k8s.io/component-base/config"
+	"k8s.io/component-base/logs"
 	"k8s.io/klog/v2"
 
 	syncerappconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/cmd/syncer/app/config"
@@ -47,14 +48,20 @@ import (
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions"
 	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/tracing"
+	syncerutil "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 )
 
 // ResourceSyncerOptions is the main context object for the resource syncer.
 type ResourceSyncerOptions struct {
 	// The syncer configuration.
 	ComponentConfig syncerconfig.SyncerConfiguration
+	// ConfigFile is the path of a serialized SyncerConfiguration the ComponentConfig is loaded from.
+	// The flags set on the command line override the values of the file.
+	ConfigFile string
 
 	MetaClusterAddress string
 	// MetaClusterClientConnection specifies the kubeconfig file and client connection
@@ -69,46 +76,38 @@ type ResourceSyncerOptions struct {
 	CertFile            string
 	KeyFile             string
 	DNSOptions          map[string]string
+	// DNSOptionList are the DNS options of the repeated dns-option flag, in order. If set, they
+	// replace DNSOptions.
+	DNSOptionList []string
+
+	// Logs are the log format options, applied before the clients and the controllers are created.
+	Logs *logs.Options
+
+	// SelfTestConversion runs the conversion round-trip self test and exits instead of starting the syncer.
+	SelfTestConversion bool
+
+	// flagSets are the flags bound to the options, used to tell the flags set on the command line
+	// from the values of the config file.
+	flagSets cliflag.NamedFlagSets
 }
 
 // NewResourceSyncerOptions creates a new resource syncer with a default config.
 func NewResourceSyncerOptions() (*ResourceSyncerOptions, error) {
+	componentConfig, err := newDefaultComponentConfig()
+	if err != nil {
+		return nil, err
+	}
 	return &ResourceSyncerOptions{
-		ComponentConfig: syncerconfig.SyncerConfiguration{
-			LeaderElection: syncerconfig.SyncerLeaderElectionConfiguration{
-				LeaderElectionConfiguration: componentbaseconfig.LeaderElectionConfiguration{
-					LeaderElect:   true,
-					LeaseDuration: metav1.Duration{Duration: 15 * time.Second},
-					RenewDeadline: metav1.Duration{Duration: 10 * time.Second},
-					RetryPeriod:   metav1.Duration{Duration: 2 * time.Second},
-					ResourceLock:  resourcelock.ConfigMapsResourceLock,
-				},
-				LockObjectName: "syncer-leaderelection-lock",
-			},
-			ClientConnection:           componentbaseconfig.ClientConnectionConfiguration{},
-			Timeout:                    "",
-			DisableServiceAccountToken: true,
-			DefaultOpaqueMetaDomains:   []string{"kubernetes.io", "k8s.io"},
-			ExtraSyncingResources:      []string{},
-			ExtraNodeLabels:            []string{},
-			OpaqueTaintKeys:            []string{},
-			VNAgentPort:                int32(10550),
-			VNAgentNamespacedName:      "vc-manager/vn-agent",
-			VNAgentLabelSelector:       "app=vn-agent",
-			FeatureGates: map[string]bool{
-				featuregate.SuperClusterPooling:        false,
-				featuregate.SuperClusterServiceNetwork: false,
-				featuregate.VNodeProviderService:       false,
-			},
-		},
-		SyncerName: "vc",
-		Address:    "",
-		Port:       "80",
-		CertFile:   "",
-		KeyFile:    "",
+		ComponentConfig: componentConfig,
+		SyncerName:      "vc",
+		Address:         "",
+		Port:            "80",
+		CertFile:        "",
+		KeyFile:         "",
 		DNSOptions: map[string]string{
 			"ndots": "5",
 		},
+		Logs: logs.NewOptions(),
 	}, nil
 }
 
@@ -116,25 +115,102 @@ func (o *ResourceSyncerOptions) Flags() cliflag.NamedFlagSets {
 	fss := cliflag.NamedFlagSets{}
 
 	fs := fss.FlagSet("server")
+	fs.StringVar(&o.ConfigFile, "config", o.ConfigFile, "The path to a YAML or JSON file with the syncer configuration, a SyncerConfiguration of apiVersion syncer.config.tenancy.x-k8s.io/v1alpha1, e.g. with leaderElection or extraSyncingResources. Unknown keys are rejected. The flags set on the command line override the values of the file.")
 	fs.StringVar(&o.SuperClusterAddress, "super-master", o.SuperClusterAddress, "The address of the super cluster Kubernetes API server (overrides any value in super-master-kubeconfig).")
 	fs.StringVar(&o.ComponentConfig.ClientConnection.Kubeconfig, "super-master-kubeconfig", o.ComponentConfig.ClientConnection.Kubeconfig, "Path to kubeconfig file with authorization and control plane location information.")
 	fs.StringVar(&o.ComponentConfig.Timeout, "super-master-timeout", o.ComponentConfig.Timeout, "Timeout of the super cluster Kubernetes API server, Valid time units are 'ns', 'us' (or 'µs'), 'ms', 's', 'm', 'h'. (overrides any value in super-master-kubeconfig).")
 	fs.StringVar(&o.MetaClusterAddress, "meta-cluster-address", o.MetaClusterAddress, "The address of the meta cluster Kubernetes API server (overrides any value in meta-cluster-kubeconfig).")
 	fs.StringVar(&o.MetaClusterClientConnection.Kubeconfig, "meta-cluster-kubeconfig", o.MetaClusterClientConnection.Kubeconfig, "Path to kubeconfig file of the meta cluster. If it is not provided, the super cluster is used")
+	fs.Float32Var(&o.ComponentConfig.ClientConnection.QPS, "super-master-qps", o.ComponentConfig.ClientConnection.QPS, fmt.Sprintf("QPS of the client of the super cluster Kubernetes API server, 0 means %d.", constants.DefaultSyncerClientQPS))
+	fs.Int32Var(&o.ComponentConfig.ClientConnection.Burst, "super-master-burst", o.ComponentConfig.ClientConnection.Burst, fmt.Sprintf("Burst of the client of the super cluster Kubernetes API server, 0 means %d.", constants.DefaultSyncerClientBurst))
+	fs.Float32Var(&o.MetaClusterClientConnection.QPS, "meta-cluster-qps", o.MetaClusterClientConnection.QPS, fmt.Sprintf("QPS of the client of the meta cluster Kubernetes API server, 0 means %d. It applies to the meta cluster client even if the meta cluster is the super cluster.", constants.DefaultSyncerClientQPS))
+	fs.Int32Var(&o.MetaClusterClientConnection.Burst, "meta-cluster-burst", o.MetaClusterClientConnection.Burst, fmt.Sprintf("Burst of the client of the meta cluster Kubernetes API server, 0 means %d. It applies to the meta cluster client even if the meta cluster is the super cluster.", constants.DefaultSyncerClientBurst))
 	fs.BoolVar(&o.DeployOnMetaCluster, "deployment-on-meta", o.DeployOnMetaCluster, "Whether vc-syncer deploy on meta cluster")
+	fs.BoolVar(&o.ComponentConfig.DryRun, "dry-run", o.ComponentConfig.DryRun, "DryRun sends the creates, updates, patches and deletes of the syncer to the super cluster and the tenant control planes as server-side dry runs (dryRun=All), which are validated and admitted but not persisted, and logs and counts them, e.g. to validate a configuration before it changes a live super cluster.")
+	fs.BoolVar(&o.SelfTestConversion, "selftest-conversion", o.SelfTestConversion, "Round-trip the built-in corpus of tenant objects through the conversion, report the fields that do not survive and exit, non-zero if any field is lost.")
 	fs.StringVar(&o.SyncerName, "syncer-name", o.SyncerName, "Syncer name (default vc).")
+	fs.StringVar(&o.ComponentConfig.FieldManager, "field-manager", o.ComponentConfig.FieldManager, "FieldManager is the field manager name of the objects the syncer writes to the super cluster, e.g. with server-side apply. Defaults to the syncer name.")
 	fs.BoolVar(&o.ComponentConfig.DisableServiceAccountToken, "disable-service-account-token", o.ComponentConfig.DisableServiceAccountToken, "DisableServiceAccountToken indicates whether to disable super cluster service account tokens being auto generated and mounted in vc pods.")
+	fs.BoolVar(&o.ComponentConfig.AllowPodServiceAccountTokenAutomount, "allow-pod-service-account-token-automount", o.ComponentConfig.AllowPodServiceAccountTokenAutomount, "AllowPodServiceAccountTokenAutomount indicates whether vc pods explicitly setting automountServiceAccountToken to true still get the super cluster service account token when disable-service-account-token is set.")
 	fs.BoolVar(&o.ComponentConfig.DisablePodServiceLinks, "disable-service-links", o.ComponentConfig.DisablePodServiceLinks, "DisablePodServiceLinks indicates whether to disable the `EnableServiceLinks` field in pPod spec.")
+	fs.BoolVar(&o.ComponentConfig.PreserveTenantCreationTimestamp, "preserve-tenant-creation-timestamp", o.ComponentConfig.PreserveTenantCreationTimestamp, "PreserveTenantCreationTimestamp indicates whether to record the tenant object's creationTimestamp in an annotation of the synced super cluster object.")
 	fs.StringSliceVar(&o.ComponentConfig.DefaultOpaqueMetaDomains, "default-opaque-meta-domains", o.ComponentConfig.DefaultOpaqueMetaDomains, "DefaultOpaqueMetaDomains is the default opaque meta configuration for each Virtual Cluster.")
-	fs.StringSliceVar(&o.ComponentConfig.ExtraSyncingResources, "extra-syncing-resources", o.ComponentConfig.ExtraSyncingResources, "ExtraSyncingResources defines additional resources that need to be synced for each Virtual Cluster. (priorityclass, ingress, crd)")
+	fs.StringSliceVar(&o.ComponentConfig.DWSAnnotationPassthrough, "dws-annotation-passthrough", o.ComponentConfig.DWSAnnotationPassthrough, "DWSAnnotationPassthrough lists annotation keys passed through from tenant objects to super cluster objects unchanged although they match default-opaque-meta-domains.")
+	fs.StringSliceVar(&o.ComponentConfig.UWSMetadataAllowlist, "uws-metadata-allowlist", o.ComponentConfig.UWSMetadataAllowlist, "UWSMetadataAllowlist lists the label and annotation key prefixes that may be back populated from super cluster objects to tenant objects, in addition to matching the transparent meta prefixes of the VirtualCluster. Empty allows all the transparent keys. The transparency.tenancy.x-k8s.io keys set by the syncer are always allowed.")
+	fs.StringSliceVar(&o.ComponentConfig.ExtraSyncingResources, "extra-syncing-resources", o.ComponentConfig.ExtraSyncingResources, "ExtraSyncingResources defines additional resources that need to be synced for each Virtual Cluster. (priorityclass, ingress, crd, clusterrolebinding, deployment, replicaset) The values are case-insensitive, the syncer does not start with an unknown one. The resources synced by default, e.g. pvc, are accepted as well. An entry may set the direction the resource is synced in as name:direction, down (tenant to super cluster only), up (super cluster back to tenant only) or both (the default), e.g. ingress:down or configmap:both.")
+	fs.BoolVar(&o.ComponentConfig.RequireRBAC, "require-rbac", o.ComponentConfig.RequireRBAC, "RequireRBAC indicates whether the syncer refuses to start when it misses super cluster permissions of the enabled syncers, which are logged at startup either way.")
+	fs.BoolVar(&o.ComponentConfig.PruneOnFeatureDisable, "prune-on-feature-disable", o.ComponentConfig.PruneOnFeatureDisable, "PruneOnFeatureDisable indicates whether to delete, at startup, the super cluster objects synced by the extra syncing resources that are not enabled anymore.")
 	fs.Var(cliflag.NewMapStringBool(&o.ComponentConfig.FeatureGates), "feature-gates", "A set of key=value pairs that describe feature gates for various features."+
 		"Options are:\n"+strings.Join(featuregate.DefaultFeatureGate.KnownFeatures(), "\n"))
-	fs.StringSliceVar(&o.ComponentConfig.ExtraNodeLabels, "extra-node-labels", o.ComponentConfig.ExtraNodeLabels, "ExtraNodeLabels defines additional node labels that need to be synced for each Virtual Cluster")
+	fs.StringSliceVar(&o.ComponentConfig.ExtraNodeLabels, "extra-node-labels", o.ComponentConfig.ExtraNodeLabels, "ExtraNodeLabels defines additional node labels that need to be synced for each Virtual Cluster. An entry is a label key, a glob of label keys such as node.example.com/*, or key=value to set the label to value on every vNode")
 	fs.StringSliceVar(&o.ComponentConfig.OpaqueTaintKeys, "opaque-taint-keys", o.ComponentConfig.OpaqueTaintKeys, "OpaqueTaintKeys defines taint keys that need to be synced for each Virtual Cluster")
-	fs.Int32Var(&o.ComponentConfig.VNAgentPort, "vn-agent-port", 10550, "Port the vn-agent listens on")
-	fs.StringVar(&o.ComponentConfig.VNAgentNamespacedName, "vn-agent-namespace-name", "vc-manager/vn-agent", "Namespace/Name of the vn-agent running in cluster, used for VNodeProviderService")
-	fs.Var(cliflag.NewMapStringString(&o.DNSOptions), "dns-options", "DNSOptions is the default DNS options attached to each pod")
-	fs.StringVar(&o.ComponentConfig.VNAgentLabelSelector, "vn-agent-label-selector", "app=vn-agent", "Label key=value of the vn-agent running in cluster, used for VNodeProviderPodIP")
+	fs.Var(cliflag.NewMapStringString(&o.ComponentConfig.VirtualClusterLabelMapping), "vc-label-mapping", "VirtualClusterLabelMapping is a set of vcLabelKey=superLabelKey pairs. The VirtualCluster label values are copied onto every synced super cluster object under the super label key (an empty super label key reuses the VirtualCluster key).")
+	fs.Int32Var(&o.ComponentConfig.MaxContainersPerPod, "max-containers-per-pod", o.ComponentConfig.MaxContainersPerPod, "MaxContainersPerPod is the maximum number of regular, init and ephemeral containers of a tenant pod to be synced, 0 means no limit. It can be overridden by the tenancy.x-k8s.io/max-containers-per-pod annotation of a VirtualCluster.")
+	fs.Int64Var(&o.ComponentConfig.MaxPodCommandBytes, "max-pod-command-bytes", o.ComponentConfig.MaxPodCommandBytes, "MaxPodCommandBytes is the maximum total size in bytes of the command, args and env of the containers of a tenant pod to be synced, 0 means no limit. It can be overridden by the tenancy.x-k8s.io/max-pod-command-bytes annotation of a VirtualCluster.")
+	fs.BoolVar(&o.ComponentConfig.ProvisionSuperNamespaceQuota, "provision-super-namespace-quota", o.ComponentConfig.ProvisionSuperNamespaceQuota, "ProvisionSuperNamespaceQuota indicates whether to provision a ResourceQuota, managed by the syncer, in each synced super cluster namespace.")
+	fs.Var(cliflag.NewMapStringString(&o.ComponentConfig.SuperNamespaceQuota), "super-namespace-quota", "SuperNamespaceQuota is a set of resource=quantity hard limits of the provisioned super cluster namespace ResourceQuotas, e.g. pods=50,requests.cpu=10. It can be overridden by the tenancy.x-k8s.io/super-namespace-quota annotation of a VirtualCluster.")
+	fs.BoolVar(&o.ComponentConfig.ForcePodNonPreempting, "force-pod-non-preempting", o.ComponentConfig.ForcePodNonPreempting, "ForcePodNonPreempting indicates whether to set the preemptionPolicy of all synced pods to Never, so that tenant pods never preempt other pods in the super cluster.")
+	fs.Var(cliflag.NewMapStringString(&o.ComponentConfig.QoSToPriorityClass), "qos-to-priority-class", "QoSToPriorityClass maps the QoS classes of tenant pods to the super cluster priority classes of the synced pods, e.g. Guaranteed=high-priority,BestEffort=low-priority. The tenant pods of the other QoS classes keep their priority class.")
+	fs.BoolVar(&o.ComponentConfig.DisableEphemeralContainersSync, "disable-ephemeral-containers-sync", o.ComponentConfig.DisableEphemeralContainersSync, "DisableEphemeralContainersSync indicates whether to stop adding the ephemeral containers of the tenant pods to the super pods.")
+	fs.Int64Var(&o.ComponentConfig.DefaultNotReadyTolerationSeconds, "default-not-ready-toleration-seconds", o.ComponentConfig.DefaultNotReadyTolerationSeconds, "DefaultNotReadyTolerationSeconds is the tolerationSeconds of the notReady:NoExecute toleration added to every synced pod that does not already have such a toleration, 0 leaves it to the super cluster.")
+	fs.Int64Var(&o.ComponentConfig.DefaultUnreachableTolerationSeconds, "default-unreachable-toleration-seconds", o.ComponentConfig.DefaultUnreachableTolerationSeconds, "DefaultUnreachableTolerationSeconds is the tolerationSeconds of the unreachable:NoExecute toleration added to every synced pod that does not already have such a toleration, 0 leaves it to the super cluster.")
+	fs.StringVar(&o.ComponentConfig.UnsupportedProbePolicy, "unsupported-probe-policy", o.ComponentConfig.UnsupportedProbePolicy, "UnsupportedProbePolicy is what happens to pods with probes of a type the syncer does not support, such as grpc: reject (leave the pod unsynced) or drop (sync the pod without these probes).")
+	fs.StringVar(&o.ComponentConfig.StartupProbePolicy, "startup-probe-policy", o.ComponentConfig.StartupProbePolicy, "StartupProbePolicy is how the startup probes of pods are synced: keep (sync them unchanged) or fold (remove them and delay the liveness probe of their container by initialDelaySeconds + failureThreshold * periodSeconds of the startup probe, for super clusters not supporting startup probes).")
+	fs.StringVar(&o.ComponentConfig.OnNameTooLong, "on-name-too-long", o.ComponentConfig.OnNameTooLong, "OnNameTooLong is what happens when a super control plane name derived from the tenant, such as a namespace name, exceeds its length limit: hash (shorten the name with a hash suffix) or fail (leave the object unsynced).")
+	fs.StringVar(&o.ComponentConfig.OnVCReadoption, "on-vc-readoption", o.ComponentConfig.OnVCReadoption, "OnVCReadoption is what happens to a super cluster namespace left by a deleted virtual cluster when a virtual cluster with the same cluster key syncs the tenant namespace again: conflict (leave it and fail the sync), recreate (delete and recreate it) or adopt (re-stamp it and its objects to the new virtual cluster, keeping them).")
+	fs.StringVar(&o.ComponentConfig.ExistenceDisagreementPolicy, "existence-disagreement-policy", o.ComponentConfig.ExistenceDisagreementPolicy, "ExistenceDisagreementPolicy is what the periodic checkers do when an object is missing from the informer cache of the side authoritative for its existence but has a copy on the other side: confirm (read the object from the authoritative apiserver and delete the copy only if it is missing) or trust-cache (delete the copy right away).")
+	fs.StringVar(&o.ComponentConfig.OnSuperObjectDeleted, "on-super-object-deleted", o.ComponentConfig.OnSuperObjectDeleted, "OnSuperObjectDeleted is what happens when the super pod of a tenant pod is deleted out of band: recreate (recreate it if the tenant pod is not scheduled yet) or propagate (delete the tenant pod). Scheduled tenant pods are deleted either way.")
+	fs.StringSliceVar(&o.ComponentConfig.PodMutatorOrder, "pod-mutator-order", o.ComponentConfig.PodMutatorOrder, "PodMutatorOrder is the order of the pod mutation pipeline, pod mutator plugin IDs and PodMutateDefault for the default conversion. The listed mutators run first, followed by the other mutator plugins in the order of their IDs and the default conversion.")
+	fs.StringSliceVar(&o.ComponentConfig.DisabledPodMutators, "disabled-pod-mutators", o.ComponentConfig.DisabledPodMutators, "DisabledPodMutators lists the pod mutator plugin IDs left out of the pod mutation pipeline. They can be enabled for a VirtualCluster by its tenancy.x-k8s.io/enabled-pod-mutators annotation.")
+	fs.Var(cliflag.NewMapStringStringNoSplit(&o.ComponentConfig.InformerFieldSelectors), "informer-field-selector", fmt.Sprintf("InformerFieldSelectors is a resource=selector field selector of the super cluster informer of a resource, e.g. event=type!=Normal. It can be repeated, once per resource. Supported resources are %s, whose super cluster objects the syncer never checks for existence.", strings.Join(syncerutil.FieldSelectorResources(), ", ")))
+	fs.StringSliceVar(&o.ComponentConfig.UncachedResources, "uncached-resources", o.ComponentConfig.UncachedResources, fmt.Sprintf("UncachedResources lists the resources whose syncers get and list the super cluster objects from the apiserver, page by page, instead of caching them in an informer, trading latency for memory. Supported resources are %s.", strings.Join(syncerutil.UncachedResources(), ", ")))
+	fs.StringVar(&o.ComponentConfig.OnClusterScopedConflict, "on-cluster-scoped-conflict", o.ComponentConfig.OnClusterScopedConflict, "OnClusterScopedConflict is what happens when a tenant control plane has a cluster scoped object, such as a priorityclass or a storageclass, with the name of a super control plane public object but not synced from it: adopt (overwrite it and mark it as synced), skip (leave it untouched) or fail (leave it untouched and fail the sync).")
+	fs.StringVar(&o.ComponentConfig.ImagePullPolicyRewrite, "image-pull-policy-rewrite", o.ComponentConfig.ImagePullPolicyRewrite, "ImagePullPolicyRewrite decides whether the imagePullPolicy of super pod containers is rewritten to --image-pull-policy: none (keep the tenant value), force (rewrite all containers) or override-always (rewrite containers using Always). It can be overridden by the tenancy.x-k8s.io/image-pull-policy-rewrite annotation of a VirtualCluster.")
+	fs.StringVar(&o.ComponentConfig.ImagePullPolicy, "image-pull-policy", o.ComponentConfig.ImagePullPolicy, "ImagePullPolicy is the imagePullPolicy set by --image-pull-policy-rewrite. It can be overridden by the tenancy.x-k8s.io/image-pull-policy annotation of a VirtualCluster.")
+	fs.DurationVar(&o.ComponentConfig.ObjectCountQuotaPausePeriod.Duration, "object-count-quota-pause-period", o.ComponentConfig.ObjectCountQuotaPausePeriod.Duration, "ObjectCountQuotaPausePeriod is how long the creation of a resource type is paused for a Virtual Cluster after the super cluster rejected an object due to an object count quota, 0 disables the pause.")
+	fs.IntVar(&o.ComponentConfig.DWSMaxConcurrentReconcilesPerCluster, "dws-max-concurrent-reconciles-per-cluster", o.ComponentConfig.DWSMaxConcurrentReconcilesPerCluster, "DWSMaxConcurrentReconcilesPerCluster is the maximum number of workers of a dws controller reconciling requests of a single Virtual Cluster at the same time, 0 means no limit.")
+	fs.IntVar(&o.ComponentConfig.PerClusterWorkerLimit, "per-cluster-worker-limit", o.ComponentConfig.PerClusterWorkerLimit, "PerClusterWorkerLimit is the maximum number of workers of all the dws controllers together reconciling requests of a single Virtual Cluster at the same time, 0 means no limit.")
+	fs.DurationVar(&o.ComponentConfig.ShutdownGracePeriod.Duration, "shutdown-grace-period", o.ComponentConfig.ShutdownGracePeriod.Duration, "ShutdownGracePeriod is how long the syncer waits for in-flight reconciles to finish, without starting new ones, when it is stopped or loses its leadership. Keep it shorter than the termination grace period of the syncer pod, 0 exits right away.")
+	fs.IntVar(&o.ComponentConfig.DWSOnboardingMaxConcurrentReconciles, "dws-onboarding-max-concurrent-reconciles", o.ComponentConfig.DWSOnboardingMaxConcurrentReconciles, "DWSOnboardingMaxConcurrentReconciles is the maximum number of workers of a dws controller reconciling requests of a newly added Virtual Cluster at the same time, until all its existing objects are synced, 0 means no onboarding limit.")
+	fs.DurationVar(&o.ComponentConfig.DWSOnboardingRampUpPeriod.Duration, "dws-onboarding-ramp-up-period", o.ComponentConfig.DWSOnboardingRampUpPeriod.Duration, "DWSOnboardingRampUpPeriod is how often the onboarding limit of dws-onboarding-max-concurrent-reconciles doubles, 0 keeps it constant.")
+	fs.IntVar(&o.ComponentConfig.SyncMaxRetries, "sync-max-retries", o.ComponentConfig.SyncMaxRetries, "SyncMaxRetries is the number of retries after which a failing request is taken out of the work queue of a dws or uws controller, dws requests are dead-lettered and uws requests are dropped.")
+	fs.DurationVar(&o.ComponentConfig.SyncBaseDelay.Duration, "sync-base-delay", o.ComponentConfig.SyncBaseDelay.Duration, "SyncBaseDelay is the delay before the first retry of a failing dws or uws request, doubled with every retry up to sync-max-delay.")
+	fs.DurationVar(&o.ComponentConfig.SyncMaxDelay.Duration, "sync-max-delay", o.ComponentConfig.SyncMaxDelay.Duration, "SyncMaxDelay is the maximum delay between the retries of a failing dws or uws request.")
+	fs.IntVar(&o.ComponentConfig.DWSDeadLetterRetryThreshold, "dws-dead-letter-retry-threshold", o.ComponentConfig.DWSDeadLetterRetryThreshold, "DWSDeadLetterRetryThreshold is the number of retries after which a tenant object failing to sync is taken out of the retry loop of a dws controller and added to the dead-letter set, 0 means sync-max-retries.")
+	fs.DurationVar(&o.ComponentConfig.DWSDeadLetterRetryPeriod.Duration, "dws-dead-letter-retry-period", o.ComponentConfig.DWSDeadLetterRetryPeriod.Duration, "DWSDeadLetterRetryPeriod is how often the objects of the dead-letter set are retried, besides when they change, 0 retries them only when they change.")
+	fs.IntVar(&o.ComponentConfig.NamespaceCreationMaxRetries, "namespace-creation-max-retries", o.ComponentConfig.NamespaceCreationMaxRetries, "NamespaceCreationMaxRetries is the number of times the creation of a super cluster namespace failing transiently, e.g. due to an admission webhook timeout, is retried before its VirtualCluster gets a NamespaceCreationFailed condition, 0 disables the conditions.")
+	fs.DurationVar(&o.ComponentConfig.NamespaceCreationRetryPeriod.Duration, "namespace-creation-retry-period", o.ComponentConfig.NamespaceCreationRetryPeriod.Duration, "NamespaceCreationRetryPeriod is the delay before the first retry of a failing namespace request, doubled with every retry up to sync-max-delay.")
+	fs.IntVar(&o.ComponentConfig.MetricsMaxVCCardinality, "metrics-max-vc-cardinality", o.ComponentConfig.MetricsMaxVCCardinality, "MetricsMaxVCCardinality is the maximum number of Virtual Clusters with their own vc_name label value in the per Virtual Cluster metrics, the Virtual Clusters beyond it are aggregated in the vc_name=\"other\" series. 0 means no limit.")
+	fs.IntVar(&o.ComponentConfig.LogSampling, "log-sampling", o.ComponentConfig.LogSampling, "LogSampling lets one in every N repetitive info logs, e.g. the per-request logs of the syncing controllers, through per resource type, errors are never sampled. 0 or 1 disables sampling.")
+	fs.Float32Var(&o.ComponentConfig.UWSQPS, "uws-qps", o.ComponentConfig.UWSQPS, "UWSQPS is the maximum number of back populations per second of each uws controller to the tenant control planes, 0 means no limit.")
+	fs.IntVar(&o.ComponentConfig.UWSBurst, "uws-burst", o.ComponentConfig.UWSBurst, "UWSBurst is the maximum burst of back populations of each uws controller allowed by uws-qps.")
+	fs.DurationVar(&o.ComponentConfig.UWSCoalescePeriod.Duration, "uws-coalesce-period", o.ComponentConfig.UWSCoalescePeriod.Duration, "UWSCoalescePeriod is how long the back population of a changed super cluster object is delayed to coalesce its further changes into a single tenant write, 0 disables the coalescing.")
+	fs.DurationVar(&o.ComponentConfig.ObjectCountRecountInterval.Duration, "object-count-recount-interval", o.ComponentConfig.ObjectCountRecountInterval.Duration, "ObjectCountRecountInterval is how often the per Virtual Cluster tenant object counts are rebuilt from the informer caches to correct drift, 0 disables the recount.")
+	fs.StringVar(&o.ComponentConfig.LifecycleWebhookURL, "lifecycle-webhook-url", o.ComponentConfig.LifecycleWebhookURL, "LifecycleWebhookURL is the http(s) endpoint the sync lifecycle events (Synced, Failed, CleanedUp) of tenant objects are POSTed to, empty disables the notifications.")
+	fs.StringSliceVar(&o.ComponentConfig.LifecycleWebhookEventTypes, "lifecycle-webhook-event-types", o.ComponentConfig.LifecycleWebhookEventTypes, "LifecycleWebhookEventTypes are the lifecycle event types sent to the lifecycle webhook (Synced, Failed, CleanedUp), empty means all.")
+	fs.Float32Var(&o.ComponentConfig.LifecycleWebhookQPS, "lifecycle-webhook-qps", o.ComponentConfig.LifecycleWebhookQPS, "LifecycleWebhookQPS is the maximum rate of requests to the lifecycle webhook.")
+	fs.IntVar(&o.ComponentConfig.LifecycleWebhookBurst, "lifecycle-webhook-burst", o.ComponentConfig.LifecycleWebhookBurst, "LifecycleWebhookBurst is the maximum burst of requests to the lifecycle webhook.")
+	fs.IntVar(&o.ComponentConfig.LifecycleWebhookMaxRetries, "lifecycle-webhook-max-retries", o.ComponentConfig.LifecycleWebhookMaxRetries, "LifecycleWebhookMaxRetries is the number of retries, with exponential backoff, of a failed lifecycle event delivery before the event is dropped.")
+	fs.StringVar(&o.ComponentConfig.Tracing.Endpoint, "tracing-endpoint", o.ComponentConfig.Tracing.Endpoint, "Tracing.Endpoint is the http(s) OTLP/HTTP endpoint of an OpenTelemetry collector, e.g. http://tempo:4318, a span per dws and uws reconcile is exported to, at /v1/traces if it has no path. Empty disables tracing.")
+	fs.Int32Var(&o.ComponentConfig.Tracing.SamplingRatePerMillion, "tracing-sampling-rate-per-million", o.ComponentConfig.Tracing.SamplingRatePerMillion, "Tracing.SamplingRatePerMillion is the number of traces per million exported to tracing-endpoint, the reconciles of an object are in the same trace.")
+	fs.StringVar(&o.ComponentConfig.DefaultWindowsRunAsUserName, "default-windows-run-as-user-name", o.ComponentConfig.DefaultWindowsRunAsUserName, "DefaultWindowsRunAsUserName is the windowsOptions.runAsUserName applied to Windows pods whose pod and containers specify none, e.g. ContainerUser.")
+	fs.StringSliceVar(&o.ComponentConfig.AllowedWindowsRunAsUserNames, "allowed-windows-run-as-user-names", o.ComponentConfig.AllowedWindowsRunAsUserNames, "AllowedWindowsRunAsUserNames are the windowsOptions.runAsUserName values pods may use, compared case insensitively, empty allows all. Pods using other users are not synced.")
+	fs.StringSliceVar(&o.ComponentConfig.SkipSyncServiceAccounts, "skip-sync-service-accounts", o.ComponentConfig.SkipSyncServiceAccounts, "SkipSyncServiceAccounts are the names of the service accounts, e.g. default, not created or deleted in the super cluster. The service account created by the super cluster in the namespace is adopted and gets the automountServiceAccountToken and imagePullSecrets of the tenant one.")
+	fs.StringSliceVar(&o.ComponentConfig.SuperClusterIPFamilies, "super-cluster-ip-families", o.ComponentConfig.SuperClusterIPFamilies, "SuperClusterIPFamilies are the IP families of the super cluster service network (IPv4, IPv6), primary first. Services are synced with the ipFamilies the super cluster supports, services requiring others are not synced. Empty passes ipFamilies unchanged.")
+	fs.StringVar(&o.ComponentConfig.DefaultAppArmorProfile, "default-apparmor-profile", o.ComponentConfig.DefaultAppArmorProfile, "DefaultAppArmorProfile is the AppArmor profile (runtime/default, unconfined or localhost/<name>) applied to pod containers that specify none.")
+	fs.IntVar(&o.ComponentConfig.VirtualClusterRegistrationConcurrency, "vc-registration-concurrency", o.ComponentConfig.VirtualClusterRegistrationConcurrency, "VirtualClusterRegistrationConcurrency is the number of VirtualClusters registered in parallel at startup.")
+	fs.IntVar(&o.ComponentConfig.SuperClusterFailureThreshold, "super-cluster-failure-threshold", o.ComponentConfig.SuperClusterFailureThreshold, "SuperClusterFailureThreshold is the number of consecutive failed checks of the super cluster apiserver after which the VirtualClusters get a SuperClusterUnreachable condition, 0 disables the checks.")
+	fs.DurationVar(&o.ComponentConfig.SuperClusterHealthCheckPeriod.Duration, "super-cluster-health-check-period", o.ComponentConfig.SuperClusterHealthCheckPeriod.Duration, "SuperClusterHealthCheckPeriod is how often the super cluster apiserver is checked.")
+	fs.IntVar(&o.ComponentConfig.SuperWatchErrorThreshold, "super-watch-error-threshold", o.ComponentConfig.SuperWatchErrorThreshold, "SuperWatchErrorThreshold is the number of consecutive list and watch errors of a super or meta cluster informer after which it is considered failing, 0 disables the checks.")
+	fs.StringVar(&o.ComponentConfig.OnPersistentWatchErrors, "on-persistent-watch-errors", o.ComponentConfig.OnPersistentWatchErrors, "OnPersistentWatchErrors is what happens while a super or meta cluster informer is failing: degrade (mark the VirtualClusters with a SuperClusterWatchFailing condition) or restart (mark them, then stop like on a termination signal and exit, so that the syncer restarts with new informers).")
+	fs.DurationVar(&o.ComponentConfig.SuperClusterLookupCacheTTL.Duration, "super-cluster-lookup-cache-ttl", o.ComponentConfig.SuperClusterLookupCacheTTL.Duration, "SuperClusterLookupCacheTTL is how long the super cluster objects looked up by the conversions without an informer are cached, 0 disables the cache.")
+	fs.Int32Var(&o.ComponentConfig.VNAgentPort, "vn-agent-port", o.ComponentConfig.VNAgentPort, "Port the vn-agent listens on")
+	fs.DurationVar(&o.ComponentConfig.TerminationStuckTimeout.Duration, "termination-stuck-timeout", o.ComponentConfig.TerminationStuckTimeout.Duration, "TerminationStuckTimeout is how long past its deletion grace period a super cluster pod can be terminating, e.g. held by a finalizer or an unresponsive node, before its tenant pod gets the TerminationStuck condition and event. 0 disables the check.")
+	fs.BoolVar(&o.ComponentConfig.ForceDeleteStuckPods, "force-delete-stuck-pods", o.ComponentConfig.ForceDeleteStuckPods, "ForceDeleteStuckPods force deletes, with a zero grace period, the super cluster pods stuck terminating past termination-stuck-timeout.")
+	fs.StringVar(&o.ComponentConfig.VNAgentNamespacedName, "vn-agent-namespace-name", o.ComponentConfig.VNAgentNamespacedName, "Namespace/Name of the vn-agent running in cluster, used for VNodeProviderService")
+	fs.Var(cliflag.NewMapStringString(&o.DNSOptions), "dns-options", "DNSOptions is the default DNS options attached to each pod, sorted by name")
+	fs.StringArrayVar(&o.DNSOptionList, "dns-option", o.DNSOptionList, "A DNS option attached to each pod, name=value or name for an option without value, e.g. edns0. It can be repeated, the options keep their order. It replaces --dns-options and cannot be used with it.")
+	fs.StringVar(&o.ComponentConfig.VNAgentLabelSelector, "vn-agent-label-selector", o.ComponentConfig.VNAgentLabelSelector, "Label key=value of the vn-agent running in cluster, used for VNodeProviderPodIP")
 
 	serverFlags := fss.FlagSet("metricsServer")
 	serverFlags.StringVar(&o.Address, "address", o.Address, "The server address.")
@@ -143,7 +219,9 @@ func (o *ResourceSyncerOptions) Flags() cliflag.NamedFlagSets {
 	serverFlags.StringVar(&o.KeyFile, "key-file", o.KeyFile, "KeyFile is the file containing x509 private key matching certFile.")
 
 	BindFlags(&o.ComponentConfig.LeaderElection, fss.FlagSet("leader election"))
+	o.Logs.AddFlags(fss.FlagSet("logging"))
 
+	o.flagSets = fss
 	return fss
 }
 
@@ -168,34 +246,42 @@ func BindFlags(l *syncerconfig.SyncerLeaderElectionConfiguration, fs *pflag.Flag
 		"of a leadership. This is only applicable if leader election is enabled.")
 	fs.StringVar(&l.ResourceLock, "leader-elect-resource-lock", l.ResourceLock, ""+
 		"The type of resource object that is used for locking during "+
-		"leader election. Supported options are `endpoints` and `configmaps` (default).")
+		"leader election. Supported options are `leases` (default), `endpoints` and `configmaps`, "+
+		"deprecated, and `endpointsleases` and `configmapsleases`, which hold both locks to migrate "+
+		"from the deprecated ones to `leases` without two replicas leading at once.")
+	fs.DurationVar(&l.WatchDogTimeout.Duration, "leader-elect-watchdog-timeout", l.WatchDogTimeout.Duration, ""+
+		"How long past the lease duration the leader may go without renewing its leadership "+
+		"before the leader election health check of the healthz endpoint fails. It must be "+
+		"positive and at most 4 times the lease duration. This is only applicable if leader "+
+		"election is enabled.")
 	fs.StringVar(&l.LockObjectNamespace, "lock-object-namespace", l.LockObjectNamespace, "DEPRECATED: define the namespace of the lock object.")
 	fs.StringVar(&l.LockObjectName, "lock-object-name", l.LockObjectName, "DEPRECATED: define the name of the lock object.")
 }
 
 // Config return a syncer config object
 func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
+	if o.ConfigFile != "" {
+		if err := o.loadConfigFile(o.ConfigFile); err != nil {
+			return nil, err
+		}
+	}
+	o.ComponentConfig.ExtraSyncingResources = normalizeExtraSyncingResources(o.ComponentConfig.ExtraSyncingResources)
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	// Every log line from now on, e.g. of the leader election and the controllers, uses the format.
+	o.Logs.Apply()
+	o.ComponentConfig.ExtraSyncingResources, o.ComponentConfig.SyncDirections = splitSyncDirections(o.ComponentConfig.ExtraSyncingResources, o.ComponentConfig.SyncDirections)
+
 	c := &syncerappconfig.Config{}
 	c.ComponentConfig = o.ComponentConfig
 
 	// Prepare kube clients
-	var (
-		metaRestConfig, superRestConfig *restclient.Config
-		leaderElectionRestConfig        restclient.Config
-		err                             error
-	)
-	superRestConfig, err = getClientConfig(c.ComponentConfig.ClientConnection, o.SuperClusterAddress, o.ComponentConfig.Timeout, !o.DeployOnMetaCluster)
+	var leaderElectionRestConfig restclient.Config
+	metaRestConfig, superRestConfig, err := o.clientConfigs()
 	if err != nil {
 		return nil, err
 	}
-	if o.DeployOnMetaCluster || o.MetaClusterClientConnection.Kubeconfig != "" {
-		metaRestConfig, err = getClientConfig(o.MetaClusterClientConnection, o.MetaClusterAddress, o.ComponentConfig.Timeout, o.DeployOnMetaCluster)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		metaRestConfig = superRestConfig
-	}
 
 	if o.DeployOnMetaCluster {
 		leaderElectionRestConfig = *metaRestConfig
@@ -203,6 +289,14 @@ func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 		leaderElectionRestConfig = *superRestConfig
 	}
 
+	if c.ComponentConfig.FieldManager == "" {
+		c.ComponentConfig.FieldManager = o.SyncerName
+	}
+	superRestConfig = syncerutil.WithFieldManager(superRestConfig, c.ComponentConfig.FieldManager)
+	if c.ComponentConfig.DryRun {
+		superRestConfig = syncerutil.WithDryRun(superRestConfig, "super")
+	}
+
 	superClusterClient, err := clientset.NewForConfig(restclient.AddUserAgent(superRestConfig, constants.ResourceSyncerUserAgent))
 	if err != nil {
 		return nil, err
@@ -243,18 +337,31 @@ func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	logsampling.SetRate(c.ComponentConfig.LogSampling)
 
 	// Setup Scheme for all resources
 	if err := apis.AddToScheme(scheme.Scheme); err != nil {
 		return nil, err
 	}
-	c.ComponentConfig.RestConfig = superRestConfig
-	c.ComponentConfig.DNSOptions = dnsOptionsConvert(o.DNSOptions)
+	c.Kubeconfig = superRestConfig
+	if c.ComponentConfig.DNSOptions == nil {
+		if len(o.DNSOptionList) != 0 {
+			c.ComponentConfig.DNSOptions, err = parseDNSOptions(o.DNSOptionList)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			c.ComponentConfig.DNSOptions = dnsOptionsConvert(o.DNSOptions)
+		}
+	}
 	c.VirtualClusterClient = virtualClusterClient
 	c.VirtualClusterInformer = vcinformers.NewSharedInformerFactory(virtualClusterClient, 0).Tenancy().V1alpha1().VirtualClusters()
 	c.MetaClusterClient = metaClusterClient
 	c.SuperClusterClient = superClusterClient
 	c.SuperClusterInformerFactory = informers.NewSharedInformerFactory(superClusterClient, 0)
+	if err := syncerutil.ApplyInformerFieldSelectors(c.SuperClusterInformerFactory, c.ComponentConfig.InformerFieldSelectors); err != nil {
+		return nil, err
+	}
 	c.Broadcaster = eventBroadcaster
 	c.Recorder = recorder
 	c.LeaderElectionClient = leaderElectionClient
@@ -271,6 +378,10 @@ func (o *ResourceSyncerOptions) Config() (*syncerappconfig.Config, error) {
 // makeLeaderElectionConfig builds a leader election configuration. It will
 // create a new resource lock associated with the configuration.
 func makeLeaderElectionConfig(config syncerconfig.SyncerLeaderElectionConfiguration, client clientset.Interface, recorder record.EventRecorder, syncername string) (*leaderelection.LeaderElectionConfig, error) {
+	if err := validateWatchDogTimeout(config); err != nil {
+		return nil, err
+	}
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, fmt.Errorf("unable to get hostname: %v", err)
@@ -304,11 +415,29 @@ func makeLeaderElectionConfig(config syncerconfig.SyncerLeaderElectionConfigurat
 		LeaseDuration: config.LeaseDuration.Duration,
 		RenewDeadline: config.RenewDeadline.Duration,
 		RetryPeriod:   config.RetryPeriod.Duration,
-		WatchDog:      leaderelection.NewLeaderHealthzAdaptor(time.Second * 20),
+		WatchDog:      leaderelection.NewLeaderHealthzAdaptor(config.WatchDogTimeout.Duration),
 		Name:          constants.ResourceSyncerUserAgent,
+		// the syncer cancels the leader election once the requests in flight are drained, release
+		// the lease then so the next leader does not wait for it to expire.
+		ReleaseOnCancel: true,
 	}, nil
 }
 
+// maxWatchDogTimeoutLeases bounds the watchdog timeout in lease durations. Another replica may
+// lead once the lease expires, so a stuck leader must not go unnoticed for much longer.
+const maxWatchDogTimeoutLeases = 4
+
+func validateWatchDogTimeout(config syncerconfig.SyncerLeaderElectionConfiguration) error {
+	timeout := config.WatchDogTimeout.Duration
+	if timeout <= 0 {
+		return fmt.Errorf("leader election watchdog timeout must be positive, got %v", timeout)
+	}
+	if limit := maxWatchDogTimeoutLeases * config.LeaseDuration.Duration; timeout > limit {
+		return fmt.Errorf("leader election watchdog timeout %v exceeds %d times the lease duration %v", timeout, maxWatchDogTimeoutLeases, config.LeaseDuration.Duration)
+	}
+	return nil
+}
+
 func getInClusterNamespace() (string, error) {
 	// Check whether the namespace file exists.
 	// If not, we are not running in cluster so can't guess the namespace.
@@ -328,6 +457,29 @@ func getInClusterNamespace() (string, error) {
 }
 
 // getClientConfig creates a Kubernetes client rest config from the given config and serverAddrOverride.
+// clientConfigs builds the rest configs of the meta and super clusters. The meta cluster is the super
+// cluster unless the syncer is deployed on it or its kubeconfig is set, its client is still rate
+// limited by the meta cluster QPS and burst, if set.
+func (o *ResourceSyncerOptions) clientConfigs() (metaRestConfig, superRestConfig *restclient.Config, err error) {
+	superRestConfig, err = getClientConfig(o.ComponentConfig.ClientConnection, o.SuperClusterAddress, o.ComponentConfig.Timeout, !o.DeployOnMetaCluster)
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.DeployOnMetaCluster || o.MetaClusterClientConnection.Kubeconfig != "" {
+		metaRestConfig, err = getClientConfig(o.MetaClusterClientConnection, o.MetaClusterAddress, o.ComponentConfig.Timeout, o.DeployOnMetaCluster)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		metaRestConfig = superRestConfig
+		if o.MetaClusterClientConnection.QPS != 0 || o.MetaClusterClientConnection.Burst != 0 {
+			metaRestConfig = restclient.CopyConfig(superRestConfig)
+			setClientRateLimit(metaRestConfig, o.MetaClusterClientConnection)
+		}
+	}
+	return metaRestConfig, superRestConfig, nil
+}
+
 func getClientConfig(config componentbaseconfig.ClientConnectionConfiguration, serverAddrOverride, timeout string, inCluster bool) (*restclient.Config, error) {
 	// This creates a client, first loading any specified kubeconfig
 	// file, and then overriding the serverAddr flag, if non-empty.
@@ -365,6 +517,13 @@ func getClientConfig(config componentbaseconfig.ClientConnectionConfiguration, s
 	}
 
 	restConfig.ContentConfig.ContentType = config.AcceptContentTypes
+	setClientRateLimit(restConfig, config)
+
+	return restConfig, nil
+}
+
+// setClientRateLimit sets the QPS and burst of the client connection, the syncer defaults if zero.
+func setClientRateLimit(restConfig *restclient.Config, config componentbaseconfig.ClientConnectionConfiguration) {
 	restConfig.QPS = config.QPS
 	if restConfig.QPS == 0 {
 		restConfig.QPS = constants.DefaultSyncerClientQPS
@@ -373,14 +532,38 @@ func getClientConfig(config componentbaseconfig.ClientConnectionConfiguration, s
 	if restConfig.Burst == 0 {
 		restConfig.Burst = constants.DefaultSyncerClientBurst
 	}
-
-	return restConfig, nil
 }
 
+// dnsOptionsConvert converts the DNS options sorted by name, so that the pods get the same options
+// in the same order from every syncer.
 func dnsOptionsConvert(dnsoptions map[string]string) []corev1.PodDNSConfigOption {
+	names := make([]string, 0, len(dnsoptions))
+	for k := range dnsoptions {
+		names = append(names, k)
+	}
+	sort.Strings(names)
 	podDNSOptions := []corev1.PodDNSConfigOption{}
-	for k, v := range dnsoptions {
-		podDNSOptions = append(podDNSOptions, corev1.PodDNSConfigOption{Name: k, Value: pointer.StringPtr(v)})
+	for _, k := range names {
+		podDNSOptions = append(podDNSOptions, corev1.PodDNSConfigOption{Name: k, Value: pointer.StringPtr(dnsoptions[k])})
 	}
 	return podDNSOptions
 }
+
+// parseDNSOptions converts the name=value DNS options in order. An option without "=", e.g. edns0,
+// has no value, and an option may be repeated.
+func parseDNSOptions(options []string) ([]corev1.PodDNSConfigOption, error) {
+	podDNSOptions := make([]corev1.PodDNSConfigOption, 0, len(options))
+	for _, option := range options {
+		kv := strings.SplitN(option, "=", 2)
+		name := strings.TrimSpace(kv[0])
+		if name == "" {
+			return nil, fmt.Errorf("invalid --dns-option %q: missing name", option)
+		}
+		dnsOption := corev1.PodDNSConfigOption{Name: name}
+		if len(kv) == 2 {
+			dnsOption.Value = pointer.StringPtr(kv[1])
+		}
+		podDNSOptions = append(podDNSOptions, dnsOption)
+	}
+	return podDNSOptions, nil
+}
@@ -0,0 +1,548 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+
+	syncerconfigscheme "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/scheme"
+	syncerconfigv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+)
+
+func TestLeaderElectWatchDogTimeoutFlag(t *testing.T) {
+	o, err := NewResourceSyncerOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := o.ComponentConfig.LeaderElection.WatchDogTimeout.Duration; got != 20*time.Second {
+		t.Errorf("expected the default watchdog timeout to be 20s, got %v", got)
+	}
+
+	fs := o.Flags().FlagSet("leader election")
+	if err := fs.Parse([]string{"--leader-elect-watchdog-timeout=45s"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := o.ComponentConfig.LeaderElection.WatchDogTimeout.Duration; got != 45*time.Second {
+		t.Errorf("expected the watchdog timeout to be 45s, got %v", got)
+	}
+}
+
+func TestMakeLeaderElectionConfigWatchDog(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		timeout     time.Duration
+		expectedErr bool
+	}{
+		{
+			name:    "default",
+			timeout: 20 * time.Second,
+		},
+		{
+			name:    "at the limit",
+			timeout: 60 * time.Second,
+		},
+		{
+			name:        "zero",
+			timeout:     0,
+			expectedErr: true,
+		},
+		{
+			name:        "negative",
+			timeout:     -time.Second,
+			expectedErr: true,
+		},
+		{
+			name:        "too long for the lease duration",
+			timeout:     61 * time.Second,
+			expectedErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := NewResourceSyncerOptions()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			config := o.ComponentConfig.LeaderElection
+			config.LockObjectNamespace = "default"
+			config.LeaseDuration = metav1.Duration{Duration: 15 * time.Second}
+			config.WatchDogTimeout = metav1.Duration{Duration: tt.timeout}
+
+			le, err := makeLeaderElectionConfig(config, fake.NewSimpleClientset(), record.NewFakeRecorder(10), "test")
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if le.WatchDog == nil {
+				t.Fatalf("expected a watchdog")
+			}
+			// The adaptor does not expose its timeout.
+			if got := time.Duration(reflect.ValueOf(le.WatchDog).Elem().FieldByName("timeout").Int()); got != tt.timeout {
+				t.Errorf("expected the watchdog timeout to be %v, got %v", tt.timeout, got)
+			}
+		})
+	}
+}
+
+func TestMakeLeaderElectionConfigResourceLock(t *testing.T) {
+	o, err := NewResourceSyncerOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := o.ComponentConfig.LeaderElection.ResourceLock; got != resourcelock.LeasesResourceLock {
+		t.Errorf("expected the default resource lock to be leases, got %q", got)
+	}
+
+	for _, tt := range []struct {
+		lock         string
+		expectedLock resourcelock.Interface
+	}{
+		{lock: resourcelock.LeasesResourceLock, expectedLock: &resourcelock.LeaseLock{}},
+		{lock: resourcelock.ConfigMapsResourceLock, expectedLock: &resourcelock.ConfigMapLock{}},
+		{lock: resourcelock.EndpointsResourceLock, expectedLock: &resourcelock.EndpointsLock{}},
+		{lock: resourcelock.ConfigMapsLeasesResourceLock, expectedLock: &resourcelock.MultiLock{}},
+		{lock: resourcelock.EndpointsLeasesResourceLock, expectedLock: &resourcelock.MultiLock{}},
+	} {
+		t.Run(tt.lock, func(t *testing.T) {
+			config := o.ComponentConfig.LeaderElection
+			config.LockObjectNamespace = "default"
+			config.ResourceLock = tt.lock
+
+			le, err := makeLeaderElectionConfig(config, fake.NewSimpleClientset(), record.NewFakeRecorder(10), "test")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if reflect.TypeOf(le.Lock) != reflect.TypeOf(tt.expectedLock) {
+				t.Errorf("expected a %T lock, got %T", tt.expectedLock, le.Lock)
+			}
+			if got := le.Lock.Describe(); got != "default/test-syncer-leaderelection-lock" {
+				t.Errorf("expected the lock default/test-syncer-leaderelection-lock, got %s", got)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		file        string
+		args        []string
+		expectedErr string
+		check       func(t *testing.T, o *ResourceSyncerOptions)
+	}{
+		{
+			name: "file values and defaults",
+			file: `
+leaderElection:
+  leaseDuration: 30s
+  lockObjectName: my-lock
+extraSyncingResources:
+- priorityclass
+- ingress
+featureGates:
+  SuperClusterPooling: true
+`,
+			check: func(t *testing.T, o *ResourceSyncerOptions) {
+				le := o.ComponentConfig.LeaderElection
+				if le.LeaseDuration.Duration != 30*time.Second || le.LockObjectName != "my-lock" {
+					t.Errorf("expected the leader election of the file, got %+v", le)
+				}
+				if le.RenewDeadline.Duration != 10*time.Second || !le.LeaderElect {
+					t.Errorf("expected the leader election defaults to be kept, got %+v", le)
+				}
+				if got := o.ComponentConfig.ExtraSyncingResources; !reflect.DeepEqual(got, []string{"priorityclass", "ingress"}) {
+					t.Errorf("expected the extra syncing resources of the file, got %v", got)
+				}
+				if got := o.ComponentConfig.FeatureGates; !got["SuperClusterPooling"] || len(got) != 3 {
+					t.Errorf("expected the feature gates of the file merged with the defaults, got %v", got)
+				}
+				if got := o.ComponentConfig.VNAgentPort; got != 10550 {
+					t.Errorf("expected the default vn-agent port, got %d", got)
+				}
+			},
+		},
+		{
+			name: "vn-agent file values",
+			file: `
+vnAgentPort: 10551
+vnAgentNamespacedName: tenancy/vn-agent
+vnAgentLabelSelector: app=my-vn-agent
+`,
+			check: func(t *testing.T, o *ResourceSyncerOptions) {
+				if got := o.ComponentConfig.VNAgentPort; got != 10551 {
+					t.Errorf("expected the vn-agent port of the file, got %d", got)
+				}
+				if got := o.ComponentConfig.VNAgentNamespacedName; got != "tenancy/vn-agent" {
+					t.Errorf("expected the vn-agent namespaced name of the file, got %s", got)
+				}
+				if got := o.ComponentConfig.VNAgentLabelSelector; got != "app=my-vn-agent" {
+					t.Errorf("expected the vn-agent label selector of the file, got %s", got)
+				}
+			},
+		},
+		{
+			name: "flags override the file",
+			file: `
+leaderElection:
+  leaseDuration: 30s
+extraSyncingResources:
+- priorityclass
+vnAgentPort: 10551
+superNamespaceQuota:
+  pods: "100"
+`,
+			args: []string{
+				"--leader-elect-lease-duration=45s",
+				"--extra-syncing-resources=crd",
+				"--super-namespace-quota=cpu=2",
+			},
+			check: func(t *testing.T, o *ResourceSyncerOptions) {
+				if got := o.ComponentConfig.LeaderElection.LeaseDuration.Duration; got != 45*time.Second {
+					t.Errorf("expected the lease duration of the flag, got %v", got)
+				}
+				if got := o.ComponentConfig.ExtraSyncingResources; !reflect.DeepEqual(got, []string{"crd"}) {
+					t.Errorf("expected the extra syncing resources of the flag, got %v", got)
+				}
+				if got := o.ComponentConfig.SuperNamespaceQuota; !reflect.DeepEqual(got, map[string]string{"cpu": "2"}) {
+					t.Errorf("expected the super namespace quota of the flag, got %v", got)
+				}
+				if got := o.ComponentConfig.VNAgentPort; got != 10551 {
+					t.Errorf("expected the vn-agent port of the file, got %d", got)
+				}
+			},
+		},
+		{
+			name: "defaults",
+			file: "apiVersion: syncer.config.tenancy.x-k8s.io/v1alpha1\nkind: SyncerConfiguration\n",
+			check: func(t *testing.T, o *ResourceSyncerOptions) {
+				defaults, err := NewResourceSyncerOptions()
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				got := o.ComponentConfig
+				got.TypeMeta = metav1.TypeMeta{}
+				if !reflect.DeepEqual(got, defaults.ComponentConfig) {
+					t.Errorf("expected the defaults, got %+v", got)
+				}
+			},
+		},
+		{
+			name: "defaults < file < flags",
+			file: `
+apiVersion: syncer.config.tenancy.x-k8s.io/v1alpha1
+kind: SyncerConfiguration
+leaderElection:
+  leaseDuration: 30s
+  renewDeadline: 20s
+`,
+			args: []string{"--leader-elect-renew-deadline=25s"},
+			check: func(t *testing.T, o *ResourceSyncerOptions) {
+				le := o.ComponentConfig.LeaderElection
+				if le.RetryPeriod.Duration != 2*time.Second {
+					t.Errorf("expected the default retry period, got %v", le.RetryPeriod.Duration)
+				}
+				if le.LeaseDuration.Duration != 30*time.Second {
+					t.Errorf("expected the lease duration of the file, got %v", le.LeaseDuration.Duration)
+				}
+				if le.RenewDeadline.Duration != 25*time.Second {
+					t.Errorf("expected the renew deadline of the flag, got %v", le.RenewDeadline.Duration)
+				}
+			},
+		},
+		{
+			name: "dns options of the file",
+			file: `
+dnsOptions:
+- name: ndots
+  value: "2"
+- name: edns0
+`,
+			check: func(t *testing.T, o *ResourceSyncerOptions) {
+				expected := []corev1.PodDNSConfigOption{{Name: "ndots", Value: pointer.StringPtr("2")}, {Name: "edns0"}}
+				if got := o.ComponentConfig.DNSOptions; !reflect.DeepEqual(got, expected) {
+					t.Errorf("expected the dns options of the file, got %v", got)
+				}
+			},
+		},
+		{
+			name: "dns options flag overrides the file",
+			file: "dnsOptions:\n- name: edns0\n",
+			args: []string{"--dns-options=ndots=3"},
+			check: func(t *testing.T, o *ResourceSyncerOptions) {
+				if got := o.ComponentConfig.DNSOptions; got != nil {
+					t.Errorf("expected the dns options to be converted from the flag, got %v", got)
+				}
+				if got := o.DNSOptions; !reflect.DeepEqual(got, map[string]string{"ndots": "3"}) {
+					t.Errorf("expected the dns options of the flag, got %v", got)
+				}
+			},
+		},
+		{
+			name:        "unknown field",
+			file:        "extraSyncingResource:\n- priorityclass\n",
+			expectedErr: "unknown field",
+		},
+		{
+			name:        "rest config",
+			file:        "restConfig:\n  host: https://example.com\n",
+			expectedErr: "unknown field",
+		},
+		{
+			name:        "unknown version",
+			file:        "apiVersion: syncer.config.tenancy.x-k8s.io/v1beta1\nkind: SyncerConfiguration\n",
+			expectedErr: "is registered for version",
+		},
+		{
+			name:        "unknown kind",
+			file:        "apiVersion: syncer.config.tenancy.x-k8s.io/v1alpha1\nkind: ProxyConfiguration\n",
+			expectedErr: "is registered for version",
+		},
+		{
+			name:        "malformed duration",
+			file:        "leaderElection:\n  leaseDuration: soon\n",
+			expectedErr: "failed to decode config file",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := loadTestConfigFile(t, tt.file, tt.args...)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+					t.Errorf("expected error containing %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, o)
+		})
+	}
+}
+
+// loadTestConfigFile writes data to a config file and loads it, with args parsed like the command does.
+func loadTestConfigFile(t *testing.T, data string, args ...string) (*ResourceSyncerOptions, error) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	o, err := NewResourceSyncerOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The command parses the flags of all the named flag sets at once.
+	fs := pflag.NewFlagSet("syncer", pflag.ContinueOnError)
+	for _, f := range o.Flags().FlagSets {
+		fs.AddFlagSet(f)
+	}
+	if err := fs.Parse(append([]string{"--config=" + path}, args...)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return o, o.loadConfigFile(o.ConfigFile)
+}
+
+func TestConfigFileRoundTrip(t *testing.T) {
+	o, err := NewResourceSyncerOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config := o.ComponentConfig
+	config.FeatureGates = map[string]bool{
+		featuregate.SuperClusterPooling:        true,
+		featuregate.SuperClusterServiceNetwork: false,
+		featuregate.VNodeProviderService:       true,
+	}
+	config.ExtraSyncingResources = []string{"priorityclass", "ingress", "crd"}
+	config.DNSOptions = []corev1.PodDNSConfigOption{
+		{Name: "ndots", Value: pointer.StringPtr("2")},
+		{Name: "edns0"},
+	}
+
+	for _, mediaType := range []string{runtime.ContentTypeYAML, runtime.ContentTypeJSON} {
+		t.Run(mediaType, func(t *testing.T) {
+			info, ok := runtime.SerializerInfoForMediaType(syncerconfigscheme.Codecs.SupportedMediaTypes(), mediaType)
+			if !ok {
+				t.Fatalf("no serializer for %s", mediaType)
+			}
+			encoder := syncerconfigscheme.Codecs.EncoderForVersion(info.Serializer, syncerconfigv1alpha1.SchemeGroupVersion)
+			data, err := runtime.Encode(encoder, &config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			loaded, err := loadTestConfigFile(t, string(data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			// The decoded config is converted to the internal one, which has no apiVersion and kind.
+			if !reflect.DeepEqual(loaded.ComponentConfig, config) {
+				t.Errorf("expected the decoded config to equal the encoded one:\n%s", data)
+			}
+
+			encoded, err := runtime.Encode(encoder, &loaded.ComponentConfig)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(encoded) != string(data) {
+				t.Errorf("expected the decoded config to encode to\n%s\ngot\n%s", data, encoded)
+			}
+		})
+	}
+}
+
+func TestDNSOptionsConvert(t *testing.T) {
+	got := dnsOptionsConvert(map[string]string{"timeout": "2", "ndots": "5", "attempts": "3"})
+	expected := []corev1.PodDNSConfigOption{
+		{Name: "attempts", Value: pointer.StringPtr("3")},
+		{Name: "ndots", Value: pointer.StringPtr("5")},
+		{Name: "timeout", Value: pointer.StringPtr("2")},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the dns options sorted by name %v, got %v", expected, got)
+	}
+}
+
+func TestDNSOptionFlag(t *testing.T) {
+	o, err := NewResourceSyncerOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs := pflag.NewFlagSet("syncer", pflag.ContinueOnError)
+	for _, f := range o.Flags().FlagSets {
+		fs.AddFlagSet(f)
+	}
+	if err := fs.Parse([]string{"--dns-option=ndots=5", "--dns-option=edns0", "--dns-option=timeout=", "--dns-option=ndots=2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := parseDNSOptions(o.DNSOptionList)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []corev1.PodDNSConfigOption{
+		{Name: "ndots", Value: pointer.StringPtr("5")},
+		{Name: "edns0"},
+		{Name: "timeout", Value: pointer.StringPtr("")},
+		{Name: "ndots", Value: pointer.StringPtr("2")},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected the dns options in flag order %v, got %v", expected, got)
+	}
+}
+
+func TestClientConfigsRateLimit(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := ioutil.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: super
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: super
+  context:
+    cluster: super
+current-context: super
+`), 0600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name          string
+		args          []string
+		expectedSuper [2]int
+		expectedMeta  [2]int
+	}{
+		{
+			name:          "defaults",
+			expectedSuper: [2]int{1000, 2000},
+			expectedMeta:  [2]int{1000, 2000},
+		},
+		{
+			name:          "super cluster flags apply to the shared meta cluster client",
+			args:          []string{"--super-master-qps=50", "--super-master-burst=100"},
+			expectedSuper: [2]int{50, 100},
+			expectedMeta:  [2]int{50, 100},
+		},
+		{
+			name:          "meta cluster flags on the super cluster",
+			args:          []string{"--super-master-qps=50", "--super-master-burst=100", "--meta-cluster-qps=20"},
+			expectedSuper: [2]int{50, 100},
+			expectedMeta:  [2]int{20, 2000},
+		},
+		{
+			name:          "meta cluster kubeconfig",
+			args:          []string{"--meta-cluster-kubeconfig=" + kubeconfig, "--meta-cluster-qps=20", "--meta-cluster-burst=40"},
+			expectedSuper: [2]int{1000, 2000},
+			expectedMeta:  [2]int{20, 40},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := NewResourceSyncerOptions()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			fs := pflag.NewFlagSet("syncer", pflag.ContinueOnError)
+			for _, f := range o.Flags().FlagSets {
+				fs.AddFlagSet(f)
+			}
+			if err := fs.Parse(append([]string{"--super-master-kubeconfig=" + kubeconfig}, tt.args...)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			metaRestConfig, superRestConfig, err := o.clientConfigs()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := [2]int{int(superRestConfig.QPS), superRestConfig.Burst}; got != tt.expectedSuper {
+				t.Errorf("expected the super cluster QPS and burst %v, got %v", tt.expectedSuper, got)
+			}
+			if got := [2]int{int(metaRestConfig.QPS), metaRestConfig.Burst}; got != tt.expectedMeta {
+				t.Errorf("expected the meta cluster QPS and burst %v, got %v", tt.expectedMeta, got)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	o, err := NewResourceSyncerOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := o.loadConfigFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil || !strings.Contains(err.Error(), "failed to read config file") {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
@@ -0,0 +1,385 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	componentbaseconfig "k8s.io/component-base/config"
+
+	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	syncerconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/tracing"
+	syncerutil "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	vnodeprovider "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/vnode/provider"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+// Validate checks the options for invalid values and combinations, and returns the errors of all the
+// invalid ones at once.
+func (o *ResourceSyncerOptions) Validate() error {
+	var errs []error
+	errs = append(errs, o.Logs.Validate()...)
+	errs = append(errs, validateLeaderElection(o.ComponentConfig.LeaderElection)...)
+	errs = append(errs, validateFeatureGates(o.ComponentConfig.FeatureGates)...)
+	errs = append(errs, validateExtraSyncingResources(o.ComponentConfig.ExtraSyncingResources)...)
+	errs = append(errs, validateSyncDirections(o.ComponentConfig.SyncDirections)...)
+	errs = append(errs, validateUncachedResources(o.ComponentConfig.UncachedResources, o.ComponentConfig.InformerFieldSelectors)...)
+	for _, f := range o.enumFlags() {
+		if !sets.NewString(f.values...).Has(f.value) {
+			errs = append(errs, fmt.Errorf("unknown --%s %q, valid values are: %s", f.flag, f.value, strings.Join(f.values, ", ")))
+		}
+	}
+	if o.ComponentConfig.Timeout != "" {
+		if _, err := time.ParseDuration(o.ComponentConfig.Timeout); err != nil {
+			errs = append(errs, fmt.Errorf("invalid --super-master-timeout %q: %v", o.ComponentConfig.Timeout, err))
+		}
+	}
+	for _, entry := range o.ComponentConfig.ExtraNodeLabels {
+		if _, _, err := vnodeprovider.ParseLabelToSync(entry); err != nil {
+			errs = append(errs, fmt.Errorf("invalid --extra-node-labels %q: %v", entry, err))
+		}
+	}
+	if o.ComponentConfig.PerClusterWorkerLimit < 0 {
+		errs = append(errs, fmt.Errorf("--per-cluster-worker-limit must not be negative, got %d", o.ComponentConfig.PerClusterWorkerLimit))
+	}
+	if o.ComponentConfig.MetricsMaxVCCardinality < 0 {
+		errs = append(errs, fmt.Errorf("--metrics-max-vc-cardinality must not be negative, got %d", o.ComponentConfig.MetricsMaxVCCardinality))
+	}
+	if rate := o.ComponentConfig.Tracing.SamplingRatePerMillion; rate < 0 || rate > tracing.MaxSamplingRatePerMillion {
+		errs = append(errs, fmt.Errorf("--tracing-sampling-rate-per-million must be between 0 and %d, got %d", tracing.MaxSamplingRatePerMillion, rate))
+	}
+	if o.ComponentConfig.SyncMaxRetries < 1 {
+		errs = append(errs, fmt.Errorf("--sync-max-retries must be positive, got %d", o.ComponentConfig.SyncMaxRetries))
+	}
+	if base, max := o.ComponentConfig.SyncBaseDelay.Duration, o.ComponentConfig.SyncMaxDelay.Duration; base <= 0 {
+		errs = append(errs, fmt.Errorf("--sync-base-delay must be positive, got %v", base))
+	} else if max < base {
+		errs = append(errs, fmt.Errorf("--sync-max-delay must not be less than --sync-base-delay %v, got %v", base, max))
+	}
+	if o.ComponentConfig.DWSDeadLetterRetryThreshold < 0 {
+		errs = append(errs, fmt.Errorf("--dws-dead-letter-retry-threshold must not be negative, got %d", o.ComponentConfig.DWSDeadLetterRetryThreshold))
+	}
+	if o.ComponentConfig.ShutdownGracePeriod.Duration < 0 {
+		errs = append(errs, fmt.Errorf("--shutdown-grace-period must not be negative, got %v", o.ComponentConfig.ShutdownGracePeriod.Duration))
+	}
+	if o.ComponentConfig.NamespaceCreationMaxRetries < 0 {
+		errs = append(errs, fmt.Errorf("--namespace-creation-max-retries must not be negative, got %d", o.ComponentConfig.NamespaceCreationMaxRetries))
+	} else if o.ComponentConfig.NamespaceCreationMaxRetries > 0 && o.ComponentConfig.NamespaceCreationRetryPeriod.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("--namespace-creation-retry-period must be positive when namespace creation retries are enabled, got %v", o.ComponentConfig.NamespaceCreationRetryPeriod.Duration))
+	}
+	errs = append(errs, validateQoSToPriorityClass(o.ComponentConfig.QoSToPriorityClass)...)
+	for _, name := range o.ComponentConfig.SkipSyncServiceAccounts {
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid service account %q in --skip-sync-service-accounts: %s", name, strings.Join(msgs, "; ")))
+		}
+	}
+	errs = append(errs, o.validateServing()...)
+	errs = append(errs, validateClientRateLimit("super-master", o.ComponentConfig.ClientConnection)...)
+	errs = append(errs, validateClientRateLimit("meta-cluster", o.MetaClusterClientConnection)...)
+	if len(o.DNSOptionList) != 0 {
+		if o.flagChanged("dns-options") {
+			errs = append(errs, fmt.Errorf("--dns-option and --dns-options cannot be used together"))
+		}
+		if _, err := parseDNSOptions(o.DNSOptionList); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// enumFlag is a flag taking one of a fixed set of values.
+type enumFlag struct {
+	flag   string
+	value  string
+	values []string
+}
+
+// enumFlags returns the flags taking one of a fixed set of values, so that a typo fails at startup
+// instead of falling back to the behavior of an unknown value.
+func (o *ResourceSyncerOptions) enumFlags() []enumFlag {
+	c := o.ComponentConfig
+	return []enumFlag{
+		{"on-vc-readoption", c.OnVCReadoption, []string{syncerconstants.OnVCReadoptionConflict, syncerconstants.OnVCReadoptionRecreate, syncerconstants.OnVCReadoptionAdopt}},
+		{"existence-disagreement-policy", c.ExistenceDisagreementPolicy, []string{syncerconstants.ExistenceDisagreementConfirm, syncerconstants.ExistenceDisagreementTrustCache}},
+		{"on-cluster-scoped-conflict", c.OnClusterScopedConflict, []string{syncerconstants.ClusterScopedConflictAdopt, syncerconstants.ClusterScopedConflictSkip, syncerconstants.ClusterScopedConflictFail}},
+		{"on-name-too-long", c.OnNameTooLong, []string{syncerconstants.OnNameTooLongHash, syncerconstants.OnNameTooLongFail}},
+		{"unsupported-probe-policy", c.UnsupportedProbePolicy, []string{syncerconstants.UnsupportedProbePolicyReject, syncerconstants.UnsupportedProbePolicyDrop}},
+		{"startup-probe-policy", c.StartupProbePolicy, []string{syncerconstants.StartupProbePolicyKeep, syncerconstants.StartupProbePolicyFold}},
+		{"image-pull-policy-rewrite", c.ImagePullPolicyRewrite, []string{syncerconstants.ImagePullPolicyRewriteNone, syncerconstants.ImagePullPolicyRewriteForce, syncerconstants.ImagePullPolicyRewriteOverrideAlways}},
+		{"image-pull-policy", c.ImagePullPolicy, []string{string(corev1.PullAlways), string(corev1.PullIfNotPresent), string(corev1.PullNever)}},
+		{"on-super-object-deleted", c.OnSuperObjectDeleted, []string{syncerconstants.OnSuperObjectDeletedRecreate, syncerconstants.OnSuperObjectDeletedPropagate}},
+		{"on-persistent-watch-errors", c.OnPersistentWatchErrors, []string{syncerconstants.PersistentWatchErrorsDegrade, syncerconstants.PersistentWatchErrorsRestart}},
+	}
+}
+
+// validateLeaderElection checks the durations of the leader election the way the leader elector does,
+// so that a wrong combination fails at startup instead of when the leader election starts.
+func validateLeaderElection(config syncerconfig.SyncerLeaderElectionConfiguration) []error {
+	if !config.LeaderElect {
+		return nil
+	}
+	var errs []error
+	for _, d := range []struct {
+		flag     string
+		duration time.Duration
+	}{
+		{"--leader-elect-lease-duration", config.LeaseDuration.Duration},
+		{"--leader-elect-renew-deadline", config.RenewDeadline.Duration},
+		{"--leader-elect-retry-period", config.RetryPeriod.Duration},
+	} {
+		if d.duration <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %v", d.flag, d.duration))
+		}
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	if config.RenewDeadline.Duration >= config.LeaseDuration.Duration {
+		errs = append(errs, fmt.Errorf("--leader-elect-renew-deadline %v must be less than --leader-elect-lease-duration %v", config.RenewDeadline.Duration, config.LeaseDuration.Duration))
+	}
+	if retry := time.Duration(leaderelection.JitterFactor * float64(config.RetryPeriod.Duration)); config.RenewDeadline.Duration <= retry {
+		errs = append(errs, fmt.Errorf("--leader-elect-renew-deadline %v must be greater than %v times --leader-elect-retry-period %v", config.RenewDeadline.Duration, leaderelection.JitterFactor, config.RetryPeriod.Duration))
+	}
+	if err := validateWatchDogTimeout(config); err != nil {
+		errs = append(errs, err)
+	}
+	if !sets.NewString(resourceLocks...).Has(config.ResourceLock) {
+		errs = append(errs, fmt.Errorf("unknown --leader-elect-resource-lock %q, supported locks are %s", config.ResourceLock, strings.Join(resourceLocks, ", ")))
+	}
+	return errs
+}
+
+// resourceLocks are the supported leader election resource locks, the multi-locks holding both a
+// deprecated lock and the leases one.
+var resourceLocks = []string{
+	resourcelock.LeasesResourceLock,
+	resourcelock.EndpointsLeasesResourceLock,
+	resourcelock.ConfigMapsLeasesResourceLock,
+	resourcelock.EndpointsResourceLock,
+	resourcelock.ConfigMapsResourceLock,
+}
+
+// validateFeatureGates checks that the feature gates are known, KnownFeatures lists them as
+// "name=true|false (default=...)".
+func validateFeatureGates(gates map[string]bool) []error {
+	known := make(map[string]bool)
+	for _, f := range featuregate.DefaultFeatureGate.KnownFeatures() {
+		known[strings.SplitN(f, "=", 2)[0]] = true
+	}
+	var unknown []string
+	for k := range gates {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return []error{fmt.Errorf("unknown --feature-gates key(s): %s", strings.Join(unknown, ", "))}
+}
+
+// syncDirections are the directions accepted by --extra-syncing-resources name:direction entries.
+var syncDirections = sets.NewString(string(syncerconfig.SyncDirectionDown), string(syncerconfig.SyncDirectionUp), string(syncerconfig.SyncDirectionBoth))
+
+// validateExtraSyncingResources checks that the extra syncing resources are registered syncers, so
+// that a typo fails at startup instead of leaving the resource silently unsynced. The syncers enabled
+// by default are accepted as well, they are synced anyway. The resources are matched
+// case-insensitively, see normalizeExtraSyncingResources. An entry may set the sync direction of the
+// resource, e.g. ingress:down.
+func validateExtraSyncingResources(resources []string) []error {
+	known := registeredResources()
+	var errs []error
+	var unknown []string
+	for _, r := range resources {
+		name, direction := splitSyncDirection(r)
+		if !known.Has(syncerutil.NormalizeResourceName(name)) {
+			unknown = append(unknown, r)
+			continue
+		}
+		if direction != "" && !syncDirections.Has(direction) {
+			errs = append(errs, fmt.Errorf("unknown sync direction in --extra-syncing-resources value %q, valid directions are: %s", r, strings.Join(syncDirections.List(), ", ")))
+		}
+	}
+	if len(unknown) != 0 {
+		errs = append(errs, fmt.Errorf("unknown --extra-syncing-resources value(s): %s, valid values are: %s", strings.Join(unknown, ", "), strings.Join(known.List(), ", ")))
+	}
+	return errs
+}
+
+// validateSyncDirections checks the sync directions of the config file.
+func validateSyncDirections(directions map[string]syncerconfig.SyncDirection) []error {
+	known := registeredResources()
+	var errs []error
+	for _, id := range sets.StringKeySet(directions).List() {
+		if !known.Has(id) {
+			errs = append(errs, fmt.Errorf("unknown resource %q in syncDirections, valid values are: %s", id, strings.Join(known.List(), ", ")))
+			continue
+		}
+		if !syncDirections.Has(string(directions[id])) {
+			errs = append(errs, fmt.Errorf("unknown sync direction %q of resource %s in syncDirections, valid directions are: %s", directions[id], id, strings.Join(syncDirections.List(), ", ")))
+		}
+	}
+	return errs
+}
+
+// registeredResources returns the IDs of the registered resource syncers.
+func registeredResources() sets.String {
+	known := sets.NewString()
+	for _, r := range plugin.SyncerResourceRegister.List() {
+		known.Insert(r.ID)
+	}
+	return known
+}
+
+// normalizeExtraSyncingResources returns the extra syncing resources as the IDs of their syncers, e.g.
+// Ingress as ingress and pvc as persistentvolumeclaim, dropping the duplicates. The sync directions
+// are kept, lower-cased, the last one of a resource wins.
+func normalizeExtraSyncingResources(resources []string) []string {
+	normalized := make([]string, 0, len(resources))
+	index := make(map[string]int)
+	for _, r := range resources {
+		name, direction := splitSyncDirection(r)
+		id := syncerutil.NormalizeResourceName(name)
+		entry := id
+		if direction != "" {
+			entry += ":" + direction
+		}
+		if i, ok := index[id]; ok {
+			if direction != "" {
+				normalized[i] = entry
+			}
+			continue
+		}
+		index[id] = len(normalized)
+		normalized = append(normalized, entry)
+	}
+	return normalized
+}
+
+// splitSyncDirections returns the IDs of the normalized extra syncing resources and the sync
+// directions, those of the resources overriding the given ones, e.g. of the config file.
+func splitSyncDirections(resources []string, directions map[string]syncerconfig.SyncDirection) ([]string, map[string]syncerconfig.SyncDirection) {
+	ids := make([]string, 0, len(resources))
+	merged := make(map[string]syncerconfig.SyncDirection, len(directions))
+	for id, direction := range directions {
+		merged[id] = direction
+	}
+	for _, r := range resources {
+		id, direction := splitSyncDirection(r)
+		ids = append(ids, id)
+		if direction != "" {
+			merged[id] = syncerconfig.SyncDirection(direction)
+		}
+	}
+	return ids, merged
+}
+
+// splitSyncDirection splits an extra syncing resource into its name and its lower-cased sync
+// direction, empty if it has none.
+func splitSyncDirection(resource string) (string, string) {
+	parts := strings.SplitN(resource, ":", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], strings.ToLower(strings.TrimSpace(parts[1]))
+}
+
+// validateUncachedResources checks that the uncached resources support it and have no informer field
+// selector, which would create their informer anyway.
+func validateUncachedResources(resources []string, fieldSelectors map[string]string) []error {
+	supported := sets.NewString(syncerutil.UncachedResources()...)
+	var errs []error
+	for _, r := range resources {
+		if !supported.Has(r) {
+			errs = append(errs, fmt.Errorf("unsupported --uncached-resources value %q, supported values are: %s", r, strings.Join(supported.List(), ", ")))
+			continue
+		}
+		if _, ok := fieldSelectors[r]; ok {
+			errs = append(errs, fmt.Errorf("resource %s cannot have an --informer-field-selector and be in --uncached-resources", r))
+		}
+	}
+	return errs
+}
+
+// validateQoSToPriorityClass checks that the QoS classes exist and the priority class names are valid.
+func validateQoSToPriorityClass(qosToPriorityClass map[string]string) []error {
+	qosClasses := []string{string(corev1.PodQOSGuaranteed), string(corev1.PodQOSBurstable), string(corev1.PodQOSBestEffort)}
+	var errs []error
+	for _, qos := range sets.StringKeySet(qosToPriorityClass).List() {
+		if !sets.NewString(qosClasses...).Has(qos) {
+			errs = append(errs, fmt.Errorf("unknown QoS class %q in --qos-to-priority-class, supported classes are %s", qos, strings.Join(qosClasses, ", ")))
+			continue
+		}
+		if msgs := validation.IsDNS1123Subdomain(qosToPriorityClass[qos]); len(msgs) != 0 {
+			errs = append(errs, fmt.Errorf("invalid priority class %q for QoS class %s in --qos-to-priority-class: %s", qosToPriorityClass[qos], qos, strings.Join(msgs, "; ")))
+		}
+	}
+	return errs
+}
+
+// validateClientRateLimit checks the QPS and burst of the --<prefix>-qps and --<prefix>-burst flags.
+func validateClientRateLimit(prefix string, config componentbaseconfig.ClientConnectionConfiguration) []error {
+	var errs []error
+	if config.QPS < 0 {
+		errs = append(errs, fmt.Errorf("--%s-qps must not be negative, got %v", prefix, config.QPS))
+	}
+	if config.Burst < 0 {
+		errs = append(errs, fmt.Errorf("--%s-burst must not be negative, got %d", prefix, config.Burst))
+	}
+	return errs
+}
+
+// validateServing checks that the certificate and key of the serving endpoint are set together and
+// exist, and that they come with a port.
+func (o *ResourceSyncerOptions) validateServing() []error {
+	if o.CertFile == "" && o.KeyFile == "" {
+		return nil
+	}
+	var errs []error
+	if o.Port == "" {
+		errs = append(errs, fmt.Errorf("--port must be set when --cert-file and --key-file are set"))
+	}
+	for _, f := range []struct {
+		flag string
+		path string
+	}{
+		{"--cert-file", o.CertFile},
+		{"--key-file", o.KeyFile},
+	} {
+		if f.path == "" {
+			errs = append(errs, fmt.Errorf("--cert-file and --key-file must be set together, %s is missing", f.flag))
+			continue
+		}
+		if _, err := os.Stat(f.path); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s: %v", f.flag, err))
+		}
+	}
+	return errs
+}
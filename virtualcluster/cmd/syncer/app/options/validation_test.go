@@ -0,0 +1,458 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	for _, f := range []string{certFile, keyFile} {
+		if err := ioutil.WriteFile(f, []byte("pem"), 0600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	duration := func(d time.Duration) metav1.Duration {
+		return metav1.Duration{Duration: d}
+	}
+	// The syncers are registered by the imports of the syncer command.
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{ID: "ingress", Disable: true})
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{ID: "priorityclass", Disable: true})
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{ID: "persistentvolumeclaim"})
+
+	for _, tt := range []struct {
+		name           string
+		modify         func(o *ResourceSyncerOptions)
+		expectedErrors []string
+	}{
+		{
+			name:   "defaults",
+			modify: func(o *ResourceSyncerOptions) {},
+		},
+		{
+			name: "valid",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.LeaderElection.LeaseDuration = duration(30 * time.Second)
+				o.ComponentConfig.LeaderElection.RenewDeadline = duration(20 * time.Second)
+				o.ComponentConfig.LeaderElection.RetryPeriod = duration(5 * time.Second)
+				o.ComponentConfig.FeatureGates = map[string]bool{"SuperClusterPooling": true, "VNodeProviderService": false}
+				o.ComponentConfig.ExtraSyncingResources = []string{"Ingress", " priorityclass", "PVC", "persistentvolumeclaim"}
+				o.ComponentConfig.Timeout = "30s"
+				o.Logs.LogFormat = "json"
+				o.Port = "443"
+				o.CertFile = certFile
+				o.KeyFile = keyFile
+			},
+		},
+		{
+			name: "renew deadline longer than the lease duration",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.LeaderElection.RenewDeadline = duration(20 * time.Second)
+			},
+			expectedErrors: []string{"--leader-elect-renew-deadline 20s must be less than --leader-elect-lease-duration 15s"},
+		},
+		{
+			name: "renew deadline equal to the lease duration",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.LeaderElection.RenewDeadline = duration(15 * time.Second)
+			},
+			expectedErrors: []string{"must be less than --leader-elect-lease-duration"},
+		},
+		{
+			name: "retry period too long for the renew deadline",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.LeaderElection.RetryPeriod = duration(9 * time.Second)
+			},
+			expectedErrors: []string{"must be greater than 1.2 times --leader-elect-retry-period 9s"},
+		},
+		{
+			name: "non positive durations",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.LeaderElection.LeaseDuration = duration(0)
+				o.ComponentConfig.LeaderElection.RetryPeriod = duration(-time.Second)
+			},
+			expectedErrors: []string{
+				"--leader-elect-lease-duration must be positive",
+				"--leader-elect-retry-period must be positive",
+			},
+		},
+		{
+			name: "watchdog timeout",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.LeaderElection.WatchDogTimeout = duration(0)
+			},
+			expectedErrors: []string{"leader election watchdog timeout must be positive"},
+		},
+		{
+			name: "unknown resource lock",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.LeaderElection.ResourceLock = "leasesleases"
+			},
+			expectedErrors: []string{"unknown --leader-elect-resource-lock \"leasesleases\""},
+		},
+		{
+			name: "leader election disabled",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.LeaderElection.LeaderElect = false
+				o.ComponentConfig.LeaderElection.RenewDeadline = duration(20 * time.Second)
+			},
+		},
+		{
+			name: "unknown feature gates",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.FeatureGates = map[string]bool{"SuperClusterPooling": true, "SuperClusterPoolin": true, "Foo": false}
+			},
+			expectedErrors: []string{"unknown --feature-gates key(s): Foo, SuperClusterPoolin"},
+		},
+		{
+			name: "malformed super master timeout",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.Timeout = "30"
+			},
+			expectedErrors: []string{`invalid --super-master-timeout "30"`},
+		},
+		{
+			name: "unknown extra syncing resources",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.ExtraSyncingResources = []string{"ingres", "priorityClass", "pvcs"}
+			},
+			expectedErrors: []string{"unknown --extra-syncing-resources value(s): ingres, pvcs, valid values are: ingress, persistentvolumeclaim, priorityclass"},
+		},
+		{
+			name: "extra syncing resources with sync directions",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.ExtraSyncingResources = []string{"ingress:down", "priorityClass:UP", "pvc: both"}
+				o.ComponentConfig.SyncDirections = map[string]syncerconfig.SyncDirection{"persistentvolumeclaim": syncerconfig.SyncDirectionDown}
+			},
+		},
+		{
+			name: "unknown sync direction",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.ExtraSyncingResources = []string{"ingress:sideways", "ingres:down"}
+			},
+			expectedErrors: []string{
+				`unknown sync direction in --extra-syncing-resources value "ingress:sideways", valid directions are: both, down, up`,
+				"unknown --extra-syncing-resources value(s): ingres:down",
+			},
+		},
+		{
+			name: "unknown sync directions of the config file",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.SyncDirections = map[string]syncerconfig.SyncDirection{"ingress": "left", "ingres": syncerconfig.SyncDirectionDown}
+			},
+			expectedErrors: []string{
+				`unknown resource "ingres" in syncDirections`,
+				`unknown sync direction "left" of resource ingress in syncDirections`,
+			},
+		},
+		{
+			name: "uncached resources",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.UncachedResources = []string{"pod", "secret", "configmap"}
+				o.ComponentConfig.InformerFieldSelectors = map[string]string{"configmap": "metadata.name=foo"}
+			},
+			expectedErrors: []string{
+				`unsupported --uncached-resources value "pod", supported values are: configmap, secret`,
+				"resource configmap cannot have an --informer-field-selector and be in --uncached-resources",
+			},
+		},
+		{
+			name: "dns option without name",
+			modify: func(o *ResourceSyncerOptions) {
+				o.DNSOptionList = []string{"edns0", "=5"}
+			},
+			expectedErrors: []string{`invalid --dns-option "=5": missing name`},
+		},
+		{
+			name: "unknown enum values",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.OnVCReadoption = "keep"
+				o.ComponentConfig.ExistenceDisagreementPolicy = "trust"
+				o.ComponentConfig.OnClusterScopedConflict = "Adopt"
+				o.ComponentConfig.OnNameTooLong = "truncate"
+				o.ComponentConfig.UnsupportedProbePolicy = ""
+				o.ComponentConfig.StartupProbePolicy = "drop"
+				o.ComponentConfig.ImagePullPolicyRewrite = "always"
+				o.ComponentConfig.ImagePullPolicy = "always"
+				o.ComponentConfig.OnSuperObjectDeleted = "delete"
+				o.ComponentConfig.OnPersistentWatchErrors = "exit"
+			},
+			expectedErrors: []string{
+				`unknown --on-vc-readoption "keep", valid values are: conflict, recreate, adopt`,
+				`unknown --existence-disagreement-policy "trust", valid values are: confirm, trust-cache`,
+				`unknown --on-cluster-scoped-conflict "Adopt", valid values are: adopt, skip, fail`,
+				`unknown --on-name-too-long "truncate", valid values are: hash, fail`,
+				`unknown --unsupported-probe-policy "", valid values are: reject, drop`,
+				`unknown --startup-probe-policy "drop", valid values are: keep, fold`,
+				`unknown --image-pull-policy-rewrite "always", valid values are: none, force, override-always`,
+				`unknown --image-pull-policy "always", valid values are: Always, IfNotPresent, Never`,
+				`unknown --on-super-object-deleted "delete", valid values are: recreate, propagate`,
+				`unknown --on-persistent-watch-errors "exit", valid values are: degrade, restart`,
+			},
+		},
+		{
+			name: "invalid extra node labels",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.ExtraNodeLabels = []string{"node.example.com/*", "zone=us-east-1", "=x", "gpu=not valid", "bad key*"}
+			},
+			expectedErrors: []string{
+				`invalid --extra-node-labels "=x": missing label key`,
+				`invalid --extra-node-labels "gpu=not valid": invalid label value "not valid"`,
+				`invalid --extra-node-labels "bad key*": invalid label key "bad key*"`,
+			},
+		},
+		{
+			name: "invalid qos to priority class",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.QoSToPriorityClass = map[string]string{
+					"Guaranteed": "high-priority",
+					"Burstable":  "Not_Valid",
+					"guaranteed": "high-priority",
+				}
+			},
+			expectedErrors: []string{
+				`invalid priority class "Not_Valid" for QoS class Burstable in --qos-to-priority-class`,
+				`unknown QoS class "guaranteed" in --qos-to-priority-class, supported classes are Guaranteed, Burstable, BestEffort`,
+			},
+		},
+		{
+			name: "negative per cluster worker limit",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.PerClusterWorkerLimit = -1
+			},
+			expectedErrors: []string{"--per-cluster-worker-limit must not be negative, got -1"},
+		},
+		{
+			name: "negative metrics max vc cardinality",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.MetricsMaxVCCardinality = -1
+			},
+			expectedErrors: []string{"--metrics-max-vc-cardinality must not be negative, got -1"},
+		},
+		{
+			name: "tracing sampling rate above a million",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.Tracing.SamplingRatePerMillion = 1000001
+			},
+			expectedErrors: []string{"--tracing-sampling-rate-per-million must be between 0 and 1000000, got 1000001"},
+		},
+		{
+			name: "zero sync max retries",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.SyncMaxRetries = 0
+			},
+			expectedErrors: []string{"--sync-max-retries must be positive, got 0"},
+		},
+		{
+			name: "zero sync base delay",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.SyncBaseDelay = duration(0)
+			},
+			expectedErrors: []string{"--sync-base-delay must be positive, got 0s"},
+		},
+		{
+			name: "sync max delay below base delay",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.SyncBaseDelay = duration(time.Second)
+				o.ComponentConfig.SyncMaxDelay = duration(time.Millisecond)
+			},
+			expectedErrors: []string{"--sync-max-delay must not be less than --sync-base-delay 1s, got 1ms"},
+		},
+		{
+			name: "negative dead letter retry threshold",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.DWSDeadLetterRetryThreshold = -1
+			},
+			expectedErrors: []string{"--dws-dead-letter-retry-threshold must not be negative, got -1"},
+		},
+		{
+			name: "negative shutdown grace period",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.ShutdownGracePeriod = duration(-time.Second)
+			},
+			expectedErrors: []string{"--shutdown-grace-period must not be negative, got -1s"},
+		},
+		{
+			name: "unknown logging format",
+			modify: func(o *ResourceSyncerOptions) {
+				o.Logs.LogFormat = "yaml"
+			},
+			expectedErrors: []string{"unsupported log format: yaml"},
+		},
+		{
+			name: "negative namespace creation retries",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.NamespaceCreationMaxRetries = -1
+			},
+			expectedErrors: []string{"--namespace-creation-max-retries must not be negative, got -1"},
+		},
+		{
+			name: "namespace creation retries without period",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.NamespaceCreationRetryPeriod.Duration = 0
+			},
+			expectedErrors: []string{"--namespace-creation-retry-period must be positive"},
+		},
+		{
+			name: "invalid skipped service account",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.SkipSyncServiceAccounts = []string{"default", "Builder"}
+			},
+			expectedErrors: []string{"invalid service account \"Builder\" in --skip-sync-service-accounts"},
+		},
+		{
+			name: "negative client rate limits",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.ClientConnection.QPS = -1
+				o.MetaClusterClientConnection.Burst = -1
+			},
+			expectedErrors: []string{"--super-master-qps must not be negative", "--meta-cluster-burst must not be negative"},
+		},
+		{
+			name: "cert file without key file",
+			modify: func(o *ResourceSyncerOptions) {
+				o.CertFile = certFile
+			},
+			expectedErrors: []string{"--key-file is missing"},
+		},
+		{
+			name: "missing cert and key files",
+			modify: func(o *ResourceSyncerOptions) {
+				o.CertFile = filepath.Join(dir, "missing.crt")
+				o.KeyFile = filepath.Join(dir, "missing.key")
+			},
+			expectedErrors: []string{"invalid --cert-file", "invalid --key-file"},
+		},
+		{
+			name: "cert and key files without port",
+			modify: func(o *ResourceSyncerOptions) {
+				o.Port = ""
+				o.CertFile = certFile
+				o.KeyFile = keyFile
+			},
+			expectedErrors: []string{"--port must be set"},
+		},
+		{
+			name: "all at once",
+			modify: func(o *ResourceSyncerOptions) {
+				o.ComponentConfig.LeaderElection.RenewDeadline = duration(20 * time.Second)
+				o.ComponentConfig.FeatureGates = map[string]bool{"Foo": true}
+				o.ComponentConfig.ExtraSyncingResources = []string{"crds"}
+				o.ComponentConfig.Timeout = "soon"
+				o.Port = ""
+				o.KeyFile = keyFile
+			},
+			expectedErrors: []string{
+				"must be less than --leader-elect-lease-duration",
+				"unknown --feature-gates key(s): Foo",
+				"unknown --extra-syncing-resources value(s): crds",
+				"invalid --super-master-timeout",
+				"--port must be set",
+				"--cert-file is missing",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := NewResourceSyncerOptions()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.modify(o)
+
+			err = o.Validate()
+			if len(tt.expectedErrors) == 0 {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			agg, ok := err.(utilerrors.Aggregate)
+			if !ok {
+				t.Fatalf("expected an aggregate error, got %v", err)
+			}
+			if len(agg.Errors()) != len(tt.expectedErrors) {
+				t.Errorf("expected %d errors, got %v", len(tt.expectedErrors), err)
+			}
+			for _, expected := range tt.expectedErrors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error containing %q, got %v", expected, err)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeExtraSyncingResources(t *testing.T) {
+	got := normalizeExtraSyncingResources([]string{"Ingress", " priorityClass ", "ingress", "CRD", "pvc", "PersistentVolumeClaim"})
+	if expected := []string{"ingress", "priorityclass", "crd", "persistentvolumeclaim"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	got = normalizeExtraSyncingResources([]string{"Ingress:Down", "crd", "PVC:up", "ingress", "persistentvolumeclaim:both"})
+	if expected := []string{"ingress:down", "crd", "persistentvolumeclaim:both"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestSplitSyncDirections(t *testing.T) {
+	ids, directions := splitSyncDirections([]string{"ingress:down", "crd", "priorityclass:up"}, map[string]syncerconfig.SyncDirection{
+		"ingress":   syncerconfig.SyncDirectionUp,
+		"configmap": syncerconfig.SyncDirectionDown,
+	})
+	if expected := []string{"ingress", "crd", "priorityclass"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected the resources %v, got %v", expected, ids)
+	}
+	expected := map[string]syncerconfig.SyncDirection{
+		"ingress":       syncerconfig.SyncDirectionDown,
+		"configmap":     syncerconfig.SyncDirectionDown,
+		"priorityclass": syncerconfig.SyncDirectionUp,
+	}
+	if !reflect.DeepEqual(directions, expected) {
+		t.Errorf("expected the directions %v, got %v", expected, directions)
+	}
+}
+
+func TestValidateDNSOptionFlags(t *testing.T) {
+	o, err := NewResourceSyncerOptions()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fs := pflag.NewFlagSet("syncer", pflag.ContinueOnError)
+	for _, f := range o.Flags().FlagSets {
+		fs.AddFlagSet(f)
+	}
+	if err := fs.Parse([]string{"--dns-options=ndots=2", "--dns-option=edns0"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := o.Validate(); err == nil || !strings.Contains(err.Error(), "--dns-option and --dns-options cannot be used together") {
+		t.Errorf("expected the dns option flags to be rejected together, got %v", err)
+	}
+}
@@ -18,11 +18,13 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	_ "net/http/pprof" // enable pprof in the server
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apiserver/pkg/server/healthz"
@@ -36,10 +38,25 @@ import (
 	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/cmd/syncer/app/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/cmd/syncer/app/options"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion/selftest"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/drain"
 	utilflag "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/flag"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/version/verflag"
 )
 
+// ErrDrainIncomplete is returned by Run when the shutdown grace period expired before the requests
+// in flight finished. The syncer then exits with ExitCodeDrainIncomplete.
+var ErrDrainIncomplete = errors.New("shutdown grace period expired before the in-flight requests finished")
+
+// ErrRestart is returned by Run when the syncer asked to be restarted, e.g. to recreate its failing
+// informers, once it is stopped.
+var ErrRestart = errors.New("the syncer asked to be restarted")
+
+// ExitCodeDrainIncomplete is the exit code of a syncer stopped before its in-flight requests
+// finished. A syncer that drained them exits with 0, and with 1 on any other error.
+const ExitCodeDrainIncomplete = 2
+
 func NewSyncerCommand(stopChan <-chan struct{}) *cobra.Command {
 	s, err := options.NewResourceSyncerOptions()
 	if err != nil {
@@ -57,6 +74,10 @@ custom resources on behalf of the tenant users in super cluster.`,
 			verflag.PrintAndExitIfRequested()
 			utilflag.PrintFlags(cmd.Flags())
 
+			if s.SelfTestConversion {
+				os.Exit(runConversionSelfTest(&s.ComponentConfig))
+			}
+
 			c, err = s.Config()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
@@ -65,6 +86,9 @@ custom resources on behalf of the tenant users in super cluster.`,
 
 			if err := Run(c.Complete(), stopChan); err != nil {
 				fmt.Fprintf(os.Stderr, "%v\n", err)
+				if errors.Is(err, ErrDrainIncomplete) {
+					os.Exit(ExitCodeDrainIncomplete)
+				}
 				os.Exit(1)
 			}
 		},
@@ -93,12 +117,29 @@ custom resources on behalf of the tenant users in super cluster.`,
 	return cmd
 }
 
+// runConversionSelfTest round-trips the conversion self test corpus and returns the process exit code.
+func runConversionSelfTest(syncerConfig *config.SyncerConfiguration) int {
+	results, err := selftest.Run(syncerConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "conversion self test: %v\n", err)
+		return 1
+	}
+	report, failed := selftest.Report(results)
+	fmt.Fprint(os.Stdout, report)
+	if failed != 0 {
+		fmt.Fprintf(os.Stderr, "conversion self test: %d of %d object(s) lost fields\n", failed, len(results))
+		return 1
+	}
+	return 0
+}
+
 func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 	ss, err := syncer.New(&cc.ComponentConfig,
 		cc.VirtualClusterClient,
 		cc.VirtualClusterInformer,
 		cc.MetaClusterClient,
 		cc.SuperClusterClient,
+		cc.Kubeconfig,
 		cc.SuperClusterInformerFactory,
 		cc.Recorder)
 
@@ -111,6 +152,11 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 		cc.Broadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: cc.SuperClusterClient.CoreV1().Events("")})
 	}
 
+	// a syncer asking to be restarted is stopped like on a termination signal, so that the requests
+	// in flight are drained and the leadership is released before it exits.
+	restart := ss.Restart()
+	stopCh = stopOrRestart(stopCh, restart)
+
 	// Start all informers.
 	go cc.VirtualClusterInformer.Informer().Run(stopCh)
 	cc.SuperClusterInformerFactory.Start(stopCh)
@@ -124,9 +170,15 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 	// Prepare a reusable runCommand function.
 	run := startSyncer(ss, stopCh)
 
+	// drained receives whether the in-flight requests finished within the grace period once the
+	// syncer is stopped.
+	drained := make(chan bool, 1)
 	go func() {
 		select {
 		case <-stopCh:
+			// drain before canceling, the leadership is released only once the requests in
+			// flight are done so that the next leader does not sync the same objects meanwhile.
+			drained <- drainInFlight(cc.ComponentConfig.ShutdownGracePeriod.Duration)
 			cancel()
 		case <-ctx.Done():
 		}
@@ -140,7 +192,11 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 	go func() {
 		// start a health http server.
 		mux := http.NewServeMux()
-		healthz.InstallHandler(mux)
+		checks := []healthz.HealthChecker{healthz.PingHealthz}
+		if cc.LeaderElection != nil {
+			checks = append(checks, cc.LeaderElection.WatchDog)
+		}
+		healthz.InstallHandler(mux, checks...)
 		klog.Fatal(http.ListenAndServe(":8080", mux))
 	}()
 
@@ -152,6 +208,13 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 		cc.LeaderElection.Callbacks = leaderelection.LeaderCallbacks{
 			OnStartedLeading: run,
 			OnStoppedLeading: func() {
+				if ctx.Err() != nil {
+					// stopped, the requests in flight are already drained.
+					return
+				}
+				// the controllers keep running after a lost leadership, stop them from syncing
+				// along with the new leader.
+				drainInFlight(cc.ComponentConfig.ShutdownGracePeriod.Duration)
 				klog.Fatalf("leaderelection lost")
 			},
 		}
@@ -161,13 +224,46 @@ func Run(cc *syncerconfig.CompletedConfig, stopCh <-chan struct{}) error {
 		}
 
 		leaderElector.Run(ctx)
+	} else {
+		// Leader election is disabled, so runCommand inline until done.
+		run(ctx)
+	}
 
-		return fmt.Errorf("lost lease")
+	if !<-drained {
+		return ErrDrainIncomplete
+	}
+	select {
+	case <-restart:
+		return ErrRestart
+	default:
 	}
+	return nil
+}
 
-	// Leader election is disabled, so runCommand inline until done.
-	run(ctx)
-	return fmt.Errorf("finished without leader elect")
+// stopOrRestart returns a channel closed once stopCh or restart is closed.
+func stopOrRestart(stopCh, restart <-chan struct{}) <-chan struct{} {
+	stop := make(chan struct{})
+	go func() {
+		defer close(stop)
+		select {
+		case <-stopCh:
+		case <-restart:
+			klog.Infof("stopping the syncer to restart it")
+		}
+	}()
+	return stop
+}
+
+// drainInFlight stops the syncing controllers from taking new requests and waits for the requests
+// in flight to finish, at most for the grace period. It returns whether they all finished.
+func drainInFlight(gracePeriod time.Duration) bool {
+	klog.Infof("draining %d in-flight requests for up to %v", drain.DefaultTracker.InFlight(), gracePeriod)
+	if drain.DefaultTracker.Drain(gracePeriod) {
+		klog.Infof("drained in-flight requests")
+		return true
+	}
+	klog.Warningf("shutdown grace period %v expired with %d requests in flight", gracePeriod, drain.DefaultTracker.InFlight())
+	return false
 }
 
 func startSyncer(s syncer.Bootstrap, stopCh <-chan struct{}) func(context.Context) {
@@ -16,6 +16,7 @@ require (
 	github.com/spf13/pflag v1.0.5
 	go.uber.org/zap v1.17.0
 	golang.org/x/net v0.17.0
+	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba
 	k8s.io/api v0.21.9
 	k8s.io/apiextensions-apiserver v0.21.9
 	k8s.io/apimachinery v0.21.9
@@ -30,3 +30,12 @@ bash "${CODEGEN_PKG}"/generate-groups.sh "deepcopy,client,informer,lister" \
   tenancy:v1alpha1 \
   --output-base "$(dirname "${BASH_SOURCE[0]}")/../../../../.." \
   --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
+
+# the syncer component config, its internal and versioned types are in the same apis dir.
+bash "${CODEGEN_PKG}"/generate-internal-groups.sh "deepcopy,conversion,defaulter" \
+  sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/unused \
+  sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis \
+  sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis \
+  config:v1alpha1 \
+  --output-base "$(dirname "${BASH_SOURCE[0]}")/../../../../.." \
+  --go-header-file "${SCRIPT_ROOT}"/hack/boilerplate.go.txt
@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheme
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+
+	syncerconfig "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	syncerconfigv1alpha1 "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config/v1alpha1"
+)
+
+var (
+	// Scheme is the scheme of the syncer config file, with the internal and the versioned
+	// SyncerConfiguration, their defaults and their conversions.
+	Scheme = runtime.NewScheme()
+
+	// Codecs decode the syncer config file strictly, i.e. reject its unknown and duplicate fields.
+	Codecs = serializer.NewCodecFactory(Scheme, serializer.EnableStrict)
+)
+
+func init() {
+	utilruntime.Must(syncerconfig.AddToScheme(Scheme))
+	utilruntime.Must(syncerconfigv1alpha1.AddToScheme(Scheme))
+	utilruntime.Must(Scheme.SetVersionPriority(syncerconfigv1alpha1.SchemeGroupVersion))
+}
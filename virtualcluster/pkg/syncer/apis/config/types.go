@@ -19,20 +19,24 @@ package config
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/rest"
 	componentbaseconfig "k8s.io/component-base/config"
 )
 
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // SyncerConfiguration configures a syncer. It is read only during syncer life cycle.
 type SyncerConfiguration struct {
-	metav1.TypeMeta
+	metav1.TypeMeta `json:",inline"`
 
 	// LeaderElection defines the configuration of leader election client.
-	LeaderElection SyncerLeaderElectionConfiguration
+	LeaderElection SyncerLeaderElectionConfiguration `json:"leaderElection"`
 
 	// ClientConnection specifies the kubeconfig file and client connection
 	// settings for the proxy server to use when communicating with the apiserver.
-	ClientConnection componentbaseconfig.ClientConnectionConfiguration
+	ClientConnection componentbaseconfig.ClientConnectionConfiguration `json:"clientConnection"`
+
+	// Tracing configures the OpenTelemetry tracing of the dws and uws reconciles.
+	Tracing TracingConfiguration `json:"tracing"`
 
 	// DefaultOpaqueMetaDomains is the default configuration for each Virtual Cluster.
 	// The key prefix of labels or annotations match this domain would be invisible to Virtual Cluster but
@@ -44,57 +48,449 @@ type SyncerConfiguration struct {
 	// ["foo.kubernetes.io"]    | ["foo=bar", "foo.kubernetes.io/foo=foo", "foo.kubernetes.io/a=b"]
 	// ["kubernetes.io"]        | ["foo=bar", "foo.kubernetes.io/foo=foo", "foo.kubernetes.io/a=b", "a.kubernetes.io/b=c"]
 	// ["aaa"]                  | ["foo=bar", "foo.kubernetes.io/foo=bar", "aaa/b=c"]
-	DefaultOpaqueMetaDomains []string
+	DefaultOpaqueMetaDomains []string `json:"defaultOpaqueMetaDomains"`
+
+	// DWSAnnotationPassthrough lists annotation keys that are passed through from tenant objects to
+	// their super cluster objects unchanged although they match DefaultOpaqueMetaDomains, e.g. the
+	// annotations consumed by a timezone injector of the super cluster.
+	DWSAnnotationPassthrough []string `json:"dwsAnnotationPassthrough"`
+
+	// UWSMetadataAllowlist lists the label and annotation key prefixes that may be back populated from
+	// super cluster objects to tenant objects. A key has to match VC.Spec.TransparentMetaPrefixes and,
+	// if UWSMetadataAllowlist is not empty, a prefix in it, so that the super cluster keeps its
+	// internal metadata, e.g. scheduler hints, out of the tenant objects. The keys of the
+	// transparency.tenancy.x-k8s.io prefix, set by the syncer itself, are always allowed.
+	UWSMetadataAllowlist []string `json:"uwsMetadataAllowlist"`
 
 	// ExtraSyncingResources defines additional resources that need to be synced for each Virtual Cluster
-	ExtraSyncingResources []string
+	ExtraSyncingResources []string `json:"extraSyncingResources"`
+
+	// SyncDirections maps the IDs of resource syncers, e.g. ingress, to the directions they sync.
+	// The resources missing from it are synced in both directions. It is set from the name:direction
+	// entries of ExtraSyncingResources as well.
+	SyncDirections map[string]SyncDirection `json:"syncDirections,omitempty"`
+
+	// RequireRBAC indicates whether the syncer refuses to start when it misses super cluster
+	// permissions of the enabled syncers. The missing permissions are logged at startup either way.
+	RequireRBAC bool `json:"requireRBAC"`
+
+	// PruneOnFeatureDisable indicates whether to delete, at startup, the super cluster objects synced
+	// by the opt-in syncers that are not enabled anymore, e.g. deployments after deployment is
+	// removed from ExtraSyncingResources, or the scoped RBAC objects after the ScopedTenantClusterRBAC
+	// feature gate is turned off. Otherwise they are left behind in the super cluster.
+	PruneOnFeatureDisable bool `json:"pruneOnFeatureDisable"`
 
 	// DisableServiceAccountToken indicates whether to disable super cluster service account tokens being auto generated
 	// and mounted in vc pods.
-	DisableServiceAccountToken bool
+	DisableServiceAccountToken bool `json:"disableServiceAccountToken"`
+
+	// AllowPodServiceAccountTokenAutomount indicates whether tenant pods explicitly setting
+	// automountServiceAccountToken to true still get the super cluster service account token
+	// when DisableServiceAccountToken is set. Pods that do not set it are not affected.
+	AllowPodServiceAccountTokenAutomount bool `json:"allowPodServiceAccountTokenAutomount"`
 
 	// DisablePodServiceLinks indicates whether to disable the `EnableServiceLinks` field in pPod spec.
 	// Defaults to false, it won‘t mutate the EnableServiceLinks field in pPod spec.
 	// If set to true, it will disable service links for all of the pPods to avoid massive env injections
 	// from syncer which replace the kubelet generated envs.
-	DisablePodServiceLinks bool
+	// Tenants can override this setting per pod or per namespace with the
+	// `tenancy.x-k8s.io/disable.podServiceLinks` annotation, see doc/pod-service-links.md.
+	DisablePodServiceLinks bool `json:"disablePodServiceLinks"`
+
+	// PreserveTenantCreationTimestamp indicates whether to record the creationTimestamp of the tenant
+	// object in the tenancy.x-k8s.io/creationTimestamp annotation of the synced super cluster object,
+	// since the creationTimestamp field itself is set by the super cluster apiserver.
+	PreserveTenantCreationTimestamp bool `json:"preserveTenantCreationTimestamp"`
+
+	// MaxContainersPerPod is the maximum number of containers, counting regular, init and ephemeral
+	// containers, that a tenant pod may have to be synced to the super cluster. Pods over the limit
+	// are not created and a warning event is sent to the tenant. 0 means no limit.
+	// It can be overridden per Virtual Cluster by the tenancy.x-k8s.io/max-containers-per-pod annotation.
+	MaxContainersPerPod int32 `json:"maxContainersPerPod"`
+
+	// MaxPodCommandBytes is the maximum total size in bytes of the command, args and env of all
+	// containers, counting regular, init and ephemeral containers, that a tenant pod may have to be
+	// synced to the super cluster. Pods over the limit are not created and a warning event is sent to
+	// the tenant. 0 means no limit.
+	// It can be overridden per Virtual Cluster by the tenancy.x-k8s.io/max-pod-command-bytes annotation.
+	MaxPodCommandBytes int64 `json:"maxPodCommandBytes"`
+
+	// ProvisionSuperNamespaceQuota indicates whether the namespace syncer provisions a ResourceQuota,
+	// labelled tenancy.x-k8s.io/managed-resource-quota, in each synced super cluster namespace, to cap
+	// the super cluster footprint of a Virtual Cluster independently of the tenant quotas.
+	ProvisionSuperNamespaceQuota bool `json:"provisionSuperNamespaceQuota"`
+
+	// SuperNamespaceQuota is the resource to quantity hard limits of the provisioned ResourceQuotas, e.g.
+	// pods=50. It can be overridden per Virtual Cluster by the tenancy.x-k8s.io/super-namespace-quota
+	// annotation.
+	SuperNamespaceQuota map[string]string `json:"superNamespaceQuota"`
+
+	// ForcePodNonPreempting indicates whether the preemptionPolicy of all synced pods is set to Never,
+	// so that tenant pods never preempt the pods of other tenants in the shared super cluster.
+	ForcePodNonPreempting bool `json:"forcePodNonPreempting"`
+
+	// QoSToPriorityClass maps the QoS classes of tenant pods, Guaranteed, Burstable or BestEffort, to
+	// the super cluster priority classes of their super pods, e.g. Guaranteed=high-priority, so that
+	// the super cluster evicts and preempts them in this order. The tenant pods of the other QoS
+	// classes keep their priority class.
+	QoSToPriorityClass map[string]string `json:"qosToPriorityClass"`
+
+	// DisableEphemeralContainersSync indicates whether to stop adding the ephemeral containers of the
+	// tenant pods, e.g. the ones of kubectl debug, to the super pods, for super clusters that do not
+	// serve the pods/ephemeralcontainers subresource.
+	DisableEphemeralContainersSync bool `json:"disableEphemeralContainersSync"`
+
+	// DefaultNotReadyTolerationSeconds is the tolerationSeconds of the node.kubernetes.io/not-ready:NoExecute
+	// toleration added to the synced pods that do not tolerate the taint already. 0 disables it.
+	DefaultNotReadyTolerationSeconds int64 `json:"defaultNotReadyTolerationSeconds"`
+
+	// DefaultUnreachableTolerationSeconds is the tolerationSeconds of the node.kubernetes.io/unreachable:NoExecute
+	// toleration added to the synced pods that do not tolerate the taint already. 0 disables it.
+	DefaultUnreachableTolerationSeconds int64 `json:"defaultUnreachableTolerationSeconds"`
+
+	// UnsupportedProbePolicy decides what happens to tenant pods with probes of a type the syncer
+	// does not know, such as grpc, which reach the syncer without a handler. "reject" (the default)
+	// leaves the pod unsynced with a warning event, "drop" syncs the pod without these probes.
+	UnsupportedProbePolicy string `json:"unsupportedProbePolicy"`
+
+	// StartupProbePolicy decides how the startup probes of tenant pods are synced. "keep" (the
+	// default) syncs them unchanged, "fold" removes them and delays the liveness probe of their
+	// container by initialDelaySeconds + failureThreshold * periodSeconds of the startup probe, for
+	// super clusters whose apiserver drops startup probes, e.g. with the StartupProbe feature gate
+	// disabled.
+	StartupProbePolicy string `json:"startupProbePolicy"`
+
+	// OnNameTooLong decides what happens when a super control plane name derived from the tenant,
+	// such as the "<cluster key>-<tenant namespace>" namespace name, exceeds the length limit of
+	// its kind. "hash" (the default) shortens the name with a hash suffix, the tenant name being
+	// kept in the annotations, "fail" leaves the object unsynced.
+	OnNameTooLong string `json:"onNameTooLong"`
+
+	// OnVCReadoption decides what happens to a super control plane namespace synced for a deleted
+	// Virtual Cluster, detected by its Virtual Cluster uid annotation, when a Virtual Cluster with
+	// the same cluster key (e.g. the same status.clusterNamespace) syncs a tenant namespace of the
+	// same name. "conflict" (the default) leaves it in place and fails the sync of the tenant
+	// namespace, "recreate" deletes and recreates it and "adopt" re-stamps it and the objects synced
+	// into it to the new Virtual Cluster.
+	OnVCReadoption string `json:"onVCReadoption"`
+
+	// NamespaceCreationMaxRetries is the number of times the creation of a super control plane
+	// namespace failing transiently, e.g. due to an admission webhook timeout, is retried by the work
+	// queue of the namespace syncer before the Virtual Cluster gets a NamespaceCreationFailed
+	// condition. 0 disables the conditions.
+	NamespaceCreationMaxRetries int `json:"namespaceCreationMaxRetries"`
+
+	// NamespaceCreationRetryPeriod is the delay before the first retry of a failing namespace
+	// request, doubled with every retry up to SyncMaxDelay.
+	NamespaceCreationRetryPeriod metav1.Duration `json:"namespaceCreationRetryPeriod"`
+
+	// ExistenceDisagreementPolicy decides what the periodic checkers do when an object is missing
+	// from the informer cache of the side authoritative for its existence, the tenant control plane
+	// for the downward synced resources and the super cluster for the upward synced ones, but
+	// still has a copy on the other side. "confirm" (the default) reads the object from the
+	// authoritative apiserver first and keeps the copy if the object exists, as the informer cache
+	// lags behind, "trust-cache" deletes the copy right away.
+	ExistenceDisagreementPolicy string `json:"existenceDisagreementPolicy"`
+
+	// OnSuperObjectDeleted decides what the pod checker does when the super pod of a tenant pod is
+	// deleted out of band, e.g. by a super cluster operator. "recreate" (the default) recreates it
+	// if the tenant pod is not scheduled yet, "propagate" deletes the tenant pod instead, making
+	// the super cluster deletion authoritative. A scheduled tenant pod is deleted either way, as it
+	// cannot move to the node of a new super pod.
+	OnSuperObjectDeleted string `json:"onSuperObjectDeleted"`
+
+	// PodMutatorOrder is the order of the pod mutation pipeline, a list of pod mutator plugin IDs
+	// and PodMutateDefault for the default conversion. The listed mutators run first, in the given
+	// order, followed by the other mutator plugins in the order of their IDs and the default
+	// conversion. Empty runs the mutator plugins in the order of their IDs, then the default
+	// conversion.
+	PodMutatorOrder []string `json:"podMutatorOrder"`
+
+	// DisabledPodMutators lists the pod mutator plugin IDs left out of the pod mutation pipeline. A
+	// Virtual Cluster can enable or disable mutator plugins for itself with the
+	// tenancy.x-k8s.io/enabled-pod-mutators and tenancy.x-k8s.io/disabled-pod-mutators annotations.
+	// The default conversion cannot be disabled.
+	DisabledPodMutators []string `json:"disabledPodMutators"`
+
+	// InformerFieldSelectors are the field selectors of the super cluster informers by resource, e.g.
+	// event: type!=Normal. The informer of a resource lists and watches the objects matching its
+	// selector only. Only the resources whose super cluster objects are never checked for existence,
+	// i.e. event and node, can be filtered.
+	InformerFieldSelectors map[string]string `json:"informerFieldSelectors"`
+
+	// UncachedResources lists the resources, e.g. secret, whose syncers get and list the super cluster
+	// objects from the apiserver, the lists page by page, instead of caching all of them in an informer.
+	// It trades the latency of the syncing and the load of the apiserver for the memory of the syncer.
+	UncachedResources []string `json:"uncachedResources"`
+
+	// OnClusterScopedConflict decides what the upward syncer does when a tenant control plane
+	// already has a cluster scoped object, such as a PriorityClass or a StorageClass, with the name
+	// of a super control plane public object but not synced from it, i.e. created by the tenant.
+	// "adopt" (the default) overwrites it with the super object and marks it as synced, "skip"
+	// leaves it untouched and "fail" leaves it untouched and fails the sync.
+	OnClusterScopedConflict string `json:"onClusterScopedConflict"`
+
+	// ImagePullPolicyRewrite decides whether the imagePullPolicy of the super pod containers is
+	// rewritten to ImagePullPolicy, e.g. for pre-pulled images in air-gapped environments. "none"
+	// (the default) keeps the tenant value, "force" rewrites all containers and "override-always"
+	// only rewrites the containers whose tenant imagePullPolicy is Always. The tenant pod is not
+	// changed. Both settings can be overridden per Virtual Cluster by the
+	// tenancy.x-k8s.io/image-pull-policy-rewrite and tenancy.x-k8s.io/image-pull-policy annotations.
+	ImagePullPolicyRewrite string `json:"imagePullPolicyRewrite"`
+
+	// ImagePullPolicy is the imagePullPolicy set by ImagePullPolicyRewrite, IfNotPresent if empty.
+	ImagePullPolicy string `json:"imagePullPolicy"`
+
+	// SuperClusterIPFamilies are the IP families of the super cluster service network, IPv4 and/or
+	// IPv6, primary first. Tenant services are then created with the families of their ipFamilies
+	// the super cluster supports, and services the super cluster cannot satisfy, e.g. requiring
+	// dual-stack on a single-stack super cluster, are not synced. Empty passes the ipFamilies and
+	// ipFamilyPolicy of tenant services unchanged.
+	SuperClusterIPFamilies []string `json:"superClusterIPFamilies"`
+
+	// DefaultWindowsRunAsUserName is the windowsOptions.runAsUserName set on the securityContext of
+	// synced Windows pods, i.e. pods selecting kubernetes.io/os=windows nodes or having Windows
+	// options, when neither the pod nor its containers specify one. Empty disables it.
+	DefaultWindowsRunAsUserName string `json:"defaultWindowsRunAsUserName"`
+
+	// AllowedWindowsRunAsUserNames are the windowsOptions.runAsUserName values tenant pods and their
+	// containers may use, compared case insensitively. Pods using other users are not synced.
+	// Empty allows all users.
+	AllowedWindowsRunAsUserNames []string `json:"allowedWindowsRunAsUserNames"`
+
+	// SkipSyncServiceAccounts are the names of the tenant service accounts, such as "default", that
+	// are not created or deleted in the super cluster, to not conflict with the super cluster
+	// service account controller creating them in every namespace. Their super cluster counterpart
+	// created by that controller is adopted instead, and kept in line with the automountServiceAccountToken
+	// and imagePullSecrets of the tenant service account.
+	SkipSyncServiceAccounts []string `json:"skipSyncServiceAccounts,omitempty"`
+
+	// DefaultAppArmorProfile is the AppArmor profile, in the legacy annotation format (runtime/default,
+	// unconfined or localhost/<name>), applied to the containers of pPods whose tenant pod specifies none.
+	// Empty means no default profile is applied.
+	DefaultAppArmorProfile string `json:"defaultAppArmorProfile"`
+
+	// ObjectCountQuotaPausePeriod is how long the syncer stops creating objects of a resource type for a
+	// Virtual Cluster after the super cluster rejected one of them due to an object count quota
+	// (e.g. count/configmaps). The rejected object is retried after the period. 0 disables the pause and
+	// the rejected object is retried with backoff like the other failures.
+	ObjectCountQuotaPausePeriod metav1.Duration `json:"objectCountQuotaPausePeriod"`
+
+	// DWSMaxConcurrentReconcilesPerCluster caps the number of workers of a downward syncing controller
+	// that can reconcile requests of the same virtual cluster at the same time, so that one busy tenant
+	// cannot starve the others. 0 means no per cluster limit.
+	DWSMaxConcurrentReconcilesPerCluster int `json:"dwsMaxConcurrentReconcilesPerCluster"`
+
+	// PerClusterWorkerLimit caps the number of workers of all the downward syncing controllers together
+	// that can reconcile requests of the same virtual cluster at the same time, so that onboarding a
+	// large tenant does not take the workers of every controller. 0 means no limit.
+	PerClusterWorkerLimit int `json:"perClusterWorkerLimit"`
+
+	// ShutdownGracePeriod is how long the syncer waits for the in-flight reconciles of its downward and
+	// upward syncing controllers to finish when it is stopped or loses its leadership, after they stop
+	// taking new requests. It should be shorter than the termination grace period of the syncer pod.
+	// 0 exits right away.
+	ShutdownGracePeriod metav1.Duration `json:"shutdownGracePeriod"`
+
+	// DWSOnboardingMaxConcurrentReconciles caps the number of workers of a downward syncing controller
+	// that can reconcile requests of a newly added virtual cluster at the same time, until all its
+	// existing tenant objects have been reconciled once, so that onboarding a large tenant does not
+	// overwhelm the super cluster. 0 disables the onboarding limit.
+	DWSOnboardingMaxConcurrentReconciles int `json:"dwsOnboardingMaxConcurrentReconciles"`
+
+	// DWSOnboardingRampUpPeriod is how often the onboarding limit doubles. 0 keeps it constant.
+	DWSOnboardingRampUpPeriod metav1.Duration `json:"dwsOnboardingRampUpPeriod"`
+
+	// SyncMaxRetries is the number of retries after which a failing request is taken out of the work
+	// queue of a syncing controller. The downward syncing controllers add its object to the dead-letter
+	// set, the upward syncing controllers drop it.
+	SyncMaxRetries int `json:"syncMaxRetries"`
+
+	// SyncBaseDelay is the delay before the first retry of a failing request, doubled with every
+	// retry up to SyncMaxDelay.
+	SyncBaseDelay metav1.Duration `json:"syncBaseDelay"`
+
+	// SyncMaxDelay is the maximum delay between the retries of a failing request.
+	SyncMaxDelay metav1.Duration `json:"syncMaxDelay"`
+
+	// DWSDeadLetterRetryThreshold is the number of retries after which the failing request of a
+	// tenant object is taken out of the retry loop of a downward syncing controller and the object
+	// added to the dead-letter set. 0 means SyncMaxRetries.
+	DWSDeadLetterRetryThreshold int `json:"dwsDeadLetterRetryThreshold"`
+
+	// DWSDeadLetterRetryPeriod is how often the objects of the dead-letter set are retried. They are
+	// also retried when they change. 0 retries them only when they change.
+	DWSDeadLetterRetryPeriod metav1.Duration `json:"dwsDeadLetterRetryPeriod"`
+
+	// UWSQPS limits the back populations of each upward syncing controller, which write to the
+	// tenant control planes, to this many per second. 0 means no limit.
+	UWSQPS float32 `json:"uwsQPS"`
+
+	// UWSBurst is the maximum burst of back populations allowed by UWSQPS.
+	UWSBurst int `json:"uwsBurst"`
+
+	// UWSCoalescePeriod delays the back population of a super cluster object by this period after
+	// its first change, so that the changes of the object within the period, e.g. the pod status
+	// changes during its startup, are written to the tenant control plane at once. 0 disables it.
+	UWSCoalescePeriod metav1.Duration `json:"uwsCoalescePeriod"`
+
+	// ObjectCountRecountInterval is how often the per Virtual Cluster tenant object counts are rebuilt
+	// from the informer caches, correcting the drift caused by missed events. 0 disables the recount.
+	ObjectCountRecountInterval metav1.Duration `json:"objectCountRecountInterval"`
+
+	// LifecycleWebhookURL is the http(s) endpoint that sync lifecycle events of tenant objects, i.e.
+	// Synced, Failed and CleanedUp, are POSTed to as JSON. Empty disables the notifications.
+	LifecycleWebhookURL string `json:"lifecycleWebhookURL"`
+
+	// LifecycleWebhookEventTypes are the lifecycle event types sent to the webhook. Empty means all.
+	LifecycleWebhookEventTypes []string `json:"lifecycleWebhookEventTypes"`
+
+	// LifecycleWebhookQPS and LifecycleWebhookBurst limit the rate of requests to the lifecycle webhook.
+	LifecycleWebhookQPS   float32 `json:"lifecycleWebhookQPS"`
+	LifecycleWebhookBurst int     `json:"lifecycleWebhookBurst"`
+
+	// LifecycleWebhookMaxRetries is the number of times the delivery of a lifecycle event is retried,
+	// with exponential backoff, before the event is dropped.
+	LifecycleWebhookMaxRetries int `json:"lifecycleWebhookMaxRetries"`
 
 	// ExtraNodeLabels is the list of extra labels to be synced to vNode from the super cluster.
-	ExtraNodeLabels []string
+	// An entry is a label key, a glob of label keys where * matches any characters, or key=value
+	// to set the label to value on every vNode. Globs do not match the kubernetes.io and k8s.io
+	// labels unless they name the domain without *. The most specific entry wins.
+	ExtraNodeLabels []string `json:"extraNodeLabels"`
 
 	// OpaqueTaintKeys is the list of taint keys to be synced to vNode from the super cluster
-	OpaqueTaintKeys []string
+	OpaqueTaintKeys []string `json:"opaqueTaintKeys"`
+
+	// VirtualClusterLabelMapping maps VirtualCluster label keys to super cluster label keys.
+	// The value of each mapped VirtualCluster label is set on every object synced for that
+	// Virtual Cluster under the mapped key. An empty super cluster key reuses the VirtualCluster key.
+	// The derived labels are owned by the syncer and follow the VirtualCluster labels when they change.
+	VirtualClusterLabelMapping map[string]string `json:"virtualClusterLabelMapping"`
+
+	// VirtualClusterRegistrationConcurrency is the number of VirtualClusters that are registered,
+	// i.e. have their tenant informers set up, in parallel. Defaults to 3.
+	VirtualClusterRegistrationConcurrency int `json:"virtualClusterRegistrationConcurrency"`
+
+	// SuperClusterFailureThreshold is the number of consecutive failed checks of the super cluster
+	// apiserver after which the managed VirtualClusters get a SuperClusterUnreachable condition.
+	// The condition is cleared once the apiserver is reachable again. 0 disables the checks.
+	SuperClusterFailureThreshold int `json:"superClusterFailureThreshold"`
+
+	// SuperClusterHealthCheckPeriod is how often the super cluster apiserver is checked.
+	SuperClusterHealthCheckPeriod metav1.Duration `json:"superClusterHealthCheckPeriod"`
+
+	// SuperWatchErrorThreshold is the number of consecutive list and watch errors of a super or meta
+	// cluster informer, e.g. after its RBAC permissions are revoked, after which the informer is considered
+	// failing. It is checked every SuperClusterHealthCheckPeriod. 0 disables the checks.
+	SuperWatchErrorThreshold int `json:"superWatchErrorThreshold"`
+
+	// OnPersistentWatchErrors is what happens while a super or meta cluster informer is failing, one of
+	// degrade or restart. Defaults to degrade.
+	OnPersistentWatchErrors string `json:"onPersistentWatchErrors"`
+
+	// SuperClusterLookupCacheTTL is how long the super cluster objects looked up by the conversions
+	// without an informer, e.g. the service accounts of the kube-api-access volumes, are cached. It
+	// bounds how stale a conversion input can be. 0 disables the cache.
+	SuperClusterLookupCacheTTL metav1.Duration `json:"superClusterLookupCacheTTL"`
 
 	// VNAgentPort defines the port that the VN Agent is running on per host
-	VNAgentPort int32
+	VNAgentPort int32 `json:"vnAgentPort"`
 
 	// VNAgentNamespacedName defines the namespace/name of the VN Agent Kubernetes
 	// service, this is used for feature VNodeProviderService.
-	VNAgentNamespacedName string
+	VNAgentNamespacedName string `json:"vnAgentNamespacedName"`
 
 	// VNAgentLabelSelector defines the label of the VN Agent Kubernetes pods, this
 	// is used for the feature VNodeProviderPodIP
-	VNAgentLabelSelector string
+	VNAgentLabelSelector string `json:"vnAgentLabelSelector"`
+
+	// TerminationStuckTimeout is how long past its deletion grace period a super cluster pod can be
+	// terminating before its tenant pod gets the TerminationStuck condition. 0 disables the check.
+	TerminationStuckTimeout metav1.Duration `json:"terminationStuckTimeout"`
+
+	// ForceDeleteStuckPods force deletes, with a zero grace period, the super cluster pods stuck
+	// terminating past TerminationStuckTimeout.
+	ForceDeleteStuckPods bool `json:"forceDeleteStuckPods"`
 
 	// FeatureGates enabled by the user.
-	FeatureGates map[string]bool
+	FeatureGates map[string]bool `json:"featureGates"`
+
+	// FieldManager is the field manager name of the objects the syncer writes to the super cluster,
+	// used for conflict detection of server-side apply patches with other super cluster controllers.
+	// Defaults to the syncer name.
+	FieldManager string `json:"fieldManager"`
 
-	// Super cluster rest config
-	RestConfig *rest.Config
+	// DryRun sends the creates, updates, patches and deletes of the syncer to the super cluster and
+	// the tenant control planes as server-side dry runs, validated and admitted but not persisted,
+	// and logs and counts them, e.g. to validate a configuration before it changes a live super
+	// cluster.
+	DryRun bool `json:"dryRun,omitempty"`
 
 	// The maximum length of time to wait before giving up on a server request. A value of "" means use default.
-	Timeout string
+	Timeout string `json:"timeout"`
+
+	// The DNSOptions are the DNS options in resolv.conf that is attached to pod. If the config file
+	// does not set them, they are set from the dns-option or dns-options flag.
+	DNSOptions []corev1.PodDNSConfigOption `json:"dnsOptions,omitempty"`
+
+	// LogSampling lets one in every LogSampling repetitive info logs, e.g. the per-request logs of the
+	// syncing controllers, through per resource type. Errors are never sampled. 0 or 1 disables
+	// sampling.
+	LogSampling int `json:"logSampling"`
 
-	// The DNSOptions are the DNS options in resolv.conf that is attached to pod
-	DNSOptions []corev1.PodDNSConfigOption
+	// MetricsMaxVCCardinality caps the number of virtual clusters with their own vc_name label value
+	// in the per virtual cluster metrics. The virtual clusters recording metrics once the limit is
+	// reached are aggregated in the vc_name="other" series. 0 means no limit.
+	MetricsMaxVCCardinality int `json:"metricsMaxVCCardinality,omitempty"`
+}
+
+// SyncDirection is the direction a resource is synced in.
+type SyncDirection string
+
+const (
+	// SyncDirectionDown syncs the tenant objects to the super cluster only, the changes of the super
+	// cluster objects, e.g. their status, are not back populated to the tenant objects.
+	SyncDirectionDown SyncDirection = "down"
+	// SyncDirectionUp only back populates the super cluster objects to the tenant objects, the tenant
+	// objects are not synced to the super cluster.
+	SyncDirectionUp SyncDirection = "up"
+	// SyncDirectionBoth syncs the resource in both directions, the default.
+	SyncDirectionBoth SyncDirection = "both"
+)
+
+// SyncsDown returns whether the tenant objects are synced to the super cluster. An empty direction
+// means both.
+func (d SyncDirection) SyncsDown() bool {
+	return d != SyncDirectionUp
+}
+
+// SyncsUp returns whether the super cluster objects are back populated to the tenant objects. An
+// empty direction means both.
+func (d SyncDirection) SyncsUp() bool {
+	return d != SyncDirectionDown
 }
 
 // SyncerLeaderElectionConfiguration expands LeaderElectionConfiguration
 // to include syncer specific configuration.
 type SyncerLeaderElectionConfiguration struct {
-	componentbaseconfig.LeaderElectionConfiguration
+	componentbaseconfig.LeaderElectionConfiguration `json:",inline"`
 	// LockObjectNamespace defines the namespace of the lock object
-	LockObjectNamespace string
+	LockObjectNamespace string `json:"lockObjectNamespace"`
 	// LockObjectName defines the lock object name
-	LockObjectName string
+	LockObjectName string `json:"lockObjectName"`
+	// WatchDogTimeout is how long past the lease duration the leader may go without renewing
+	// the lease before its leader election health check fails.
+	WatchDogTimeout metav1.Duration `json:"watchDogTimeout"`
+}
+
+// TracingConfiguration configures the export of a span per dws and uws reconcile to an
+// OpenTelemetry collector.
+type TracingConfiguration struct {
+	// Endpoint is the http(s) OTLP/HTTP endpoint of the collector, e.g. http://tempo:4318, the spans
+	// are POSTed to. Its path defaults to /v1/traces. Empty disables tracing.
+	Endpoint string `json:"endpoint"`
+	// SamplingRatePerMillion is the number of traces per million sampled. The spans of the same object
+	// are in the same trace, sampled or not together.
+	SamplingRatePerMillion int32 `json:"samplingRatePerMillion"`
 }
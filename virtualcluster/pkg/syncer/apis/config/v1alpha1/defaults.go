@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/utils/pointer"
+
+	syncerconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/tracing"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+)
+
+// SetDefaults_SyncerConfiguration sets the defaults of the fields missing in a syncer config file.
+// The fields whose zero value is meaningful, e.g. 0 disabling a check, are pointers, defaulted only
+// when they are missing.
+func SetDefaults_SyncerConfiguration(obj *SyncerConfiguration) {
+	if obj.DisableServiceAccountToken == nil {
+		obj.DisableServiceAccountToken = pointer.BoolPtr(true)
+	}
+	if obj.DefaultOpaqueMetaDomains == nil {
+		obj.DefaultOpaqueMetaDomains = []string{"kubernetes.io", "k8s.io"}
+	}
+	if obj.MaxContainersPerPod == nil {
+		obj.MaxContainersPerPod = pointer.Int32Ptr(100)
+	}
+	if obj.MaxPodCommandBytes == nil {
+		obj.MaxPodCommandBytes = pointer.Int64Ptr(1024 * 1024)
+	}
+	if obj.UnsupportedProbePolicy == "" {
+		obj.UnsupportedProbePolicy = syncerconstants.UnsupportedProbePolicyReject
+	}
+	if obj.StartupProbePolicy == "" {
+		obj.StartupProbePolicy = syncerconstants.StartupProbePolicyKeep
+	}
+	if obj.OnNameTooLong == "" {
+		obj.OnNameTooLong = syncerconstants.OnNameTooLongHash
+	}
+	if obj.OnVCReadoption == "" {
+		obj.OnVCReadoption = syncerconstants.OnVCReadoptionConflict
+	}
+	if obj.NamespaceCreationMaxRetries == nil {
+		obj.NamespaceCreationMaxRetries = pointer.Int64Ptr(5)
+	}
+	if obj.NamespaceCreationRetryPeriod == nil {
+		obj.NamespaceCreationRetryPeriod = &metav1.Duration{Duration: time.Second}
+	}
+	if obj.ExistenceDisagreementPolicy == "" {
+		obj.ExistenceDisagreementPolicy = syncerconstants.ExistenceDisagreementConfirm
+	}
+	if obj.OnSuperObjectDeleted == "" {
+		obj.OnSuperObjectDeleted = syncerconstants.OnSuperObjectDeletedRecreate
+	}
+	if obj.OnClusterScopedConflict == "" {
+		obj.OnClusterScopedConflict = syncerconstants.ClusterScopedConflictAdopt
+	}
+	if obj.ImagePullPolicyRewrite == "" {
+		obj.ImagePullPolicyRewrite = syncerconstants.ImagePullPolicyRewriteNone
+	}
+	if obj.ImagePullPolicy == "" {
+		obj.ImagePullPolicy = string(corev1.PullIfNotPresent)
+	}
+	if obj.ShutdownGracePeriod == nil {
+		obj.ShutdownGracePeriod = &metav1.Duration{Duration: 20 * time.Second}
+	}
+	if obj.DWSOnboardingRampUpPeriod == nil {
+		obj.DWSOnboardingRampUpPeriod = &metav1.Duration{Duration: 30 * time.Second}
+	}
+	if obj.SyncMaxRetries == nil {
+		obj.SyncMaxRetries = pointer.Int64Ptr(constants.MaxReconcileRetryAttempts)
+	}
+	if obj.SyncBaseDelay == nil {
+		obj.SyncBaseDelay = &metav1.Duration{Duration: 5 * time.Millisecond}
+	}
+	if obj.SyncMaxDelay == nil {
+		obj.SyncMaxDelay = &metav1.Duration{Duration: 1000 * time.Second}
+	}
+	if obj.DWSDeadLetterRetryPeriod == nil {
+		obj.DWSDeadLetterRetryPeriod = &metav1.Duration{Duration: 10 * time.Minute}
+	}
+	if obj.UWSBurst == 0 {
+		obj.UWSBurst = 10
+	}
+	if obj.ObjectCountRecountInterval == nil {
+		obj.ObjectCountRecountInterval = &metav1.Duration{Duration: 10 * time.Minute}
+	}
+	if obj.LifecycleWebhookQPS == 0 {
+		obj.LifecycleWebhookQPS = 10
+	}
+	if obj.LifecycleWebhookBurst == 0 {
+		obj.LifecycleWebhookBurst = 20
+	}
+	if obj.LifecycleWebhookMaxRetries == nil {
+		obj.LifecycleWebhookMaxRetries = pointer.Int64Ptr(5)
+	}
+	if obj.VirtualClusterRegistrationConcurrency == 0 {
+		obj.VirtualClusterRegistrationConcurrency = 3
+	}
+	if obj.SuperClusterFailureThreshold == nil {
+		obj.SuperClusterFailureThreshold = pointer.Int64Ptr(3)
+	}
+	if obj.SuperClusterHealthCheckPeriod.Duration == 0 {
+		obj.SuperClusterHealthCheckPeriod = metav1.Duration{Duration: 10 * time.Second}
+	}
+	if obj.SuperWatchErrorThreshold == nil {
+		obj.SuperWatchErrorThreshold = pointer.Int64Ptr(5)
+	}
+	if obj.OnPersistentWatchErrors == "" {
+		obj.OnPersistentWatchErrors = syncerconstants.PersistentWatchErrorsDegrade
+	}
+	if obj.SuperClusterLookupCacheTTL == nil {
+		obj.SuperClusterLookupCacheTTL = &metav1.Duration{Duration: 2 * time.Second}
+	}
+	if obj.VNAgentPort == 0 {
+		obj.VNAgentPort = 10550
+	}
+	if obj.VNAgentNamespacedName == "" {
+		obj.VNAgentNamespacedName = "vc-manager/vn-agent"
+	}
+	if obj.VNAgentLabelSelector == "" {
+		obj.VNAgentLabelSelector = "app=vn-agent"
+	}
+	if obj.TerminationStuckTimeout == nil {
+		obj.TerminationStuckTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+	}
+	// The feature gates of the file are merged with the default ones.
+	if obj.FeatureGates == nil {
+		obj.FeatureGates = make(map[string]bool)
+	}
+	for _, gate := range []string{featuregate.SuperClusterPooling, featuregate.SuperClusterServiceNetwork, featuregate.VNodeProviderService} {
+		if _, ok := obj.FeatureGates[gate]; !ok {
+			obj.FeatureGates[gate] = false
+		}
+	}
+}
+
+// SetDefaults_SyncerLeaderElectionConfiguration sets the defaults of the leader election of a
+// syncer config file.
+func SetDefaults_SyncerLeaderElectionConfiguration(obj *SyncerLeaderElectionConfiguration) {
+	if obj.LeaderElect == nil {
+		obj.LeaderElect = pointer.BoolPtr(true)
+	}
+	if obj.LeaseDuration.Duration == 0 {
+		obj.LeaseDuration = metav1.Duration{Duration: 15 * time.Second}
+	}
+	if obj.RenewDeadline.Duration == 0 {
+		obj.RenewDeadline = metav1.Duration{Duration: 10 * time.Second}
+	}
+	if obj.RetryPeriod.Duration == 0 {
+		obj.RetryPeriod = metav1.Duration{Duration: 2 * time.Second}
+	}
+	if obj.ResourceLock == "" {
+		obj.ResourceLock = resourcelock.LeasesResourceLock
+	}
+	if obj.LockObjectName == "" {
+		obj.LockObjectName = "syncer-leaderelection-lock"
+	}
+	if obj.WatchDogTimeout.Duration == 0 {
+		obj.WatchDogTimeout = metav1.Duration{Duration: 20 * time.Second}
+	}
+}
+
+// SetDefaults_TracingConfiguration sets the defaults of the tracing of a syncer config file.
+func SetDefaults_TracingConfiguration(obj *TracingConfiguration) {
+	if obj.SamplingRatePerMillion == nil {
+		obj.SamplingRatePerMillion = pointer.Int32Ptr(tracing.MaxSamplingRatePerMillion)
+	}
+}
@@ -33,6 +33,8 @@ const (
 	LabelNamespace = "tenancy.x-k8s.io/namespace"
 	// LabelOwnerReferences is the ownerReferences of the object in tenant context.
 	LabelOwnerReferences = "tenancy.x-k8s.io/ownerReferences"
+	// LabelCreationTimestamp is the creationTimestamp of the object in tenant context, in RFC3339 format.
+	LabelCreationTimestamp = "tenancy.x-k8s.io/creationTimestamp"
 	// LabelClusterIP is the cluster ip of the corresponding service in tenant namespace.
 	LabelClusterIP = "tenancy.x-k8s.io/clusterIP"
 	// LabelSecretName is the service account token secret name in tenant namespace.
@@ -64,9 +66,22 @@ const (
 	// LabelSecretUID is the service account token secret UID in tenant namespace.
 	LabelSecretUID = "tenancy.x-k8s.io/secret.UID" // #nosec G101 -- This is a label key
 
+	// LabelTLSCertSerial and LabelTLSCertNotAfter are annotations on the super control plane copy of a
+	// tenant secret with a tls.crt, the serial number and the expiry of its soonest expiring certificate.
+	LabelTLSCertSerial   = "tenancy.x-k8s.io/tls.crt.serial"
+	LabelTLSCertNotAfter = "tenancy.x-k8s.io/tls.crt.not-after"
+	// LabelCACertSerial and LabelCACertNotAfter are LabelTLSCertSerial and LabelTLSCertNotAfter for
+	// the ca.crt of a tenant secret, e.g. a CA bundle.
+	LabelCACertSerial   = "tenancy.x-k8s.io/ca.crt.serial"
+	LabelCACertNotAfter = "tenancy.x-k8s.io/ca.crt.not-after"
+
 	// LabelTenantIgnoreSync is used by resources that do not need to be synced.
 	LabelTenantIgnoreSync = "tenancy.x-k8s.io/ignore-sync"
 
+	// LabelSyncPause is an annotation on a tenant object that, when "true", stops the syncer from
+	// reconciling the object and leaves its super control plane counterpart as is.
+	LabelSyncPause = "tenancy.x-k8s.io/sync-pause"
+
 	// UwsControllerWorkerHigh is the quantity of the worker routine for a resource that generates high number of uws requests.
 	UwsControllerWorkerHigh = 10
 	// UwsControllerWorkerLow is the quantity of the worker routine for a resource that generates low number of uws requests.
@@ -83,6 +98,155 @@ const (
 	// TenantDisableDNSPolicyMutation is a label that allows pods to stop the syncer from mutating the dnsPolicy
 	TenantDisableDNSPolicyMutation = "tenancy.x-k8s.io/disable.dnsPolicyMutation"
 
+	// TenantDisablePodServiceLinks is an annotation on a tenant pod or namespace that overrides the
+	// syncer's DisablePodServiceLinks setting. A pod annotation takes precedence over a namespace annotation.
+	TenantDisablePodServiceLinks = "tenancy.x-k8s.io/disable.podServiceLinks"
+
+	// LabelMaxContainersPerPod is an annotation on the VirtualCluster that overrides the syncer's
+	// MaxContainersPerPod setting for that Virtual Cluster.
+	LabelMaxContainersPerPod = "tenancy.x-k8s.io/max-containers-per-pod"
+
+	// LabelMaxPodCommandBytes is an annotation on the VirtualCluster that overrides the syncer's
+	// MaxPodCommandBytes setting for that Virtual Cluster.
+	LabelMaxPodCommandBytes = "tenancy.x-k8s.io/max-pod-command-bytes"
+
+	// LabelAllowedHostNamespaces is an annotation on the VirtualCluster listing, comma separated, the
+	// host namespaces its pods may share with the super cluster node: HostNamespaceIPC and HostNamespacePID.
+	// Tenant pods sharing a host namespace not in the list are not synced.
+	LabelAllowedHostNamespaces = "tenancy.x-k8s.io/allowed-host-namespaces"
+
+	// LabelEnabledPodMutators is an annotation on the VirtualCluster listing, comma separated, the pod
+	// mutator plugin IDs to run for its pods even if the syncer's DisabledPodMutators setting lists them.
+	LabelEnabledPodMutators = "tenancy.x-k8s.io/enabled-pod-mutators"
+
+	// LabelDisabledPodMutators is an annotation on the VirtualCluster listing, comma separated, the pod
+	// mutator plugin IDs not to run for its pods. It takes precedence over LabelEnabledPodMutators.
+	LabelDisabledPodMutators = "tenancy.x-k8s.io/disabled-pod-mutators"
+
+	// LabelExtraSyncingResources is an annotation on the VirtualCluster listing, comma separated, the
+	// IDs of the syncer's ExtraSyncingResources to sync for it, e.g. ingress,deployment. The resources
+	// not enabled by the syncer are ignored, an empty value syncs none of them.
+	LabelExtraSyncingResources = "tenancy.x-k8s.io/extra-syncing-resources"
+
+	// LabelSuperNamespaceQuota is an annotation on the VirtualCluster listing, comma separated, the
+	// resource=quantity hard limits of the ResourceQuota provisioned in each of its super cluster
+	// namespaces. It overrides the syncer's SuperNamespaceQuota setting, an empty value provisions none.
+	LabelSuperNamespaceQuota = "tenancy.x-k8s.io/super-namespace-quota"
+
+	// LabelManagedResourceQuota marks the super cluster ResourceQuotas provisioned by the syncer.
+	LabelManagedResourceQuota = "tenancy.x-k8s.io/managed-resource-quota"
+
+	// SuperNamespaceQuotaName is the name of the ResourceQuota provisioned by the syncer in the super
+	// cluster namespaces.
+	SuperNamespaceQuotaName = "virtualcluster-quota"
+
+	// HostNamespaceIPC allows tenant pods to set hostIPC.
+	HostNamespaceIPC = "hostIPC"
+	// HostNamespacePID allows tenant pods to set hostPID.
+	HostNamespacePID = "hostPID"
+
+	// UnsupportedProbePolicyReject rejects tenant pods having probes of a type unknown to the syncer.
+	UnsupportedProbePolicyReject = "reject"
+	// UnsupportedProbePolicyDrop syncs tenant pods without their probes of a type unknown to the syncer.
+	UnsupportedProbePolicyDrop = "drop"
+
+	// StartupProbePolicyKeep syncs the startup probes of tenant pods unchanged.
+	StartupProbePolicyKeep = "keep"
+	// StartupProbePolicyFold replaces the startup probe of a container by delaying its liveness probe
+	// for as long as the startup probe may fail, for super clusters not supporting startup probes.
+	StartupProbePolicyFold = "fold"
+
+	// OnNameTooLongHash shortens super control plane names exceeding their length limit with a hash suffix.
+	OnNameTooLongHash = "hash"
+	// OnNameTooLongFail fails the sync of tenant objects whose super control plane name exceeds its length limit.
+	OnNameTooLongFail = "fail"
+
+	// OnVCReadoptionRecreate deletes the super control plane namespaces left by a deleted Virtual
+	// Cluster when a Virtual Cluster with the same cluster key is created, they are then recreated.
+	OnVCReadoptionRecreate = "recreate"
+	// OnVCReadoptionAdopt re-stamps the super control plane namespaces left by a deleted Virtual
+	// Cluster, and the objects synced into them, to the Virtual Cluster with the same cluster key.
+	OnVCReadoptionAdopt = "adopt"
+	// OnVCReadoptionConflict leaves the super control plane namespaces left by a deleted Virtual
+	// Cluster in place and fails the sync of the tenant namespaces of the same name.
+	OnVCReadoptionConflict = "conflict"
+
+	// ExistenceDisagreementConfirm reads an object missing from the informer cache of the side
+	// authoritative for its existence from that side's apiserver before the periodic checker
+	// deletes its copy on the other side, keeping the copy if the object still exists.
+	ExistenceDisagreementConfirm = "confirm"
+	// ExistenceDisagreementTrustCache lets the periodic checker delete the copy of an object missing
+	// from the informer cache of the authoritative side without reading it from its apiserver.
+	ExistenceDisagreementTrustCache = "trust-cache"
+
+	// OnSuperObjectDeletedRecreate recreates a super pod deleted out of band whose tenant pod is not
+	// scheduled yet. A scheduled tenant pod cannot move to another node, so it is deleted.
+	OnSuperObjectDeletedRecreate = "recreate"
+	// OnSuperObjectDeletedPropagate deletes the tenant pod of a super pod deleted out of band,
+	// whether it is scheduled or not.
+	OnSuperObjectDeletedPropagate = "propagate"
+
+	// PodMutateDefaultID identifies the default pod conversion in the pod mutation pipeline order.
+	PodMutateDefaultID = "PodMutateDefault"
+
+	// ClusterScopedConflictAdopt takes over a tenant cluster scoped object that was not synced from
+	// the super control plane public object of the same name, overwriting it.
+	ClusterScopedConflictAdopt = "adopt"
+	// ClusterScopedConflictSkip leaves a tenant cluster scoped object that was not synced from the
+	// super control plane public object of the same name untouched.
+	ClusterScopedConflictSkip = "skip"
+	// ClusterScopedConflictFail leaves the tenant object untouched and fails the sync, so that it is
+	// retried until the conflict is resolved.
+	ClusterScopedConflictFail = "fail"
+
+	// PersistentWatchErrorsDegrade marks the managed VirtualClusters with a SuperClusterWatchFailing
+	// condition while a super cluster informer keeps failing.
+	PersistentWatchErrorsDegrade = "degrade"
+	// PersistentWatchErrorsRestart marks the managed VirtualClusters and exits the syncer, so that it
+	// is restarted with new informers.
+	PersistentWatchErrorsRestart = "restart"
+
+	// ImagePullPolicyRewriteNone keeps the imagePullPolicy of the tenant pod containers.
+	ImagePullPolicyRewriteNone = "none"
+	// ImagePullPolicyRewriteForce sets the imagePullPolicy of all super pod containers.
+	ImagePullPolicyRewriteForce = "force"
+	// ImagePullPolicyRewriteOverrideAlways sets the imagePullPolicy of the super pod containers
+	// whose tenant imagePullPolicy is Always.
+	ImagePullPolicyRewriteOverrideAlways = "override-always"
+
+	// LabelImagePullPolicyRewrite is an annotation on the VirtualCluster that overrides the syncer's
+	// ImagePullPolicyRewrite setting for that Virtual Cluster.
+	LabelImagePullPolicyRewrite = "tenancy.x-k8s.io/image-pull-policy-rewrite"
+	// LabelImagePullPolicy is an annotation on the VirtualCluster that overrides the syncer's
+	// ImagePullPolicy setting for that Virtual Cluster.
+	LabelImagePullPolicy = "tenancy.x-k8s.io/image-pull-policy"
+
+	// LabelSuperClusterWorkloadControllers is an annotation on the VirtualCluster that, when "true",
+	// lets the super cluster controller-manager reconcile the tenant Deployments and ReplicaSets.
+	// Only set it on Virtual Clusters without a controller-manager.
+	LabelSuperClusterWorkloadControllers = "tenancy.x-k8s.io/super-cluster-workload-controllers"
+
+	// LabelScopedClusterRBAC marks the super cluster Roles and RoleBindings converted from tenant ClusterRoleBindings.
+	LabelScopedClusterRBAC = "tenancy.x-k8s.io/scoped-cluster-rbac"
+	// LabelClusterRoleBinding is the name of the tenant ClusterRoleBinding a super cluster Role or RoleBinding is converted from.
+	LabelClusterRoleBinding = "tenancy.x-k8s.io/clusterrolebinding"
+	// ScopedClusterRBACNamePrefix is the name prefix of the super cluster Roles and RoleBindings converted
+	// from tenant ClusterRoleBindings.
+	ScopedClusterRBACNamePrefix = "vc:"
+
+	// AppArmorBetaContainerAnnotationKeyPrefix is the prefix of the legacy per container AppArmor profile annotation.
+	AppArmorBetaContainerAnnotationKeyPrefix = "container.apparmor.security.beta.kubernetes.io/"
+	// AppArmorBetaProfileRuntimeDefault is the legacy annotation value of the runtime default AppArmor profile.
+	AppArmorBetaProfileRuntimeDefault = "runtime/default"
+	// AppArmorBetaProfileNameUnconfined is the legacy annotation value of the unconfined AppArmor profile.
+	AppArmorBetaProfileNameUnconfined = "unconfined"
+	// AppArmorBetaProfileNamePrefix is the prefix of the legacy annotation value of a localhost AppArmor profile.
+	AppArmorBetaProfileNamePrefix = "localhost/"
+
+	// PodConditionTerminationStuck is the type of the tenant pod condition reporting that its super
+	// cluster pod is stuck terminating.
+	PodConditionTerminationStuck = "TerminationStuck"
+
 	// PublicObjectKey is a label key which marks the super control plane object that should be populated to every tenant control plane.
 	PublicObjectKey = "tenancy.x-k8s.io/super.public"
 
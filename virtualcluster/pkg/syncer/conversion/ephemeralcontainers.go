@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// BuildSuperClusterEphemeralContainers returns the ephemeral containers of the super pod with the tenant
+// ephemeral containers it misses added, or nil if it misses none. Ephemeral containers cannot be
+// changed or removed once added, so the ones of the super pod are kept as is.
+// The default pod conversion does not run for ephemeral containers, which are added to running pods.
+// Instead, an added container mounts the super volumes the tenant volumes were converted to, resolves
+// its downward API env vars to the tenant pod values, and gets the env vars the syncer injected into
+// its target container, or the first container if it has no target. The pod syncer runs the pod
+// mutator plugins on the added containers afterwards.
+func BuildSuperClusterEphemeralContainers(pPod, vPod *v1.Pod) []v1.EphemeralContainer {
+	existing := sets.NewString()
+	for _, c := range pPod.Spec.EphemeralContainers {
+		existing.Insert(c.Name)
+	}
+
+	var added []v1.EphemeralContainer
+	volumeNames := superVolumeNames(pPod, vPod)
+	for _, vContainer := range vPod.Spec.EphemeralContainers {
+		if existing.Has(vContainer.Name) {
+			continue
+		}
+		c := vContainer.DeepCopy()
+		for i := range c.VolumeMounts {
+			if name, ok := volumeNames[c.VolumeMounts[i].Name]; ok {
+				c.VolumeMounts[i].Name = name
+			}
+		}
+		for i := range c.Env {
+			mutateDownwardAPIField(&c.Env[i], vPod)
+			mutateDownwardAPIKeyField(&c.Env[i], vPod, pPod)
+		}
+
+		target := c.TargetContainerName
+		if target == "" && len(vPod.Spec.Containers) > 0 {
+			target = vPod.Spec.Containers[0].Name
+		}
+		pTarget, vTarget := findContainer(pPod.Spec.Containers, target), findContainer(vPod.Spec.Containers, target)
+		if pTarget != nil && vTarget != nil {
+			c.Env = append(c.Env, injectedEnv(pTarget, vTarget, c.Env)...)
+		}
+		added = append(added, *c)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	return append(append([]v1.EphemeralContainer{}, pPod.Spec.EphemeralContainers...), added...)
+}
+
+// superVolumeNames maps the tenant volume names to the super ones, e.g. of the service account token
+// secret volumes, by matching the mount paths of the containers of both pods.
+func superVolumeNames(pPod, vPod *v1.Pod) map[string]string {
+	names := make(map[string]string)
+	for _, containers := range [][2][]v1.Container{
+		{pPod.Spec.Containers, vPod.Spec.Containers},
+		{pPod.Spec.InitContainers, vPod.Spec.InitContainers},
+	} {
+		for _, vContainer := range containers[1] {
+			pContainer := findContainer(containers[0], vContainer.Name)
+			if pContainer == nil {
+				continue
+			}
+			for _, vMount := range vContainer.VolumeMounts {
+				for _, pMount := range pContainer.VolumeMounts {
+					if pMount.MountPath == vMount.MountPath {
+						names[vMount.Name] = pMount.Name
+						break
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// injectedEnv returns the env vars of the super container that are not set in the tenant container,
+// nor in env.
+func injectedEnv(pContainer, vContainer *v1.Container, env []v1.EnvVar) []v1.EnvVar {
+	names := sets.NewString()
+	for _, e := range vContainer.Env {
+		names.Insert(e.Name)
+	}
+	for _, e := range env {
+		names.Insert(e.Name)
+	}
+
+	var injected []v1.EnvVar
+	for _, e := range pContainer.Env {
+		if !names.Has(e.Name) {
+			injected = append(injected, e)
+		}
+	}
+	return injected
+}
+
+func findContainer(containers []v1.Container, name string) *v1.Container {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i]
+		}
+	}
+	return nil
+}
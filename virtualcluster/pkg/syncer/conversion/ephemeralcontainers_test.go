@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+func TestBuildSuperClusterEphemeralContainers(t *testing.T) {
+	mount := func(name, path string) v1.VolumeMount {
+		return v1.VolumeMount{Name: name, MountPath: path}
+	}
+	env := func(name, value string) v1.EnvVar {
+		return v1.EnvVar{Name: name, Value: value}
+	}
+	ephemeral := func(name, target string, env []v1.EnvVar, mounts ...v1.VolumeMount) v1.EphemeralContainer {
+		return v1.EphemeralContainer{
+			EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: name, Image: "busybox", Env: env, VolumeMounts: mounts},
+			TargetContainerName:      target,
+		}
+	}
+	pod := func(containers []v1.Container, ephemeralContainers ...v1.EphemeralContainer) *v1.Pod {
+		return &v1.Pod{Spec: v1.PodSpec{Containers: containers, EphemeralContainers: ephemeralContainers}}
+	}
+
+	vContainers := []v1.Container{
+		{Name: "app", Env: []v1.EnvVar{env("FOO", "foo")}, VolumeMounts: []v1.VolumeMount{mount("default-token-v", "/token")}},
+		{Name: "sidecar", VolumeMounts: []v1.VolumeMount{mount("data", "/data")}},
+	}
+	pContainers := []v1.Container{
+		{Name: "app", Env: []v1.EnvVar{env("FOO", "foo"), env("KUBERNETES_SERVICE_HOST", "10.0.0.1")}, VolumeMounts: []v1.VolumeMount{mount("default-token-p", "/token")}},
+		{Name: "sidecar", Env: []v1.EnvVar{env("KUBERNETES_SERVICE_HOST", "10.0.0.2")}, VolumeMounts: []v1.VolumeMount{mount("data", "/data")}},
+	}
+
+	for _, tt := range []struct {
+		name     string
+		pPod     *v1.Pod
+		vPod     *v1.Pod
+		expected []v1.EphemeralContainer
+	}{
+		{
+			name:     "no ephemeral containers",
+			pPod:     pod(pContainers),
+			vPod:     pod(vContainers),
+			expected: nil,
+		},
+		{
+			name: "added without target",
+			pPod: pod(pContainers),
+			vPod: pod(vContainers, ephemeral("debugger", "", nil, mount("default-token-v", "/token"), mount("data", "/data"))),
+			expected: []v1.EphemeralContainer{
+				ephemeral("debugger", "", []v1.EnvVar{env("KUBERNETES_SERVICE_HOST", "10.0.0.1")}, mount("default-token-p", "/token"), mount("data", "/data")),
+			},
+		},
+		{
+			name: "added with target",
+			pPod: pod(pContainers),
+			vPod: pod(vContainers, ephemeral("debugger", "sidecar", []v1.EnvVar{env("BAR", "bar")})),
+			expected: []v1.EphemeralContainer{
+				ephemeral("debugger", "sidecar", []v1.EnvVar{env("BAR", "bar"), env("KUBERNETES_SERVICE_HOST", "10.0.0.2")}),
+			},
+		},
+		{
+			name: "added to existing",
+			pPod: pod(pContainers, ephemeral("debugger", "", []v1.EnvVar{env("KUBERNETES_SERVICE_HOST", "10.0.0.1")})),
+			vPod: pod(vContainers, ephemeral("debugger", "", nil), ephemeral("debugger-2", "", []v1.EnvVar{env("KUBERNETES_SERVICE_HOST", "localhost")})),
+			expected: []v1.EphemeralContainer{
+				ephemeral("debugger", "", []v1.EnvVar{env("KUBERNETES_SERVICE_HOST", "10.0.0.1")}),
+				ephemeral("debugger-2", "", []v1.EnvVar{env("KUBERNETES_SERVICE_HOST", "localhost")}),
+			},
+		},
+		{
+			name: "added with downward API env",
+			pPod: pod(pContainers),
+			vPod: func() *v1.Pod {
+				p := pod(vContainers, ephemeral("debugger", "sidecar", []v1.EnvVar{
+					{Name: "POD_NAMESPACE", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.namespace"}}},
+					{Name: "APP", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.labels['app.kubernetes.io/name']"}}},
+				}))
+				p.Namespace = "ns"
+				p.Labels = map[string]string{"app.kubernetes.io/name": "frontend"}
+				return p
+			}(),
+			expected: []v1.EphemeralContainer{
+				ephemeral("debugger", "sidecar", []v1.EnvVar{env("POD_NAMESPACE", "ns"), env("APP", "frontend"), env("KUBERNETES_SERVICE_HOST", "10.0.0.2")}),
+			},
+		},
+		{
+			name:     "already added",
+			pPod:     pod(pContainers, ephemeral("debugger", "", []v1.EnvVar{env("KUBERNETES_SERVICE_HOST", "10.0.0.1")})),
+			vPod:     pod(vContainers, ephemeral("debugger", "", nil)),
+			expected: nil,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vPod := tt.vPod.DeepCopy()
+			got := BuildSuperClusterEphemeralContainers(tt.pPod, tt.vPod)
+			if !equality.Semantic.DeepEqual(got, tt.expected) {
+				tc.Errorf("expected %v, got %v", tt.expected, got)
+			}
+			if !equality.Semantic.DeepEqual(vPod, tt.vPod) {
+				tc.Errorf("expected the tenant pod not to be modified")
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package conversion
 import (
 	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	v1networking "k8s.io/api/networking/v1"
 	v1scheduling "k8s.io/api/scheduling/v1"
@@ -137,7 +138,7 @@ func (e vcEquality) CheckDWObjectMetaEquality(pObj, vObj *metav1.ObjectMeta) *me
 		updatedObj.GenerateName = vObj.GenerateName
 	}
 
-	labels, equal := e.checkDWKVEquality(pObj.Labels, vObj.Labels)
+	labels, equal := e.checkDWLabelsEquality(pObj.Labels, vObj.Labels)
 	if !equal {
 		if updatedObj == nil {
 			updatedObj = pObj.DeepCopy()
@@ -145,7 +146,7 @@ func (e vcEquality) CheckDWObjectMetaEquality(pObj, vObj *metav1.ObjectMeta) *me
 		updatedObj.Labels = labels
 	}
 
-	annotations, equal := e.checkDWKVEquality(pObj.Annotations, vObj.Annotations)
+	annotations, equal := e.checkDWAnnotationsEquality(pObj.Annotations, vObj.Annotations)
 	if !equal {
 		if updatedObj == nil {
 			updatedObj = pObj.DeepCopy()
@@ -208,7 +209,7 @@ func (e vcEquality) checkUWKVEquality(pKV, vKV map[string]string) (map[string]st
 
 	moreOrDiff := make(map[string]string)
 	for pk, pv := range pKV {
-		if hasPrefixInArray(pk, matchingList) {
+		if hasPrefixInArray(pk, matchingList) && e.uwsMetadataAllowed(pk) {
 			vv, ok := vKV[pk]
 			if !ok || pv != vv {
 				moreOrDiff[pk] = pv
@@ -228,11 +229,32 @@ func (e vcEquality) checkUWKVEquality(pKV, vKV map[string]string) (map[string]st
 	return updated, false
 }
 
+// uwsMetadataAllowed returns whether a super control plane label or annotation key is allowed to be
+// back populated to tenant control plane by UWSMetadataAllowlist. The keys of the default transparent
+// prefix are set by the syncer itself, e.g. the super cluster IP of services, and are always allowed.
+func (e vcEquality) uwsMetadataAllowed(key string) bool {
+	if e.config == nil || len(e.config.UWSMetadataAllowlist) == 0 {
+		return true
+	}
+	return strings.HasPrefix(key, constants.DefaultTransparentMetaPrefix) || hasPrefixInArray(key, e.config.UWSMetadataAllowlist)
+}
+
 // checkDWKVEquality check the whether super control plane object labels and virtual object labels
 // are logically equal. If not, return the updated value. The source of truth is virtual object.
 // The exceptional keys that used by super control plane object are specified in
 // VC.Spec.TransparentMetaPrefixes plus an ignorelist (e.g., tenancy.x-k8s.io).
 func (e vcEquality) checkDWKVEquality(pKV, vKV map[string]string) (map[string]string, bool) {
+	return e.checkDWKVEqualityWithPassthrough(pKV, vKV, nil)
+}
+
+// checkDWAnnotationsEquality is checkDWKVEquality for object annotations. The opaque annotations
+// listed in DWSAnnotationPassthrough are passed through from the virtual object, so they are
+// compared like any other key.
+func (e vcEquality) checkDWAnnotationsEquality(pKV, vKV map[string]string) (map[string]string, bool) {
+	return e.checkDWKVEqualityWithPassthrough(pKV, vKV, dwsAnnotationPassthrough(e.config))
+}
+
+func (e vcEquality) checkDWKVEqualityWithPassthrough(pKV, vKV map[string]string, passthrough sets.String) (map[string]string, bool) {
 	var exceptionsList []string
 	if e.vc != nil {
 		exceptions := sets.NewString()
@@ -250,7 +272,7 @@ func (e vcEquality) checkDWKVEquality(pKV, vKV map[string]string) (map[string]st
 			// tenant pod should not use exceptional keys. it may conflicts with syncer.
 			continue
 		}
-		if isOpaquedKey(e.config, vk) {
+		if isOpaquedKey(e.config, vk) && !passthrough.Has(vk) {
 			continue
 		}
 		pv, ok := pKV[vk]
@@ -265,7 +287,7 @@ func (e vcEquality) checkDWKVEquality(pKV, vKV map[string]string) (map[string]st
 		if hasPrefixInArray(pk, exceptionsList) {
 			continue
 		}
-		if isOpaquedKey(e.config, pk) {
+		if isOpaquedKey(e.config, pk) && !passthrough.Has(pk) {
 			continue
 		}
 
@@ -293,6 +315,63 @@ func (e vcEquality) checkDWKVEquality(pKV, vKV map[string]string) (map[string]st
 	return updated, false
 }
 
+// checkDWLabelsEquality is checkDWKVEquality for object labels. The labels derived from the
+// VirtualCluster (see VirtualClusterLabelMapping) are owned by the syncer, so they are not diffed
+// against the virtual object but reconciled against the current VirtualCluster labels instead.
+func (e vcEquality) checkDWLabelsEquality(pKV, vKV map[string]string) (map[string]string, bool) {
+	derivedKeys := virtualClusterDerivedLabelKeys(e.config)
+	if derivedKeys.Len() == 0 {
+		return e.checkDWKVEquality(pKV, vKV)
+	}
+
+	tenantKV := make(map[string]string)
+	existingDerived := make(map[string]string)
+	for k, v := range pKV {
+		if derivedKeys.Has(k) {
+			existingDerived[k] = v
+			continue
+		}
+		tenantKV[k] = v
+	}
+
+	virtualKV := make(map[string]string)
+	for k, v := range vKV {
+		if !derivedKeys.Has(k) {
+			virtualKV[k] = v
+		}
+	}
+
+	updated, equal := e.checkDWKVEquality(tenantKV, virtualKV)
+	if equal {
+		updated = tenantKV
+	}
+
+	derived := existingDerived
+	if e.vc != nil {
+		derived = BuildVirtualClusterDerivedLabels(e.config, e.vc)
+	}
+	if equal && equality.Semantic.DeepEqual(existingDerived, derived) {
+		return nil, true
+	}
+
+	if updated == nil {
+		updated = make(map[string]string)
+	}
+	for k, v := range derived {
+		updated[k] = v
+	}
+	return updated, false
+}
+
+// dwsAnnotationPassthrough returns the opaque annotation keys passed through to the super control
+// plane objects.
+func dwsAnnotationPassthrough(config *config.SyncerConfiguration) sets.String {
+	if config == nil {
+		return nil
+	}
+	return sets.NewString(config.DWSAnnotationPassthrough...)
+}
+
 func isOpaquedKey(config *config.SyncerConfiguration, key string) bool {
 	if config == nil {
 		return false
@@ -310,8 +389,8 @@ func isOpaquedKey(config *config.SyncerConfiguration, key string) bool {
 }
 
 // CheckUWPodStatusEquality compute status upward to tenant.
-// User-defined readiness type condition unchanged in tenant, others
-// keep consistent with super.
+// User-defined readiness type condition and the TerminationStuck condition set by
+// the syncer unchanged in tenant, others keep consistent with super.
 func (e vcEquality) CheckUWPodStatusEquality(pObj, vObj *v1.Pod) *v1.PodStatus {
 	newVStatus := pObj.Status.DeepCopy()
 
@@ -325,7 +404,7 @@ func (e vcEquality) CheckUWPodStatusEquality(pObj, vObj *v1.Pod) *v1.PodStatus {
 	}
 	vConditionMap := make(map[string]v1.PodCondition)
 	for _, c := range vObj.Status.Conditions {
-		if vReadinessGateSet.Has(string(c.Type)) {
+		if vReadinessGateSet.Has(string(c.Type)) || c.Type == constants.PodConditionTerminationStuck {
 			vConditionMap[string(c.Type)] = c
 		}
 	}
@@ -354,6 +433,8 @@ func (e vcEquality) CheckUWPodStatusEquality(pObj, vObj *v1.Pod) *v1.PodStatus {
 		newVStatus.Conditions = append(newVStatus.Conditions, c)
 	}
 
+	normalizePodIPs(newVStatus)
+
 	if !equality.Semantic.DeepEqual(vObj.Status, *newVStatus) {
 		return newVStatus
 	}
@@ -361,12 +442,27 @@ func (e vcEquality) CheckUWPodStatusEquality(pObj, vObj *v1.Pod) *v1.PodStatus {
 	return nil
 }
 
+// normalizePodIPs makes status.podIP and status.podIPs consistent. The podIPs of the super pod are
+// kept as is, in the family order reported by the super cluster, so dual-stack tenants see both
+// addresses, and podIP is always the primary (first) address. Pod addresses are not remapped by the
+// syncer, including when SuperClusterServiceNetwork is enabled, so both families are propagated verbatim.
+func normalizePodIPs(status *v1.PodStatus) {
+	if len(status.PodIPs) == 0 {
+		if status.PodIP != "" {
+			status.PodIPs = []v1.PodIP{{IP: status.PodIP}}
+		}
+		return
+	}
+	status.PodIP = status.PodIPs[0].IP
+}
+
 // checkPodSpecEquality check the whether super control plane Pod Spec and virtual object
 // PodSpec are logically equal. The source of truth is virtual Pod Spec.
 // Mutable fields:
 // - spec.containers[*].image
 // - spec.initContainers[*].image
 // - spec.activeDeadlineSeconds
+// - spec.tolerations (additions and tolerationSeconds)
 func (e vcEquality) checkPodSpecEquality(pObj, vObj *v1.PodSpec) *v1.PodSpec {
 	var updatedPodSpec *v1.PodSpec
 
@@ -378,6 +474,14 @@ func (e vcEquality) checkPodSpecEquality(pObj, vObj *v1.PodSpec) *v1.PodSpec {
 		updatedPodSpec.ActiveDeadlineSeconds = val
 	}
 
+	updatedTolerations := e.checkTolerationsEquality(pObj.Tolerations, vObj.Tolerations)
+	if updatedTolerations != nil {
+		if updatedPodSpec == nil {
+			updatedPodSpec = pObj.DeepCopy()
+		}
+		updatedPodSpec.Tolerations = updatedTolerations
+	}
+
 	updatedContainer := e.checkContainersImageEquality(pObj.Containers, vObj.Containers)
 	if len(updatedContainer) != 0 {
 		if updatedPodSpec == nil {
@@ -429,6 +533,38 @@ func (e vcEquality) checkContainersImageEquality(pObj, vObj []v1.Container) []v1
 	return updated
 }
 
+// checkTolerationsEquality returns the super pod tolerations updated with the tolerations added to
+// the tenant pod and the changed tolerationSeconds, e.g. of the node.kubernetes.io/not-ready and
+// unreachable tolerations, or nil if there is no change. Tolerations are never removed from the super
+// pod, as a pod update cannot remove them, which keeps the ones added by the super cluster admission.
+func (e vcEquality) checkTolerationsEquality(pObj, vObj []v1.Toleration) []v1.Toleration {
+	var updated []v1.Toleration
+	for _, vToleration := range vObj {
+		found := false
+		for i, pToleration := range pObj {
+			if pToleration.Key != vToleration.Key || pToleration.Operator != vToleration.Operator ||
+				pToleration.Value != vToleration.Value || pToleration.Effect != vToleration.Effect {
+				continue
+			}
+			found = true
+			if val, equal := e.checkInt64Equality(pToleration.TolerationSeconds, vToleration.TolerationSeconds); !equal {
+				if updated == nil {
+					updated = append([]v1.Toleration{}, pObj...)
+				}
+				updated[i].TolerationSeconds = val
+			}
+			break
+		}
+		if !found {
+			if updated == nil {
+				updated = append([]v1.Toleration{}, pObj...)
+			}
+			updated = append(updated, *vToleration.DeepCopy())
+		}
+	}
+	return updated
+}
+
 func (e vcEquality) checkInt64Equality(pObj, vObj *int64) (*int64, bool) {
 	if pObj == nil && vObj == nil {
 		return nil, true
@@ -533,7 +669,7 @@ func (e vcEquality) CheckSecretEquality(pObj, vObj *v1.Secret) *v1.Secret {
 			updatedObj.Labels = labels
 		}
 
-		annotations, equal := e.checkDWKVEquality(pObj.Annotations, vObj.Annotations)
+		annotations, equal := e.checkDWAnnotationsEquality(pObj.Annotations, vObj.Annotations)
 		if !equal {
 			if updatedObj == nil {
 				updatedObj = pObj.DeepCopy()
@@ -659,6 +795,50 @@ func (e vcEquality) CheckIngressEquality(pObj, vObj *v1networking.Ingress) *v1ne
 	}
 }
 
+// CheckDeploymentEquality checks whether the super control plane Deployment is in line with the
+// tenant Deployment, whose pod template is mutated by MutateWorkloadPodTemplate. It returns the
+// updated super Deployment or nil if they are equal.
+func (e vcEquality) CheckDeploymentEquality(pObj, vObj *appsv1.Deployment) *appsv1.Deployment {
+	var updated *appsv1.Deployment
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		updated = pObj.DeepCopy()
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	vSpec := vObj.Spec.DeepCopy()
+	MutateWorkloadPodTemplate(e.config, &vSpec.Template)
+	if !equality.Semantic.DeepEqual(*vSpec, pObj.Spec) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec = *vSpec
+	}
+	return updated
+}
+
+// CheckReplicaSetEquality checks whether the super control plane ReplicaSet is in line with the
+// tenant ReplicaSet, whose pod template is mutated by MutateWorkloadPodTemplate. It returns the
+// updated super ReplicaSet or nil if they are equal.
+func (e vcEquality) CheckReplicaSetEquality(pObj, vObj *appsv1.ReplicaSet) *appsv1.ReplicaSet {
+	var updated *appsv1.ReplicaSet
+	updatedMeta := e.CheckDWObjectMetaEquality(&pObj.ObjectMeta, &vObj.ObjectMeta)
+	if updatedMeta != nil {
+		updated = pObj.DeepCopy()
+		updated.ObjectMeta = *updatedMeta
+	}
+
+	vSpec := vObj.Spec.DeepCopy()
+	MutateWorkloadPodTemplate(e.config, &vSpec.Template)
+	if !equality.Semantic.DeepEqual(*vSpec, pObj.Spec) {
+		if updated == nil {
+			updated = pObj.DeepCopy()
+		}
+		updated.Spec = *vSpec
+	}
+	return updated
+}
+
 func filterNodePort(svc *v1.Service) *v1.ServiceSpec {
 	specClone := svc.Spec.DeepCopy()
 	specClone.HealthCheckNodePort = 0
@@ -26,6 +26,7 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 )
 
@@ -188,6 +189,117 @@ func TestCheckDWKVEquality(t *testing.T) {
 	}
 }
 
+func TestCheckDWLabelsEquality(t *testing.T) {
+	syncerConfig := &config.SyncerConfiguration{
+		VirtualClusterLabelMapping: map[string]string{
+			"team":        "tenant-team",
+			"cost-center": "",
+		},
+	}
+	vc := v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"team":        "a",
+				"cost-center": "100",
+				"unmapped":    "x",
+			},
+		},
+	}
+	for _, tt := range []struct {
+		name     string
+		super    map[string]string
+		virtual  map[string]string
+		isEqual  bool
+		expected map[string]string
+	}{
+		{
+			name: "equal with derived labels",
+			super: map[string]string{
+				"a":           "b",
+				"tenant-team": "a",
+				"cost-center": "100",
+			},
+			virtual: map[string]string{
+				"a": "b",
+			},
+			isEqual:  true,
+			expected: nil,
+		},
+		{
+			name: "virtual cluster label changed",
+			super: map[string]string{
+				"a":           "b",
+				"tenant-team": "old",
+				"cost-center": "100",
+			},
+			virtual: map[string]string{
+				"a": "b",
+			},
+			isEqual: false,
+			expected: map[string]string{
+				"a":           "b",
+				"tenant-team": "a",
+				"cost-center": "100",
+			},
+		},
+		{
+			name: "derived labels missing",
+			super: map[string]string{
+				"a": "b",
+			},
+			virtual: map[string]string{
+				"a": "b",
+			},
+			isEqual: false,
+			expected: map[string]string{
+				"a":           "b",
+				"tenant-team": "a",
+				"cost-center": "100",
+			},
+		},
+		{
+			name: "tenant label changed",
+			super: map[string]string{
+				"a":           "b",
+				"tenant-team": "a",
+				"cost-center": "100",
+			},
+			virtual: map[string]string{
+				"a": "c",
+			},
+			isEqual: false,
+			expected: map[string]string{
+				"a":           "c",
+				"tenant-team": "a",
+				"cost-center": "100",
+			},
+		},
+		{
+			name: "tenant cannot override derived label",
+			super: map[string]string{
+				"tenant-team": "a",
+				"cost-center": "100",
+			},
+			virtual: map[string]string{
+				"tenant-team": "b",
+			},
+			isEqual:  true,
+			expected: nil,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			got, equal := Equality(syncerConfig, &vc).checkDWLabelsEquality(tt.super, tt.virtual)
+			if equal != tt.isEqual {
+				tc.Errorf("expected equal %v, got %v %v", tt.isEqual, equal, got)
+			} else {
+				if !equality.Semantic.DeepEqual(got, tt.expected) {
+					tc.Errorf("expected result %+v, got %+v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
 func TestCheckUWKVEquality(t *testing.T) {
 	vc := v1alpha1.VirtualCluster{
 		Spec: v1alpha1.VirtualClusterSpec{
@@ -297,6 +409,64 @@ func TestCheckUWKVEquality(t *testing.T) {
 	}
 }
 
+func TestCheckUWKVEqualityAllowlist(t *testing.T) {
+	vc := v1alpha1.VirtualCluster{
+		Spec: v1alpha1.VirtualClusterSpec{
+			TransparentMetaPrefixes: []string{"tp.x-k8s.io", "scheduler.x-k8s.io"},
+		},
+	}
+	super := map[string]string{
+		"a":                       "b",
+		"tp.x-k8s.io/zone":        "zone-a",
+		"scheduler.x-k8s.io/hint": "rack-1",
+		constants.DefaultOpaqueMetaPrefix + "/vc-name": "vc",
+	}
+	for _, tt := range []struct {
+		name      string
+		allowlist []string
+		isEqual   bool
+		expected  map[string]string
+	}{
+		{
+			name:      "no allowlist",
+			allowlist: nil,
+			isEqual:   false,
+			expected: map[string]string{
+				"tp.x-k8s.io/zone":                                    "zone-a",
+				"scheduler.x-k8s.io/hint":                             "rack-1",
+				constants.DefaultTransparentMetaPrefix + "/clusterIP": "10.0.0.1",
+			},
+		},
+		{
+			name:      "allowlist",
+			allowlist: []string{"tp.x-k8s.io"},
+			isEqual:   false,
+			expected: map[string]string{
+				"tp.x-k8s.io/zone": "zone-a",
+				constants.DefaultTransparentMetaPrefix + "/clusterIP": "10.0.0.1",
+			},
+		},
+		{
+			name:      "allowlist of non transparent keys",
+			allowlist: []string{"a", constants.DefaultOpaqueMetaPrefix},
+			isEqual:   false,
+			expected: map[string]string{
+				constants.DefaultTransparentMetaPrefix + "/clusterIP": "10.0.0.1",
+			},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			syncerConfig := &config.SyncerConfiguration{UWSMetadataAllowlist: tt.allowlist}
+			got, equal := Equality(syncerConfig, &vc).checkUWKVEquality(super, nil)
+			if equal != tt.isEqual {
+				tc.Errorf("expected equal %v, got %v", tt.isEqual, equal)
+			} else if !equality.Semantic.DeepEqual(got, tt.expected) {
+				tc.Errorf("expected result %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestCheckContainersImageEquality(t *testing.T) {
 	for _, tt := range []struct {
 		name     string
@@ -478,6 +648,217 @@ func TestCheckActiveDeadlineSecondsEquality(t *testing.T) {
 	}
 }
 
+func TestCheckTolerationsEquality(t *testing.T) {
+	toleration := func(key string, effect v1.TaintEffect, seconds *int64) v1.Toleration {
+		return v1.Toleration{Key: key, Operator: v1.TolerationOpExists, Effect: effect, TolerationSeconds: seconds}
+	}
+	notReady := func(seconds int64) v1.Toleration {
+		return toleration(v1.TaintNodeNotReady, v1.TaintEffectNoExecute, pointer.Int64Ptr(seconds))
+	}
+	unreachable := func(seconds int64) v1.Toleration {
+		return toleration(v1.TaintNodeUnreachable, v1.TaintEffectNoExecute, pointer.Int64Ptr(seconds))
+	}
+
+	for _, tt := range []struct {
+		name     string
+		pObj     []v1.Toleration
+		vObj     []v1.Toleration
+		expected []v1.Toleration
+	}{
+		{
+			name:     "equal",
+			pObj:     []v1.Toleration{notReady(30), unreachable(30)},
+			vObj:     []v1.Toleration{notReady(30), unreachable(30)},
+			expected: nil,
+		},
+		{
+			name:     "super cluster default tolerations are kept",
+			pObj:     []v1.Toleration{notReady(300), unreachable(300)},
+			vObj:     nil,
+			expected: nil,
+		},
+		{
+			name:     "tolerationSeconds changed",
+			pObj:     []v1.Toleration{notReady(300), unreachable(300)},
+			vObj:     []v1.Toleration{notReady(300), unreachable(10)},
+			expected: []v1.Toleration{notReady(300), unreachable(10)},
+		},
+		{
+			name:     "tolerationSeconds removed",
+			pObj:     []v1.Toleration{notReady(300)},
+			vObj:     []v1.Toleration{toleration(v1.TaintNodeNotReady, v1.TaintEffectNoExecute, nil)},
+			expected: []v1.Toleration{toleration(v1.TaintNodeNotReady, v1.TaintEffectNoExecute, nil)},
+		},
+		{
+			name:     "toleration added",
+			pObj:     []v1.Toleration{notReady(300)},
+			vObj:     []v1.Toleration{notReady(300), unreachable(60)},
+			expected: []v1.Toleration{notReady(300), unreachable(60)},
+		},
+		{
+			name:     "NoSchedule toleration does not match NoExecute one",
+			pObj:     []v1.Toleration{notReady(300)},
+			vObj:     []v1.Toleration{toleration(v1.TaintNodeNotReady, v1.TaintEffectNoSchedule, nil)},
+			expected: []v1.Toleration{notReady(300), toleration(v1.TaintNodeNotReady, v1.TaintEffectNoSchedule, nil)},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			pObj := make([]v1.Toleration, len(tt.pObj))
+			for i := range tt.pObj {
+				tt.pObj[i].DeepCopyInto(&pObj[i])
+			}
+			got := Equality(nil, nil).checkTolerationsEquality(pObj, tt.vObj)
+			if !equality.Semantic.DeepEqual(got, tt.expected) {
+				tc.Errorf("expected %v, got %v", tt.expected, got)
+			}
+			if !equality.Semantic.DeepEqual(pObj, tt.pObj) {
+				tc.Errorf("expected the super pod tolerations not to be modified, got %v", pObj)
+			}
+		})
+	}
+}
+
+func TestCheckBinaryDataEquality(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		pObj     map[string][]byte
+		vObj     map[string][]byte
+		expected map[string][]byte
+		equal    bool
+	}{
+		{
+			name:  "equal",
+			pObj:  map[string][]byte{"bin": {0x00, 0xff, 0x10}, "empty": {}},
+			vObj:  map[string][]byte{"bin": {0x00, 0xff, 0x10}, "empty": {}},
+			equal: true,
+		},
+		{
+			name:  "nil and empty",
+			pObj:  nil,
+			vObj:  map[string][]byte{},
+			equal: true,
+		},
+		{
+			name:  "nil and empty value",
+			pObj:  map[string][]byte{"empty": nil},
+			vObj:  map[string][]byte{"empty": {}},
+			equal: true,
+		},
+		{
+			name:     "byte changed",
+			pObj:     map[string][]byte{"bin": {0x00, 0xff, 0x10}},
+			vObj:     map[string][]byte{"bin": {0x00, 0xfe, 0x10}},
+			expected: map[string][]byte{"bin": {0x00, 0xfe, 0x10}},
+		},
+		{
+			name:     "key added",
+			pObj:     map[string][]byte{"bin": {0x00}},
+			vObj:     map[string][]byte{"bin": {0x00}, "bin2": {0x01}},
+			expected: map[string][]byte{"bin": {0x00}, "bin2": {0x01}},
+		},
+		{
+			name:     "all keys removed",
+			pObj:     map[string][]byte{"bin": {0x00}},
+			vObj:     nil,
+			expected: nil,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			got, equal := Equality(nil, nil).CheckBinaryDataEquality(tt.pObj, tt.vObj)
+			if equal != tt.equal {
+				tc.Errorf("expected equal %v, got %v", tt.equal, equal)
+			}
+			if !equality.Semantic.DeepEqual(got, tt.expected) {
+				tc.Errorf("expected %v, got %v", tt.expected, got)
+			}
+			for k := range got {
+				if len(got[k]) > 0 && len(tt.vObj[k]) > 0 && &got[k][0] == &tt.vObj[k][0] {
+					tc.Errorf("expected %s to be copied, not shared with the tenant object", k)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckDWAnnotationsEquality(t *testing.T) {
+	syncerConfig := &config.SyncerConfiguration{
+		DefaultOpaqueMetaDomains: []string{"kubernetes.io"},
+		DWSAnnotationPassthrough: []string{"tz.kubernetes.io/timezone"},
+	}
+	vc := v1alpha1.VirtualCluster{
+		Spec: v1alpha1.VirtualClusterSpec{
+			OpaqueMetaPrefixes: []string{"tenancy.x-k8s.io"},
+		},
+	}
+	for _, tt := range []struct {
+		name     string
+		super    map[string]string
+		virtual  map[string]string
+		isEqual  bool
+		expected map[string]string
+	}{
+		{
+			name:  "passthrough key added",
+			super: map[string]string{"a": "b", "tenancy.x-k8s.io/name": "name"},
+			virtual: map[string]string{
+				"a":                         "b",
+				"tz.kubernetes.io/timezone": "Europe/Berlin",
+			},
+			isEqual: false,
+			expected: map[string]string{
+				"a":                         "b",
+				"tenancy.x-k8s.io/name":     "name",
+				"tz.kubernetes.io/timezone": "Europe/Berlin",
+			},
+		},
+		{
+			name:    "passthrough key changed",
+			super:   map[string]string{"tz.kubernetes.io/timezone": "UTC"},
+			virtual: map[string]string{"tz.kubernetes.io/timezone": "Europe/Berlin"},
+			isEqual: false,
+			expected: map[string]string{
+				"tz.kubernetes.io/timezone": "Europe/Berlin",
+			},
+		},
+		{
+			name: "passthrough key removed",
+			super: map[string]string{
+				"a":                         "b",
+				"tz.kubernetes.io/timezone": "Europe/Berlin",
+			},
+			virtual:  map[string]string{"a": "b"},
+			isEqual:  false,
+			expected: map[string]string{"a": "b"},
+		},
+		{
+			name: "other opaque keys ignored",
+			super: map[string]string{
+				"a":                     "b",
+				"tz.kubernetes.io/zone": "a",
+			},
+			virtual: map[string]string{
+				"a":                       "b",
+				"tz.kubernetes.io/locale": "de_DE",
+			},
+			isEqual: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			got, equal := Equality(syncerConfig, &vc).checkDWAnnotationsEquality(tt.super, tt.virtual)
+			if equal != tt.isEqual {
+				tc.Errorf("expected equal %v, got %v %v", tt.isEqual, equal, got)
+			} else if !equality.Semantic.DeepEqual(got, tt.expected) {
+				tc.Errorf("expected result %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	// labels are not passed through.
+	if got, equal := Equality(syncerConfig, &vc).checkDWLabelsEquality(nil, map[string]string{"tz.kubernetes.io/timezone": "UTC"}); !equal {
+		t.Errorf("expected the opaque label to be ignored, got %v", got)
+	}
+}
+
 func TestCheckUWPodStatusEquality(t *testing.T) {
 	for _, tt := range []struct {
 		name       string
@@ -545,6 +926,37 @@ func TestCheckUWPodStatusEquality(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "termination stuck condition in tenant",
+			pObj: &v1.Pod{
+				Status: v1.PodStatus{
+					Conditions: []v1.PodCondition{
+						{
+							Type:    "a",
+							Message: "aaa",
+							Reason:  "aaa",
+						},
+					},
+				},
+			},
+			vObj: &v1.Pod{
+				Status: v1.PodStatus{
+					Conditions: []v1.PodCondition{
+						{
+							Type:    "a",
+							Message: "aaa",
+							Reason:  "aaa",
+						},
+						{
+							Type:   constants.PodConditionTerminationStuck,
+							Status: v1.ConditionTrue,
+							Reason: "SuperPodTerminationStuck",
+						},
+					},
+				},
+			},
+			updatedVal: nil,
+		},
 		{
 			name: "no readiness condition in super",
 			pObj: &v1.Pod{
@@ -796,6 +1208,170 @@ func TestCheckUWPodStatusEquality(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "dual-stack pod ips",
+			pObj: &v1.Pod{
+				Status: v1.PodStatus{
+					PodIP: "10.0.0.1",
+					PodIPs: []v1.PodIP{
+						{IP: "10.0.0.1"},
+						{IP: "fd00::1"},
+					},
+				},
+			},
+			vObj: &v1.Pod{
+				Status: v1.PodStatus{
+					PodIP: "10.0.0.1",
+					PodIPs: []v1.PodIP{
+						{IP: "10.0.0.1"},
+					},
+				},
+			},
+			updatedVal: &v1.PodStatus{
+				PodIP: "10.0.0.1",
+				PodIPs: []v1.PodIP{
+					{IP: "10.0.0.1"},
+					{IP: "fd00::1"},
+				},
+			},
+		},
+		{
+			name: "container running but not started yet",
+			pObj: &v1.Pod{
+				Status: v1.PodStatus{
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:    "app",
+							Ready:   false,
+							Started: pointer.BoolPtr(false),
+							State:   v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+						},
+					},
+				},
+			},
+			vObj: &v1.Pod{
+				Status: v1.PodStatus{
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:  "app",
+							State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+						},
+					},
+				},
+			},
+			updatedVal: &v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:    "app",
+						Ready:   false,
+						Started: pointer.BoolPtr(false),
+						State:   v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+					},
+				},
+			},
+		},
+		{
+			name: "container started by its startup probe",
+			pObj: &v1.Pod{
+				Status: v1.PodStatus{
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:    "app",
+							Ready:   true,
+							Started: pointer.BoolPtr(true),
+							State:   v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+						},
+					},
+				},
+			},
+			vObj: &v1.Pod{
+				Status: v1.PodStatus{
+					ContainerStatuses: []v1.ContainerStatus{
+						{
+							Name:    "app",
+							Ready:   false,
+							Started: pointer.BoolPtr(false),
+							State:   v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+						},
+					},
+				},
+			},
+			updatedVal: &v1.PodStatus{
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:    "app",
+						Ready:   true,
+						Started: pointer.BoolPtr(true),
+						State:   v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+					},
+				},
+			},
+		},
+		{
+			name: "ipv6 primary dual-stack pod ips keep family order",
+			pObj: &v1.Pod{
+				Status: v1.PodStatus{
+					PodIPs: []v1.PodIP{
+						{IP: "fd00::1"},
+						{IP: "10.0.0.1"},
+					},
+				},
+			},
+			vObj: &v1.Pod{},
+			updatedVal: &v1.PodStatus{
+				PodIP: "fd00::1",
+				PodIPs: []v1.PodIP{
+					{IP: "fd00::1"},
+					{IP: "10.0.0.1"},
+				},
+			},
+		},
+		{
+			name: "init container statuses in order with running sidecars",
+			pObj: &v1.Pod{
+				Status: v1.PodStatus{
+					InitContainerStatuses: []v1.ContainerStatus{
+						{Name: "init-config", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}}},
+						{Name: "istio-proxy", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}, Ready: true, Started: pointer.BoolPtr(true)},
+						{Name: "init-db", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}}},
+						{Name: "log-shipper", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}, Ready: true, Started: pointer.BoolPtr(true)},
+					},
+				},
+			},
+			vObj: &v1.Pod{
+				Status: v1.PodStatus{
+					InitContainerStatuses: []v1.ContainerStatus{
+						{Name: "init-config", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}}},
+						{Name: "istio-proxy", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}, Started: pointer.BoolPtr(true)},
+						{Name: "init-db", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+						{Name: "log-shipper", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "PodInitializing"}}},
+					},
+				},
+			},
+			updatedVal: &v1.PodStatus{
+				InitContainerStatuses: []v1.ContainerStatus{
+					{Name: "init-config", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}}},
+					{Name: "istio-proxy", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}, Ready: true, Started: pointer.BoolPtr(true)},
+					{Name: "init-db", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{Reason: "Completed"}}},
+					{Name: "log-shipper", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}, Ready: true, Started: pointer.BoolPtr(true)},
+				},
+			},
+		},
+		{
+			name: "single pod ip without pod ips",
+			pObj: &v1.Pod{
+				Status: v1.PodStatus{
+					PodIP: "10.0.0.1",
+				},
+			},
+			vObj: &v1.Pod{},
+			updatedVal: &v1.PodStatus{
+				PodIP: "10.0.0.1",
+				PodIPs: []v1.PodIP{
+					{IP: "10.0.0.1"},
+				},
+			},
+		},
 	} {
 		t.Run(tt.name, func(tc *testing.T) {
 			val := Equality(nil, nil).CheckUWPodStatusEquality(tt.pObj, tt.vObj)
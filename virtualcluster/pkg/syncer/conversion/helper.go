@@ -24,16 +24,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	v1scheduling "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	listersv1 "k8s.io/client-go/listers/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -43,6 +48,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/scheme"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
@@ -59,12 +65,56 @@ func ToClusterKey(vc *v1alpha1.VirtualCluster) string {
 }
 
 func ToSuperClusterNamespace(cluster, ns string) string {
-	targetNamespace := strings.Join([]string{cluster, ns}, "-")
-	if len(targetNamespace) > validation.DNS1123LabelMaxLength {
-		digest := sha256.Sum256([]byte(targetNamespace))
-		return targetNamespace[0:57] + "-" + hex.EncodeToString(digest[0:])[0:5]
+	return hashTooLongName(strings.Join([]string{cluster, ns}, "-"), validation.DNS1123LabelMaxLength)
+}
+
+// ToSuperClusterLabelValue returns the value of a super control plane label derived from a tenant name,
+// which is shortened with a hash suffix like the namespace names if it exceeds the label value limit.
+func ToSuperClusterLabelValue(value string) string {
+	return hashTooLongName(value, validation.LabelValueMaxLength)
+}
+
+// hashTooLongName replaces the end of a name longer than maxLength with a hash of the full name.
+func hashTooLongName(name string, maxLength int) string {
+	if len(name) > maxLength {
+		digest := sha256.Sum256([]byte(name))
+		return name[0:maxLength-6] + "-" + hex.EncodeToString(digest[0:])[0:5]
+	}
+	return name
+}
+
+// checkNameLength returns an error if a super control plane name derived from a tenant object
+// exceeds maxLength and the syncer is configured to fail rather than hash such names.
+func (c *objectConversion) checkNameLength(kind, name string, maxLength int) error {
+	if len(name) <= maxLength || c.config == nil || c.config.OnNameTooLong != constants.OnNameTooLongFail {
+		return nil
 	}
-	return targetNamespace
+	return fmt.Errorf("super control plane %s %q exceeds %d characters", kind, name, maxLength)
+}
+
+// ValidateSuperClusterLabels returns an InvalidObjectError, wrapping an Invalid error naming the
+// offending labels, if the labels of a super control plane object, i.e. the tenant labels plus the
+// ones added by the syncer, are not valid label keys or values. The super control plane would reject
+// the object again and again otherwise.
+func ValidateSuperClusterLabels(obj client.Object) error {
+	labels := obj.GetLabels()
+	fldPath := field.NewPath("metadata", "labels")
+	var errs field.ErrorList
+	for _, k := range sets.StringKeySet(labels).List() {
+		errs = append(errs, metav1validation.ValidateLabelName(k, fldPath)...)
+		// unlike metav1validation.ValidateLabels, name the label of an invalid value.
+		for _, msg := range validation.IsValidLabelValue(labels[k]) {
+			errs = append(errs, field.Invalid(fldPath.Key(k), labels[k], msg))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	var kind schema.GroupKind
+	if kinds, _, err := scheme.Scheme.ObjectKinds(obj); err == nil && len(kinds) > 0 {
+		kind = kinds[0].GroupKind()
+	}
+	return mc.NewInvalidObjectError(apierrors.NewInvalid(kind, obj.GetName(), errs))
 }
 
 // GetVirtualNamespace is used to find the corresponding namespace in tenant control plane for objects created in super control plane originally, e.g., events.
@@ -169,14 +219,21 @@ func (c *objectConversion) BuildSuperClusterObject(cluster string, obj client.Ob
 	for k, v := range tenantScopeMetaInAnnotation {
 		anno[k] = v
 	}
+	if creationTimestamp := obj.GetCreationTimestamp(); c.config != nil && c.config.PreserveTenantCreationTimestamp && !creationTimestamp.IsZero() {
+		anno[constants.LabelCreationTimestamp] = creationTimestamp.UTC().Format(time.RFC3339)
+	}
 	m.SetAnnotations(anno)
 
 	labels := m.GetLabels()
 	if labels == nil {
 		labels = make(map[string]string)
 	}
+	// The full values are kept in the annotations.
+	if err := c.checkNameLength("label value", vcName, validation.LabelValueMaxLength); err != nil {
+		return nil, err
+	}
 	var tenantScopeMetaInLabel = map[string]string{
-		constants.LabelVCName:      vcName,
+		constants.LabelVCName:      ToSuperClusterLabelValue(vcName),
 		constants.LabelVCNamespace: vcNS,
 	}
 	for k, v := range tenantScopeMetaInLabel {
@@ -188,12 +245,29 @@ func (c *objectConversion) BuildSuperClusterObject(cluster string, obj client.Ob
 		m.SetLabels(WithSuperClusterLabels(m.GetLabels()))
 	}
 
+	if err := c.checkNameLength("namespace", strings.Join([]string{cluster, obj.GetNamespace()}, "-"), validation.DNS1123LabelMaxLength); err != nil {
+		return nil, err
+	}
 	m.SetNamespace(ToSuperClusterNamespace(cluster, obj.GetNamespace()))
 
+	if err := ValidateSuperClusterLabels(m); err != nil {
+		return nil, err
+	}
+
 	return m, nil
 }
 
 func (c *objectConversion) CleanOpaqueKeys(vc *v1alpha1.VirtualCluster, keyMap map[string]string) {
+	c.cleanOpaqueKeys(vc, keyMap, nil)
+}
+
+// cleanOpaqueAnnotations is CleanOpaqueKeys for annotations, it keeps the opaque annotations
+// listed in DWSAnnotationPassthrough.
+func (c *objectConversion) cleanOpaqueAnnotations(vc *v1alpha1.VirtualCluster, keyMap map[string]string) {
+	c.cleanOpaqueKeys(vc, keyMap, dwsAnnotationPassthrough(c.config))
+}
+
+func (c *objectConversion) cleanOpaqueKeys(vc *v1alpha1.VirtualCluster, keyMap map[string]string, passthrough sets.String) {
 	var exceptionsList []string
 	if vc != nil {
 		exceptions := sets.NewString()
@@ -203,7 +277,7 @@ func (c *objectConversion) CleanOpaqueKeys(vc *v1alpha1.VirtualCluster, keyMap m
 	}
 
 	for k := range keyMap {
-		if hasPrefixInArray(k, exceptionsList) || isOpaquedKey(c.config, k) {
+		if hasPrefixInArray(k, exceptionsList) || (isOpaquedKey(c.config, k) && !passthrough.Has(k)) {
 			delete(keyMap, k)
 		}
 	}
@@ -247,8 +321,15 @@ func (c *objectConversion) BuildSuperClusterNamespace(cluster string, obj client
 	anno[constants.LabelVCUID] = vcUID
 	m.SetAnnotations(anno)
 
+	if err := c.checkNameLength("namespace", strings.Join([]string{cluster, obj.GetName()}, "-"), validation.DNS1123LabelMaxLength); err != nil {
+		return nil, err
+	}
 	m.SetName(ToSuperClusterNamespace(cluster, obj.GetName()))
 
+	if err := ValidateSuperClusterLabels(m); err != nil {
+		return nil, err
+	}
+
 	return m, nil
 }
 
@@ -266,13 +347,58 @@ func (c *objectConversion) buildCleanSuperClusterObject(cluster string, obj clie
 	}
 
 	c.CleanOpaqueKeys(vc, m.GetLabels())
-	c.CleanOpaqueKeys(vc, m.GetAnnotations())
+	c.cleanOpaqueAnnotations(vc, m.GetAnnotations())
 
 	ResetMetadata(m)
 
+	if derived := BuildVirtualClusterDerivedLabels(c.config, vc); len(derived) != 0 {
+		labels := m.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		for k, v := range derived {
+			labels[k] = v
+		}
+		m.SetLabels(labels)
+	}
+
 	return target.(client.Object), nil
 }
 
+// virtualClusterDerivedLabelKeys returns the super cluster label keys that are owned by
+// the VirtualClusterLabelMapping.
+func virtualClusterDerivedLabelKeys(syncerConfig *config.SyncerConfiguration) sets.String {
+	keys := sets.NewString()
+	if syncerConfig == nil {
+		return keys
+	}
+	for vcKey, superKey := range syncerConfig.VirtualClusterLabelMapping {
+		if superKey == "" {
+			superKey = vcKey
+		}
+		keys.Insert(superKey)
+	}
+	return keys
+}
+
+// BuildVirtualClusterDerivedLabels returns the labels copied from the VirtualCluster onto
+// every object synced for it, according to the VirtualClusterLabelMapping.
+func BuildVirtualClusterDerivedLabels(syncerConfig *config.SyncerConfiguration, vc *v1alpha1.VirtualCluster) map[string]string {
+	derived := make(map[string]string)
+	if syncerConfig == nil || vc == nil {
+		return derived
+	}
+	for vcKey, superKey := range syncerConfig.VirtualClusterLabelMapping {
+		if superKey == "" {
+			superKey = vcKey
+		}
+		if v, ok := vc.GetLabels()[vcKey]; ok {
+			derived[superKey] = v
+		}
+	}
+	return derived
+}
+
 func ResetMetadata(obj metav1.Object) {
 	obj.SetSelfLink("")
 	obj.SetUID("")
@@ -302,12 +428,14 @@ func BuildVirtualEvent(cluster string, pEvent *v1.Event, vObj client.Object) *v1
 func BuildVirtualStorageClass(cluster string, pStorageClass *storagev1.StorageClass) *storagev1.StorageClass {
 	vStorageClass := pStorageClass.DeepCopy()
 	ResetMetadata(vStorageClass)
+	util.MarkSyncedFromSuper(vStorageClass, pStorageClass)
 	return vStorageClass
 }
 
 func BuildVirtualPriorityClass(cluster string, pPriorityClass *v1scheduling.PriorityClass) *v1scheduling.PriorityClass {
 	vPriorityClass := pPriorityClass.DeepCopy()
 	ResetMetadata(vPriorityClass)
+	util.MarkSyncedFromSuper(vPriorityClass, pPriorityClass)
 	return vPriorityClass
 }
 
@@ -1,12 +1,21 @@
 package conversion
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
 func TestToClusterKey(t *testing.T) {
@@ -96,3 +105,298 @@ func TestIsControlPlaneService(t *testing.T) {
 		})
 	}
 }
+
+type fakeMultiClusterController struct {
+	mc.MultiClusterInterface
+	vc *v1alpha1.VirtualCluster
+}
+
+func (f *fakeMultiClusterController) GetClusterObject(string) (client.Object, error) {
+	return f.vc, nil
+}
+
+func (f *fakeMultiClusterController) GetOwnerInfo(string) (string, string, string, error) {
+	return f.vc.Name, f.vc.Namespace, string(f.vc.UID), nil
+}
+
+func TestBuildSuperClusterObjectCreationTimestamp(t *testing.T) {
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "ns",
+			UID:       "d64ea0c0-91f8-46f5-8643-c0cab32ab0cd",
+		},
+	}
+	created := metav1.NewTime(time.Date(2021, 6, 1, 8, 30, 0, 0, time.UTC))
+
+	for _, tt := range []struct {
+		name     string
+		preserve bool
+		obj      *v1.ConfigMap
+		expected string
+	}{
+		{
+			name:     "preserve",
+			preserve: true,
+			obj:      &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm", CreationTimestamp: created}},
+			expected: "2021-06-01T08:30:00Z",
+		},
+		{
+			name:     "not preserve",
+			preserve: false,
+			obj:      &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm", CreationTimestamp: created}},
+			expected: "",
+		},
+		{
+			name:     "preserve without creationTimestamp",
+			preserve: true,
+			obj:      &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}},
+			expected: "",
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			conv := Convertor(&config.SyncerConfiguration{PreserveTenantCreationTimestamp: tt.preserve}, &fakeMultiClusterController{vc: vc})
+			got, err := conv.BuildSuperClusterObject("cluster", tt.obj)
+			if err != nil {
+				tc.Fatalf("unexpected error: %v", err)
+			}
+			if v := got.GetAnnotations()[constants.LabelCreationTimestamp]; v != tt.expected {
+				tc.Errorf("expected annotation %q, got %q", tt.expected, v)
+			}
+		})
+	}
+}
+
+func TestBuildSuperClusterObjectAnnotationPassthrough(t *testing.T) {
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "ns",
+			UID:       "d64ea0c0-91f8-46f5-8643-c0cab32ab0cd",
+		},
+	}
+	obj := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "cm",
+			Labels: map[string]string{
+				"tz.kubernetes.io/timezone": "UTC",
+			},
+			Annotations: map[string]string{
+				"tz.kubernetes.io/timezone": "Europe/Berlin",
+				"tz.kubernetes.io/locale":   "de_DE",
+				"a":                         "b",
+			},
+		},
+	}
+
+	conv := Convertor(&config.SyncerConfiguration{
+		DefaultOpaqueMetaDomains: []string{"kubernetes.io"},
+		DWSAnnotationPassthrough: []string{"tz.kubernetes.io/timezone"},
+	}, &fakeMultiClusterController{vc: vc})
+	got, err := conv.BuildSuperClusterObject("cluster", obj)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	anno := got.GetAnnotations()
+	if v := anno["tz.kubernetes.io/timezone"]; v != "Europe/Berlin" {
+		t.Errorf("expected the passthrough annotation to be kept, got %q", v)
+	}
+	if v, ok := anno["tz.kubernetes.io/locale"]; ok {
+		t.Errorf("expected the opaque annotation to be removed, got %q", v)
+	}
+	if v := anno["a"]; v != "b" {
+		t.Errorf("expected the annotation a to be kept, got %q", v)
+	}
+	if v, ok := got.GetLabels()["tz.kubernetes.io/timezone"]; ok {
+		t.Errorf("expected the opaque label to be removed, got %q", v)
+	}
+}
+
+func TestBuildSuperClusterObjectInvalidLabels(t *testing.T) {
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "ns",
+			UID:       "d64ea0c0-91f8-46f5-8643-c0cab32ab0cd",
+		},
+	}
+	for _, tt := range []struct {
+		name          string
+		labels        map[string]string
+		expectedField string
+	}{
+		{
+			name:   "valid",
+			labels: map[string]string{"app": "web"},
+		},
+		{
+			name:          "value too long",
+			labels:        map[string]string{"app": "web", "release": strings.Repeat("a", validation.LabelValueMaxLength+1)},
+			expectedField: "metadata.labels[release]",
+		},
+		{
+			name:          "invalid key",
+			labels:        map[string]string{"app": "web", "-release": "v1"},
+			expectedField: "metadata.labels",
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			obj := &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "cm",
+					Labels:    tt.labels,
+				},
+			}
+			conv := Convertor(&config.SyncerConfiguration{}, &fakeMultiClusterController{vc: vc})
+			_, err := conv.BuildSuperClusterObject("cluster", obj)
+			if tt.expectedField == "" {
+				if err != nil {
+					tc.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !mc.IsInvalidObjectError(err) || !apierrors.IsInvalid(err) {
+				tc.Fatalf("expected an invalid object error, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.expectedField) || !strings.Contains(err.Error(), "ConfigMap") {
+				tc.Errorf("expected the error to name the configmap and %s, got %v", tt.expectedField, err)
+			}
+		})
+	}
+}
+
+func TestToSuperClusterNamespace(t *testing.T) {
+	cluster := "ns-fd1b34-name"
+	for _, tt := range []struct {
+		name     string
+		ns       string
+		expected string
+	}{
+		{
+			name:     "short namespace",
+			ns:       "default",
+			expected: "ns-fd1b34-name-default",
+		},
+		{
+			name:     "namespace at the limit",
+			ns:       strings.Repeat("a", 48),
+			expected: cluster + "-" + strings.Repeat("a", 48),
+		},
+		{
+			name:     "namespace over the limit",
+			ns:       strings.Repeat("a", 49),
+			expected: cluster + "-" + strings.Repeat("a", 42) + "-70bb0",
+		},
+		{
+			name:     "longest tenant namespace",
+			ns:       strings.Repeat("a", 63),
+			expected: cluster + "-" + strings.Repeat("a", 42) + "-83970",
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			got := ToSuperClusterNamespace(cluster, tt.ns)
+			if got != tt.expected {
+				tc.Errorf("expected namespace %s, got %s", tt.expected, got)
+			}
+			if len(got) > validation.DNS1123LabelMaxLength {
+				tc.Errorf("namespace %s exceeds %d characters", got, validation.DNS1123LabelMaxLength)
+			}
+		})
+	}
+}
+
+func TestBuildSuperClusterNameTooLong(t *testing.T) {
+	longName := strings.Repeat("v", 64)
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      longName,
+			Namespace: "ns",
+			UID:       "d64ea0c0-91f8-46f5-8643-c0cab32ab0cd",
+		},
+	}
+	cluster := "ns-fd1b34-name"
+
+	for _, tt := range []struct {
+		name                string
+		onNameTooLong       string
+		obj                 client.Object
+		buildNamespace      bool
+		expectedError       string
+		expectedName        string
+		expectedVCNameLabel string
+	}{
+		{
+			name:                "hash label value",
+			onNameTooLong:       constants.OnNameTooLongHash,
+			obj:                 &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}},
+			expectedName:        "cm",
+			expectedVCNameLabel: strings.Repeat("v", 57) + "-c354e",
+		},
+		{
+			name:                "hash label value by default",
+			obj:                 &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}},
+			expectedName:        "cm",
+			expectedVCNameLabel: strings.Repeat("v", 57) + "-c354e",
+		},
+		{
+			name:          "fail on label value",
+			onNameTooLong: constants.OnNameTooLongFail,
+			obj:           &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}},
+			expectedError: "label value",
+		},
+		{
+			name:           "hash namespace",
+			onNameTooLong:  constants.OnNameTooLongHash,
+			obj:            &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 49)}},
+			buildNamespace: true,
+			expectedName:   cluster + "-" + strings.Repeat("a", 42) + "-70bb0",
+		},
+		{
+			name:           "namespace at the limit",
+			onNameTooLong:  constants.OnNameTooLongFail,
+			obj:            &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 48)}},
+			buildNamespace: true,
+			expectedName:   cluster + "-" + strings.Repeat("a", 48),
+		},
+		{
+			name:           "fail on namespace",
+			onNameTooLong:  constants.OnNameTooLongFail,
+			obj:            &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: strings.Repeat("a", 49)}},
+			buildNamespace: true,
+			expectedError:  "namespace",
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			conv := Convertor(&config.SyncerConfiguration{OnNameTooLong: tt.onNameTooLong}, &fakeMultiClusterController{vc: vc})
+			var got client.Object
+			var err error
+			if tt.buildNamespace {
+				got, err = conv.BuildSuperClusterNamespace(cluster, tt.obj)
+			} else {
+				got, err = conv.BuildSuperClusterObject(cluster, tt.obj)
+			}
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					tc.Errorf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				tc.Fatalf("unexpected error: %v", err)
+			}
+			if got.GetName() != tt.expectedName {
+				tc.Errorf("expected name %s, got %s", tt.expectedName, got.GetName())
+			}
+			if tt.expectedVCNameLabel != "" {
+				if v := got.GetLabels()[constants.LabelVCName]; v != tt.expectedVCNameLabel {
+					tc.Errorf("expected label %q, got %q", tt.expectedVCNameLabel, v)
+				}
+				if v := got.GetAnnotations()[constants.LabelVCName]; v != longName {
+					tc.Errorf("expected annotation %q, got %q", longName, v)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"time"
+
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+)
+
+// lookupCacheSize is the maximum number of super cluster objects kept by a LookupCache.
+const lookupCacheSize = 4096
+
+// LookupCache keeps the super cluster objects looked up by the conversions that are not backed by
+// an informer for a short TTL, e.g. so that the pods of a scaled up deployment look up their service
+// account once. The TTL bounds how stale a conversion input can be, keep it short.
+type LookupCache struct {
+	ttl   time.Duration
+	cache *utilcache.LRUExpireCache
+}
+
+// NewLookupCache returns a LookupCache keeping the objects for ttl, a ttl of 0 disables the cache.
+func NewLookupCache(ttl time.Duration) *LookupCache {
+	return newLookupCache(ttl, utilcache.NewLRUExpireCache(lookupCacheSize))
+}
+
+func newLookupCache(ttl time.Duration, cache *utilcache.LRUExpireCache) *LookupCache {
+	return &LookupCache{
+		ttl:   ttl,
+		cache: cache,
+	}
+}
+
+// Get returns the cached object of key, or looks it up with get and caches it. Errors and nil
+// results, which get returns for objects that do not exist yet, are not cached. The returned object
+// is shared and must not be modified.
+func (c *LookupCache) Get(key string, get func() (interface{}, error)) (interface{}, error) {
+	if c == nil || c.ttl <= 0 {
+		return get()
+	}
+	if obj, ok := c.cache.Get(key); ok {
+		return obj, nil
+	}
+	obj, err := get()
+	if err != nil || obj == nil {
+		return obj, err
+	}
+	c.cache.Add(key, obj, c.ttl)
+	return obj, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestLookupCache(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Now())
+	c := newLookupCache(2*time.Second, utilcache.NewLRUExpireCacheWithClock(lookupCacheSize, fakeClock))
+
+	lookups := 0
+	var result interface{}
+	var lookupErr error
+	get := func() (interface{}, error) {
+		lookups++
+		return result, lookupErr
+	}
+
+	// misses and errors are not cached.
+	if obj, err := c.Get("a", get); obj != nil || err != nil {
+		t.Fatalf("expected a miss, got %v, %v", obj, err)
+	}
+	lookupErr = errors.New("unavailable")
+	if _, err := c.Get("a", get); err == nil {
+		t.Fatalf("expected the lookup error")
+	}
+	lookupErr = nil
+	result = "v1"
+	if obj, err := c.Get("a", get); obj != "v1" || err != nil {
+		t.Fatalf("expected v1, got %v, %v", obj, err)
+	}
+	if lookups != 3 {
+		t.Errorf("expected 3 lookups, got %d", lookups)
+	}
+
+	// hits until the TTL is over.
+	result = "v2"
+	fakeClock.Step(time.Second)
+	if obj, _ := c.Get("a", get); obj != "v1" {
+		t.Errorf("expected the cached v1, got %v", obj)
+	}
+	if lookups != 3 {
+		t.Errorf("expected a cache hit, got %d lookups", lookups)
+	}
+	fakeClock.Step(2 * time.Second)
+	if obj, _ := c.Get("a", get); obj != "v2" {
+		t.Errorf("expected v2 once the TTL is over, got %v", obj)
+	}
+	if lookups != 4 {
+		t.Errorf("expected 4 lookups, got %d", lookups)
+	}
+}
+
+func TestLookupCacheDisabled(t *testing.T) {
+	for _, c := range []*LookupCache{nil, NewLookupCache(0)} {
+		lookups := 0
+		for i := 0; i < 2; i++ {
+			if _, err := c.Get("a", func() (interface{}, error) {
+				lookups++
+				return "v1", nil
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if lookups != 2 {
+			t.Errorf("expected every get to look up, got %d lookups", lookups)
+		}
+	}
+}
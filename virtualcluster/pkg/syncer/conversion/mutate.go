@@ -19,15 +19,19 @@ package conversion
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion/envvars"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	utilconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
@@ -112,6 +116,96 @@ func mutateWeightedPodAffinityTerms(weightedTerms []v1.WeightedPodAffinityTerm,
 	}
 }
 
+// vNodeOnlyLabels returns the labels the syncer adds to every vNode of this super cluster, see
+// provider.GetNodeLabels. Super cluster nodes never carry them, the other vNode labels and the
+// vNode name are copied from the super cluster node. The key=value overrides of ExtraNodeLabels
+// are the exception, requirements on them still resolve against the super cluster node value.
+func vNodeOnlyLabels() map[string]string {
+	vNodeLabels := map[string]string{
+		constants.LabelVirtualNode: "true",
+	}
+	if featuregate.DefaultFeatureGate.Enabled(featuregate.SuperClusterPooling) {
+		vNodeLabels[constants.LabelSuperClusterID] = utilconstants.SuperClusterID
+	}
+	return vNodeLabels
+}
+
+var (
+	// superNodeMatchAll is a node selector requirement every super cluster node satisfies.
+	superNodeMatchAll = v1.NodeSelectorRequirement{Key: constants.LabelVirtualNode, Operator: v1.NodeSelectorOpDoesNotExist}
+	// superNodeMatchNone is a node selector requirement no super cluster node satisfies.
+	superNodeMatchNone = v1.NodeSelectorRequirement{Key: constants.LabelVirtualNode, Operator: v1.NodeSelectorOpExists}
+)
+
+// vNodeOnlyRequirementMatches tells whether a requirement on a vNode only label is satisfied by
+// the vNodes of this super cluster.
+func vNodeOnlyRequirementMatches(req v1.NodeSelectorRequirement, vNodeLabels map[string]string) bool {
+	var op selection.Operator
+	switch req.Operator {
+	case v1.NodeSelectorOpIn:
+		op = selection.In
+	case v1.NodeSelectorOpNotIn:
+		op = selection.NotIn
+	case v1.NodeSelectorOpExists:
+		op = selection.Exists
+	case v1.NodeSelectorOpDoesNotExist:
+		op = selection.DoesNotExist
+	case v1.NodeSelectorOpGt:
+		op = selection.GreaterThan
+	case v1.NodeSelectorOpLt:
+		op = selection.LessThan
+	default:
+		return false
+	}
+	r, err := labels.NewRequirement(req.Key, op, req.Values)
+	if err != nil {
+		return false
+	}
+	return r.Matches(labels.Set(vNodeLabels))
+}
+
+// mutateNodeSelectorTerm rewrites the requirements of a term on the vNode only labels, which the
+// super cluster nodes do not carry, into requirements on the super cluster nodes with the same
+// outcome. Requirements on the other labels and the node name already resolve against the super
+// cluster node the vNode is copied from and are kept as is.
+func mutateNodeSelectorTerm(term *v1.NodeSelectorTerm, vNodeLabels map[string]string) {
+	for i, req := range term.MatchExpressions {
+		if req.Key != constants.LabelVirtualNode && req.Key != constants.LabelSuperClusterID {
+			continue
+		}
+		if vNodeOnlyRequirementMatches(req, vNodeLabels) {
+			term.MatchExpressions[i] = superNodeMatchAll
+		} else {
+			term.MatchExpressions[i] = superNodeMatchNone
+		}
+	}
+}
+
+// mutatePodNodeAffinity makes the node selector and the node affinity of the pod resolve against
+// the super cluster node labels.
+func mutatePodNodeAffinity(pPod *v1.Pod) {
+	vNodeLabels := vNodeOnlyLabels()
+	for k, v := range pPod.Spec.NodeSelector {
+		if vNodeValue, found := vNodeLabels[k]; found && vNodeValue == v {
+			delete(pPod.Spec.NodeSelector, k)
+		}
+	}
+
+	if pPod.Spec.Affinity == nil || pPod.Spec.Affinity.NodeAffinity == nil {
+		return
+	}
+	nodeAffinity := pPod.Spec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		terms := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		for i := range terms {
+			mutateNodeSelectorTerm(&terms[i], vNodeLabels)
+		}
+	}
+	for i := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		mutateNodeSelectorTerm(&nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].Preference, vNodeLabels)
+	}
+}
+
 func PodMutateDefault(vPod *v1.Pod, saSecretMap map[string]string, services []*v1.Service, nameServer string, dnsOption []v1.PodDNSConfigOption) PodMutator {
 	return func(p *PodMutateCtx) error {
 		p.PPod.Status = v1.PodStatus{}
@@ -127,16 +221,23 @@ func PodMutateDefault(vPod *v1.Pod, saSecretMap map[string]string, services []*v
 		})
 
 		for i := range p.PPod.Spec.Containers {
-			mutateContainerEnv(&p.PPod.Spec.Containers[i], vPod, serviceEnv)
+			mutateContainerEnv(&p.PPod.Spec.Containers[i], vPod, p.PPod, serviceEnv)
 			mutateContainerSecret(&p.PPod.Spec.Containers[i], saSecretMap, vPod)
 		}
 
 		for i := range p.PPod.Spec.InitContainers {
-			mutateContainerEnv(&p.PPod.Spec.InitContainers[i], vPod, serviceEnv)
+			mutateContainerEnv(&p.PPod.Spec.InitContainers[i], vPod, p.PPod, serviceEnv)
 			mutateContainerSecret(&p.PPod.Spec.InitContainers[i], saSecretMap, vPod)
 		}
 
 		for i, volume := range p.PPod.Spec.Volumes {
+			// inline CSI volumes are kept as is, except the node publish secret which is renamed in
+			// the super control plane if it is a service account token.
+			if volume.CSI != nil && volume.CSI.NodePublishSecretRef != nil {
+				if pSecretName, exists := saSecretMap[volume.CSI.NodePublishSecretRef.Name]; exists {
+					p.PPod.Spec.Volumes[i].CSI.NodePublishSecretRef.Name = pSecretName
+				}
+			}
 			if volume.Secret == nil {
 				continue
 			}
@@ -169,28 +270,44 @@ func PodMutateDefault(vPod *v1.Pod, saSecretMap map[string]string, services []*v
 			mutateWeightedPodAffinityTerms(p.PPod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, p.ClusterName)
 		}
 
+		mutatePodNodeAffinity(p.PPod)
+
 		vc, err := util.GetVirtualClusterObject(p.Mc, p.ClusterName)
 		if err != nil {
 			return err
 		}
 		mutateDNSConfig(p, vPod, vc.Spec.ClusterDomain, nameServer, dnsOption)
 
-		// FIXME(zhuangqh): how to support pod subdomain.
-		if p.PPod.Spec.Subdomain != "" {
-			p.PPod.Spec.Subdomain = ""
-		}
+		mutatePodHostname(p)
 
 		return nil
 	}
 }
 
-func mutateContainerEnv(c *v1.Container, vPod *v1.Pod, serviceEnvMap map[string]string) {
+// mutatePodHostname keeps the hostname of the tenant pod and drops its subdomain. The super
+// cluster kubelet derives the pod FQDN from the subdomain and the pod namespace, which would be
+// "<hostname>.<subdomain>.<super namespace>.svc.<super cluster domain>", a name the tenant DNS
+// does not serve. The tenant FQDN "<hostname>.<subdomain>.<namespace>.svc.<cluster domain>" is
+// served by the tenant DNS from the endpoints of the tenant headless service, which the tenant
+// control plane builds from the tenant pod that keeps its subdomain. Without a subdomain,
+// setHostnameAsFQDN sets the short hostname.
+func mutatePodHostname(p *PodMutateCtx) {
+	p.PPod.Spec.Subdomain = ""
+}
+
+func mutateContainerEnv(c *v1.Container, vPod, pPod *v1.Pod, serviceEnvMap map[string]string) {
 	// Inject env var from service
 	// 1. Do nothing if it conflicts with user-defined one.
 	// 2. Add remaining service environment vars
 	envNameMap := make(map[string]struct{})
+	expandsSubPath := hasSubPathExpr(c)
 	for j, env := range c.Env {
 		mutateDownwardAPIField(&c.Env[j], vPod)
+		if expandsSubPath {
+			mutateDownwardAPIMetadataField(&c.Env[j], vPod)
+		} else {
+			mutateDownwardAPIKeyField(&c.Env[j], vPod, pPod)
+		}
 		envNameMap[env.Name] = struct{}{}
 	}
 	for k, v := range serviceEnvMap {
@@ -219,6 +336,10 @@ func mutateContainerSecret(c *v1.Container, SASecretMap map[string]string, vPod
 	}
 }
 
+// mutateDownwardAPIField replaces the downward API references to the pod namespace and uid, which
+// always differ in the super pod, with the values of the tenant pod. The pod name is kept by the
+// super pod, and so are the container resources referenced by resourceFieldRef, so those references
+// are left as is.
 func mutateDownwardAPIField(env *v1.EnvVar, vPod *v1.Pod) {
 	if env.ValueFrom == nil {
 		return
@@ -236,6 +357,74 @@ func mutateDownwardAPIField(env *v1.EnvVar, vPod *v1.Pod) {
 	}
 }
 
+func hasSubPathExpr(c *v1.Container) bool {
+	for _, volumeMount := range c.VolumeMounts {
+		if volumeMount.SubPathExpr != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// mutateDownwardAPIMetadataField replaces the downward API references to the pod name, labels
+// and annotations with the values of the tenant pod. subPathExpr is expanded by the super
+// control plane kubelet from the env of the super pod, whose labels and annotations differ
+// from the tenant pod, e.g. opaque keys are stripped.
+func mutateDownwardAPIMetadataField(env *v1.EnvVar, vPod *v1.Pod) {
+	if env.ValueFrom == nil || env.ValueFrom.FieldRef == nil {
+		return
+	}
+	fieldPath := env.ValueFrom.FieldRef.FieldPath
+	if fieldPath == "metadata.name" {
+		env.Value = vPod.Name
+		env.ValueFrom = nil
+		return
+	}
+	if key, ok := downwardAPIKey(fieldPath, "metadata.labels"); ok {
+		env.Value = vPod.Labels[key]
+		env.ValueFrom = nil
+	} else if key, ok := downwardAPIKey(fieldPath, "metadata.annotations"); ok {
+		env.Value = vPod.Annotations[key]
+		env.ValueFrom = nil
+	}
+}
+
+// mutateDownwardAPIKeyField replaces the downward API references to a label or an annotation
+// of the pod with the value of the tenant pod, if the super pod does not carry the key with the
+// same value, e.g. an opaque key stripped by the syncer or a tenancy key set by it. The other
+// references are left as is, so the kubelet resolves them again when the container restarts.
+func mutateDownwardAPIKeyField(env *v1.EnvVar, vPod, pPod *v1.Pod) {
+	if env.ValueFrom == nil || env.ValueFrom.FieldRef == nil {
+		return
+	}
+	fieldPath := env.ValueFrom.FieldRef.FieldPath
+	var vMap, pMap map[string]string
+	key, ok := downwardAPIKey(fieldPath, "metadata.labels")
+	if ok {
+		vMap, pMap = vPod.Labels, pPod.Labels
+	} else if key, ok = downwardAPIKey(fieldPath, "metadata.annotations"); ok {
+		vMap, pMap = vPod.Annotations, pPod.Annotations
+	} else {
+		return
+	}
+	vValue, vFound := vMap[key]
+	pValue, pFound := pMap[key]
+	if vFound == pFound && vValue == pValue {
+		return
+	}
+	env.Value = vValue
+	env.ValueFrom = nil
+}
+
+// downwardAPIKey returns the key of a downward API field path of the form field['key'].
+func downwardAPIKey(fieldPath, field string) (string, bool) {
+	prefix := field + "['"
+	if !strings.HasPrefix(fieldPath, prefix) || !strings.HasSuffix(fieldPath, "']") || len(fieldPath) < len(prefix)+len("']") {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(fieldPath, prefix), "']"), true
+}
+
 func getServiceEnvVarMap(ns, cluster string, enableServiceLinks *bool, services []*v1.Service) (string, map[string]string) {
 	var (
 		serviceMap       = make(map[string]*v1.Service)
@@ -283,6 +472,12 @@ func getServiceEnvVarMap(ns, cluster string, enableServiceLinks *bool, services
 	return apiServerService, m
 }
 
+const (
+	// maxDNSNameservers and maxDNSSearchPaths are the limits the apiserver validates pod dnsConfig against.
+	maxDNSNameservers = 3
+	maxDNSSearchPaths = 6
+)
+
 func mutateDNSConfig(p *PodMutateCtx, vPod *v1.Pod, clusterDomain, nameServer string, dnsOption []v1.PodDNSConfigOption) {
 	// If the TenantAllowDNSPolicy feature gate is added AND if the vPod labels include
 	// tenancy.x-k8s.io/disable.dnsPolicyMutation: "true" then we should return without
@@ -298,6 +493,7 @@ func mutateDNSConfig(p *PodMutateCtx, vPod *v1.Pod, clusterDomain, nameServer st
 
 	switch dnsPolicy {
 	case v1.DNSNone:
+		mutateNoneDNS(p, dnsOption)
 		return
 	case v1.DNSClusterFirstWithHostNet:
 		mutateClusterFirstDNS(p, vPod, clusterDomain, nameServer, dnsOption)
@@ -329,7 +525,7 @@ func mutateClusterFirstDNS(p *PodMutateCtx, vPod *v1.Pod, clusterDomain, nameSer
 	// itself.
 	dnsConfig := &v1.PodDNSConfig{
 		Nameservers: []string{nameServer},
-		Options:     dnsOption,
+		Options:     append([]v1.PodDNSConfigOption{}, dnsOption...),
 	}
 
 	if clusterDomain != "" {
@@ -344,11 +540,33 @@ func mutateClusterFirstDNS(p *PodMutateCtx, vPod *v1.Pod, clusterDomain, nameSer
 		dnsConfig.Searches = omitDuplicates(append(dnsConfig.Searches, existingDNSConfig.Searches...))
 		dnsConfig.Options = omitDuplicatePodDNSConfigOption(append(dnsConfig.Options, existingDNSConfig.Options...))
 	}
+	// The merged config must pass the apiserver validation of a pod with the None policy. Like the
+	// kubelet does for ClusterFirst pods, the entries beyond the limits are dropped.
+	if len(dnsConfig.Nameservers) > maxDNSNameservers {
+		klog.Warningf("pod %s/%s of vc %s has more than %d nameservers, the extra ones are dropped", vPod.Namespace, vPod.Name, p.ClusterName, maxDNSNameservers)
+		dnsConfig.Nameservers = dnsConfig.Nameservers[:maxDNSNameservers]
+	}
+	if len(dnsConfig.Searches) > maxDNSSearchPaths {
+		klog.Warningf("pod %s/%s of vc %s has more than %d search paths, the extra ones are dropped", vPod.Namespace, vPod.Name, p.ClusterName, maxDNSSearchPaths)
+		dnsConfig.Searches = dnsConfig.Searches[:maxDNSSearchPaths]
+	}
 
 	p.PPod.Spec.DNSPolicy = v1.DNSNone
 	p.PPod.Spec.DNSConfig = dnsConfig
 }
 
+// mutateNoneDNS merges the global DNS options into the dnsConfig of a pod with the None policy.
+// The nameservers and searches are the pod's own, and the pod options take precedence over the
+// global options of the same name.
+func mutateNoneDNS(p *PodMutateCtx, dnsOption []v1.PodDNSConfigOption) {
+	existingDNSConfig := p.PPod.Spec.DNSConfig
+	if existingDNSConfig == nil || len(dnsOption) == 0 {
+		return
+	}
+	options := append([]v1.PodDNSConfigOption{}, dnsOption...)
+	existingDNSConfig.Options = omitDuplicatePodDNSConfigOption(append(options, existingDNSConfig.Options...))
+}
+
 func omitDuplicates(strs []string) []string {
 	uniqueStrs := make(map[string]bool)
 
@@ -437,11 +655,63 @@ func (s *serviceMutator) Mutate(vService *v1.Service) {
 }
 
 // this function aims to check if the service's ClusterIP is set or not
-// the objective is not to perform validation here
+// the objective is not to perform validation here. ExternalName services are
+// DNS aliases and never have a ClusterIP to rewrite.
 func isServiceIPSet(service *v1.Service) bool {
+	if service.Spec.Type == v1.ServiceTypeExternalName {
+		return false
+	}
 	return service.Spec.ClusterIP != v1.ClusterIPNone && service.Spec.ClusterIP != ""
 }
 
+// MutateServiceIPFamilies restricts the ipFamilies of the super service to the IP families of
+// the super cluster service network, given primary first, according to the ipFamilyPolicy. The
+// tenant apiserver has defaulted ipFamilies to its own families, which the super cluster rejects
+// when it does not support them. It returns an error if the super cluster cannot satisfy the
+// policy. No superFamilies leaves the service unchanged.
+func MutateServiceIPFamilies(pService *v1.Service, superFamilies []v1.IPFamily) error {
+	if len(superFamilies) == 0 || pService.Spec.Type == v1.ServiceTypeExternalName {
+		return nil
+	}
+	supported := make(map[v1.IPFamily]bool, len(superFamilies))
+	for _, family := range superFamilies {
+		supported[family] = true
+	}
+	var families []v1.IPFamily
+	for _, family := range pService.Spec.IPFamilies {
+		if supported[family] {
+			families = append(families, family)
+		}
+	}
+
+	policy := v1.IPFamilyPolicySingleStack
+	if pService.Spec.IPFamilyPolicy != nil {
+		policy = *pService.Spec.IPFamilyPolicy
+	}
+	switch policy {
+	case v1.IPFamilyPolicyRequireDualStack:
+		if len(superFamilies) < 2 {
+			return fmt.Errorf("service requires dual-stack but the super cluster only supports %v", superFamilies)
+		}
+		if len(families) != len(pService.Spec.IPFamilies) {
+			return fmt.Errorf("service ipFamilies %v are not all supported by the super cluster (%v)", pService.Spec.IPFamilies, superFamilies)
+		}
+	case v1.IPFamilyPolicyPreferDualStack:
+		if len(pService.Spec.IPFamilies) > 0 && len(families) == 0 {
+			return fmt.Errorf("none of the service ipFamilies %v is supported by the super cluster (%v)", pService.Spec.IPFamilies, superFamilies)
+		}
+		pService.Spec.IPFamilies = families
+	default:
+		if len(pService.Spec.IPFamilies) > 0 && !supported[pService.Spec.IPFamilies[0]] {
+			return fmt.Errorf("service ipFamily %s is not supported by the super cluster (%v)", pService.Spec.IPFamilies[0], superFamilies)
+		}
+		if len(pService.Spec.IPFamilies) > 1 {
+			pService.Spec.IPFamilies = pService.Spec.IPFamilies[:1]
+		}
+	}
+	return nil
+}
+
 type SecretMutateInterface interface {
 	Mutate(vSecret *v1.Secret, clusterName string)
 }
@@ -17,16 +17,21 @@ limitations under the License.
 package conversion
 
 import (
+	"strings"
 	"testing"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	utilconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
 )
 
 func Test_mutateDownwardAPIField(t *testing.T) {
@@ -174,6 +179,30 @@ func Test_mutateDownwardAPIField(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "env with resourceFieldRef",
+			pod:  aPod,
+			env: &v1.EnvVar{
+				Name: "env_name",
+				ValueFrom: &v1.EnvVarSource{
+					ResourceFieldRef: &v1.ResourceFieldSelector{
+						ContainerName: "c",
+						Resource:      "limits.memory",
+						Divisor:       resource.MustParse("1Mi"),
+					},
+				},
+			},
+			expectedEnv: &v1.EnvVar{
+				Name: "env_name",
+				ValueFrom: &v1.EnvVarSource{
+					ResourceFieldRef: &v1.ResourceFieldSelector{
+						ContainerName: "c",
+						Resource:      "limits.memory",
+						Divisor:       resource.MustParse("1Mi"),
+					},
+				},
+			},
+		},
 	} {
 		t.Run(tt.name, func(tc *testing.T) {
 			mutateDownwardAPIField(tt.env, tt.pod)
@@ -184,6 +213,230 @@ func Test_mutateDownwardAPIField(t *testing.T) {
 	}
 }
 
+func Test_mutateDownwardAPIKeyField(t *testing.T) {
+	fieldRefEnv := func(fieldPath string) *v1.EnvVar {
+		return &v1.EnvVar{Name: "env_name", ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{APIVersion: "v1", FieldPath: fieldPath}}}
+	}
+	valueEnv := func(value string) *v1.EnvVar {
+		return &v1.EnvVar{Name: "env_name", Value: value}
+	}
+	vPod := newPod(func(p *v1.Pod) {
+		p.Labels = map[string]string{"app": "web", "app.kubernetes.io/name": "frontend"}
+		p.Annotations = map[string]string{"shard": "s1", "kubernetes.io/description": "web frontend"}
+	})
+	pPod := newPod(func(p *v1.Pod) {
+		p.Namespace = ToSuperClusterNamespace("cluster", vPod.Namespace)
+		p.Labels = map[string]string{"app": "web", constants.LabelVCName: "vc"}
+		p.Annotations = map[string]string{"shard": "s1", constants.LabelCluster: "cluster"}
+	})
+
+	for _, tt := range []struct {
+		name        string
+		env         *v1.EnvVar
+		expectedEnv *v1.EnvVar
+	}{
+		{
+			name:        "env with the same label",
+			env:         fieldRefEnv("metadata.labels['app']"),
+			expectedEnv: fieldRefEnv("metadata.labels['app']"),
+		},
+		{
+			name:        "env with a stripped label",
+			env:         fieldRefEnv("metadata.labels['app.kubernetes.io/name']"),
+			expectedEnv: valueEnv("frontend"),
+		},
+		{
+			name:        "env with a super cluster only label",
+			env:         fieldRefEnv("metadata.labels['" + constants.LabelVCName + "']"),
+			expectedEnv: valueEnv(""),
+		},
+		{
+			name:        "env with a label missing in both pods",
+			env:         fieldRefEnv("metadata.labels['missing']"),
+			expectedEnv: fieldRefEnv("metadata.labels['missing']"),
+		},
+		{
+			name:        "env with the same annotation",
+			env:         fieldRefEnv("metadata.annotations['shard']"),
+			expectedEnv: fieldRefEnv("metadata.annotations['shard']"),
+		},
+		{
+			name:        "env with a stripped annotation",
+			env:         fieldRefEnv("metadata.annotations['kubernetes.io/description']"),
+			expectedEnv: valueEnv("web frontend"),
+		},
+		{
+			name:        "env with a super cluster only annotation",
+			env:         fieldRefEnv("metadata.annotations['" + constants.LabelCluster + "']"),
+			expectedEnv: valueEnv(""),
+		},
+		{
+			name:        "env with metadata.name",
+			env:         fieldRefEnv("metadata.name"),
+			expectedEnv: fieldRefEnv("metadata.name"),
+		},
+		{
+			name:        "env with all labels",
+			env:         fieldRefEnv("metadata.labels"),
+			expectedEnv: fieldRefEnv("metadata.labels"),
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			mutateDownwardAPIKeyField(tt.env, vPod, pPod)
+			if !equality.Semantic.DeepEqual(tt.env, tt.expectedEnv) {
+				tc.Errorf("expected env %+v, got %+v", tt.expectedEnv, tt.env)
+			}
+		})
+	}
+}
+
+func Test_mutateContainerEnvDownwardAPI(t *testing.T) {
+	fieldRefEnv := func(name, fieldPath string) v1.EnvVar {
+		return v1.EnvVar{Name: name, ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{APIVersion: "v1", FieldPath: fieldPath}}}
+	}
+	memoryLimitEnv := v1.EnvVar{Name: "MEMORY_LIMIT", ValueFrom: &v1.EnvVarSource{
+		ResourceFieldRef: &v1.ResourceFieldSelector{ContainerName: "c", Resource: "limits.memory", Divisor: resource.MustParse("1Mi")},
+	}}
+	vPod := newPod(func(p *v1.Pod) {
+		p.Labels = map[string]string{"app": "web", "app.kubernetes.io/name": "frontend"}
+		p.Annotations = map[string]string{"shard": "s1"}
+		p.Spec.Containers = []v1.Container{{
+			Name: "c",
+			Env: []v1.EnvVar{
+				fieldRefEnv("POD_NAME", "metadata.name"),
+				fieldRefEnv("POD_NAMESPACE", "metadata.namespace"),
+				fieldRefEnv("POD_UID", "metadata.uid"),
+				fieldRefEnv("APP", "metadata.labels['app']"),
+				fieldRefEnv("APP_NAME", "metadata.labels['app.kubernetes.io/name']"),
+				fieldRefEnv("SHARD", "metadata.annotations['shard']"),
+				memoryLimitEnv,
+			},
+			Resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("64Mi")}},
+		}}
+	})
+
+	pPod := vPod.DeepCopy()
+	pPod.Namespace = ToSuperClusterNamespace("cluster", vPod.Namespace)
+	pPod.UID = types.UID("d2e5f5c1-3c2f-4b0e-9d0a-1f7c8b1a6e42")
+	delete(pPod.Labels, "app.kubernetes.io/name")
+	pPod.Labels[constants.LabelVCName] = "vc"
+	pPod.Annotations[constants.LabelCluster] = "cluster"
+
+	mutateContainerEnv(&pPod.Spec.Containers[0], vPod, pPod, nil)
+
+	for i, vEnv := range vPod.Spec.Containers[0].Env {
+		pEnv := pPod.Spec.Containers[0].Env[i]
+		if vEnv.ValueFrom.FieldRef != nil {
+			expected := expandSubPathExpr("$("+vEnv.Name+")", vPod.Spec.Containers[0].Env, vPod)
+			if got := expandSubPathExpr("$("+pEnv.Name+")", pPod.Spec.Containers[0].Env, pPod); got != expected {
+				t.Errorf("expected env %s to resolve to %q, got %q", vEnv.Name, expected, got)
+			}
+			continue
+		}
+		if !equality.Semantic.DeepEqual(pEnv, vEnv) {
+			t.Errorf("expected env %+v, got %+v", vEnv, pEnv)
+		}
+	}
+	for _, name := range []string{"POD_NAME", "APP", "SHARD"} {
+		for _, env := range pPod.Spec.Containers[0].Env {
+			if env.Name == name && env.ValueFrom == nil {
+				t.Errorf("expected env %s to keep the fieldRef", name)
+			}
+		}
+	}
+}
+
+func Test_mutateSubPathExprEnv(t *testing.T) {
+	fieldRefEnv := func(name, fieldPath string) v1.EnvVar {
+		return v1.EnvVar{Name: name, ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{APIVersion: "v1", FieldPath: fieldPath}}}
+	}
+	env := []v1.EnvVar{
+		fieldRefEnv("POD_NAME", "metadata.name"),
+		fieldRefEnv("POD_NAMESPACE", "metadata.namespace"),
+		fieldRefEnv("POD_UID", "metadata.uid"),
+		fieldRefEnv("APP", "metadata.labels['app']"),
+		fieldRefEnv("APP_NAME", "metadata.labels['app.kubernetes.io/name']"),
+		fieldRefEnv("SHARD", "metadata.annotations['shard']"),
+		{Name: "LITERAL", Value: "data"},
+	}
+	subPathExpr := "$(POD_NAMESPACE)/$(POD_NAME)/$(POD_UID)/$(APP)/$(APP_NAME)/$(SHARD)/$(LITERAL)"
+
+	vPod := newPod(func(p *v1.Pod) {
+		p.Labels = map[string]string{"app": "web", "app.kubernetes.io/name": "frontend"}
+		p.Annotations = map[string]string{"shard": "s1"}
+	})
+
+	for _, tt := range []struct {
+		name                  string
+		volumeMounts          []v1.VolumeMount
+		expectedLabelFieldRef bool
+	}{
+		{
+			name:                  "container with subPathExpr",
+			volumeMounts:          []v1.VolumeMount{{Name: "data", MountPath: "/data", SubPathExpr: subPathExpr}},
+			expectedLabelFieldRef: false,
+		},
+		{
+			name:                  "container without subPathExpr",
+			volumeMounts:          []v1.VolumeMount{{Name: "data", MountPath: "/data", SubPath: "data"}},
+			expectedLabelFieldRef: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vPod := vPod.DeepCopy()
+			vPod.Spec.Containers = []v1.Container{{Name: "c", Env: env, VolumeMounts: tt.volumeMounts}}
+
+			// The super pod lives in a prefixed namespace, carries the tenancy metadata and
+			// has the opaque keys stripped.
+			pPod := vPod.DeepCopy()
+			pPod.Namespace = ToSuperClusterNamespace("cluster", vPod.Namespace)
+			delete(pPod.Labels, "app.kubernetes.io/name")
+			pPod.Annotations[constants.LabelCluster] = "cluster"
+			pPod.Labels[constants.LabelVCName] = "vc"
+
+			mutateContainerEnv(&pPod.Spec.Containers[0], vPod, pPod, nil)
+
+			isFieldRef := pPod.Spec.Containers[0].Env[3].ValueFrom != nil
+			if isFieldRef != tt.expectedLabelFieldRef {
+				tc.Errorf("expected label fieldRef kept %v, got %v", tt.expectedLabelFieldRef, isFieldRef)
+			}
+			if !tt.expectedLabelFieldRef {
+				expected := expandSubPathExpr(subPathExpr, vPod.Spec.Containers[0].Env, vPod)
+				if got := expandSubPathExpr(subPathExpr, pPod.Spec.Containers[0].Env, pPod); got != expected {
+					tc.Errorf("expected subPathExpr to expand to %q, got %q", expected, got)
+				}
+			}
+		})
+	}
+}
+
+// expandSubPathExpr expands subPathExpr from the container env against the pod the way the kubelet does.
+func expandSubPathExpr(subPathExpr string, env []v1.EnvVar, pod *v1.Pod) string {
+	fieldValue := func(fieldPath string) string {
+		switch {
+		case fieldPath == "metadata.name":
+			return pod.Name
+		case fieldPath == "metadata.namespace":
+			return pod.Namespace
+		case fieldPath == "metadata.uid":
+			return string(pod.UID)
+		case strings.HasPrefix(fieldPath, "metadata.labels['"):
+			return pod.Labels[strings.TrimSuffix(strings.TrimPrefix(fieldPath, "metadata.labels['"), "']")]
+		case strings.HasPrefix(fieldPath, "metadata.annotations['"):
+			return pod.Annotations[strings.TrimSuffix(strings.TrimPrefix(fieldPath, "metadata.annotations['"), "']")]
+		}
+		return ""
+	}
+	for _, e := range env {
+		value := e.Value
+		if e.ValueFrom != nil && e.ValueFrom.FieldRef != nil {
+			value = fieldValue(e.ValueFrom.FieldRef.FieldPath)
+		}
+		subPathExpr = strings.ReplaceAll(subPathExpr, "$("+e.Name+")", value)
+	}
+	return subPathExpr
+}
+
 func Test_mutateContainerSecret(t *testing.T) {
 	for _, tt := range []struct {
 		name              string
@@ -389,6 +642,46 @@ func Test_mutateDNSConfig(t *testing.T) {
 			expectedDNSPolicy: &dnsNone,
 			expectedDNSConfig: &v1.PodDNSConfig{
 				Nameservers: []string{"0.0.0.0"},
+				Options:     defaultOptions,
+			},
+		},
+		{
+			name: "dns policy set to none with config options",
+			args: args{
+				p: podMutateCtxFunc(v1.DNSNone, &v1.PodDNSConfig{
+					Nameservers: []string{"1.1.1.1"},
+					Searches:    []string{"example.com"},
+					Options: []v1.PodDNSConfigOption{
+						{
+							Name:  "ndots",
+							Value: pointer.StringPtr("2"),
+						},
+						{
+							Name: "edns0",
+						},
+					},
+				}, false),
+				vPod:          newPod(),
+				clusterDomain: "cluster.local",
+				nameServer:    "0.0.0.0",
+				dnsoptions:    defaultOptions,
+			},
+			expectedDNSPolicy: &dnsNone,
+			expectedDNSConfig: &v1.PodDNSConfig{
+				Nameservers: []string{"1.1.1.1"},
+				Searches:    []string{"example.com"},
+				Options: []v1.PodDNSConfigOption{
+					{
+						Name: "use-vc",
+					},
+					{
+						Name:  "ndots",
+						Value: pointer.StringPtr("2"),
+					},
+					{
+						Name: "edns0",
+					},
+				},
 			},
 		},
 		{
@@ -460,6 +753,100 @@ func Test_mutateDNSConfig(t *testing.T) {
 				Options:     defaultOptions,
 			},
 		},
+		{
+			name: "dns policy set to cluster first with config searches and options",
+			args: args{
+				p: podMutateCtxFunc(v1.DNSClusterFirst, &v1.PodDNSConfig{
+					Searches: []string{"example.com"},
+					Options: []v1.PodDNSConfigOption{
+						{
+							Name:  "ndots",
+							Value: pointer.StringPtr("2"),
+						},
+					},
+				}, false),
+				vPod:          newPod(),
+				clusterDomain: "cluster.local",
+				nameServer:    "0.0.0.0",
+				dnsoptions:    defaultOptions,
+			},
+			expectedDNSPolicy: &dnsNone,
+			expectedDNSConfig: &v1.PodDNSConfig{
+				Nameservers: []string{"0.0.0.0"},
+				Searches:    []string{"ns.svc.cluster.local", "svc.cluster.local", "cluster.local", "example.com"},
+				Options: []v1.PodDNSConfigOption{
+					{
+						Name: "use-vc",
+					},
+					{
+						Name:  "ndots",
+						Value: pointer.StringPtr("2"),
+					},
+				},
+			},
+		},
+		{
+			name: "dns policy set to cluster first with config over the limits",
+			args: args{
+				p: podMutateCtxFunc(v1.DNSClusterFirst, &v1.PodDNSConfig{
+					Nameservers: []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"},
+					Searches:    []string{"a.com", "b.com", "c.com", "d.com"},
+				}, false),
+				vPod:          newPod(),
+				clusterDomain: "cluster.local",
+				nameServer:    "0.0.0.0",
+				dnsoptions:    defaultOptions,
+			},
+			expectedDNSPolicy: &dnsNone,
+			expectedDNSConfig: &v1.PodDNSConfig{
+				Nameservers: []string{"0.0.0.0", "1.1.1.1", "2.2.2.2"},
+				Searches:    []string{"ns.svc.cluster.local", "svc.cluster.local", "cluster.local", "a.com", "b.com", "c.com"},
+				Options:     defaultOptions,
+			},
+		},
+		{
+			name: "dns policy set to cluster first with host network on host network",
+			args: args{
+				p:          podMutateCtxFunc(v1.DNSClusterFirstWithHostNet, nil, true),
+				vPod:       newPod(),
+				nameServer: "0.0.0.0",
+				dnsoptions: defaultOptions,
+			},
+			expectedDNSPolicy: &dnsNone,
+			expectedDNSConfig: &v1.PodDNSConfig{
+				Nameservers: []string{"0.0.0.0"},
+				Options:     defaultOptions,
+			},
+		},
+		{
+			name: "dns policy set to cluster first without cluster dns",
+			args: args{
+				p: podMutateCtxFunc(v1.DNSClusterFirst, &v1.PodDNSConfig{
+					Searches: []string{"example.com"},
+				}, false),
+				vPod:       newPod(),
+				dnsoptions: defaultOptions,
+			},
+			expectedDNSPolicy: &dnsDefault,
+			expectedDNSConfig: &v1.PodDNSConfig{
+				Searches: []string{"example.com"},
+			},
+		},
+		{
+			name: "dns policy set to default with config",
+			args: args{
+				p: podMutateCtxFunc(v1.DNSDefault, &v1.PodDNSConfig{
+					Searches: []string{"example.com"},
+				}, false),
+				vPod:       newPod(),
+				nameServer: "0.0.0.0",
+				dnsoptions: defaultOptions,
+			},
+			expectedDNSPolicy: &dnsDefault,
+			expectedDNSConfig: &v1.PodDNSConfig{
+				Searches: []string{"example.com"},
+			},
+		},
 		{
 			name: "dns policy set to cluster first host network",
 			args: args{
@@ -585,6 +972,380 @@ func Test_mutateDNSConfig(t *testing.T) {
 	}
 }
 
+func Test_mutatePodHostname(t *testing.T) {
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "name",
+			Namespace: "ns",
+			UID:       "d64ea0c0-91f8-46f5-8643-c0cab32ab0cd",
+		},
+	}
+	for _, tt := range []struct {
+		name              string
+		hostname          string
+		subdomain         string
+		setHostnameAsFQDN *bool
+	}{
+		{
+			name: "no hostname",
+		},
+		{
+			name:     "hostname",
+			hostname: "web-0",
+		},
+		{
+			name:      "subdomain",
+			subdomain: "web",
+		},
+		{
+			name:      "hostname and subdomain",
+			hostname:  "web-0",
+			subdomain: "web",
+		},
+		{
+			name:              "hostname and subdomain as fqdn",
+			hostname:          "web-0",
+			subdomain:         "web",
+			setHostnameAsFQDN: pointer.BoolPtr(true),
+		},
+		{
+			name:              "hostname as fqdn without subdomain",
+			hostname:          "web-0",
+			setHostnameAsFQDN: pointer.BoolPtr(true),
+		},
+		{
+			name:              "hostname and subdomain not as fqdn",
+			hostname:          "web-0",
+			subdomain:         "web",
+			setHostnameAsFQDN: pointer.BoolPtr(false),
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vPod := newPod(func(p *v1.Pod) {
+				p.Spec.Hostname = tt.hostname
+				p.Spec.Subdomain = tt.subdomain
+				p.Spec.SetHostnameAsFQDN = tt.setHostnameAsFQDN
+			})
+			conv := Convertor(&config.SyncerConfiguration{}, &fakeMultiClusterController{vc: vc})
+			obj, err := conv.BuildSuperClusterObject("cluster", vPod)
+			if err != nil {
+				tc.Fatalf("unexpected error: %v", err)
+			}
+			pPod := obj.(*v1.Pod)
+			mutatePodHostname(&PodMutateCtx{ClusterName: "cluster", PPod: pPod})
+
+			if pPod.Spec.Hostname != tt.hostname {
+				tc.Errorf("expected hostname %q, got %q", tt.hostname, pPod.Spec.Hostname)
+			}
+			if pPod.Spec.Subdomain != "" {
+				tc.Errorf("expected no subdomain, got %q", pPod.Spec.Subdomain)
+			}
+			if !equality.Semantic.DeepEqual(pPod.Spec.SetHostnameAsFQDN, tt.setHostnameAsFQDN) {
+				tc.Errorf("expected setHostnameAsFQDN %v, got %v", tt.setHostnameAsFQDN, pPod.Spec.SetHostnameAsFQDN)
+			}
+			if vPod.Spec.Subdomain != tt.subdomain {
+				tc.Errorf("expected the tenant pod to keep subdomain %q, got %q", tt.subdomain, vPod.Spec.Subdomain)
+			}
+		})
+	}
+}
+
+func Test_mutatePodNodeAffinity(t *testing.T) {
+	defer func(id string) { utilconstants.SuperClusterID = id }(utilconstants.SuperClusterID)
+	utilconstants.SuperClusterID = "super-1"
+
+	requiredTerms := func(terms ...v1.NodeSelectorTerm) *v1.Affinity {
+		return &v1.Affinity{
+			NodeAffinity: &v1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: terms},
+			},
+		}
+	}
+	expressions := func(reqs ...v1.NodeSelectorRequirement) v1.NodeSelectorTerm {
+		return v1.NodeSelectorTerm{MatchExpressions: reqs}
+	}
+	zone := v1.NodeSelectorRequirement{Key: v1.LabelTopologyZone, Operator: v1.NodeSelectorOpIn, Values: []string{"zone-a"}}
+	vNodeName := v1.NodeSelectorTerm{
+		MatchFields: []v1.NodeSelectorRequirement{{Key: "metadata.name", Operator: v1.NodeSelectorOpIn, Values: []string{"node-1"}}},
+	}
+
+	for _, tt := range []struct {
+		name                 string
+		pooling              bool
+		nodeSelector         map[string]string
+		affinity             *v1.Affinity
+		expectedNodeSelector map[string]string
+		expectedAffinity     *v1.Affinity
+	}{
+		{
+			name:                 "node selector on super node labels",
+			nodeSelector:         map[string]string{v1.LabelHostname: "node-1"},
+			expectedNodeSelector: map[string]string{v1.LabelHostname: "node-1"},
+		},
+		{
+			name:                 "node selector on vnode label",
+			nodeSelector:         map[string]string{v1.LabelHostname: "node-1", constants.LabelVirtualNode: "true"},
+			expectedNodeSelector: map[string]string{v1.LabelHostname: "node-1"},
+		},
+		{
+			name:                 "node selector on vnode label not matching any vnode",
+			nodeSelector:         map[string]string{constants.LabelVirtualNode: "false"},
+			expectedNodeSelector: map[string]string{constants.LabelVirtualNode: "false"},
+		},
+		{
+			name:                 "node selector on super cluster id",
+			pooling:              true,
+			nodeSelector:         map[string]string{constants.LabelSuperClusterID: "super-1"},
+			expectedNodeSelector: map[string]string{},
+		},
+		{
+			name:                 "node selector on another super cluster id",
+			pooling:              true,
+			nodeSelector:         map[string]string{constants.LabelSuperClusterID: "super-2"},
+			expectedNodeSelector: map[string]string{constants.LabelSuperClusterID: "super-2"},
+		},
+		{
+			name:             "vnode name",
+			affinity:         requiredTerms(vNodeName),
+			expectedAffinity: requiredTerms(vNodeName),
+		},
+		{
+			name:             "super node labels",
+			affinity:         requiredTerms(expressions(zone)),
+			expectedAffinity: requiredTerms(expressions(zone)),
+		},
+		{
+			name: "vnode label exists",
+			affinity: requiredTerms(
+				expressions(zone, v1.NodeSelectorRequirement{Key: constants.LabelVirtualNode, Operator: v1.NodeSelectorOpExists}),
+			),
+			expectedAffinity: requiredTerms(expressions(zone, superNodeMatchAll)),
+		},
+		{
+			name: "only vnode label",
+			affinity: requiredTerms(
+				expressions(v1.NodeSelectorRequirement{Key: constants.LabelVirtualNode, Operator: v1.NodeSelectorOpIn, Values: []string{"true"}}),
+			),
+			expectedAffinity: requiredTerms(expressions(superNodeMatchAll)),
+		},
+		{
+			name: "vnode label does not exist",
+			affinity: requiredTerms(
+				expressions(v1.NodeSelectorRequirement{Key: constants.LabelVirtualNode, Operator: v1.NodeSelectorOpDoesNotExist}),
+				vNodeName,
+			),
+			expectedAffinity: requiredTerms(expressions(superNodeMatchNone), vNodeName),
+		},
+		{
+			name:    "super cluster id",
+			pooling: true,
+			affinity: requiredTerms(
+				expressions(v1.NodeSelectorRequirement{Key: constants.LabelSuperClusterID, Operator: v1.NodeSelectorOpIn, Values: []string{"super-1", "super-2"}}),
+				expressions(v1.NodeSelectorRequirement{Key: constants.LabelSuperClusterID, Operator: v1.NodeSelectorOpNotIn, Values: []string{"super-1"}}),
+			),
+			expectedAffinity: requiredTerms(expressions(superNodeMatchAll), expressions(superNodeMatchNone)),
+		},
+		{
+			name: "super cluster id without pooling",
+			affinity: requiredTerms(
+				expressions(v1.NodeSelectorRequirement{Key: constants.LabelSuperClusterID, Operator: v1.NodeSelectorOpExists}),
+			),
+			expectedAffinity: requiredTerms(expressions(superNodeMatchNone)),
+		},
+		{
+			name: "preferred vnode label",
+			affinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
+						{Weight: 1, Preference: expressions(zone, v1.NodeSelectorRequirement{Key: constants.LabelVirtualNode, Operator: v1.NodeSelectorOpExists})},
+					},
+				},
+			},
+			expectedAffinity: &v1.Affinity{
+				NodeAffinity: &v1.NodeAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
+						{Weight: 1, Preference: expressions(zone, superNodeMatchAll)},
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer featuregate.DefaultFeatureGate.Set(featuregate.SuperClusterPooling, false)
+			featuregate.DefaultFeatureGate.Set(featuregate.SuperClusterPooling, tt.pooling)
+
+			pPod := &v1.Pod{
+				Spec: v1.PodSpec{
+					NodeSelector: tt.nodeSelector,
+					Affinity:     tt.affinity,
+				},
+			}
+			mutatePodNodeAffinity(pPod)
+			if !equality.Semantic.DeepEqual(pPod.Spec.NodeSelector, tt.expectedNodeSelector) {
+				t.Errorf("expected node selector %v, got %v", tt.expectedNodeSelector, pPod.Spec.NodeSelector)
+			}
+			if !equality.Semantic.DeepEqual(pPod.Spec.Affinity, tt.expectedAffinity) {
+				t.Errorf("expected affinity %+v, got %+v", tt.expectedAffinity, pPod.Spec.Affinity)
+			}
+		})
+	}
+}
+
+func TestServiceMutate(t *testing.T) {
+	for _, tt := range []struct {
+		name                string
+		spec                v1.ServiceSpec
+		expectedClusterIP   string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name: "cluster ip",
+			spec: v1.ServiceSpec{
+				Type:      v1.ServiceTypeClusterIP,
+				ClusterIP: "10.0.0.1",
+				Ports:     []v1.ServicePort{{Port: 80}},
+			},
+			expectedAnnotations: map[string]string{constants.LabelClusterIP: "10.0.0.1"},
+		},
+		{
+			name: "headless",
+			spec: v1.ServiceSpec{
+				Type:      v1.ServiceTypeClusterIP,
+				ClusterIP: v1.ClusterIPNone,
+			},
+			expectedClusterIP: v1.ClusterIPNone,
+		},
+		{
+			name: "external name",
+			spec: v1.ServiceSpec{
+				Type:         v1.ServiceTypeExternalName,
+				ExternalName: "db.example.com",
+				Ports:        []v1.ServicePort{{Port: 5432}},
+			},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			vService := &v1.Service{Spec: tt.spec}
+			pService := vService.DeepCopy()
+			VC(nil, "").Service(pService).Mutate(vService)
+
+			if pService.Spec.ClusterIP != tt.expectedClusterIP {
+				tc.Errorf("expected clusterIP %q, got %q", tt.expectedClusterIP, pService.Spec.ClusterIP)
+			}
+			if !equality.Semantic.DeepEqual(pService.Annotations, tt.expectedAnnotations) {
+				tc.Errorf("expected annotations %v, got %v", tt.expectedAnnotations, pService.Annotations)
+			}
+			if pService.Spec.Type != tt.spec.Type || pService.Spec.ExternalName != tt.spec.ExternalName {
+				tc.Errorf("expected type %q and externalName %q, got %q and %q", tt.spec.Type, tt.spec.ExternalName, pService.Spec.Type, pService.Spec.ExternalName)
+			}
+		})
+	}
+}
+
+func TestMutateServiceIPFamilies(t *testing.T) {
+	ipv4, ipv6 := v1.IPv4Protocol, v1.IPv6Protocol
+	singleStack, preferDualStack, requireDualStack := v1.IPFamilyPolicySingleStack, v1.IPFamilyPolicyPreferDualStack, v1.IPFamilyPolicyRequireDualStack
+	for _, tt := range []struct {
+		name          string
+		serviceType   v1.ServiceType
+		policy        *v1.IPFamilyPolicyType
+		families      []v1.IPFamily
+		superFamilies []v1.IPFamily
+		expected      []v1.IPFamily
+		expectedErr   bool
+	}{
+		{
+			name:     "super families unknown",
+			policy:   &requireDualStack,
+			families: []v1.IPFamily{ipv4, ipv6},
+			expected: []v1.IPFamily{ipv4, ipv6},
+		},
+		{
+			name:          "single-stack supported",
+			policy:        &singleStack,
+			families:      []v1.IPFamily{ipv4},
+			superFamilies: []v1.IPFamily{ipv4},
+			expected:      []v1.IPFamily{ipv4},
+		},
+		{
+			name:          "single-stack by default",
+			families:      []v1.IPFamily{ipv6},
+			superFamilies: []v1.IPFamily{ipv4, ipv6},
+			expected:      []v1.IPFamily{ipv6},
+		},
+		{
+			name:          "single-stack not supported",
+			policy:        &singleStack,
+			families:      []v1.IPFamily{ipv6},
+			superFamilies: []v1.IPFamily{ipv4},
+			expectedErr:   true,
+		},
+		{
+			name:          "prefer-dual-stack on single-stack super cluster",
+			policy:        &preferDualStack,
+			families:      []v1.IPFamily{ipv6, ipv4},
+			superFamilies: []v1.IPFamily{ipv4},
+			expected:      []v1.IPFamily{ipv4},
+		},
+		{
+			name:          "prefer-dual-stack on dual-stack super cluster",
+			policy:        &preferDualStack,
+			families:      []v1.IPFamily{ipv6, ipv4},
+			superFamilies: []v1.IPFamily{ipv4, ipv6},
+			expected:      []v1.IPFamily{ipv6, ipv4},
+		},
+		{
+			name:          "prefer-dual-stack without supported family",
+			policy:        &preferDualStack,
+			families:      []v1.IPFamily{ipv6},
+			superFamilies: []v1.IPFamily{ipv4},
+			expectedErr:   true,
+		},
+		{
+			name:          "require-dual-stack on dual-stack super cluster",
+			policy:        &requireDualStack,
+			families:      []v1.IPFamily{ipv4, ipv6},
+			superFamilies: []v1.IPFamily{ipv6, ipv4},
+			expected:      []v1.IPFamily{ipv4, ipv6},
+		},
+		{
+			name:          "require-dual-stack on single-stack super cluster",
+			policy:        &requireDualStack,
+			families:      []v1.IPFamily{ipv4, ipv6},
+			superFamilies: []v1.IPFamily{ipv4},
+			expectedErr:   true,
+		},
+		{
+			name:          "external name",
+			serviceType:   v1.ServiceTypeExternalName,
+			superFamilies: []v1.IPFamily{ipv4},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			service := &v1.Service{
+				Spec: v1.ServiceSpec{
+					Type:           tt.serviceType,
+					IPFamilyPolicy: tt.policy,
+					IPFamilies:     tt.families,
+				},
+			}
+			err := MutateServiceIPFamilies(service, tt.superFamilies)
+			if tt.expectedErr {
+				if err == nil {
+					tc.Errorf("expected an error, got ipFamilies %v", service.Spec.IPFamilies)
+				}
+				return
+			}
+			if err != nil {
+				tc.Fatalf("unexpected error: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(service.Spec.IPFamilies, tt.expected) {
+				tc.Errorf("expected ipFamilies %v, got %v", tt.expected, service.Spec.IPFamilies)
+			}
+		})
+	}
+}
+
 func newPod(fns ...func(*v1.Pod)) *v1.Pod {
 	pod := &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
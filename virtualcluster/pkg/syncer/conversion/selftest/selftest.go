@@ -0,0 +1,250 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package selftest round-trips a corpus of representative tenant objects through the
+// syncer conversion (tenant -> super -> tenant view) and reports the fields that do not
+// survive. Fields are most commonly lost when the tenant object carries fields that are
+// unknown to the vendored k8s.io/api version, so the corpus should be extended whenever
+// a new field needs to be supported.
+package selftest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+//go:embed corpus/*.json
+var corpus embed.FS
+
+const selfTestCluster = "selftest"
+
+// ignoredPaths are fields that the syncer resets on purpose and does not restore in the tenant view.
+var ignoredPaths = []string{
+	"metadata.resourceVersion",
+	"metadata.selfLink",
+	"metadata.generation",
+	"metadata.finalizers",
+	"metadata.managedFields",
+}
+
+// Result is the outcome of round-tripping one corpus object.
+type Result struct {
+	// Name is the corpus file of the object.
+	Name string
+	// Kind is the kind of the object.
+	Kind string
+	// LostFields are the JSON paths of the fields that are missing or changed in the tenant view.
+	LostFields []string
+}
+
+// Run round-trips every object of the built-in corpus and returns one Result per object.
+func Run(syncerConfig *config.SyncerConfiguration) ([]Result, error) {
+	entries, err := corpus.ReadDir("corpus")
+	if err != nil {
+		return nil, err
+	}
+
+	var results []Result
+	for _, entry := range entries {
+		name := path.Join("corpus", entry.Name())
+		raw, err := corpus.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		result, err := RoundTrip(syncerConfig, entry.Name(), raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to round-trip %s: %v", name, err)
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// RoundTrip decodes the raw tenant object into its typed struct, converts it into the super
+// cluster object, converts that back into the tenant view and reports the fields of the raw
+// object that are not present with the same value in the tenant view.
+func RoundTrip(syncerConfig *config.SyncerConfiguration, name string, raw []byte) (*Result, error) {
+	obj, gvk, err := scheme.Codecs.UniversalDeserializer().Decode(raw, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	tenantObj, ok := obj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("unsupported object %s", gvk.String())
+	}
+
+	superObj, err := conversion.Convertor(syncerConfig, newFakeMultiClusterController()).BuildSuperClusterObject(selfTestCluster, tenantObj)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantView, err := toTenantView(superObj)
+	if err != nil {
+		return nil, err
+	}
+
+	var original, roundTripped interface{}
+	if err := json.Unmarshal(raw, &original); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tenantView, &roundTripped); err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Name:       name,
+		Kind:       gvk.Kind,
+		LostFields: lostFields("", original, roundTripped),
+	}, nil
+}
+
+// toTenantView restores the tenant scoped metadata that the conversion moves into annotations
+// and strips the syncer owned metadata, which is what the tenant observes for the object.
+func toTenantView(superObj client.Object) ([]byte, error) {
+	obj := superObj.DeepCopyObject().(client.Object)
+	anno := obj.GetAnnotations()
+
+	obj.SetNamespace(anno[constants.LabelNamespace])
+	obj.SetUID(types.UID(anno[constants.LabelUID]))
+	if ownerReferences := anno[constants.LabelOwnerReferences]; ownerReferences != "" {
+		var refs []metav1.OwnerReference
+		if err := json.Unmarshal([]byte(ownerReferences), &refs); err != nil {
+			return nil, err
+		}
+		obj.SetOwnerReferences(refs)
+	}
+
+	return json.Marshal(obj)
+}
+
+// lostFields returns the sorted leaf paths of expected that are missing or different in actual.
+func lostFields(prefix string, expected, actual interface{}) []string {
+	for _, ignored := range ignoredPaths {
+		if prefix == ignored {
+			return nil
+		}
+	}
+
+	var lost []string
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, _ := actual.(map[string]interface{})
+		for k, v := range e {
+			p := k
+			if prefix != "" {
+				p = prefix + "." + k
+			}
+			av, ok := a[k]
+			if !ok {
+				if !isEmpty(v) {
+					lost = append(lost, p)
+				}
+				continue
+			}
+			lost = append(lost, lostFields(p, v, av)...)
+		}
+	case []interface{}:
+		a, _ := actual.([]interface{})
+		for i, v := range e {
+			p := fmt.Sprintf("%s[%d]", prefix, i)
+			if i >= len(a) {
+				lost = append(lost, p)
+				continue
+			}
+			lost = append(lost, lostFields(p, v, a[i])...)
+		}
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			lost = append(lost, prefix)
+		}
+	}
+	sort.Strings(lost)
+	return lost
+}
+
+// isEmpty reports whether v is a JSON value that is equivalent to an omitted field.
+func isEmpty(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(t) == 0
+	case []interface{}:
+		return len(t) == 0
+	}
+	return false
+}
+
+// Report writes a human readable summary of the results and returns the number of objects
+// that lost fields.
+func Report(results []Result) (string, int) {
+	var b strings.Builder
+	failed := 0
+	for _, r := range results {
+		if len(r.LostFields) == 0 {
+			fmt.Fprintf(&b, "PASS %s (%s)\n", r.Name, r.Kind)
+			continue
+		}
+		failed++
+		fmt.Fprintf(&b, "FAIL %s (%s): %d field(s) lost\n", r.Name, r.Kind, len(r.LostFields))
+		for _, f := range r.LostFields {
+			fmt.Fprintf(&b, "    %s\n", f)
+		}
+	}
+	return b.String(), failed
+}
+
+// fakeMultiClusterController serves the VirtualCluster that owns the self test objects.
+type fakeMultiClusterController struct {
+	mc.MultiClusterInterface
+	vc *v1alpha1.VirtualCluster
+}
+
+func newFakeMultiClusterController() *fakeMultiClusterController {
+	return &fakeMultiClusterController{
+		vc: &v1alpha1.VirtualCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      selfTestCluster,
+				Namespace: "default",
+				UID:       "00000000-0000-0000-0000-000000000000",
+			},
+		},
+	}
+}
+
+func (f *fakeMultiClusterController) GetClusterObject(string) (client.Object, error) {
+	return f.vc, nil
+}
+
+func (f *fakeMultiClusterController) GetOwnerInfo(string) (string, string, string, error) {
+	return f.vc.Name, f.vc.Namespace, string(f.vc.UID), nil
+}
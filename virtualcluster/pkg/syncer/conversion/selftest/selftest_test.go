@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selftest
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+func TestRunCorpus(t *testing.T) {
+	results, err := Run(&config.SyncerConfiguration{
+		DefaultOpaqueMetaDomains: []string{"kubernetes.io", "k8s.io"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatalf("expected corpus to be non-empty")
+	}
+	if report, failed := Report(results); failed != 0 {
+		t.Errorf("conversion lost fields:\n%s", report)
+	}
+}
+
+func TestRoundTripReportsLostFields(t *testing.T) {
+	raw := []byte(`{
+  "apiVersion": "v1",
+  "kind": "Pod",
+  "metadata": {"name": "p", "namespace": "default", "uid": "123"},
+  "spec": {
+    "containers": [{"name": "c", "image": "busybox"}],
+    "unknownPodField": true,
+    "initContainers": [{"name": "sidecar", "image": "busybox", "unknownContainerField": "Always"}]
+  }
+}`)
+	result, err := RoundTrip(&config.SyncerConfiguration{}, "pod", raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"spec.initContainers[0].unknownContainerField", "spec.unknownPodField"}
+	if !reflect.DeepEqual(result.LostFields, expected) {
+		t.Errorf("expected lost fields %v, got %v", expected, result.LostFields)
+	}
+}
+
+func TestLostFields(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		expected interface{}
+		actual   interface{}
+		lost     []string
+	}{
+		{
+			name:     "equal",
+			expected: map[string]interface{}{"a": "b"},
+			actual:   map[string]interface{}{"a": "b"},
+		},
+		{
+			name:     "extra field in actual",
+			expected: map[string]interface{}{"a": "b"},
+			actual:   map[string]interface{}{"a": "b", "c": "d"},
+		},
+		{
+			name:     "empty field omitted",
+			expected: map[string]interface{}{"a": map[string]interface{}{}},
+			actual:   map[string]interface{}{},
+		},
+		{
+			name:     "changed value",
+			expected: map[string]interface{}{"a": map[string]interface{}{"b": "c"}},
+			actual:   map[string]interface{}{"a": map[string]interface{}{"b": "d"}},
+			lost:     []string{"a.b"},
+		},
+		{
+			name:     "shorter list",
+			expected: map[string]interface{}{"a": []interface{}{"x", "y"}},
+			actual:   map[string]interface{}{"a": []interface{}{"x"}},
+			lost:     []string{"a[1]"},
+		},
+		{
+			name:     "ignored path",
+			expected: map[string]interface{}{"metadata": map[string]interface{}{"resourceVersion": "1"}},
+			actual:   map[string]interface{}{"metadata": map[string]interface{}{}},
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			lost := lostFields("", tt.expected, tt.actual)
+			if len(lost) != len(tt.lost) || (len(lost) != 0 && !reflect.DeepEqual(lost, tt.lost)) {
+				tc.Errorf("expected lost fields %v, got %v", tt.lost, lost)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conversion
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+// SuperClusterWorkloadControllersEnabled returns true if the Virtual Cluster lets the super cluster
+// controller-manager reconcile its Deployments and ReplicaSets.
+func SuperClusterWorkloadControllersEnabled(vc *v1alpha1.VirtualCluster) bool {
+	return vc.GetAnnotations()[constants.LabelSuperClusterWorkloadControllers] == "true"
+}
+
+// MutateWorkloadPodTemplate mutates the pod template of a workload synced to the super cluster.
+// The pods created from the template by the super cluster controller-manager are not tenant pods
+// and skip the default pod conversion, so the super cluster service account token is not mounted
+// when the syncer disables it for tenant pods. The template is expected to have been checked and
+// mutated like a tenant pod beforehand, see pod.TemplateChecker.
+func MutateWorkloadPodTemplate(syncerConfig *config.SyncerConfiguration, template *v1.PodTemplateSpec) {
+	if syncerConfig.DisableServiceAccountToken {
+		template.Spec.AutomountServiceAccountToken = pointer.BoolPtr(false)
+	}
+}
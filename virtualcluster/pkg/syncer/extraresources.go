@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+// effectiveExtraSyncingResources returns the extra syncing resources synced for a virtual cluster:
+// the ones of the syncer requested by its LabelExtraSyncingResources annotation, matched like
+// --extra-syncing-resources, or all of them without the annotation. A virtual cluster cannot request a resource the syncer does not sync.
+func effectiveExtraSyncingResources(global sets.String, annotations map[string]string) sets.String {
+	value, ok := annotations[constants.LabelExtraSyncingResources]
+	if !ok {
+		return sets.NewString(global.UnsortedList()...)
+	}
+	requested := sets.NewString()
+	for _, id := range strings.Split(value, ",") {
+		if id = util.NormalizeResourceName(id); id != "" {
+			requested.Insert(id)
+		}
+	}
+	return global.Intersection(requested)
+}
+
+// extraResourceSyncer is the resource syncer of an extra syncing resource, only watching the
+// virtual clusters the resource is synced for.
+type extraResourceSyncer struct {
+	manager.ResourceSyncer
+	listener *extraResourceListener
+}
+
+func newExtraResourceSyncer(s manager.ResourceSyncer, id string, global sets.String) manager.ResourceSyncer {
+	return &extraResourceSyncer{
+		ResourceSyncer: s,
+		listener: &extraResourceListener{
+			ClusterChangeListener: s.GetListener(),
+			id:                    id,
+			global:                global,
+			clusters:              sets.NewString(),
+		},
+	}
+}
+
+func (s *extraResourceSyncer) GetListener() listener.ClusterChangeListener {
+	return s.listener
+}
+
+// extraResourceListener adds and watches a virtual cluster only if the resource is one of its
+// effective extra syncing resources. The annotation is read when the virtual cluster is added.
+type extraResourceListener struct {
+	listener.ClusterChangeListener
+	id     string
+	global sets.String
+
+	sync.Mutex
+	// clusters are the names of the added virtual clusters syncing the resource.
+	clusters sets.String
+}
+
+func (l *extraResourceListener) AddCluster(cluster mc.ClusterInterface) {
+	obj, err := cluster.GetObject()
+	if err != nil {
+		klog.Errorf("failed to get the virtual cluster of cluster %s, not syncing %s for it: %v", cluster.GetClusterName(), l.id, err)
+		return
+	}
+	if !effectiveExtraSyncingResources(l.global, obj.GetAnnotations()).Has(l.id) {
+		klog.Infof("cluster %s does not request extra syncing resource %s", cluster.GetClusterName(), l.id)
+		return
+	}
+	l.Lock()
+	l.clusters.Insert(cluster.GetClusterName())
+	l.Unlock()
+	l.ClusterChangeListener.AddCluster(cluster)
+}
+
+func (l *extraResourceListener) WatchCluster(cluster mc.ClusterInterface) {
+	l.Lock()
+	added := l.clusters.Has(cluster.GetClusterName())
+	l.Unlock()
+	if added {
+		l.ClusterChangeListener.WatchCluster(cluster)
+	}
+}
+
+func (l *extraResourceListener) RemoveCluster(cluster mc.ClusterInterface) {
+	l.Lock()
+	added := l.clusters.Has(cluster.GetClusterName())
+	l.clusters.Delete(cluster.GetClusterName())
+	l.Unlock()
+	if added {
+		l.ClusterChangeListener.RemoveCluster(cluster)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+func TestEffectiveExtraSyncingResources(t *testing.T) {
+	global := sets.NewString("ingress", "deployment", "replicaset", "persistentvolumeclaim")
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    []string
+	}{
+		{
+			name:     "no annotation syncs the global set",
+			expected: []string{"deployment", "ingress", "persistentvolumeclaim", "replicaset"},
+		},
+		{
+			name:        "annotation restricts the global set",
+			annotations: map[string]string{constants.LabelExtraSyncingResources: "ingress"},
+			expected:    []string{"ingress"},
+		},
+		{
+			name:        "annotation cannot add a resource the syncer does not sync",
+			annotations: map[string]string{constants.LabelExtraSyncingResources: "ingress,crd,clusterrolebinding"},
+			expected:    []string{"ingress"},
+		},
+		{
+			name:        "empty annotation removes all the extra resources",
+			annotations: map[string]string{constants.LabelExtraSyncingResources: ""},
+			expected:    []string{},
+		},
+		{
+			name:        "annotation is case insensitive and ignores blanks",
+			annotations: map[string]string{constants.LabelExtraSyncingResources: " Deployment , ,REPLICASET"},
+			expected:    []string{"deployment", "replicaset"},
+		},
+		{
+			name:        "annotation accepts the short names",
+			annotations: map[string]string{constants.LabelExtraSyncingResources: "PVC"},
+			expected:    []string{"persistentvolumeclaim"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := effectiveExtraSyncingResources(global, tt.annotations).List()
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// recordingListener records the clusters it is called for.
+type recordingListener struct {
+	added, watched, removed []string
+}
+
+func (l *recordingListener) AddCluster(c mc.ClusterInterface) {
+	l.added = append(l.added, c.GetClusterName())
+}
+
+func (l *recordingListener) WatchCluster(c mc.ClusterInterface) {
+	l.watched = append(l.watched, c.GetClusterName())
+}
+
+func (l *recordingListener) RemoveCluster(c mc.ClusterInterface) {
+	l.removed = append(l.removed, c.GetClusterName())
+}
+
+// recordingSyncer is a resource syncer with a recordingListener.
+type recordingSyncer struct {
+	manager.BaseResourceSyncer
+	listener *recordingListener
+}
+
+func (s *recordingSyncer) GetListener() listener.ClusterChangeListener {
+	return s.listener
+}
+
+func TestExtraResourceListener(t *testing.T) {
+	newCluster := func(name string, annotations map[string]string) mc.ClusterInterface {
+		return cluster.NewFakeTenantCluster(&v1alpha1.VirtualCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   "tenant",
+				UID:         "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+				Annotations: annotations,
+			},
+		}, nil, nil)
+	}
+	all := newCluster("all", nil)
+	requesting := newCluster("requesting", map[string]string{constants.LabelExtraSyncingResources: "deployment,ingress"})
+	other := newCluster("other", map[string]string{constants.LabelExtraSyncingResources: "deployment"})
+
+	recorder := &recordingListener{}
+	s := newExtraResourceSyncer(&recordingSyncer{listener: recorder}, "ingress", sets.NewString("ingress", "deployment"))
+	l := s.GetListener()
+	for _, c := range []mc.ClusterInterface{all, requesting, other} {
+		l.AddCluster(c)
+		l.WatchCluster(c)
+	}
+	for _, c := range []mc.ClusterInterface{all, requesting, other} {
+		l.RemoveCluster(c)
+	}
+
+	expected := []string{all.GetClusterName(), requesting.GetClusterName()}
+	if !reflect.DeepEqual(recorder.added, expected) {
+		t.Errorf("expected clusters %v to be added, got %v", expected, recorder.added)
+	}
+	if !reflect.DeepEqual(recorder.watched, expected) {
+		t.Errorf("expected clusters %v to be watched, got %v", expected, recorder.watched)
+	}
+	if !reflect.DeepEqual(recorder.removed, expected) {
+		t.Errorf("expected clusters %v to be removed, got %v", expected, recorder.removed)
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lifecycle notifies external systems about the sync lifecycle of tenant objects.
+package lifecycle
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType is the type of a sync lifecycle event.
+type EventType string
+
+const (
+	// EventSynced is sent when a change of a tenant object is written to the super cluster successfully.
+	EventSynced EventType = "Synced"
+	// EventFailed is sent when a tenant object cannot be synced and the syncer gives up.
+	EventFailed EventType = "Failed"
+	// EventCleanedUp is sent when a deleted tenant object has been removed from the super cluster.
+	EventCleanedUp EventType = "CleanedUp"
+)
+
+// Outcomes of the sync lifecycle events.
+const (
+	OutcomeOK                       = "OK"
+	OutcomeRejected                 = "Rejected"
+	OutcomeExceededObjectCountQuota = "ExceededObjectCountQuota"
+	OutcomeExceededMaxRetryAttempts = "ExceededMaxRetryAttempts"
+)
+
+// EventTypes are all the known sync lifecycle event types.
+var EventTypes = []EventType{EventSynced, EventFailed, EventCleanedUp}
+
+// Event is a sync lifecycle event of a tenant object.
+type Event struct {
+	Type      EventType `json:"type"`
+	Cluster   string    `json:"cluster"`
+	Resource  string    `json:"resource"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	UID       string    `json:"uid,omitempty"`
+	// Outcome is one of the Outcome* constants.
+	Outcome   string    `json:"outcome"`
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// Done, if set, is called once the notifier is done with the event, i.e. it is delivered or dropped.
+	Done func() `json:"-"`
+}
+
+// Notifier delivers sync lifecycle events.
+type Notifier interface {
+	Notify(event Event)
+}
+
+var (
+	notifierLock sync.RWMutex
+	notifier     Notifier
+)
+
+// SetNotifier sets the notifier used by Notify. A nil notifier disables the notifications.
+func SetNotifier(n Notifier) {
+	notifierLock.Lock()
+	defer notifierLock.Unlock()
+	notifier = n
+}
+
+// Enabled returns true if a notifier is set.
+func Enabled() bool {
+	notifierLock.RLock()
+	defer notifierLock.RUnlock()
+	return notifier != nil
+}
+
+// Notify hands the event to the notifier, if any.
+func Notify(event Event) {
+	notifierLock.RLock()
+	n := notifier
+	notifierLock.RUnlock()
+	if n == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	n.Notify(event)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// webhookQueueSize is the number of events waiting for delivery before new events are dropped.
+	webhookQueueSize = 1000
+	// webhookTimeout is the timeout of a single delivery attempt.
+	webhookTimeout = 10 * time.Second
+)
+
+// WebhookOptions configure a WebhookNotifier.
+type WebhookOptions struct {
+	// URL is the endpoint the events are POSTed to as JSON.
+	URL string
+	// EventTypes are the event types to send. Empty means all.
+	EventTypes []string
+	// QPS and Burst limit the rate of delivery attempts.
+	QPS   float32
+	Burst int
+	// MaxRetries is the number of retries of a failed delivery before the event is dropped.
+	MaxRetries int
+}
+
+// WebhookNotifier sends sync lifecycle events to a webhook endpoint. Events are queued and
+// delivered in order by Run, failed deliveries are retried with exponential backoff.
+type WebhookNotifier struct {
+	url        string
+	eventTypes sets.String
+	client     *http.Client
+	limiter    flowcontrol.RateLimiter
+	backoff    wait.Backoff
+	events     chan Event
+}
+
+// NewWebhookNotifier validates the options and creates a WebhookNotifier.
+func NewWebhookNotifier(opts WebhookOptions) (*WebhookNotifier, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lifecycle webhook url %q: %v", opts.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid lifecycle webhook url %q: scheme must be http or https", opts.URL)
+	}
+
+	known := sets.NewString()
+	for _, t := range EventTypes {
+		known.Insert(string(t))
+	}
+	eventTypes := sets.NewString(opts.EventTypes...)
+	if unknown := eventTypes.Difference(known); unknown.Len() > 0 {
+		return nil, fmt.Errorf("unknown lifecycle webhook event types %v, must be in %v", unknown.List(), known.List())
+	}
+	if eventTypes.Len() == 0 {
+		eventTypes = known
+	}
+
+	if opts.QPS <= 0 || opts.Burst <= 0 {
+		return nil, fmt.Errorf("lifecycle webhook qps and burst must be positive, got %v and %d", opts.QPS, opts.Burst)
+	}
+	if opts.MaxRetries < 0 {
+		return nil, fmt.Errorf("lifecycle webhook max retries must not be negative, got %d", opts.MaxRetries)
+	}
+
+	return &WebhookNotifier{
+		url:        opts.URL,
+		eventTypes: eventTypes,
+		client:     &http.Client{Timeout: webhookTimeout},
+		limiter:    flowcontrol.NewTokenBucketRateLimiter(opts.QPS, opts.Burst),
+		backoff: wait.Backoff{
+			Duration: time.Second,
+			Factor:   2,
+			Jitter:   0.1,
+			Steps:    opts.MaxRetries + 1,
+			Cap:      time.Minute,
+		},
+		events: make(chan Event, webhookQueueSize),
+	}, nil
+}
+
+// Notify queues the event for delivery if its type is wanted. The event is dropped if the queue is full.
+func (n *WebhookNotifier) Notify(event Event) {
+	if !n.eventTypes.Has(string(event.Type)) {
+		done(event)
+		return
+	}
+	select {
+	case n.events <- event:
+	default:
+		klog.Warningf("lifecycle webhook queue is full, drop %s event of %s %s/%s of cluster %s", event.Type, event.Resource, event.Namespace, event.Name, event.Cluster)
+		done(event)
+	}
+}
+
+// Run delivers the queued events until stopCh is closed.
+func (n *WebhookNotifier) Run(stopCh <-chan struct{}) {
+	for {
+		select {
+		case <-stopCh:
+			return
+		case event := <-n.events:
+			n.deliver(event, stopCh)
+		}
+	}
+}
+
+// deliver sends the event, retrying with backoff until it is accepted, the retries are exhausted or
+// stopCh is closed.
+func (n *WebhookNotifier) deliver(event Event, stopCh <-chan struct{}) {
+	defer done(event)
+	body, err := json.Marshal(event)
+	if err != nil {
+		klog.Errorf("failed to marshal lifecycle event %+v: %v", event, err)
+		return
+	}
+	backoff := n.backoff
+	for {
+		n.limiter.Accept()
+		retriable, err := n.send(body)
+		if err == nil {
+			return
+		}
+		if !retriable || backoff.Steps <= 1 {
+			klog.Errorf("drop %s lifecycle event of %s %s/%s of cluster %s: %v", event.Type, event.Resource, event.Namespace, event.Name, event.Cluster, err)
+			return
+		}
+		klog.V(4).Infof("failed to send lifecycle event to %s, will retry: %v", n.url, err)
+		select {
+		case <-stopCh:
+			klog.Warningf("drop %s lifecycle event of %s %s/%s of cluster %s: the syncer is stopping", event.Type, event.Resource, event.Namespace, event.Name, event.Cluster)
+			return
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// send POSTs the event once. It returns whether a failure is worth retrying.
+func (n *WebhookNotifier) send(body []byte) (bool, error) {
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("lifecycle webhook responded %s", resp.Status)
+	default:
+		return false, fmt.Errorf("lifecycle webhook responded %s", resp.Status)
+	}
+}
+
+// done calls the Done callback of the event, if any.
+func done(event Event) {
+	if event.Done != nil {
+		event.Done()
+	}
+}
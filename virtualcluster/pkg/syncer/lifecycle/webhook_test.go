@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewWebhookNotifier(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		opts        WebhookOptions
+		expectedErr bool
+	}{
+		{
+			name: "valid",
+			opts: WebhookOptions{URL: "https://example.com/hook", EventTypes: []string{"Synced", "Failed"}, QPS: 1, Burst: 1, MaxRetries: 3},
+		},
+		{
+			name:        "invalid scheme",
+			opts:        WebhookOptions{URL: "ftp://example.com/hook", QPS: 1, Burst: 1},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown event type",
+			opts:        WebhookOptions{URL: "https://example.com/hook", EventTypes: []string{"Created"}, QPS: 1, Burst: 1},
+			expectedErr: true,
+		},
+		{
+			name:        "no qps",
+			opts:        WebhookOptions{URL: "https://example.com/hook", Burst: 1},
+			expectedErr: true,
+		},
+		{
+			name:        "negative retries",
+			opts:        WebhookOptions{URL: "https://example.com/hook", QPS: 1, Burst: 1, MaxRetries: -1},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			_, err := NewWebhookNotifier(tt.opts)
+			if (err != nil) != tt.expectedErr {
+				tc.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierEventTypes(t *testing.T) {
+	n, err := NewWebhookNotifier(WebhookOptions{URL: "http://example.com", EventTypes: []string{"Failed"}, QPS: 1, Burst: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n.Notify(Event{Type: EventSynced})
+	n.Notify(Event{Type: EventFailed})
+	n.Notify(Event{Type: EventCleanedUp})
+	if len(n.events) != 1 {
+		t.Fatalf("expected 1 queued event, got %d", len(n.events))
+	}
+	if event := <-n.events; event.Type != EventFailed {
+		t.Errorf("expected Failed event, got %s", event.Type)
+	}
+}
+
+func TestWebhookNotifierDelivery(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		statusCodes      []int
+		maxRetries       int
+		expectedAttempts int
+	}{
+		{
+			name:             "accepted",
+			statusCodes:      []int{http.StatusOK},
+			maxRetries:       2,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retried until accepted",
+			statusCodes:      []int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusAccepted},
+			maxRetries:       2,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "retries exhausted",
+			statusCodes:      []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			maxRetries:       1,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "not retried on client error",
+			statusCodes:      []int{http.StatusBadRequest, http.StatusOK},
+			maxRetries:       2,
+			expectedAttempts: 1,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			var lock sync.Mutex
+			var received []Event
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				var event Event
+				if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+					tc.Errorf("unexpected payload: %v", err)
+				}
+				w.WriteHeader(tt.statusCodes[len(received)])
+				received = append(received, event)
+			}))
+			defer server.Close()
+
+			n, err := NewWebhookNotifier(WebhookOptions{URL: server.URL, QPS: 100, Burst: 10, MaxRetries: tt.maxRetries})
+			if err != nil {
+				tc.Fatalf("unexpected error: %v", err)
+			}
+			n.backoff.Duration = time.Millisecond
+
+			done := 0
+			event := Event{Type: EventSynced, Cluster: "tenant", Resource: "Pod", Namespace: "default", Name: "pod", Outcome: "OK", Timestamp: time.Now().UTC(), Done: func() { done++ }}
+			n.deliver(event, make(chan struct{}))
+			if done != 1 {
+				tc.Errorf("expected the event to be done once, got %d", done)
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+			if len(received) != tt.expectedAttempts {
+				tc.Fatalf("expected %d attempts, got %d", tt.expectedAttempts, len(received))
+			}
+			if got := received[0]; got.Type != event.Type || got.Cluster != event.Cluster || got.Resource != event.Resource ||
+				got.Namespace != event.Namespace || got.Name != event.Name || got.Outcome != event.Outcome || !got.Timestamp.Equal(event.Timestamp) {
+				tc.Errorf("expected payload %+v, got %+v", event, got)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierStopDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	n, err := NewWebhookNotifier(WebhookOptions{URL: server.URL, QPS: 100, Burst: 10, MaxRetries: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	n.backoff.Duration = time.Hour
+
+	stopCh := make(chan struct{})
+	close(stopCh)
+	delivered := make(chan struct{})
+	go func() {
+		n.deliver(Event{Type: EventSynced, Done: func() { close(delivered) }}, stopCh)
+	}()
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the delivery to stop during the backoff")
+	}
+}
+
+func TestWebhookNotifierDroppedEventDone(t *testing.T) {
+	n, err := NewWebhookNotifier(WebhookOptions{URL: "http://example.com", EventTypes: []string{"Failed"}, QPS: 1, Burst: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	done := 0
+	n.Notify(Event{Type: EventSynced, Done: func() { done++ }})
+	if done != 1 {
+		t.Errorf("expected the filtered event to be done, got %d", done)
+	}
+}
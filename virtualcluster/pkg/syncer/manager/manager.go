@@ -17,6 +17,7 @@ limitations under the License.
 package manager
 
 import (
+	"sort"
 	"sync"
 
 	"k8s.io/client-go/informers"
@@ -36,7 +37,8 @@ import (
 // ControllerManager manages number of resource syncers. It starts their caches, waits for those to sync,
 // then starts the controllers.
 type ControllerManager struct {
-	resourceSyncers map[ResourceSyncer]struct{}
+	// resourceSyncers are the managed resource syncers with the directions they sync.
+	resourceSyncers map[ResourceSyncer]config.SyncDirection
 }
 
 type ResourceSyncerOptions struct {
@@ -47,7 +49,7 @@ type ResourceSyncerOptions struct {
 }
 
 func New() *ControllerManager {
-	return &ControllerManager{resourceSyncers: make(map[ResourceSyncer]struct{})}
+	return &ControllerManager{resourceSyncers: make(map[ResourceSyncer]config.SyncDirection)}
 }
 
 // ResourceSyncer is the interface used by ControllerManager to manage multiple resource syncers.
@@ -63,9 +65,11 @@ type ResourceSyncer interface {
 	StartPatrol(stopCh <-chan struct{}) error
 }
 
-// AddResourceSyncer adds a resource syncer to the ControllerManager.
-func (m *ControllerManager) AddResourceSyncer(s ResourceSyncer) {
-	m.resourceSyncers[s] = struct{}{}
+// AddResourceSyncer adds a resource syncer to the ControllerManager. Only the controllers of the
+// direction are started: the downward syncer and the periodic checker to sync down, the upward
+// syncer to sync up. An empty direction means both.
+func (m *ControllerManager) AddResourceSyncer(s ResourceSyncer, direction config.SyncDirection) {
+	m.resourceSyncers[s] = direction
 
 	l := s.GetListener()
 	if l == nil {
@@ -75,6 +79,21 @@ func (m *ControllerManager) AddResourceSyncer(s ResourceSyncer) {
 	listener.AddListener(l)
 }
 
+// DeadLetters returns the tenant objects whose sync has been taken out of the retry loop by the
+// downward syncers.
+func (m *ControllerManager) DeadLetters() []mc.DeadLetter {
+	var dls []mc.DeadLetter
+	for s := range m.resourceSyncers {
+		if c := s.GetMCController(); c != nil {
+			dls = append(dls, c.DeadLetters()...)
+		}
+	}
+	sort.SliceStable(dls, func(i, j int) bool {
+		return dls[i].Kind < dls[j].Kind
+	})
+	return dls
+}
+
 type ResourceSyncerNew func(*config.SyncerConfiguration,
 	clientset.Interface,
 	informers.SharedInformerFactory,
@@ -141,29 +160,26 @@ func (m *ControllerManager) Start(stop <-chan struct{}) error {
 	errCh := make(chan error)
 
 	wg := &sync.WaitGroup{}
-	wg.Add(len(m.resourceSyncers) * 3)
-
-	for s := range m.resourceSyncers {
-		go func(s ResourceSyncer) {
-			defer wg.Done()
-			if err := s.StartDWS(stop); err != nil {
-				errCh <- err
-			}
-		}(s)
-		// start UWS syncer
-		go func(s ResourceSyncer) {
+	start := func(startFn func(stop <-chan struct{}) error) {
+		wg.Add(1)
+		go func() {
 			defer wg.Done()
-			if err := s.StartUWS(stop); err != nil {
+			if err := startFn(stop); err != nil {
 				errCh <- err
 			}
-		}(s)
-		// start periodic checker
-		go func(s ResourceSyncer) {
-			defer wg.Done()
-			if err := s.StartPatrol(stop); err != nil {
-				errCh <- err
-			}
-		}(s)
+		}()
+	}
+
+	for s, direction := range m.resourceSyncers {
+		if direction.SyncsDown() {
+			start(s.StartDWS)
+			// start periodic checker
+			start(s.StartPatrol)
+		}
+		if direction.SyncsUp() {
+			// start UWS syncer
+			start(s.StartUWS)
+		}
 	}
 
 	doneCh := make(chan struct{})
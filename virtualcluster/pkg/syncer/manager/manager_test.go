@@ -18,7 +18,10 @@ package manager
 
 import (
 	"encoding/json"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
@@ -32,6 +35,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
@@ -461,3 +465,62 @@ func TestBuildSuperClusterNamespace(t *testing.T) {
 		})
 	}
 }
+
+type noopListener struct{}
+
+func (noopListener) AddCluster(mc.ClusterInterface)    {}
+func (noopListener) WatchCluster(mc.ClusterInterface)  {}
+func (noopListener) RemoveCluster(mc.ClusterInterface) {}
+
+// startRecorder is a resource syncer recording which of its controllers are started.
+type startRecorder struct {
+	BaseResourceSyncer
+	name    string
+	lock    *sync.Mutex
+	started *[]string
+}
+
+func (r *startRecorder) record(controller string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	*r.started = append(*r.started, r.name+"/"+controller)
+	return nil
+}
+
+func (r *startRecorder) GetListener() listener.ClusterChangeListener { return noopListener{} }
+func (r *startRecorder) StartDWS(<-chan struct{}) error              { return r.record("dws") }
+func (r *startRecorder) StartUWS(<-chan struct{}) error              { return r.record("uws") }
+func (r *startRecorder) StartPatrol(<-chan struct{}) error           { return r.record("patrol") }
+
+func TestControllerManagerSyncDirections(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		started []string
+	)
+	m := New()
+	for name, direction := range map[string]config.SyncDirection{
+		"default": "",
+		"both":    config.SyncDirectionBoth,
+		"down":    config.SyncDirectionDown,
+		"up":      config.SyncDirectionUp,
+	} {
+		m.AddResourceSyncer(&startRecorder{name: name, lock: &lock, started: &started}, direction)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := m.Start(stop); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(started)
+	expected := []string{
+		"both/dws", "both/patrol", "both/uws",
+		"default/dws", "default/patrol", "default/uws",
+		"down/dws", "down/patrol",
+		"up/uws",
+	}
+	if !reflect.DeepEqual(started, expected) {
+		t.Errorf("expected the controllers %v to be started, got %v", expected, started)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OtherVCLabel is the vc_name of the series aggregating the virtual clusters beyond the
+// cardinality limit.
+const OtherVCLabel = "other"
+
+// vcCardinality bounds the number of vc_name label values of the per virtual cluster metrics. The
+// label values are assigned first-come: the first virtual clusters recording a metric get their own
+// label value, up to the limit, the others are aggregated in the OtherVCLabel series until they are
+// removed. They are not ranked by traffic, since moving a virtual cluster between its own series and
+// the other series would break the continuity of the counters of both.
+type vcCardinality struct {
+	sync.Mutex
+	// max is the maximum number of virtual clusters with their own label value, 0 means no limit.
+	max int
+	// vcs are the virtual clusters with their own label value, others the ones in the other bucket.
+	vcs    map[string]bool
+	others map[string]bool
+	// other holds the values of the gauges of the virtual clusters in the other bucket, since the
+	// value of the other series is an aggregate of them, e.g. their sum.
+	other map[otherGaugeKey]*otherGauge
+	// series are the series of the virtual clusters with their own label value, deleted once the
+	// virtual cluster is forgotten.
+	series map[string]map[seriesKey]prometheus.Labels
+}
+
+// deleter is a metric vector whose series can be deleted, e.g. a CounterVec or a GaugeVec.
+type deleter interface {
+	Delete(labels prometheus.Labels) bool
+}
+
+type seriesKey struct {
+	vec    deleter
+	labels string
+}
+
+type otherGaugeKey struct {
+	gauge  *prometheus.GaugeVec
+	labels string
+}
+
+type otherGauge struct {
+	labels    prometheus.Labels
+	values    map[string]float64
+	aggregate func(map[string]float64) float64
+}
+
+var cardinality = &vcCardinality{
+	vcs:    make(map[string]bool),
+	others: make(map[string]bool),
+	other:  make(map[otherGaugeKey]*otherGauge),
+	series: make(map[string]map[seriesKey]prometheus.Labels),
+}
+
+// SetMaxVCCardinality sets the maximum number of virtual clusters with their own vc_name label
+// value, 0 means no limit. It applies to the virtual clusters recording metrics afterwards.
+func SetMaxVCCardinality(max int) {
+	cardinality.Lock()
+	defer cardinality.Unlock()
+	cardinality.max = max
+}
+
+// ForgetVC frees the label value of a removed virtual cluster for another one, deletes its own
+// series and removes it from the aggregated gauges.
+func ForgetVC(cluster string) {
+	cardinality.Lock()
+	defer cardinality.Unlock()
+	delete(cardinality.vcs, cluster)
+	delete(cardinality.others, cluster)
+	for key, labels := range cardinality.series[cluster] {
+		key.vec.Delete(labels)
+	}
+	delete(cardinality.series, cluster)
+	for key, g := range cardinality.other {
+		if _, ok := g.values[cluster]; !ok {
+			continue
+		}
+		delete(g.values, cluster)
+		cardinality.exportLocked(key, g)
+	}
+}
+
+// vcLabels returns the labels of the series of the virtual cluster in a per virtual cluster metric,
+// labels being its other labels.
+func vcLabels(vec deleter, labels prometheus.Labels, cluster string) prometheus.Labels {
+	cardinality.Lock()
+	defer cardinality.Unlock()
+	return cardinality.labelsLocked(vec, labels, cluster)
+}
+
+func (c *vcCardinality) labelsLocked(vec deleter, labels prometheus.Labels, cluster string) prometheus.Labels {
+	vcLabels := withVCLabel(labels, c.labelLocked(cluster))
+	if vcLabels["vc_name"] == OtherVCLabel {
+		return vcLabels
+	}
+	series, ok := c.series[cluster]
+	if !ok {
+		series = make(map[seriesKey]prometheus.Labels)
+		c.series[cluster] = series
+	}
+	series[seriesKey{vec: vec, labels: fmt.Sprint(vcLabels)}] = vcLabels
+	return vcLabels
+}
+
+func (c *vcCardinality) labelLocked(cluster string) string {
+	if c.max <= 0 || c.vcs[cluster] {
+		return cluster
+	}
+	if c.others[cluster] {
+		// keep it in the other bucket even if a label value has been freed meanwhile, not to count
+		// it twice.
+		return OtherVCLabel
+	}
+	if len(c.vcs) < c.max {
+		c.vcs[cluster] = true
+		return cluster
+	}
+	c.others[cluster] = true
+	return OtherVCLabel
+}
+
+// setVCGauge sets the value of a per virtual cluster gauge, labels being its other labels. The
+// value of a virtual cluster in the other bucket is aggregated with the ones of the other virtual
+// clusters in the bucket.
+func setVCGauge(gauge *prometheus.GaugeVec, labels prometheus.Labels, cluster string, value float64, aggregate func(map[string]float64) float64) {
+	cardinality.Lock()
+	defer cardinality.Unlock()
+	vcLabels := cardinality.labelsLocked(gauge, labels, cluster)
+	if vcLabels["vc_name"] != OtherVCLabel {
+		gauge.With(vcLabels).Set(value)
+		return
+	}
+	key := otherGaugeKey{gauge: gauge, labels: fmt.Sprint(labels)}
+	g, ok := cardinality.other[key]
+	if !ok {
+		g = &otherGauge{labels: vcLabels, values: make(map[string]float64), aggregate: aggregate}
+		cardinality.other[key] = g
+	}
+	g.values[cluster] = value
+	cardinality.exportLocked(key, g)
+}
+
+// deleteVCGauge deletes the value of a per virtual cluster gauge.
+func deleteVCGauge(gauge *prometheus.GaugeVec, labels prometheus.Labels, cluster string) {
+	cardinality.Lock()
+	defer cardinality.Unlock()
+	key := otherGaugeKey{gauge: gauge, labels: fmt.Sprint(labels)}
+	if g, ok := cardinality.other[key]; ok {
+		if _, ok := g.values[cluster]; ok {
+			delete(g.values, cluster)
+			cardinality.exportLocked(key, g)
+			return
+		}
+	}
+	vcLabels := withVCLabel(labels, cluster)
+	delete(cardinality.series[cluster], seriesKey{vec: gauge, labels: fmt.Sprint(vcLabels)})
+	gauge.Delete(vcLabels)
+}
+
+func (c *vcCardinality) exportLocked(key otherGaugeKey, g *otherGauge) {
+	if len(g.values) == 0 {
+		key.gauge.Delete(g.labels)
+		delete(c.other, key)
+		return
+	}
+	key.gauge.With(g.labels).Set(g.aggregate(g.values))
+}
+
+func withVCLabel(labels prometheus.Labels, vc string) prometheus.Labels {
+	vcLabels := make(prometheus.Labels, len(labels)+1)
+	for k, v := range labels {
+		vcLabels[k] = v
+	}
+	vcLabels["vc_name"] = vc
+	return vcLabels
+}
+
+func sumValues(values map[string]float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum
+}
+
+func minValues(values map[string]float64) float64 {
+	first := true
+	var min float64
+	for _, v := range values {
+		if first || v < min {
+			min, first = v, false
+		}
+	}
+	return min
+}
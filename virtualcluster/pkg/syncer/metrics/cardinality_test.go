@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// resetVCCardinality sets the limit and forgets the virtual clusters recorded by a previous test.
+func resetVCCardinality(t *testing.T, max int) {
+	cardinality.Lock()
+	cardinality.vcs = make(map[string]bool)
+	cardinality.others = make(map[string]bool)
+	cardinality.other = make(map[otherGaugeKey]*otherGauge)
+	cardinality.series = make(map[string]map[seriesKey]prometheus.Labels)
+	cardinality.Unlock()
+	SetMaxVCCardinality(max)
+	t.Cleanup(func() { SetMaxVCCardinality(0) })
+}
+
+func TestVCCardinalityUnlimited(t *testing.T) {
+	resetVCCardinality(t, 0)
+	DWSOperationCounter.Reset()
+
+	for _, cluster := range []string{"vc-1", "vc-2", "vc-3"} {
+		RecordDWSOperationStatus("configmaps", cluster, "200")
+	}
+	for _, cluster := range []string{"vc-1", "vc-2", "vc-3"} {
+		if got := testutil.ToFloat64(DWSOperationCounter.WithLabelValues("configmaps", cluster, "200")); got != 1 {
+			t.Errorf("expected 1 operation of %s, got %v", cluster, got)
+		}
+	}
+	if got := testutil.CollectAndCount(DWSOperationCounter); got != 3 {
+		t.Errorf("expected 3 series, got %d", got)
+	}
+}
+
+func TestVCCardinalityOtherBucket(t *testing.T) {
+	resetVCCardinality(t, 2)
+	DWSOperationCounter.Reset()
+	ObjectCount.Reset()
+
+	for i, cluster := range []string{"vc-1", "vc-2", "vc-3", "vc-4"} {
+		RecordDWSOperationStatus("configmaps", cluster, "200")
+		RecordObjectCount("configmaps", cluster, 10*(i+1))
+	}
+	// the first virtual clusters keep their series as they record more.
+	RecordDWSOperationStatus("configmaps", "vc-1", "200")
+
+	if got := testutil.ToFloat64(DWSOperationCounter.WithLabelValues("configmaps", "vc-1", "200")); got != 2 {
+		t.Errorf("expected 2 operations of vc-1, got %v", got)
+	}
+	if got := testutil.ToFloat64(DWSOperationCounter.WithLabelValues("configmaps", "vc-2", "200")); got != 1 {
+		t.Errorf("expected 1 operation of vc-2, got %v", got)
+	}
+	if got := testutil.ToFloat64(DWSOperationCounter.WithLabelValues("configmaps", OtherVCLabel, "200")); got != 2 {
+		t.Errorf("expected the 2 operations of vc-3 and vc-4 in the other bucket, got %v", got)
+	}
+	if got := testutil.CollectAndCount(DWSOperationCounter); got != 3 {
+		t.Errorf("expected 3 operation series, got %d", got)
+	}
+
+	if got := testutil.ToFloat64(ObjectCount.WithLabelValues("configmaps", OtherVCLabel)); got != 70 {
+		t.Errorf("expected the other bucket to sum the 30 and 40 objects of vc-3 and vc-4, got %v", got)
+	}
+	RecordObjectCount("configmaps", "vc-3", 35)
+	if got := testutil.ToFloat64(ObjectCount.WithLabelValues("configmaps", OtherVCLabel)); got != 75 {
+		t.Errorf("expected 75 objects in the other bucket after the update of vc-3, got %v", got)
+	}
+	DeleteObjectCount("configmaps", "vc-4")
+	if got := testutil.ToFloat64(ObjectCount.WithLabelValues("configmaps", OtherVCLabel)); got != 35 {
+		t.Errorf("expected 35 objects in the other bucket after the deletion of vc-4, got %v", got)
+	}
+	DeleteObjectCount("configmaps", "vc-3")
+	if got := testutil.CollectAndCount(ObjectCount); got != 2 {
+		t.Errorf("expected the other object count series to be deleted with its last virtual cluster, got %d series", got)
+	}
+}
+
+func TestVCCardinalityForgetVC(t *testing.T) {
+	resetVCCardinality(t, 1)
+	ObjectCount.Reset()
+	DWSOperationCounter.Reset()
+
+	RecordDWSOperationStatus("configmaps", "vc-1", "200")
+	RecordObjectCount("configmaps", "vc-1", 1)
+	RecordObjectCount("configmaps", "vc-2", 2)
+	RecordObjectCount("configmaps", "vc-3", 3)
+
+	// vc-2 stays in the other bucket once the label value of vc-1 is freed, the next virtual
+	// cluster gets it.
+	ForgetVC("vc-1")
+	if got := testutil.CollectAndCount(DWSOperationCounter); got != 0 {
+		t.Errorf("expected the operation series of the forgotten vc-1 to be deleted, got %d series", got)
+	}
+	RecordObjectCount("configmaps", "vc-2", 2)
+	RecordObjectCount("configmaps", "vc-4", 4)
+	if got := testutil.ToFloat64(ObjectCount.WithLabelValues("configmaps", "vc-4")); got != 4 {
+		t.Errorf("expected vc-4 to take the freed label value, got %v", got)
+	}
+	if got := testutil.ToFloat64(ObjectCount.WithLabelValues("configmaps", OtherVCLabel)); got != 5 {
+		t.Errorf("expected 5 objects of vc-2 and vc-3 in the other bucket, got %v", got)
+	}
+
+	ForgetVC("vc-3")
+	if got := testutil.ToFloat64(ObjectCount.WithLabelValues("configmaps", OtherVCLabel)); got != 2 {
+		t.Errorf("expected the objects of the forgotten vc-3 to leave the other bucket, got %v", got)
+	}
+}
+
+func TestVCCardinalitySecretCertExpiry(t *testing.T) {
+	resetVCCardinality(t, 1)
+	SecretCertExpiry.Reset()
+
+	soonest := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	RecordSecretCertExpiry("vc-1", soonest.Add(-time.Hour))
+	RecordSecretCertExpiry("vc-2", soonest.Add(time.Hour))
+	RecordSecretCertExpiry("vc-3", soonest)
+
+	if got := testutil.ToFloat64(SecretCertExpiry.WithLabelValues(OtherVCLabel)); got != float64(soonest.Unix()) {
+		t.Errorf("expected the other bucket to have the soonest expiry %v, got %v", soonest.Unix(), got)
+	}
+}
@@ -22,20 +22,49 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	_ "k8s.io/component-base/metrics/prometheus/workqueue" // add workqueue metrics
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/version"
 )
 
 const (
-	ResourceSyncerSubsystem  = "syncer"
-	PodOperationsKey         = "pod_operations_total"
-	PodOperationsDurationKey = "pod_operations_duration_seconds"
-	CheckerMissMatchKey      = "checker_missmatch_count"
-	CheckerRemedyKey         = "checker_remedy_count"
-	CheckerScanDurationKey   = "checker_scan_duration_seconds"
-	DWSOperationCounterKey   = "dws_operations_total"
-	DWSOperationDurationKey  = "dws_operations_duration_seconds"
-	UWSOperationCounterKey   = "uws_operations_total"
-	UWSOperationDurationKey  = "uws_operations_duration_seconds"
-	ClusterHealthKey         = "virtual_cluster_health"
+	ResourceSyncerSubsystem    = "syncer"
+	PodOperationsKey           = "pod_operations_total"
+	PodOperationsDurationKey   = "pod_operations_duration_seconds"
+	CheckerMissMatchKey        = "checker_missmatch_count"
+	CheckerRemedyKey           = "checker_remedy_count"
+	CheckerScanDurationKey     = "checker_scan_duration_seconds"
+	DWSOperationCounterKey     = "dws_operations_total"
+	DWSOperationDurationKey    = "dws_operations_duration_seconds"
+	UWSOperationCounterKey     = "uws_operations_total"
+	UWSOperationDurationKey    = "uws_operations_duration_seconds"
+	ClusterHealthKey           = "virtual_cluster_health"
+	DWSActiveWorkersKey        = "dws_active_workers"
+	ClusterInFlightWorkersKey  = "cluster_inflight_workers"
+	ObjectCountKey             = "tenant_objects"
+	ObjectCountCorrectionKey   = "tenant_object_count_corrections_total"
+	PausedObjectsKey           = "paused_objects"
+	SuperClusterUnreachableKey = "super_cluster_unreachable"
+	OnboardingReconciledKey    = "onboarding_reconciled_objects"
+	OnboardingObjectsKey       = "onboarding_objects"
+	ExistenceDisagreementsKey  = "existence_disagreements_total"
+	ClusterScopedConflictsKey  = "cluster_scoped_conflicts_total"
+	DeadLettersKey             = "dead_letter_objects"
+	SuperWatchErrorsKey        = "super_watch_errors_total"
+	SuperWatchFailingKey       = "super_watch_failing"
+	PodMutatorMutationsKey     = "pod_mutator_mutations_total"
+	PodMutatorEnabledKey       = "pod_mutator_enabled"
+	DryRunOperationsKey        = "dryrun_operations_total"
+	SecretCertExpiryKey        = "secret_cert_expiry_timestamp_seconds"
+	SyncLatencyNamespace       = "vc"
+	SyncLatencyKey             = "sync_latency_seconds"
+	QueueDepthKey              = "queue_depth"
+	QueueRetriesKey            = "queue_retries_total"
+	QueueDropsKey              = "queue_dropped_total"
+	BuildInfoKey               = "build_info"
+
+	// SyncDirectionDWS and SyncDirectionUWS are the direction label values of the sync latency.
+	SyncDirectionDWS = "dws"
+	SyncDirectionUWS = "uws"
 )
 
 var (
@@ -120,6 +149,161 @@ var (
 		},
 		[]string{"status"},
 	)
+	DWSActiveWorkers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      DWSActiveWorkersKey,
+			Help:      "Number of dws workers currently reconciling requests of a virtual cluster.",
+		},
+		[]string{"resource", "vc_name"})
+	ClusterInFlightWorkers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      ClusterInFlightWorkersKey,
+			Help:      "Number of workers of all the dws controllers currently reconciling requests of a virtual cluster.",
+		},
+		[]string{"vc_name"})
+	ObjectCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      ObjectCountKey,
+			Help:      "Number of tenant objects of a virtual cluster watched by the syncer.",
+		},
+		[]string{"resource", "vc_name"})
+	ObjectCountCorrections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      ObjectCountCorrectionKey,
+			Help:      "Number of tenant object counts corrected by the periodic recount.",
+		},
+		[]string{"resource", "vc_name"})
+	PausedObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      PausedObjectsKey,
+			Help:      "Number of tenant objects of a virtual cluster whose sync is paused by annotation.",
+		},
+		[]string{"resource", "vc_name"})
+	OnboardingReconciled = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      OnboardingReconciledKey,
+			Help:      "Number of tenant objects of a newly watched virtual cluster reconciled during its onboarding.",
+		},
+		[]string{"resource", "vc_name"})
+	OnboardingObjects = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      OnboardingObjectsKey,
+			Help:      "Number of tenant objects of a newly watched virtual cluster to reconcile during its onboarding.",
+		},
+		[]string{"resource", "vc_name"})
+	ExistenceDisagreements = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      ExistenceDisagreementsKey,
+			Help:      "Cumulative number of objects missing from the informer cache of the side authoritative for their existence but not from the other side, by resolution.",
+		},
+		[]string{"resource", "vc_name", "resolution"})
+	ClusterScopedConflicts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      ClusterScopedConflictsKey,
+			Help:      "Cumulative number of tenant cluster scoped objects with the name of a super control plane public object but not synced from it, by resolution.",
+		},
+		[]string{"resource", "vc_name", "resolution"})
+	DeadLetters = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      DeadLettersKey,
+			Help:      "Number of tenant objects of a virtual cluster whose sync kept failing and has been taken out of the retry loop.",
+		},
+		[]string{"resource", "vc_name"})
+	SuperClusterUnreachable = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      SuperClusterUnreachableKey,
+			Help:      "Whether the super cluster apiserver has failed the consecutive checks of the failure threshold (1) or not (0).",
+		})
+	SuperWatchErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      SuperWatchErrorsKey,
+			Help:      "Cumulative number of list and watch errors of the super cluster informers.",
+		},
+		[]string{"resource"})
+	SuperWatchFailing = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      SuperWatchFailingKey,
+			Help:      "Whether the super cluster informer of a resource has failed the consecutive list and watch attempts of the threshold (1) or not (0).",
+		},
+		[]string{"resource"})
+	PodMutatorMutations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      PodMutatorMutationsKey,
+			Help:      "Cumulative number of super pods changed by a stage of the pod mutation pipeline.",
+		},
+		[]string{"mutator"})
+	PodMutatorEnabled = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      PodMutatorEnabledKey,
+			Help:      "Whether a stage of the pod mutation pipeline runs by default (1) or only for the virtual clusters enabling it (0).",
+		},
+		[]string{"mutator"})
+	DryRunOperations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      DryRunOperationsKey,
+			Help:      "Cumulative number of write operations sent as dry runs, or skipped when they cannot be, by resource and verb.",
+		},
+		[]string{"resource", "verb"})
+	SecretCertExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      SecretCertExpiryKey,
+			Help:      "Expiry time, in seconds since the epoch, of the soonest expiring certificate of the tls.crt and ca.crt of the secrets synced for a virtual cluster.",
+		},
+		[]string{"vc_name"})
+	SyncLatency = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: SyncLatencyNamespace,
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      SyncLatencyKey,
+			Help:      "Duration in seconds from a change of an object being observed to its sync completing, i.e. the super cluster write for dws or the tenant write for uws. The dws reconciles which do not write are not recorded.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 16),
+		},
+		[]string{"resource", "direction"})
+	QueueDepth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      QueueDepthKey,
+			Help:      "Number of requests waiting in the work queue of a dws or uws controller.",
+		},
+		[]string{"controller"})
+	QueueRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      QueueRetriesKey,
+			Help:      "Cumulative number of requests put back in the work queue of a dws or uws controller to be retried after a back-off.",
+		},
+		[]string{"controller"})
+	QueueDrops = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      QueueDropsKey,
+			Help:      "Cumulative number of requests taken out of the work queue of a dws or uws controller after reaching the max retries.",
+		},
+		[]string{"controller"})
+	BuildInfo = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: ResourceSyncerSubsystem,
+			Name:      BuildInfoKey,
+			Help:      "A metric with a constant '1' value labeled by the version, git commit, build date, go version and platform the syncer was built from.",
+		},
+		[]string{"version", "git_commit", "build_date", "go_version", "platform"})
 )
 
 var registerMetrics sync.Once
@@ -137,6 +321,31 @@ func Register() {
 		prometheus.MustRegister(UWSOperationDuration)
 		prometheus.MustRegister(UWSOperationCounter)
 		prometheus.MustRegister(ClusterHealthStats)
+		prometheus.MustRegister(DWSActiveWorkers)
+		prometheus.MustRegister(ClusterInFlightWorkers)
+		prometheus.MustRegister(ObjectCount)
+		prometheus.MustRegister(ObjectCountCorrections)
+		prometheus.MustRegister(PausedObjects)
+		prometheus.MustRegister(SuperClusterUnreachable)
+		prometheus.MustRegister(OnboardingReconciled)
+		prometheus.MustRegister(OnboardingObjects)
+		prometheus.MustRegister(ExistenceDisagreements)
+		prometheus.MustRegister(ClusterScopedConflicts)
+		prometheus.MustRegister(DeadLetters)
+		prometheus.MustRegister(SuperWatchErrors)
+		prometheus.MustRegister(SuperWatchFailing)
+		prometheus.MustRegister(PodMutatorMutations)
+		prometheus.MustRegister(PodMutatorEnabled)
+		prometheus.MustRegister(DryRunOperations)
+		prometheus.MustRegister(SecretCertExpiry)
+		prometheus.MustRegister(SyncLatency)
+		prometheus.MustRegister(QueueDepth)
+		prometheus.MustRegister(QueueRetries)
+		prometheus.MustRegister(QueueDrops)
+		prometheus.MustRegister(BuildInfo)
+
+		info := version.Get()
+		BuildInfo.WithLabelValues(info.GitVersion, info.GitCommit, info.BuildDate, info.GoVersion, info.Platform).Set(1)
 	})
 }
 
@@ -163,9 +372,105 @@ func RecordUWSOperationStatus(resource, code string) {
 }
 
 func RecordDWSOperationDuration(resource, cluster string, start time.Time) {
-	DWSOperationDuration.With(prometheus.Labels{"resource": resource, "vc_name": cluster}).Observe(SinceInSeconds(start))
+	DWSOperationDuration.With(vcLabels(DWSOperationDuration, prometheus.Labels{"resource": resource}, cluster)).Observe(SinceInSeconds(start))
 }
 
 func RecordDWSOperationStatus(resource, cluster, code string) {
-	DWSOperationCounter.With(prometheus.Labels{"resource": resource, "vc_name": cluster, "code": code}).Inc()
+	DWSOperationCounter.With(vcLabels(DWSOperationCounter, prometheus.Labels{"resource": resource, "code": code}, cluster)).Inc()
+}
+
+func RecordDWSActiveWorkers(resource, cluster string, workers int) {
+	setVCGauge(DWSActiveWorkers, prometheus.Labels{"resource": resource}, cluster, float64(workers), sumValues)
+}
+
+func RecordClusterInFlightWorkers(cluster string, workers int) {
+	setVCGauge(ClusterInFlightWorkers, prometheus.Labels{}, cluster, float64(workers), sumValues)
+}
+
+func RecordObjectCount(resource, cluster string, count int) {
+	setVCGauge(ObjectCount, prometheus.Labels{"resource": resource}, cluster, float64(count), sumValues)
+}
+
+func DeleteObjectCount(resource, cluster string) {
+	deleteVCGauge(ObjectCount, prometheus.Labels{"resource": resource}, cluster)
+}
+
+func RecordOnboardingProgress(resource, cluster string, reconciled, total int) {
+	labels := prometheus.Labels{"resource": resource}
+	setVCGauge(OnboardingReconciled, labels, cluster, float64(reconciled), sumValues)
+	setVCGauge(OnboardingObjects, labels, cluster, float64(total), sumValues)
+}
+
+func DeleteOnboardingProgress(resource, cluster string) {
+	labels := prometheus.Labels{"resource": resource}
+	deleteVCGauge(OnboardingReconciled, labels, cluster)
+	deleteVCGauge(OnboardingObjects, labels, cluster)
+}
+
+func RecordObjectCountCorrection(resource, cluster string) {
+	ObjectCountCorrections.With(vcLabels(ObjectCountCorrections, prometheus.Labels{"resource": resource}, cluster)).Inc()
+}
+
+func RecordPausedObjects(resource, cluster string, count int) {
+	setVCGauge(PausedObjects, prometheus.Labels{"resource": resource}, cluster, float64(count), sumValues)
+}
+
+func DeletePausedObjects(resource, cluster string) {
+	deleteVCGauge(PausedObjects, prometheus.Labels{"resource": resource}, cluster)
+}
+
+func RecordExistenceDisagreement(resource, cluster, resolution string) {
+	ExistenceDisagreements.With(vcLabels(ExistenceDisagreements, prometheus.Labels{"resource": resource, "resolution": resolution}, cluster)).Inc()
+}
+
+func RecordClusterScopedConflict(resource, cluster, resolution string) {
+	ClusterScopedConflicts.With(vcLabels(ClusterScopedConflicts, prometheus.Labels{"resource": resource, "resolution": resolution}, cluster)).Inc()
+}
+
+func RecordDeadLetters(resource, cluster string, count int) {
+	setVCGauge(DeadLetters, prometheus.Labels{"resource": resource}, cluster, float64(count), sumValues)
+}
+
+func DeleteDeadLetters(resource, cluster string) {
+	deleteVCGauge(DeadLetters, prometheus.Labels{"resource": resource}, cluster)
+}
+
+func RecordSuperWatchError(resource string) {
+	SuperWatchErrors.With(prometheus.Labels{"resource": resource}).Inc()
+}
+
+func RecordSuperWatchFailing(resource string, failing bool) {
+	value := 0.0
+	if failing {
+		value = 1
+	}
+	SuperWatchFailing.With(prometheus.Labels{"resource": resource}).Set(value)
+}
+
+func RecordDryRunOperation(resource, verb string) {
+	DryRunOperations.With(prometheus.Labels{"resource": resource, "verb": verb}).Inc()
+}
+
+func RecordSecretCertExpiry(cluster string, notAfter time.Time) {
+	setVCGauge(SecretCertExpiry, prometheus.Labels{}, cluster, float64(notAfter.Unix()), minValues)
+}
+
+func DeleteSecretCertExpiry(cluster string) {
+	deleteVCGauge(SecretCertExpiry, prometheus.Labels{}, cluster)
+}
+
+func RecordSyncLatency(resource, direction string, observed time.Time) {
+	SyncLatency.With(prometheus.Labels{"resource": resource, "direction": direction}).Observe(SinceInSeconds(observed))
+}
+
+func RecordQueueDepth(controller string, depth int) {
+	QueueDepth.With(prometheus.Labels{"controller": controller}).Set(float64(depth))
+}
+
+func RecordQueueRetry(controller string) {
+	QueueRetries.With(prometheus.Labels{"controller": controller}).Inc()
+}
+
+func RecordQueueDrop(controller string) {
+	QueueDrops.With(prometheus.Labels{"controller": controller}).Inc()
 }
@@ -0,0 +1,37 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/version"
+)
+
+func TestBuildInfo(t *testing.T) {
+	Register()
+
+	info := version.Get()
+	if got := testutil.ToFloat64(BuildInfo.WithLabelValues(info.GitVersion, info.GitCommit, info.BuildDate, info.GoVersion, info.Platform)); got != 1 {
+		t.Errorf("expected the build info of the running syncer to be 1, got %v", got)
+	}
+	if got := testutil.CollectAndCount(BuildInfo); got != 1 {
+		t.Errorf("expected a single build info series, got %d", got)
+	}
+}
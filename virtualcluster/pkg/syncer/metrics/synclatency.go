@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// ObservedTimes tracks when the changes of the queued work items were first observed, to record
+// the sync latency once an item is synced. The changes observed again before the item is synced
+// keep the time of the first one, they are synced together.
+type ObservedTimes struct {
+	sync.Mutex
+	times map[interface{}]time.Time
+}
+
+func NewObservedTimes() *ObservedTimes {
+	return &ObservedTimes{times: make(map[interface{}]time.Time)}
+}
+
+// Observe records that a change of the item is observed now, unless an earlier change is pending.
+func (o *ObservedTimes) Observe(item interface{}) {
+	o.Lock()
+	defer o.Unlock()
+	if _, ok := o.times[item]; !ok {
+		o.times[item] = time.Now()
+	}
+}
+
+// Take returns when the pending change of the item was observed and forgets it, so that a change
+// observed while the item is processed is tracked on its own.
+func (o *ObservedTimes) Take(item interface{}) (time.Time, bool) {
+	o.Lock()
+	defer o.Unlock()
+	observed, ok := o.times[item]
+	delete(o.times, item)
+	return observed, ok
+}
+
+// Restore puts back the observed time of an item taken but not synced, e.g. to retry it, unless a
+// change observed earlier is pending.
+func (o *ObservedTimes) Restore(item interface{}, observed time.Time) {
+	o.Lock()
+	defer o.Unlock()
+	if pending, ok := o.times[item]; !ok || observed.Before(pending) {
+		o.times[item] = observed
+	}
+}
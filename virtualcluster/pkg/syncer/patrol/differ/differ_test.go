@@ -26,8 +26,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 )
 
@@ -120,6 +122,35 @@ func TestDifferSetDifference(t *testing.T) {
 	}
 }
 
+func TestDefaultDifferFilterSyncPause(t *testing.T) {
+	superObject := func(name string) ClusterObject {
+		obj := makeObject(conversion.ToSuperClusterNamespace("t1", "n1"), name)
+		obj.SetAnnotations(map[string]string{constants.LabelCluster: "t1", constants.LabelNamespace: "n1"})
+		return ClusterObject{Key: "t1-n1/" + name, Object: obj}
+	}
+	paused := makeObject("n1", "paused")
+	paused.SetAnnotations(map[string]string{constants.LabelSyncPause: "true"})
+	ta := ClusterObject{Key: "t1-n1/a", OwnerCluster: "t1", Object: makeObject("n1", "a")}
+	tp := ClusterObject{Key: "t1-n1/paused", OwnerCluster: "t1", Object: paused}
+
+	updateCounter := make(map[string]int)
+	d := HandlerFuncs{
+		UpdateFunc: func(obj1, obj2 ClusterObject) {
+			updateCounter[obj1.Key]++
+		},
+	}
+
+	NewDiffSet(ta, tp).Difference(NewDiffSet(superObject("a"), superObject("paused")), FilteringHandler{
+		Handler:    d,
+		FilterFunc: DefaultDifferFilter(sets.NewString("t1")),
+	})
+
+	expectedUpdateCounter := map[string]int{ta.Key: 1}
+	if !equality.Semantic.DeepEqual(updateCounter, expectedUpdateCounter) {
+		t.Errorf("Expected updateCounter %+v, got %+v", expectedUpdateCounter, updateCounter)
+	}
+}
+
 func Benchmark_Difference_1000(b *testing.B) {
 	b.ReportAllocs()
 	rand.Seed(time.Now().UnixNano())
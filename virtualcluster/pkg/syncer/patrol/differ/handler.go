@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
 // HandlerFuncs is an adaptor to let you easily specify as many or
@@ -88,9 +89,9 @@ func (h FilteringHandler) OnDelete(obj ClusterObject) {
 
 func DefaultDifferFilter(knownClusterSet sets.String) func(obj ClusterObject) bool {
 	return func(obj ClusterObject) bool {
-		// vObj
+		// vObj, paused tenant objects and their super control plane counterparts are left as is.
 		if obj.OwnerCluster != "" {
-			return knownClusterSet.Has(obj.OwnerCluster)
+			return knownClusterSet.Has(obj.OwnerCluster) && !mc.IsSyncPaused(obj)
 		}
 
 		// pObj
@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+// pruneListLimit is the page size of the lists of the objects to prune.
+const pruneListLimit = 500
+
+// prunableResource is a kind of super cluster object created by a syncer.
+type prunableResource struct {
+	resource schema.GroupVersionResource
+	// synced returns true if the super cluster object was created by the syncer.
+	synced func(obj metav1.Object) bool
+}
+
+// prunableResources are the objects created in the super cluster by the opt-in syncers, by plugin
+// ID. The opt-in syncers that only sync upward, e.g. priorityclass, create nothing to prune.
+//
+// The deployments and replicasets are deleted with the default background propagation, so pruning
+// them cascades: the super cluster garbage collector deletes their replicasets and pods, i.e. the
+// tenant workloads they run stop.
+var prunableResources = map[string][]prunableResource{
+	"deployment": {{
+		resource: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		synced:   isDownwardSynced,
+	}},
+	"replicaset": {{
+		resource: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "replicasets"},
+		synced:   isDownwardSynced,
+	}},
+	"ingress": {{
+		resource: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		synced:   isDownwardSynced,
+	}},
+	"clusterrolebinding": {{
+		resource: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+		synced:   isScopedClusterRBAC,
+	}, {
+		resource: schema.GroupVersionResource{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
+		synced:   isScopedClusterRBAC,
+	}},
+}
+
+// prune deletes the super cluster objects of the resource created by the syncer, listing them page
+// by page, and returns the number of deleted objects.
+func (r prunableResource) prune(c dynamic.Interface, id string) (int, error) {
+	pruned := 0
+	opts := metav1.ListOptions{Limit: pruneListLimit}
+	for {
+		list, err := c.Resource(r.resource).Namespace(metav1.NamespaceAll).List(context.TODO(), opts)
+		if err != nil {
+			return pruned, err
+		}
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if !r.synced(obj) {
+				continue
+			}
+			deleteOpts := metav1.DeleteOptions{
+				PropagationPolicy: &constants.DefaultDeletionPolicy,
+				Preconditions:     metav1.NewUIDPreconditions(string(obj.GetUID())),
+			}
+			if err := c.Resource(r.resource).Namespace(obj.GetNamespace()).Delete(context.TODO(), obj.GetName(), deleteOpts); err != nil && !apierrors.IsNotFound(err) {
+				klog.Errorf("failed to prune %s %s/%s of disabled syncer %s: %v", r.resource.Resource, obj.GetNamespace(), obj.GetName(), id, err)
+				continue
+			}
+			pruned++
+		}
+		if list.GetContinue() == "" {
+			return pruned, nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+// isDownwardSynced returns true if the super cluster object is synced from a tenant object. The
+// objects created by the super cluster controllers, e.g. the replicasets of a synced deployment,
+// carry the annotations of their owner but also an owner reference.
+func isDownwardSynced(obj metav1.Object) bool {
+	anno := obj.GetAnnotations()
+	return anno[constants.LabelCluster] != "" && anno[constants.LabelUID] != "" && len(obj.GetOwnerReferences()) == 0
+}
+
+// isScopedClusterRBAC returns true if the super cluster object is converted from a tenant ClusterRoleBinding.
+func isScopedClusterRBAC(obj metav1.Object) bool {
+	return obj.GetLabels()[constants.LabelScopedClusterRBAC] == "true" && obj.GetAnnotations()[constants.LabelCluster] != ""
+}
+
+// DisabledPlugins returns the IDs of the registered syncers that are not loaded with the config.
+func DisabledPlugins(config *config.SyncerConfiguration) sets.String {
+	disabled := sets.NewString()
+	for _, r := range plugin.SyncerResourceRegister.List() {
+		disabled.Insert(r.ID)
+	}
+	for _, r := range LoadPlugins(config) {
+		disabled.Delete(r.ID)
+	}
+	return disabled
+}
+
+// pruneDisabledResources deletes the super cluster objects synced by the given disabled syncers.
+// Nothing reconciles them anymore, they would be left behind otherwise.
+func (s *Syncer) pruneDisabledResources(disabled sets.String) {
+	for _, id := range disabled.List() {
+		for _, r := range prunableResources[id] {
+			pruned, err := r.prune(s.superDynamicClient, id)
+			if err != nil {
+				klog.Errorf("failed to list %s to prune for disabled syncer %s: %v", r.resource.Resource, id, err)
+			}
+			if pruned > 0 {
+				klog.Infof("pruned %d %s of disabled syncer %s", pruned, r.resource.Resource, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func syncedMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: "cluster1-default",
+		UID:       "12345",
+		Annotations: map[string]string{
+			constants.LabelCluster: "cluster1",
+			constants.LabelUID:     "67890",
+		},
+	}
+}
+
+func TestDisabledPlugins(t *testing.T) {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{ID: "prune-test-default"})
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{ID: "prune-test-extra", Disable: true})
+
+	disabled := DisabledPlugins(&config.SyncerConfiguration{})
+	if !disabled.Has("prune-test-extra") || disabled.Has("prune-test-default") {
+		t.Errorf("expected only the opt-in syncer to be disabled, got %v", disabled.List())
+	}
+	disabled = DisabledPlugins(&config.SyncerConfiguration{ExtraSyncingResources: []string{"prune-test-extra"}})
+	if disabled.Has("prune-test-extra") || disabled.Has("prune-test-default") {
+		t.Errorf("expected no disabled syncer, got %v", disabled.List())
+	}
+}
+
+func TestPruneDisabledResources(t *testing.T) {
+	syncedDeployment := &appsv1.Deployment{ObjectMeta: syncedMeta("synced")}
+	superDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "super", Namespace: "cluster1-default"}}
+	ownedReplicaSet := &appsv1.ReplicaSet{ObjectMeta: syncedMeta("owned")}
+	ownedReplicaSet.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "synced", UID: "12345"}}
+	syncedIngress := &networkingv1.Ingress{ObjectMeta: syncedMeta("synced")}
+	scopedMeta := syncedMeta("scoped")
+	scopedMeta.Labels = map[string]string{constants.LabelScopedClusterRBAC: "true"}
+	scopedRole := &rbacv1.Role{ObjectMeta: scopedMeta}
+	scopedRoleBinding := &rbacv1.RoleBinding{ObjectMeta: scopedMeta}
+	superRole := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: "super", Namespace: "cluster1-default"}}
+
+	superClient := fakedynamic.NewSimpleDynamicClient(scheme.Scheme, syncedDeployment, superDeployment, ownedReplicaSet, syncedIngress, scopedRole, scopedRoleBinding, superRole)
+	s := &Syncer{
+		config:             &config.SyncerConfiguration{PruneOnFeatureDisable: true},
+		superDynamicClient: superClient,
+	}
+	// ingress is still enabled.
+	s.pruneDisabledResources(sets.NewString("deployment", "replicaset", "clusterrolebinding"))
+
+	for _, tt := range []struct {
+		resource string
+		expected []string
+	}{
+		{resource: "deployments", expected: []string{"super"}},
+		{resource: "replicasets", expected: []string{"owned"}},
+		{resource: "ingresses", expected: []string{"synced"}},
+		{resource: "roles", expected: []string{"super"}},
+		{resource: "rolebindings"},
+	} {
+		var gvr schema.GroupVersionResource
+		for _, resources := range prunableResources {
+			for _, r := range resources {
+				if r.resource.Resource == tt.resource {
+					gvr = r.resource
+				}
+			}
+		}
+		list, err := superClient.Resource(gvr).Namespace(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			t.Fatalf("failed to list %s: %v", tt.resource, err)
+		}
+		var names []string
+		for _, obj := range list.Items {
+			names = append(names, obj.GetName())
+		}
+		if !reflect.DeepEqual(names, tt.expected) {
+			t.Errorf("expected the %s %v to be left, got %v", tt.resource, tt.expected, names)
+		}
+	}
+}
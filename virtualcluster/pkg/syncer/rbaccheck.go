@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+var (
+	readVerbs  = []string{"get", "list", "watch"}
+	writeVerbs = []string{"get", "list", "watch", "create", "update", "patch", "delete"}
+)
+
+// superClusterPermission is a resource of the super cluster and the verbs a syncer needs on it.
+type superClusterPermission struct {
+	group    string
+	resource string
+	verbs    []string
+}
+
+// requiredPermissions are the super cluster permissions of the syncers, by plugin ID. The syncers
+// read the objects they sync upward and write the ones they sync downward.
+var requiredPermissions = map[string][]superClusterPermission{
+	"clusterrolebinding":    {{"rbac.authorization.k8s.io", "roles", writeVerbs}, {"rbac.authorization.k8s.io", "rolebindings", writeVerbs}, {"", "namespaces", readVerbs}},
+	"configmap":             {{"", "configmaps", writeVerbs}},
+	"crd":                   {{"apiextensions.k8s.io", "customresourcedefinitions", readVerbs}},
+	"deployment":            {{"apps", "deployments", writeVerbs}},
+	"endpoints":             {{"", "endpoints", writeVerbs}},
+	"event":                 {{"", "events", readVerbs}},
+	"ingress":               {{"networking.k8s.io", "ingresses", writeVerbs}},
+	"namespace":             {{"", "namespaces", writeVerbs}},
+	"node":                  {{"", "nodes", readVerbs}},
+	"persistentvolume":      {{"", "persistentvolumes", readVerbs}},
+	"persistentvolumeclaim": {{"", "persistentvolumeclaims", writeVerbs}},
+	"pod":                   {{"", "pods", writeVerbs}, {"", "pods/status", []string{"update"}}},
+	"priorityclass":         {{"scheduling.k8s.io", "priorityclasses", readVerbs}},
+	"replicaset":            {{"apps", "replicasets", writeVerbs}},
+	"secret":                {{"", "secrets", writeVerbs}},
+	"service":               {{"", "services", writeVerbs}},
+	"serviceaccount":        {{"", "serviceaccounts", writeVerbs}},
+	"storageclass":          {{"storage.k8s.io", "storageclasses", readVerbs}},
+}
+
+// checkSuperClusterPermissions asks the super cluster, with SelfSubjectAccessReviews, whether the
+// syncer has the permissions the enabled syncers need and logs the missing ones. It returns an
+// error if permissions are missing and RequireRBAC is set, so that the syncer does not start and fail
+// every sync of the resource instead. A failing review only stops the syncer with RequireRBAC too,
+// the check is skipped otherwise.
+func checkSuperClusterPermissions(client clientset.Interface, plugins []*plugin.Registration, config *config.SyncerConfiguration) error {
+	var missing []string
+	for _, p := range plugins {
+		perms := requiredPermissions[p.ID]
+		if p.ID == "namespace" && config.ProvisionSuperNamespaceQuota {
+			perms = append(perms, superClusterPermission{"", "resourcequotas", writeVerbs})
+		}
+		if p.ID == "pod" && !config.DisableEphemeralContainersSync {
+			perms = append(perms, superClusterPermission{"", "pods/ephemeralcontainers", []string{"update"}})
+		}
+		for _, perm := range perms {
+			resource, subresource := perm.resource, ""
+			if i := strings.Index(resource, "/"); i >= 0 {
+				resource, subresource = resource[:i], resource[i+1:]
+			}
+			for _, verb := range perm.verbs {
+				review := &authorizationv1.SelfSubjectAccessReview{
+					Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+						ResourceAttributes: &authorizationv1.ResourceAttributes{
+							Verb:        verb,
+							Group:       perm.group,
+							Resource:    resource,
+							Subresource: subresource,
+						},
+					},
+				}
+				result, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(context.TODO(), review, metav1.CreateOptions{})
+				if err != nil {
+					err = fmt.Errorf("failed to review the %s permission on %s: %v", verb, qualifiedResource(perm), err)
+					if config.RequireRBAC {
+						return err
+					}
+					klog.Warningf("skip the super cluster permission check: %v", err)
+					return nil
+				}
+				if !result.Status.Allowed {
+					missing = append(missing, fmt.Sprintf("%s %s (%s syncer)", verb, qualifiedResource(perm), p.ID))
+				}
+			}
+		}
+	}
+	if len(missing) == 0 {
+		klog.Infof("the syncer has all the super cluster permissions of the enabled syncers")
+		return nil
+	}
+	klog.Warningf("the syncer is missing %d super cluster permissions: %s", len(missing), strings.Join(missing, ", "))
+	if config.RequireRBAC {
+		return fmt.Errorf("missing super cluster permissions: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func qualifiedResource(perm superClusterPermission) string {
+	if perm.group == "" {
+		return perm.resource
+	}
+	return perm.resource + "." + perm.group
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+// failingAuthorizer fails every SelfSubjectAccessReview.
+func failingAuthorizer() *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action core.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("the server is currently unable to handle the request")
+	})
+	return client
+}
+
+// denyingAuthorizer allows every super cluster request but the denied verb/resource ones.
+func denyingAuthorizer(denied ...string) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "selfsubjectaccessreviews", func(action core.Action) (bool, runtime.Object, error) {
+		review := action.(core.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		resource := attrs.Resource
+		if attrs.Subresource != "" {
+			resource += "/" + attrs.Subresource
+		}
+		review.Status.Allowed = true
+		for _, d := range denied {
+			if d == attrs.Verb+" "+resource {
+				review.Status.Allowed = false
+			}
+		}
+		return true, review, nil
+	})
+	return client
+}
+
+func TestCheckSuperClusterPermissions(t *testing.T) {
+	plugins := []*plugin.Registration{{ID: "secret"}, {ID: "pod"}, {ID: "node"}, {ID: "namespace"}}
+
+	for _, tt := range []struct {
+		name            string
+		denied          []string
+		required        bool
+		provisionQuota  bool
+		noEphemeral     bool
+		expectedMissing []string
+	}{
+		{
+			name:     "all allowed",
+			required: true,
+		},
+		{
+			name:     "missing permissions not required",
+			denied:   []string{"delete secrets", "update pods/status"},
+			required: false,
+		},
+		{
+			name:            "missing permissions required",
+			denied:          []string{"delete secrets", "update pods/status", "create deployments"},
+			required:        true,
+			expectedMissing: []string{"delete secrets (secret syncer)", "update pods/status (pod syncer)"},
+		},
+		{
+			name:     "resourcequotas not needed",
+			denied:   []string{"create resourcequotas"},
+			required: true,
+		},
+		{
+			name:            "resourcequotas needed to provision quotas",
+			denied:          []string{"create resourcequotas"},
+			required:        true,
+			provisionQuota:  true,
+			expectedMissing: []string{"create resourcequotas (namespace syncer)"},
+		},
+		{
+			name:            "patch and ephemeral containers needed",
+			denied:          []string{"patch secrets", "update pods/ephemeralcontainers"},
+			required:        true,
+			expectedMissing: []string{"patch secrets (secret syncer)", "update pods/ephemeralcontainers (pod syncer)"},
+		},
+		{
+			name:        "ephemeral containers not synced",
+			denied:      []string{"update pods/ephemeralcontainers"},
+			required:    true,
+			noEphemeral: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			err := checkSuperClusterPermissions(denyingAuthorizer(tt.denied...), plugins, &config.SyncerConfiguration{RequireRBAC: tt.required, ProvisionSuperNamespaceQuota: tt.provisionQuota, DisableEphemeralContainersSync: tt.noEphemeral})
+			if len(tt.expectedMissing) == 0 {
+				if err != nil {
+					tc.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				tc.Fatalf("expected missing permissions error")
+			}
+			for _, m := range tt.expectedMissing {
+				if !strings.Contains(err.Error(), m) {
+					tc.Errorf("expected the error to name %q, got %v", m, err)
+				}
+			}
+			// the deployment syncer is not enabled.
+			if strings.Contains(err.Error(), "deployments") {
+				tc.Errorf("expected only the permissions of the enabled syncers to be checked, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckSuperClusterPermissionsReviewError(t *testing.T) {
+	plugins := []*plugin.Registration{{ID: "secret"}}
+	if err := checkSuperClusterPermissions(failingAuthorizer(), plugins, &config.SyncerConfiguration{}); err != nil {
+		t.Errorf("expected a failing review to be skipped without RequireRBAC, got %v", err)
+	}
+	if err := checkSuperClusterPermissions(failingAuthorizer(), plugins, &config.SyncerConfiguration{RequireRBAC: true}); err == nil {
+		t.Errorf("expected a failing review to be an error with RequireRBAC")
+	}
+}
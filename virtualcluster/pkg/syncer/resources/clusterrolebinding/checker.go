@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrolebinding
+
+import (
+	"fmt"
+	"sync"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.roleSynced, c.roleBindingSynced, c.nsSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting ClusterRoleBinding checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo requeues all tenant clusterrolebindings, so that changes of the referred clusterroles
+// and of the tenant namespaces are picked up, and removes the super control plane roles and
+// rolebindings whose tenant clusterrolebinding is gone.
+func (c *controller) PatrollerDo() {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.V(5).Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "clusterrolebinding")
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	for _, clusterName := range clusterNames {
+		wg.Add(1)
+		go func(clusterName string) {
+			defer wg.Done()
+			c.checkClusterRoleBindingsOfTenantCluster(clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	selector := labels.SelectorFromSet(labels.Set{constants.LabelScopedClusterRBAC: "true"})
+	pRoleBindings, err := c.roleBindingLister.List(selector)
+	if err != nil {
+		klog.Errorf("error listing rolebindings from super control plane informer cache: %v", err)
+		return
+	}
+	for _, pRoleBinding := range pRoleBindings {
+		if !c.isOrphan(pRoleBinding.Annotations) {
+			continue
+		}
+		if err := c.deleteRoleBinding(pRoleBinding); err != nil {
+			klog.Errorf("error deleting pRoleBinding %s/%s in super control plane: %v", pRoleBinding.Namespace, pRoleBinding.Name, err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperControlPlaneScopedRoleBindings").Inc()
+		}
+	}
+
+	pRoles, err := c.roleLister.List(selector)
+	if err != nil {
+		klog.Errorf("error listing roles from super control plane informer cache: %v", err)
+		return
+	}
+	for _, pRole := range pRoles {
+		if !c.isOrphan(pRole.Annotations) {
+			continue
+		}
+		if err := c.deleteRole(pRole); err != nil {
+			klog.Errorf("error deleting pRole %s/%s in super control plane: %v", pRole.Namespace, pRole.Name, err)
+		} else {
+			metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperControlPlaneScopedRoles").Inc()
+		}
+	}
+}
+
+// isOrphan returns true if the tenant clusterrolebinding recorded in the annotations does not exist anymore.
+func (c *controller) isOrphan(annotations map[string]string) bool {
+	clusterName, name := annotations[constants.LabelCluster], annotations[constants.LabelClusterRoleBinding]
+	if clusterName == "" || name == "" {
+		return false
+	}
+	vClusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+	err := c.MultiClusterController.Get(clusterName, "", name, vClusterRoleBinding)
+	return apierrors.IsNotFound(err)
+}
+
+func (c *controller) checkClusterRoleBindingsOfTenantCluster(clusterName string) {
+	crbList := &rbacv1.ClusterRoleBindingList{}
+	if err := c.MultiClusterController.List(clusterName, crbList); err != nil {
+		klog.Errorf("error listing clusterrolebindings from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	klog.V(4).Infof("check clusterrolebindings consistency in cluster %s", clusterName)
+
+	for i := range crbList.Items {
+		if err := c.MultiClusterController.RequeueObject(clusterName, &crbList.Items[i]); err != nil {
+			klog.Errorf("error requeue vclusterrolebinding %s in cluster %s: %v", crbList.Items[i].Name, clusterName, err)
+		}
+	}
+}
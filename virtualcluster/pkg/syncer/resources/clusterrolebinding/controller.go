@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrolebinding
+
+import (
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	v1rbac "k8s.io/client-go/kubernetes/typed/rbac/v1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	listersrbacv1 "k8s.io/client-go/listers/rbac/v1"
+	"k8s.io/client-go/tools/cache"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "clusterrolebinding",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			if !featuregate.DefaultFeatureGate.Enabled(featuregate.ScopedTenantClusterRBAC) {
+				return nil, fmt.Errorf("syncing clusterrolebindings requires the %s feature gate", featuregate.ScopedTenantClusterRBAC)
+			}
+			return NewClusterRoleBindingController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+		Disable: true,
+	})
+}
+
+// controller syncs tenant ClusterRoleBindings to Roles and RoleBindings in the
+// super control plane namespaces of the tenant. It never creates cluster scoped
+// RBAC objects in the super control plane.
+type controller struct {
+	manager.BaseResourceSyncer
+	// super control plane rbac client
+	rbacClient v1rbac.RbacV1Interface
+	// super control plane informer listers/synced functions
+	roleLister        listersrbacv1.RoleLister
+	roleSynced        cache.InformerSynced
+	roleBindingLister listersrbacv1.RoleBindingLister
+	roleBindingSynced cache.InformerSynced
+	nsLister          listersv1.NamespaceLister
+	nsSynced          cache.InformerSynced
+}
+
+func NewClusterRoleBindingController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		rbacClient: client.RbacV1(),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&rbacv1.ClusterRoleBinding{}, &rbacv1.ClusterRoleBindingList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.roleLister = informer.Rbac().V1().Roles().Lister()
+	c.roleBindingLister = informer.Rbac().V1().RoleBindings().Lister()
+	c.nsLister = informer.Core().V1().Namespaces().Lister()
+	if options.IsFake {
+		c.roleSynced = func() bool { return true }
+		c.roleBindingSynced = func() bool { return true }
+		c.nsSynced = func() bool { return true }
+	} else {
+		util.TrackWatchErrors(informer.Rbac().V1().Roles().Informer(), "roles")
+		c.roleSynced = informer.Rbac().V1().Roles().Informer().HasSynced
+		util.TrackWatchErrors(informer.Rbac().V1().RoleBindings().Informer(), "rolebindings")
+		c.roleBindingSynced = informer.Rbac().V1().RoleBindings().Informer().HasSynced
+		util.TrackWatchErrors(informer.Core().V1().Namespaces().Informer(), "namespaces")
+		c.nsSynced = informer.Core().V1().Namespaces().Informer().HasSynced
+	}
+
+	c.Patroller, err = pa.NewPatroller(&rbacv1.ClusterRoleBinding{}, c, pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
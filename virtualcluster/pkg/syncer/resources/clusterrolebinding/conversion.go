@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrolebinding
+
+import (
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+// allowedVerbs are the verbs a scoped rule may grant. The wildcard verb and the bind, escalate and
+// impersonate verbs, which allow a subject to grant or assume permissions it does not hold, are
+// not in the list.
+var allowedVerbs = sets.NewString("create", "delete", "deletecollection", "get", "list", "patch", "update", "watch")
+
+// allowedResources are the resources, by API group, a scoped rule may grant. They are the namespaced
+// resources a tenant workload owns in its super control plane namespaces. RBAC, quota and limit range
+// resources, which the super control plane administrator manages, are not in the list.
+var allowedResources = map[string]sets.String{
+	"":                    sets.NewString("configmaps", "endpoints", "events", "persistentvolumeclaims", "pods", "pods/log", "secrets", "serviceaccounts", "services"),
+	"apps":                sets.NewString("daemonsets", "deployments", "deployments/scale", "replicasets", "replicasets/scale", "statefulsets", "statefulsets/scale"),
+	"autoscaling":         sets.NewString("horizontalpodautoscalers"),
+	"batch":               sets.NewString("cronjobs", "jobs"),
+	"coordination.k8s.io": sets.NewString("leases"),
+	"discovery.k8s.io":    sets.NewString("endpointslices"),
+	"events.k8s.io":       sets.NewString("events"),
+	"policy":              sets.NewString("poddisruptionbudgets"),
+}
+
+// ScopedName returns the name of the super control plane Role and RoleBinding of a tenant ClusterRoleBinding.
+func ScopedName(clusterRoleBinding string) string {
+	return constants.ScopedClusterRBACNamePrefix + clusterRoleBinding
+}
+
+// ScopeRules converts the rules of a tenant ClusterRole to the rules of a namespaced Role. Only the
+// allowed verbs, API groups and resources of a rule are kept, wildcards included in none of them, and
+// a rule is split into one rule per API group. nonResourceURLs rules and rules left without a verb or
+// a resource are removed.
+func ScopeRules(rules []rbacv1.PolicyRule) []rbacv1.PolicyRule {
+	var scoped []rbacv1.PolicyRule
+	for _, rule := range rules {
+		if len(rule.NonResourceURLs) > 0 {
+			continue
+		}
+		verbs := allowedVerbs.Intersection(sets.NewString(rule.Verbs...))
+		if verbs.Len() == 0 {
+			continue
+		}
+		for _, group := range sets.NewString(rule.APIGroups...).List() {
+			allowed, ok := allowedResources[group]
+			if !ok {
+				continue
+			}
+			resources := allowed.Intersection(sets.NewString(rule.Resources...))
+			if resources.Len() == 0 {
+				continue
+			}
+			scoped = append(scoped, rbacv1.PolicyRule{
+				Verbs:         verbs.List(),
+				APIGroups:     []string{group},
+				Resources:     resources.List(),
+				ResourceNames: append([]string(nil), rule.ResourceNames...),
+			})
+		}
+	}
+	return scoped
+}
+
+// ScopeSubjects converts the subjects of a tenant ClusterRoleBinding to the subjects of a RoleBinding
+// in the super control plane. Only ServiceAccounts of the given tenant namespaces are kept, with their
+// namespace translated to the super control plane namespace. Users and Groups are dropped since they
+// are only known to the tenant.
+func ScopeSubjects(clusterName string, subjects []rbacv1.Subject, tenantNamespaces sets.String) []rbacv1.Subject {
+	var scoped []rbacv1.Subject
+	for _, subject := range subjects {
+		if subject.Kind != rbacv1.ServiceAccountKind || !tenantNamespaces.Has(subject.Namespace) {
+			continue
+		}
+		scoped = append(scoped, rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      subject.Name,
+			Namespace: conversion.ToSuperClusterNamespace(clusterName, subject.Namespace),
+		})
+	}
+	return scoped
+}
+
+// scopedObjectMeta returns the metadata of the super control plane Role and RoleBinding
+// of a tenant ClusterRoleBinding in the given super control plane namespace.
+func scopedObjectMeta(clusterName, targetNamespace string, crb *rbacv1.ClusterRoleBinding) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      ScopedName(crb.Name),
+		Namespace: targetNamespace,
+		Labels: map[string]string{
+			constants.LabelScopedClusterRBAC: "true",
+		},
+		Annotations: map[string]string{
+			constants.LabelCluster:            clusterName,
+			constants.LabelClusterRoleBinding: crb.Name,
+			constants.LabelUID:                string(crb.UID),
+		},
+	}
+}
+
+// BuildScopedRole builds the Role granting the scoped rules of a tenant ClusterRoleBinding
+// in a super control plane namespace.
+func BuildScopedRole(clusterName, targetNamespace string, crb *rbacv1.ClusterRoleBinding, rules []rbacv1.PolicyRule) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: scopedObjectMeta(clusterName, targetNamespace, crb),
+		Rules:      rules,
+	}
+}
+
+// BuildScopedRoleBinding builds the RoleBinding binding the scoped subjects of a tenant ClusterRoleBinding
+// to its scoped Role in a super control plane namespace.
+func BuildScopedRoleBinding(clusterName, targetNamespace string, crb *rbacv1.ClusterRoleBinding, subjects []rbacv1.Subject) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: scopedObjectMeta(clusterName, targetNamespace, crb),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     ScopedName(crb.Name),
+		},
+		Subjects: subjects,
+	}
+}
+
+// validateScope makes sure a RoleBinding and its subjects stay within the given super control
+// plane namespaces of a tenant.
+func validateScope(binding *rbacv1.RoleBinding, superNamespaces sets.String) error {
+	if !superNamespaces.Has(binding.Namespace) {
+		return fmt.Errorf("rolebinding %s/%s is not in a namespace of the tenant", binding.Namespace, binding.Name)
+	}
+	if binding.RoleRef.Kind != "Role" {
+		return fmt.Errorf("rolebinding %s/%s refers to a %s", binding.Namespace, binding.Name, binding.RoleRef.Kind)
+	}
+	for _, subject := range binding.Subjects {
+		if subject.Kind != rbacv1.ServiceAccountKind || !superNamespaces.Has(subject.Namespace) {
+			return fmt.Errorf("rolebinding %s/%s has subject %s %s/%s outside of the tenant", binding.Namespace, binding.Name, subject.Kind, subject.Namespace, subject.Name)
+		}
+	}
+	return nil
+}
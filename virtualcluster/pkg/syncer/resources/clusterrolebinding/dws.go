@@ -0,0 +1,258 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrolebinding
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// scopedRBAC is the Role and RoleBinding of a tenant ClusterRoleBinding in a super control plane namespace.
+type scopedRBAC struct {
+	role    *rbacv1.Role
+	binding *rbacv1.RoleBinding
+}
+
+func (c *controller) StartDWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.roleSynced, c.roleBindingSynced, c.nsSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.MultiClusterController.Start(stopCh)
+}
+
+// The reconcile logic for tenant control plane clusterrolebinding informer
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	logsampling.V(4, "clusterrolebinding").Infof("reconcile clusterrolebinding %s event for cluster %s", request.Name, request.ClusterName)
+
+	desired, superNamespaces, err := c.desiredScopedRBAC(request.ClusterName, request.Name)
+	if err != nil {
+		return reconciler.Result{Requeue: true}, err
+	}
+	existingRoles, existingBindings, err := c.existingScopedRBAC(request.ClusterName, request.Name)
+	if err != nil {
+		return reconciler.Result{Requeue: true}, err
+	}
+
+	// written is whether the reconcile wrote to the super control plane.
+	written := false
+	for targetNamespace, want := range desired {
+		if err := validateScope(want.binding, superNamespaces); err != nil {
+			// never grant anything outside of the tenant, retrying won't help.
+			klog.Errorf("refuse to sync clusterrolebinding %s of cluster %s: %v", request.Name, request.ClusterName, err)
+			return reconciler.Result{}, nil
+		}
+		roleWritten, err := c.reconcileRole(existingRoles[targetNamespace], want.role)
+		if err != nil {
+			klog.Errorf("failed reconcile role %s/%s of clusterrolebinding %s of cluster %s: %v", targetNamespace, want.role.Name, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+		bindingWritten, err := c.reconcileRoleBinding(existingBindings[targetNamespace], want.binding)
+		if err != nil {
+			klog.Errorf("failed reconcile rolebinding %s/%s of clusterrolebinding %s of cluster %s: %v", targetNamespace, want.binding.Name, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+		written = written || roleWritten || bindingWritten
+	}
+
+	for targetNamespace, binding := range existingBindings {
+		if _, ok := desired[targetNamespace]; ok {
+			continue
+		}
+		if err := c.deleteRoleBinding(binding); err != nil {
+			return reconciler.Result{Requeue: true}, err
+		}
+		written = true
+	}
+	for targetNamespace, role := range existingRoles {
+		if _, ok := desired[targetNamespace]; ok {
+			continue
+		}
+		if err := c.deleteRole(role); err != nil {
+			return reconciler.Result{Requeue: true}, err
+		}
+		written = true
+	}
+	if written {
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
+	}
+	return reconciler.Result{}, nil
+}
+
+// desiredScopedRBAC returns the Roles and RoleBindings a tenant ClusterRoleBinding converts to, by
+// super control plane namespace, along with all the super control plane namespaces of the tenant.
+func (c *controller) desiredScopedRBAC(clusterName, name string) (map[string]*scopedRBAC, sets.String, error) {
+	desired := make(map[string]*scopedRBAC)
+
+	vClusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+	if err := c.MultiClusterController.Get(clusterName, "", name, vClusterRoleBinding); err != nil {
+		if apierrors.IsNotFound(err) {
+			return desired, nil, nil
+		}
+		return nil, nil, err
+	}
+	if vClusterRoleBinding.RoleRef.Kind != "ClusterRole" {
+		return desired, nil, nil
+	}
+	vClusterRole := &rbacv1.ClusterRole{}
+	if err := c.MultiClusterController.Get(clusterName, "", vClusterRoleBinding.RoleRef.Name, vClusterRole); err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.V(4).Infof("clusterrole %s of clusterrolebinding %s of cluster %s not found", vClusterRoleBinding.RoleRef.Name, name, clusterName)
+			return desired, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	vNamespaces := &corev1.NamespaceList{}
+	if err := c.MultiClusterController.List(clusterName, vNamespaces); err != nil {
+		return nil, nil, err
+	}
+	tenantNamespaces := sets.NewString()
+	superNamespaces := sets.NewString()
+	for _, ns := range vNamespaces.Items {
+		tenantNamespaces.Insert(ns.Name)
+		superNamespaces.Insert(conversion.ToSuperClusterNamespace(clusterName, ns.Name))
+	}
+
+	rules := ScopeRules(vClusterRole.Rules)
+	subjects := ScopeSubjects(clusterName, vClusterRoleBinding.Subjects, tenantNamespaces)
+	if len(rules) == 0 || len(subjects) == 0 {
+		klog.V(4).Infof("clusterrolebinding %s of cluster %s grants nothing to scope to the super control plane", name, clusterName)
+		return desired, superNamespaces, nil
+	}
+
+	for _, targetNamespace := range superNamespaces.List() {
+		if _, err := c.nsLister.Get(targetNamespace); err != nil {
+			if apierrors.IsNotFound(err) {
+				// the namespace is not synced yet, it is picked up by the checker.
+				continue
+			}
+			return nil, nil, err
+		}
+		desired[targetNamespace] = &scopedRBAC{
+			role:    BuildScopedRole(clusterName, targetNamespace, vClusterRoleBinding, rules),
+			binding: BuildScopedRoleBinding(clusterName, targetNamespace, vClusterRoleBinding, subjects),
+		}
+	}
+	return desired, superNamespaces, nil
+}
+
+// existingScopedRBAC returns the Roles and RoleBindings of a tenant ClusterRoleBinding in the super
+// control plane, by namespace.
+func (c *controller) existingScopedRBAC(clusterName, name string) (map[string]*rbacv1.Role, map[string]*rbacv1.RoleBinding, error) {
+	selector := labels.SelectorFromSet(labels.Set{constants.LabelScopedClusterRBAC: "true"})
+	pRoles, err := c.roleLister.List(selector)
+	if err != nil {
+		return nil, nil, err
+	}
+	pRoleBindings, err := c.roleBindingLister.List(selector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roles := make(map[string]*rbacv1.Role)
+	for _, pRole := range pRoles {
+		if pRole.Annotations[constants.LabelCluster] == clusterName && pRole.Annotations[constants.LabelClusterRoleBinding] == name {
+			roles[pRole.Namespace] = pRole
+		}
+	}
+	bindings := make(map[string]*rbacv1.RoleBinding)
+	for _, pRoleBinding := range pRoleBindings {
+		if pRoleBinding.Annotations[constants.LabelCluster] == clusterName && pRoleBinding.Annotations[constants.LabelClusterRoleBinding] == name {
+			bindings[pRoleBinding.Namespace] = pRoleBinding
+		}
+	}
+	return roles, bindings, nil
+}
+
+// reconcileRole creates or updates the super control plane role, it returns whether it wrote it.
+func (c *controller) reconcileRole(pRole, role *rbacv1.Role) (bool, error) {
+	if pRole == nil {
+		_, err := c.rbacClient.Roles(role.Namespace).Create(context.TODO(), role, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return false, fmt.Errorf("role %s/%s exists but is not converted from a tenant clusterrolebinding", role.Namespace, role.Name)
+		}
+		return err == nil, err
+	}
+	if equality.Semantic.DeepEqual(pRole.Rules, role.Rules) && pRole.Annotations[constants.LabelUID] == role.Annotations[constants.LabelUID] {
+		return false, nil
+	}
+	updated := pRole.DeepCopy()
+	updated.Rules = role.Rules
+	updated.Annotations[constants.LabelUID] = role.Annotations[constants.LabelUID]
+	_, err := c.rbacClient.Roles(updated.Namespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+	return err == nil, err
+}
+
+// reconcileRoleBinding creates, updates or recreates the super control plane rolebinding, it
+// returns whether it wrote it.
+func (c *controller) reconcileRoleBinding(pRoleBinding, binding *rbacv1.RoleBinding) (bool, error) {
+	if pRoleBinding != nil && pRoleBinding.RoleRef != binding.RoleRef {
+		// roleRef is immutable, recreate the rolebinding.
+		if err := c.deleteRoleBinding(pRoleBinding); err != nil {
+			return false, err
+		}
+		pRoleBinding = nil
+	}
+	if pRoleBinding == nil {
+		_, err := c.rbacClient.RoleBindings(binding.Namespace).Create(context.TODO(), binding, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return false, fmt.Errorf("rolebinding %s/%s exists but is not converted from a tenant clusterrolebinding", binding.Namespace, binding.Name)
+		}
+		return err == nil, err
+	}
+	if equality.Semantic.DeepEqual(pRoleBinding.Subjects, binding.Subjects) && pRoleBinding.Annotations[constants.LabelUID] == binding.Annotations[constants.LabelUID] {
+		return false, nil
+	}
+	updated := pRoleBinding.DeepCopy()
+	updated.Subjects = binding.Subjects
+	updated.Annotations[constants.LabelUID] = binding.Annotations[constants.LabelUID]
+	_, err := c.rbacClient.RoleBindings(updated.Namespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+	return err == nil, err
+}
+
+func (c *controller) deleteRole(pRole *rbacv1.Role) error {
+	deleteOptions := metav1.NewPreconditionDeleteOptions(string(pRole.UID))
+	err := c.rbacClient.Roles(pRole.Namespace).Delete(context.TODO(), pRole.Name, *deleteOptions)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *controller) deleteRoleBinding(pRoleBinding *rbacv1.RoleBinding) error {
+	deleteOptions := metav1.NewPreconditionDeleteOptions(string(pRoleBinding.UID))
+	err := c.rbacClient.RoleBindings(pRoleBinding.Namespace).Delete(context.TODO(), pRoleBinding.Name, *deleteOptions)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
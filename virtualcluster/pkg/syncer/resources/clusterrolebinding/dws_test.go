@@ -0,0 +1,314 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterrolebinding
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func namespace(name string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+	}
+}
+
+func tenantClusterRole(name string, rules ...rbacv1.PolicyRule) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Rules: rules,
+	}
+}
+
+func tenantClusterRoleBinding(name, uid, clusterRole string, subjects ...rbacv1.Subject) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(uid),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRole,
+		},
+		Subjects: subjects,
+	}
+}
+
+func superRole(name, namespace, uid, clusterKey string, rules ...rbacv1.PolicyRule) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ScopedName(name),
+			Namespace: namespace,
+			UID:       types.UID("role-" + uid),
+			Labels: map[string]string{
+				constants.LabelScopedClusterRBAC: "true",
+			},
+			Annotations: map[string]string{
+				constants.LabelCluster:            clusterKey,
+				constants.LabelClusterRoleBinding: name,
+				constants.LabelUID:                uid,
+			},
+		},
+		Rules: rules,
+	}
+}
+
+func superRoleBinding(name, namespace, uid, clusterKey string, subjects ...rbacv1.Subject) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ScopedName(name),
+			Namespace: namespace,
+			UID:       types.UID("rolebinding-" + uid),
+			Labels: map[string]string{
+				constants.LabelScopedClusterRBAC: "true",
+			},
+			Annotations: map[string]string{
+				constants.LabelCluster:            clusterKey,
+				constants.LabelClusterRoleBinding: name,
+				constants.LabelUID:                uid,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     ScopedName(name),
+		},
+		Subjects: subjects,
+	}
+}
+
+func serviceAccount(namespace, name string) rbacv1.Subject {
+	return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: namespace, Name: name}
+}
+
+func TestDWClusterRoleBindingSync(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+	superNSAName := conversion.ToSuperClusterNamespace(defaultClusterKey, "ns-a")
+
+	podsRule := rbacv1.PolicyRule{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods"}}
+	tenantRules := []rbacv1.PolicyRule{
+		{Verbs: []string{"get", "list", "*"}, APIGroups: []string{""}, Resources: []string{"configmaps", "resourcequotas", "limitranges", "*"}},
+		{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}},
+		{Verbs: []string{"get", "escalate", "bind"}, APIGroups: []string{rbacv1.GroupName}, Resources: []string{"roles"}},
+		{Verbs: []string{"impersonate"}, APIGroups: []string{""}, Resources: []string{"serviceaccounts"}},
+		{Verbs: []string{"*"}, APIGroups: []string{""}, Resources: []string{"secrets"}},
+		{Verbs: []string{"watch", "get"}, APIGroups: []string{"apps", "*"}, Resources: []string{"deployments", "deployments/scale", "*"}},
+	}
+	scopedRules := []rbacv1.PolicyRule{
+		{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"configmaps"}},
+		{Verbs: []string{"get", "watch"}, APIGroups: []string{"apps"}, Resources: []string{"deployments", "deployments/scale"}},
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueueObject          runtime.Object
+		ExpectedActions        []string
+		ExpectedRules          []rbacv1.PolicyRule
+		ExpectedSubjects       []rbacv1.Subject
+	}{
+		"new clusterrolebinding": {
+			ExistingObjectInSuper: []runtime.Object{
+				namespace(superDefaultNSName),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				namespace("default"),
+				namespace("ns-a"),
+				tenantClusterRole("cr", tenantRules...),
+				tenantClusterRoleBinding("crb", "12345", "cr",
+					serviceAccount("default", "sa-1"),
+					serviceAccount("ns-a", "sa-2"),
+					serviceAccount("ghost", "sa-3"),
+					rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+					rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "system:authenticated"},
+				),
+			},
+			EnqueueObject:   tenantClusterRoleBinding("crb", "12345", "cr"),
+			ExpectedActions: []string{"create roles " + superDefaultNSName, "create rolebindings " + superDefaultNSName},
+			ExpectedRules:   scopedRules,
+			ExpectedSubjects: []rbacv1.Subject{
+				serviceAccount(superDefaultNSName, "sa-1"),
+				serviceAccount(superNSAName, "sa-2"),
+			},
+		},
+		"new clusterrolebinding without serviceaccounts": {
+			ExistingObjectInSuper: []runtime.Object{
+				namespace(superDefaultNSName),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				namespace("default"),
+				tenantClusterRole("cr", tenantRules...),
+				tenantClusterRoleBinding("crb", "12345", "cr",
+					rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+				),
+			},
+			EnqueueObject: tenantClusterRoleBinding("crb", "12345", "cr"),
+		},
+		"new clusterrolebinding of nonResourceURLs only": {
+			ExistingObjectInSuper: []runtime.Object{
+				namespace(superDefaultNSName),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				namespace("default"),
+				tenantClusterRole("cr", rbacv1.PolicyRule{Verbs: []string{"get"}, NonResourceURLs: []string{"*"}}),
+				tenantClusterRoleBinding("crb", "12345", "cr", serviceAccount("default", "sa-1")),
+			},
+			EnqueueObject: tenantClusterRoleBinding("crb", "12345", "cr"),
+		},
+		"clusterrolebinding already synced": {
+			ExistingObjectInSuper: []runtime.Object{
+				namespace(superDefaultNSName),
+				superRole("crb", superDefaultNSName, "12345", defaultClusterKey, podsRule),
+				superRoleBinding("crb", superDefaultNSName, "12345", defaultClusterKey, serviceAccount(superDefaultNSName, "sa-1")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				namespace("default"),
+				tenantClusterRole("cr", podsRule),
+				tenantClusterRoleBinding("crb", "12345", "cr", serviceAccount("default", "sa-1")),
+			},
+			EnqueueObject: tenantClusterRoleBinding("crb", "12345", "cr"),
+		},
+		"clusterrole updated": {
+			ExistingObjectInSuper: []runtime.Object{
+				namespace(superDefaultNSName),
+				superRole("crb", superDefaultNSName, "12345", defaultClusterKey, podsRule),
+				superRoleBinding("crb", superDefaultNSName, "12345", defaultClusterKey, serviceAccount(superDefaultNSName, "sa-1")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				namespace("default"),
+				tenantClusterRole("cr", tenantRules...),
+				tenantClusterRoleBinding("crb", "12345", "cr", serviceAccount("default", "sa-1")),
+			},
+			EnqueueObject:   tenantClusterRoleBinding("crb", "12345", "cr"),
+			ExpectedActions: []string{"update roles " + superDefaultNSName},
+			ExpectedRules:   scopedRules,
+		},
+		"clusterrolebinding subjects updated": {
+			ExistingObjectInSuper: []runtime.Object{
+				namespace(superDefaultNSName),
+				superRole("crb", superDefaultNSName, "12345", defaultClusterKey, podsRule),
+				superRoleBinding("crb", superDefaultNSName, "12345", defaultClusterKey, serviceAccount(superDefaultNSName, "sa-1")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				namespace("default"),
+				tenantClusterRole("cr", podsRule),
+				tenantClusterRoleBinding("crb", "12345", "cr", serviceAccount("default", "sa-2")),
+			},
+			EnqueueObject:    tenantClusterRoleBinding("crb", "12345", "cr"),
+			ExpectedActions:  []string{"update rolebindings " + superDefaultNSName},
+			ExpectedSubjects: []rbacv1.Subject{serviceAccount(superDefaultNSName, "sa-2")},
+		},
+		"clusterrolebinding deleted": {
+			ExistingObjectInSuper: []runtime.Object{
+				namespace(superDefaultNSName),
+				superRole("crb", superDefaultNSName, "12345", defaultClusterKey, podsRule),
+				superRoleBinding("crb", superDefaultNSName, "12345", defaultClusterKey, serviceAccount(superDefaultNSName, "sa-1")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				namespace("default"),
+			},
+			EnqueueObject:   tenantClusterRoleBinding("crb", "12345", "cr"),
+			ExpectedActions: []string{"delete rolebindings " + superDefaultNSName, "delete roles " + superDefaultNSName},
+		},
+		"clusterrolebinding of other cluster": {
+			ExistingObjectInSuper: []runtime.Object{
+				namespace(superDefaultNSName),
+				superRole("crb", "other-default", "12345", "other", podsRule),
+				superRoleBinding("crb", "other-default", "12345", "other", serviceAccount("other-default", "sa-1")),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				namespace("default"),
+			},
+			EnqueueObject: tenantClusterRoleBinding("crb", "12345", "cr"),
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(NewClusterRoleBindingController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueueObject, nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("%s: expected no error, but got \"%v\"", k, reconcileErr)
+			}
+
+			if len(tc.ExpectedActions) != len(actions) {
+				t.Errorf("%s: Expected actions %v. Actual actions were: %#v", k, tc.ExpectedActions, actions)
+				return
+			}
+			for i, expected := range tc.ExpectedActions {
+				action := actions[i]
+				if got := action.GetVerb() + " " + action.GetResource().Resource + " " + action.GetNamespace(); got != expected {
+					t.Errorf("%s: Expected action %s, got %s", k, expected, got)
+					continue
+				}
+				var obj runtime.Object
+				switch a := action.(type) {
+				case core.CreateAction:
+					obj = a.GetObject()
+				case core.UpdateAction:
+					obj = a.GetObject()
+				}
+				switch o := obj.(type) {
+				case *rbacv1.Role:
+					if !equality.Semantic.DeepEqual(o.Rules, tc.ExpectedRules) {
+						t.Errorf("%s: Expected rules %+v, got %+v", k, tc.ExpectedRules, o.Rules)
+					}
+				case *rbacv1.RoleBinding:
+					if o.RoleRef.Kind != "Role" || o.RoleRef.Name != ScopedName("crb") {
+						t.Errorf("%s: Expected binding to role %s, got %+v", k, ScopedName("crb"), o.RoleRef)
+					}
+					if !equality.Semantic.DeepEqual(o.Subjects, tc.ExpectedSubjects) {
+						t.Errorf("%s: Expected subjects %+v, got %+v", k, tc.ExpectedSubjects, o.Subjects)
+					}
+				}
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -126,6 +127,13 @@ func (c *controller) PatrollerDo() {
 		}
 	}
 	configMapDiffer.DeleteFunc = func(pObj differ.ClusterObject) {
+		clusterName, vNamespace := conversion.GetVirtualOwner(pObj)
+		if !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "configmap", clusterName, vNamespace+"/"+pObj.GetName(), pObj.GetAnnotations()[constants.LabelUID],
+			util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+				return tenantClient.CoreV1().ConfigMaps(vNamespace).Get(context.TODO(), pObj.GetName(), metav1.GetOptions{})
+			})) {
+			return
+		}
 		_, pName := conversion.GetConfigMapName(pObj.GetName())
 		deleteOptions := &metav1.DeleteOptions{}
 		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pObj.GetUID()))
@@ -17,6 +17,7 @@ limitations under the License.
 package configmap
 
 import (
+	"sync/atomic"
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
@@ -60,6 +61,7 @@ func TestConfigMapPatrol(t *testing.T) {
 		ExpectedUpdatedPObject []runtime.Object
 		ExpectedUpdatedVObject []runtime.Object
 		ExpectedNoOperation    bool
+		ExpectedMissMatches    uint64
 		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
 		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
 	}{
@@ -125,8 +127,28 @@ func TestConfigMapPatrol(t *testing.T) {
 				applyDataToConfigMap(tenantConfigMap("cm-4", "default", "12345"), "data2"),
 			},
 			ExpectedNoOperation: true,
+			ExpectedMissMatches: 1,
 			// notes: have not updated the different pConfigMap in patrol now.
 		},
+		"pConfigMap exists, vConfigMap exists with the same binary data": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(superConfigMap("cm-6", superDefaultNSName, "12345", defaultClusterKey), "data1"), binaryData1),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(tenantConfigMap("cm-6", "default", "12345"), "data1"), binaryData1),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pConfigMap exists, vConfigMap exists with different binary data": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(superConfigMap("cm-7", superDefaultNSName, "12345", defaultClusterKey), "data1"), binaryData1),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(tenantConfigMap("cm-7", "default", "12345"), "data1"), binaryData2),
+			},
+			ExpectedNoOperation: true,
+			ExpectedMissMatches: 1,
+		},
 		"vConfigMap exists, pConfigMap does not exists": {
 			ExistingObjectInTenant: []runtime.Object{
 				tenantConfigMap("cm-5", "default", "12345"),
@@ -149,12 +171,17 @@ func TestConfigMapPatrol(t *testing.T) {
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
+			missMatches := atomic.LoadUint64(&numMissMatchedConfigMaps)
 			tenantActions, superActions, err := util.RunPatrol(NewConfigMapController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
 			if err != nil {
 				t.Errorf("%s: error running patrol: %v", k, err)
 				return
 			}
 
+			if got := atomic.LoadUint64(&numMissMatchedConfigMaps) - missMatches; got != tc.ExpectedMissMatches {
+				t.Errorf("%s: Expected %d mismatched configmaps, got %d", k, tc.ExpectedMissMatches, got)
+			}
+
 			if tc.ExpectedNoOperation {
 				if len(superActions) != 0 {
 					t.Errorf("%s: Expect no operation, got %v in super cluster", k, superActions)
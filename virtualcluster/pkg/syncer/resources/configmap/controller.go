@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
@@ -65,16 +66,22 @@ func NewConfigMapController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
 
-	c.configMapLister = informer.Core().V1().ConfigMaps().Lister()
-	if options.IsFake {
+	if util.IsUncached(config, "configmap") {
+		c.configMapLister = util.NewUncachedConfigMapLister(client.CoreV1())
 		c.configMapSynced = func() bool { return true }
 	} else {
-		c.configMapSynced = informer.Core().V1().ConfigMaps().Informer().HasSynced
+		c.configMapLister = informer.Core().V1().ConfigMaps().Lister()
+		if options.IsFake {
+			c.configMapSynced = func() bool { return true }
+		} else {
+			util.TrackWatchErrors(informer.Core().V1().ConfigMaps().Informer(), "configmaps")
+			c.configMapSynced = informer.Core().V1().ConfigMaps().Informer().HasSynced
+		}
 	}
 
 	c.Patroller, err = pa.NewPatroller(&corev1.ConfigMap{}, c, pa.WithOptions(options.PatrolOptions))
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -41,7 +42,7 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 
 // The reconcile logic for tenant control plane configMap informer
 func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
-	klog.V(4).Infof("reconcile configmap %s/%s event for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	logsampling.V(4, "configmap").Infof("reconcile configmap %s/%s event for cluster %s", request.Namespace, request.Name, request.ClusterName)
 
 	vName, pName := conversion.GetConfigMapName(request.Name)
 
@@ -65,6 +66,9 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 
 	switch {
 	case vExists && !pExists:
+		if paused := c.MultiClusterController.CreationPausedFor(request.ClusterName); paused > 0 {
+			return reconciler.Result{RequeueAfter: paused}, nil
+		}
 		err := c.reconcileConfigMapCreate(request.ClusterName, pName, targetNamespace, request.UID, vConfigMap)
 		if err != nil {
 			klog.Errorf("failed reconcile configmap %s/%s CREATE of cluster %s %v", request.Namespace, vName, request.ClusterName, err)
@@ -76,6 +80,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 			klog.Errorf("failed reconcile configmap %s/%s DELETE of cluster %s %v", request.Namespace, vName, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
 	case vExists && pExists:
 		err := c.reconcileConfigMapUpdate(request.ClusterName, targetNamespace, request.UID, pConfigMap, vConfigMap)
 		if err != nil {
@@ -89,6 +94,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 }
 
 func (c *controller) reconcileConfigMapCreate(clusterName, targetName, targetNamespace, requestUID string, configMap *corev1.ConfigMap) error {
+	vName := configMap.Name
 	// This supports setting a different name between tenant and super
 	configMap.SetName(targetName)
 
@@ -105,7 +111,11 @@ func (c *controller) reconcileConfigMapCreate(clusterName, targetName, targetNam
 		}
 		return fmt.Errorf("pConfigMap %s/%s exists but its delegated object UID is different", targetNamespace, pConfigMap.Name)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, configMap.Namespace, vName)
+	return nil
 }
 
 func (c *controller) reconcileConfigMapUpdate(clusterName, targetNamespace, requestUID string, pConfigMap, vConfigMap *corev1.ConfigMap) error {
@@ -122,6 +132,7 @@ func (c *controller) reconcileConfigMapUpdate(clusterName, targetNamespace, requ
 		if err != nil {
 			return err
 		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vConfigMap.Namespace, vConfigMap.Name)
 	}
 	return nil
 }
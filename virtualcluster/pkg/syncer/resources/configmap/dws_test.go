@@ -88,6 +88,7 @@ func TestDWConfigMapCreation(t *testing.T) {
 		ExistingObjectInSuper  []runtime.Object
 		ExistingObjectInTenant []runtime.Object
 		ExpectedCreatedPObject []string
+		ExpectedCreatedData    []*corev1.ConfigMap
 		ExpectedNoOperation    bool
 		ExpectedError          string
 	}{
@@ -98,6 +99,16 @@ func TestDWConfigMapCreation(t *testing.T) {
 			},
 			ExpectedCreatedPObject: []string{superDefaultNSName + "/cm-1"},
 		},
+		"new cm with binary data": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(tenantConfigMap("cm-1", "default", "12345"), "data1"), binaryData1),
+			},
+			ExpectedCreatedPObject: []string{superDefaultNSName + "/cm-1"},
+			ExpectedCreatedData: []*corev1.ConfigMap{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(&corev1.ConfigMap{}, "data1"), binaryData1),
+			},
+		},
 		"new root ca cm": {
 			ExistingObjectInSuper: []runtime.Object{},
 			ExistingObjectInTenant: []runtime.Object{
@@ -167,6 +178,12 @@ func TestDWConfigMapCreation(t *testing.T) {
 				if fullName != expectedName {
 					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
 				}
+				if tc.ExpectedCreatedData != nil {
+					expected := tc.ExpectedCreatedData[i]
+					if !equality.Semantic.DeepEqual(created.Data, expected.Data) || !equality.Semantic.DeepEqual(created.BinaryData, expected.BinaryData) {
+						t.Errorf("%s: Expected %s to be created with data %v and binary data %v, got %v and %v", k, expectedName, expected.Data, expected.BinaryData, created.Data, created.BinaryData)
+					}
+				}
 			}
 		})
 	}
@@ -270,6 +287,19 @@ func applyDataToConfigMap(cm *corev1.ConfigMap, data string) *corev1.ConfigMap {
 	return cm
 }
 
+// binaryData1 and binaryData2 are not valid UTF-8, so a round trip through a string would change them.
+var (
+	binaryData1 = []byte{0x00, 0xff, 0xfe, 0x80, 0x0a}
+	binaryData2 = []byte{0x00, 0xff, 0xfe, 0x81, 0x0a}
+)
+
+func applyBinaryDataToConfigMap(cm *corev1.ConfigMap, data []byte) *corev1.ConfigMap {
+	cm.BinaryData = map[string][]byte{
+		"binary": append([]byte(nil), data...),
+	}
+	return cm
+}
+
 func TestDWConfigMapUpdate(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -316,6 +346,26 @@ func TestDWConfigMapUpdate(t *testing.T) {
 				applyDataToConfigMap(superConfigMap("cm-2", superDefaultNSName, "12345", defaultClusterKey), data2),
 			},
 		},
+		"no diff with binary data": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(superConfigMap("cm-1", superDefaultNSName, "12345", defaultClusterKey), data1), binaryData1),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(tenantConfigMap("cm-1", "default", "12345"), data1), binaryData1),
+			},
+			ExpectedNoOperation: true,
+		},
+		"diff in binary data": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(superConfigMap("cm-2", superDefaultNSName, "12345", defaultClusterKey), data1), binaryData1),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(tenantConfigMap("cm-2", "default", "12345"), data1), binaryData2),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyBinaryDataToConfigMap(applyDataToConfigMap(superConfigMap("cm-2", superDefaultNSName, "12345", defaultClusterKey), data1), binaryData2),
+			},
+		},
 		"diff exists but uid is wrong": {
 			ExistingObjectInSuper: []runtime.Object{
 				applyDataToConfigMap(superConfigMap("cm-3", superDefaultNSName, "12345", defaultClusterKey), data1),
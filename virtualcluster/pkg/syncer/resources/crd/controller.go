@@ -62,7 +62,7 @@ func init() {
 	plugin.SyncerResourceRegister.Register(&plugin.Registration{
 		ID: "crd",
 		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
-			return NewCrdController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+			return NewCrdController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.RestConfig, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
 		},
 		Disable: true,
 	})
@@ -100,6 +100,7 @@ type controller struct {
 
 func NewCrdController(config *config.SyncerConfiguration,
 	client clientset.Interface,
+	restConfig *restclient.Config,
 	informer informers.SharedInformerFactory,
 	vcClient vcclient.Interface,
 	vcInformer vcinformers.VirtualClusterInformer,
@@ -112,24 +113,20 @@ func NewCrdController(config *config.SyncerConfiguration,
 			Config: config,
 		},
 		config:     config,
-		restConfig: config.RestConfig,
+		restConfig: restConfig,
 		crdcache:   nil,
 	}
 
-	if config.RestConfig == nil {
+	if restConfig == nil {
 		return nil, fmt.Errorf("cannot get super control plane restful config")
 	}
-	sc, err = dclient.New(config.RestConfig, dclient.Options{})
+	sc, err = dclient.New(restConfig, dclient.Options{})
 	if err != nil {
 		return nil, err
 	}
 	c.superClient = sc
 
-	if config.RestConfig == nil {
-		return nil, fmt.Errorf("cannot get super control plane restful config")
-	}
-
-	c.crdcache, err = rinformer.New(config.RestConfig, rinformer.Options{})
+	c.crdcache, err = rinformer.New(restConfig, rinformer.Options{})
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +136,7 @@ func NewCrdController(config *config.SyncerConfiguration,
 	}
 
 	c.MultiClusterController, err = mc.NewMCController(&apiextensionsv1.CustomResourceDefinition{}, &apiextensionsv1.CustomResourceDefinitionList{}, c,
-		mc.WithMaxConcurrentReconciles(constants.DwsControllerWorkerLow), mc.WithOptions(options.MCOptions))
+		mc.WithMaxConcurrentReconciles(constants.DwsControllerWorkerLow), mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create crd mc controller: %v", err)
 	}
@@ -152,7 +149,8 @@ func NewCrdController(config *config.SyncerConfiguration,
 		c.vcSynced = vcInformer.Informer().HasSynced
 	}
 
-	c.UpwardController, err = uw.NewUWController(&apiextensionsv1.CustomResourceDefinition{}, c, uw.WithOptions(options.UWOptions))
+	c.UpwardController, err = uw.NewUWController(&apiextensionsv1.CustomResourceDefinition{}, c,
+		uw.WithSyncerConfig(config), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +88,7 @@ func (c *controller) BackPopulate(key string) error {
 			if op == reconciler.AddEvent {
 				// Available in super, hence create a new in tenant control plane
 				vCRD := conversion.BuildVirtualCRD(clusterName, pCRD)
+				c.UpwardController.WaitForTenantWrite()
 				_, err = vcapiextensionsClient.CustomResourceDefinitions().Create(context.TODO(), vCRD, metav1.CreateOptions{})
 				if err != nil {
 					return err
@@ -103,6 +104,7 @@ func (c *controller) BackPopulate(key string) error {
 		opts := &metav1.DeleteOptions{
 			PropagationPolicy: &constants.DefaultDeletionPolicy,
 		}
+		c.UpwardController.WaitForTenantWrite()
 		err = vcapiextensionsClient.CustomResourceDefinitions().Delete(context.TODO(), crdName, *opts)
 		if err != nil {
 			klog.Errorf("cannot delete with err=%v", err)
@@ -111,6 +113,7 @@ func (c *controller) BackPopulate(key string) error {
 	} else {
 		updatedCRD := conversion.Equality(c.Config, nil).CheckCRDEquality(pCRD, vCRD)
 		if updatedCRD != nil {
+			c.UpwardController.WaitForTenantWrite()
 			_, err = vcapiextensionsClient.CustomResourceDefinitions().Update(context.TODO(), updatedCRD, metav1.UpdateOptions{})
 			if err != nil {
 				return err
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+var numSpecMissMatchedDeployments uint64
+var numStatusMissMatchedDeployments uint64
+var numUWMetaMissMatchedDeployments uint64
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.deploymentSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting Deployment checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if deployments keep consistency between super
+// control plane and tenant control planes.
+func (c *controller) PatrollerDo() {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.V(5).Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "deployment")
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	numSpecMissMatchedDeployments = 0
+	numStatusMissMatchedDeployments = 0
+	numUWMetaMissMatchedDeployments = 0
+
+	for _, clusterName := range clusterNames {
+		wg.Add(1)
+		go func(clusterName string) {
+			defer wg.Done()
+			c.checkDeploymentsOfTenantCluster(clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	pDeployments, err := c.deploymentLister.List(util.GetSuperClusterListerLabelsSelector())
+	if err != nil {
+		klog.Errorf("error listing deployments from super control plane informer cache: %v", err)
+		return
+	}
+
+	for _, pDeployment := range pDeployments {
+		clusterName, vNamespace := conversion.GetVirtualOwner(pDeployment)
+		if len(clusterName) == 0 || len(vNamespace) == 0 {
+			continue
+		}
+		shouldDelete := false
+		vDeployment := &appsv1.Deployment{}
+		err := c.MultiClusterController.Get(clusterName, vNamespace, pDeployment.Name, vDeployment)
+		if apierrors.IsNotFound(err) {
+			shouldDelete = true
+		}
+		if err == nil {
+			if pDeployment.Annotations[constants.LabelUID] != string(vDeployment.UID) {
+				shouldDelete = true
+				klog.Warningf("Found pDeployment %s/%s delegated UID is different from tenant object.", pDeployment.Namespace, pDeployment.Name)
+			}
+		}
+		if shouldDelete && !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "deployment", clusterName, vNamespace+"/"+pDeployment.Name, pDeployment.Annotations[constants.LabelUID],
+			util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+				return tenantClient.AppsV1().Deployments(vNamespace).Get(context.TODO(), pDeployment.Name, metav1.GetOptions{})
+			})) {
+			shouldDelete = false
+		}
+		if shouldDelete {
+			deleteOptions := metav1.NewPreconditionDeleteOptions(string(pDeployment.UID))
+			deleteOptions.PropagationPolicy = &constants.DefaultDeletionPolicy
+			if err = c.deploymentClient.Deployments(pDeployment.Namespace).Delete(context.TODO(), pDeployment.Name, *deleteOptions); err != nil {
+				klog.Errorf("error deleting pDeployment %s/%s in super control plane: %v", pDeployment.Namespace, pDeployment.Name, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperControlPlaneDeployments").Inc()
+			}
+		}
+	}
+
+	metrics.CheckerMissMatchStats.WithLabelValues("SpecMissMatchedDeployments").Set(float64(numSpecMissMatchedDeployments))
+	metrics.CheckerMissMatchStats.WithLabelValues("StatusMissMatchedDeployments").Set(float64(numStatusMissMatchedDeployments))
+	metrics.CheckerMissMatchStats.WithLabelValues("UWMetaMissMatchedDeployments").Set(float64(numUWMetaMissMatchedDeployments))
+}
+
+func (c *controller) checkDeploymentsOfTenantCluster(clusterName string) {
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		klog.Errorf("fail to get cluster spec : %s", clusterName)
+		return
+	}
+	if !conversion.SuperClusterWorkloadControllersEnabled(vc) {
+		return
+	}
+
+	deploymentList := &appsv1.DeploymentList{}
+	if err := c.MultiClusterController.List(clusterName, deploymentList); err != nil {
+		klog.Errorf("error listing deployments from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	klog.V(4).Infof("check deployments consistency in cluster %s", clusterName)
+
+	for i, vDeployment := range deploymentList.Items {
+		if mc.IsSyncPaused(&deploymentList.Items[i]) {
+			continue
+		}
+		targetNamespace := conversion.ToSuperClusterNamespace(clusterName, vDeployment.Namespace)
+		pDeployment, err := c.deploymentLister.Deployments(targetNamespace).Get(vDeployment.Name)
+		if apierrors.IsNotFound(err) {
+			if err := c.MultiClusterController.RequeueObject(clusterName, &deploymentList.Items[i]); err != nil {
+				klog.Errorf("error requeue vdeployment %v/%v in cluster %s: %v", vDeployment.Namespace, vDeployment.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantDeployments").Inc()
+			}
+			continue
+		}
+
+		if err != nil {
+			klog.Errorf("failed to get pDeployment %s/%s from super control plane cache: %v", targetNamespace, vDeployment.Name, err)
+			continue
+		}
+
+		if pDeployment.Annotations[constants.LabelUID] != string(vDeployment.UID) {
+			klog.Errorf("Found pDeployment %s/%s delegated UID is different from tenant object.", targetNamespace, pDeployment.Name)
+			continue
+		}
+
+		updatedDeployment := conversion.Equality(c.Config, vc).CheckDeploymentEquality(pDeployment, &deploymentList.Items[i])
+		if updatedDeployment != nil {
+			atomic.AddUint64(&numSpecMissMatchedDeployments, 1)
+			klog.Warningf("spec of deployment %v/%v diff in super&tenant control plane", vDeployment.Namespace, vDeployment.Name)
+			if err := c.MultiClusterController.RequeueObject(clusterName, &deploymentList.Items[i]); err != nil {
+				klog.Errorf("error requeue vdeployment %v/%v in cluster %s: %v", vDeployment.Namespace, vDeployment.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantDeployments").Inc()
+			}
+		}
+
+		enqueue := false
+		updatedMeta := conversion.Equality(c.Config, vc).CheckUWObjectMetaEquality(&pDeployment.ObjectMeta, &deploymentList.Items[i].ObjectMeta)
+		if updatedMeta != nil {
+			atomic.AddUint64(&numUWMetaMissMatchedDeployments, 1)
+			enqueue = true
+			klog.Warningf("UWObjectMeta of vDeployment %v/%v diff in super&tenant control plane", vDeployment.Namespace, vDeployment.Name)
+		}
+		if !equality.Semantic.DeepEqual(vDeployment.Status, tenantDeploymentStatus(c.Config, vc, pDeployment, &deploymentList.Items[i])) {
+			enqueue = true
+			atomic.AddUint64(&numStatusMissMatchedDeployments, 1)
+			klog.Warningf("Status of vDeployment %v/%v diff in super&tenant control plane", vDeployment.Namespace, vDeployment.Name)
+		}
+		if enqueue {
+			c.enqueueDeployment(pDeployment)
+		}
+	}
+}
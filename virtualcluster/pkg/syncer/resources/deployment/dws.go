@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+func (c *controller) StartDWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.deploymentSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting Deployment dws")
+	}
+	return c.MultiClusterController.Start(stopCh)
+}
+
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	logsampling.V(4, "deployment").Infof("reconcile deployment %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
+	pDeployment, err := c.deploymentLister.Deployments(targetNamespace).Get(request.Name)
+	pExists := true
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		pExists = false
+	}
+	vExists := true
+	vDeployment := &appsv1.Deployment{}
+	if err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vDeployment); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		vExists = false
+	}
+
+	var vc *v1alpha1.VirtualCluster
+	if vExists {
+		vc, err = util.GetVirtualClusterObject(c.MultiClusterController, request.ClusterName)
+		if err != nil {
+			return reconciler.Result{Requeue: true}, err
+		}
+		if !conversion.SuperClusterWorkloadControllersEnabled(vc) {
+			// the tenant control plane reconciles its own deployments.
+			return reconciler.Result{}, nil
+		}
+	}
+
+	switch {
+	case vExists && !pExists:
+		err := c.reconcileDeploymentCreate(vc, request.ClusterName, targetNamespace, request.UID, vDeployment)
+		if err != nil {
+			klog.Errorf("failed reconcile deployment %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	case !vExists && pExists:
+		err := c.reconcileDeploymentRemove(targetNamespace, request.UID, request.Name, pDeployment)
+		if err != nil {
+			klog.Errorf("failed reconcile deployment %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
+	case vExists && pExists:
+		err := c.reconcileDeploymentUpdate(vc, request.ClusterName, targetNamespace, request.UID, pDeployment, vDeployment)
+		if err != nil {
+			klog.Errorf("failed reconcile deployment %s/%s UPDATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	default:
+		// object is gone.
+	}
+	return reconciler.Result{}, nil
+}
+
+func (c *controller) reconcileDeploymentCreate(vc *v1alpha1.VirtualCluster, clusterName, targetNamespace, requestUID string, deployment *appsv1.Deployment) error {
+	newObj, err := c.Conversion().BuildSuperClusterObject(clusterName, deployment)
+	if err != nil {
+		return err
+	}
+
+	pDeployment := newObj.(*appsv1.Deployment)
+	if rejected, err := c.checkPodTemplate(vc, clusterName, deployment, &pDeployment.Spec.Template); err != nil || rejected {
+		return err
+	}
+	conversion.MutateWorkloadPodTemplate(c.Config, &pDeployment.Spec.Template)
+
+	pDeployment, err = c.deploymentClient.Deployments(targetNamespace).Create(context.TODO(), pDeployment, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		if pDeployment.Annotations[constants.LabelUID] == requestUID {
+			klog.Infof("deployment %s/%s of cluster %s already exist in super control plane", targetNamespace, pDeployment.Name, clusterName)
+			return nil
+		}
+		return fmt.Errorf("pDeployment %s/%s exists but its delegated object UID is different", targetNamespace, pDeployment.Name)
+	}
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, deployment.Namespace, deployment.Name)
+	return nil
+}
+
+func (c *controller) reconcileDeploymentUpdate(vc *v1alpha1.VirtualCluster, clusterName, targetNamespace, requestUID string, pDeployment, vDeployment *appsv1.Deployment) error {
+	if pDeployment.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("pDeployment %s/%s delegated UID is different from updated object", targetNamespace, pDeployment.Name)
+	}
+
+	// the super deployment keeps its last accepted pod template until the tenant fixes it.
+	vDeployment = vDeployment.DeepCopy()
+	if rejected, err := c.checkPodTemplate(vc, clusterName, vDeployment, &vDeployment.Spec.Template); err != nil || rejected {
+		return err
+	}
+	updated := conversion.Equality(c.Config, vc).CheckDeploymentEquality(pDeployment, vDeployment)
+	if updated != nil {
+		_, err := c.deploymentClient.Deployments(targetNamespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vDeployment.Namespace, vDeployment.Name)
+	}
+	return nil
+}
+
+func (c *controller) reconcileDeploymentRemove(targetNamespace, requestUID, name string, pDeployment *appsv1.Deployment) error {
+	if pDeployment.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("to be deleted pDeployment %s/%s delegated UID is different from deleted object", targetNamespace, name)
+	}
+
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+		Preconditions:     metav1.NewUIDPreconditions(string(pDeployment.UID)),
+	}
+	err := c.deploymentClient.Deployments(targetNamespace).Delete(context.TODO(), name, *opts)
+	if apierrors.IsNotFound(err) {
+		klog.Warningf("To be deleted deployment %s/%s not found in super control plane", targetNamespace, name)
+		return nil
+	}
+	return err
+}
+
+// checkPodTemplate checks and mutates the pod template of the tenant deployment like a tenant pod. It
+// returns true, after recording the reason on the tenant deployment, if the template is rejected.
+func (c *controller) checkPodTemplate(vc *v1alpha1.VirtualCluster, clusterName string, vDeployment *appsv1.Deployment, template *corev1.PodTemplateSpec) (bool, error) {
+	rejection, err := c.templateChecker.CheckTemplate(vc, clusterName, vDeployment.Namespace, vDeployment.Name, template)
+	if err != nil || rejection == nil {
+		return false, err
+	}
+	// Reject the deployment without retrying, the tenant has to change its pod template.
+	klog.Infof("reject deployment %s/%s of cluster %s: %s", vDeployment.Namespace, vDeployment.Name, clusterName, rejection.Message)
+	return true, c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+		Kind:       "Deployment",
+		Name:       vDeployment.Name,
+		Namespace:  vDeployment.Namespace,
+		UID:        vDeployment.UID,
+	}, corev1.EventTypeWarning, rejection.Reason, "%s", rejection.Message)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	pkgerr "github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.deploymentSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+func (c *controller) BackPopulate(key string) error {
+	pNamespace, pName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key %v: %v", key, err))
+		return nil
+	}
+
+	pDeployment, err := c.deploymentLister.Deployments(pNamespace).Get(pName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	clusterName, vNamespace := conversion.GetVirtualOwner(pDeployment)
+	if clusterName == "" || vNamespace == "" {
+		klog.Infof("drop deployment %s/%s which is not belongs to any tenant", pNamespace, pName)
+		return nil
+	}
+
+	vDeployment := &appsv1.Deployment{}
+	if err := c.MultiClusterController.Get(clusterName, vNamespace, pName, vDeployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return pkgerr.Wrapf(err, "could not find pDeployment %s/%s's vDeployment in controller cache", vNamespace, pName)
+	}
+	if pDeployment.Annotations[constants.LabelUID] != string(vDeployment.UID) {
+		return fmt.Errorf("backPopulated pDeployment %s/%s delegated UID is different from updated object", pDeployment.Namespace, pDeployment.Name)
+	}
+
+	if mc.IsSyncPaused(vDeployment) {
+		return nil
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed to create client from cluster %s config", clusterName)
+	}
+
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed to get spec of cluster %s", clusterName)
+	}
+
+	var newDeployment *appsv1.Deployment
+	updatedMeta := conversion.Equality(c.Config, vc).CheckUWObjectMetaEquality(&pDeployment.ObjectMeta, &vDeployment.ObjectMeta)
+	if updatedMeta != nil {
+		newDeployment = vDeployment.DeepCopy()
+		newDeployment.ObjectMeta = *updatedMeta
+		c.UpwardController.WaitForTenantWrite()
+		if _, err = tenantClient.AppsV1().Deployments(vDeployment.Namespace).Update(context.TODO(), newDeployment, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to back populate deployment %s/%s meta update for cluster %s: %v", vDeployment.Namespace, vDeployment.Name, clusterName, err)
+		}
+	}
+
+	status := tenantDeploymentStatus(c.Config, vc, pDeployment, vDeployment)
+	if !equality.Semantic.DeepEqual(vDeployment.Status, status) {
+		if newDeployment == nil {
+			newDeployment = vDeployment.DeepCopy()
+		} else {
+			// vDeployment has been updated, let us fetch the lastest version.
+			if newDeployment, err = tenantClient.AppsV1().Deployments(vDeployment.Namespace).Get(context.TODO(), vDeployment.Name, metav1.GetOptions{}); err != nil {
+				return fmt.Errorf("failed to retrieve vDeployment %s/%s from cluster %s: %v", vDeployment.Namespace, vDeployment.Name, clusterName, err)
+			}
+		}
+		newDeployment.Status = status
+		c.UpwardController.WaitForTenantWrite()
+		if _, err = tenantClient.AppsV1().Deployments(vDeployment.Namespace).UpdateStatus(context.TODO(), newDeployment, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to back populate deployment %s/%s status update for cluster %s: %v", vDeployment.Namespace, vDeployment.Name, clusterName, err)
+		}
+	}
+	return nil
+}
+
+// tenantDeploymentStatus returns the status of the super Deployment as the tenant should see it.
+// The generations of the super and tenant Deployments are unrelated, so the observedGeneration
+// becomes the tenant generation once the super Deployment controller has observed the spec
+// synced from the tenant, and stays unchanged otherwise.
+func tenantDeploymentStatus(syncerConfig *config.SyncerConfiguration, vc *v1alpha1.VirtualCluster, pDeployment, vDeployment *appsv1.Deployment) appsv1.DeploymentStatus {
+	status := pDeployment.Status.DeepCopy()
+	status.ObservedGeneration = vDeployment.Status.ObservedGeneration
+	if pDeployment.Status.ObservedGeneration >= pDeployment.Generation {
+		updated := conversion.Equality(syncerConfig, vc).CheckDeploymentEquality(pDeployment, vDeployment)
+		if updated == nil || equality.Semantic.DeepEqual(updated.Spec, pDeployment.Spec) {
+			status.ObservedGeneration = vDeployment.Generation
+		}
+	}
+	return *status
+}
@@ -89,6 +89,9 @@ func (c *controller) PatrollerDo() {
 
 	d := differ.HandlerFuncs{}
 	d.AddFunc = func(vObj differ.ClusterObject) {
+		if c.isExternalNameService(vObj) {
+			return
+		}
 		atomic.AddUint64(&numMissingEndPoints, 1)
 		if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj); err != nil {
 			klog.Errorf("error requeue vEndpoints %s: %v", vObj.Key, err)
@@ -100,7 +103,7 @@ func (c *controller) PatrollerDo() {
 		v := vObj.Object.(*corev1.Endpoints)
 		p := pObj.Object.(*corev1.Endpoints)
 		updated := conversion.Equality(c.Config, nil).CheckEndpointsEquality(p, v)
-		if updated != nil {
+		if updated != nil && !c.isExternalNameService(vObj) {
 			atomic.AddUint64(&numMissMatchedEndPoints, 1)
 			if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj); err != nil {
 				klog.Errorf("error requeue vEndpoints %s: %v", vObj.Key, err)
@@ -118,3 +121,13 @@ func (c *controller) PatrollerDo() {
 	metrics.CheckerMissMatchStats.WithLabelValues("MissingEndPoints").Set(float64(numMissingEndPoints))
 	metrics.CheckerMissMatchStats.WithLabelValues("MissMatchedEndPoints").Set(float64(numMissMatchedEndPoints))
 }
+
+// isExternalNameService returns true if the tenant endpoints belong to an ExternalName service.
+// The dws does not sync such endpoints, so they are not reported as missing or mismatched.
+func (c *controller) isExternalNameService(vObj differ.ClusterObject) bool {
+	vService := &corev1.Service{}
+	if err := c.MultiClusterController.Get(vObj.OwnerCluster, vObj.GetNamespace(), vObj.GetName(), vService); err != nil {
+		return false
+	}
+	return vService.Spec.Type == corev1.ServiceTypeExternalName
+}
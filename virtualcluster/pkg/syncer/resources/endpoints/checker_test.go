@@ -68,6 +68,13 @@ func TestEndpointsPatrol(t *testing.T) {
 			},
 			ExpectedNoOperation: true,
 		},
+		"vEndpoints of ExternalName service exists, pEndpoints does not exists": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantEndpoints("ep", "default", "12345"),
+				applyExternalNameToService(tenantService("ep", "default", "123456"), "db.example.com"),
+			},
+			ExpectedNoOperation: true,
+		},
 	}
 
 	for k, tc := range testcases {
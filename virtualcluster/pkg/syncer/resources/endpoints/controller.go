@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
@@ -65,7 +66,7 @@ func NewEndpointsController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.Endpoints{}, &corev1.EndpointsList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.Endpoints{}, &corev1.EndpointsList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -74,6 +75,7 @@ func NewEndpointsController(config *config.SyncerConfiguration,
 	if options.IsFake {
 		c.endpointsSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(informer.Core().V1().Endpoints().Informer(), "endpoints")
 		c.endpointsSynced = informer.Core().V1().Endpoints().Informer().HasSynced
 	}
 
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -51,8 +52,12 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 			// Supercontrol plane ep controller handles the service ep lifecycle, quit.
 			return reconciler.Result{}, nil
 		}
+		if vService.Spec.Type == corev1.ServiceTypeExternalName {
+			// ExternalName services resolve to a DNS name and have no endpoints, quit.
+			return reconciler.Result{}, nil
+		}
 	}
-	klog.V(4).Infof("reconcile endpoints %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	logsampling.V(4, "endpoints").Infof("reconcile endpoints %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
 	pEndpoints, err := c.endpointsLister.Endpoints(targetNamespace).Get(request.Name)
 	pExists := true
@@ -84,6 +89,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 			klog.Errorf("failed reconcile endpoints %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
 	case vExists && pExists:
 		err := c.reconcileEndpointsUpdate(request.ClusterName, targetNamespace, request.UID, pEndpoints, vEndpoints)
 		if err != nil {
@@ -112,7 +118,11 @@ func (c *controller) reconcileEndpointsCreate(clusterName, targetNamespace, requ
 		}
 		return fmt.Errorf("pEndpoints %s/%s exists but its delegated object UID is different", targetNamespace, pEndpoints.Name)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, ep.Namespace, ep.Name)
+	return nil
 }
 
 func (c *controller) reconcileEndpointsUpdate(clusterName, targetNamespace, requestUID string, pEP, vEP *corev1.Endpoints) error {
@@ -129,6 +139,7 @@ func (c *controller) reconcileEndpointsUpdate(clusterName, targetNamespace, requ
 		if err != nil {
 			return err
 		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vEP.Namespace, vEP.Name)
 	}
 	return nil
 }
@@ -92,6 +92,12 @@ func applySelectorToService(svc *corev1.Service, key, value string) *corev1.Serv
 	return svc
 }
 
+func applyExternalNameToService(svc *corev1.Service, externalName string) *corev1.Service {
+	svc.Spec.Type = corev1.ServiceTypeExternalName
+	svc.Spec.ExternalName = externalName
+	return svc
+}
+
 func TestDWEndpointsCreation(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -154,6 +160,13 @@ func TestDWEndpointsCreation(t *testing.T) {
 			},
 			ExpectedNoOperation: true,
 		},
+		"new ep related to ExternalName service": {
+			ExistingObjectInTenant: []runtime.Object{
+				tenantEndpoints("svc", "default", "12345"),
+				applyExternalNameToService(tenantService("svc", "default", "123456"), "db.example.com"),
+			},
+			ExpectedNoOperation: true,
+		},
 	}
 
 	for k, tc := range testcases {
@@ -34,6 +34,7 @@ import (
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
@@ -81,21 +82,24 @@ func NewEventController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.Event{}, &corev1.EventList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.Event{}, &corev1.EventList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
 
 	c.nsLister = c.informer.Namespaces().Lister()
 	c.eventLister = c.informer.Events().Lister()
+	util.TrackWatchErrors(c.informer.Namespaces().Informer(), "namespaces")
 	c.nsSynced = c.informer.Namespaces().Informer().HasSynced
+	util.TrackWatchErrors(c.informer.Events().Informer(), "events")
 	c.eventSynced = c.informer.Events().Informer().HasSynced
 	if options.IsFake {
 		c.nsSynced = func() bool { return true }
 		c.eventSynced = func() bool { return true }
 	}
 
-	c.UpwardController, err = uw.NewUWController(&corev1.Event{}, c, uw.WithOptions(options.UWOptions))
+	c.UpwardController, err = uw.NewUWController(&corev1.Event{}, c,
+		uw.WithSyncerConfig(config), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +93,7 @@ func (c *controller) BackPopulate(key string) error {
 
 	if err = c.MultiClusterController.Get(clusterName, tenantNS, vEvent.Name, &corev1.Event{}); err != nil {
 		if apierrors.IsNotFound(err) {
+			c.UpwardController.WaitForTenantWrite()
 			_, err = tenantClient.CoreV1().Events(tenantNS).Create(context.TODO(), vEvent, metav1.CreateOptions{})
 			return err
 		}
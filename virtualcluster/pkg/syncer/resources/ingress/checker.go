@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -33,6 +34,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
 var numSpecMissMatchedIngresses uint64
@@ -93,6 +95,12 @@ func (c *controller) PatrollerDo() {
 				klog.Warningf("Found pIngress %s/%s delegated UID is different from tenant object.", pIngress.Namespace, pIngress.Name)
 			}
 		}
+		if shouldDelete && !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "ingress", clusterName, vNamespace+"/"+pIngress.Name, pIngress.Annotations[constants.LabelUID],
+			util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+				return tenantClient.NetworkingV1().Ingresses(vNamespace).Get(context.TODO(), pIngress.Name, metav1.GetOptions{})
+			})) {
+			shouldDelete = false
+		}
 		if shouldDelete {
 			deleteOptions := metav1.NewPreconditionDeleteOptions(string(pIngress.UID))
 			if err = c.ingressClient.Ingresses(pIngress.Namespace).Delete(context.TODO(), pIngress.Name, *deleteOptions); err != nil {
@@ -117,6 +125,9 @@ func (c *controller) checkIngressesOfTenantCluster(clusterName string) {
 	klog.V(4).Infof("check ingresss consistency in cluster %s", clusterName)
 
 	for i, vIngress := range ingList.Items {
+		if mc.IsSyncPaused(&ingList.Items[i]) {
+			continue
+		}
 		targetNamespace := conversion.ToSuperClusterNamespace(clusterName, vIngress.Namespace)
 		pIngress, err := c.ingressLister.Ingresses(targetNamespace).Get(vIngress.Name)
 		if apierrors.IsNotFound(err) {
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
@@ -71,7 +72,7 @@ func NewIngressController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&networkingv1.Ingress{}, &networkingv1.IngressList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&networkingv1.Ingress{}, &networkingv1.IngressList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -80,10 +81,12 @@ func NewIngressController(config *config.SyncerConfiguration,
 	if options.IsFake {
 		c.ingressSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(informer.Networking().V1().Ingresses().Informer(), "ingresses")
 		c.ingressSynced = informer.Networking().V1().Ingresses().Informer().HasSynced
 	}
 
-	c.UpwardController, err = uw.NewUWController(&networkingv1.Ingress{}, c, uw.WithOptions(options.UWOptions))
+	c.UpwardController, err = uw.NewUWController(&networkingv1.Ingress{}, c,
+		uw.WithSyncerConfig(config), uw.WithObjectGetter(informer.Networking().V1().Ingresses().Informer().GetIndexer()), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -40,7 +41,7 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 }
 
 func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
-	klog.V(4).Infof("reconcile ingress %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	logsampling.V(4, "ingress").Infof("reconcile ingress %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
 	pIngress, err := c.ingressLister.Ingresses(targetNamespace).Get(request.Name)
 	pExists := true
@@ -72,6 +73,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 			klog.Errorf("failed reconcile ingress %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
 	case vExists && pExists:
 		err := c.reconcileIngressUpdate(request.ClusterName, targetNamespace, request.UID, pIngress, vIngress)
 		if err != nil {
@@ -100,7 +102,11 @@ func (c *controller) reconcileIngressCreate(clusterName, targetNamespace, reques
 		}
 		return fmt.Errorf("pIngress %s/%s exists but its delegated object UID is different", targetNamespace, pIngress.Name)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, ingress.Namespace, ingress.Name)
+	return nil
 }
 
 func (c *controller) reconcileIngressUpdate(clusterName, targetNamespace, requestUID string, pIngress, vIngress *networkingv1.Ingress) error {
@@ -118,6 +124,7 @@ func (c *controller) reconcileIngressUpdate(clusterName, targetNamespace, reques
 		if err != nil {
 			return err
 		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vIngress.Namespace, vIngress.Name)
 	}
 	return nil
 }
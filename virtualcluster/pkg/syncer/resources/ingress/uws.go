@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
 // StartUWS starts the upward syncer
@@ -75,6 +76,10 @@ func (c *controller) BackPopulate(key string) error {
 		return fmt.Errorf("backPopulated pIngress %s/%s delegated UID is different from updated object", pIngress.Namespace, pIngress.Name)
 	}
 
+	if mc.IsSyncPaused(vIngress) {
+		return nil
+	}
+
 	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
 	if err != nil {
 		return pkgerr.Wrapf(err, "failed to create client from cluster %s config", clusterName)
@@ -90,6 +95,7 @@ func (c *controller) BackPopulate(key string) error {
 	if updatedMeta != nil {
 		newIngress = vIngress.DeepCopy()
 		newIngress.ObjectMeta = *updatedMeta
+		c.UpwardController.WaitForTenantWrite()
 		if _, err = tenantClient.NetworkingV1().Ingresses(vIngress.Namespace).Update(context.TODO(), newIngress, metav1.UpdateOptions{}); err != nil {
 			return fmt.Errorf("failed to back populate ingress %s/%s meta update for cluster %s: %v", vIngress.Namespace, vIngress.Name, clusterName, err)
 		}
@@ -105,6 +111,7 @@ func (c *controller) BackPopulate(key string) error {
 			}
 		}
 		newIngress.Status = pIngress.Status
+		c.UpwardController.WaitForTenantWrite()
 		if _, err = tenantClient.NetworkingV1().Ingresses(vIngress.Namespace).UpdateStatus(context.TODO(), newIngress, metav1.UpdateOptions{}); err != nil {
 			return fmt.Errorf("failed to back populate ingress %s/%s status update for cluster %s: %v", vIngress.Namespace, vIngress.Name, clusterName, err)
 		}
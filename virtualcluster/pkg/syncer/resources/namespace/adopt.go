@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+// adoptedResource is a kind of object the default syncers sync into a super control plane
+// namespace, whose uid annotation is re-stamped when the namespace is adopted.
+type adoptedResource struct {
+	resource string
+	// list lists the objects of the kind in a namespace of the super control plane or of a tenant.
+	list func(c clientset.Interface, namespace string, opts metav1.ListOptions) (runtime.Object, error)
+	// patch applies a merge patch to an object of the kind.
+	patch func(c clientset.Interface, namespace, name string, data []byte) error
+}
+
+var adoptedResources = []adoptedResource{
+	{
+		resource: "configmaps",
+		list: func(c clientset.Interface, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().ConfigMaps(namespace).List(context.TODO(), opts)
+		},
+		patch: func(c clientset.Interface, namespace, name string, data []byte) error {
+			_, err := c.CoreV1().ConfigMaps(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		},
+	},
+	{
+		resource: "secrets",
+		list: func(c clientset.Interface, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().Secrets(namespace).List(context.TODO(), opts)
+		},
+		patch: func(c clientset.Interface, namespace, name string, data []byte) error {
+			_, err := c.CoreV1().Secrets(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		},
+	},
+	{
+		resource: "serviceaccounts",
+		list: func(c clientset.Interface, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().ServiceAccounts(namespace).List(context.TODO(), opts)
+		},
+		patch: func(c clientset.Interface, namespace, name string, data []byte) error {
+			_, err := c.CoreV1().ServiceAccounts(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		},
+	},
+	{
+		resource: "services",
+		list: func(c clientset.Interface, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().Services(namespace).List(context.TODO(), opts)
+		},
+		patch: func(c clientset.Interface, namespace, name string, data []byte) error {
+			_, err := c.CoreV1().Services(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		},
+	},
+	{
+		resource: "endpoints",
+		list: func(c clientset.Interface, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().Endpoints(namespace).List(context.TODO(), opts)
+		},
+		patch: func(c clientset.Interface, namespace, name string, data []byte) error {
+			_, err := c.CoreV1().Endpoints(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		},
+	},
+	{
+		resource: "persistentvolumeclaims",
+		list: func(c clientset.Interface, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().PersistentVolumeClaims(namespace).List(context.TODO(), opts)
+		},
+		patch: func(c clientset.Interface, namespace, name string, data []byte) error {
+			_, err := c.CoreV1().PersistentVolumeClaims(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		},
+	},
+	{
+		resource: "pods",
+		list: func(c clientset.Interface, namespace string, opts metav1.ListOptions) (runtime.Object, error) {
+			return c.CoreV1().Pods(namespace).List(context.TODO(), opts)
+		},
+		patch: func(c clientset.Interface, namespace, name string, data []byte) error {
+			_, err := c.CoreV1().Pods(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		},
+	},
+}
+
+// listObjects lists all the objects of the resource in the namespace, page by page.
+func (r adoptedResource) listObjects(c clientset.Interface, namespace string) ([]metav1.Object, error) {
+	var objs []metav1.Object
+	opts := metav1.ListOptions{Limit: 500}
+	for {
+		list, err := r.list(c, namespace, opts)
+		if err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			obj, err := meta.Accessor(item)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, obj)
+		}
+		listMeta, err := meta.ListAccessor(list)
+		if err != nil {
+			return nil, err
+		}
+		if listMeta.GetContinue() == "" {
+			return objs, nil
+		}
+		opts.Continue = listMeta.GetContinue()
+	}
+}
+
+// restampObjects re-stamps the uid annotation of the objects synced into the adopted super control
+// plane namespace with the uid of the tenant object of the same name, so that the syncers reconcile
+// them against the tenant objects of the new Virtual Cluster instead of failing on a delegated uid
+// mismatch. Objects the syncers did not create, having no uid annotation, and objects without a
+// tenant counterpart, which the periodic checkers remove, are left as they are.
+func (c *controller) restampObjects(clusterName, targetNamespace, vNamespace string) error {
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create client from cluster %s config: %v", clusterName, err)
+	}
+	for _, r := range adoptedResources {
+		vObjs, err := r.listObjects(tenantClient, vNamespace)
+		if err != nil {
+			return fmt.Errorf("failed to list tenant %s of namespace %s: %v", r.resource, vNamespace, err)
+		}
+		vUIDs := make(map[string]types.UID, len(vObjs))
+		for _, vObj := range vObjs {
+			vUIDs[vObj.GetName()] = vObj.GetUID()
+		}
+		pObjs, err := r.listObjects(c.client, targetNamespace)
+		if err != nil {
+			return fmt.Errorf("failed to list %s of namespace %s: %v", r.resource, targetNamespace, err)
+		}
+		for _, pObj := range pObjs {
+			delegatedUID, synced := pObj.GetAnnotations()[constants.LabelUID]
+			vUID, exists := vUIDs[pObj.GetName()]
+			if !synced || !exists || delegatedUID == string(vUID) {
+				continue
+			}
+			patch, err := json.Marshal(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{constants.LabelUID: string(vUID)},
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if err := r.patch(c.client, targetNamespace, pObj.GetName(), patch); err != nil {
+				return fmt.Errorf("failed to re-stamp %s %s/%s: %v", r.resource, targetNamespace, pObj.GetName(), err)
+			}
+			klog.V(4).Infof("re-stamped %s %s/%s of adopted namespace to tenant uid %s", r.resource, targetNamespace, pObj.GetName(), vUID)
+		}
+	}
+	return nil
+}
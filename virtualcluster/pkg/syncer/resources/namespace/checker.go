@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -134,6 +135,15 @@ func (c *controller) PatrollerDo() {
 		v := vObj.Object.(*corev1.Namespace)
 		p := pObj.Object.(*corev1.Namespace)
 
+		// a namespace of a deleted vc having the cluster key of the current one is left to the dws,
+		// unless it is recreated.
+		if c.Config.OnVCReadoption != constants.OnVCReadoptionRecreate {
+			if readopted, err := c.isReadoption(vObj.GetOwnerCluster(), p); err == nil && readopted {
+				d.OnAdd(vObj)
+				return
+			}
+		}
+
 		// if vc object is deleted, we should reach here
 		if c.shouldBeGarbageCollected(p) || p.Annotations[constants.LabelUID] != string(v.UID) {
 			c.deleteNamespace(p)
@@ -145,6 +155,10 @@ func (c *controller) PatrollerDo() {
 			klog.Errorf("fail to get cluster spec : %s", vObj.GetOwnerCluster())
 			return
 		}
+		// the quota follows changes of the Virtual Cluster, which do not trigger the dws.
+		if err := c.reconcileSuperNamespaceQuota(vc, vObj.GetOwnerCluster(), p.Name); err != nil {
+			klog.Errorf("error reconciling resourcequota of namespace %s: %v", p.Name, err)
+		}
 		updatedNamespace := conversion.Equality(c.Config, vc).CheckNamespaceEquality(p, v)
 		if updatedNamespace != nil {
 			klog.Warningf("metadata of namespace %s diff in super&tenant cluster", pObj.Key)
@@ -161,10 +175,15 @@ func (c *controller) PatrollerDo() {
 			}
 			return
 		}
-		clusterName, _ := conversion.GetVirtualOwner(p)
+		clusterName, vNamespace := conversion.GetVirtualOwner(p)
 		// most possible case. vc is loaded and tenant ns is missing
 		if knownClusterSet.Has(clusterName) {
-			c.deleteNamespace(p)
+			if util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "namespace", clusterName, vNamespace, p.Annotations[constants.LabelUID],
+				util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+					return tenantClient.CoreV1().Namespaces().Get(context.TODO(), vNamespace, metav1.GetOptions{})
+				})) {
+				c.deleteNamespace(p)
+			}
 			return
 		}
 
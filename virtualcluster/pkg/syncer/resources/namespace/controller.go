@@ -17,6 +17,8 @@ limitations under the License.
 package namespace
 
 import (
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
@@ -30,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
@@ -45,15 +48,23 @@ func init() {
 
 type controller struct {
 	manager.BaseResourceSyncer
+	// super control plane client, used to re-stamp the objects of adopted namespaces
+	client clientset.Interface
 	// super control plane namespace client
 	namespaceClient v1core.NamespacesGetter
 	// super control plane namespace lister
 	nsLister listersv1.NamespaceLister
 	nsSynced cache.InformerSynced
+	// super control plane resourcequota client and lister of the provisioned quotas
+	quotaClient v1core.ResourceQuotasGetter
+	quotaLister listersv1.ResourceQuotaLister
+	quotaSynced cache.InformerSynced
 	// super control plane virtual cluster lister
 	vcClient vcclient.Interface
 	vcLister vclisters.VirtualClusterLister
 	vcSynced cache.InformerSynced
+	// super control plane namespaces whose creation fails transiently
+	creationFailures namespaceCreationFailures
 }
 
 func NewNamespaceController(config *config.SyncerConfiguration,
@@ -66,24 +77,36 @@ func NewNamespaceController(config *config.SyncerConfiguration,
 		BaseResourceSyncer: manager.BaseResourceSyncer{
 			Config: config,
 		},
+		client:          client,
 		namespaceClient: client.CoreV1(),
+		quotaClient:     client.CoreV1(),
 		vcClient:        vcClient,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.Namespace{}, &corev1.NamespaceList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.Namespace{}, &corev1.NamespaceList{}, c, mc.WithSyncerConfig(config), mc.WithRetries(config.SyncMaxRetries, retryBaseDelay(config), config.SyncMaxDelay.Duration), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
 
 	c.nsLister = informer.Core().V1().Namespaces().Lister()
 	c.vcLister = vcInformer.Lister()
+	// the resourcequotas are only watched if they are provisioned.
+	c.quotaSynced = func() bool { return true }
+	if config.ProvisionSuperNamespaceQuota {
+		c.quotaLister = informer.Core().V1().ResourceQuotas().Lister()
+	}
 	if options.IsFake {
 		c.nsSynced = func() bool { return true }
 		c.vcSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(informer.Core().V1().Namespaces().Informer(), "namespaces")
 		c.nsSynced = informer.Core().V1().Namespaces().Informer().HasSynced
 		c.vcSynced = vcInformer.Informer().HasSynced
+		if config.ProvisionSuperNamespaceQuota {
+			util.TrackWatchErrors(informer.Core().V1().ResourceQuotas().Informer(), "resourcequotas")
+			c.quotaSynced = informer.Core().V1().ResourceQuotas().Informer().HasSynced
+		}
 	}
 
 	c.Patroller, err = pa.NewPatroller(&corev1.Namespace{}, c, pa.WithOptions(options.PatrolOptions))
@@ -93,3 +116,12 @@ func NewNamespaceController(config *config.SyncerConfiguration,
 
 	return c, nil
 }
+
+// retryBaseDelay returns the delay before the first retry of a failing namespace request, which
+// is NamespaceCreationRetryPeriod when the namespace creations are retried.
+func retryBaseDelay(config *config.SyncerConfiguration) time.Duration {
+	if config.NamespaceCreationMaxRetries > 0 && config.NamespaceCreationRetryPeriod.Duration > 0 {
+		return config.NamespaceCreationRetryPeriod.Duration
+	}
+	return config.SyncBaseDelay.Duration
+}
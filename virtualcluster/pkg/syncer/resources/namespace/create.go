@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+)
+
+const (
+	// NamespaceCreationRetryingReason is the reason of the VirtualCluster condition set while the
+	// creation of some of its super cluster namespaces is retried.
+	NamespaceCreationRetryingReason = "NamespaceCreationRetrying"
+	// NamespaceCreationFailedReason is the reason of the VirtualCluster condition set when the
+	// creation of some of its super cluster namespaces still fails after NamespaceCreationMaxRetries
+	// retries.
+	NamespaceCreationFailedReason = "NamespaceCreationFailed"
+)
+
+// namespaceCreationFailures tracks the super cluster namespaces of each cluster whose creation
+// fails transiently, and the reason of the condition they are reported by.
+type namespaceCreationFailures struct {
+	sync.Mutex
+	clusters map[string]map[string]string
+}
+
+// set records the reason of the failing namespace and returns whether it changed.
+func (f *namespaceCreationFailures) set(clusterName, targetNamespace, reason string) bool {
+	f.Lock()
+	defer f.Unlock()
+	if f.clusters == nil {
+		f.clusters = make(map[string]map[string]string)
+	}
+	if f.clusters[clusterName] == nil {
+		f.clusters[clusterName] = make(map[string]string)
+	}
+	if f.clusters[clusterName][targetNamespace] == reason {
+		return false
+	}
+	f.clusters[clusterName][targetNamespace] = reason
+	return true
+}
+
+// remove forgets the namespace.
+func (f *namespaceCreationFailures) remove(clusterName, targetNamespace string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.clusters[clusterName], targetNamespace)
+	if len(f.clusters[clusterName]) == 0 {
+		delete(f.clusters, clusterName)
+	}
+}
+
+// removeCluster forgets all the namespaces of the cluster.
+func (f *namespaceCreationFailures) removeCluster(clusterName string) {
+	f.Lock()
+	defer f.Unlock()
+	delete(f.clusters, clusterName)
+}
+
+// namespaces returns the sorted failing namespaces of the cluster reported with the reason.
+func (f *namespaceCreationFailures) namespaces(clusterName, reason string) []string {
+	f.Lock()
+	defer f.Unlock()
+	var namespaces []string
+	for namespace, r := range f.clusters[clusterName] {
+		if r == reason {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// createNamespace creates the super cluster namespace once. A failed creation is returned so that
+// the request is retried by the work queue of the controller, whose retries start after
+// NamespaceCreationRetryPeriod. While the creation fails transiently, e.g. due to a timed out
+// admission webhook, the VirtualCluster has a NamespaceCreationRetrying condition naming the
+// namespace, replaced by a NamespaceCreationFailed one once the request has been retried
+// NamespaceCreationMaxRetries times. The conditions are cleared when the namespace is created.
+func (c *controller) createNamespace(clusterName string, requeues int, pNamespace *corev1.Namespace) error {
+	_, err := c.namespaceClient.Namespaces().Create(context.TODO(), pNamespace, metav1.CreateOptions{})
+	maxRetries := c.Config.NamespaceCreationMaxRetries
+	if maxRetries <= 0 {
+		return err
+	}
+	if err != nil && isTransientError(err) {
+		reason := NamespaceCreationRetryingReason
+		if requeues >= maxRetries {
+			reason = NamespaceCreationFailedReason
+		}
+		klog.Warningf("failed to create namespace %s of cluster %s (attempt %d/%d): %v", pNamespace.Name, clusterName, requeues+1, maxRetries+1, err)
+		if c.creationFailures.set(clusterName, pNamespace.Name, reason) {
+			c.updateNamespaceCreationConditions(clusterName)
+		}
+		return err
+	}
+	// the namespace is created, or its creation is rejected and it is no longer retried.
+	c.creationFailures.remove(clusterName, pNamespace.Name)
+	c.updateNamespaceCreationConditions(clusterName)
+	return err
+}
+
+// updateNamespaceCreationConditions sets the conditions of the VirtualCluster of the cluster from
+// the failing namespaces. The cached VirtualCluster is checked first, so that the meta cluster is
+// only written to when the set of failing namespaces changes. Failing to update the VirtualCluster
+// is logged, it does not fail the namespace creation.
+func (c *controller) updateNamespaceCreationConditions(clusterName string) {
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		// the cluster is removed, its namespaces are no longer retried.
+		c.creationFailures.removeCluster(clusterName)
+		return
+	}
+	cached, err := c.vcLister.VirtualClusters(vc.Namespace).Get(vc.Name)
+	if err == nil && !c.setNamespaceCreationConditions(clusterName, cached.DeepCopy()) {
+		return
+	}
+	namespace, name := vc.Namespace, vc.Name
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		vc, err := c.vcClient.TenancyV1alpha1().VirtualClusters(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !c.setNamespaceCreationConditions(clusterName, vc) {
+			return nil
+		}
+		_, err = c.vcClient.TenancyV1alpha1().VirtualClusters(namespace).Update(vc)
+		return err
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.Errorf("failed to set the namespace creation conditions of virtual cluster %s/%s: %v", namespace, name, err)
+	}
+}
+
+// setNamespaceCreationConditions sets both namespace creation conditions of the VirtualCluster
+// from the failing namespaces of the cluster and returns whether they changed.
+func (c *controller) setNamespaceCreationConditions(clusterName string, vc *v1alpha1.VirtualCluster) bool {
+	changed := false
+	for _, reason := range []string{NamespaceCreationRetryingReason, NamespaceCreationFailedReason} {
+		status, message := namespaceCreationCondition(reason, c.creationFailures.namespaces(clusterName, reason))
+		if setNamespaceCreationCondition(vc, reason, status, message) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// namespaceCreationCondition returns the status and message of the condition with the reason given
+// the namespaces it reports.
+func namespaceCreationCondition(reason string, namespaces []string) (corev1.ConditionStatus, string) {
+	if len(namespaces) == 0 {
+		return corev1.ConditionFalse, "No namespace creation is failing."
+	}
+	quoted := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		quoted = append(quoted, fmt.Sprintf("%q", namespace))
+	}
+	if reason == NamespaceCreationFailedReason {
+		return corev1.ConditionTrue, fmt.Sprintf("Creating namespaces %s still fails after all the retries.", strings.Join(quoted, ", "))
+	}
+	return corev1.ConditionTrue, fmt.Sprintf("Creating namespaces %s failed, retrying.", strings.Join(quoted, ", "))
+}
+
+// setNamespaceCreationCondition sets the condition with the reason of the VirtualCluster and
+// returns whether it changed. A VirtualCluster without the condition is not given a False one.
+func setNamespaceCreationCondition(vc *v1alpha1.VirtualCluster, reason string, status corev1.ConditionStatus, message string) bool {
+	for i := range vc.Status.Conditions {
+		condition := &vc.Status.Conditions[i]
+		if condition.Reason != reason {
+			continue
+		}
+		if condition.Status == status && (status != corev1.ConditionTrue || condition.Message == message) {
+			return false
+		}
+		if condition.Status != status {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		condition.Status = status
+		condition.Message = message
+		return true
+	}
+	if status != corev1.ConditionTrue {
+		return false
+	}
+	vc.Status.Conditions = append(vc.Status.Conditions, v1alpha1.ClusterCondition{
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	})
+	return true
+}
+
+// isTransientError returns whether the namespace creation failed for a reason that may go away
+// without any change, e.g. an apiserver or admission webhook timeout.
+func isTransientError(err error) bool {
+	return apierrors.IsInternalError(err) || apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) || apierrors.IsServiceUnavailable(err) ||
+		utilnet.IsConnectionReset(err) || utilnet.IsConnectionRefused(err) || utilnet.IsProbableEOF(err)
+}
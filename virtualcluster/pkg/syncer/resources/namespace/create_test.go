@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func TestDWNamespaceCreationRetry(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	nsResource := schema.GroupResource{Resource: "namespaces"}
+	webhookTimeout := apierrors.NewInternalError(fmt.Errorf("failed calling webhook \"ns.example.com\": context deadline exceeded"))
+
+	testcases := map[string]struct {
+		MaxRetries int
+		FailWith   error
+
+		ExpectedError string
+	}{
+		"created": {
+			MaxRetries: 3,
+		},
+		"transient failure is returned to be retried": {
+			MaxRetries:    3,
+			FailWith:      webhookTimeout,
+			ExpectedError: "context deadline exceeded",
+		},
+		"rejection": {
+			MaxRetries:    3,
+			FailWith:      apierrors.NewForbidden(nsResource, "default", fmt.Errorf("denied by policy")),
+			ExpectedError: "denied by policy",
+		},
+		"conditions disabled": {
+			FailWith:      apierrors.NewServerTimeout(nsResource, "create", 1),
+			ExpectedError: "namespaces",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(func(config *config.SyncerConfiguration,
+				client clientset.Interface,
+				informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface,
+				vcInformer vcinformers.VirtualClusterInformer,
+				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.NamespaceCreationMaxRetries = tc.MaxRetries
+				config.NamespaceCreationRetryPeriod = metav1.Duration{Duration: time.Millisecond}
+				return NewNamespaceController(config, client, informer, vcClient, vcInformer, options)
+			}, testTenant, nil, []runtime.Object{tenantNamespace("default", "12345")}, tenantNamespace("default", "12345"),
+				func(tenantClientset, superClientset *fake.Clientset) {
+					superClientset.PrependReactor("create", "namespaces", func(action core.Action) (bool, runtime.Object, error) {
+						if tc.FailWith != nil {
+							return true, nil, tc.FailWith
+						}
+						return false, nil, nil
+					})
+				})
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else if tc.ExpectedError != "" {
+				t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+			}
+
+			creates := 0
+			for _, action := range actions {
+				if action.Matches("create", "namespaces") {
+					creates++
+				}
+			}
+			if creates != 1 {
+				t.Errorf("%s: expected a single namespace creation, got %d: %#v", k, creates, actions)
+			}
+		})
+	}
+}
+
+func TestSetNamespaceCreationConditions(t *testing.T) {
+	condition := func(reason string, status corev1.ConditionStatus, message string) v1alpha1.ClusterCondition {
+		return v1alpha1.ClusterCondition{Reason: reason, Status: status, Message: message}
+	}
+	type failure struct {
+		namespace string
+		reason    string
+	}
+	testcases := map[string]struct {
+		Conditions []v1alpha1.ClusterCondition
+		Failures   []failure
+		Created    []string
+
+		ExpectedChanged    bool
+		ExpectedConditions []v1alpha1.ClusterCondition
+	}{
+		"no failure": {},
+		"retrying": {
+			Failures:        []failure{{"ns-a", NamespaceCreationRetryingReason}},
+			ExpectedChanged: true,
+			ExpectedConditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionTrue, `Creating namespaces "ns-a" failed, retrying.`),
+			},
+		},
+		"namespaces do not overwrite each other": {
+			Failures: []failure{
+				{"ns-b", NamespaceCreationRetryingReason},
+				{"ns-a", NamespaceCreationRetryingReason},
+				{"ns-c", NamespaceCreationFailedReason},
+			},
+			ExpectedChanged: true,
+			ExpectedConditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionTrue, `Creating namespaces "ns-a", "ns-b" failed, retrying.`),
+				condition(NamespaceCreationFailedReason, corev1.ConditionTrue, `Creating namespaces "ns-c" still fails after all the retries.`),
+			},
+		},
+		"retries exhausted": {
+			Conditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionTrue, `Creating namespaces "ns-a" failed, retrying.`),
+			},
+			Failures:        []failure{{"ns-a", NamespaceCreationRetryingReason}, {"ns-a", NamespaceCreationFailedReason}},
+			ExpectedChanged: true,
+			ExpectedConditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionFalse, "No namespace creation is failing."),
+				condition(NamespaceCreationFailedReason, corev1.ConditionTrue, `Creating namespaces "ns-a" still fails after all the retries.`),
+			},
+		},
+		"unchanged": {
+			Conditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionTrue, `Creating namespaces "ns-a" failed, retrying.`),
+			},
+			Failures: []failure{{"ns-a", NamespaceCreationRetryingReason}},
+			ExpectedConditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionTrue, `Creating namespaces "ns-a" failed, retrying.`),
+			},
+		},
+		"created": {
+			Conditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionTrue, `Creating namespaces "ns-a", "ns-b" failed, retrying.`),
+			},
+			Failures:        []failure{{"ns-a", NamespaceCreationRetryingReason}, {"ns-b", NamespaceCreationRetryingReason}},
+			Created:         []string{"ns-a"},
+			ExpectedChanged: true,
+			ExpectedConditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionTrue, `Creating namespaces "ns-b" failed, retrying.`),
+			},
+		},
+		"all created": {
+			Conditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionTrue, `Creating namespaces "ns-a" failed, retrying.`),
+			},
+			Failures:        []failure{{"ns-a", NamespaceCreationRetryingReason}},
+			Created:         []string{"ns-a"},
+			ExpectedChanged: true,
+			ExpectedConditions: []v1alpha1.ClusterCondition{
+				condition(NamespaceCreationRetryingReason, corev1.ConditionFalse, "No namespace creation is failing."),
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			c := &controller{}
+			for _, f := range tc.Failures {
+				c.creationFailures.set("cluster", f.namespace, f.reason)
+			}
+			for _, namespace := range tc.Created {
+				c.creationFailures.remove("cluster", namespace)
+			}
+			vc := &v1alpha1.VirtualCluster{Status: v1alpha1.VirtualClusterStatus{Conditions: tc.Conditions}}
+			changed := c.setNamespaceCreationConditions("cluster", vc)
+			if changed != tc.ExpectedChanged {
+				t.Errorf("expected changed %v, got %v", tc.ExpectedChanged, changed)
+			}
+			if len(vc.Status.Conditions) != len(tc.ExpectedConditions) {
+				t.Fatalf("expected conditions %+v, got %+v", tc.ExpectedConditions, vc.Status.Conditions)
+			}
+			for i, expected := range tc.ExpectedConditions {
+				got := vc.Status.Conditions[i]
+				if got.Reason != expected.Reason || got.Status != expected.Status || got.Message != expected.Message {
+					t.Errorf("expected condition %+v, got %+v", expected, got)
+				}
+			}
+		})
+	}
+}
@@ -30,11 +30,12 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
 func (c *controller) StartDWS(stopCh <-chan struct{}) error {
-	if !cache.WaitForCacheSync(stopCh, c.nsSynced) {
+	if !cache.WaitForCacheSync(stopCh, c.nsSynced, c.quotaSynced) {
 		return fmt.Errorf("failed to wait for caches to sync")
 	}
 	return c.MultiClusterController.Start(stopCh)
@@ -42,7 +43,7 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 
 // The reconcile logic for tenant control plane namespace informer
 func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
-	klog.V(4).Infof("reconcile namespace %s for cluster %s", request.Name, request.ClusterName)
+	logsampling.V(4, "namespace").Infof("reconcile namespace %s for cluster %s", request.Name, request.ClusterName)
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Name)
 	pNamespace, err := c.nsLister.Get(targetNamespace)
 	pExists := true
@@ -62,41 +63,126 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 	}
 	switch {
 	case vExists && !pExists:
-		err := c.reconcileNamespaceCreate(request.ClusterName, targetNamespace, vNamespace)
+		err := c.reconcileNamespaceCreate(request.ClusterName, targetNamespace, c.MultiClusterController.Queue.NumRequeues(request), vNamespace)
 		if err != nil {
 			klog.Errorf("failed reconcile namespace %s CREATE of cluster %s %v", request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		if err := c.syncSuperNamespaceQuota(request.ClusterName, targetNamespace); err != nil {
+			klog.Errorf("failed reconcile resourcequota of namespace %s of cluster %s %v", request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
 	case !vExists && pExists:
 		err := c.reconcileNamespaceRemove(request.ClusterName, targetNamespace, request.UID, pNamespace)
 		if err != nil {
 			klog.Errorf("failed reconcile namespace %s DELETE of cluster %s %v", request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
 	case vExists && pExists:
-		err := c.reconcileNamespaceUpdate(request.ClusterName, targetNamespace, request.UID, pNamespace, vNamespace)
+		readopted, err := c.isReadoption(request.ClusterName, pNamespace)
+		if err != nil {
+			return reconciler.Result{Requeue: true}, err
+		}
+		if readopted {
+			if err := c.reconcileNamespaceReadoption(request.ClusterName, targetNamespace, pNamespace, vNamespace); err != nil {
+				klog.Errorf("failed reconcile namespace %s READOPTION of cluster %s %v", request.Name, request.ClusterName, err)
+				return reconciler.Result{Requeue: true}, err
+			}
+			return reconciler.Result{}, nil
+		}
+		err = c.reconcileNamespaceUpdate(request.ClusterName, targetNamespace, request.UID, pNamespace, vNamespace)
 		if err != nil {
 			klog.Errorf("failed reconcile namespace %s UPDATE of cluster %s %v", request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		if err := c.syncSuperNamespaceQuota(request.ClusterName, targetNamespace); err != nil {
+			klog.Errorf("failed reconcile resourcequota of namespace %s of cluster %s %v", request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
 	default:
 		// object is gone.
 	}
 	return reconciler.Result{}, nil
 }
 
-func (c *controller) reconcileNamespaceCreate(clusterName, targetNamespace string, vNamespace *corev1.Namespace) error {
+func (c *controller) reconcileNamespaceCreate(clusterName, targetNamespace string, requeues int, vNamespace *corev1.Namespace) error {
 	newObj, err := c.Conversion().BuildSuperClusterNamespace(clusterName, vNamespace)
 	if err != nil {
 		return err
 	}
 
-	_, err = c.namespaceClient.Namespaces().Create(context.TODO(), newObj.(*corev1.Namespace), metav1.CreateOptions{})
+	err = c.createNamespace(clusterName, requeues, newObj.(*corev1.Namespace))
 	if apierrors.IsAlreadyExists(err) {
 		klog.Infof("namespace %s of cluster %s already exist in super control plane", targetNamespace, clusterName)
 		return nil
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, vNamespace.Namespace, vNamespace.Name)
+	return nil
+}
+
+// isReadoption returns whether the super control plane namespace was synced for a deleted
+// Virtual Cluster having the same cluster key as the current one, i.e. its Virtual Cluster uid
+// annotation differs from the uid of the current Virtual Cluster.
+func (c *controller) isReadoption(clusterName string, pNamespace *corev1.Namespace) (bool, error) {
+	vcUID := pNamespace.Annotations[constants.LabelVCUID]
+	if vcUID == "" {
+		return false, nil
+	}
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return false, err
+	}
+	return vcUID != string(vc.UID), nil
+}
+
+// reconcileNamespaceReadoption handles a super control plane namespace synced for a deleted
+// Virtual Cluster according to the OnVCReadoption policy.
+func (c *controller) reconcileNamespaceReadoption(clusterName, targetNamespace string, pNamespace, vNamespace *corev1.Namespace) error {
+	switch c.Config.OnVCReadoption {
+	case constants.OnVCReadoptionConflict:
+		return fmt.Errorf("pNamespace %s belongs to a previous virtual cluster %s", targetNamespace, pNamespace.Annotations[constants.LabelVCUID])
+	case constants.OnVCReadoptionAdopt:
+		newObj, err := c.Conversion().BuildSuperClusterNamespace(clusterName, vNamespace)
+		if err != nil {
+			return err
+		}
+		// the objects are re-stamped first, the namespace is readopted again if it fails.
+		if err := c.restampObjects(clusterName, targetNamespace, vNamespace.Name); err != nil {
+			return err
+		}
+		updatedNamespace := pNamespace.DeepCopy()
+		if updatedNamespace.Annotations == nil {
+			updatedNamespace.Annotations = make(map[string]string)
+		}
+		for _, key := range []string{constants.LabelCluster, constants.LabelUID, constants.LabelNamespace,
+			constants.LabelVCName, constants.LabelVCNamespace, constants.LabelVCUID} {
+			updatedNamespace.Annotations[key] = newObj.GetAnnotations()[key]
+		}
+		klog.Infof("adopting namespace %s of a previous virtual cluster %s for cluster %s", targetNamespace, pNamespace.Annotations[constants.LabelVCUID], clusterName)
+		_, err = c.namespaceClient.Namespaces().Update(context.TODO(), updatedNamespace, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vNamespace.Namespace, vNamespace.Name)
+		return nil
+	case constants.OnVCReadoptionRecreate:
+		klog.Infof("deleting namespace %s of a previous virtual cluster %s for cluster %s", targetNamespace, pNamespace.Annotations[constants.LabelVCUID], clusterName)
+		err := c.namespaceClient.Namespaces().Delete(context.TODO(), targetNamespace, metav1.DeleteOptions{
+			PropagationPolicy: &constants.DefaultDeletionPolicy,
+			Preconditions:     metav1.NewUIDPreconditions(string(pNamespace.UID)),
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		// Requeue to create the namespace once the deletion completes.
+		return fmt.Errorf("pNamespace %s of a previous virtual cluster is being deleted", targetNamespace)
+	default:
+		return fmt.Errorf("unknown on-vc-readoption policy %q for pNamespace %s of a previous virtual cluster", c.Config.OnVCReadoption, targetNamespace)
+	}
 }
 
 func (c *controller) reconcileNamespaceUpdate(clusterName, targetNamespace, requestUID string, pNamespace, vNamespace *corev1.Namespace) error {
@@ -116,11 +202,25 @@ func (c *controller) reconcileNamespaceUpdate(clusterName, targetNamespace, requ
 			if err != nil {
 				return err
 			}
+			c.MultiClusterController.SuperClusterWritten(clusterName, vNamespace.Namespace, vNamespace.Name)
 		}
 	}
 	return nil
 }
 
+// syncSuperNamespaceQuota reconciles the ResourceQuota provisioned in the super control plane
+// namespace, if enabled.
+func (c *controller) syncSuperNamespaceQuota(clusterName, targetNamespace string) error {
+	if !c.Config.ProvisionSuperNamespaceQuota {
+		return nil
+	}
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return err
+	}
+	return c.reconcileSuperNamespaceQuota(vc, clusterName, targetNamespace)
+}
+
 func (c *controller) reconcileNamespaceRemove(clusterName, targetNamespace, requestUID string, pNamespace *corev1.Namespace) error {
 	if pNamespace.Annotations[constants.LabelUID] != requestUID {
 		return fmt.Errorf("to be deleted pNamespace %s delegated UID is different from deleted object", targetNamespace)
@@ -22,14 +22,21 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	core "k8s.io/client-go/testing"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 	utilconst "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
@@ -282,3 +289,157 @@ func TestDWNamespaceDeletion(t *testing.T) {
 		})
 	}
 }
+
+func TestDWNamespaceReadoption(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "d64ea0c0-91f8-46f5-8643-c0cab32ab0cd",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase:            v1alpha1.ClusterRunning,
+			ClusterNamespace: "tenant-1-test",
+		},
+	}
+
+	defaultNSName := "default"
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	defaultSuperNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, defaultNSName)
+
+	superConfigMap := func(name, uid string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: defaultSuperNSName}}
+		if uid != "" {
+			cm.Annotations = map[string]string{constants.LabelUID: uid}
+		}
+		return cm
+	}
+	tenantConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: defaultNSName, UID: "cm-uid"}}
+
+	testcases := map[string]struct {
+		OnVCReadoption         string
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+
+		ExpectedAction    string
+		ExpectedRestamped []string
+		ExpectedError     string
+	}{
+		"unknown policy": {
+			OnVCReadoption: "keep",
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(defaultSuperNSName, "123456", defaultClusterKey),
+			},
+			ExpectedError: "unknown on-vc-readoption policy",
+		},
+		"recreate": {
+			OnVCReadoption: constants.OnVCReadoptionRecreate,
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(defaultSuperNSName, "123456", defaultClusterKey),
+			},
+			ExpectedAction: "delete",
+			ExpectedError:  "is being deleted",
+		},
+		"adopt": {
+			OnVCReadoption: constants.OnVCReadoptionAdopt,
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(defaultSuperNSName, "123456", defaultClusterKey),
+			},
+			ExpectedAction: "update",
+		},
+		"adopt re-stamps the synced objects": {
+			OnVCReadoption: constants.OnVCReadoptionAdopt,
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(defaultSuperNSName, "123456", defaultClusterKey),
+				superConfigMap("cm", "old-cm-uid"),
+				superConfigMap("orphan", "orphan-uid"),
+				superConfigMap("kube-root-ca.crt", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{tenantConfigMap},
+			ExpectedAction:         "update",
+			ExpectedRestamped:      []string{"cm"},
+		},
+		"conflict": {
+			OnVCReadoption: constants.OnVCReadoptionConflict,
+			ExistingObjectInSuper: []runtime.Object{
+				superNamespace(defaultSuperNSName, "123456", defaultClusterKey),
+			},
+			ExpectedError: "belongs to a previous virtual cluster",
+		},
+		"same virtual cluster is not readopted": {
+			OnVCReadoption: constants.OnVCReadoptionAdopt,
+			ExistingObjectInSuper: []runtime.Object{
+				applyAnnotationToNS(superNamespace(defaultSuperNSName, "12345", defaultClusterKey), constants.LabelVCUID, string(testTenant.UID)),
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunDownwardSync(func(config *config.SyncerConfiguration,
+				client clientset.Interface,
+				informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface,
+				vcInformer vcinformers.VirtualClusterInformer,
+				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.OnVCReadoption = tc.OnVCReadoption
+				return NewNamespaceController(config, client, informer, vcClient, vcInformer, options)
+			}, testTenant, tc.ExistingObjectInSuper, append([]runtime.Object{tenantNamespace(defaultNSName, "12345")}, tc.ExistingObjectInTenant...), tenantNamespace(defaultNSName, "12345"), nil)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else if tc.ExpectedError != "" {
+				t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+			}
+
+			// the objects of an adopted namespace are listed, and the synced ones re-stamped, first.
+			var restamped []string
+			var nsActions []core.Action
+			for _, action := range actions {
+				switch {
+				case action.GetVerb() == "list":
+				case action.Matches("patch", "configmaps"):
+					restamped = append(restamped, action.(core.PatchAction).GetName())
+					if !strings.Contains(string(action.(core.PatchAction).GetPatch()), "cm-uid") {
+						t.Errorf("%s: Expected configmap to be re-stamped with cm-uid, got patch %s", k, action.(core.PatchAction).GetPatch())
+					}
+				default:
+					nsActions = append(nsActions, action)
+				}
+			}
+			if !equality.Semantic.DeepEqual(restamped, tc.ExpectedRestamped) {
+				t.Errorf("%s: Expected re-stamped configmaps %v, got %v", k, tc.ExpectedRestamped, restamped)
+			}
+			actions = nsActions
+
+			if tc.ExpectedAction == "" {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expected no action, got %#v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches(tc.ExpectedAction, "namespaces") {
+				t.Errorf("%s: Expected to %s namespace %s. Actual actions were: %#v", k, tc.ExpectedAction, defaultSuperNSName, actions)
+				return
+			}
+			if tc.ExpectedAction == "update" {
+				updated := actions[0].(core.UpdateAction).GetObject().(*corev1.Namespace)
+				if updated.Annotations[constants.LabelVCUID] != string(testTenant.UID) {
+					t.Errorf("%s: Expected vc uid %s, got %s", k, testTenant.UID, updated.Annotations[constants.LabelVCUID])
+				}
+				if updated.Annotations[constants.LabelUID] != "12345" {
+					t.Errorf("%s: Expected tenant uid 12345, got %s", k, updated.Annotations[constants.LabelUID])
+				}
+			}
+		})
+	}
+}
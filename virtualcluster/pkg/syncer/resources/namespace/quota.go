@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+// superNamespaceQuota returns the hard limits of the ResourceQuota provisioned in the super
+// cluster namespaces of the Virtual Cluster, from its annotation or else the syncer config.
+func (c *controller) superNamespaceQuota(vc *v1alpha1.VirtualCluster) (corev1.ResourceList, error) {
+	limits := c.Config.SuperNamespaceQuota
+	if v, ok := vc.GetAnnotations()[constants.LabelSuperNamespaceQuota]; ok {
+		limits = make(map[string]string)
+		for _, item := range strings.Split(v, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			kv := strings.SplitN(item, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid %s annotation item %q, expected resource=quantity", constants.LabelSuperNamespaceQuota, item)
+			}
+			limits[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	hard := make(corev1.ResourceList, len(limits))
+	for name, value := range limits {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q of %s: %v", value, name, err)
+		}
+		hard[corev1.ResourceName(name)] = quantity
+	}
+	return hard, nil
+}
+
+// reconcileSuperNamespaceQuota creates, updates or deletes the ResourceQuota provisioned in the super
+// cluster namespace so that it has the hard limits of the Virtual Cluster. A ResourceQuota of the same
+// name not labelled as managed by the syncer is left alone.
+func (c *controller) reconcileSuperNamespaceQuota(vc *v1alpha1.VirtualCluster, clusterName, targetNamespace string) error {
+	if !c.Config.ProvisionSuperNamespaceQuota {
+		return nil
+	}
+	hard, err := c.superNamespaceQuota(vc)
+	if err != nil {
+		return err
+	}
+
+	pQuota, err := c.quotaLister.ResourceQuotas(targetNamespace).Get(constants.SuperNamespaceQuotaName)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && pQuota.Labels[constants.LabelManagedResourceQuota] != "true" {
+		return fmt.Errorf("resourcequota %s/%s exists but is not managed by the syncer", targetNamespace, constants.SuperNamespaceQuotaName)
+	}
+
+	switch {
+	case pQuota == nil && len(hard) == 0:
+		return nil
+	case pQuota == nil:
+		quota := &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.SuperNamespaceQuotaName,
+				Namespace: targetNamespace,
+				Labels: map[string]string{
+					constants.LabelManagedResourceQuota: "true",
+				},
+				Annotations: map[string]string{
+					constants.LabelCluster: clusterName,
+				},
+			},
+			Spec: corev1.ResourceQuotaSpec{
+				Hard: hard,
+			},
+		}
+		_, err = c.quotaClient.ResourceQuotas(targetNamespace).Create(context.TODO(), quota, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	case len(hard) == 0:
+		klog.Infof("deleting resourcequota %s/%s of cluster %s as no quota is set", targetNamespace, pQuota.Name, clusterName)
+		err = c.quotaClient.ResourceQuotas(targetNamespace).Delete(context.TODO(), pQuota.Name, metav1.DeleteOptions{
+			Preconditions: metav1.NewUIDPreconditions(string(pQuota.UID)),
+		})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	case !equality.Semantic.DeepEqual(pQuota.Spec.Hard, hard):
+		updated := pQuota.DeepCopy()
+		updated.Spec.Hard = hard
+		_, err = c.quotaClient.ResourceQuotas(targetNamespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+)
+
+func superQuota(namespace string, managed bool, hard corev1.ResourceList) *corev1.ResourceQuota {
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.SuperNamespaceQuotaName,
+			Namespace: namespace,
+			UID:       "12345",
+		},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: hard,
+		},
+	}
+	if managed {
+		quota.Labels = map[string]string{constants.LabelManagedResourceQuota: "true"}
+	}
+	return quota
+}
+
+func TestReconcileSuperNamespaceQuota(t *testing.T) {
+	const targetNamespace = "cluster1-default"
+	defaults := map[string]string{"pods": "50", "requests.cpu": "10"}
+	defaultHard := corev1.ResourceList{
+		corev1.ResourcePods:        resource.MustParse("50"),
+		corev1.ResourceRequestsCPU: resource.MustParse("10"),
+	}
+
+	for _, tt := range []struct {
+		name          string
+		annotations   map[string]string
+		existing      *corev1.ResourceQuota
+		expectedHard  corev1.ResourceList
+		expectDeleted bool
+		expectErr     bool
+	}{
+		{
+			name:         "provisioned from the syncer config",
+			expectedHard: defaultHard,
+		},
+		{
+			name:        "provisioned from the virtual cluster annotation",
+			annotations: map[string]string{constants.LabelSuperNamespaceQuota: "pods=10, requests.memory=1Gi"},
+			expectedHard: corev1.ResourceList{
+				corev1.ResourcePods:           resource.MustParse("10"),
+				corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+			},
+		},
+		{
+			name:         "managed quota updated",
+			annotations:  map[string]string{constants.LabelSuperNamespaceQuota: "pods=10"},
+			existing:     superQuota(targetNamespace, true, defaultHard),
+			expectedHard: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")},
+		},
+		{
+			name:         "managed quota unchanged",
+			existing:     superQuota(targetNamespace, true, defaultHard),
+			expectedHard: defaultHard,
+		},
+		{
+			name:          "managed quota deleted",
+			annotations:   map[string]string{constants.LabelSuperNamespaceQuota: ""},
+			existing:      superQuota(targetNamespace, true, defaultHard),
+			expectDeleted: true,
+		},
+		{
+			name:         "unmanaged quota left alone",
+			annotations:  map[string]string{constants.LabelSuperNamespaceQuota: "pods=10"},
+			existing:     superQuota(targetNamespace, false, defaultHard),
+			expectedHard: defaultHard,
+			expectErr:    true,
+		},
+		{
+			name:        "invalid annotation",
+			annotations: map[string]string{constants.LabelSuperNamespaceQuota: "pods"},
+			expectErr:   true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			var existing []runtime.Object
+			if tt.existing != nil {
+				existing = append(existing, tt.existing)
+			}
+			client := fake.NewSimpleClientset(existing...)
+			quotaInformer := informers.NewSharedInformerFactory(client, 0).Core().V1().ResourceQuotas()
+			if tt.existing != nil {
+				if err := quotaInformer.Informer().GetStore().Add(tt.existing); err != nil {
+					tc.Fatalf("unexpected error: %v", err)
+				}
+			}
+			c := &controller{
+				BaseResourceSyncer: manager.BaseResourceSyncer{
+					Config: &config.SyncerConfiguration{ProvisionSuperNamespaceQuota: true, SuperNamespaceQuota: defaults},
+				},
+				quotaClient: client.CoreV1(),
+				quotaLister: quotaInformer.Lister(),
+			}
+			vc := &v1alpha1.VirtualCluster{ObjectMeta: metav1.ObjectMeta{Name: "vc", Namespace: "tenant", Annotations: tt.annotations}}
+
+			err := c.reconcileSuperNamespaceQuota(vc, "cluster1", targetNamespace)
+			if (err != nil) != tt.expectErr {
+				tc.Errorf("expected error %v, got %v", tt.expectErr, err)
+			}
+
+			got, err := client.CoreV1().ResourceQuotas(targetNamespace).Get(context.TODO(), constants.SuperNamespaceQuotaName, metav1.GetOptions{})
+			if tt.expectDeleted || tt.expectedHard == nil {
+				if !apierrors.IsNotFound(err) {
+					tc.Errorf("expected no resourcequota, got %v, %v", got, err)
+				}
+				return
+			}
+			if err != nil {
+				tc.Fatalf("unexpected error: %v", err)
+			}
+			if !equality.Semantic.DeepEqual(got.Spec.Hard, tt.expectedHard) {
+				tc.Errorf("expected hard limits %v, got %v", tt.expectedHard, got.Spec.Hard)
+			}
+			if tt.existing == nil && got.Labels[constants.LabelManagedResourceQuota] != "true" {
+				tc.Errorf("expected the provisioned resourcequota to be labelled as managed, got %v", got.Labels)
+			}
+		})
+	}
+}
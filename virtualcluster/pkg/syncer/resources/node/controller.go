@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/vnode"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/vnode/provider"
@@ -78,7 +79,7 @@ func NewNodeController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.Node{}, &corev1.NodeList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.Node{}, &corev1.NodeList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -87,11 +88,13 @@ func NewNodeController(config *config.SyncerConfiguration,
 	if options.IsFake {
 		c.nodeSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(informer.Core().V1().Nodes().Informer(), "nodes")
 		c.nodeSynced = informer.Core().V1().Nodes().Informer().HasSynced
 	}
 
 	c.UpwardController, err = uw.NewUWController(&corev1.Node{}, c,
-		uw.WithMaxConcurrentReconciles(constants.UwsControllerWorkerHigh), uw.WithOptions(options.UWOptions))
+		uw.WithMaxConcurrentReconciles(constants.UwsControllerWorkerHigh),
+		uw.WithSyncerConfig(config), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
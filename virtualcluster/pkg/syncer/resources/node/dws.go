@@ -19,9 +19,9 @@ package node
 import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -32,7 +32,7 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 // The reconcile logic for tenant control plane node informer, the main purpose is to maintain
 // the nodeNameToCluster mapping
 func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
-	klog.V(4).Infof("reconcile node %s for cluster %s", request.Name, request.ClusterName)
+	logsampling.V(4, "node").Infof("reconcile node %s for cluster %s", request.Name, request.ClusterName)
 	vExists := true
 	vNode := &corev1.Node{}
 	if err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vNode); err != nil {
@@ -119,6 +119,7 @@ func (c *controller) updateClusterNode(clusterName string, node *corev1.Node, wg
 	newVNode.Spec.Taints = provider.GetNodeTaints(c.vnodeProvider, node, metav1.Now())
 	newVNode.ObjectMeta.SetLabels(provider.GetNodeLabels(c.vnodeProvider, node))
 
+	c.UpwardController.WaitForTenantWrite()
 	if err := vnode.UpdateNode(tenantClient.CoreV1().Nodes(), vNode, newVNode); err != nil {
 		klog.Errorf("failed to update node %s/%s's heartbeats: %v", clusterName, node.Name, err)
 	}
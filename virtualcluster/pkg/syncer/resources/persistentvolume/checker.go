@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
 var numClaimMissMatchedPVs uint64
@@ -131,6 +132,13 @@ func (c *controller) PatrollerDo() {
 			klog.Errorf("Removed pv %s in cluster %s is bound to a pvc", vPV.Name, vObj.GetOwnerCluster())
 		}
 
+		// the super cluster is authoritative for the existence of a pv.
+		if !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "persistentvolume", vObj.GetOwnerCluster(), vPV.Name, vPV.Annotations[constants.LabelUID], func() (metav1.Object, error) {
+			return c.client.PersistentVolumes().Get(context.TODO(), vPV.Name, metav1.GetOptions{})
+		}) {
+			return
+		}
+
 		tenantClient, err := c.MultiClusterController.GetClusterClient(vObj.GetOwnerCluster())
 		if err != nil {
 			klog.Errorf("error getting cluster %s clientset: %v", vObj.GetOwnerCluster(), err)
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
@@ -74,7 +75,7 @@ func NewPVController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.PersistentVolume{}, &corev1.PersistentVolumeList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.PersistentVolume{}, &corev1.PersistentVolumeList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -86,11 +87,14 @@ func NewPVController(config *config.SyncerConfiguration,
 		c.pvSynced = func() bool { return true }
 		c.pvcSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(c.informer.PersistentVolumes().Informer(), "persistentvolumes")
 		c.pvSynced = c.informer.PersistentVolumes().Informer().HasSynced
+		util.TrackWatchErrors(c.informer.PersistentVolumeClaims().Informer(), "persistentvolumeclaims")
 		c.pvcSynced = c.informer.PersistentVolumeClaims().Informer().HasSynced
 	}
 
-	c.UpwardController, err = uw.NewUWController(&corev1.PersistentVolume{}, c, uw.WithOptions(options.UWOptions))
+	c.UpwardController, err = uw.NewUWController(&corev1.PersistentVolume{}, c,
+		uw.WithSyncerConfig(config), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
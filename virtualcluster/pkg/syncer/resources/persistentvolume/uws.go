@@ -84,6 +84,7 @@ func (c *controller) BackPopulate(key string) error {
 				return nil
 			}
 			vPV := conversion.BuildVirtualPersistentVolume(pPV, vPVC)
+			c.UpwardController.WaitForTenantWrite()
 			_, err = tenantClient.CoreV1().PersistentVolumes().Create(context.TODO(), vPV, metav1.CreateOptions{})
 			if err != nil {
 				return err
@@ -102,6 +103,7 @@ func (c *controller) BackPopulate(key string) error {
 	if updatedPVSpec != nil {
 		newPV := vPV.DeepCopy()
 		newPV.Spec = *updatedPVSpec
+		c.UpwardController.WaitForTenantWrite()
 		_, err := tenantClient.CoreV1().PersistentVolumes().Update(context.TODO(), newPV, metav1.UpdateOptions{})
 		if err != nil {
 			return err
@@ -24,6 +24,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -118,6 +119,13 @@ func (c *controller) PatrollerDo() {
 		}
 	}
 	d.DeleteFunc = func(pObj differ.ClusterObject) {
+		clusterName, vNamespace := conversion.GetVirtualOwner(pObj)
+		if !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "persistentvolumeclaim", clusterName, vNamespace+"/"+pObj.GetName(), pObj.GetAnnotations()[constants.LabelUID],
+			util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+				return tenantClient.CoreV1().PersistentVolumeClaims(vNamespace).Get(context.TODO(), pObj.GetName(), metav1.GetOptions{})
+			})) {
+			return
+		}
 		deleteOptions := &metav1.DeleteOptions{}
 		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pObj.GetUID()))
 		if err = c.pvcClient.PersistentVolumeClaims(pObj.GetNamespace()).Delete(context.TODO(), pObj.GetName(), *deleteOptions); err != nil {
@@ -29,6 +29,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
@@ -74,7 +75,7 @@ func NewPVCController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.PersistentVolumeClaim{}, &corev1.PersistentVolumeClaimList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.PersistentVolumeClaim{}, &corev1.PersistentVolumeClaimList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -83,10 +84,12 @@ func NewPVCController(config *config.SyncerConfiguration,
 	if options.IsFake {
 		c.pvcSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(informer.Core().V1().PersistentVolumeClaims().Informer(), "persistentvolumeclaims")
 		c.pvcSynced = informer.Core().V1().PersistentVolumeClaims().Informer().HasSynced
 	}
 
-	c.UpwardController, err = uw.NewUWController(&corev1.PersistentVolumeClaim{}, c, uw.WithOptions(options.UWOptions))
+	c.UpwardController, err = uw.NewUWController(&corev1.PersistentVolumeClaim{}, c,
+		uw.WithSyncerConfig(config), uw.WithObjectGetter(c.informer.PersistentVolumeClaims().Informer().GetIndexer()), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -41,7 +42,7 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 
 // The reconcile logic for tenant control plane pvc informer
 func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
-	klog.V(4).Infof("reconcile pvc %s/%s event for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	logsampling.V(4, "persistentvolumeclaim").Infof("reconcile pvc %s/%s event for cluster %s", request.Namespace, request.Name, request.ClusterName)
 
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
 	pPVC, err := c.pvcLister.PersistentVolumeClaims(targetNamespace).Get(request.Name)
@@ -63,6 +64,9 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 	}
 	switch {
 	case vExists && !pExists:
+		if paused := c.MultiClusterController.CreationPausedFor(request.ClusterName); paused > 0 {
+			return reconciler.Result{RequeueAfter: paused}, nil
+		}
 		err := c.reconcilePVCCreate(request.ClusterName, targetNamespace, request.UID, vPVC)
 		if err != nil {
 			klog.Errorf("failed reconcile pvc %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
@@ -74,6 +78,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 			klog.Errorf("failed reconcile pvc %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
 	case vExists && pExists:
 		err := c.reconcilePVCUpdate(request.ClusterName, targetNamespace, request.UID, pPVC, vPVC)
 		if err != nil {
@@ -94,15 +99,25 @@ func (c *controller) reconcilePVCCreate(clusterName, targetNamespace, requestUID
 
 	pPVC := newObj.(*corev1.PersistentVolumeClaim)
 
-	pPVC, err = c.pvcClient.PersistentVolumeClaims(targetNamespace).Create(context.TODO(), pPVC, metav1.CreateOptions{})
+	_, err = c.pvcClient.PersistentVolumeClaims(targetNamespace).Create(context.TODO(), pPVC, metav1.CreateOptions{})
 	if apierrors.IsAlreadyExists(err) {
-		if pPVC.Annotations[constants.LabelUID] == requestUID {
+		// The lister has not seen the pPVC yet, e.g. it has just been created and bound in the super
+		// control plane. Check the one of the super control plane, the update reconciles it later.
+		existing, getErr := c.pvcClient.PersistentVolumeClaims(targetNamespace).Get(context.TODO(), pPVC.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		if existing.Annotations[constants.LabelUID] == requestUID {
 			klog.Infof("pvc %s/%s of cluster %s already exist in super control plane", targetNamespace, pPVC.Name, clusterName)
 			return nil
 		}
 		return fmt.Errorf("pPVC %s/%s exists but its delegated object UID is different", targetNamespace, pPVC.Name)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, pvc.Namespace, pvc.Name)
+	return nil
 }
 
 func (c *controller) reconcilePVCUpdate(clusterName, targetNamespace, requestUID string, pPVC, vPVC *corev1.PersistentVolumeClaim) error {
@@ -119,6 +134,7 @@ func (c *controller) reconcilePVCUpdate(clusterName, targetNamespace, requestUID
 		if err != nil {
 			return err
 		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vPVC.Namespace, vPVC.Name)
 	}
 	return nil
 }
@@ -26,6 +26,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 	"k8s.io/utils/pointer"
 
@@ -158,6 +159,73 @@ func TestDWPVCCreation(t *testing.T) {
 	}
 }
 
+func TestDWPVCCreationNotInLister(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	bound := func(pvc *corev1.PersistentVolumeClaim) *corev1.PersistentVolumeClaim {
+		pvc.Spec.VolumeName = "volume-1"
+		pvc.Status.Phase = corev1.ClaimBound
+		return pvc
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper *corev1.PersistentVolumeClaim
+		ExpectedError         string
+	}{
+		"bound pvc of the tenant pvc": {
+			ExistingObjectInSuper: bound(superPVC("pvc-1", superDefaultNSName, "12345", defaultClusterKey)),
+		},
+		"bound pvc of another uid": {
+			ExistingObjectInSuper: bound(superPVC("pvc-1", superDefaultNSName, "123456", defaultClusterKey)),
+			ExpectedError:         "delegated object UID is different",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			// The pPVC exists in the super control plane but not in the lister yet.
+			addToSuper := func(_, superClientset *fake.Clientset) {
+				if err := superClientset.Tracker().Add(tc.ExistingObjectInSuper); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			tenantObj := tenantPVC("pvc-1", "default", "12345")
+			actions, reconcileErr, err := util.RunDownwardSync(NewPVCController, testTenant, nil, []runtime.Object{tenantObj}, tenantObj, addToSuper)
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else if tc.ExpectedError != "" {
+				t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+			}
+
+			if len(actions) != 2 || !actions[0].Matches("create", "persistentvolumeclaims") || !actions[1].Matches("get", "persistentvolumeclaims") {
+				t.Errorf("%s: expected a create and a get of the pvc, got %#v", k, actions)
+			}
+		})
+	}
+}
+
 func TestDWPVCDeletion(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -289,6 +357,11 @@ func TestDWPVCUpdate(t *testing.T) {
 		VolumeName:       "volume-1",
 	}
 
+	// The storage class and volume name cannot be changed once the pPVC is created.
+	spec3 := spec1.DeepCopy()
+	spec3.StorageClassName = pointer.StringPtr("storage-class-2")
+	spec3.VolumeName = ""
+
 	testcases := map[string]struct {
 		ExistingObjectInSuper  []runtime.Object
 		ExistingObjectInTenant []runtime.Object
@@ -315,6 +388,15 @@ func TestDWPVCUpdate(t *testing.T) {
 				applySpecToPVC(superPVC("pvc-1", superDefaultNSName, "12345", defaultClusterKey), spec2),
 			},
 		},
+		"diff in immutable storage class and volume name": {
+			ExistingObjectInSuper: []runtime.Object{
+				applySpecToPVC(superPVC("pvc-1", superDefaultNSName, "12345", defaultClusterKey), spec1),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applySpecToPVC(tenantPVC("pvc-1", "default", "12345"), spec3),
+			},
+			ExpectedUpdatedPVC: []runtime.Object{},
+		},
 		"diff exists but uid is wrong": {
 			ExistingObjectInSuper: []runtime.Object{
 				applySpecToPVC(superPVC("pvc-1", superDefaultNSName, "12345", defaultClusterKey), spec1),
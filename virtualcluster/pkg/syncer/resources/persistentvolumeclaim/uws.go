@@ -27,6 +27,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
 // StartUWS starts the upward syncer
@@ -70,8 +71,13 @@ func (c *controller) BackPopulate(key string) error {
 		return err
 	}
 
+	if mc.IsSyncPaused(vPVC) {
+		return nil
+	}
+
 	updatedPVC := conversion.Equality(c.Config, nil).CheckUWPVCStatusEquality(pPVC, vPVC)
 	if updatedPVC != nil {
+		c.UpwardController.WaitForTenantWrite()
 		_, err = tenantClient.CoreV1().PersistentVolumeClaims(vNamespace).UpdateStatus(context.TODO(), updatedPVC, metav1.UpdateOptions{})
 		if err != nil {
 			klog.Errorf("failed to update tenant cluster %s pvc %s/%s, %v", clusterName, vNamespace, pName, err)
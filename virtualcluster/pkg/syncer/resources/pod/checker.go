@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -29,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -51,6 +53,7 @@ const (
 var numStatusMissMatchedPods uint64
 var numSpecMissMatchedPods uint64
 var numUWMetaMissMatchedPods uint64
+var numTerminationStuckPods uint64
 
 // StartPatrol starts the period checker for data consistency check. Checker is
 // blocking so should be called via a goroutine.
@@ -145,6 +148,7 @@ func (c *controller) PatrollerDo() {
 	numStatusMissMatchedPods = 0
 	numSpecMissMatchedPods = 0
 	numUWMetaMissMatchedPods = 0
+	numTerminationStuckPods = 0
 
 	pList, err := c.podLister.List(util.GetSuperClusterListerLabelsSelector())
 	if err != nil {
@@ -218,6 +222,7 @@ func (c *controller) PatrollerDo() {
 	metrics.CheckerMissMatchStats.WithLabelValues("StatusMissMatchedPods").Set(float64(numStatusMissMatchedPods))
 	metrics.CheckerMissMatchStats.WithLabelValues("SpecMissMatchedPods").Set(float64(numSpecMissMatchedPods))
 	metrics.CheckerMissMatchStats.WithLabelValues("UWMetaMissMatchedPods").Set(float64(numUWMetaMissMatchedPods))
+	metrics.CheckerMissMatchStats.WithLabelValues("TerminationStuckPods").Set(float64(numTerminationStuckPods))
 
 	for _, clusterName := range clusterNames {
 		wg.Add(1)
@@ -233,6 +238,13 @@ func (c *controller) PatrollerDo() {
 }
 
 func (c *controller) differDeleteFunc(pObj differ.ClusterObject) {
+	clusterName, vNamespace := conversion.GetVirtualOwner(pObj)
+	if !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "pod", clusterName, vNamespace+"/"+pObj.GetName(), pObj.GetAnnotations()[constants.LabelUID],
+		util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+			return tenantClient.CoreV1().Pods(vNamespace).Get(context.TODO(), pObj.GetName(), metav1.GetOptions{})
+		})) {
+		return
+	}
 	c.graceDeletePPod(pObj.Object.(*corev1.Pod))
 }
 
@@ -255,6 +267,10 @@ func (c *controller) differUpdateFunc(vObj differ.ClusterObject, pObj differ.Clu
 		return
 	}
 
+	if pPod.DeletionTimestamp != nil {
+		c.checkTerminationStuck(vObj.GetOwnerCluster(), pPod, vPod)
+	}
+
 	if pPod.Spec.NodeName != "" && vPod.Spec.NodeName != "" && pPod.Spec.NodeName != vPod.Spec.NodeName {
 		// If pPod can be deleted arbitrarily, e.g., evicted by node controller, this inconsistency may happen.
 		// For example, if pPod is deleted just before uws tries to bind the vPod and dws gets a request from checker or
@@ -310,6 +326,66 @@ func (c *controller) differUpdateFunc(vObj differ.ClusterObject, pObj differ.Clu
 	}
 }
 
+// checkTerminationStuck reports a super pod terminating for longer than TerminationStuckTimeout past
+// its grace period, e.g. held by a finalizer or an unresponsive node, with a TerminationStuck condition
+// and event on the tenant pod, which would otherwise hang in terminating as well. The super pod is force
+// deleted if ForceDeleteStuckPods is set.
+func (c *controller) checkTerminationStuck(clusterName string, pPod, vPod *corev1.Pod) {
+	timeout := c.Config.TerminationStuckTimeout.Duration
+	if timeout <= 0 || !time.Now().After(pPod.DeletionTimestamp.Add(timeout)) {
+		return
+	}
+	atomic.AddUint64(&numTerminationStuckPods, 1)
+	klog.Warningf("pPod %s/%s has been terminating for more than %v past its grace period", pPod.Namespace, pPod.Name, timeout)
+
+	if _, condition := getPodCondition(&vPod.Status, constants.PodConditionTerminationStuck); condition == nil {
+		if err := c.reportTerminationStuck(clusterName, pPod, vPod); err != nil {
+			klog.Errorf("error reporting vPod %s/%s in cluster %s stuck terminating: %v", vPod.Namespace, vPod.Name, clusterName, err)
+		}
+	}
+
+	if c.Config.ForceDeleteStuckPods {
+		deleteOptions := metav1.NewDeleteOptions(0)
+		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pPod.UID))
+		err := c.client.Pods(pPod.Namespace).Delete(context.TODO(), pPod.Name, *deleteOptions)
+		if err == nil {
+			metrics.CheckerRemedyStats.WithLabelValues("ForceDeletedStuckSuperControlPlanePods").Inc()
+		} else if !apierrors.IsNotFound(err) {
+			klog.Errorf("error force deleting pPod %s/%s stuck terminating: %v", pPod.Namespace, pPod.Name, err)
+		}
+	}
+}
+
+// reportTerminationStuck adds the TerminationStuck condition to the tenant pod and records an event.
+// The condition is kept by the upward status sync and records that the pod was reported already.
+func (c *controller) reportTerminationStuck(clusterName string, pPod, vPod *corev1.Pod) error {
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return err
+	}
+	message := fmt.Sprintf("the pod has been terminating in the super control plane since %s, past its deletion grace period", pPod.DeletionTimestamp.UTC().Format(time.RFC3339))
+	if c.Config.ForceDeleteStuckPods {
+		message += ", it is force deleted"
+	}
+	newPod := vPod.DeepCopy()
+	newPod.Status.Conditions = append(newPod.Status.Conditions, corev1.PodCondition{
+		Type:               constants.PodConditionTerminationStuck,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             "SuperPodTerminationStuck",
+		Message:            message,
+	})
+	if _, err := tenantClient.CoreV1().Pods(vPod.Namespace).UpdateStatus(context.TODO(), newPod, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	return c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+		Kind:      "Pod",
+		Name:      vPod.Name,
+		Namespace: vPod.Namespace,
+		UID:       vPod.UID,
+	}, corev1.EventTypeWarning, constants.PodConditionTerminationStuck, "%s", message)
+}
+
 func (c *controller) differAddFunc(vObj differ.ClusterObject) {
 	vPod := vObj.Object.(*corev1.Pod)
 
@@ -320,22 +396,41 @@ func (c *controller) differAddFunc(vObj differ.ClusterObject) {
 		return
 	}
 	// pPod not found and vPod still exists, the pPod may be deleted manually or by controller pod eviction.
-	// If the vPod has not been bound yet, we can create pPod again.
+	// If the vPod has not been bound yet, we can create pPod again, unless super cluster deletions are propagated.
 	// If the vPod has been bound, we'd better delete the vPod since the new pPod may have a different nodename.
-	if isPodScheduled(vPod) {
+	scheduled := isPodScheduled(vPod)
+	if scheduled || (c.Config.OnSuperObjectDeleted == constants.OnSuperObjectDeletedPropagate && isPodBackPopulated(vPod)) {
 		// double check pPod exist or not.
 		targetNamespace := conversion.ToSuperClusterNamespace(vObj.OwnerCluster, vPod.Namespace)
 		if _, err := c.podLister.Pods(targetNamespace).Get(vPod.Name); err == nil || !apierrors.IsNotFound(err) {
 			klog.Warningf("pPod %s may exist, should not delete vPod", vObj.Key)
 			return
 		}
+		// the super cluster is authoritative for the existence of a scheduled pod.
+		if !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "pod", vObj.GetOwnerCluster(), targetNamespace+"/"+vPod.Name, "", func() (metav1.Object, error) {
+			return c.client.Pods(targetNamespace).Get(context.TODO(), vPod.Name, metav1.GetOptions{})
+		}) {
+			return
+		}
 		c.forceDeleteVPod(vObj.GetOwnerCluster(), vPod, false)
-		metrics.CheckerRemedyStats.WithLabelValues("DeletedTenantPodsDueToSuperEviction").Inc()
+		if scheduled {
+			metrics.CheckerRemedyStats.WithLabelValues("DeletedTenantPodsDueToSuperEviction").Inc()
+		} else {
+			klog.Infof("pPod of %s was deleted, propagate the deletion to the vPod", vObj.Key)
+			metrics.CheckerRemedyStats.WithLabelValues("DeletedTenantPodsDueToSuperDeletion").Inc()
+		}
 		return
 	}
 	c.requeuePod(vObj.GetOwnerCluster(), vPod)
 }
 
+// isPodBackPopulated returns whether the status of the super pod has been back populated to the
+// tenant pod. No tenant component but the syncer sets the pod conditions, so a tenant pod with
+// conditions had a super pod.
+func isPodBackPopulated(vPod *corev1.Pod) bool {
+	return len(vPod.Status.Conditions) != 0
+}
+
 func (c *controller) forceDeleteVPod(clusterName string, vPod *corev1.Pod, graceful bool) {
 	client, err := c.MultiClusterController.GetClusterClient(clusterName)
 	if err != nil {
@@ -25,9 +25,15 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	core "k8s.io/client-go/testing"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
@@ -76,6 +82,16 @@ func TestPodPatrol(t *testing.T) {
 			},
 		},
 	}
+	statusUnschedulable := &corev1.PodStatus{
+		Phase: corev1.PodPending,
+		Conditions: []corev1.PodCondition{
+			{
+				Type:   "PodScheduled",
+				Status: "False",
+				Reason: "Unschedulable",
+			},
+		},
+	}
 	statusReadyAndRunning := &corev1.PodStatus{
 		Phase: corev1.PodRunning,
 		Conditions: []corev1.PodCondition{
@@ -98,6 +114,7 @@ func TestPodPatrol(t *testing.T) {
 		ExpectedUpdatedPPods   []runtime.Object
 		ExpectedUpdatedVPods   []runtime.Object
 		ExpectedNoOperation    bool
+		OnSuperObjectDeleted   string
 		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
 		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
 	}{
@@ -160,6 +177,62 @@ func TestPodPatrol(t *testing.T) {
 			},
 			WaitDWS: true,
 		},
+		"vPod not scheduled, pPod deleted, recreate": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyStatusToPod(tenantPod("pod-5", "default", "12345"), statusUnschedulable),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			OnSuperObjectDeleted: constants.OnSuperObjectDeletedRecreate,
+			ExpectedCreatedPPods: []string{
+				superDefaultNSName + "/pod-5",
+			},
+			WaitDWS: true,
+		},
+		"vPod not scheduled, pPod deleted, propagate": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyStatusToPod(tenantPod("pod-5", "default", "12345"), statusUnschedulable),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			OnSuperObjectDeleted: constants.OnSuperObjectDeletedPropagate,
+			ExpectedDeletedVPods: []string{
+				"default/pod-5",
+			},
+		},
+		"vPod not synced yet, pPod does not exists, propagate": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPod("pod-5", "default", "12345"),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			OnSuperObjectDeleted: constants.OnSuperObjectDeletedPropagate,
+			ExpectedCreatedPPods: []string{
+				superDefaultNSName + "/pod-5",
+			},
+			WaitDWS: true,
+		},
+		"vPod scheduled without DeletionTimestamp, pPod deleted, recreate": {
+			ExistingObjectInTenant: []runtime.Object{
+				applyStatusToPod(tenantAssignedPod("pod-7", "default", "12345", "n1"), statusReadyAndRunning),
+			},
+			OnSuperObjectDeleted: constants.OnSuperObjectDeletedRecreate,
+			ExpectedDeletedVPods: []string{
+				"default/pod-7",
+			},
+		},
 		"vPod scheduled with DeletionTimestamp, pPod does not exists": {
 			ExistingObjectInTenant: []runtime.Object{
 				applyStatusToPod(applyDeletionTimestampToPod(tenantAssignedPod("pod-6", "default", "12345", "n1"), time.Now(), 30), statusReadyAndRunning),
@@ -221,7 +294,15 @@ func TestPodPatrol(t *testing.T) {
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			tenantActions, superActions, err := util.RunPatrol(NewPodController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
+			tenantActions, superActions, err := util.RunPatrol(func(config *config.SyncerConfiguration,
+				client clientset.Interface,
+				informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface,
+				vcInformer vcinformers.VirtualClusterInformer,
+				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.OnSuperObjectDeleted = tc.OnSuperObjectDeleted
+				return NewPodController(config, client, informer, vcClient, vcInformer, options)
+			}, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
 			if err != nil {
 				t.Errorf("%s: error running patrol: %v", k, err)
 				return
@@ -329,6 +410,148 @@ func TestPodPatrol(t *testing.T) {
 	}
 }
 
+func TestPodPatrolTerminationStuck(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	statusRunning := &corev1.PodStatus{
+		Phase: corev1.PodRunning,
+	}
+	statusTerminationStuck := &corev1.PodStatus{
+		Phase: corev1.PodRunning,
+		Conditions: []corev1.PodCondition{
+			{
+				Type:   constants.PodConditionTerminationStuck,
+				Status: corev1.ConditionTrue,
+				Reason: "SuperPodTerminationStuck",
+			},
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	// The super pod is held, e.g. by a finalizer, since its deletion 20 minutes ago.
+	deletedAt := time.Now().Add(-20 * time.Minute)
+	stuckPPod := func() *corev1.Pod {
+		return applyDeletionTimestampToPod(applyStatusToPod(superAssignedPod("pod", superDefaultNSName, "12345", "n1", defaultClusterKey), statusRunning), deletedAt, 30)
+	}
+	terminatingVPod := func(status *corev1.PodStatus) *corev1.Pod {
+		return applyDeletionTimestampToPod(applyStatusToPod(tenantAssignedPod("pod", "default", "12345", "n1"), status), deletedAt, 30)
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		Timeout                time.Duration
+		ForceDelete            bool
+		ExpectedReported       bool
+		ExpectedForceDeleted   bool
+	}{
+		"stuck": {
+			ExistingObjectInSuper:  []runtime.Object{stuckPPod()},
+			ExistingObjectInTenant: []runtime.Object{terminatingVPod(statusRunning)},
+			Timeout:                10 * time.Minute,
+			ExpectedReported:       true,
+		},
+		"stuck and force deleted": {
+			ExistingObjectInSuper:  []runtime.Object{stuckPPod()},
+			ExistingObjectInTenant: []runtime.Object{terminatingVPod(statusRunning)},
+			Timeout:                10 * time.Minute,
+			ForceDelete:            true,
+			ExpectedReported:       true,
+			ExpectedForceDeleted:   true,
+		},
+		"stuck and reported already": {
+			ExistingObjectInSuper:  []runtime.Object{stuckPPod()},
+			ExistingObjectInTenant: []runtime.Object{terminatingVPod(statusTerminationStuck)},
+			Timeout:                10 * time.Minute,
+		},
+		"terminating within the timeout": {
+			ExistingObjectInSuper:  []runtime.Object{stuckPPod()},
+			ExistingObjectInTenant: []runtime.Object{terminatingVPod(statusRunning)},
+			Timeout:                30 * time.Minute,
+			ForceDelete:            true,
+		},
+		"check disabled": {
+			ExistingObjectInSuper:  []runtime.Object{stuckPPod()},
+			ExistingObjectInTenant: []runtime.Object{terminatingVPod(statusRunning)},
+			Timeout:                0,
+			ForceDelete:            true,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			tenantActions, superActions, err := util.RunPatrol(func(config *config.SyncerConfiguration,
+				client clientset.Interface,
+				informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface,
+				vcInformer vcinformers.VirtualClusterInformer,
+				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.TerminationStuckTimeout = metav1.Duration{Duration: tc.Timeout}
+				config.ForceDeleteStuckPods = tc.ForceDelete
+				return NewPodController(config, client, informer, vcClient, vcInformer, options)
+			}, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, false, false, nil)
+			if err != nil {
+				t.Errorf("%s: error running patrol: %v", k, err)
+				return
+			}
+
+			var reported, recorded bool
+			for _, action := range tenantActions {
+				switch {
+				case action.Matches("update", "pods") && action.GetSubresource() == "status":
+					vPod := action.(core.UpdateAction).GetObject().(*corev1.Pod)
+					_, condition := getPodCondition(&vPod.Status, constants.PodConditionTerminationStuck)
+					if condition == nil || condition.Status != corev1.ConditionTrue {
+						t.Errorf("%s: expected the TerminationStuck condition, got %v", k, vPod.Status.Conditions)
+					}
+					reported = true
+				case action.GetResource().Resource == "events":
+					// A repeated event is correlated to a patch of the recorded one.
+					if createAction, ok := action.(core.CreateAction); ok {
+						event := createAction.GetObject().(*corev1.Event)
+						if event.Reason != constants.PodConditionTerminationStuck || event.InvolvedObject.Name != "pod" {
+							t.Errorf("%s: unexpected event %v", k, event)
+						}
+					}
+					recorded = true
+				default:
+					t.Errorf("%s: unexpected tenant action %v", k, action)
+				}
+			}
+			if reported != tc.ExpectedReported || recorded != tc.ExpectedReported {
+				t.Errorf("%s: expected reported %v, got condition %v and event %v", k, tc.ExpectedReported, reported, recorded)
+			}
+
+			forceDeleted := false
+			for _, action := range superActions {
+				if !action.Matches("delete", "pods") {
+					t.Errorf("%s: unexpected super action %v", k, action)
+					continue
+				}
+				deleteAction := action.(core.DeleteAction)
+				if deleteAction.GetNamespace()+"/"+deleteAction.GetName() != superDefaultNSName+"/pod" {
+					t.Errorf("%s: unexpected deleted pPod %s/%s", k, deleteAction.GetNamespace(), deleteAction.GetName())
+				}
+				forceDeleted = true
+			}
+			if forceDeleted != tc.ExpectedForceDeleted {
+				t.Errorf("%s: expected force deleted %v, got %v", k, tc.ExpectedForceDeleted, forceDeleted)
+			}
+		})
+	}
+}
+
 func TestVNodeGC(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -42,6 +42,7 @@ import (
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/pod/mutatorplugin"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/pod/validationplugin"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/vnode"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/vnode/provider"
@@ -79,7 +80,7 @@ type controller struct {
 	// vnodeProvider manages vnode object.
 	vnodeProvider provider.VirtualNodeProvider
 	plugin        validationplugin.Interface
-	podMutators   []conversion.PodMutator
+	podMutators   []podMutator
 }
 
 type VirtulNodeDeletionPhase string
@@ -114,61 +115,64 @@ func NewPodController(config *config.SyncerConfiguration,
 
 	var err error
 	c.MultiClusterController, err = mc.NewMCController(&corev1.Pod{}, &corev1.PodList{}, c,
-		mc.WithMaxConcurrentReconciles(constants.DwsControllerWorkerHigh), mc.WithOptions(options.MCOptions))
+		mc.WithMaxConcurrentReconciles(constants.DwsControllerWorkerHigh), mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
 
-	// check registered validation plugin
-	rs := validationplugin.ValidationRegister.List()
-	c.plugin = nil
-	for _, r := range rs {
-		if r.ID == validationplugin.QuotaValidationPluginName {
-			quotaplugin, err := r.Init(nil).Instance()
-			if err != nil {
-				klog.Errorf("initialize validation plugin with err %v", err)
-				return nil, err
-			}
-			c.plugin = quotaplugin.(validationplugin.Interface)
-			c.plugin.ContextInit(c.MultiClusterController, options.IsFake)
-		}
+	c.plugin, err = newValidationPlugin(c.MultiClusterController, options.IsFake)
+	if err != nil {
+		return nil, err
 	}
 
-	initContext := &plugin.InitContext{
+	c.podMutators, err = newPodMutators(&plugin.InitContext{
 		Context:    context.Background(),
 		Config:     config,
 		Client:     client,
 		Informer:   informer,
 		VCClient:   vcClient,
 		VCInformer: vcInformer,
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	mutatorList := mutatorplugin.MutatorRegister.List()
-	for _, r := range mutatorList {
-		mutator, err := r.Init(initContext).Instance()
-		if err != nil {
-			klog.Errorf("initialize mutator plugin %s with err %v", r.ID, err)
+	pipeline := make([]string, 0, len(c.podMutators))
+	for _, m := range c.podMutators {
+		if m.disabled {
+			pipeline = append(pipeline, m.id+" (disabled)")
 			continue
 		}
-		mp := mutator.(mutatorplugin.Interface)
-		c.podMutators = append(c.podMutators, mp.Mutator())
+		pipeline = append(pipeline, m.id)
 	}
+	klog.Infof("pod mutation pipeline: %v", pipeline)
 
 	c.serviceLister = c.informer.Services().Lister()
-	c.secretLister = c.informer.Secrets().Lister()
 	c.podLister = c.informer.Pods().Lister()
 	if options.IsFake {
 		c.serviceSynced = func() bool { return true }
-		c.secretSynced = func() bool { return true }
 		c.podSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(c.informer.Services().Informer(), "services")
 		c.serviceSynced = c.informer.Services().Informer().HasSynced
-		c.secretSynced = c.informer.Secrets().Informer().HasSynced
+		util.TrackWatchErrors(c.informer.Pods().Informer(), "pods")
 		c.podSynced = c.informer.Pods().Informer().HasSynced
 	}
+	if util.IsUncached(config, "secret") {
+		c.secretLister = util.NewUncachedSecretLister(client.CoreV1())
+		c.secretSynced = func() bool { return true }
+	} else {
+		c.secretLister = c.informer.Secrets().Lister()
+		if options.IsFake {
+			c.secretSynced = func() bool { return true }
+		} else {
+			util.TrackWatchErrors(c.informer.Secrets().Informer(), "secrets")
+			c.secretSynced = c.informer.Secrets().Informer().HasSynced
+		}
+	}
 
 	c.UpwardController, err = uw.NewUWController(&corev1.Pod{}, c,
-		uw.WithMaxConcurrentReconciles(constants.UwsControllerWorkerHigh), uw.WithOptions(options.UWOptions))
+		uw.WithMaxConcurrentReconciles(constants.UwsControllerWorkerHigh),
+		uw.WithSyncerConfig(config), uw.WithObjectGetter(c.informer.Pods().Informer().GetIndexer()), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -317,3 +321,45 @@ func (c *controller) SetVNodeProvider(provider provider.VirtualNodeProvider) {
 func assignedPod(pod *corev1.Pod) bool {
 	return len(pod.Spec.NodeName) != 0
 }
+
+// newValidationPlugin returns the registered quota validation plugin initialized for the controller,
+// or nil if there is none.
+func newValidationPlugin(mccontroller *mc.MultiClusterController, isFake bool) (validationplugin.Interface, error) {
+	var validation validationplugin.Interface
+	for _, r := range validationplugin.ValidationRegister.List() {
+		if r.ID == validationplugin.QuotaValidationPluginName {
+			quotaplugin, err := r.Init(nil).Instance()
+			if err != nil {
+				klog.Errorf("initialize validation plugin with err %v", err)
+				return nil, err
+			}
+			validation = quotaplugin.(validationplugin.Interface)
+			validation.ContextInit(mccontroller, isFake)
+		}
+	}
+	return validation, nil
+}
+
+// newPodMutators returns the pod mutation pipeline of the registered mutator plugins and the default
+// conversion, ordered and disabled as configured.
+func newPodMutators(initContext *plugin.InitContext) ([]podMutator, error) {
+	syncerConfig := initContext.Config.(*config.SyncerConfiguration)
+	var plugins []podMutator
+	for _, r := range mutatorplugin.MutatorRegister.List() {
+		mutator, err := r.Init(initContext).Instance()
+		if err != nil {
+			klog.Errorf("initialize mutator plugin %s with err %v", r.ID, err)
+			continue
+		}
+		mp := mutator.(mutatorplugin.Interface)
+		plugins = append(plugins, podMutator{id: r.ID, mutator: mp.Mutator()})
+	}
+	podMutators, err := orderPodMutators(syncerConfig.PodMutatorOrder, plugins)
+	if err != nil {
+		return nil, err
+	}
+	if err = disablePodMutators(podMutators, syncerConfig.DisabledPodMutators); err != nil {
+		return nil, err
+	}
+	return podMutators, nil
+}
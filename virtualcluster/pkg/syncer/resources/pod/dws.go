@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	pkgerr "github.com/pkg/errors"
@@ -34,12 +36,16 @@ import (
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	utilconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -51,7 +57,7 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 }
 
 func (c *controller) Reconcile(request reconciler.Request) (res reconciler.Result, retErr error) {
-	klog.V(4).Infof("reconcile pod %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	logsampling.V(4, "pod").Infof("reconcile pod %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
 	reconcilestart := time.Now()
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
 
@@ -80,6 +86,9 @@ func (c *controller) Reconcile(request reconciler.Request) (res reconciler.Resul
 
 	switch {
 	case !reflect.DeepEqual(vPod, &corev1.Pod{}) && pPod == nil:
+		if paused := c.MultiClusterController.CreationPausedFor(request.ClusterName); paused > 0 {
+			return reconciler.Result{RequeueAfter: paused}, nil
+		}
 		operation = "pod_add"
 		err := c.reconcilePodCreate(request.ClusterName, targetNamespace, request.UID, vPod)
 		if err != nil {
@@ -88,12 +97,15 @@ func (c *controller) Reconcile(request reconciler.Request) (res reconciler.Resul
 			if parentRef := getParentRefFromPod(vPod); parentRef != nil {
 				c.MultiClusterController.Eventf(request.ClusterName, parentRef, corev1.EventTypeWarning, "FailedCreate", "Error creating: %v", err)
 			}
-			c.MultiClusterController.Eventf(request.ClusterName, &corev1.ObjectReference{
-				Kind:      "Pod",
-				Name:      vPod.Name,
-				Namespace: vPod.Namespace,
-				UID:       vPod.UID,
-			}, corev1.EventTypeWarning, "FailedCreate", "Error creating: %v", err)
+			// the controller sends the ExceededObjectCountQuota event of the pod for a quota rejection.
+			if !mc.IsObjectCountQuotaError(err) {
+				c.MultiClusterController.Eventf(request.ClusterName, &corev1.ObjectReference{
+					Kind:      "Pod",
+					Name:      vPod.Name,
+					Namespace: vPod.Namespace,
+					UID:       vPod.UID,
+				}, corev1.EventTypeWarning, "FailedCreate", "Error creating: %v", err)
+			}
 
 			return reconciler.Result{Requeue: true}, err
 		}
@@ -104,6 +116,7 @@ func (c *controller) Reconcile(request reconciler.Request) (res reconciler.Resul
 			klog.Errorf("failed reconcile Pod %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
 		if pPod.Spec.NodeName != "" {
 			c.updateClusterVNodePodMap(request.ClusterName, pPod.Spec.NodeName, request.UID, reconciler.DeleteEvent)
 		}
@@ -165,22 +178,246 @@ func getParentRefFromPod(vPod *corev1.Pod) *corev1.ObjectReference {
 	}
 }
 
+// countPodContainers returns the number of regular, init and ephemeral containers of the pod spec.
+func countPodContainers(spec *corev1.PodSpec) int {
+	return len(spec.Containers) + len(spec.InitContainers) + len(spec.EphemeralContainers)
+}
+
+// podCommandBytes returns the total size in bytes of the command, args and env of the regular, init
+// and ephemeral containers of the pod spec.
+func podCommandBytes(spec *corev1.PodSpec) int64 {
+	var size int64
+	addContainer := func(command, args []string, env []corev1.EnvVar) {
+		for _, s := range command {
+			size += int64(len(s))
+		}
+		for _, s := range args {
+			size += int64(len(s))
+		}
+		for _, e := range env {
+			size += int64(len(e.Name) + len(e.Value))
+		}
+	}
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			addContainer(container.Command, container.Args, container.Env)
+		}
+	}
+	for _, container := range spec.EphemeralContainers {
+		addContainer(container.Command, container.Args, container.Env)
+	}
+	return size
+}
+
+// disallowedWindowsRunAsUserNames returns the windowsOptions.runAsUserName values of the pod spec and
+// its regular, init and ephemeral containers that are not in the allowed list. Windows user names are case
+// insensitive.
+func disallowedWindowsRunAsUserNames(spec *corev1.PodSpec, allowed []string) []string {
+	if len(allowed) == 0 {
+		return nil
+	}
+	var names []*string
+	if spec.SecurityContext != nil && spec.SecurityContext.WindowsOptions != nil {
+		names = append(names, spec.SecurityContext.WindowsOptions.RunAsUserName)
+	}
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			if container.SecurityContext != nil && container.SecurityContext.WindowsOptions != nil {
+				names = append(names, container.SecurityContext.WindowsOptions.RunAsUserName)
+			}
+		}
+	}
+	for _, container := range spec.EphemeralContainers {
+		if container.SecurityContext != nil && container.SecurityContext.WindowsOptions != nil {
+			names = append(names, container.SecurityContext.WindowsOptions.RunAsUserName)
+		}
+	}
+
+	var disallowed []string
+	for _, name := range names {
+		if name == nil {
+			continue
+		}
+		isAllowed := false
+		for _, a := range allowed {
+			if strings.EqualFold(*name, a) {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			disallowed = append(disallowed, *name)
+		}
+	}
+	return disallowed
+}
+
+// unsupportedProbes returns the probes of the pod spec that have no handler the syncer knows about.
+// Probes of a type newer than the vendored API, such as grpc, lose their handler when the tenant
+// pod is decoded, and the super control plane rejects a probe without a handler.
+func unsupportedProbes(spec *corev1.PodSpec) []string {
+	var probes []string
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for _, container := range containers {
+			for _, probe := range []struct {
+				name  string
+				probe *corev1.Probe
+			}{
+				{"livenessProbe", container.LivenessProbe},
+				{"readinessProbe", container.ReadinessProbe},
+				{"startupProbe", container.StartupProbe},
+			} {
+				if probe.probe != nil && !hasKnownProbeHandler(probe.probe) {
+					probes = append(probes, fmt.Sprintf("%s of container %s", probe.name, container.Name))
+				}
+			}
+		}
+	}
+	return probes
+}
+
+func hasKnownProbeHandler(probe *corev1.Probe) bool {
+	return probe.Exec != nil || probe.HTTPGet != nil || probe.TCPSocket != nil
+}
+
+// dropUnsupportedProbes removes the probes reported by unsupportedProbes from the pod spec.
+func dropUnsupportedProbes(spec *corev1.PodSpec) {
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			for _, probe := range []**corev1.Probe{&containers[i].LivenessProbe, &containers[i].ReadinessProbe, &containers[i].StartupProbe} {
+				if *probe != nil && !hasKnownProbeHandler(*probe) {
+					*probe = nil
+				}
+			}
+		}
+	}
+}
+
+// allowedHostNamespaces returns the host namespaces the pods of the Virtual Cluster may share, listed
+// by its annotation. No host namespace is allowed by default.
+func allowedHostNamespaces(vc *v1alpha1.VirtualCluster) sets.String {
+	allowed := sets.NewString()
+	for _, v := range strings.Split(vc.GetAnnotations()[constants.LabelAllowedHostNamespaces], ",") {
+		switch v = strings.TrimSpace(v); v {
+		case "":
+		case constants.HostNamespaceIPC, constants.HostNamespacePID:
+			allowed.Insert(v)
+		default:
+			klog.Warningf("ignore unknown host namespace %q in %s annotation of virtual cluster %s/%s", v, constants.LabelAllowedHostNamespaces, vc.Namespace, vc.Name)
+		}
+	}
+	return allowed
+}
+
+// disallowedHostNamespaces returns the host namespaces the pod spec shares that are not allowed.
+func disallowedHostNamespaces(spec *corev1.PodSpec, allowed sets.String) []string {
+	var namespaces []string
+	if spec.HostIPC && !allowed.Has(constants.HostNamespaceIPC) {
+		namespaces = append(namespaces, constants.HostNamespaceIPC)
+	}
+	if spec.HostPID && !allowed.Has(constants.HostNamespacePID) {
+		namespaces = append(namespaces, constants.HostNamespacePID)
+	}
+	return namespaces
+}
+
+// maxContainersPerPod returns the container limit of the pods of the Virtual Cluster. Its annotation
+// takes precedence over the syncer configuration.
+func maxContainersPerPod(syncerConfig *config.SyncerConfiguration, vc *v1alpha1.VirtualCluster) int32 {
+	if v, ok := vc.GetAnnotations()[constants.LabelMaxContainersPerPod]; ok {
+		limit, err := strconv.ParseInt(v, 10, 32)
+		if err == nil && limit >= 0 {
+			return int32(limit)
+		}
+		klog.Warningf("ignore invalid %s annotation %q of virtual cluster %s/%s", constants.LabelMaxContainersPerPod, v, vc.Namespace, vc.Name)
+	}
+	return syncerConfig.MaxContainersPerPod
+}
+
+// maxPodCommandBytes returns the command, args and env size limit of the pods of the Virtual
+// Cluster. Its annotation takes precedence over the syncer configuration.
+func maxPodCommandBytes(syncerConfig *config.SyncerConfiguration, vc *v1alpha1.VirtualCluster) int64 {
+	if v, ok := vc.GetAnnotations()[constants.LabelMaxPodCommandBytes]; ok {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err == nil && limit >= 0 {
+			return limit
+		}
+		klog.Warningf("ignore invalid %s annotation %q of virtual cluster %s/%s", constants.LabelMaxPodCommandBytes, v, vc.Namespace, vc.Name)
+	}
+	return syncerConfig.MaxPodCommandBytes
+}
+
+// Rejection is the reason and message of the warning event rejecting a tenant pod, or the pod
+// template of a tenant workload. The pod is not synced until the tenant changes its spec.
+type Rejection struct {
+	Reason  string
+	Message string
+}
+
+// checkPodSpec returns the rejection of a pod spec by the syncer policies of its Virtual Cluster, or
+// nil if the spec is accepted. subject names the pod or the pod template in the message.
+func checkPodSpec(syncerConfig *config.SyncerConfiguration, vc *v1alpha1.VirtualCluster, subject string, spec *corev1.PodSpec) *Rejection {
+	if spec.NodeName != "" {
+		// For now, we skip vPod that has NodeName set to prevent tenant from deploying DaemonSet or DaemonSet alike CRDs.
+		return &Rejection{Reason: "NotSupported", Message: fmt.Sprintf("The %s has nodeName set in the spec which is not supported for now", subject)}
+	}
+	if rejection := checkContainers(syncerConfig, vc, subject, spec); rejection != nil {
+		return rejection
+	}
+	if namespaces := disallowedHostNamespaces(spec, allowedHostNamespaces(vc)); len(namespaces) > 0 {
+		// sharing host namespaces has to be allowed for the Virtual Cluster.
+		return &Rejection{Reason: "HostNamespaceNotAllowed", Message: fmt.Sprintf("The %s shares host namespaces that are not allowed for this virtual cluster: %s", subject, strings.Join(namespaces, ", "))}
+	}
+	if probes := unsupportedProbes(spec); len(probes) > 0 && syncerConfig.UnsupportedProbePolicy != constants.UnsupportedProbePolicyDrop {
+		// the super control plane would reject the probes.
+		return &Rejection{Reason: "UnsupportedProbe", Message: fmt.Sprintf("The %s has probes of a type not supported by the syncer, such as grpc: %s", subject, strings.Join(probes, ", "))}
+	}
+	return nil
+}
+
+// checkContainers returns the rejection of the containers of a pod spec, including its ephemeral
+// containers, by the syncer policies of its Virtual Cluster, or nil if they are accepted.
+func checkContainers(syncerConfig *config.SyncerConfiguration, vc *v1alpha1.VirtualCluster, subject string, spec *corev1.PodSpec) *Rejection {
+	if count, limit := countPodContainers(spec), maxContainersPerPod(syncerConfig, vc); limit > 0 && count > int(limit) {
+		return &Rejection{Reason: "TooManyContainers", Message: fmt.Sprintf("The %s has %d containers which exceeds the maximum of %d containers per pod", subject, count, limit)}
+	}
+	if size, limit := podCommandBytes(spec), maxPodCommandBytes(syncerConfig, vc); limit > 0 && size > limit {
+		// the tenant has to move the inline configuration elsewhere, e.g. to a configmap.
+		return &Rejection{Reason: "CommandTooLarge", Message: fmt.Sprintf("The %s has %d bytes of container command, args and env which exceeds the maximum of %d bytes per pod", subject, size, limit)}
+	}
+	if names := disallowedWindowsRunAsUserNames(spec, syncerConfig.AllowedWindowsRunAsUserNames); len(names) > 0 {
+		return &Rejection{Reason: "WindowsRunAsUserNameNotAllowed", Message: fmt.Sprintf("The %s runs as Windows users that are not allowed: %s", subject, strings.Join(names, ", "))}
+	}
+	return nil
+}
+
 func (c *controller) reconcilePodCreate(clusterName, targetNamespace, requestUID string, vPod *corev1.Pod) error {
 	// load deleting pod, don't create any pod on super control plane.
 	if vPod.DeletionTimestamp != nil {
 		return nil
 	}
 
-	if vPod.Spec.NodeName != "" {
-		// For now, we skip vPod that has NodeName set to prevent tenant from deploying DaemonSet or DaemonSet alike CRDs.
-		err := c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
-			Kind:      "Pod",
-			Name:      vPod.Name,
-			Namespace: vPod.Namespace,
-			UID:       vPod.UID,
-		}, corev1.EventTypeWarning, "NotSupported", "The Pod has nodeName set in the spec which is not supported for now")
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
 		return err
 	}
+	ref := &corev1.ObjectReference{
+		Kind:      "Pod",
+		Name:      vPod.Name,
+		Namespace: vPod.Namespace,
+		UID:       vPod.UID,
+	}
+	if rejection := checkPodSpec(c.Config, vc, "Pod", &vPod.Spec); rejection != nil {
+		// Reject the pod without retrying, the tenant has to change its spec.
+		klog.Infof("reject pod %s/%s of cluster %s: %s", vPod.Namespace, vPod.Name, clusterName, rejection.Message)
+		return c.MultiClusterController.Eventf(clusterName, ref, corev1.EventTypeWarning, rejection.Reason, "%s", rejection.Message)
+	}
+	if probes := unsupportedProbes(&vPod.Spec); len(probes) > 0 {
+		if err := c.MultiClusterController.Eventf(clusterName, ref, corev1.EventTypeWarning, "UnsupportedProbeDropped",
+			"Probes of a type not supported by the syncer, such as grpc, are not synced: %s", strings.Join(probes, ", ")); err != nil {
+			klog.Warningf("failed to record event for pod %s/%s of cluster %s: %v", vPod.Namespace, vPod.Name, clusterName, err)
+		}
+	}
 
 	newObj, err := c.Conversion().BuildSuperClusterObject(clusterName, vPod)
 	if err != nil {
@@ -188,6 +425,7 @@ func (c *controller) reconcilePodCreate(clusterName, targetNamespace, requestUID
 	}
 
 	pPod := newObj.(*corev1.Pod)
+	dropUnsupportedProbes(&pPod.Spec)
 
 	pSecretMap, err := c.findPodServiceAccountSecret(clusterName, pPod, vPod)
 	if err != nil {
@@ -206,12 +444,18 @@ func (c *controller) reconcilePodCreate(clusterName, targetNamespace, requestUID
 
 	// TODO: Convert PodMutateDefault to a plugin
 	// It is not an easy task as it uses a lot of controller methods now, but could be nice to be generalised.
-	var ms = append(c.podMutators, conversion.PodMutateDefault(vPod, pSecretMap, services, nameServer, c.Config.DNSOptions))
+	ms := c.podMutationPipeline(vc.GetAnnotations(), conversion.PodMutateDefault(vPod, pSecretMap, services, nameServer, c.Config.DNSOptions))
 
 	err = conversion.VC(c.MultiClusterController, clusterName).Pod(pPod, vPod).Mutate(ms...)
 	if err != nil {
 		return fmt.Errorf("failed to mutate pod: %v", err)
 	}
+	// the mutators may add labels too.
+	if err := conversion.ValidateSuperClusterLabels(pPod); err != nil {
+		return err
+	}
+	// ephemeral containers cannot be set at creation, they are added once the pod exists.
+	pPod.Spec.EphemeralContainers = nil
 
 	// Validation plugin processing
 	if c.plugin != nil {
@@ -243,8 +487,12 @@ func (c *controller) reconcilePodCreate(clusterName, targetNamespace, requestUID
 		}
 		return fmt.Errorf("pPod %s/%s exists but the UID is different from tenant control plane", targetNamespace, pPod.Name)
 	}
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, vPod.Namespace, vPod.Name)
 
-	return err
+	return c.reconcilePodEphemeralContainers(vc, clusterName, targetNamespace, pPod, vPod)
 }
 
 func (c *controller) findPodServiceAccountSecret(clusterName string, pPod, vPod *corev1.Pod) (map[string]string, error) {
@@ -253,6 +501,10 @@ func (c *controller) findPodServiceAccountSecret(clusterName string, pPod, vPod
 		if volume.Secret != nil && !pointer.BoolDeref(volume.Secret.Optional, false) {
 			mountSecretSet.Insert(volume.Secret.SecretName)
 		}
+		// the node publish secret of an inline CSI volume is read by the super cluster kubelet.
+		if volume.CSI != nil && volume.CSI.NodePublishSecretRef != nil {
+			mountSecretSet.Insert(volume.CSI.NodePublishSecretRef.Name)
+		}
 	}
 
 	// vSecretName -> pSecretName
@@ -353,7 +605,11 @@ func (c *controller) reconcilePodUpdate(clusterName, targetNamespace, requestUID
 		if apierrors.IsNotFound(err) {
 			return nil
 		}
-		return err
+		if err != nil {
+			return err
+		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vPod.Namespace, vPod.Name)
+		return nil
 	}
 	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
 	if err != nil {
@@ -365,19 +621,127 @@ func (c *controller) reconcilePodUpdate(clusterName, targetNamespace, requestUID
 		if err != nil {
 			return err
 		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vPod.Namespace, vPod.Name)
 	}
 	updatedPodStatus := conversion.CheckDWPodConditionEquality(pPod, vPod)
 	if updatedPodStatus != nil {
 		updatedPod = pPod.DeepCopy()
 		updatedPod.Status = *updatedPodStatus
-		_, err = c.client.Pods(targetNamespace).UpdateStatus(context.TODO(), updatedPod, metav1.UpdateOptions{})
+		pPod, err = c.client.Pods(targetNamespace).UpdateStatus(context.TODO(), updatedPod, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vPod.Namespace, vPod.Name)
+	}
+	return c.reconcilePodEphemeralContainers(vc, clusterName, targetNamespace, pPod, vPod)
+}
+
+// reconcilePodEphemeralContainers adds the tenant ephemeral containers the super pod misses. They are
+// added through the ephemeralcontainers subresource, since a pod update cannot change them. The added
+// containers go through the container checks, the pod mutators and the validation plugin like the
+// containers of a new pod, a rejection is recorded on the tenant pod and none of them is added.
+func (c *controller) reconcilePodEphemeralContainers(vc *v1alpha1.VirtualCluster, clusterName, targetNamespace string, pPod, vPod *corev1.Pod) error {
+	if c.Config.DisableEphemeralContainersSync {
+		return nil
+	}
+	ephemeralContainers := conversion.BuildSuperClusterEphemeralContainers(pPod, vPod)
+	if ephemeralContainers == nil {
+		return nil
+	}
+
+	updatedPod := pPod.DeepCopy()
+	updatedPod.Spec.EphemeralContainers = ephemeralContainers
+	rejection := checkContainers(c.Config, vc, "Pod with its ephemeral containers", &updatedPod.Spec)
+	if rejection == nil {
+		added := ephemeralContainers[len(pPod.Spec.EphemeralContainers):]
+		if err := c.mutateEphemeralContainers(vc, clusterName, pPod, vPod, added); err != nil {
+			return err
+		}
+		accepted, err := c.validateEphemeralContainers(clusterName, updatedPod)
 		if err != nil {
 			return err
 		}
+		if !accepted {
+			rejection = &Rejection{Reason: "ValidationFailed", Message: "The ephemeral containers of the Pod are rejected by the validation plugin of the syncer"}
+		}
 	}
+	if rejection != nil {
+		// Reject the ephemeral containers without retrying, they cannot be changed once added.
+		klog.Infof("reject ephemeral containers of pod %s/%s of cluster %s: %s", vPod.Namespace, vPod.Name, clusterName, rejection.Message)
+		return c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+			Kind:      "Pod",
+			Name:      vPod.Name,
+			Namespace: vPod.Namespace,
+			UID:       vPod.UID,
+		}, corev1.EventTypeWarning, rejection.Reason, "%s", rejection.Message)
+	}
+
+	_, err := c.client.Pods(targetNamespace).UpdateEphemeralContainers(context.TODO(), pPod.Name, &corev1.EphemeralContainers{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            pPod.Name,
+			Namespace:       targetNamespace,
+			ResourceVersion: pPod.ResourceVersion,
+		},
+		EphemeralContainers: ephemeralContainers,
+	}, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, vPod.Namespace, vPod.Name)
 	return nil
 }
 
+// mutateEphemeralContainers runs the pod mutators on the ephemeral containers added to the super pod.
+// The mutators work on the containers of a pod, so they run on copies of the tenant and super pods
+// whose containers are the added ones, and the mutated containers are copied back. The pod level
+// mutations, which cannot be applied to a running pod, are dropped.
+func (c *controller) mutateEphemeralContainers(vc *v1alpha1.VirtualCluster, clusterName string, pPod, vPod *corev1.Pod, added []corev1.EphemeralContainer) error {
+	pCopy, vCopy := pPod.DeepCopy(), vPod.DeepCopy()
+	vContainers := make(map[string]corev1.Container, len(vCopy.Spec.EphemeralContainers))
+	for _, container := range vCopy.Spec.EphemeralContainers {
+		vContainers[container.Name] = corev1.Container(container.EphemeralContainerCommon)
+	}
+	pCopy.Spec.InitContainers, vCopy.Spec.InitContainers = nil, nil
+	pCopy.Spec.EphemeralContainers, vCopy.Spec.EphemeralContainers = nil, nil
+	pCopy.Spec.Containers, vCopy.Spec.Containers = nil, nil
+	for _, container := range added {
+		pContainer := corev1.Container(container.EphemeralContainerCommon)
+		pCopy.Spec.Containers = append(pCopy.Spec.Containers, *pContainer.DeepCopy())
+		vCopy.Spec.Containers = append(vCopy.Spec.Containers, vContainers[container.Name])
+	}
+
+	// the default conversion already ran for the pod, the added containers get their part of it from
+	// BuildSuperClusterEphemeralContainers.
+	ms := c.podMutationPipeline(vc.GetAnnotations(), nil)
+	if err := conversion.VC(c.MultiClusterController, clusterName).Pod(pCopy, vCopy).Mutate(ms...); err != nil {
+		return fmt.Errorf("failed to mutate ephemeral containers: %v", err)
+	}
+	if len(pCopy.Spec.Containers) != len(added) {
+		return fmt.Errorf("failed to mutate ephemeral containers: the mutators changed the number of containers")
+	}
+	for i := range added {
+		added[i].EphemeralContainerCommon = corev1.EphemeralContainerCommon(pCopy.Spec.Containers[i])
+	}
+	return nil
+}
+
+// validateEphemeralContainers runs the validation plugin on the super pod with its ephemeral
+// containers, serialized with the pod creations of the tenant.
+func (c *controller) validateEphemeralContainers(clusterName string, pPod *corev1.Pod) (bool, error) {
+	if c.plugin == nil || !c.plugin.Enabled() {
+		return true, nil
+	}
+	pluginstart := time.Now()
+	defer recordOperationDuration("validation_plugin", pluginstart)
+	t := c.plugin.GetTenantLocker(clusterName)
+	if t == nil {
+		return false, apierrors.NewBadRequest("cannot get tenant")
+	}
+	t.Cond.Lock()
+	defer t.Cond.Unlock()
+	return c.plugin.Validation(pPod, clusterName), nil
+}
+
 func (c *controller) reconcilePodRemove(clusterName, targetNamespace, requestUID, name string, pPod *corev1.Pod) error {
 	if pPod.Annotations[constants.LabelUID] != requestUID {
 		return fmt.Errorf("to be deleted pPod %s/%s delegated UID is different from deleted object", targetNamespace, name)
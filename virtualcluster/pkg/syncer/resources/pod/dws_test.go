@@ -19,6 +19,7 @@ package pod
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
@@ -29,8 +30,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 	"k8s.io/utils/pointer"
 
@@ -113,6 +117,107 @@ func tenantSecret(name, namespace, uid string) *corev1.Secret {
 	}
 }
 
+func applyActiveDeadlineSecondsToPod(pod *corev1.Pod, seconds int64) *corev1.Pod {
+	pod.Spec.ActiveDeadlineSeconds = pointer.Int64Ptr(seconds)
+	return pod
+}
+
+func applyTerminationMessageToPod(pod *corev1.Pod, path string, policy corev1.TerminationMessagePolicy) *corev1.Pod {
+	for i := range pod.Spec.Containers {
+		pod.Spec.Containers[i].TerminationMessagePath = path
+		pod.Spec.Containers[i].TerminationMessagePolicy = policy
+	}
+	return pod
+}
+
+func applyProbesToPod(pod *corev1.Pod, liveness, readiness, startup *corev1.Probe) *corev1.Pod {
+	pod.Spec.Containers[0].LivenessProbe = liveness
+	pod.Spec.Containers[0].ReadinessProbe = readiness
+	pod.Spec.Containers[0].StartupProbe = startup
+	return pod
+}
+
+// applyInitContainersToPod appends init containers with the names, in order, e.g. native sidecars
+// interleaved with regular init containers.
+func applyInitContainersToPod(pod *corev1.Pod, env []corev1.EnvVar, names ...string) *corev1.Pod {
+	for _, name := range names {
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{Name: name, Image: "busybox", Env: env})
+	}
+	return pod
+}
+
+// nodeTaintTolerations are the node.kubernetes.io/not-ready and unreachable tolerations of a tenant
+// pod with custom tolerationSeconds.
+func nodeTaintTolerations() []corev1.Toleration {
+	return []corev1.Toleration{
+		{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: pointer.Int64Ptr(10)},
+		{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: pointer.Int64Ptr(600)},
+	}
+}
+
+func applyTolerationsToPod(pod *corev1.Pod, tolerations ...corev1.Toleration) *corev1.Pod {
+	pod.Spec.Tolerations = append(pod.Spec.Tolerations, tolerations...)
+	return pod
+}
+
+func applyPreemptionPolicyToPod(pod *corev1.Pod, policy corev1.PreemptionPolicy) *corev1.Pod {
+	pod.Spec.PreemptionPolicy = &policy
+	return pod
+}
+
+func applyHostNamespacesToPod(pod *corev1.Pod, hostIPC, hostPID bool) *corev1.Pod {
+	pod.Spec.HostIPC = hostIPC
+	pod.Spec.HostPID = hostPID
+	return pod
+}
+
+func applyWindowsUserToPod(pod *corev1.Pod, user string) *corev1.Pod {
+	pod.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{
+		WindowsOptions: &corev1.WindowsSecurityContextOptions{RunAsUserName: &user},
+	}
+	return pod
+}
+
+// grpcProbe is what a grpc probe looks like once decoded with an API version that does not know it.
+func applyCSIVolumeToPod(pod *corev1.Pod, nodePublishSecretName string) *corev1.Pod {
+	csi := &corev1.CSIVolumeSource{
+		Driver:           "secrets-store.csi.k8s.io",
+		ReadOnly:         pointer.Bool(true),
+		VolumeAttributes: map[string]string{"secretProviderClass": "vault-db"},
+	}
+	if nodePublishSecretName != "" {
+		csi.NodePublishSecretRef = &corev1.LocalObjectReference{Name: nodePublishSecretName}
+	}
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{Name: "secrets-store", VolumeSource: corev1.VolumeSource{CSI: csi}})
+	return pod
+}
+
+func grpcProbe() *corev1.Probe {
+	return &corev1.Probe{InitialDelaySeconds: 5, PeriodSeconds: 10}
+}
+
+func execProbe() *corev1.Probe {
+	return &corev1.Probe{Handler: corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"cat", "/tmp/healthy"}}}, PeriodSeconds: 10}
+}
+
+func httpGetProbe() *corev1.Probe {
+	return &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)}}, PeriodSeconds: 10}
+}
+
+func tcpSocketProbe() *corev1.Probe {
+	return &corev1.Probe{Handler: corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString("grpc")}}, FailureThreshold: 30}
+}
+
+// startupProbe gives a slow starting container up to 5s + 30 * 10s to start.
+func startupProbe() *corev1.Probe {
+	return &corev1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/started", Port: intstr.FromInt(8080)}}, InitialDelaySeconds: 5, PeriodSeconds: 10, FailureThreshold: 30}
+}
+
+func applyInitialDelayToProbe(probe *corev1.Probe, seconds int32) *corev1.Probe {
+	probe.InitialDelaySeconds = seconds
+	return probe
+}
+
 func applyNodeNameToPod(vPod *corev1.Pod, nodeName string) *corev1.Pod {
 	vPod.Spec.NodeName = nodeName
 	return vPod
@@ -275,7 +380,15 @@ func TestDWPodCreation(t *testing.T) {
 		ExistingObjectInSuper  []runtime.Object
 		ExistingObjectInTenant []runtime.Object
 		DisablePodServiceLinks bool
+		MaxContainersPerPod    int32
+		MaxPodCommandBytes     int64
+		UnsupportedProbePolicy string
+		StartupProbePolicy     string
+		AllowedWindowsUsers    []string
+		ForcePodNonPreempting  bool
+		VCAnnotations          map[string]string
 		ExpectedCreatedPods    []*corev1.Pod
+		ExpectedEventReason    string
 		ExpectedError          string
 	}{
 		"new Pod": {
@@ -451,6 +564,324 @@ func TestDWPodCreation(t *testing.T) {
 				tenantServiceAccount("default", "default", "12345"),
 			},
 		},
+		"new Pod with activeDeadlineSeconds": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyActiveDeadlineSecondsToPod(tenantPod("pod-1", "default", "12345"), 600),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPods: []*corev1.Pod{applyActiveDeadlineSecondsToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), 600)},
+		},
+		"new Pod with termination message": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyTerminationMessageToPod(tenantPod("pod-1", "default", "12345"), "/tmp/termination-log", corev1.TerminationMessageFallbackToLogsOnError),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPods: []*corev1.Pod{applyTerminationMessageToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), "/tmp/termination-log", corev1.TerminationMessageFallbackToLogsOnError)},
+		},
+		"new Pod with probes": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyProbesToPod(tenantPod("pod-1", "default", "12345"), execProbe(), httpGetProbe(), tcpSocketProbe()),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPods: []*corev1.Pod{applyProbesToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), execProbe(), httpGetProbe(), tcpSocketProbe())},
+		},
+		"new Pod keeps the startup probe": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyProbesToPod(tenantPod("pod-1", "default", "12345"), httpGetProbe(), httpGetProbe(), startupProbe()),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			StartupProbePolicy:  constants.StartupProbePolicyKeep,
+			ExpectedCreatedPods: []*corev1.Pod{applyProbesToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), httpGetProbe(), httpGetProbe(), startupProbe())},
+		},
+		"new Pod with startup probe folded": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyProbesToPod(tenantPod("pod-1", "default", "12345"), httpGetProbe(), httpGetProbe(), startupProbe()),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			StartupProbePolicy:  constants.StartupProbePolicyFold,
+			ExpectedCreatedPods: []*corev1.Pod{applyProbesToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), applyInitialDelayToProbe(httpGetProbe(), 305), httpGetProbe(), nil)},
+		},
+		"new Pod with inline CSI volume": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyCSIVolumeToPod(tenantPod("pod-1", "default", "12345"), ""),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPods: []*corev1.Pod{applyCSIVolumeToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), "")},
+		},
+		"new Pod with inline CSI volume and node publish secret": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyCSIVolumeToPod(tenantPod("pod-1", "default", "12345"), "vault-creds"),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "vault-creds", Namespace: "default", UID: "s67890"}, Type: corev1.SecretTypeOpaque},
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPods: []*corev1.Pod{applyCSIVolumeToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), "vault-creds")},
+		},
+		"new Pod with inline CSI volume and service account token node publish secret": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyCSIVolumeToPod(tenantPod("pod-1", "default", "12345"), testTenantServiceAccountTokenSecretName),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPods: []*corev1.Pod{applyCSIVolumeToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), testSuperServiceAccountTokenSecretName)},
+		},
+		"new Pod with inline CSI volume and missing node publish secret": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyCSIVolumeToPod(tenantPod("pod-1", "default", "12345"), "vault-creds"),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedError: "failed to get vSecret",
+		},
+		"new Pod keeps the init container order": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyInitContainersToPod(tenantPod("pod-1", "default", "12345"), nil, "init-config", "istio-proxy", "init-db", "log-shipper"),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPods: []*corev1.Pod{applyInitContainersToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"),
+				[]corev1.EnvVar{{Name: "KUBERNETES_SERVICE_HOST", Value: "kubernetes"}}, "init-config", "istio-proxy", "init-db", "log-shipper")},
+		},
+		"new Pod keeps the node taint tolerations": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyTolerationsToPod(tenantPod("pod-1", "default", "12345"), nodeTaintTolerations()...),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPods: []*corev1.Pod{applyTolerationsToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), nodeTaintTolerations()...)},
+		},
+		"new Pod keeps the preemption policy": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyPreemptionPolicyToPod(tenantPod("pod-1", "default", "12345"), corev1.PreemptLowerPriority),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPods: []*corev1.Pod{applyPreemptionPolicyToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), corev1.PreemptLowerPriority)},
+		},
+		"new Pod forced non-preempting": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyPreemptionPolicyToPod(tenantPod("pod-1", "default", "12345"), corev1.PreemptLowerPriority),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ForcePodNonPreempting: true,
+			ExpectedCreatedPods:   []*corev1.Pod{applyPreemptionPolicyToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), corev1.PreemptNever)},
+		},
+		"new Pod with grpc probes": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyProbesToPod(tenantPod("pod-1", "default", "12345"), grpcProbe(), grpcProbe(), grpcProbe()),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+		},
+		"new Pod with grpc readiness probe": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyProbesToPod(tenantPod("pod-1", "default", "12345"), execProbe(), grpcProbe(), nil),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			UnsupportedProbePolicy: constants.UnsupportedProbePolicyReject,
+		},
+		"new Pod with grpc probes dropped": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyProbesToPod(tenantPod("pod-1", "default", "12345"), grpcProbe(), httpGetProbe(), grpcProbe()),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			UnsupportedProbePolicy: constants.UnsupportedProbePolicyDrop,
+			ExpectedCreatedPods:    []*corev1.Pod{applyProbesToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), nil, httpGetProbe(), nil)},
+		},
+		"new Pod within max containers": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPod("pod-1", "default", "12345"),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			MaxContainersPerPod: 1,
+			ExpectedCreatedPods: []*corev1.Pod{superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345")},
+		},
+		"new Pod exceeding max containers": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				func() *corev1.Pod {
+					vPod := tenantPod("pod-1", "default", "12345")
+					vPod.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "busybox"}}
+					vPod.Spec.EphemeralContainers = []corev1.EphemeralContainer{{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debug", Image: "busybox"}}}
+					return vPod
+				}(),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			MaxContainersPerPod: 2,
+			ExpectedEventReason: "TooManyContainers",
+		},
+		"new Pod within max command bytes": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantPod("pod-1", "default", "12345"),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			MaxPodCommandBytes:  1024,
+			ExpectedCreatedPods: []*corev1.Pod{superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345")},
+		},
+		"new Pod exceeding max command bytes": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				func() *corev1.Pod {
+					vPod := tenantPod("pod-1", "default", "12345")
+					vPod.Spec.Containers[0].Args = []string{strings.Repeat("x", 1025)}
+					return vPod
+				}(),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			MaxPodCommandBytes: 1024,
+		},
+		"new Pod with allowed windows user": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyWindowsUserToPod(tenantPod("pod-1", "default", "12345"), "containeruser"),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			AllowedWindowsUsers: []string{"ContainerUser"},
+			ExpectedCreatedPods: []*corev1.Pod{applyWindowsUserToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), "containeruser")},
+		},
+		"new Pod with disallowed windows user": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyWindowsUserToPod(tenantPod("pod-1", "default", "12345"), "ContainerAdministrator"),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			AllowedWindowsUsers: []string{"ContainerUser"},
+		},
+		"new Pod sharing host namespaces denied by default": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyHostNamespacesToPod(tenantPod("pod-1", "default", "12345"), true, false),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+		},
+		"new Pod sharing host namespaces not all allowed": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyHostNamespacesToPod(tenantPod("pod-1", "default", "12345"), true, true),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			VCAnnotations: map[string]string{constants.LabelAllowedHostNamespaces: constants.HostNamespaceIPC},
+		},
+		"new Pod sharing allowed host namespaces": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyHostNamespacesToPod(tenantPod("pod-1", "default", "12345"), true, true),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			VCAnnotations:       map[string]string{constants.LabelAllowedHostNamespaces: "hostIPC, hostPID"},
+			ExpectedCreatedPods: []*corev1.Pod{applyHostNamespacesToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"), true, true)},
+		},
 		"new Pod but already exists": {
 			ExistingObjectInSuper: []runtime.Object{
 				superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"),
@@ -473,6 +904,9 @@ func TestDWPodCreation(t *testing.T) {
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
+			vc := testTenant.DeepCopy()
+			vc.Annotations = tc.VCAnnotations
+			var tenant *fake.Clientset
 			actions, reconcileErr, err := util.RunDownwardSync(func(config *config.SyncerConfiguration,
 				client clientset.Interface,
 				informer informers.SharedInformerFactory,
@@ -480,8 +914,16 @@ func TestDWPodCreation(t *testing.T) {
 				vcInformer vcinformers.VirtualClusterInformer,
 				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
 				config.DisablePodServiceLinks = tc.DisablePodServiceLinks
+				config.MaxContainersPerPod = tc.MaxContainersPerPod
+				config.MaxPodCommandBytes = tc.MaxPodCommandBytes
+				config.UnsupportedProbePolicy = tc.UnsupportedProbePolicy
+				config.StartupProbePolicy = tc.StartupProbePolicy
+				config.AllowedWindowsRunAsUserNames = tc.AllowedWindowsUsers
+				config.ForcePodNonPreempting = tc.ForcePodNonPreempting
 				return NewPodController(config, client, informer, vcClient, vcInformer, options)
-			}, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			}, vc, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], func(tenantClientset, superClientset *fake.Clientset) {
+				tenant = tenantClientset
+			})
 			if err != nil {
 				t.Errorf("%s: error running downward sync: %v", k, err)
 				return
@@ -499,6 +941,18 @@ func TestDWPodCreation(t *testing.T) {
 				}
 			}
 
+			if tc.ExpectedEventReason != "" {
+				var eventReasons []string
+				for _, action := range tenant.Actions() {
+					if action.Matches("create", "events") {
+						eventReasons = append(eventReasons, action.(core.CreateAction).GetObject().(*corev1.Event).Reason)
+					}
+				}
+				if len(eventReasons) != 1 || eventReasons[0] != tc.ExpectedEventReason {
+					t.Errorf("%s: Expected a %s event, got %v", k, tc.ExpectedEventReason, eventReasons)
+				}
+			}
+
 			if len(tc.ExpectedCreatedPods) != len(actions) {
 				t.Errorf("%s: Expected to create Pod %#v. Actual actions were: %#v", k, tc.ExpectedCreatedPods, actions)
 				return
@@ -788,3 +1242,289 @@ func TestDWPodUpdate(t *testing.T) {
 		})
 	}
 }
+
+func applyEphemeralContainersToPod(pod *corev1.Pod, containers ...corev1.EphemeralContainer) *corev1.Pod {
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, containers...)
+	return pod
+}
+
+func debugContainer(name, tokenVolume string, env ...corev1.EnvVar) corev1.EphemeralContainer {
+	return corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:  name,
+			Image: "busybox",
+			Env:   env,
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      tokenVolume,
+					MountPath: "/var/run/secrets/kubernetes.io/serviceaccount",
+				},
+			},
+		},
+	}
+}
+
+func withImagePullPolicy(container corev1.EphemeralContainer, policy corev1.PullPolicy) corev1.EphemeralContainer {
+	container.ImagePullPolicy = policy
+	return container
+}
+
+func withWindowsRunAsUserName(container corev1.EphemeralContainer, userName string) corev1.EphemeralContainer {
+	container.SecurityContext = &corev1.SecurityContext{WindowsOptions: &corev1.WindowsSecurityContextOptions{RunAsUserName: &userName}}
+	return container
+}
+
+func TestDWPodEphemeralContainers(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	defaultVCName, defaultVCNamespace := testTenant.Name, testTenant.Namespace
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+	injectedEnv := corev1.EnvVar{Name: "KUBERNETES_SERVICE_HOST", Value: "kubernetes"}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper               []runtime.Object
+		ExistingObjectInTenant              []runtime.Object
+		DisableEphemeralContainersSync      bool
+		ImagePullPolicyRewrite              string
+		AllowedWindowsRunAsUserNames        []string
+		ExpectedUpdatedEphemeralContainers  []corev1.EphemeralContainer
+		ExpectedCreatedPodWithoutEphemerals bool
+		ExpectedEventReason                 string
+	}{
+		"ephemeral container added": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyEphemeralContainersToPod(tenantPod("pod-1", "default", "12345"), debugContainer("debugger", testTenantServiceAccountTokenSecretName)),
+			},
+			ExpectedUpdatedEphemeralContainers: []corev1.EphemeralContainer{
+				debugContainer("debugger", testSuperServiceAccountTokenSecretName, injectedEnv),
+			},
+		},
+		"second ephemeral container added": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyEphemeralContainersToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"),
+					debugContainer("debugger", testSuperServiceAccountTokenSecretName, injectedEnv)),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyEphemeralContainersToPod(tenantPod("pod-1", "default", "12345"),
+					debugContainer("debugger", testTenantServiceAccountTokenSecretName),
+					debugContainer("debugger-2", testTenantServiceAccountTokenSecretName, corev1.EnvVar{Name: "KUBERNETES_SERVICE_HOST", Value: "localhost"})),
+			},
+			ExpectedUpdatedEphemeralContainers: []corev1.EphemeralContainer{
+				debugContainer("debugger", testSuperServiceAccountTokenSecretName, injectedEnv),
+				debugContainer("debugger-2", testSuperServiceAccountTokenSecretName, corev1.EnvVar{Name: "KUBERNETES_SERVICE_HOST", Value: "localhost"}),
+			},
+		},
+		"ephemeral container already added": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyEphemeralContainersToPod(superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"),
+					debugContainer("debugger", testSuperServiceAccountTokenSecretName, injectedEnv)),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyEphemeralContainersToPod(tenantPod("pod-1", "default", "12345"), debugContainer("debugger", testTenantServiceAccountTokenSecretName)),
+			},
+		},
+		"ephemeral container mutated": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyEphemeralContainersToPod(tenantPod("pod-1", "default", "12345"), debugContainer("debugger", testTenantServiceAccountTokenSecretName)),
+			},
+			ImagePullPolicyRewrite: constants.ImagePullPolicyRewriteForce,
+			ExpectedUpdatedEphemeralContainers: []corev1.EphemeralContainer{
+				withImagePullPolicy(debugContainer("debugger", testSuperServiceAccountTokenSecretName, injectedEnv), corev1.PullIfNotPresent),
+			},
+		},
+		"ephemeral container rejected": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyEphemeralContainersToPod(tenantPod("pod-1", "default", "12345"), withWindowsRunAsUserName(debugContainer("debugger", testTenantServiceAccountTokenSecretName), "ContainerAdministrator")),
+			},
+			AllowedWindowsRunAsUserNames: []string{"ContainerUser"},
+			ExpectedEventReason:          "WindowsRunAsUserNameNotAllowed",
+		},
+		"ephemeral container sync disabled": {
+			ExistingObjectInSuper: []runtime.Object{
+				superPod(defaultClusterKey, defaultVCName, defaultVCNamespace, "pod-1", "default", "12345"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyEphemeralContainersToPod(tenantPod("pod-1", "default", "12345"), debugContainer("debugger", testTenantServiceAccountTokenSecretName)),
+			},
+			DisableEphemeralContainersSync: true,
+		},
+		"new pod with ephemeral container": {
+			ExistingObjectInSuper: []runtime.Object{
+				superSecret("default-token-12345", superDefaultNSName, "s12345"),
+				superService("kubernetes", superDefaultNSName, "12345", ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyEphemeralContainersToPod(tenantPod("pod-1", "default", "12345"), debugContainer("debugger", testTenantServiceAccountTokenSecretName)),
+				tenantSecret(testTenantServiceAccountTokenSecretName, "default", "s12345"),
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			ExpectedCreatedPodWithoutEphemerals: true,
+			ExpectedUpdatedEphemeralContainers: []corev1.EphemeralContainer{
+				debugContainer("debugger", testSuperServiceAccountTokenSecretName, injectedEnv),
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			var tenant *fake.Clientset
+			actions, reconcileErr, err := util.RunDownwardSync(func(config *config.SyncerConfiguration,
+				client clientset.Interface,
+				informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface,
+				vcInformer vcinformers.VirtualClusterInformer,
+				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.DisableEphemeralContainersSync = tc.DisableEphemeralContainersSync
+				config.ImagePullPolicyRewrite = tc.ImagePullPolicyRewrite
+				config.AllowedWindowsRunAsUserNames = tc.AllowedWindowsRunAsUserNames
+				return NewPodController(config, client, informer, vcClient, vcInformer, options)
+			}, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], func(tenantClientset, superClientset *fake.Clientset) {
+				tenant = tenantClientset
+			})
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+			}
+
+			var eventReasons []string
+			for _, action := range tenant.Actions() {
+				if action.Matches("create", "events") {
+					eventReasons = append(eventReasons, action.(core.CreateAction).GetObject().(*corev1.Event).Reason)
+				}
+			}
+			if tc.ExpectedEventReason == "" && len(eventReasons) != 0 {
+				t.Errorf("%s: Expected no event, got %v", k, eventReasons)
+			}
+			if tc.ExpectedEventReason != "" && (len(eventReasons) != 1 || eventReasons[0] != tc.ExpectedEventReason) {
+				t.Errorf("%s: Expected a %s event, got %v", k, tc.ExpectedEventReason, eventReasons)
+			}
+
+			if tc.ExpectedCreatedPodWithoutEphemerals {
+				if len(actions) == 0 || !actions[0].Matches("create", "pods") {
+					t.Errorf("%s: Expected to create a pod. Actual actions were: %#v", k, actions)
+					return
+				}
+				if created := actions[0].(core.CreateAction).GetObject().(*corev1.Pod); len(created.Spec.EphemeralContainers) != 0 {
+					t.Errorf("%s: Expected the pod to be created without ephemeral containers, got %v", k, created.Spec.EphemeralContainers)
+				}
+				actions = actions[1:]
+			}
+
+			if tc.ExpectedUpdatedEphemeralContainers == nil {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 {
+				t.Errorf("%s: Expected to update the ephemeral containers. Actual actions were: %#v", k, actions)
+				return
+			}
+			if !actions[0].Matches("update", "pods") || actions[0].GetSubresource() != "ephemeralcontainers" {
+				t.Errorf("%s: Unexpected action %s", k, actions[0])
+				return
+			}
+			updated := actions[0].(core.UpdateAction).GetObject().(*corev1.EphemeralContainers)
+			if updated.Name != "pod-1" || updated.Namespace != superDefaultNSName {
+				t.Errorf("%s: Expected to update the ephemeral containers of %s/pod-1, got %s/%s", k, superDefaultNSName, updated.Namespace, updated.Name)
+			}
+			if !equality.Semantic.DeepEqual(updated.EphemeralContainers, tc.ExpectedUpdatedEphemeralContainers) {
+				t.Errorf("%s: Expected ephemeral containers %v, got %v", k, tc.ExpectedUpdatedEphemeralContainers, updated.EphemeralContainers)
+			}
+		})
+	}
+}
+
+func TestCountPodContainers(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers:     []corev1.Container{{Name: "a"}, {Name: "b"}},
+			InitContainers: []corev1.Container{{Name: "init"}},
+			EphemeralContainers: []corev1.EphemeralContainer{
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debug"}},
+			},
+		},
+	}
+	if got := countPodContainers(&pod.Spec); got != 4 {
+		t.Errorf("countPodContainers() = %d, want 4", got)
+	}
+}
+
+func TestPodCommandBytes(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "a", Command: []string{"sh", "-c"}, Args: []string{"echo"}},
+				{Name: "b", Env: []corev1.EnvVar{{Name: "KEY", Value: "value"}, {Name: "REF", ValueFrom: &corev1.EnvVarSource{}}}},
+			},
+			InitContainers: []corev1.Container{{Name: "init", Args: []string{"init"}}},
+			EphemeralContainers: []corev1.EphemeralContainer{
+				{EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "debug", Command: []string{"debug"}}},
+			},
+		},
+	}
+	// "sh" + "-c" + "echo" + "KEY" + "value" + "REF" + "init" + "debug"
+	if got := podCommandBytes(&pod.Spec); got != 28 {
+		t.Errorf("podCommandBytes() = %d, want 28", got)
+	}
+}
+
+func TestDisallowedHostNamespaces(t *testing.T) {
+	testcases := map[string]struct {
+		hostIPC, hostPID bool
+		allowed          sets.String
+		expected         []string
+	}{
+		"no host namespace": {
+			allowed: sets.NewString(),
+		},
+		"denied by default": {
+			hostIPC:  true,
+			hostPID:  true,
+			allowed:  sets.NewString(),
+			expected: []string{constants.HostNamespaceIPC, constants.HostNamespacePID},
+		},
+		"partially allowed": {
+			hostIPC:  true,
+			hostPID:  true,
+			allowed:  sets.NewString(constants.HostNamespacePID),
+			expected: []string{constants.HostNamespaceIPC},
+		},
+		"allowed": {
+			hostIPC: true,
+			hostPID: true,
+			allowed: sets.NewString(constants.HostNamespaceIPC, constants.HostNamespacePID),
+		},
+	}
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			pod := applyHostNamespacesToPod(&corev1.Pod{}, tc.hostIPC, tc.hostPID)
+			if got := disallowedHostNamespaces(&pod.Spec, tc.allowed); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("disallowedHostNamespaces() = %v, want %v", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	uplugin "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	MutatorRegister.Register(&uplugin.Registration{
+		ID: "00_PodAppArmorMutator",
+		InitFn: func(ctx *uplugin.InitContext) (interface{}, error) {
+			return NewPodAppArmorMutatorPlugin(ctx.Config.(*config.SyncerConfiguration).DefaultAppArmorProfile), nil
+		},
+	})
+}
+
+type PodAppArmorMutatorPlugin struct {
+	defaultProfile string
+}
+
+func NewPodAppArmorMutatorPlugin(defaultProfile string) *PodAppArmorMutatorPlugin {
+	if defaultProfile != "" && !isValidAppArmorProfile(defaultProfile) {
+		klog.Warningf("ignore invalid default AppArmor profile %q", defaultProfile)
+		defaultProfile = ""
+	}
+	return &PodAppArmorMutatorPlugin{defaultProfile: defaultProfile}
+}
+
+// Mutator keeps the AppArmor profiles of the tenant pod and applies the default profile to the
+// containers without one. The profiles are carried by the legacy per container annotations,
+// which the generic conversion strips as they belong to an opaque domain. The typed
+// securityContext.appArmorProfile field is unknown to the vendored API and cannot be set here,
+// super clusters that use the field populate it from the annotations.
+func (pl *PodAppArmorMutatorPlugin) Mutator() conversion.PodMutator {
+	return func(p *conversion.PodMutateCtx) error {
+		annotations := p.PPod.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+
+		if p.VPod != nil {
+			for k, v := range p.VPod.GetAnnotations() {
+				if strings.HasPrefix(k, constants.AppArmorBetaContainerAnnotationKeyPrefix) {
+					annotations[k] = v
+				}
+			}
+		}
+
+		if pl.defaultProfile != "" {
+			var names []string
+			for _, c := range p.PPod.Spec.InitContainers {
+				names = append(names, c.Name)
+			}
+			for _, c := range p.PPod.Spec.Containers {
+				names = append(names, c.Name)
+			}
+			for _, c := range p.PPod.Spec.EphemeralContainers {
+				names = append(names, c.Name)
+			}
+			for _, name := range names {
+				key := constants.AppArmorBetaContainerAnnotationKeyPrefix + name
+				if _, ok := annotations[key]; !ok {
+					annotations[key] = pl.defaultProfile
+				}
+			}
+		}
+
+		if len(annotations) != 0 {
+			p.PPod.SetAnnotations(annotations)
+		}
+		return nil
+	}
+}
+
+func isValidAppArmorProfile(profile string) bool {
+	return profile == constants.AppArmorBetaProfileRuntimeDefault ||
+		profile == constants.AppArmorBetaProfileNameUnconfined ||
+		(strings.HasPrefix(profile, constants.AppArmorBetaProfileNamePrefix) && len(profile) > len(constants.AppArmorBetaProfileNamePrefix))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func TestPodAppArmorMutatorPlugin_Mutator(t *testing.T) {
+	withContainerNames := func(p *corev1.Pod) {
+		p.Spec.InitContainers[0].Name = "init"
+		p.Spec.Containers[0].Name = "app"
+	}
+	withAppArmorAnnotation := func(container, profile string) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			if p.Annotations == nil {
+				p.Annotations = map[string]string{}
+			}
+			p.Annotations[constants.AppArmorBetaContainerAnnotationKeyPrefix+container] = profile
+		}
+	}
+
+	tests := []struct {
+		name           string
+		defaultProfile string
+		vPod           *corev1.Pod
+		want           map[string]string
+	}{
+		{
+			name: "no profile and no default",
+			vPod: tenantPod("test", "default", "123-456-789", withContainerNames),
+			want: nil,
+		},
+		{
+			name: "tenant profile is preserved",
+			vPod: tenantPod("test", "default", "123-456-789", withContainerNames, withAppArmorAnnotation("app", "localhost/tenant")),
+			want: map[string]string{
+				constants.AppArmorBetaContainerAnnotationKeyPrefix + "app": "localhost/tenant",
+			},
+		},
+		{
+			name:           "default profile is injected",
+			defaultProfile: constants.AppArmorBetaProfileRuntimeDefault,
+			vPod:           tenantPod("test", "default", "123-456-789", withContainerNames),
+			want: map[string]string{
+				constants.AppArmorBetaContainerAnnotationKeyPrefix + "init": constants.AppArmorBetaProfileRuntimeDefault,
+				constants.AppArmorBetaContainerAnnotationKeyPrefix + "app":  constants.AppArmorBetaProfileRuntimeDefault,
+			},
+		},
+		{
+			name:           "default profile does not override tenant profile",
+			defaultProfile: constants.AppArmorBetaProfileRuntimeDefault,
+			vPod:           tenantPod("test", "default", "123-456-789", withContainerNames, withAppArmorAnnotation("app", constants.AppArmorBetaProfileNameUnconfined)),
+			want: map[string]string{
+				constants.AppArmorBetaContainerAnnotationKeyPrefix + "init": constants.AppArmorBetaProfileRuntimeDefault,
+				constants.AppArmorBetaContainerAnnotationKeyPrefix + "app":  constants.AppArmorBetaProfileNameUnconfined,
+			},
+		},
+		{
+			name:           "invalid default profile is ignored",
+			defaultProfile: "enforce-everything",
+			vPod:           tenantPod("test", "default", "123-456-789", withContainerNames),
+			want:           nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutator := NewPodAppArmorMutatorPlugin(tt.defaultProfile).Mutator()
+
+			// the generic conversion strips the opaque AppArmor annotations from the super copy.
+			pPod := tt.vPod.DeepCopy()
+			pPod.Annotations = nil
+			if err := mutator(&conversion.PodMutateCtx{PPod: pPod, VPod: tt.vPod}); err != nil {
+				t.Errorf("mutator failed processing the pod")
+			}
+
+			if !equality.Semantic.DeepEqual(pPod.Annotations, tt.want) {
+				t.Errorf("pPod.Annotations = %v, want %v", pPod.Annotations, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	uplugin "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	MutatorRegister.Register(&uplugin.Registration{
+		ID: "00_PodImagePullPolicyMutator",
+		InitFn: func(ctx *uplugin.InitContext) (interface{}, error) {
+			syncerConfig := ctx.Config.(*config.SyncerConfiguration)
+			return &PodImagePullPolicyMutatorPlugin{
+				rewrite: syncerConfig.ImagePullPolicyRewrite,
+				policy:  corev1.PullPolicy(syncerConfig.ImagePullPolicy),
+			}, nil
+		},
+	})
+}
+
+type PodImagePullPolicyMutatorPlugin struct {
+	rewrite string
+	policy  corev1.PullPolicy
+}
+
+// Mutator rewrites the imagePullPolicy of the super pod containers according to the
+// ImagePullPolicyRewrite and ImagePullPolicy settings, which the annotations of the
+// VirtualCluster take precedence over. The tenant pod is left unchanged.
+func (pl *PodImagePullPolicyMutatorPlugin) Mutator() conversion.PodMutator {
+	return func(p *conversion.PodMutateCtx) error {
+		var annotations map[string]string
+		if p.Mc != nil {
+			vc, err := util.GetVirtualClusterObject(p.Mc, p.ClusterName)
+			if err != nil {
+				return err
+			}
+			annotations = vc.GetAnnotations()
+		}
+		rewrite, policy := pl.imagePullPolicyRewrite(annotations)
+
+		switch rewrite {
+		case "", constants.ImagePullPolicyRewriteNone:
+			return nil
+		case constants.ImagePullPolicyRewriteForce, constants.ImagePullPolicyRewriteOverrideAlways:
+		default:
+			klog.Warningf("ignore unknown image pull policy rewrite %q of cluster %s", rewrite, p.ClusterName)
+			return nil
+		}
+		switch policy {
+		case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		default:
+			klog.Warningf("ignore unknown image pull policy %q of cluster %s", policy, p.ClusterName)
+			return nil
+		}
+
+		rewriteImagePullPolicy(p.PPod, rewrite, policy)
+		return nil
+	}
+}
+
+// imagePullPolicyRewrite returns the rewrite mode and the policy of a cluster given the annotations
+// of its VirtualCluster.
+func (pl *PodImagePullPolicyMutatorPlugin) imagePullPolicyRewrite(annotations map[string]string) (string, corev1.PullPolicy) {
+	rewrite, policy := pl.rewrite, pl.policy
+	if v, ok := annotations[constants.LabelImagePullPolicyRewrite]; ok {
+		rewrite = v
+	}
+	if v, ok := annotations[constants.LabelImagePullPolicy]; ok {
+		policy = corev1.PullPolicy(v)
+	}
+	if policy == "" {
+		policy = corev1.PullIfNotPresent
+	}
+	return rewrite, policy
+}
+
+func rewriteImagePullPolicy(pod *corev1.Pod, rewrite string, policy corev1.PullPolicy) {
+	rewriteContainer := func(pullPolicy *corev1.PullPolicy) {
+		if rewrite == constants.ImagePullPolicyRewriteForce || *pullPolicy == corev1.PullAlways {
+			*pullPolicy = policy
+		}
+	}
+	for i := range pod.Spec.InitContainers {
+		rewriteContainer(&pod.Spec.InitContainers[i].ImagePullPolicy)
+	}
+	for i := range pod.Spec.Containers {
+		rewriteContainer(&pod.Spec.Containers[i].ImagePullPolicy)
+	}
+	for i := range pod.Spec.EphemeralContainers {
+		rewriteContainer(&pod.Spec.EphemeralContainers[i].ImagePullPolicy)
+	}
+}
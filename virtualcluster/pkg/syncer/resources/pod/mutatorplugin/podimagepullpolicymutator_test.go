@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func TestPodImagePullPolicyMutatorPlugin_Mutator(t *testing.T) {
+	withPullPolicies := func(policies ...corev1.PullPolicy) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "busybox", ImagePullPolicy: policies[0]}}
+			p.Spec.Containers = nil
+			for _, policy := range policies[1:] {
+				p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: "c", Image: "busybox", ImagePullPolicy: policy})
+			}
+		}
+	}
+
+	tests := []struct {
+		name     string
+		rewrite  string
+		policy   corev1.PullPolicy
+		tenant   []corev1.PullPolicy
+		expected []corev1.PullPolicy
+	}{
+		{
+			name:     "no rewrite by default",
+			tenant:   []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever},
+			expected: []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever},
+		},
+		{
+			name:     "none",
+			rewrite:  constants.ImagePullPolicyRewriteNone,
+			policy:   corev1.PullIfNotPresent,
+			tenant:   []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever},
+			expected: []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever},
+		},
+		{
+			name:     "force",
+			rewrite:  constants.ImagePullPolicyRewriteForce,
+			policy:   corev1.PullIfNotPresent,
+			tenant:   []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent},
+			expected: []corev1.PullPolicy{corev1.PullIfNotPresent, corev1.PullIfNotPresent, corev1.PullIfNotPresent},
+		},
+		{
+			name:     "force defaults to IfNotPresent",
+			rewrite:  constants.ImagePullPolicyRewriteForce,
+			tenant:   []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever},
+			expected: []corev1.PullPolicy{corev1.PullIfNotPresent, corev1.PullIfNotPresent},
+		},
+		{
+			name:     "override always",
+			rewrite:  constants.ImagePullPolicyRewriteOverrideAlways,
+			policy:   corev1.PullIfNotPresent,
+			tenant:   []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever, corev1.PullAlways},
+			expected: []corev1.PullPolicy{corev1.PullIfNotPresent, corev1.PullNever, corev1.PullIfNotPresent},
+		},
+		{
+			name:     "unknown rewrite",
+			rewrite:  "sometimes",
+			policy:   corev1.PullIfNotPresent,
+			tenant:   []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever},
+			expected: []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever},
+		},
+		{
+			name:     "unknown policy",
+			rewrite:  constants.ImagePullPolicyRewriteForce,
+			policy:   "Sometimes",
+			tenant:   []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever},
+			expected: []corev1.PullPolicy{corev1.PullAlways, corev1.PullNever},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := &PodImagePullPolicyMutatorPlugin{rewrite: tt.rewrite, policy: tt.policy}
+			mutator := pl.Mutator()
+
+			vPod := tenantPod("test", "default", "123-456-789", withPullPolicies(tt.tenant...))
+			pPod := vPod.DeepCopy()
+			if err := mutator(&conversion.PodMutateCtx{PPod: pPod, VPod: vPod}); err != nil {
+				t.Errorf("mutator failed processing the pod")
+			}
+
+			got := []corev1.PullPolicy{pPod.Spec.InitContainers[0].ImagePullPolicy}
+			for _, c := range pPod.Spec.Containers {
+				got = append(got, c.ImagePullPolicy)
+			}
+			for i := range tt.expected {
+				if got[i] != tt.expected[i] {
+					t.Errorf("super pod pull policies = %v, want %v", got, tt.expected)
+					break
+				}
+			}
+			if vPod.Spec.InitContainers[0].ImagePullPolicy != tt.tenant[0] || vPod.Spec.Containers[0].ImagePullPolicy != tt.tenant[1] {
+				t.Errorf("tenant pod must not be changed")
+			}
+		})
+	}
+}
+
+func TestPodImagePullPolicyMutatorPlugin_imagePullPolicyRewrite(t *testing.T) {
+	pl := &PodImagePullPolicyMutatorPlugin{rewrite: constants.ImagePullPolicyRewriteNone, policy: corev1.PullIfNotPresent}
+
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		expectedRewrite string
+		expectedPolicy  corev1.PullPolicy
+	}{
+		{
+			name:            "global settings",
+			expectedRewrite: constants.ImagePullPolicyRewriteNone,
+			expectedPolicy:  corev1.PullIfNotPresent,
+		},
+		{
+			name:            "cluster rewrite",
+			annotations:     map[string]string{constants.LabelImagePullPolicyRewrite: constants.ImagePullPolicyRewriteOverrideAlways},
+			expectedRewrite: constants.ImagePullPolicyRewriteOverrideAlways,
+			expectedPolicy:  corev1.PullIfNotPresent,
+		},
+		{
+			name: "cluster rewrite and policy",
+			annotations: map[string]string{
+				constants.LabelImagePullPolicyRewrite: constants.ImagePullPolicyRewriteForce,
+				constants.LabelImagePullPolicy:        string(corev1.PullNever),
+			},
+			expectedRewrite: constants.ImagePullPolicyRewriteForce,
+			expectedPolicy:  corev1.PullNever,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewrite, policy := pl.imagePullPolicyRewrite(tt.annotations)
+			if rewrite != tt.expectedRewrite || policy != tt.expectedPolicy {
+				t.Errorf("imagePullPolicyRewrite() = %q, %q, want %q, %q", rewrite, policy, tt.expectedRewrite, tt.expectedPolicy)
+			}
+		})
+	}
+}
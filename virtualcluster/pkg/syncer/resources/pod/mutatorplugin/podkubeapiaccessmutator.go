@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,6 +29,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
 
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
@@ -58,12 +60,20 @@ func init() {
 type PodKubeAPIAccessMutatorPlugin struct {
 	client       kubernetes.Interface
 	generateName func(string) string
+	// lookups caches the super control plane service accounts and token secrets, which are not
+	// backed by an informer.
+	lookups *conversion.LookupCache
 }
 
 func NewPodKubeAPIAccessMutatorPlugin(ctx *uplugin.InitContext) (*PodKubeAPIAccessMutatorPlugin, error) {
+	var ttl time.Duration
+	if cfg, ok := ctx.Config.(*config.SyncerConfiguration); ok {
+		ttl = cfg.SuperClusterLookupCacheTTL.Duration
+	}
 	plugin := &PodKubeAPIAccessMutatorPlugin{
 		client:       ctx.Client,
 		generateName: names.SimpleNameGenerator.GenerateName,
+		lookups:      conversion.NewLookupCache(ttl),
 	}
 	return plugin, nil
 }
@@ -87,7 +97,7 @@ func (pl *PodKubeAPIAccessMutatorPlugin) Mutator() conversion.PodMutator {
 		}
 
 		targetNamespace := conversion.ToSuperClusterNamespace(p.ClusterName, p.VPod.Namespace)
-		serviceAccount, err := pl.client.CoreV1().ServiceAccounts(targetNamespace).Get(context.TODO(), p.PPod.Spec.ServiceAccountName, metav1.GetOptions{})
+		serviceAccount, err := pl.getServiceAccount(targetNamespace, p.PPod.Spec.ServiceAccountName)
 		if err != nil {
 			return fmt.Errorf("error looking up serviceAccount %s/%s: %v", targetNamespace, p.PPod.Spec.ServiceAccountName, err)
 		}
@@ -105,7 +115,32 @@ func (pl *PodKubeAPIAccessMutatorPlugin) Mutator() conversion.PodMutator {
 	}
 }
 
+func (pl *PodKubeAPIAccessMutatorPlugin) getServiceAccount(namespace, name string) (*corev1.ServiceAccount, error) {
+	obj, err := pl.lookups.Get("serviceaccounts/"+namespace+"/"+name, func() (interface{}, error) {
+		return pl.client.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*corev1.ServiceAccount), nil
+}
+
 func (pl *PodKubeAPIAccessMutatorPlugin) getSecret(cluster, namespace string, sa *corev1.ServiceAccount) (*corev1.Secret, error) {
+	obj, err := pl.lookups.Get("secrets/"+namespace+"/"+cluster+"/"+sa.Name, func() (interface{}, error) {
+		secret, err := pl.findSecret(cluster, namespace, sa)
+		if err != nil || secret == nil {
+			// the token secret may not be synced yet, do not cache the miss.
+			return nil, err
+		}
+		return secret, nil
+	})
+	if err != nil || obj == nil {
+		return nil, err
+	}
+	return obj.(*corev1.Secret), nil
+}
+
+func (pl *PodKubeAPIAccessMutatorPlugin) findSecret(cluster, namespace string, sa *corev1.ServiceAccount) (*corev1.Secret, error) {
 	secrets, err := pl.client.CoreV1().Secrets(namespace).List(context.Background(), metav1.ListOptions{})
 	if err != nil {
 		klog.Errorf("error listing secret from super control plane informer cache: %v", err)
@@ -28,20 +28,35 @@ func init() {
 	MutatorRegister.Register(&uplugin.Registration{
 		ID: "00_PodMountServiceAccountTokenMutator",
 		InitFn: func(ctx *uplugin.InitContext) (interface{}, error) {
-			return &PodMountServiceAccountTokenMutatorPlugin{disable: ctx.Config.(*config.SyncerConfiguration).DisableServiceAccountToken}, nil
+			syncerConfig := ctx.Config.(*config.SyncerConfiguration)
+			return &PodMountServiceAccountTokenMutatorPlugin{
+				disable:        syncerConfig.DisableServiceAccountToken,
+				allowAutomount: syncerConfig.AllowPodServiceAccountTokenAutomount,
+			}, nil
 		},
 	})
 }
 
 type PodMountServiceAccountTokenMutatorPlugin struct {
 	disable bool
+	// allowAutomount keeps the super cluster service account token of the pods
+	// explicitly asking for it even if disable is set.
+	allowAutomount bool
 }
 
 func (pl *PodMountServiceAccountTokenMutatorPlugin) Mutator() conversion.PodMutator {
 	return func(p *conversion.PodMutateCtx) error {
-		if pl.disable {
-			p.PPod.Spec.AutomountServiceAccountToken = pointer.BoolPtr(false)
+		if !pl.disable {
+			// The tenant pod setting is kept, pods not specifying it fall back to the
+			// automountServiceAccountToken of the synced service account.
+			return nil
 		}
+		automount := p.VPod.Spec.AutomountServiceAccountToken
+		if pl.allowAutomount && automount != nil && *automount {
+			p.PPod.Spec.AutomountServiceAccountToken = pointer.BoolPtr(true)
+			return nil
+		}
+		p.PPod.Spec.AutomountServiceAccountToken = pointer.BoolPtr(false)
 		return nil
 	}
 }
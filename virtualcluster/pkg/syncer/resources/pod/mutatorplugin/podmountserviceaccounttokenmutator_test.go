@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func TestPodMountServiceAccountTokenMutatorPlugin_Mutator(t *testing.T) {
+	withAutomount := func(automount *bool) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Spec.AutomountServiceAccountToken = automount
+		}
+	}
+
+	tests := []struct {
+		name           string
+		disable        bool
+		allowAutomount bool
+		automount      *bool
+		want           *bool
+	}{
+		{
+			name:    "token enabled, pod unset falls back to the service account",
+			disable: false,
+			want:    nil,
+		},
+		{
+			name:      "token enabled, pod requests token",
+			disable:   false,
+			automount: pointer.BoolPtr(true),
+			want:      pointer.BoolPtr(true),
+		},
+		{
+			name:      "token enabled, pod opts out",
+			disable:   false,
+			automount: pointer.BoolPtr(false),
+			want:      pointer.BoolPtr(false),
+		},
+		{
+			name:    "token disabled, pod unset",
+			disable: true,
+			want:    pointer.BoolPtr(false),
+		},
+		{
+			name:      "token disabled, pod requests token",
+			disable:   true,
+			automount: pointer.BoolPtr(true),
+			want:      pointer.BoolPtr(false),
+		},
+		{
+			name:      "token disabled, pod opts out",
+			disable:   true,
+			automount: pointer.BoolPtr(false),
+			want:      pointer.BoolPtr(false),
+		},
+		{
+			name:           "token disabled with override, pod unset",
+			disable:        true,
+			allowAutomount: true,
+			want:           pointer.BoolPtr(false),
+		},
+		{
+			name:           "token disabled with override, pod requests token",
+			disable:        true,
+			allowAutomount: true,
+			automount:      pointer.BoolPtr(true),
+			want:           pointer.BoolPtr(true),
+		},
+		{
+			name:           "token disabled with override, pod opts out",
+			disable:        true,
+			allowAutomount: true,
+			automount:      pointer.BoolPtr(false),
+			want:           pointer.BoolPtr(false),
+		},
+		{
+			name:           "token enabled with override, pod unset falls back to the service account",
+			disable:        false,
+			allowAutomount: true,
+			want:           nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := &PodMountServiceAccountTokenMutatorPlugin{disable: tt.disable, allowAutomount: tt.allowAutomount}
+			mutator := pl.Mutator()
+
+			vPod := tenantPod("test", "default", "123-456-789", withAutomount(tt.automount))
+			pPod := vPod.DeepCopy()
+			if err := mutator(&conversion.PodMutateCtx{PPod: pPod, VPod: vPod}); err != nil {
+				t.Errorf("mutator failed processing the pod")
+			}
+
+			got := pPod.Spec.AutomountServiceAccountToken
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("pPod.Spec.AutomountServiceAccountToken = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
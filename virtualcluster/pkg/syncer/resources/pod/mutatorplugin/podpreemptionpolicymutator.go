@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	uplugin "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	MutatorRegister.Register(&uplugin.Registration{
+		ID: "00_PodPreemptionPolicyMutator",
+		InitFn: func(ctx *uplugin.InitContext) (interface{}, error) {
+			return NewPodPreemptionPolicyMutatorPlugin(ctx.Config.(*config.SyncerConfiguration).ForcePodNonPreempting), nil
+		},
+	})
+}
+
+type PodPreemptionPolicyMutatorPlugin struct {
+	forceNonPreempting bool
+}
+
+// NewPodPreemptionPolicyMutatorPlugin creates the plugin, which does nothing unless forceNonPreempting is set.
+func NewPodPreemptionPolicyMutatorPlugin(forceNonPreempting bool) *PodPreemptionPolicyMutatorPlugin {
+	return &PodPreemptionPolicyMutatorPlugin{forceNonPreempting: forceNonPreempting}
+}
+
+// Mutator sets the preemptionPolicy of the super pod to Never, so that tenant pods never preempt the
+// pods of other tenants. Otherwise the preemptionPolicy of the tenant pod is synced unchanged by the
+// generic conversion.
+func (pl *PodPreemptionPolicyMutatorPlugin) Mutator() conversion.PodMutator {
+	return func(p *conversion.PodMutateCtx) error {
+		if !pl.forceNonPreempting {
+			return nil
+		}
+		never := corev1.PreemptNever
+		p.PPod.Spec.PreemptionPolicy = &never
+		return nil
+	}
+}
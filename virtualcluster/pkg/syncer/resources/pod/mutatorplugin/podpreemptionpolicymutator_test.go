@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func TestPodPreemptionPolicyMutatorPlugin_Mutator(t *testing.T) {
+	withPreemptionPolicy := func(policy corev1.PreemptionPolicy) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Spec.PreemptionPolicy = &policy
+		}
+	}
+	policy := func(policy corev1.PreemptionPolicy) *corev1.PreemptionPolicy {
+		return &policy
+	}
+
+	tests := []struct {
+		name  string
+		force bool
+		vPod  *corev1.Pod
+		want  *corev1.PreemptionPolicy
+	}{
+		{
+			name:  "unset is kept",
+			force: false,
+			vPod:  tenantPod("test", "default", "123-456-789"),
+			want:  nil,
+		},
+		{
+			name:  "Never is kept",
+			force: false,
+			vPod:  tenantPod("test", "default", "123-456-789", withPreemptionPolicy(corev1.PreemptNever)),
+			want:  policy(corev1.PreemptNever),
+		},
+		{
+			name:  "PreemptLowerPriority is kept",
+			force: false,
+			vPod:  tenantPod("test", "default", "123-456-789", withPreemptionPolicy(corev1.PreemptLowerPriority)),
+			want:  policy(corev1.PreemptLowerPriority),
+		},
+		{
+			name:  "unset is forced",
+			force: true,
+			vPod:  tenantPod("test", "default", "123-456-789"),
+			want:  policy(corev1.PreemptNever),
+		},
+		{
+			name:  "PreemptLowerPriority is forced",
+			force: true,
+			vPod:  tenantPod("test", "default", "123-456-789", withPreemptionPolicy(corev1.PreemptLowerPriority)),
+			want:  policy(corev1.PreemptNever),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutator := NewPodPreemptionPolicyMutatorPlugin(tt.force).Mutator()
+
+			pPod := tt.vPod.DeepCopy()
+			if err := mutator(&conversion.PodMutateCtx{PPod: pPod, VPod: tt.vPod}); err != nil {
+				t.Errorf("mutator failed processing the pod")
+			}
+
+			if !equality.Semantic.DeepEqual(pPod.Spec.PreemptionPolicy, tt.want) {
+				t.Errorf("pPod.Spec.PreemptionPolicy = %v, want %v", pPod.Spec.PreemptionPolicy, tt.want)
+			}
+			if tt.vPod.Spec.PreemptionPolicy != nil && pPod.Spec.PreemptionPolicy == tt.vPod.Spec.PreemptionPolicy {
+				t.Errorf("expected the tenant pod not to be modified")
+			}
+		})
+	}
+}
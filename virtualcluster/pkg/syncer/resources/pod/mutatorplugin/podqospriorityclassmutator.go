@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	uplugin "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	MutatorRegister.Register(&uplugin.Registration{
+		ID: "00_PodQoSPriorityClassMutator",
+		InitFn: func(ctx *uplugin.InitContext) (interface{}, error) {
+			syncerConfig := ctx.Config.(*config.SyncerConfiguration)
+			return NewPodQoSPriorityClassMutatorPlugin(syncerConfig.QoSToPriorityClass, syncerConfig.ForcePodNonPreempting), nil
+		},
+	})
+}
+
+type PodQoSPriorityClassMutatorPlugin struct {
+	qosToPriorityClass map[string]string
+	forceNonPreempting bool
+}
+
+// NewPodQoSPriorityClassMutatorPlugin creates the plugin, which does nothing unless qosToPriorityClass
+// maps some QoS classes.
+func NewPodQoSPriorityClassMutatorPlugin(qosToPriorityClass map[string]string, forceNonPreempting bool) *PodQoSPriorityClassMutatorPlugin {
+	return &PodQoSPriorityClassMutatorPlugin{qosToPriorityClass: qosToPriorityClass, forceNonPreempting: forceNonPreempting}
+}
+
+// Mutator sets the priorityClassName of the super pod to the priority class of the QoS class of the
+// tenant pod, as computed by the tenant apiserver. The priority and preemptionPolicy of the tenant
+// pod, resolved from its tenant priority class, are cleared for the super cluster Priority admission
+// to resolve them from the new class, except for the preemptionPolicy forced to Never by
+// ForcePodNonPreempting.
+func (pl *PodQoSPriorityClassMutatorPlugin) Mutator() conversion.PodMutator {
+	return func(p *conversion.PodMutateCtx) error {
+		priorityClassName, found := pl.qosToPriorityClass[string(p.VPod.Status.QOSClass)]
+		if !found {
+			return nil
+		}
+		p.PPod.Spec.PriorityClassName = priorityClassName
+		p.PPod.Spec.Priority = nil
+		p.PPod.Spec.PreemptionPolicy = nil
+		if pl.forceNonPreempting {
+			never := corev1.PreemptNever
+			p.PPod.Spec.PreemptionPolicy = &never
+		}
+		return nil
+	}
+}
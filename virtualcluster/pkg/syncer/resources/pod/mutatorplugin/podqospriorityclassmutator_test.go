@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func TestPodQoSPriorityClassMutatorPlugin_Mutator(t *testing.T) {
+	withQoS := func(qos corev1.PodQOSClass) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Status.QOSClass = qos
+		}
+	}
+	withPriorityClass := func(p *corev1.Pod) {
+		lowerPriority := corev1.PreemptLowerPriority
+		p.Spec.PriorityClassName = "tenant-priority"
+		p.Spec.Priority = pointer.Int32Ptr(100)
+		p.Spec.PreemptionPolicy = &lowerPriority
+	}
+	policy := func(policy corev1.PreemptionPolicy) *corev1.PreemptionPolicy {
+		return &policy
+	}
+	qosToPriorityClass := map[string]string{
+		"Guaranteed": "high-priority",
+		"Burstable":  "medium-priority",
+		"BestEffort": "low-priority",
+	}
+
+	tests := []struct {
+		name                 string
+		qosToPriorityClass   map[string]string
+		force                bool
+		vPod                 *corev1.Pod
+		wantPriorityClass    string
+		wantPriority         *int32
+		wantPreemptionPolicy *corev1.PreemptionPolicy
+	}{
+		{
+			name:              "Guaranteed",
+			vPod:              tenantPod("test", "default", "123-456-789", withQoS(corev1.PodQOSGuaranteed)),
+			wantPriorityClass: "high-priority",
+		},
+		{
+			name:              "Burstable",
+			vPod:              tenantPod("test", "default", "123-456-789", withQoS(corev1.PodQOSBurstable)),
+			wantPriorityClass: "medium-priority",
+		},
+		{
+			name:              "BestEffort",
+			vPod:              tenantPod("test", "default", "123-456-789", withQoS(corev1.PodQOSBestEffort)),
+			wantPriorityClass: "low-priority",
+		},
+		{
+			name:              "tenant priority class is replaced",
+			vPod:              tenantPod("test", "default", "123-456-789", withQoS(corev1.PodQOSGuaranteed), withPriorityClass),
+			wantPriorityClass: "high-priority",
+		},
+		{
+			name:                 "preemption policy forced to Never is kept",
+			force:                true,
+			vPod:                 tenantPod("test", "default", "123-456-789", withQoS(corev1.PodQOSGuaranteed), withPriorityClass),
+			wantPriorityClass:    "high-priority",
+			wantPreemptionPolicy: policy(corev1.PreemptNever),
+		},
+		{
+			name:                 "unmapped QoS class keeps the tenant priority class",
+			qosToPriorityClass:   map[string]string{"Guaranteed": "high-priority"},
+			vPod:                 tenantPod("test", "default", "123-456-789", withQoS(corev1.PodQOSBestEffort), withPriorityClass),
+			wantPriorityClass:    "tenant-priority",
+			wantPriority:         pointer.Int32Ptr(100),
+			wantPreemptionPolicy: policy(corev1.PreemptLowerPriority),
+		},
+		{
+			name:                 "unknown QoS class keeps the tenant priority class",
+			vPod:                 tenantPod("test", "default", "123-456-789", withPriorityClass),
+			wantPriorityClass:    "tenant-priority",
+			wantPriority:         pointer.Int32Ptr(100),
+			wantPreemptionPolicy: policy(corev1.PreemptLowerPriority),
+		},
+		{
+			name:                 "no mapping",
+			qosToPriorityClass:   map[string]string{},
+			vPod:                 tenantPod("test", "default", "123-456-789", withQoS(corev1.PodQOSGuaranteed), withPriorityClass),
+			wantPriorityClass:    "tenant-priority",
+			wantPriority:         pointer.Int32Ptr(100),
+			wantPreemptionPolicy: policy(corev1.PreemptLowerPriority),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping := qosToPriorityClass
+			if tt.qosToPriorityClass != nil {
+				mapping = tt.qosToPriorityClass
+			}
+			mutator := NewPodQoSPriorityClassMutatorPlugin(mapping, tt.force).Mutator()
+
+			pPod := tt.vPod.DeepCopy()
+			if err := mutator(&conversion.PodMutateCtx{PPod: pPod, VPod: tt.vPod}); err != nil {
+				t.Errorf("mutator failed processing the pod")
+			}
+
+			if pPod.Spec.PriorityClassName != tt.wantPriorityClass {
+				t.Errorf("pPod.Spec.PriorityClassName = %q, want %q", pPod.Spec.PriorityClassName, tt.wantPriorityClass)
+			}
+			if !equality.Semantic.DeepEqual(pPod.Spec.Priority, tt.wantPriority) {
+				t.Errorf("pPod.Spec.Priority = %v, want %v", pPod.Spec.Priority, tt.wantPriority)
+			}
+			if !equality.Semantic.DeepEqual(pPod.Spec.PreemptionPolicy, tt.wantPreemptionPolicy) {
+				t.Errorf("pPod.Spec.PreemptionPolicy = %v, want %v", pPod.Spec.PreemptionPolicy, tt.wantPreemptionPolicy)
+			}
+			if tt.vPod.Spec.PriorityClassName != "" && tt.vPod.Spec.PriorityClassName != "tenant-priority" {
+				t.Errorf("expected the tenant pod not to be modified")
+			}
+		})
+	}
+}
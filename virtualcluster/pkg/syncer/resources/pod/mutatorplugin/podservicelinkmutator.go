@@ -17,9 +17,14 @@ limitations under the License.
 package mutatorplugin
 
 import (
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	uplugin "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
@@ -37,11 +42,50 @@ type PodServiceLinkMutatorPlugin struct {
 	disable bool
 }
 
+// Mutator disables service links of the super pod according to, in order of precedence,
+// * the constants.TenantDisablePodServiceLinks annotation of the tenant pod
+// * the constants.TenantDisablePodServiceLinks annotation of the tenant namespace
+// * the global DisablePodServiceLinks setting
+// When service links are not disabled the tenant's enableServiceLinks is kept as is.
 func (pl *PodServiceLinkMutatorPlugin) Mutator() conversion.PodMutator {
 	return func(p *conversion.PodMutateCtx) error {
-		if pl.disable {
+		if pl.disableServiceLinks(p) {
 			p.PPod.Spec.EnableServiceLinks = pointer.BoolPtr(false)
 		}
 		return nil
 	}
 }
+
+func (pl *PodServiceLinkMutatorPlugin) disableServiceLinks(p *conversion.PodMutateCtx) bool {
+	if p.VPod == nil {
+		return pl.disable
+	}
+
+	if disable, ok := parseDisablePodServiceLinks(p.VPod.GetAnnotations(), "pod", p.VPod.Namespace+"/"+p.VPod.Name); ok {
+		return disable
+	}
+
+	if p.Mc != nil {
+		vNamespace := &corev1.Namespace{}
+		if err := p.Mc.Get(p.ClusterName, "", p.VPod.Namespace, vNamespace); err != nil {
+			klog.Warningf("failed to get namespace %s in cluster %s, fall back to the global service links setting: %v", p.VPod.Namespace, p.ClusterName, err)
+		} else if disable, ok := parseDisablePodServiceLinks(vNamespace.GetAnnotations(), "namespace", vNamespace.Name); ok {
+			return disable
+		}
+	}
+
+	return pl.disable
+}
+
+func parseDisablePodServiceLinks(annotations map[string]string, kind, name string) (bool, bool) {
+	v, ok := annotations[constants.TenantDisablePodServiceLinks]
+	if !ok {
+		return false, false
+	}
+	disable, err := strconv.ParseBool(v)
+	if err != nil {
+		klog.Warningf("ignore invalid %s annotation %q on %s %s", constants.TenantDisablePodServiceLinks, v, kind, name)
+		return false, false
+	}
+	return disable, true
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func TestPodServiceLinkMutatorPlugin_Mutator(t *testing.T) {
+	withAnnotation := func(value string) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Annotations = map[string]string{constants.TenantDisablePodServiceLinks: value}
+		}
+	}
+
+	tests := []struct {
+		name    string
+		disable bool
+		vPod    *corev1.Pod
+		want    *bool
+	}{
+		{
+			name:    "global disable",
+			disable: true,
+			vPod:    tenantPod("test", "default", "123-456-789"),
+			want:    pointer.BoolPtr(false),
+		},
+		{
+			name:    "global enable",
+			disable: false,
+			vPod:    tenantPod("test", "default", "123-456-789"),
+			want:    nil,
+		},
+		{
+			name:    "pod annotation enables service links against global disable",
+			disable: true,
+			vPod:    tenantPod("test", "default", "123-456-789", withAnnotation("false")),
+			want:    nil,
+		},
+		{
+			name:    "pod annotation disables service links against global enable",
+			disable: false,
+			vPod:    tenantPod("test", "default", "123-456-789", withAnnotation("true")),
+			want:    pointer.BoolPtr(false),
+		},
+		{
+			name:    "pod annotation keeps tenant enableServiceLinks",
+			disable: true,
+			vPod: tenantPod("test", "default", "123-456-789", withAnnotation("false"), func(p *corev1.Pod) {
+				p.Spec.EnableServiceLinks = pointer.BoolPtr(true)
+			}),
+			want: pointer.BoolPtr(true),
+		},
+		{
+			name:    "invalid pod annotation falls back to global setting",
+			disable: true,
+			vPod:    tenantPod("test", "default", "123-456-789", withAnnotation("maybe")),
+			want:    pointer.BoolPtr(false),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pl := &PodServiceLinkMutatorPlugin{disable: tt.disable}
+			mutator := pl.Mutator()
+
+			pPod := tt.vPod.DeepCopy()
+			if err := mutator(&conversion.PodMutateCtx{PPod: pPod, VPod: tt.vPod}); err != nil {
+				t.Errorf("mutator failed processing the pod")
+			}
+
+			got := pPod.Spec.EnableServiceLinks
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("pPod.Spec.EnableServiceLinks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	uplugin "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+const (
+	// defaultProbePeriodSeconds and defaultProbeFailureThreshold are the API defaults of a probe.
+	defaultProbePeriodSeconds    = 10
+	defaultProbeFailureThreshold = 3
+)
+
+func init() {
+	MutatorRegister.Register(&uplugin.Registration{
+		ID: "00_PodStartupProbeMutator",
+		InitFn: func(ctx *uplugin.InitContext) (interface{}, error) {
+			syncerConfig := ctx.Config.(*config.SyncerConfiguration)
+			return NewPodStartupProbeMutatorPlugin(syncerConfig.StartupProbePolicy), nil
+		},
+	})
+}
+
+type PodStartupProbeMutatorPlugin struct {
+	policy string
+}
+
+// NewPodStartupProbeMutatorPlugin creates the plugin, which does nothing unless the policy is fold.
+func NewPodStartupProbeMutatorPlugin(policy string) *PodStartupProbeMutatorPlugin {
+	return &PodStartupProbeMutatorPlugin{policy: policy}
+}
+
+// Mutator removes the startup probes of the super pod with the fold policy. The kubelet does not run
+// the liveness probe of a container until its startup probe succeeds, so the liveness probe is
+// delayed by the longest time the startup probe may take to succeed, keeping the failureThreshold
+// of the startup probe as the time budget of a slow start. Without a liveness probe, the startup
+// probe is only dropped.
+func (pl *PodStartupProbeMutatorPlugin) Mutator() conversion.PodMutator {
+	return func(p *conversion.PodMutateCtx) error {
+		if pl.policy != constants.StartupProbePolicyFold {
+			return nil
+		}
+		for i := range p.PPod.Spec.Containers {
+			container := &p.PPod.Spec.Containers[i]
+			if container.StartupProbe == nil {
+				continue
+			}
+			if container.LivenessProbe != nil {
+				if delay := startupProbeBudgetSeconds(container.StartupProbe); container.LivenessProbe.InitialDelaySeconds < delay {
+					container.LivenessProbe.InitialDelaySeconds = delay
+				}
+			}
+			container.StartupProbe = nil
+		}
+		return nil
+	}
+}
+
+// startupProbeBudgetSeconds returns how long a startup probe may keep failing before the kubelet
+// restarts the container.
+func startupProbeBudgetSeconds(probe *corev1.Probe) int32 {
+	period, failureThreshold := probe.PeriodSeconds, probe.FailureThreshold
+	if period <= 0 {
+		period = defaultProbePeriodSeconds
+	}
+	if failureThreshold <= 0 {
+		failureThreshold = defaultProbeFailureThreshold
+	}
+	return probe.InitialDelaySeconds + failureThreshold*period
+}
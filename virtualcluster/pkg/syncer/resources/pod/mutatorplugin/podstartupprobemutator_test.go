@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func TestPodStartupProbeMutatorPlugin_Mutator(t *testing.T) {
+	probe := func(initialDelaySeconds, periodSeconds, failureThreshold int32) *corev1.Probe {
+		return &corev1.Probe{
+			Handler: corev1.Handler{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)},
+			},
+			InitialDelaySeconds: initialDelaySeconds,
+			PeriodSeconds:       periodSeconds,
+			FailureThreshold:    failureThreshold,
+		}
+	}
+	withProbes := func(liveness, startup *corev1.Probe) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Spec.Containers[0].LivenessProbe = liveness
+			p.Spec.Containers[0].StartupProbe = startup
+		}
+	}
+
+	tests := []struct {
+		name         string
+		policy       string
+		vPod         *corev1.Pod
+		wantLiveness *corev1.Probe
+		wantStartup  *corev1.Probe
+	}{
+		{
+			name:         "keep",
+			policy:       constants.StartupProbePolicyKeep,
+			vPod:         tenantPod("test", "default", "123-456-789", withProbes(probe(0, 10, 3), probe(5, 10, 30))),
+			wantLiveness: probe(0, 10, 3),
+			wantStartup:  probe(5, 10, 30),
+		},
+		{
+			name:         "unknown policy keeps the startup probe",
+			policy:       "unknown",
+			vPod:         tenantPod("test", "default", "123-456-789", withProbes(probe(0, 10, 3), probe(5, 10, 30))),
+			wantLiveness: probe(0, 10, 3),
+			wantStartup:  probe(5, 10, 30),
+		},
+		{
+			name:         "fold delays the liveness probe by the startup budget",
+			policy:       constants.StartupProbePolicyFold,
+			vPod:         tenantPod("test", "default", "123-456-789", withProbes(probe(0, 10, 3), probe(5, 10, 30))),
+			wantLiveness: probe(305, 10, 3),
+		},
+		{
+			name:         "fold uses the probe defaults",
+			policy:       constants.StartupProbePolicyFold,
+			vPod:         tenantPod("test", "default", "123-456-789", withProbes(probe(0, 10, 3), probe(0, 0, 0))),
+			wantLiveness: probe(30, 10, 3),
+		},
+		{
+			name:         "fold keeps a longer liveness delay",
+			policy:       constants.StartupProbePolicyFold,
+			vPod:         tenantPod("test", "default", "123-456-789", withProbes(probe(600, 10, 3), probe(5, 10, 30))),
+			wantLiveness: probe(600, 10, 3),
+		},
+		{
+			name:   "fold without liveness probe",
+			policy: constants.StartupProbePolicyFold,
+			vPod:   tenantPod("test", "default", "123-456-789", withProbes(nil, probe(5, 10, 30))),
+		},
+		{
+			name:         "fold without startup probe",
+			policy:       constants.StartupProbePolicyFold,
+			vPod:         tenantPod("test", "default", "123-456-789", withProbes(probe(0, 10, 3), nil)),
+			wantLiveness: probe(0, 10, 3),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutator := NewPodStartupProbeMutatorPlugin(tt.policy).Mutator()
+
+			vPod := tt.vPod.DeepCopy()
+			pPod := tt.vPod.DeepCopy()
+			if err := mutator(&conversion.PodMutateCtx{PPod: pPod, VPod: tt.vPod}); err != nil {
+				t.Errorf("mutator failed processing the pod")
+			}
+
+			container := pPod.Spec.Containers[0]
+			if !equality.Semantic.DeepEqual(container.LivenessProbe, tt.wantLiveness) {
+				t.Errorf("livenessProbe = %v, want %v", container.LivenessProbe, tt.wantLiveness)
+			}
+			if !equality.Semantic.DeepEqual(container.StartupProbe, tt.wantStartup) {
+				t.Errorf("startupProbe = %v, want %v", container.StartupProbe, tt.wantStartup)
+			}
+			if !equality.Semantic.DeepEqual(tt.vPod, vPod) {
+				t.Errorf("expected the tenant pod not to be modified")
+			}
+		})
+	}
+}
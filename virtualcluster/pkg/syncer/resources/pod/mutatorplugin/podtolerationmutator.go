@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	uplugin "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	MutatorRegister.Register(&uplugin.Registration{
+		ID: "00_PodDefaultTolerationSecondsMutator",
+		InitFn: func(ctx *uplugin.InitContext) (interface{}, error) {
+			cfg := ctx.Config.(*config.SyncerConfiguration)
+			return NewPodDefaultTolerationSecondsMutatorPlugin(cfg.DefaultNotReadyTolerationSeconds, cfg.DefaultUnreachableTolerationSeconds), nil
+		},
+	})
+}
+
+type PodDefaultTolerationSecondsMutatorPlugin struct {
+	notReadySeconds    int64
+	unreachableSeconds int64
+}
+
+// NewPodDefaultTolerationSecondsMutatorPlugin creates the plugin, 0 seconds disables the
+// corresponding default toleration.
+func NewPodDefaultTolerationSecondsMutatorPlugin(notReadySeconds, unreachableSeconds int64) *PodDefaultTolerationSecondsMutatorPlugin {
+	return &PodDefaultTolerationSecondsMutatorPlugin{notReadySeconds: notReadySeconds, unreachableSeconds: unreachableSeconds}
+}
+
+// Mutator adds the node.kubernetes.io/not-ready and node.kubernetes.io/unreachable NoExecute
+// tolerations to the super pod, the same way the DefaultTolerationSeconds admission plugin does,
+// unless the tenant pod already tolerates the taint.
+func (pl *PodDefaultTolerationSecondsMutatorPlugin) Mutator() conversion.PodMutator {
+	return func(p *conversion.PodMutateCtx) error {
+		if pl.notReadySeconds > 0 && !toleratesNoExecuteTaint(p.PPod.Spec.Tolerations, corev1.TaintNodeNotReady) {
+			p.PPod.Spec.Tolerations = append(p.PPod.Spec.Tolerations, noExecuteToleration(corev1.TaintNodeNotReady, pl.notReadySeconds))
+		}
+		if pl.unreachableSeconds > 0 && !toleratesNoExecuteTaint(p.PPod.Spec.Tolerations, corev1.TaintNodeUnreachable) {
+			p.PPod.Spec.Tolerations = append(p.PPod.Spec.Tolerations, noExecuteToleration(corev1.TaintNodeUnreachable, pl.unreachableSeconds))
+		}
+		return nil
+	}
+}
+
+// toleratesNoExecuteTaint returns true if a toleration matches the key, or all keys, with the
+// NoExecute effect, or all effects.
+func toleratesNoExecuteTaint(tolerations []corev1.Toleration, key string) bool {
+	for _, t := range tolerations {
+		if (t.Key == key || len(t.Key) == 0) && (t.Effect == corev1.TaintEffectNoExecute || len(t.Effect) == 0) {
+			return true
+		}
+	}
+	return false
+}
+
+func noExecuteToleration(key string, seconds int64) corev1.Toleration {
+	return corev1.Toleration{
+		Key:               key,
+		Operator:          corev1.TolerationOpExists,
+		Effect:            corev1.TaintEffectNoExecute,
+		TolerationSeconds: &seconds,
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func TestPodDefaultTolerationSecondsMutatorPlugin_Mutator(t *testing.T) {
+	withTolerations := func(tolerations ...corev1.Toleration) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Spec.Tolerations = tolerations
+		}
+	}
+	toleration := func(key string, effect corev1.TaintEffect, seconds *int64) corev1.Toleration {
+		return corev1.Toleration{Key: key, Operator: corev1.TolerationOpExists, Effect: effect, TolerationSeconds: seconds}
+	}
+
+	tests := []struct {
+		name               string
+		notReadySeconds    int64
+		unreachableSeconds int64
+		vPod               *corev1.Pod
+		want               []corev1.Toleration
+	}{
+		{
+			name:               "disabled",
+			notReadySeconds:    0,
+			unreachableSeconds: 0,
+			vPod:               tenantPod("test", "default", "123-456-789"),
+			want:               nil,
+		},
+		{
+			name:               "defaults are injected",
+			notReadySeconds:    60,
+			unreachableSeconds: 120,
+			vPod:               tenantPod("test", "default", "123-456-789"),
+			want: []corev1.Toleration{
+				toleration(corev1.TaintNodeNotReady, corev1.TaintEffectNoExecute, pointer.Int64Ptr(60)),
+				toleration(corev1.TaintNodeUnreachable, corev1.TaintEffectNoExecute, pointer.Int64Ptr(120)),
+			},
+		},
+		{
+			name:               "only not-ready is injected",
+			notReadySeconds:    30,
+			unreachableSeconds: 0,
+			vPod:               tenantPod("test", "default", "123-456-789"),
+			want: []corev1.Toleration{
+				toleration(corev1.TaintNodeNotReady, corev1.TaintEffectNoExecute, pointer.Int64Ptr(30)),
+			},
+		},
+		{
+			name:               "tenant tolerations are respected",
+			notReadySeconds:    60,
+			unreachableSeconds: 120,
+			vPod: tenantPod("test", "default", "123-456-789", withTolerations(
+				toleration(corev1.TaintNodeNotReady, corev1.TaintEffectNoExecute, pointer.Int64Ptr(5)),
+				toleration(corev1.TaintNodeUnreachable, "", nil),
+			)),
+			want: []corev1.Toleration{
+				toleration(corev1.TaintNodeNotReady, corev1.TaintEffectNoExecute, pointer.Int64Ptr(5)),
+				toleration(corev1.TaintNodeUnreachable, "", nil),
+			},
+		},
+		{
+			name:               "tenant toleration of all taints is respected",
+			notReadySeconds:    60,
+			unreachableSeconds: 120,
+			vPod: tenantPod("test", "default", "123-456-789", withTolerations(
+				toleration("", "", nil),
+			)),
+			want: []corev1.Toleration{
+				toleration("", "", nil),
+			},
+		},
+		{
+			name:               "tenant NoSchedule toleration does not count",
+			notReadySeconds:    60,
+			unreachableSeconds: 0,
+			vPod: tenantPod("test", "default", "123-456-789", withTolerations(
+				toleration(corev1.TaintNodeNotReady, corev1.TaintEffectNoSchedule, nil),
+			)),
+			want: []corev1.Toleration{
+				toleration(corev1.TaintNodeNotReady, corev1.TaintEffectNoSchedule, nil),
+				toleration(corev1.TaintNodeNotReady, corev1.TaintEffectNoExecute, pointer.Int64Ptr(60)),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutator := NewPodDefaultTolerationSecondsMutatorPlugin(tt.notReadySeconds, tt.unreachableSeconds).Mutator()
+
+			pPod := tt.vPod.DeepCopy()
+			if err := mutator(&conversion.PodMutateCtx{PPod: pPod, VPod: tt.vPod}); err != nil {
+				t.Errorf("mutator failed processing the pod")
+			}
+
+			if !equality.Semantic.DeepEqual(pPod.Spec.Tolerations, tt.want) {
+				t.Errorf("pPod.Spec.Tolerations = %v, want %v", pPod.Spec.Tolerations, tt.want)
+			}
+		})
+	}
+}
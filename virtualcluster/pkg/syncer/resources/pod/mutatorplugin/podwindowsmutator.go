@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	uplugin "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	MutatorRegister.Register(&uplugin.Registration{
+		ID: "00_PodWindowsSecurityContextMutator",
+		InitFn: func(ctx *uplugin.InitContext) (interface{}, error) {
+			return NewPodWindowsSecurityContextMutatorPlugin(ctx.Config.(*config.SyncerConfiguration).DefaultWindowsRunAsUserName), nil
+		},
+	})
+}
+
+type PodWindowsSecurityContextMutatorPlugin struct {
+	defaultRunAsUserName string
+}
+
+// NewPodWindowsSecurityContextMutatorPlugin creates the plugin, an empty user name disables the default.
+func NewPodWindowsSecurityContextMutatorPlugin(defaultRunAsUserName string) *PodWindowsSecurityContextMutatorPlugin {
+	return &PodWindowsSecurityContextMutatorPlugin{defaultRunAsUserName: defaultRunAsUserName}
+}
+
+// Mutator sets the default windowsOptions.runAsUserName on the pod securityContext of Windows pods
+// that do not run as a specific user. The Windows options of the tenant pod, like the Linux ones,
+// are synced unchanged by the generic conversion.
+func (pl *PodWindowsSecurityContextMutatorPlugin) Mutator() conversion.PodMutator {
+	return func(p *conversion.PodMutateCtx) error {
+		if pl.defaultRunAsUserName == "" || !isWindowsPod(p.PPod) || hasWindowsRunAsUserName(p.PPod) {
+			return nil
+		}
+		if p.PPod.Spec.SecurityContext == nil {
+			p.PPod.Spec.SecurityContext = &corev1.PodSecurityContext{}
+		}
+		if p.PPod.Spec.SecurityContext.WindowsOptions == nil {
+			p.PPod.Spec.SecurityContext.WindowsOptions = &corev1.WindowsSecurityContextOptions{}
+		}
+		userName := pl.defaultRunAsUserName
+		p.PPod.Spec.SecurityContext.WindowsOptions.RunAsUserName = &userName
+		return nil
+	}
+}
+
+// isWindowsPod returns true if the pod selects Windows nodes or has Windows security options.
+func isWindowsPod(pod *corev1.Pod) bool {
+	if pod.Spec.NodeSelector[corev1.LabelOSStable] == "windows" {
+		return true
+	}
+	if pod.Spec.SecurityContext != nil && pod.Spec.SecurityContext.WindowsOptions != nil {
+		return true
+	}
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, c := range containers {
+			if c.SecurityContext != nil && c.SecurityContext.WindowsOptions != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasWindowsRunAsUserName returns true if the pod or one of its containers sets runAsUserName.
+func hasWindowsRunAsUserName(pod *corev1.Pod) bool {
+	if pod.Spec.SecurityContext != nil && pod.Spec.SecurityContext.WindowsOptions != nil && pod.Spec.SecurityContext.WindowsOptions.RunAsUserName != nil {
+		return true
+	}
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, c := range containers {
+			if c.SecurityContext != nil && c.SecurityContext.WindowsOptions != nil && c.SecurityContext.WindowsOptions.RunAsUserName != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
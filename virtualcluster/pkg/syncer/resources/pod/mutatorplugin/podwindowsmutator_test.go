@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutatorplugin
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+)
+
+func TestPodWindowsSecurityContextMutatorPlugin_Mutator(t *testing.T) {
+	onWindows := func(p *corev1.Pod) {
+		p.Spec.NodeSelector = map[string]string{corev1.LabelOSStable: "windows"}
+	}
+	withPodUser := func(user string) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Spec.SecurityContext = &corev1.PodSecurityContext{WindowsOptions: &corev1.WindowsSecurityContextOptions{RunAsUserName: pointer.StringPtr(user)}}
+		}
+	}
+	withContainerUser := func(user string) func(*corev1.Pod) {
+		return func(p *corev1.Pod) {
+			p.Spec.Containers = []corev1.Container{{Name: "c", SecurityContext: &corev1.SecurityContext{
+				WindowsOptions: &corev1.WindowsSecurityContextOptions{RunAsUserName: pointer.StringPtr(user)},
+			}}}
+		}
+	}
+	podUser := func(user string) *corev1.PodSecurityContext {
+		return &corev1.PodSecurityContext{WindowsOptions: &corev1.WindowsSecurityContextOptions{RunAsUserName: pointer.StringPtr(user)}}
+	}
+
+	tests := []struct {
+		name               string
+		defaultUser        string
+		vPod               *corev1.Pod
+		wantPodSecurity    *corev1.PodSecurityContext
+		wantContainerUsers []string
+	}{
+		{
+			name:            "disabled",
+			defaultUser:     "",
+			vPod:            tenantPod("test", "default", "123-456-789", onWindows),
+			wantPodSecurity: nil,
+		},
+		{
+			name:            "default is injected into windows pods",
+			defaultUser:     "ContainerUser",
+			vPod:            tenantPod("test", "default", "123-456-789", onWindows),
+			wantPodSecurity: podUser("ContainerUser"),
+		},
+		{
+			name:            "linux pods are left alone",
+			defaultUser:     "ContainerUser",
+			vPod:            tenantPod("test", "default", "123-456-789"),
+			wantPodSecurity: nil,
+		},
+		{
+			name:            "pod user is preserved",
+			defaultUser:     "ContainerUser",
+			vPod:            tenantPod("test", "default", "123-456-789", onWindows, withPodUser("ContainerAdministrator")),
+			wantPodSecurity: podUser("ContainerAdministrator"),
+		},
+		{
+			name:               "container user is preserved",
+			defaultUser:        "ContainerUser",
+			vPod:               tenantPod("test", "default", "123-456-789", withContainerUser("ContainerAdministrator")),
+			wantPodSecurity:    nil,
+			wantContainerUsers: []string{"ContainerAdministrator"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mutator := NewPodWindowsSecurityContextMutatorPlugin(tt.defaultUser).Mutator()
+
+			pPod := tt.vPod.DeepCopy()
+			if err := mutator(&conversion.PodMutateCtx{PPod: pPod, VPod: tt.vPod}); err != nil {
+				t.Errorf("mutator failed processing the pod")
+			}
+
+			if !equality.Semantic.DeepEqual(pPod.Spec.SecurityContext, tt.wantPodSecurity) {
+				t.Errorf("pPod.Spec.SecurityContext = %v, want %v", pPod.Spec.SecurityContext, tt.wantPodSecurity)
+			}
+			var containerUsers []string
+			for _, c := range pPod.Spec.Containers {
+				if c.SecurityContext != nil && c.SecurityContext.WindowsOptions != nil && c.SecurityContext.WindowsOptions.RunAsUserName != nil {
+					containerUsers = append(containerUsers, *c.SecurityContext.WindowsOptions.RunAsUserName)
+				}
+			}
+			if !equality.Semantic.DeepEqual(containerUsers, tt.wantContainerUsers) {
+				t.Errorf("container users = %v, want %v", containerUsers, tt.wantContainerUsers)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// podMutator is a stage of the pod mutation pipeline, a mutator plugin or the default conversion.
+type podMutator struct {
+	id string
+	// mutator is nil for the default conversion, which is built for each pod.
+	mutator conversion.PodMutator
+	// disabled mutators only run for the Virtual Clusters enabling them.
+	disabled bool
+}
+
+// orderPodMutators returns the pod mutation pipeline made of the mutator plugins and the default
+// conversion. The stages listed in order come first, in that order. The other mutator plugins
+// follow in the order of their IDs, then the default conversion unless it is listed. The order of
+// the mutations decides the super pod spec when several of them touch the same fields, so it must
+// not depend on anything but the configuration.
+func orderPodMutators(order []string, plugins []podMutator) ([]podMutator, error) {
+	stages := append(append(make([]podMutator, 0, len(plugins)+1), plugins...), podMutator{id: constants.PodMutateDefaultID})
+
+	pipeline := make([]podMutator, 0, len(stages))
+	listed := make(map[string]bool, len(order))
+	for _, id := range order {
+		if listed[id] {
+			return nil, fmt.Errorf("pod mutator %s is listed more than once", id)
+		}
+		found := false
+		for _, s := range stages {
+			if s.id == id {
+				pipeline = append(pipeline, s)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown pod mutator %s", id)
+		}
+		listed[id] = true
+	}
+	for _, s := range stages {
+		if !listed[s.id] {
+			pipeline = append(pipeline, s)
+		}
+	}
+	return pipeline, nil
+}
+
+// disablePodMutators disables the listed mutator plugins of the pipeline by default. The default
+// conversion cannot be disabled.
+func disablePodMutators(pipeline []podMutator, disabled []string) error {
+	for _, id := range disabled {
+		if id == constants.PodMutateDefaultID {
+			return fmt.Errorf("pod mutator %s cannot be disabled", id)
+		}
+		found := false
+		for i := range pipeline {
+			if pipeline[i].id == id {
+				pipeline[i].disabled = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown pod mutator %s", id)
+		}
+	}
+	for _, m := range pipeline {
+		enabled := 1.0
+		if m.disabled {
+			enabled = 0
+		}
+		metrics.PodMutatorEnabled.WithLabelValues(m.id).Set(enabled)
+	}
+	return nil
+}
+
+// podMutatorIDs returns the pod mutator IDs listed, comma separated, by the annotation.
+func podMutatorIDs(annotations map[string]string, key string) sets.String {
+	ids := sets.NewString()
+	for _, id := range strings.Split(annotations[key], ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids.Insert(id)
+		}
+	}
+	return ids
+}
+
+// countingMutator counts the super pods the mutation changed.
+func countingMutator(id string, mutator conversion.PodMutator) conversion.PodMutator {
+	return func(p *conversion.PodMutateCtx) error {
+		before := p.PPod.DeepCopy()
+		if err := mutator(p); err != nil {
+			return err
+		}
+		if !equality.Semantic.DeepEqual(before, p.PPod) {
+			metrics.PodMutatorMutations.WithLabelValues(id).Inc()
+		}
+		return nil
+	}
+}
+
+// podMutationPipeline returns the mutators of the pod mutation pipeline, with defaultMutator as
+// the default conversion of the pod. The annotations of the pod's VirtualCluster enable or disable
+// mutator plugins for its pods. The returned slice is not shared with other reconciles.
+func (c *controller) podMutationPipeline(vcAnnotations map[string]string, defaultMutator conversion.PodMutator) []conversion.PodMutator {
+	return buildPodMutationPipeline(c.podMutators, vcAnnotations, defaultMutator)
+}
+
+// buildPodMutationPipeline returns the mutators of the podMutators pipeline enabled by the
+// annotations of the VirtualCluster, with defaultMutator as the default conversion. The default
+// conversion is left out if defaultMutator is nil.
+func buildPodMutationPipeline(podMutators []podMutator, vcAnnotations map[string]string, defaultMutator conversion.PodMutator) []conversion.PodMutator {
+	enabled := podMutatorIDs(vcAnnotations, constants.LabelEnabledPodMutators)
+	disabled := podMutatorIDs(vcAnnotations, constants.LabelDisabledPodMutators)
+	ms := make([]conversion.PodMutator, 0, len(podMutators))
+	for _, m := range podMutators {
+		if m.mutator == nil {
+			if defaultMutator != nil {
+				ms = append(ms, countingMutator(m.id, defaultMutator))
+			}
+			continue
+		}
+		if disabled.Has(m.id) || (m.disabled && !enabled.Has(m.id)) {
+			continue
+		}
+		ms = append(ms, countingMutator(m.id, m.mutator))
+	}
+	return ms
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// recordingMutator appends id to the containers of the super pod, so that the order in which the
+// mutators ran can be read from the pod.
+func recordingMutator(id string) conversion.PodMutator {
+	return func(p *conversion.PodMutateCtx) error {
+		p.PPod.Spec.Containers = append(p.PPod.Spec.Containers, corev1.Container{Name: id})
+		return nil
+	}
+}
+
+func runPipeline(t *testing.T, ms []conversion.PodMutator) []string {
+	pPod := &corev1.Pod{}
+	if err := (&conversion.PodMutateCtx{PPod: pPod, VPod: &corev1.Pod{}}).Mutate(ms...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var ran []string
+	for _, c := range pPod.Spec.Containers {
+		ran = append(ran, c.Name)
+	}
+	return ran
+}
+
+func TestOrderPodMutators(t *testing.T) {
+	plugins := []podMutator{
+		{id: "00_a", mutator: recordingMutator("00_a")},
+		{id: "00_b", mutator: recordingMutator("00_b")},
+		{id: "01_c", mutator: recordingMutator("01_c")},
+	}
+
+	for _, tt := range []struct {
+		name        string
+		order       []string
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			name:     "default order",
+			expected: []string{"00_a", "00_b", "01_c", constants.PodMutateDefaultID},
+		},
+		{
+			name:     "plugin moved first",
+			order:    []string{"01_c"},
+			expected: []string{"01_c", "00_a", "00_b", constants.PodMutateDefaultID},
+		},
+		{
+			name:     "default conversion first",
+			order:    []string{constants.PodMutateDefaultID, "00_b"},
+			expected: []string{constants.PodMutateDefaultID, "00_b", "00_a", "01_c"},
+		},
+		{
+			name:     "full order",
+			order:    []string{"01_c", constants.PodMutateDefaultID, "00_b", "00_a"},
+			expected: []string{"01_c", constants.PodMutateDefaultID, "00_b", "00_a"},
+		},
+		{
+			name:        "unknown mutator",
+			order:       []string{"00_missing"},
+			expectedErr: true,
+		},
+		{
+			name:        "duplicated mutator",
+			order:       []string{"00_a", "00_a"},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, err := orderPodMutators(tt.order, plugins)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error, got pipeline %v", pipeline)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			c := &controller{podMutators: pipeline}
+			ran := runPipeline(t, c.podMutationPipeline(nil, recordingMutator(constants.PodMutateDefaultID)))
+			if !reflect.DeepEqual(ran, tt.expected) {
+				t.Errorf("expected mutators to run in order %v, got %v", tt.expected, ran)
+			}
+		})
+	}
+}
+
+func TestPodMutationPipelineNotShared(t *testing.T) {
+	pipeline, err := orderPodMutators(nil, []podMutator{{id: "00_a", mutator: recordingMutator("00_a")}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Leave spare capacity, as appending to the plugin mutators used to share it between reconciles.
+	c := &controller{podMutators: append(make([]podMutator, 0, 8), pipeline...)}
+
+	first := c.podMutationPipeline(nil, recordingMutator("pod-1"))
+	second := c.podMutationPipeline(nil, recordingMutator("pod-2"))
+
+	if ran := runPipeline(t, first); !reflect.DeepEqual(ran, []string{"00_a", "pod-1"}) {
+		t.Errorf("expected the first pipeline to run the default conversion of pod-1, got %v", ran)
+	}
+	if ran := runPipeline(t, second); !reflect.DeepEqual(ran, []string{"00_a", "pod-2"}) {
+		t.Errorf("expected the second pipeline to run the default conversion of pod-2, got %v", ran)
+	}
+}
+
+func TestDisablePodMutators(t *testing.T) {
+	plugins := []podMutator{
+		{id: "00_a", mutator: recordingMutator("00_a")},
+		{id: "00_b", mutator: recordingMutator("00_b")},
+		{id: "01_c", mutator: recordingMutator("01_c")},
+	}
+
+	for _, tt := range []struct {
+		name          string
+		disabled      []string
+		vcAnnotations map[string]string
+		expected      []string
+		expectedErr   bool
+	}{
+		{
+			name:     "none disabled",
+			expected: []string{"00_a", "00_b", "01_c", constants.PodMutateDefaultID},
+		},
+		{
+			name:     "disabled",
+			disabled: []string{"00_b"},
+			expected: []string{"00_a", "01_c", constants.PodMutateDefaultID},
+		},
+		{
+			name:          "enabled by the virtual cluster",
+			disabled:      []string{"00_b", "01_c"},
+			vcAnnotations: map[string]string{constants.LabelEnabledPodMutators: "01_c"},
+			expected:      []string{"00_a", "01_c", constants.PodMutateDefaultID},
+		},
+		{
+			name:          "disabled by the virtual cluster",
+			vcAnnotations: map[string]string{constants.LabelDisabledPodMutators: " 00_a, 01_c"},
+			expected:      []string{"00_b", constants.PodMutateDefaultID},
+		},
+		{
+			name:     "disabled by the virtual cluster over enabled",
+			disabled: []string{"00_a"},
+			vcAnnotations: map[string]string{
+				constants.LabelEnabledPodMutators:  "00_a",
+				constants.LabelDisabledPodMutators: "00_a",
+			},
+			expected: []string{"00_b", "01_c", constants.PodMutateDefaultID},
+		},
+		{
+			name:          "default conversion not disabled by the virtual cluster",
+			vcAnnotations: map[string]string{constants.LabelDisabledPodMutators: constants.PodMutateDefaultID},
+			expected:      []string{"00_a", "00_b", "01_c", constants.PodMutateDefaultID},
+		},
+		{
+			name:        "default conversion",
+			disabled:    []string{constants.PodMutateDefaultID},
+			expectedErr: true,
+		},
+		{
+			name:        "unknown mutator",
+			disabled:    []string{"00_missing"},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline, err := orderPodMutators(nil, plugins)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err = disablePodMutators(pipeline, tt.disabled)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			c := &controller{podMutators: pipeline}
+			ran := runPipeline(t, c.podMutationPipeline(tt.vcAnnotations, recordingMutator(constants.PodMutateDefaultID)))
+			if !reflect.DeepEqual(ran, tt.expected) {
+				t.Errorf("expected mutators %v to run, got %v", tt.expected, ran)
+			}
+		})
+	}
+}
+
+func TestPodMutationPipelineMetrics(t *testing.T) {
+	noop := func(p *conversion.PodMutateCtx) error { return nil }
+	pipeline, err := orderPodMutators(nil, []podMutator{
+		{id: "00_metrics_changing", mutator: recordingMutator("00_metrics_changing")},
+		{id: "00_metrics_noop", mutator: noop},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := disablePodMutators(pipeline, []string{"00_metrics_noop"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enabled := testutil.ToFloat64(metrics.PodMutatorEnabled.WithLabelValues("00_metrics_changing")); enabled != 1 {
+		t.Errorf("expected 00_metrics_changing to be enabled, got %v", enabled)
+	}
+	if enabled := testutil.ToFloat64(metrics.PodMutatorEnabled.WithLabelValues("00_metrics_noop")); enabled != 0 {
+		t.Errorf("expected 00_metrics_noop to be disabled, got %v", enabled)
+	}
+
+	changing := testutil.ToFloat64(metrics.PodMutatorMutations.WithLabelValues("00_metrics_changing"))
+	unchanged := testutil.ToFloat64(metrics.PodMutatorMutations.WithLabelValues("00_metrics_noop"))
+	c := &controller{podMutators: pipeline}
+	runPipeline(t, c.podMutationPipeline(map[string]string{constants.LabelEnabledPodMutators: "00_metrics_noop"}, noop))
+	runPipeline(t, c.podMutationPipeline(nil, noop))
+
+	if got := testutil.ToFloat64(metrics.PodMutatorMutations.WithLabelValues("00_metrics_changing")) - changing; got != 2 {
+		t.Errorf("expected 00_metrics_changing to change 2 pods, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.PodMutatorMutations.WithLabelValues("00_metrics_noop")) - unchanged; got != 0 {
+		t.Errorf("expected 00_metrics_noop to change no pod, got %v", got)
+	}
+}
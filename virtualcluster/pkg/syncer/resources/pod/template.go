@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/pod/validationplugin"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+// TemplateChecker applies the checks and the mutator plugins of the tenant pods to the pod templates
+// of the tenant workloads synced to the super cluster. The super cluster controller-manager creates
+// the pods of these workloads from their super pod templates, so the pods never go through the pod
+// syncer.
+type TemplateChecker struct {
+	config      *config.SyncerConfiguration
+	mc          *mc.MultiClusterController
+	plugin      validationplugin.Interface
+	podMutators []podMutator
+}
+
+// NewTemplateChecker returns the TemplateChecker of the workloads synced by mccontroller.
+func NewTemplateChecker(mccontroller *mc.MultiClusterController, initContext *plugin.InitContext, isFake bool) (*TemplateChecker, error) {
+	validation, err := newValidationPlugin(mccontroller, isFake)
+	if err != nil {
+		return nil, err
+	}
+	podMutators, err := newPodMutators(initContext)
+	if err != nil {
+		return nil, err
+	}
+	return &TemplateChecker{
+		config:      initContext.Config.(*config.SyncerConfiguration),
+		mc:          mccontroller,
+		plugin:      validation,
+		podMutators: podMutators,
+	}, nil
+}
+
+// CheckTemplate checks the pod template of the tenant workload namespace/name like a tenant pod, and
+// mutates the accepted template like the spec of a super pod. It returns the rejection of the
+// template, if any, in which case the workload must not be synced.
+func (t *TemplateChecker) CheckTemplate(vc *v1alpha1.VirtualCluster, clusterName, namespace, name string, template *corev1.PodTemplateSpec) (*Rejection, error) {
+	if rejection := checkPodSpec(t.config, vc, "pod template", &template.Spec); rejection != nil {
+		return rejection, nil
+	}
+
+	// the mutators work on pods, the template is mutated as the tenant and super pod of the workload.
+	vPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+		},
+		Spec: template.Spec,
+	}
+	pPod := vPod.DeepCopy()
+	pPod.Namespace = conversion.ToSuperClusterNamespace(clusterName, namespace)
+	dropUnsupportedProbes(&pPod.Spec)
+	// the default conversion is specific to the pods synced by the pod syncer, e.g. their service
+	// environment variables and DNS, the super pods of the workload get them from the super cluster.
+	ms := buildPodMutationPipeline(t.podMutators, vc.GetAnnotations(), nil)
+	if err := conversion.VC(t.mc, clusterName).Pod(pPod, vPod).Mutate(ms...); err != nil {
+		return nil, fmt.Errorf("failed to mutate pod template: %v", err)
+	}
+	// the mutators may add labels too.
+	if err := conversion.ValidateSuperClusterLabels(pPod); err != nil {
+		return nil, err
+	}
+	// the pods of the workload cannot have ephemeral containers at creation either.
+	pPod.Spec.EphemeralContainers = nil
+
+	if t.plugin != nil && t.plugin.Enabled() {
+		tenant := t.plugin.GetTenantLocker(clusterName)
+		if tenant == nil {
+			return nil, fmt.Errorf("cannot get tenant %s of the validation plugin", clusterName)
+		}
+		tenant.Cond.Lock()
+		accepted := t.plugin.Validation(pPod, clusterName)
+		tenant.Cond.Unlock()
+		if !accepted {
+			return &Rejection{Reason: "ValidationFailed", Message: "The pod template is rejected by the validation plugin of the syncer"}, nil
+		}
+	}
+
+	template.Labels = pPod.Labels
+	template.Annotations = pPod.Annotations
+	template.Spec = pPod.Spec
+	return nil, nil
+}
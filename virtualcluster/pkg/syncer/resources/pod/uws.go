@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/vnode"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -78,6 +79,10 @@ func (c *controller) BackPopulate(key string) error {
 		return fmt.Errorf("backPopulated pPod %s/%s delegated UID is different from updated object", pPod.Namespace, pPod.Name)
 	}
 
+	if mc.IsSyncPaused(vPod) {
+		return nil
+	}
+
 	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
 	if err != nil {
 		return pkgerr.Wrapf(err, "failed to create client from cluster %s config", clusterName)
@@ -112,6 +117,7 @@ func (c *controller) BackPopulate(key string) error {
 	if updatedMeta != nil {
 		newPod = vPod.DeepCopy()
 		newPod.ObjectMeta = *updatedMeta
+		c.UpwardController.WaitForTenantWrite()
 		if _, err = tenantClient.CoreV1().Pods(vPod.Namespace).Update(context.TODO(), newPod, metav1.UpdateOptions{}); err != nil {
 			return fmt.Errorf("failed to back populate pod %s/%s meta update for cluster %s: %v", vPod.Namespace, vPod.Name, clusterName, err)
 		}
@@ -127,6 +133,7 @@ func (c *controller) BackPopulate(key string) error {
 			}
 		}
 		newPod.Status = *newStatus
+		c.UpwardController.WaitForTenantWrite()
 		if _, err = tenantClient.CoreV1().Pods(vPod.Namespace).UpdateStatus(context.TODO(), newPod, metav1.UpdateOptions{}); err != nil {
 			return fmt.Errorf("failed to back populate pod %s/%s status update for cluster %s: %v", vPod.Namespace, vPod.Name, clusterName, err)
 		}
@@ -141,6 +148,7 @@ func (c *controller) BackPopulate(key string) error {
 				gracePeriod = *vPod.Spec.TerminationGracePeriodSeconds
 			}
 			deleteOptions := metav1.NewDeleteOptions(gracePeriod)
+			c.UpwardController.WaitForTenantWrite()
 			if err = tenantClient.CoreV1().Pods(vPod.Namespace).Delete(context.TODO(), vPod.Name, *deleteOptions); err != nil {
 				return err
 			}
@@ -148,6 +156,7 @@ func (c *controller) BackPopulate(key string) error {
 			klog.V(4).Infof("delete virtual pPod %s/%s with grace period seconds %v", vPod.Namespace, vPod.Name, *pPod.DeletionGracePeriodSeconds)
 			deleteOptions := metav1.NewDeleteOptions(*pPod.DeletionGracePeriodSeconds)
 			deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(vPod.UID))
+			c.UpwardController.WaitForTenantWrite()
 			if err = tenantClient.CoreV1().Pods(vPod.Namespace).Delete(context.TODO(), vPod.Name, *deleteOptions); err != nil {
 				return err
 			}
@@ -187,6 +196,7 @@ func (c *controller) bindPodToNode(pPod *corev1.Pod, clusterName string, tenantC
 		if err != nil {
 			return fmt.Errorf("failed to create virtual node %s in cluster %s from provider: %v", pPod.Spec.NodeName, clusterName, err)
 		}
+		c.UpwardController.WaitForTenantWrite()
 		_, err = tenantClient.CoreV1().Nodes().Create(context.TODO(), vn, metav1.CreateOptions{})
 		if err != nil && !apierrors.IsAlreadyExists(err) {
 			return fmt.Errorf("failed to create virtual node %s in cluster %s with err: %v", pPod.Spec.NodeName, clusterName, err)
@@ -28,11 +28,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	core "k8s.io/client-go/testing"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 )
 
@@ -135,6 +141,38 @@ func TestUWPodUpdate(t *testing.T) {
 		Phase: "Running",
 	}
 
+	statusTerminated := &corev1.PodStatus{
+		Phase: "Running",
+		ContainerStatuses: []corev1.ContainerStatus{
+			{
+				Name:         "c1",
+				RestartCount: 1,
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Reason:   "Error",
+						Message:  "panic: last lines of the container log",
+					},
+				},
+			},
+		},
+	}
+
+	statusDualStack := &corev1.PodStatus{
+		Phase: "Running",
+		PodIP: "10.0.0.1",
+		PodIPs: []corev1.PodIP{
+			{IP: "10.0.0.1"},
+			{IP: "fd00::1"},
+		},
+	}
+
+	statusDeadlineExceeded := &corev1.PodStatus{
+		Phase:   corev1.PodFailed,
+		Reason:  "DeadlineExceeded",
+		Message: "Pod was active on the node longer than the specified deadline",
+	}
+
 	defaultClusterKey := conversion.ToClusterKey(testTenant)
 	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
 
@@ -159,6 +197,48 @@ func TestUWPodUpdate(t *testing.T) {
 			},
 			ExpectedError: "",
 		},
+		"update vPod status with deadline exceeded": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatusToPod(superAssignedPod("pod-1", superDefaultNSName, "12345", "n1", defaultClusterKey), statusDeadlineExceeded),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyStatusToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), statusRunning),
+				fakeNode("n1"),
+			},
+			EnquedKey: superDefaultNSName + "/pod-1",
+			ExpectedUpdatedPods: []runtime.Object{
+				applyStatusToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), statusDeadlineExceeded),
+			},
+			ExpectedError: "",
+		},
+		"update vPod termination message": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatusToPod(superAssignedPod("pod-1", superDefaultNSName, "12345", "n1", defaultClusterKey), statusTerminated),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyStatusToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), statusRunning),
+				fakeNode("n1"),
+			},
+			EnquedKey: superDefaultNSName + "/pod-1",
+			ExpectedUpdatedPods: []runtime.Object{
+				applyStatusToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), statusTerminated),
+			},
+			ExpectedError: "",
+		},
+		"update vPod dual-stack status": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyStatusToPod(superAssignedPod("pod-1", superDefaultNSName, "12345", "n1", defaultClusterKey), statusDualStack),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyStatusToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), statusPending),
+				fakeNode("n1"),
+			},
+			EnquedKey: superDefaultNSName + "/pod-1",
+			ExpectedUpdatedPods: []runtime.Object{
+				applyStatusToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), statusDualStack),
+			},
+			ExpectedError: "",
+		},
 		"update vPod metadata": {
 			ExistingObjectInSuper: []runtime.Object{
 				applyLabelToPod(applyStatusToPod(superAssignedPod("pod-1", superDefaultNSName, "12345", "n1", defaultClusterKey), statusRunning), opaqueMetaPrefix+"/a", "b"),
@@ -268,6 +348,98 @@ func TestUWPodUpdate(t *testing.T) {
 	}
 }
 
+func TestUWPodMetadataAllowlist(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{
+			TransparentMetaPrefixes: []string{"topology.super", "scheduler.super"},
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	// The super pod carries transparent keys, and super internal ones like a scheduler hint.
+	superPod := func() *corev1.Pod {
+		pod := superAssignedPod("pod-1", superDefaultNSName, "12345", "n1", defaultClusterKey)
+		pod = applyLabelToPod(pod, "topology.super/zone", "zone-a")
+		pod = applyLabelToPod(pod, "scheduler.super/hint", "rack-1")
+		pod = applyLabelToPod(pod, "node-pool.internal/name", "pool-1")
+		return pod
+	}
+
+	testcases := map[string]struct {
+		Allowlist           []string
+		ExpectedUpdatedPods []runtime.Object
+	}{
+		"no allowlist": {
+			ExpectedUpdatedPods: []runtime.Object{
+				applyLabelToPod(applyLabelToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), "topology.super/zone", "zone-a"), "scheduler.super/hint", "rack-1"),
+			},
+		},
+		"allowlist": {
+			Allowlist: []string{"topology.super"},
+			ExpectedUpdatedPods: []runtime.Object{
+				applyLabelToPod(tenantAssignedPod("pod-1", "default", "12345", "n1"), "topology.super/zone", "zone-a"),
+			},
+		},
+		"allowlist without transparent keys": {
+			Allowlist:           []string{"node-pool.internal"},
+			ExpectedUpdatedPods: []runtime.Object{},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunUpwardSync(func(config *config.SyncerConfiguration,
+				client clientset.Interface,
+				informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface,
+				vcInformer vcinformers.VirtualClusterInformer,
+				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.UWSMetadataAllowlist = tc.Allowlist
+				return NewPodController(config, client, informer, vcClient, vcInformer, options)
+			}, testTenant, []runtime.Object{superPod()}, []runtime.Object{tenantAssignedPod("pod-1", "default", "12345", "n1"), fakeNode("n1")}, superDefaultNSName+"/pod-1", nil)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+			if reconcileErr != nil {
+				t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+			}
+
+			var updatedPods []runtime.Object
+			for _, action := range actions {
+				if action.Matches("update", "pods") && action.GetSubresource() == "" {
+					updatedPods = append(updatedPods, action.(core.UpdateAction).GetObject())
+				}
+			}
+			if len(updatedPods) != len(tc.ExpectedUpdatedPods) {
+				t.Fatalf("%s: expected %d updated pods, got %d", k, len(tc.ExpectedUpdatedPods), len(updatedPods))
+			}
+			for i, obj := range tc.ExpectedUpdatedPods {
+				accessor, _ := meta.Accessor(obj)
+				accessor.SetResourceVersion("999")
+				if !equality.Semantic.DeepEqual(obj, updatedPods[i]) {
+					exp, _ := json.Marshal(obj)
+					got, _ := json.Marshal(updatedPods[i])
+					t.Errorf("%s: Expected updated pod is %v, got %v", k, string(exp), string(got))
+				}
+				if _, leaked := updatedPods[i].(*corev1.Pod).Labels["node-pool.internal/name"]; leaked {
+					t.Errorf("%s: expected the super internal label not to leak to the tenant pod", k)
+				}
+			}
+		})
+	}
+}
+
 func TestUWPodDeletion(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
 var numMissMatchedPriorityClasses uint64
@@ -102,6 +103,11 @@ func (c *controller) checkPriorityClassOfTenantCluster(clusterName string) {
 		pPriorityClass, err := c.priorityclassLister.Get(vPriorityClass.Name)
 		if apierrors.IsNotFound(err) {
 			// super control plane is the source of the truth for priorityclass object, delete tenant control plane obj
+			if !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "priorityclass", clusterName, vPriorityClass.Name, "", func() (metav1.Object, error) {
+				return c.client.PriorityClasses().Get(context.TODO(), vPriorityClass.Name, metav1.GetOptions{})
+			}) {
+				continue
+			}
 			tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
 			if err != nil {
 				klog.Errorf("error getting cluster %s clientset: %v", clusterName, err)
@@ -35,6 +35,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
@@ -75,7 +76,7 @@ func NewPriorityClassController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&v1.PriorityClass{}, &v1.PriorityClassList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&v1.PriorityClass{}, &v1.PriorityClassList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -84,10 +85,12 @@ func NewPriorityClassController(config *config.SyncerConfiguration,
 	if options.IsFake {
 		c.priorityclassSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(informer.Scheduling().V1().PriorityClasses().Informer(), "priorityclasses")
 		c.priorityclassSynced = informer.Scheduling().V1().PriorityClasses().Informer().HasSynced
 	}
 
-	c.UpwardController, err = uw.NewUWController(&v1.PriorityClass{}, c, uw.WithOptions(options.UWOptions))
+	c.UpwardController, err = uw.NewUWController(&v1.PriorityClass{}, c,
+		uw.WithSyncerConfig(config), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
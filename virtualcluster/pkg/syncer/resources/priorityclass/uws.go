@@ -27,6 +27,7 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -63,6 +64,7 @@ func (c *controller) BackPopulate(key string) error {
 			if op == reconciler.AddEvent {
 				// Available in super, hence create a new in tenant control plane
 				vPriorityClass := conversion.BuildVirtualPriorityClass(clusterName, pPriorityClass)
+				c.UpwardController.WaitForTenantWrite()
 				_, err := tenantClient.SchedulingV1().PriorityClasses().Create(context.TODO(), vPriorityClass, metav1.CreateOptions{})
 				if err != nil {
 					return err
@@ -74,21 +76,33 @@ func (c *controller) BackPopulate(key string) error {
 	}
 
 	if op == reconciler.DeleteEvent {
+		if !util.IsSyncedFromSuper(vPriorityClass, "") {
+			// The tenant priorityclass was not synced from the deleted one.
+			return nil
+		}
 		opts := &metav1.DeleteOptions{
 			PropagationPolicy: &constants.DefaultDeletionPolicy,
 		}
-		err := tenantClient.SchedulingV1().PriorityClasses().Delete(context.TODO(), scName, *opts)
-		if err != nil {
+		c.UpwardController.WaitForTenantWrite()
+		return tenantClient.SchedulingV1().PriorityClasses().Delete(context.TODO(), scName, *opts)
+	}
+
+	updatedPriorityClass := conversion.Equality(c.Config, nil).CheckPriorityClassEquality(pPriorityClass, vPriorityClass)
+	if !util.IsSyncedFromSuper(vPriorityClass, pPriorityClass.UID) {
+		// The tenant has created a priorityclass with the name of a public one.
+		adopt, err := util.ResolveClusterScopedConflict(c.Config.OnClusterScopedConflict, "priorityclass", clusterName, scName)
+		if err != nil || !adopt {
 			return err
 		}
-	} else {
-		updatedPriorityClass := conversion.Equality(c.Config, nil).CheckPriorityClassEquality(pPriorityClass, vPriorityClass)
-		if updatedPriorityClass != nil {
-			_, err := tenantClient.SchedulingV1().PriorityClasses().Update(context.TODO(), updatedPriorityClass, metav1.UpdateOptions{})
-			if err != nil {
-				return err
-			}
+		if updatedPriorityClass == nil {
+			updatedPriorityClass = vPriorityClass.DeepCopy()
 		}
+		util.MarkSyncedFromSuper(updatedPriorityClass, pPriorityClass)
+	}
+	if updatedPriorityClass != nil {
+		c.UpwardController.WaitForTenantWrite()
+		_, err := tenantClient.SchedulingV1().PriorityClasses().Update(context.TODO(), updatedPriorityClass, metav1.UpdateOptions{})
+		return err
 	}
 	return nil
 }
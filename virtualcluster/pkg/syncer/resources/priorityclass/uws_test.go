@@ -28,10 +28,16 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	core "k8s.io/client-go/testing"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 )
 
@@ -56,6 +62,10 @@ func makePriorityClass(name, uid string, mFuncs ...func(*v1.PriorityClass)) *v1.
 	return pc
 }
 
+func markPublic(class *v1.PriorityClass) {
+	class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+}
+
 func TestUWPCCreation(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -92,7 +102,7 @@ func TestUWPCCreation(t *testing.T) {
 				makePriorityClass("pc", "12345"),
 			},
 			ExistingObjectInTenant: []runtime.Object{
-				makePriorityClass("pc", "123456"),
+				makePriorityClass("pc", "123456", markPublic),
 			},
 			EnqueuedKey:         defaultClusterKey + "/pc",
 			ExpectedNoOperation: true,
@@ -177,13 +187,13 @@ func TestUWPCUpdate(t *testing.T) {
 				}),
 			},
 			ExistingObjectInTenant: []runtime.Object{
-				makePriorityClass("pc", "123456", func(class *v1.PriorityClass) {
+				makePriorityClass("pc", "123456", markPublic, func(class *v1.PriorityClass) {
 					class.Value = 20000
 				}),
 			},
 			EnqueuedKey: defaultClusterKey + "/pc",
 			ExpectedUpdatedObject: []runtime.Object{
-				makePriorityClass("pc", "123456", func(class *v1.PriorityClass) {
+				makePriorityClass("pc", "123456", markPublic, func(class *v1.PriorityClass) {
 					class.Value = 10000
 					class.PreemptionPolicy = &policy
 				}),
@@ -279,6 +289,13 @@ func TestUWPCDeletion(t *testing.T) {
 				"pc",
 			},
 		},
+		"pPC not found, vPC created by tenant": {
+			ExistingObjectInTenant: []runtime.Object{
+				makePriorityClass("pc", "12345"),
+			},
+			EnqueuedKey:         defaultClusterKey + "/pc",
+			ExpectedNoOperation: true,
+		},
 	}
 
 	for k, tc := range testcases {
@@ -329,3 +346,91 @@ func TestUWPCDeletion(t *testing.T) {
 		})
 	}
 }
+
+func TestUWPCConflict(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superObject := makePriorityClass("pc", "12345", markPublic, func(class *v1.PriorityClass) {
+		class.Value = 10000
+	})
+	tenantObject := makePriorityClass("pc", "123456", func(class *v1.PriorityClass) {
+		class.Value = 20000
+	})
+
+	testcases := map[string]struct {
+		Policy                string
+		ExpectedUpdatedObject runtime.Object
+		ExpectedError         string
+		ExpectedNoOperation   bool
+	}{
+		"adopt": {
+			Policy: constants.ClusterScopedConflictAdopt,
+			ExpectedUpdatedObject: makePriorityClass("pc", "123456", markPublic, func(class *v1.PriorityClass) {
+				class.ResourceVersion = "999"
+				class.Annotations = map[string]string{constants.LabelUID: "12345"}
+				class.Value = 10000
+			}),
+		},
+		"skip": {
+			Policy:              constants.ClusterScopedConflictSkip,
+			ExpectedNoOperation: true,
+		},
+		"fail": {
+			Policy:        constants.ClusterScopedConflictFail,
+			ExpectedError: "was not synced from the super control plane",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunUpwardSync(func(config *config.SyncerConfiguration,
+				client clientset.Interface,
+				informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface,
+				vcInformer vcinformers.VirtualClusterInformer,
+				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.OnClusterScopedConflict = tc.Policy
+				return NewPriorityClassController(config, client, informer, vcClient, vcInformer, options)
+			}, testTenant, []runtime.Object{superObject.DeepCopy()}, []runtime.Object{tenantObject.DeepCopy()}, defaultClusterKey+"/pc", nil)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedError != "" {
+				if reconcileErr == nil || !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("%s: expected error msg \"%s\", but got \"%v\"", k, tc.ExpectedError, reconcileErr)
+				}
+			} else if reconcileErr != nil {
+				t.Errorf("%s: expected no error, but got \"%v\"", k, reconcileErr)
+			}
+
+			if tc.ExpectedUpdatedObject == nil {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("update", "priorityclasses") {
+				t.Errorf("%s: Expect one update of priorityclass, got %v", k, actions)
+				return
+			}
+			actionObj := actions[0].(core.UpdateAction).GetObject()
+			if !equality.Semantic.DeepEqual(tc.ExpectedUpdatedObject, actionObj) {
+				exp, _ := json.Marshal(tc.ExpectedUpdatedObject)
+				got, _ := json.Marshal(actionObj)
+				t.Errorf("%s: Expected updated priorityclass is %v, got %v", k, string(exp), string(got))
+			}
+		})
+	}
+}
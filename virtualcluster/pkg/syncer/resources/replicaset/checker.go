@@ -0,0 +1,197 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicaset
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+var numSpecMissMatchedReplicaSets uint64
+var numStatusMissMatchedReplicaSets uint64
+var numUWMetaMissMatchedReplicaSets uint64
+
+func (c *controller) StartPatrol(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.replicasetSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting ReplicaSet checker")
+	}
+	c.Patroller.Start(stopCh)
+	return nil
+}
+
+// PatrollerDo check if replicasets keep consistency between super
+// control plane and tenant control planes.
+func (c *controller) PatrollerDo() {
+	clusterNames := c.MultiClusterController.GetClusterNames()
+	if len(clusterNames) == 0 {
+		klog.V(5).Infof("super cluster has no tenant control planes, giving up periodic checker: %s", "replicaset")
+		return
+	}
+
+	wg := sync.WaitGroup{}
+	numSpecMissMatchedReplicaSets = 0
+	numStatusMissMatchedReplicaSets = 0
+	numUWMetaMissMatchedReplicaSets = 0
+
+	for _, clusterName := range clusterNames {
+		wg.Add(1)
+		go func(clusterName string) {
+			defer wg.Done()
+			c.checkReplicaSetsOfTenantCluster(clusterName)
+		}(clusterName)
+	}
+	wg.Wait()
+
+	pReplicaSets, err := c.replicasetLister.List(util.GetSuperClusterListerLabelsSelector())
+	if err != nil {
+		klog.Errorf("error listing replicasets from super control plane informer cache: %v", err)
+		return
+	}
+
+	for _, pReplicaSet := range pReplicaSets {
+		if metav1.GetControllerOf(pReplicaSet) != nil {
+			// created by a super cluster workload controller from a synced owner, which
+			// copies the owner annotations.
+			continue
+		}
+		clusterName, vNamespace := conversion.GetVirtualOwner(pReplicaSet)
+		if len(clusterName) == 0 || len(vNamespace) == 0 {
+			continue
+		}
+		shouldDelete := false
+		vReplicaSet := &appsv1.ReplicaSet{}
+		err := c.MultiClusterController.Get(clusterName, vNamespace, pReplicaSet.Name, vReplicaSet)
+		if apierrors.IsNotFound(err) {
+			shouldDelete = true
+		}
+		if err == nil {
+			if pReplicaSet.Annotations[constants.LabelUID] != string(vReplicaSet.UID) {
+				shouldDelete = true
+				klog.Warningf("Found pReplicaSet %s/%s delegated UID is different from tenant object.", pReplicaSet.Namespace, pReplicaSet.Name)
+			}
+		}
+		if shouldDelete && !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "replicaset", clusterName, vNamespace+"/"+pReplicaSet.Name, pReplicaSet.Annotations[constants.LabelUID],
+			util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+				return tenantClient.AppsV1().ReplicaSets(vNamespace).Get(context.TODO(), pReplicaSet.Name, metav1.GetOptions{})
+			})) {
+			shouldDelete = false
+		}
+		if shouldDelete {
+			deleteOptions := metav1.NewPreconditionDeleteOptions(string(pReplicaSet.UID))
+			deleteOptions.PropagationPolicy = &constants.DefaultDeletionPolicy
+			if err = c.replicasetClient.ReplicaSets(pReplicaSet.Namespace).Delete(context.TODO(), pReplicaSet.Name, *deleteOptions); err != nil {
+				klog.Errorf("error deleting pReplicaSet %s/%s in super control plane: %v", pReplicaSet.Namespace, pReplicaSet.Name, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("DeletedOrphanSuperControlPlaneReplicaSets").Inc()
+			}
+		}
+	}
+
+	metrics.CheckerMissMatchStats.WithLabelValues("SpecMissMatchedReplicaSets").Set(float64(numSpecMissMatchedReplicaSets))
+	metrics.CheckerMissMatchStats.WithLabelValues("StatusMissMatchedReplicaSets").Set(float64(numStatusMissMatchedReplicaSets))
+	metrics.CheckerMissMatchStats.WithLabelValues("UWMetaMissMatchedReplicaSets").Set(float64(numUWMetaMissMatchedReplicaSets))
+}
+
+func (c *controller) checkReplicaSetsOfTenantCluster(clusterName string) {
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		klog.Errorf("fail to get cluster spec : %s", clusterName)
+		return
+	}
+	if !conversion.SuperClusterWorkloadControllersEnabled(vc) {
+		return
+	}
+
+	replicasetList := &appsv1.ReplicaSetList{}
+	if err := c.MultiClusterController.List(clusterName, replicasetList); err != nil {
+		klog.Errorf("error listing replicasets from cluster %s informer cache: %v", clusterName, err)
+		return
+	}
+	klog.V(4).Infof("check replicasets consistency in cluster %s", clusterName)
+
+	for i, vReplicaSet := range replicasetList.Items {
+		if mc.IsSyncPaused(&replicasetList.Items[i]) {
+			continue
+		}
+		if metav1.GetControllerOf(&replicasetList.Items[i]) != nil {
+			continue
+		}
+		targetNamespace := conversion.ToSuperClusterNamespace(clusterName, vReplicaSet.Namespace)
+		pReplicaSet, err := c.replicasetLister.ReplicaSets(targetNamespace).Get(vReplicaSet.Name)
+		if apierrors.IsNotFound(err) {
+			if err := c.MultiClusterController.RequeueObject(clusterName, &replicasetList.Items[i]); err != nil {
+				klog.Errorf("error requeue vreplicaset %v/%v in cluster %s: %v", vReplicaSet.Namespace, vReplicaSet.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantReplicaSets").Inc()
+			}
+			continue
+		}
+
+		if err != nil {
+			klog.Errorf("failed to get pReplicaSet %s/%s from super control plane cache: %v", targetNamespace, vReplicaSet.Name, err)
+			continue
+		}
+
+		if pReplicaSet.Annotations[constants.LabelUID] != string(vReplicaSet.UID) {
+			klog.Errorf("Found pReplicaSet %s/%s delegated UID is different from tenant object.", targetNamespace, pReplicaSet.Name)
+			continue
+		}
+
+		updatedReplicaSet := conversion.Equality(c.Config, vc).CheckReplicaSetEquality(pReplicaSet, &replicasetList.Items[i])
+		if updatedReplicaSet != nil {
+			atomic.AddUint64(&numSpecMissMatchedReplicaSets, 1)
+			klog.Warningf("spec of replicaset %v/%v diff in super&tenant control plane", vReplicaSet.Namespace, vReplicaSet.Name)
+			if err := c.MultiClusterController.RequeueObject(clusterName, &replicasetList.Items[i]); err != nil {
+				klog.Errorf("error requeue vreplicaset %v/%v in cluster %s: %v", vReplicaSet.Namespace, vReplicaSet.Name, clusterName, err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantReplicaSets").Inc()
+			}
+		}
+
+		enqueue := false
+		updatedMeta := conversion.Equality(c.Config, vc).CheckUWObjectMetaEquality(&pReplicaSet.ObjectMeta, &replicasetList.Items[i].ObjectMeta)
+		if updatedMeta != nil {
+			atomic.AddUint64(&numUWMetaMissMatchedReplicaSets, 1)
+			enqueue = true
+			klog.Warningf("UWObjectMeta of vReplicaSet %v/%v diff in super&tenant control plane", vReplicaSet.Namespace, vReplicaSet.Name)
+		}
+		if !equality.Semantic.DeepEqual(vReplicaSet.Status, tenantReplicaSetStatus(c.Config, vc, pReplicaSet, &replicasetList.Items[i])) {
+			enqueue = true
+			atomic.AddUint64(&numStatusMissMatchedReplicaSets, 1)
+			klog.Warningf("Status of vReplicaSet %v/%v diff in super&tenant control plane", vReplicaSet.Namespace, vReplicaSet.Name)
+		}
+		if enqueue {
+			c.enqueueReplicaSet(pReplicaSet)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicaset
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	v1apps "k8s.io/client-go/kubernetes/typed/apps/v1"
+	listersappsv1 "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
+	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/resources/pod"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
+)
+
+func init() {
+	plugin.SyncerResourceRegister.Register(&plugin.Registration{
+		ID: "replicaset",
+		InitFn: func(ctx *plugin.InitContext) (interface{}, error) {
+			return NewReplicaSetController(ctx.Config.(*config.SyncerConfiguration), ctx.Client, ctx.Informer, ctx.VCClient, ctx.VCInformer, manager.ResourceSyncerOptions{})
+		},
+		Disable: true,
+	})
+}
+
+// controller syncs the tenant ReplicaSets of the Virtual Clusters that opted in to the super cluster
+// workload controllers, and their status back, so that tenant control planes without a
+// controller-manager get their ReplicaSets reconciled by the super cluster controller-manager.
+type controller struct {
+	manager.BaseResourceSyncer
+	// super control plane replicaset client
+	replicasetClient v1apps.ReplicaSetsGetter
+	// super control plane informer/listers/synced functions
+	replicasetLister listersappsv1.ReplicaSetLister
+	replicasetSynced cache.InformerSynced
+	// templateChecker checks and mutates the pod templates like tenant pods.
+	templateChecker *pod.TemplateChecker
+}
+
+func NewReplicaSetController(config *config.SyncerConfiguration,
+	client clientset.Interface,
+	informer informers.SharedInformerFactory,
+	vcClient vcclient.Interface,
+	vcInformer vcinformers.VirtualClusterInformer,
+	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		replicasetClient: client.AppsV1(),
+	}
+
+	var err error
+	c.MultiClusterController, err = mc.NewMCController(&appsv1.ReplicaSet{}, &appsv1.ReplicaSetList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.replicasetLister = informer.Apps().V1().ReplicaSets().Lister()
+	if options.IsFake {
+		c.replicasetSynced = func() bool { return true }
+	} else {
+		util.TrackWatchErrors(informer.Apps().V1().ReplicaSets().Informer(), "replicasets")
+		c.replicasetSynced = informer.Apps().V1().ReplicaSets().Informer().HasSynced
+	}
+
+	c.templateChecker, err = pod.NewTemplateChecker(c.MultiClusterController, &plugin.InitContext{
+		Context:    context.Background(),
+		Config:     config,
+		Client:     client,
+		Informer:   informer,
+		VCClient:   vcClient,
+		VCInformer: vcInformer,
+	}, options.IsFake)
+	if err != nil {
+		return nil, err
+	}
+
+	c.UpwardController, err = uw.NewUWController(&appsv1.ReplicaSet{}, c,
+		uw.WithSyncerConfig(config), uw.WithObjectGetter(informer.Apps().V1().ReplicaSets().Informer().GetIndexer()), uw.WithOptions(options.UWOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	c.Patroller, err = pa.NewPatroller(&appsv1.ReplicaSet{}, c, pa.WithOptions(options.PatrolOptions))
+	if err != nil {
+		return nil, err
+	}
+
+	informer.Apps().V1().ReplicaSets().Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: func(obj interface{}) bool {
+				switch t := obj.(type) {
+				case *appsv1.ReplicaSet:
+					return true
+				case cache.DeletedFinalStateUnknown:
+					if _, ok := t.Obj.(*appsv1.ReplicaSet); ok {
+						return true
+					}
+					utilruntime.HandleError(fmt.Errorf("unable to convert object %v to *appsv1.ReplicaSet", obj))
+					return false
+				default:
+					utilruntime.HandleError(fmt.Errorf("unable to handle object in super control plane replicaset controller: %v", obj))
+					return false
+				}
+			},
+			Handler: cache.ResourceEventHandlerFuncs{
+				AddFunc: c.enqueueReplicaSet,
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					newReplicaSet := newObj.(*appsv1.ReplicaSet)
+					oldReplicaSet := oldObj.(*appsv1.ReplicaSet)
+					if newReplicaSet.ResourceVersion != oldReplicaSet.ResourceVersion {
+						c.enqueueReplicaSet(newObj)
+					}
+				},
+				DeleteFunc: c.enqueueReplicaSet,
+			},
+		})
+	return c, nil
+}
+
+func (c *controller) enqueueReplicaSet(obj interface{}) {
+	replicaset, ok := obj.(*appsv1.ReplicaSet)
+	if !ok {
+		return
+	}
+	// replicasets of synced deployments carry the deployment annotations but have no tenant copy.
+	if metav1.GetControllerOf(replicaset) != nil {
+		return
+	}
+
+	clusterName, _ := conversion.GetVirtualOwner(replicaset)
+	if clusterName == "" {
+		return
+	}
+
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("couldn't get key for object %v: %v", obj, err))
+		return
+	}
+	c.UpwardController.AddToQueue(key)
+}
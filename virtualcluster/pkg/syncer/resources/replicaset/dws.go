@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicaset
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+func (c *controller) StartDWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.replicasetSynced) {
+		return fmt.Errorf("failed to wait for caches to sync before starting ReplicaSet dws")
+	}
+	return c.MultiClusterController.Start(stopCh)
+}
+
+func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
+	logsampling.V(4, "replicaset").Infof("reconcile replicaset %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
+	pReplicaSet, err := c.replicasetLister.ReplicaSets(targetNamespace).Get(request.Name)
+	pExists := true
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		pExists = false
+	}
+	vExists := true
+	vReplicaSet := &appsv1.ReplicaSet{}
+	if err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vReplicaSet); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return reconciler.Result{Requeue: true}, err
+		}
+		vExists = false
+	}
+
+	var vc *v1alpha1.VirtualCluster
+	if vExists {
+		vc, err = util.GetVirtualClusterObject(c.MultiClusterController, request.ClusterName)
+		if err != nil {
+			return reconciler.Result{Requeue: true}, err
+		}
+		if !conversion.SuperClusterWorkloadControllersEnabled(vc) {
+			// the tenant control plane reconciles its own replicasets.
+			return reconciler.Result{}, nil
+		}
+		if metav1.GetControllerOf(vReplicaSet) != nil {
+			// the replicaset is managed by a tenant controller, the super cluster workload controllers
+			// create their own replicasets for the synced owner.
+			return reconciler.Result{}, nil
+		}
+	}
+
+	switch {
+	case vExists && !pExists:
+		err := c.reconcileReplicaSetCreate(vc, request.ClusterName, targetNamespace, request.UID, vReplicaSet)
+		if err != nil {
+			klog.Errorf("failed reconcile replicaset %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	case !vExists && pExists:
+		err := c.reconcileReplicaSetRemove(targetNamespace, request.UID, request.Name, pReplicaSet)
+		if err != nil {
+			klog.Errorf("failed reconcile replicaset %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
+	case vExists && pExists:
+		err := c.reconcileReplicaSetUpdate(vc, request.ClusterName, targetNamespace, request.UID, pReplicaSet, vReplicaSet)
+		if err != nil {
+			klog.Errorf("failed reconcile replicaset %s/%s UPDATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
+			return reconciler.Result{Requeue: true}, err
+		}
+	default:
+		// object is gone.
+	}
+	return reconciler.Result{}, nil
+}
+
+func (c *controller) reconcileReplicaSetCreate(vc *v1alpha1.VirtualCluster, clusterName, targetNamespace, requestUID string, replicaset *appsv1.ReplicaSet) error {
+	newObj, err := c.Conversion().BuildSuperClusterObject(clusterName, replicaset)
+	if err != nil {
+		return err
+	}
+
+	pReplicaSet := newObj.(*appsv1.ReplicaSet)
+	if rejected, err := c.checkPodTemplate(vc, clusterName, replicaset, &pReplicaSet.Spec.Template); err != nil || rejected {
+		return err
+	}
+	conversion.MutateWorkloadPodTemplate(c.Config, &pReplicaSet.Spec.Template)
+
+	pReplicaSet, err = c.replicasetClient.ReplicaSets(targetNamespace).Create(context.TODO(), pReplicaSet, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		if pReplicaSet.Annotations[constants.LabelUID] == requestUID {
+			klog.Infof("replicaset %s/%s of cluster %s already exist in super control plane", targetNamespace, pReplicaSet.Name, clusterName)
+			return nil
+		}
+		return fmt.Errorf("pReplicaSet %s/%s exists but its delegated object UID is different", targetNamespace, pReplicaSet.Name)
+	}
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, replicaset.Namespace, replicaset.Name)
+	return nil
+}
+
+func (c *controller) reconcileReplicaSetUpdate(vc *v1alpha1.VirtualCluster, clusterName, targetNamespace, requestUID string, pReplicaSet, vReplicaSet *appsv1.ReplicaSet) error {
+	if pReplicaSet.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("pReplicaSet %s/%s delegated UID is different from updated object", targetNamespace, pReplicaSet.Name)
+	}
+
+	// the super replicaset keeps its last accepted pod template until the tenant fixes it.
+	vReplicaSet = vReplicaSet.DeepCopy()
+	if rejected, err := c.checkPodTemplate(vc, clusterName, vReplicaSet, &vReplicaSet.Spec.Template); err != nil || rejected {
+		return err
+	}
+	updated := conversion.Equality(c.Config, vc).CheckReplicaSetEquality(pReplicaSet, vReplicaSet)
+	if updated != nil {
+		_, err := c.replicasetClient.ReplicaSets(targetNamespace).Update(context.TODO(), updated, metav1.UpdateOptions{})
+		if err != nil {
+			return err
+		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vReplicaSet.Namespace, vReplicaSet.Name)
+	}
+	return nil
+}
+
+func (c *controller) reconcileReplicaSetRemove(targetNamespace, requestUID, name string, pReplicaSet *appsv1.ReplicaSet) error {
+	if pReplicaSet.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("to be deleted pReplicaSet %s/%s delegated UID is different from deleted object", targetNamespace, name)
+	}
+
+	opts := &metav1.DeleteOptions{
+		PropagationPolicy: &constants.DefaultDeletionPolicy,
+		Preconditions:     metav1.NewUIDPreconditions(string(pReplicaSet.UID)),
+	}
+	err := c.replicasetClient.ReplicaSets(targetNamespace).Delete(context.TODO(), name, *opts)
+	if apierrors.IsNotFound(err) {
+		klog.Warningf("To be deleted replicaset %s/%s not found in super control plane", targetNamespace, name)
+		return nil
+	}
+	return err
+}
+
+// checkPodTemplate checks and mutates the pod template of the tenant replicaset like a tenant pod. It
+// returns true, after recording the reason on the tenant replicaset, if the template is rejected.
+func (c *controller) checkPodTemplate(vc *v1alpha1.VirtualCluster, clusterName string, vReplicaSet *appsv1.ReplicaSet, template *corev1.PodTemplateSpec) (bool, error) {
+	rejection, err := c.templateChecker.CheckTemplate(vc, clusterName, vReplicaSet.Namespace, vReplicaSet.Name, template)
+	if err != nil || rejection == nil {
+		return false, err
+	}
+	// Reject the replicaset without retrying, the tenant has to change its pod template.
+	klog.Infof("reject replicaset %s/%s of cluster %s: %s", vReplicaSet.Namespace, vReplicaSet.Name, clusterName, rejection.Message)
+	return true, c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+		Kind:       "ReplicaSet",
+		Name:       vReplicaSet.Name,
+		Namespace:  vReplicaSet.Namespace,
+		UID:        vReplicaSet.UID,
+	}, corev1.EventTypeWarning, rejection.Reason, "%s", rejection.Message)
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicaset
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func testTenant(workloadControllers bool) *v1alpha1.VirtualCluster {
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+	if workloadControllers {
+		vc.Annotations = map[string]string{constants.LabelSuperClusterWorkloadControllers: "true"}
+	}
+	return vc
+}
+
+func tenantReplicaSet(name, namespace, uid string, replicas int32) *appsv1.ReplicaSet {
+	return &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			UID:       types.UID(uid),
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Replicas: pointer.Int32Ptr(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "c", Image: "busybox"}}},
+			},
+		},
+	}
+}
+
+func superReplicaSet(name, namespace, uid, clusterKey string, replicas int32) *appsv1.ReplicaSet {
+	d := tenantReplicaSet(name, namespace, "", replicas)
+	d.UID = types.UID(uid + "-super")
+	d.Annotations = map[string]string{
+		constants.LabelUID:       uid,
+		constants.LabelNamespace: "default",
+		constants.LabelCluster:   clusterKey,
+	}
+	d.Spec.Template.Spec.AutomountServiceAccountToken = pointer.BoolPtr(false)
+	return d
+}
+
+func controlledReplicaSet(rs *appsv1.ReplicaSet) *appsv1.ReplicaSet {
+	rs.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Name:       rs.Name,
+		UID:        "67890",
+		Controller: pointer.BoolPtr(true),
+	}}
+	return rs
+}
+
+func hostPIDReplicaSet(obj *appsv1.ReplicaSet) *appsv1.ReplicaSet {
+	obj.Spec.Template.Spec.HostPID = true
+	return obj
+}
+
+func TestDWReplicaSet(t *testing.T) {
+	defaultClusterKey := conversion.ToClusterKey(testTenant(true))
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	testcases := map[string]struct {
+		WorkloadControllers    bool
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		EnqueueObject          *appsv1.ReplicaSet
+
+		ExpectedVerb        string
+		ExpectedReplicas    int32
+		ExpectedError       string
+		ExpectedEventReason string
+	}{
+		"new replicaset": {
+			WorkloadControllers:    true,
+			ExistingObjectInTenant: []runtime.Object{tenantReplicaSet("web", "default", "12345", 2)},
+			EnqueueObject:          tenantReplicaSet("web", "default", "12345", 2),
+			ExpectedVerb:           "create",
+			ExpectedReplicas:       2,
+		},
+		"new replicaset of a cluster with its own controllers": {
+			WorkloadControllers:    false,
+			ExistingObjectInTenant: []runtime.Object{tenantReplicaSet("web", "default", "12345", 2)},
+			EnqueueObject:          tenantReplicaSet("web", "default", "12345", 2),
+		},
+		"new replicaset of a tenant controller": {
+			WorkloadControllers:    true,
+			ExistingObjectInTenant: []runtime.Object{controlledReplicaSet(tenantReplicaSet("web", "default", "12345", 2))},
+			EnqueueObject:          controlledReplicaSet(tenantReplicaSet("web", "default", "12345", 2)),
+		},
+		"new replicaset but existing different uid one": {
+			WorkloadControllers:    true,
+			ExistingObjectInSuper:  []runtime.Object{superReplicaSet("web", superDefaultNSName, "123456", defaultClusterKey, 2)},
+			ExistingObjectInTenant: []runtime.Object{tenantReplicaSet("web", "default", "12345", 2)},
+			EnqueueObject:          tenantReplicaSet("web", "default", "12345", 2),
+			ExpectedError:          "delegated UID is different",
+		},
+		"scaled replicaset": {
+			WorkloadControllers:    true,
+			ExistingObjectInSuper:  []runtime.Object{superReplicaSet("web", superDefaultNSName, "12345", defaultClusterKey, 2)},
+			ExistingObjectInTenant: []runtime.Object{tenantReplicaSet("web", "default", "12345", 5)},
+			EnqueueObject:          tenantReplicaSet("web", "default", "12345", 5),
+			ExpectedVerb:           "update",
+			ExpectedReplicas:       5,
+		},
+		"unchanged replicaset": {
+			WorkloadControllers:    true,
+			ExistingObjectInSuper:  []runtime.Object{superReplicaSet("web", superDefaultNSName, "12345", defaultClusterKey, 2)},
+			ExistingObjectInTenant: []runtime.Object{tenantReplicaSet("web", "default", "12345", 2)},
+			EnqueueObject:          tenantReplicaSet("web", "default", "12345", 2),
+		},
+		"new replicaset with a host PID pod template": {
+			WorkloadControllers:    true,
+			ExistingObjectInTenant: []runtime.Object{hostPIDReplicaSet(tenantReplicaSet("web", "default", "12345", 2))},
+			EnqueueObject:          hostPIDReplicaSet(tenantReplicaSet("web", "default", "12345", 2)),
+			ExpectedEventReason:    "HostNamespaceNotAllowed",
+		},
+		"replicaset updated to a host PID pod template": {
+			WorkloadControllers:    true,
+			ExistingObjectInSuper:  []runtime.Object{superReplicaSet("web", superDefaultNSName, "12345", defaultClusterKey, 2)},
+			ExistingObjectInTenant: []runtime.Object{hostPIDReplicaSet(tenantReplicaSet("web", "default", "12345", 2))},
+			EnqueueObject:          hostPIDReplicaSet(tenantReplicaSet("web", "default", "12345", 2)),
+			ExpectedEventReason:    "HostNamespaceNotAllowed",
+		},
+		"deleted replicaset": {
+			WorkloadControllers:   false,
+			ExistingObjectInSuper: []runtime.Object{superReplicaSet("web", superDefaultNSName, "12345", defaultClusterKey, 2)},
+			EnqueueObject:         tenantReplicaSet("web", "default", "12345", 2),
+			ExpectedVerb:          "delete",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			var tenant *fake.Clientset
+			actions, reconcileErr, err := util.RunDownwardSync(NewReplicaSetController,
+				testTenant(tc.WorkloadControllers),
+				tc.ExistingObjectInSuper,
+				tc.ExistingObjectInTenant,
+				tc.EnqueueObject,
+				func(tenantClientset, superClientset *fake.Clientset) {
+					tenant = tenantClientset
+				})
+			if err != nil {
+				t.Errorf("%s: error running downward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else if tc.ExpectedError != "" {
+				t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+			}
+
+			var eventReasons []string
+			for _, action := range tenant.Actions() {
+				if action.Matches("create", "events") {
+					eventReasons = append(eventReasons, action.(core.CreateAction).GetObject().(*corev1.Event).Reason)
+				}
+			}
+			if tc.ExpectedEventReason == "" && len(eventReasons) != 0 {
+				t.Errorf("%s: Expected no event, got %v", k, eventReasons)
+			}
+			if tc.ExpectedEventReason != "" && (len(eventReasons) != 1 || eventReasons[0] != tc.ExpectedEventReason) {
+				t.Errorf("%s: Expected a %s event, got %v", k, tc.ExpectedEventReason, eventReasons)
+			}
+
+			if tc.ExpectedVerb == "" {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expected no action, got %#v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches(tc.ExpectedVerb, "replicasets") {
+				t.Fatalf("%s: Expected to %s replicaset, got %#v", k, tc.ExpectedVerb, actions)
+			}
+			if tc.ExpectedVerb == "delete" {
+				return
+			}
+			replicaset := actions[0].(core.CreateAction).GetObject().(*appsv1.ReplicaSet)
+			if replicaset.Namespace != superDefaultNSName || replicaset.Name != "web" {
+				t.Errorf("%s: Expected %s/web, got %s/%s", k, superDefaultNSName, replicaset.Namespace, replicaset.Name)
+			}
+			if *replicaset.Spec.Replicas != tc.ExpectedReplicas {
+				t.Errorf("%s: Expected %d replicas, got %d", k, tc.ExpectedReplicas, *replicaset.Spec.Replicas)
+			}
+			if automount := replicaset.Spec.Template.Spec.AutomountServiceAccountToken; automount == nil || *automount {
+				t.Errorf("%s: Expected the service account token not to be mounted in the pod template", k)
+			}
+		})
+	}
+}
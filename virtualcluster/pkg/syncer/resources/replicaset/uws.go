@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicaset
+
+import (
+	"context"
+	"fmt"
+
+	pkgerr "github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+// StartUWS starts the upward syncer
+// and blocks until an empty struct is sent to the stop channel.
+func (c *controller) StartUWS(stopCh <-chan struct{}) error {
+	if !cache.WaitForCacheSync(stopCh, c.replicasetSynced) {
+		return fmt.Errorf("failed to wait for caches to sync")
+	}
+	return c.UpwardController.Start(stopCh)
+}
+
+func (c *controller) BackPopulate(key string) error {
+	pNamespace, pName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("invalid resource key %v: %v", key, err))
+		return nil
+	}
+
+	pReplicaSet, err := c.replicasetLister.ReplicaSets(pNamespace).Get(pName)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	clusterName, vNamespace := conversion.GetVirtualOwner(pReplicaSet)
+	if clusterName == "" || vNamespace == "" {
+		klog.Infof("drop replicaset %s/%s which is not belongs to any tenant", pNamespace, pName)
+		return nil
+	}
+
+	vReplicaSet := &appsv1.ReplicaSet{}
+	if err := c.MultiClusterController.Get(clusterName, vNamespace, pName, vReplicaSet); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return pkgerr.Wrapf(err, "could not find pReplicaSet %s/%s's vReplicaSet in controller cache", vNamespace, pName)
+	}
+	if pReplicaSet.Annotations[constants.LabelUID] != string(vReplicaSet.UID) {
+		return fmt.Errorf("backPopulated pReplicaSet %s/%s delegated UID is different from updated object", pReplicaSet.Namespace, pReplicaSet.Name)
+	}
+
+	if mc.IsSyncPaused(vReplicaSet) {
+		return nil
+	}
+
+	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed to create client from cluster %s config", clusterName)
+	}
+
+	vc, err := util.GetVirtualClusterObject(c.MultiClusterController, clusterName)
+	if err != nil {
+		return pkgerr.Wrapf(err, "failed to get spec of cluster %s", clusterName)
+	}
+
+	var newReplicaSet *appsv1.ReplicaSet
+	updatedMeta := conversion.Equality(c.Config, vc).CheckUWObjectMetaEquality(&pReplicaSet.ObjectMeta, &vReplicaSet.ObjectMeta)
+	if updatedMeta != nil {
+		newReplicaSet = vReplicaSet.DeepCopy()
+		newReplicaSet.ObjectMeta = *updatedMeta
+		c.UpwardController.WaitForTenantWrite()
+		if _, err = tenantClient.AppsV1().ReplicaSets(vReplicaSet.Namespace).Update(context.TODO(), newReplicaSet, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to back populate replicaset %s/%s meta update for cluster %s: %v", vReplicaSet.Namespace, vReplicaSet.Name, clusterName, err)
+		}
+	}
+
+	status := tenantReplicaSetStatus(c.Config, vc, pReplicaSet, vReplicaSet)
+	if !equality.Semantic.DeepEqual(vReplicaSet.Status, status) {
+		if newReplicaSet == nil {
+			newReplicaSet = vReplicaSet.DeepCopy()
+		} else {
+			// vReplicaSet has been updated, let us fetch the lastest version.
+			if newReplicaSet, err = tenantClient.AppsV1().ReplicaSets(vReplicaSet.Namespace).Get(context.TODO(), vReplicaSet.Name, metav1.GetOptions{}); err != nil {
+				return fmt.Errorf("failed to retrieve vReplicaSet %s/%s from cluster %s: %v", vReplicaSet.Namespace, vReplicaSet.Name, clusterName, err)
+			}
+		}
+		newReplicaSet.Status = status
+		c.UpwardController.WaitForTenantWrite()
+		if _, err = tenantClient.AppsV1().ReplicaSets(vReplicaSet.Namespace).UpdateStatus(context.TODO(), newReplicaSet, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to back populate replicaset %s/%s status update for cluster %s: %v", vReplicaSet.Namespace, vReplicaSet.Name, clusterName, err)
+		}
+	}
+	return nil
+}
+
+// tenantReplicaSetStatus returns the status of the super ReplicaSet as the tenant should see it.
+// The generations of the super and tenant ReplicaSets are unrelated, so the observedGeneration
+// becomes the tenant generation once the super ReplicaSet controller has observed the spec
+// synced from the tenant, and stays unchanged otherwise.
+func tenantReplicaSetStatus(syncerConfig *config.SyncerConfiguration, vc *v1alpha1.VirtualCluster, pReplicaSet, vReplicaSet *appsv1.ReplicaSet) appsv1.ReplicaSetStatus {
+	status := pReplicaSet.Status.DeepCopy()
+	status.ObservedGeneration = vReplicaSet.Status.ObservedGeneration
+	if pReplicaSet.Status.ObservedGeneration >= pReplicaSet.Generation {
+		updated := conversion.Equality(syncerConfig, vc).CheckReplicaSetEquality(pReplicaSet, vReplicaSet)
+		if updated == nil || equality.Semantic.DeepEqual(updated.Spec, pReplicaSet.Spec) {
+			status.ObservedGeneration = vReplicaSet.Generation
+		}
+	}
+	return *status
+}
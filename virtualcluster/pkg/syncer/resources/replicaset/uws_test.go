@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replicaset
+
+import (
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/client-go/testing"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
+)
+
+func TestUWReplicaSet(t *testing.T) {
+	defaultClusterKey := conversion.ToClusterKey(testTenant(true))
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	withStatus := func(d *appsv1.ReplicaSet, generation, observedGeneration int64, readyReplicas int32) *appsv1.ReplicaSet {
+		d.Generation = generation
+		d.Status = appsv1.ReplicaSetStatus{
+			ObservedGeneration:   observedGeneration,
+			Replicas:             *d.Spec.Replicas,
+			FullyLabeledReplicas: *d.Spec.Replicas,
+			ReadyReplicas:        readyReplicas,
+			AvailableReplicas:    readyReplicas,
+		}
+		return d
+	}
+
+	testcases := map[string]struct {
+		ExistingObjectInSuper  []runtime.Object
+		ExistingObjectInTenant []runtime.Object
+		ExpectedStatus         *appsv1.ReplicaSetStatus
+		ExpectedError          string
+	}{
+		"pReplicaSet exists but vReplicaSet does not exist": {
+			ExistingObjectInSuper: []runtime.Object{superReplicaSet("web", superDefaultNSName, "12345", defaultClusterKey, 2)},
+		},
+		"pReplicaSet exists, vReplicaSet exists with different uid": {
+			ExistingObjectInSuper:  []runtime.Object{superReplicaSet("web", superDefaultNSName, "123456", defaultClusterKey, 2)},
+			ExistingObjectInTenant: []runtime.Object{tenantReplicaSet("web", "default", "12345", 2)},
+			ExpectedError:          "delegated UID is different",
+		},
+		"status of the synced spec": {
+			ExistingObjectInSuper:  []runtime.Object{withStatus(superReplicaSet("web", superDefaultNSName, "12345", defaultClusterKey, 2), 3, 3, 1)},
+			ExistingObjectInTenant: []runtime.Object{withStatus(tenantReplicaSet("web", "default", "12345", 2), 1, 0, 0)},
+			ExpectedStatus:         &withStatus(tenantReplicaSet("web", "default", "12345", 2), 1, 1, 1).Status,
+		},
+		"status of a spec not observed yet": {
+			ExistingObjectInSuper:  []runtime.Object{withStatus(superReplicaSet("web", superDefaultNSName, "12345", defaultClusterKey, 2), 4, 3, 2)},
+			ExistingObjectInTenant: []runtime.Object{withStatus(tenantReplicaSet("web", "default", "12345", 2), 2, 1, 1)},
+			ExpectedStatus:         &withStatus(tenantReplicaSet("web", "default", "12345", 2), 2, 1, 2).Status,
+		},
+		"status of a spec not synced yet": {
+			ExistingObjectInSuper:  []runtime.Object{withStatus(superReplicaSet("web", superDefaultNSName, "12345", defaultClusterKey, 2), 3, 3, 2)},
+			ExistingObjectInTenant: []runtime.Object{withStatus(tenantReplicaSet("web", "default", "12345", 5), 2, 1, 1)},
+			ExpectedStatus: &appsv1.ReplicaSetStatus{
+				ObservedGeneration:   1,
+				Replicas:             2,
+				FullyLabeledReplicas: 2,
+				ReadyReplicas:        2,
+				AvailableReplicas:    2,
+			},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunUpwardSync(NewReplicaSetController, testTenant(true), tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, superDefaultNSName+"/web", nil)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+
+			if reconcileErr != nil {
+				if tc.ExpectedError == "" {
+					t.Errorf("expected no error, but got \"%v\"", reconcileErr)
+				} else if !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("expected error msg \"%s\", but got \"%v\"", tc.ExpectedError, reconcileErr)
+				}
+			} else if tc.ExpectedError != "" {
+				t.Errorf("expected error msg \"%s\", but got empty", tc.ExpectedError)
+			}
+
+			var status *appsv1.ReplicaSetStatus
+			for _, action := range actions {
+				if action.Matches("update", "replicasets") && action.GetSubresource() == "status" {
+					status = &action.(core.UpdateAction).GetObject().(*appsv1.ReplicaSet).Status
+				}
+			}
+			if !equality.Semantic.DeepEqual(status, tc.ExpectedStatus) {
+				t.Errorf("%s: Expected status %+v, got %+v", k, tc.ExpectedStatus, status)
+			}
+		})
+	}
+}
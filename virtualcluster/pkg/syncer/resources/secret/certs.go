@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"bytes"
+	"crypto/x509"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	certutil "k8s.io/client-go/util/cert"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+// certKeys are the secret keys whose certificates are recorded on the super control plane secret,
+// with the annotations recording the serial number and the expiry of each.
+var certKeys = []struct {
+	key, serialAnnotation, notAfterAnnotation string
+}{
+	{corev1.TLSCertKey, constants.LabelTLSCertSerial, constants.LabelTLSCertNotAfter},
+	{corev1.ServiceAccountRootCAKey, constants.LabelCACertSerial, constants.LabelCACertNotAfter},
+}
+
+// soonestExpiring returns the certificate of a PEM bundle that expires first, or nil if the data
+// does not hold any certificate.
+func soonestExpiring(data []byte) *x509.Certificate {
+	if len(data) == 0 {
+		return nil
+	}
+	certs, err := certutil.ParseCertsPEM(data)
+	if err != nil {
+		return nil
+	}
+	var soonest *x509.Certificate
+	for _, cert := range certs {
+		if soonest == nil || cert.NotAfter.Before(soonest.NotAfter) {
+			soonest = cert
+		}
+	}
+	return soonest
+}
+
+// certAnnotations returns the certificate annotations of a secret and the expiry of its soonest
+// expiring certificate. Keys that are missing or do not hold PEM certificates are skipped, so
+// a ca.crt-only secret only gets the ca.crt annotations and a secret without certificates gets
+// none and a zero expiry.
+func certAnnotations(secret *corev1.Secret) (map[string]string, time.Time) {
+	annotations := make(map[string]string)
+	var notAfter time.Time
+	for _, k := range certKeys {
+		cert := soonestExpiring(secret.Data[k.key])
+		if cert == nil {
+			continue
+		}
+		annotations[k.serialAnnotation] = cert.SerialNumber.Text(16)
+		annotations[k.notAfterAnnotation] = cert.NotAfter.UTC().Format(time.RFC3339)
+		if notAfter.IsZero() || cert.NotAfter.Before(notAfter) {
+			notAfter = cert.NotAfter
+		}
+	}
+	return annotations, notAfter
+}
+
+// updatedCertAnnotations returns the annotations of the secret with the certificate annotations
+// replaced by the given ones, and whether they differ.
+func updatedCertAnnotations(secret *corev1.Secret, certs map[string]string) (map[string]string, bool) {
+	updated := make(map[string]string, len(secret.Annotations)+len(certs))
+	for k, v := range secret.Annotations {
+		updated[k] = v
+	}
+	changed := false
+	for _, k := range certKeys {
+		for _, key := range []string{k.serialAnnotation, k.notAfterAnnotation} {
+			v, ok := certs[key]
+			if pv, pok := secret.Annotations[key]; pok != ok || pv != v {
+				changed = true
+			}
+			if ok {
+				updated[key] = v
+			} else {
+				delete(updated, key)
+			}
+		}
+	}
+	return updated, changed
+}
+
+// tlsDataChanged reports whether an update of a tenant secret rotated its certificate or key,
+// these updates are reconciled ahead of the other secrets of the cluster.
+func tlsDataChanged(oldObj, newObj interface{}) bool {
+	oldSecret, ok := oldObj.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	newSecret, ok := newObj.(*corev1.Secret)
+	if !ok {
+		return false
+	}
+	for _, key := range []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey, corev1.ServiceAccountRootCAKey} {
+		if !bytes.Equal(oldSecret.Data[key], newSecret.Data[key]) {
+			return true
+		}
+	}
+	return false
+}
+
+// certExpiryTracker keeps the soonest certificate expiry of every synced secret, per cluster,
+// and exports the soonest of them as the secret cert expiry metric of the cluster.
+type certExpiryTracker struct {
+	sync.Mutex
+	notAfter map[string]map[string]time.Time
+}
+
+func newCertExpiryTracker() *certExpiryTracker {
+	return &certExpiryTracker{notAfter: make(map[string]map[string]time.Time)}
+}
+
+// set records the expiry of the secret, a zero expiry forgets the secret.
+func (t *certExpiryTracker) set(cluster, key string, notAfter time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	secrets := t.notAfter[cluster]
+	if notAfter.IsZero() {
+		if _, ok := secrets[key]; !ok {
+			return
+		}
+		delete(secrets, key)
+		if len(secrets) == 0 {
+			delete(t.notAfter, cluster)
+		}
+	} else {
+		if secrets == nil {
+			secrets = make(map[string]time.Time)
+			t.notAfter[cluster] = secrets
+		}
+		secrets[key] = notAfter
+	}
+	t.export(cluster)
+}
+
+func (t *certExpiryTracker) removeCluster(cluster string) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.notAfter, cluster)
+	t.export(cluster)
+}
+
+// soonest returns the soonest expiry of the cluster, or a zero time if none is tracked.
+func (t *certExpiryTracker) soonest(cluster string) time.Time {
+	t.Lock()
+	defer t.Unlock()
+	return t.soonestLocked(cluster)
+}
+
+func (t *certExpiryTracker) soonestLocked(cluster string) time.Time {
+	var soonest time.Time
+	for _, notAfter := range t.notAfter[cluster] {
+		if soonest.IsZero() || notAfter.Before(soonest) {
+			soonest = notAfter
+		}
+	}
+	return soonest
+}
+
+func (t *certExpiryTracker) export(cluster string) {
+	soonest := t.soonestLocked(cluster)
+	if soonest.IsZero() {
+		metrics.DeleteSecretCertExpiry(cluster)
+		return
+	}
+	metrics.RecordSecretCertExpiry(cluster, soonest)
+}
+
+// certExpiryListener forgets the certificate expiries of a cluster when it is removed.
+type certExpiryListener struct {
+	listener.ClusterChangeListener
+	certs *certExpiryTracker
+}
+
+func (l certExpiryListener) RemoveCluster(cluster mc.ClusterInterface) {
+	l.ClusterChangeListener.RemoveCluster(cluster)
+	l.certs.removeCluster(cluster.GetClusterName())
+}
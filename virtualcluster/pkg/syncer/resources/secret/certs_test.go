@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secret
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+)
+
+var (
+	certNotAfter1 = time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	certNotAfter2 = time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// newCertPEM returns a PEM encoded self-signed certificate with the given serial number and expiry.
+func newCertPEM(serial int64, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestCertAnnotations(t *testing.T) {
+	bundle := append(newCertPEM(0x2a, certNotAfter2), newCertPEM(0xff, certNotAfter1)...)
+
+	testcases := map[string]struct {
+		data                map[string][]byte
+		expectedAnnotations map[string]string
+		expectedNotAfter    time.Time
+	}{
+		"no certificates": {
+			data:                map[string][]byte{"password": []byte("secret")},
+			expectedAnnotations: map[string]string{},
+		},
+		"tls.crt": {
+			data: map[string][]byte{
+				corev1.TLSCertKey:       newCertPEM(0x2a, certNotAfter1),
+				corev1.TLSPrivateKeyKey: []byte("key"),
+			},
+			expectedAnnotations: map[string]string{
+				constants.LabelTLSCertSerial:   "2a",
+				constants.LabelTLSCertNotAfter: "2030-01-01T00:00:00Z",
+			},
+			expectedNotAfter: certNotAfter1,
+		},
+		"ca.crt only": {
+			data: map[string][]byte{
+				corev1.ServiceAccountRootCAKey: newCertPEM(0x2a, certNotAfter2),
+			},
+			expectedAnnotations: map[string]string{
+				constants.LabelCACertSerial:   "2a",
+				constants.LabelCACertNotAfter: "2031-01-01T00:00:00Z",
+			},
+			expectedNotAfter: certNotAfter2,
+		},
+		"tls.crt and ca.crt": {
+			data: map[string][]byte{
+				corev1.TLSCertKey:              newCertPEM(0x2a, certNotAfter2),
+				corev1.ServiceAccountRootCAKey: newCertPEM(0x2b, certNotAfter1),
+			},
+			expectedAnnotations: map[string]string{
+				constants.LabelTLSCertSerial:   "2a",
+				constants.LabelTLSCertNotAfter: "2031-01-01T00:00:00Z",
+				constants.LabelCACertSerial:    "2b",
+				constants.LabelCACertNotAfter:  "2030-01-01T00:00:00Z",
+			},
+			expectedNotAfter: certNotAfter1,
+		},
+		"bundle records the soonest expiring certificate": {
+			data: map[string][]byte{
+				corev1.ServiceAccountRootCAKey: bundle,
+			},
+			expectedAnnotations: map[string]string{
+				constants.LabelCACertSerial:   "ff",
+				constants.LabelCACertNotAfter: "2030-01-01T00:00:00Z",
+			},
+			expectedNotAfter: certNotAfter1,
+		},
+		"unparsable tls.crt": {
+			data: map[string][]byte{
+				corev1.TLSCertKey: []byte("not a certificate"),
+			},
+			expectedAnnotations: map[string]string{},
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			annotations, notAfter := certAnnotations(&corev1.Secret{Data: tc.data})
+			if !reflect.DeepEqual(annotations, tc.expectedAnnotations) {
+				t.Errorf("expected annotations %v, got %v", tc.expectedAnnotations, annotations)
+			}
+			if !notAfter.Equal(tc.expectedNotAfter) {
+				t.Errorf("expected expiry %v, got %v", tc.expectedNotAfter, notAfter)
+			}
+		})
+	}
+}
+
+func TestTLSDataChanged(t *testing.T) {
+	secret := func(data map[string]string) *corev1.Secret {
+		s := &corev1.Secret{Data: map[string][]byte{}}
+		for k, v := range data {
+			s.Data[k] = []byte(v)
+		}
+		return s
+	}
+
+	testcases := map[string]struct {
+		oldObj, newObj interface{}
+		expected       bool
+	}{
+		"no change": {
+			oldObj:   secret(map[string]string{corev1.TLSCertKey: "crt", corev1.TLSPrivateKeyKey: "key"}),
+			newObj:   secret(map[string]string{corev1.TLSCertKey: "crt", corev1.TLSPrivateKeyKey: "key"}),
+			expected: false,
+		},
+		"other key changed": {
+			oldObj:   secret(map[string]string{corev1.TLSCertKey: "crt", "other": "1"}),
+			newObj:   secret(map[string]string{corev1.TLSCertKey: "crt", "other": "2"}),
+			expected: false,
+		},
+		"tls.crt changed": {
+			oldObj:   secret(map[string]string{corev1.TLSCertKey: "crt1"}),
+			newObj:   secret(map[string]string{corev1.TLSCertKey: "crt2"}),
+			expected: true,
+		},
+		"tls.key changed": {
+			oldObj:   secret(map[string]string{corev1.TLSPrivateKeyKey: "key1"}),
+			newObj:   secret(map[string]string{corev1.TLSPrivateKeyKey: "key2"}),
+			expected: true,
+		},
+		"ca.crt added": {
+			oldObj:   secret(map[string]string{}),
+			newObj:   secret(map[string]string{corev1.ServiceAccountRootCAKey: "ca"}),
+			expected: true,
+		},
+		"not a secret": {
+			oldObj:   &corev1.ConfigMap{},
+			newObj:   &corev1.ConfigMap{},
+			expected: false,
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			if got := tlsDataChanged(tc.oldObj, tc.newObj); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestCertExpiryTracker(t *testing.T) {
+	tracker := newCertExpiryTracker()
+
+	tracker.set("cluster1", "default/a", certNotAfter2)
+	tracker.set("cluster1", "default/b", certNotAfter1)
+	tracker.set("cluster2", "default/a", certNotAfter2)
+	if got := tracker.soonest("cluster1"); !got.Equal(certNotAfter1) {
+		t.Errorf("expected cluster1 soonest expiry %v, got %v", certNotAfter1, got)
+	}
+
+	// b is rotated.
+	tracker.set("cluster1", "default/b", certNotAfter2.Add(time.Hour))
+	if got := tracker.soonest("cluster1"); !got.Equal(certNotAfter2) {
+		t.Errorf("expected cluster1 soonest expiry %v after rotation, got %v", certNotAfter2, got)
+	}
+
+	// a is deleted.
+	tracker.set("cluster1", "default/a", time.Time{})
+	if got := tracker.soonest("cluster1"); !got.Equal(certNotAfter2.Add(time.Hour)) {
+		t.Errorf("expected cluster1 soonest expiry %v after deletion, got %v", certNotAfter2.Add(time.Hour), got)
+	}
+
+	tracker.removeCluster("cluster1")
+	if got := tracker.soonest("cluster1"); !got.IsZero() {
+		t.Errorf("expected no cluster1 expiry after removal, got %v", got)
+	}
+	if got := tracker.soonest("cluster2"); !got.Equal(certNotAfter2) {
+		t.Errorf("expected cluster2 soonest expiry %v, got %v", certNotAfter2, got)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -33,6 +34,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
 var numMissMatchedOpaqueSecrets uint64
@@ -106,6 +108,13 @@ func (c *controller) PatrollerDo() {
 			}
 		}
 
+		if shouldDelete && !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "secret", clusterName, vNamespace+"/"+vSecretName, pSecret.Annotations[constants.LabelUID],
+			util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+				return tenantClient.CoreV1().Secrets(vNamespace).Get(context.TODO(), vSecretName, metav1.GetOptions{})
+			})) {
+			shouldDelete = false
+		}
+
 		if shouldDelete {
 			deleteOptions := metav1.NewPreconditionDeleteOptions(string(pSecret.UID))
 			if err := c.secretClient.Secrets(pSecret.Namespace).Delete(context.TODO(), pSecret.Name, *deleteOptions); err != nil {
@@ -130,6 +139,9 @@ func (c *controller) checkSecretOfTenantCluster(clusterName string) {
 	klog.V(4).Infof("check secrets consistency in cluster %s", clusterName)
 
 	for i, vSecret := range secretList.Items {
+		if mc.IsSyncPaused(&secretList.Items[i]) {
+			continue
+		}
 		targetNamespace := conversion.ToSuperClusterNamespace(clusterName, vSecret.Namespace)
 
 		if vSecret.Type == corev1.SecretTypeServiceAccountToken {
@@ -29,6 +29,8 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/listener"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
@@ -49,6 +51,8 @@ type controller struct {
 	// super control plane secret lister/synced function
 	secretLister listersv1.SecretLister
 	secretSynced cache.InformerSynced
+	// soonest certificate expiry of the synced secrets of each cluster
+	certs *certExpiryTracker
 }
 
 func NewSecretController(config *config.SyncerConfiguration,
@@ -58,20 +62,30 @@ func NewSecretController(config *config.SyncerConfiguration,
 	vcInformer vcinformers.VirtualClusterInformer,
 	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
 	c := &controller{
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
 		secretClient: client.CoreV1(),
+		certs:        newCertExpiryTracker(),
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.Secret{}, &corev1.SecretList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.Secret{}, &corev1.SecretList{}, c, mc.WithSyncerConfig(config), mc.WithPrioritizedUpdates(tlsDataChanged), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
 
-	c.secretLister = informer.Core().V1().Secrets().Lister()
-	if options.IsFake {
+	if util.IsUncached(config, "secret") {
+		c.secretLister = util.NewUncachedSecretLister(client.CoreV1())
 		c.secretSynced = func() bool { return true }
 	} else {
-		c.secretSynced = informer.Core().V1().Secrets().Informer().HasSynced
+		c.secretLister = informer.Core().V1().Secrets().Lister()
+		if options.IsFake {
+			c.secretSynced = func() bool { return true }
+		} else {
+			util.TrackWatchErrors(informer.Core().V1().Secrets().Informer(), "secrets")
+			c.secretSynced = informer.Core().V1().Secrets().Informer().HasSynced
+		}
 	}
 
 	c.Patroller, err = pa.NewPatroller(&corev1.Secret{}, c, pa.WithOptions(options.PatrolOptions))
@@ -81,3 +95,10 @@ func NewSecretController(config *config.SyncerConfiguration,
 
 	return c, nil
 }
+
+func (c *controller) GetListener() listener.ClusterChangeListener {
+	return certExpiryListener{
+		ClusterChangeListener: listener.NewMCControllerListener(c.MultiClusterController, mc.WatchOptions{AttachUID: true}),
+		certs:                 c.certs,
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -31,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -43,7 +45,7 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 
 // The reconcile logic for tenant control plane secret informer
 func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
-	klog.V(4).Infof("reconcile secret %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	logsampling.V(4, "secret").Infof("reconcile secret %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
 	vSecret := &corev1.Secret{}
 	err := c.MultiClusterController.Get(request.ClusterName, request.Namespace, request.Name, vSecret)
@@ -85,6 +87,9 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 
 	switch {
 	case !reflect.DeepEqual(vSecret, &corev1.Secret{}) && pSecret == nil:
+		if paused := c.MultiClusterController.CreationPausedFor(request.ClusterName); paused > 0 {
+			return reconciler.Result{RequeueAfter: paused}, nil
+		}
 		err := c.reconcileSecretCreate(request.ClusterName, targetNamespace, request.UID, vSecret)
 		if err != nil {
 			klog.Errorf("failed reconcile secret %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
@@ -96,6 +101,8 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 			klog.Errorf("failed reconcile secret %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
+		c.certs.set(request.ClusterName, request.Namespace+"/"+request.Name, time.Time{})
 	case vSecret != nil && pSecret != nil:
 		err := c.reconcileSecretUpdate(request.ClusterName, targetNamespace, request.UID, pSecret, vSecret)
 		if err != nil {
@@ -131,11 +138,14 @@ func (c *controller) reconcileServiceAccountSecretCreate(clusterName, targetName
 		klog.Infof("secret %s/%s of cluster %s already exist in super control plane", targetNamespace, pSecret.Name, clusterName)
 		return nil
 	}
-
-	return err
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, vSecret.Namespace, vSecret.Name)
+	return nil
 }
 
-func (c *controller) reconcileServiceAccountSecretUpdate(targetNamespace string, pSecret, vSecret *corev1.Secret) error {
+func (c *controller) reconcileServiceAccountSecretUpdate(clusterName, targetNamespace string, pSecret, vSecret *corev1.Secret) error {
 	updatedBinaryData, equal := conversion.Equality(c.Config, nil).CheckBinaryDataEquality(pSecret.Data, vSecret.Data)
 	if equal {
 		return nil
@@ -147,6 +157,7 @@ func (c *controller) reconcileServiceAccountSecretUpdate(targetNamespace string,
 	if err != nil {
 		return err
 	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, vSecret.Namespace, vSecret.Name)
 
 	return nil
 }
@@ -157,7 +168,18 @@ func (c *controller) reconcileNormalSecretCreate(clusterName, targetNamespace, r
 		return err
 	}
 
-	pSecret, err := c.secretClient.Secrets(targetNamespace).Create(context.TODO(), newObj.(*corev1.Secret), metav1.CreateOptions{})
+	newSecret := newObj.(*corev1.Secret)
+	certs, notAfter := certAnnotations(secret)
+	if len(certs) != 0 {
+		if newSecret.Annotations == nil {
+			newSecret.Annotations = make(map[string]string)
+		}
+		for k, v := range certs {
+			newSecret.Annotations[k] = v
+		}
+	}
+
+	pSecret, err := c.secretClient.Secrets(targetNamespace).Create(context.TODO(), newSecret, metav1.CreateOptions{})
 	if apierrors.IsAlreadyExists(err) {
 		if pSecret.Annotations[constants.LabelUID] == requestUID {
 			klog.Infof("secret %s/%s of cluster %s already exist in super control plane", targetNamespace, secret.Name, clusterName)
@@ -165,14 +187,19 @@ func (c *controller) reconcileNormalSecretCreate(clusterName, targetNamespace, r
 		}
 		return fmt.Errorf("pSecret %s/%s exists but its delegated object UID is different", targetNamespace, pSecret.Name)
 	}
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, secret.Namespace, secret.Name)
+	c.certs.set(clusterName, secret.Namespace+"/"+secret.Name, notAfter)
 
-	return err
+	return nil
 }
 
 func (c *controller) reconcileSecretUpdate(clusterName, targetNamespace, requestUID string, pSecret, vSecret *corev1.Secret) error {
 	switch vSecret.Type {
 	case corev1.SecretTypeServiceAccountToken:
-		return c.reconcileServiceAccountSecretUpdate(targetNamespace, pSecret, vSecret)
+		return c.reconcileServiceAccountSecretUpdate(clusterName, targetNamespace, pSecret, vSecret)
 	default:
 		return c.reconcileNormalSecretUpdate(clusterName, targetNamespace, requestUID, pSecret, vSecret)
 	}
@@ -187,12 +214,25 @@ func (c *controller) reconcileNormalSecretUpdate(clusterName, targetNamespace, r
 		return err
 	}
 	updatedSecret := conversion.Equality(c.Config, vc).CheckSecretEquality(pSecret, vSecret)
+	certs, notAfter := certAnnotations(vSecret)
+	target := pSecret
+	if updatedSecret != nil {
+		target = updatedSecret
+	}
+	if annotations, changed := updatedCertAnnotations(target, certs); changed {
+		if updatedSecret == nil {
+			updatedSecret = pSecret.DeepCopy()
+		}
+		updatedSecret.Annotations = annotations
+	}
 	if updatedSecret != nil {
 		_, err = c.secretClient.Secrets(targetNamespace).Update(context.TODO(), updatedSecret, metav1.UpdateOptions{})
 		if err != nil {
 			return err
 		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vSecret.Namespace, vSecret.Name)
 	}
+	c.certs.set(clusterName, vSecret.Namespace+"/"+vSecret.Name, notAfter)
 
 	return nil
 }
@@ -18,6 +18,7 @@ package secret
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -27,12 +28,18 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 )
 
@@ -167,6 +174,28 @@ func TestDWSecretCreation(t *testing.T) {
 			},
 			ExpectedError: "delegated UID is different",
 		},
+		"new tls secret": {
+			ExistingObjectInTenant: []runtime.Object{
+				applyTLSDataToSecret(tenantSecret("tls-secret", "default", "12345", corev1.SecretTypeTLS), tlsCert1, nil),
+			},
+			ExpectedCreatedPObject: []runtime.Object{
+				applyTLSDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "tls-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeTLS), tlsCert1, map[string]string{
+					constants.LabelTLSCertSerial:   "1",
+					constants.LabelTLSCertNotAfter: "2030-01-01T00:00:00Z",
+				}),
+			},
+		},
+		"new ca.crt only secret": {
+			ExistingObjectInTenant: []runtime.Object{
+				applyCADataToSecret(tenantSecret("ca-secret", "default", "12345", corev1.SecretTypeOpaque), tlsCert2, nil),
+			},
+			ExpectedCreatedPObject: []runtime.Object{
+				applyCADataToSecret(superSecret(defaultVCName, defaultVCNamespace, "ca-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeOpaque), tlsCert2, map[string]string{
+					constants.LabelCACertSerial:   "2",
+					constants.LabelCACertNotAfter: "2031-01-01T00:00:00Z",
+				}),
+			},
+		},
 		"new service account secret": {
 			ExistingObjectInTenant: []runtime.Object{
 				tenantSecret("sa-secret", "default", "12345", corev1.SecretTypeServiceAccountToken),
@@ -450,6 +479,43 @@ func applyDataToSecret(secret *corev1.Secret, data string) *corev1.Secret {
 	return secret
 }
 
+func applyBinaryDataToSecret(secret *corev1.Secret, data []byte) *corev1.Secret {
+	secret.Data = map[string][]byte{
+		"binary": append([]byte(nil), data...),
+	}
+	return secret
+}
+
+var (
+	tlsCert1 = newCertPEM(1, certNotAfter1)
+	tlsCert2 = newCertPEM(2, certNotAfter2)
+)
+
+func applyTLSDataToSecret(secret *corev1.Secret, crt []byte, annotations map[string]string) *corev1.Secret {
+	secret.Data = map[string][]byte{
+		corev1.TLSCertKey:       crt,
+		corev1.TLSPrivateKeyKey: []byte("key"),
+	}
+	return applyAnnotationsToSecret(secret, annotations)
+}
+
+func applyCADataToSecret(secret *corev1.Secret, crt []byte, annotations map[string]string) *corev1.Secret {
+	secret.Data = map[string][]byte{
+		corev1.ServiceAccountRootCAKey: crt,
+	}
+	return applyAnnotationsToSecret(secret, annotations)
+}
+
+func applyAnnotationsToSecret(secret *corev1.Secret, annotations map[string]string) *corev1.Secret {
+	if len(annotations) != 0 && secret.Annotations == nil {
+		secret.Annotations = make(map[string]string)
+	}
+	for k, v := range annotations {
+		secret.Annotations[k] = v
+	}
+	return secret
+}
+
 func TestDWSecretUpdate(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -494,6 +560,83 @@ func TestDWSecretUpdate(t *testing.T) {
 				applyDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "normal-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeOpaque), "data2"),
 			},
 		},
+		"secret no diff in binary data": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyBinaryDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "normal-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeOpaque), []byte{0x00, 0xff, 0x80}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyBinaryDataToSecret(tenantSecret("normal-secret", "default", "12345", corev1.SecretTypeOpaque), []byte{0x00, 0xff, 0x80}),
+			},
+			ExpectedNoOperation: true,
+		},
+		"secret diff in binary data": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyBinaryDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "normal-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeOpaque), []byte{0x00, 0xff, 0x80}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyBinaryDataToSecret(tenantSecret("normal-secret", "default", "12345", corev1.SecretTypeOpaque), []byte{0x00, 0xff, 0x81}),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyBinaryDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "normal-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeOpaque), []byte{0x00, 0xff, 0x81}),
+			},
+		},
+		"tls secret no diff": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyTLSDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "tls-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeTLS), tlsCert1, map[string]string{
+					constants.LabelTLSCertSerial:   "1",
+					constants.LabelTLSCertNotAfter: "2030-01-01T00:00:00Z",
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyTLSDataToSecret(tenantSecret("tls-secret", "default", "12345", corev1.SecretTypeTLS), tlsCert1, nil),
+			},
+			ExpectedNoOperation: true,
+		},
+		"tls secret rotated": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyTLSDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "tls-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeTLS), tlsCert1, map[string]string{
+					constants.LabelTLSCertSerial:   "1",
+					constants.LabelTLSCertNotAfter: "2030-01-01T00:00:00Z",
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyTLSDataToSecret(tenantSecret("tls-secret", "default", "12345", corev1.SecretTypeTLS), tlsCert2, nil),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyTLSDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "tls-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeTLS), tlsCert2, map[string]string{
+					constants.LabelTLSCertSerial:   "2",
+					constants.LabelTLSCertNotAfter: "2031-01-01T00:00:00Z",
+				}),
+			},
+		},
+		"tls secret missing cert annotations": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyTLSDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "tls-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeTLS), tlsCert1, nil),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyTLSDataToSecret(tenantSecret("tls-secret", "default", "12345", corev1.SecretTypeTLS), tlsCert1, nil),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyTLSDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "tls-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeTLS), tlsCert1, map[string]string{
+					constants.LabelTLSCertSerial:   "1",
+					constants.LabelTLSCertNotAfter: "2030-01-01T00:00:00Z",
+				}),
+			},
+		},
+		"ca.crt removed": {
+			ExistingObjectInSuper: []runtime.Object{
+				applyCADataToSecret(superSecret(defaultVCName, defaultVCNamespace, "ca-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeOpaque), tlsCert1, map[string]string{
+					constants.LabelCACertSerial:   "1",
+					constants.LabelCACertNotAfter: "2030-01-01T00:00:00Z",
+				}),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				applyDataToSecret(tenantSecret("ca-secret", "default", "12345", corev1.SecretTypeOpaque), "data1"),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				applyDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "ca-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeOpaque), "data1"),
+			},
+		},
 		"service account secret no diff": {
 			ExistingObjectInSuper: []runtime.Object{
 				applyDataToSecret(superServiceAccountSecret(defaultVCName, defaultVCNamespace, "sa-secret", superDefaultNSName, "12345", defaultClusterKey), "data1"),
@@ -574,3 +717,66 @@ func generateNameReactor(action core.Action) (handled bool, ret runtime.Object,
 	}
 	return false, nil, nil
 }
+
+func TestDWSecretUncached(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Spec: v1alpha1.VirtualClusterSpec{},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	defaultVCName, defaultVCNamespace := testTenant.Name, testTenant.Namespace
+	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
+
+	pSecret := applyDataToSecret(superSecret(defaultVCName, defaultVCNamespace, "normal-secret", superDefaultNSName, "12345", defaultClusterKey, corev1.SecretTypeOpaque), "data1")
+	vSecret := applyDataToSecret(tenantSecret("normal-secret", "default", "12345", corev1.SecretTypeOpaque), "data2")
+
+	var superInformer informers.SharedInformerFactory
+	newController := func(cfg *config.SyncerConfiguration, client clientset.Interface, informer informers.SharedInformerFactory, vcClient vcclient.Interface, vcInformer vcinformers.VirtualClusterInformer, options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+		cfg.UncachedResources = []string{"secret"}
+		superInformer = informer
+		return NewSecretController(cfg, client, informer, vcClient, vcInformer, options)
+	}
+	// The pSecret is in the super control plane only, there is no informer cache to add it to.
+	addToSuper := func(_, superClientset *fake.Clientset) {
+		if err := superClientset.Tracker().Add(pSecret); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	actions, reconcileErr, err := util.RunDownwardSync(newController, testTenant, nil, []runtime.Object{vSecret}, vSecret, addToSuper)
+	if err != nil {
+		t.Fatalf("error running downward sync: %v", err)
+	}
+	if reconcileErr != nil {
+		t.Fatalf("unexpected reconcile error: %v", reconcileErr)
+	}
+
+	var verbs []string
+	for _, action := range actions {
+		verbs = append(verbs, action.GetVerb())
+	}
+	if expected := []string{"list", "get", "update"}; strings.Join(verbs, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected the pSecret to be listed and got from the apiserver and updated, got %v", actions)
+	}
+	updated := actions[2].(core.UpdateAction).GetObject().(*corev1.Secret)
+	if string(updated.Data["data2"]) != "data2" {
+		t.Errorf("expected the pSecret to be updated with the tenant data, got %v", updated.Data)
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	superInformer.Start(stopCh)
+	for informerType := range superInformer.WaitForCacheSync(stopCh) {
+		if informerType == reflect.TypeOf(&corev1.Secret{}) {
+			t.Errorf("expected no secret informer to be started")
+		}
+	}
+}
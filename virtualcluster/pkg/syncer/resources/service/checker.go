@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -139,6 +140,13 @@ func (c *controller) PatrollerDo() {
 		}
 	}
 	d.DeleteFunc = func(pObj differ.ClusterObject) {
+		clusterName, vNamespace := conversion.GetVirtualOwner(pObj)
+		if !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "service", clusterName, vNamespace+"/"+pObj.GetName(), pObj.GetAnnotations()[constants.LabelUID],
+			util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+				return tenantClient.CoreV1().Services(vNamespace).Get(context.TODO(), pObj.GetName(), metav1.GetOptions{})
+			})) {
+			return
+		}
 		deleteOptions := metav1.NewPreconditionDeleteOptions(string(pObj.GetUID()))
 		if err = c.serviceClient.Services(pObj.GetNamespace()).Delete(context.TODO(), pObj.GetName(), *deleteOptions); err != nil {
 			klog.Errorf("error deleting pService %s in super control plane: %v", pObj.Key, err)
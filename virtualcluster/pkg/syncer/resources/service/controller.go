@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
@@ -70,7 +71,7 @@ func NewServiceController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.Service{}, &corev1.ServiceList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.Service{}, &corev1.ServiceList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -79,10 +80,12 @@ func NewServiceController(config *config.SyncerConfiguration,
 	if options.IsFake {
 		c.serviceSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(informer.Core().V1().Services().Informer(), "services")
 		c.serviceSynced = informer.Core().V1().Services().Informer().HasSynced
 	}
 
-	c.UpwardController, err = uw.NewUWController(&corev1.Service{}, c, uw.WithOptions(options.UWOptions))
+	c.UpwardController, err = uw.NewUWController(&corev1.Service{}, c,
+		uw.WithSyncerConfig(config), uw.WithObjectGetter(informer.Core().V1().Services().Informer().GetIndexer()), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -40,7 +41,7 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 }
 
 func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
-	klog.V(4).Infof("reconcile service %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	logsampling.V(4, "service").Infof("reconcile service %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
 	pService, err := c.serviceLister.Services(targetNamespace).Get(request.Name)
 	pExists := true
@@ -60,6 +61,9 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 	}
 	switch {
 	case vExists && !pExists:
+		if paused := c.MultiClusterController.CreationPausedFor(request.ClusterName); paused > 0 {
+			return reconciler.Result{RequeueAfter: paused}, nil
+		}
 		err := c.reconcileServiceCreate(request.ClusterName, targetNamespace, request.UID, vService)
 		if err != nil {
 			klog.Errorf("failed reconcile service %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
@@ -71,6 +75,7 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 			klog.Errorf("failed reconcile service %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
 	case vExists && pExists:
 		err := c.reconcileServiceUpdate(request.ClusterName, targetNamespace, request.UID, pService, vService)
 		if err != nil {
@@ -91,6 +96,15 @@ func (c *controller) reconcileServiceCreate(clusterName, targetNamespace, reques
 
 	pService := newObj.(*corev1.Service)
 	conversion.VC(nil, "").Service(pService).Mutate(service)
+	if err := conversion.MutateServiceIPFamilies(pService, superClusterIPFamilies(c.Config.SuperClusterIPFamilies)); err != nil {
+		// Do not retry, the super cluster cannot satisfy the requested families.
+		return c.MultiClusterController.Eventf(clusterName, &corev1.ObjectReference{
+			Kind:      "Service",
+			Name:      service.Name,
+			Namespace: service.Namespace,
+			UID:       service.UID,
+		}, corev1.EventTypeWarning, "IPFamiliesNotSupported", "The Service is not synced: %v", err)
+	}
 
 	pService, err = c.serviceClient.Services(targetNamespace).Create(context.TODO(), pService, metav1.CreateOptions{})
 	if apierrors.IsAlreadyExists(err) {
@@ -100,7 +114,19 @@ func (c *controller) reconcileServiceCreate(clusterName, targetNamespace, reques
 		}
 		return fmt.Errorf("pService %s/%s exists but its delegated object UID is different", targetNamespace, pService.Name)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, service.Namespace, service.Name)
+	return nil
+}
+
+func superClusterIPFamilies(families []string) []corev1.IPFamily {
+	var ipFamilies []corev1.IPFamily
+	for _, family := range families {
+		ipFamilies = append(ipFamilies, corev1.IPFamily(family))
+	}
+	return ipFamilies
 }
 
 func (c *controller) reconcileServiceUpdate(clusterName, targetNamespace, requestUID string, pService, vService *corev1.Service) error {
@@ -118,6 +144,7 @@ func (c *controller) reconcileServiceUpdate(clusterName, targetNamespace, reques
 		if err != nil {
 			return err
 		}
+		c.MultiClusterController.SuperClusterWritten(clusterName, vService.Namespace, vService.Name)
 	}
 	return nil
 }
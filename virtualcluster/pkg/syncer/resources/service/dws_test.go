@@ -25,11 +25,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	core "k8s.io/client-go/testing"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 )
 
@@ -56,6 +62,18 @@ func applyClusterIPToService(service *corev1.Service, clusterIP string) *corev1.
 	return service
 }
 
+func applyIPFamiliesToService(service *corev1.Service, policy corev1.IPFamilyPolicyType, families ...corev1.IPFamily) *corev1.Service {
+	service.Spec.IPFamilyPolicy = &policy
+	service.Spec.IPFamilies = families
+	return service
+}
+
+func applyExternalNameToService(service *corev1.Service, externalName string) *corev1.Service {
+	service.Spec.Type = corev1.ServiceTypeExternalName
+	service.Spec.ExternalName = externalName
+	return service
+}
+
 func superService(name, namespace, uid, clusterKey string) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
@@ -89,8 +107,11 @@ func TestDWServiceCreation(t *testing.T) {
 	testcases := map[string]struct {
 		ExistingObjectInSuper  []runtime.Object
 		ExistingObjectInTenant *corev1.Service
+		SuperClusterIPFamilies []string
 
 		ExpectedCreatedServices []string
+		ExpectedIPFamilies      []corev1.IPFamily
+		ExpectedExternalName    string
 		ExpectedError           string
 	}{
 		"new service": {
@@ -103,6 +124,33 @@ func TestDWServiceCreation(t *testing.T) {
 			ExistingObjectInTenant:  applyClusterIPToService(tenantService("svc-1", "default", "12345"), "1.1.1.1"),
 			ExpectedCreatedServices: []string{superDefaultNSName + "/svc-1"},
 		},
+		"new ExternalName service": {
+			ExistingObjectInSuper:   []runtime.Object{},
+			ExistingObjectInTenant:  applyExternalNameToService(tenantService("svc-1", "default", "12345"), "db.example.com"),
+			SuperClusterIPFamilies:  []string{"IPv4"},
+			ExpectedCreatedServices: []string{superDefaultNSName + "/svc-1"},
+			ExpectedExternalName:    "db.example.com",
+		},
+		"new prefer-dual-stack service on single-stack super cluster": {
+			ExistingObjectInSuper:   []runtime.Object{},
+			ExistingObjectInTenant:  applyIPFamiliesToService(tenantService("svc-1", "default", "12345"), corev1.IPFamilyPolicyPreferDualStack, corev1.IPv6Protocol, corev1.IPv4Protocol),
+			SuperClusterIPFamilies:  []string{"IPv4"},
+			ExpectedCreatedServices: []string{superDefaultNSName + "/svc-1"},
+			ExpectedIPFamilies:      []corev1.IPFamily{corev1.IPv4Protocol},
+		},
+		"new require-dual-stack service on dual-stack super cluster": {
+			ExistingObjectInSuper:   []runtime.Object{},
+			ExistingObjectInTenant:  applyIPFamiliesToService(tenantService("svc-1", "default", "12345"), corev1.IPFamilyPolicyRequireDualStack, corev1.IPv4Protocol, corev1.IPv6Protocol),
+			SuperClusterIPFamilies:  []string{"IPv6", "IPv4"},
+			ExpectedCreatedServices: []string{superDefaultNSName + "/svc-1"},
+			ExpectedIPFamilies:      []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol},
+		},
+		"new require-dual-stack service on single-stack super cluster": {
+			ExistingObjectInSuper:   []runtime.Object{},
+			ExistingObjectInTenant:  applyIPFamiliesToService(tenantService("svc-1", "default", "12345"), corev1.IPFamilyPolicyRequireDualStack, corev1.IPv4Protocol, corev1.IPv6Protocol),
+			SuperClusterIPFamilies:  []string{"IPv4"},
+			ExpectedCreatedServices: []string{},
+		},
 		"new service but already exists": {
 			ExistingObjectInSuper: []runtime.Object{
 				superService("svc-1", superDefaultNSName, "12345", defaultClusterKey),
@@ -123,7 +171,15 @@ func TestDWServiceCreation(t *testing.T) {
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			actions, reconcileErr, err := util.RunDownwardSync(NewServiceController,
+			actions, reconcileErr, err := util.RunDownwardSync(func(config *config.SyncerConfiguration,
+				client clientset.Interface,
+				informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface,
+				vcInformer vcinformers.VirtualClusterInformer,
+				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.SuperClusterIPFamilies = tc.SuperClusterIPFamilies
+				return NewServiceController(config, client, informer, vcClient, vcInformer, options)
+			},
 				testTenant,
 				tc.ExistingObjectInSuper,
 				[]runtime.Object{tc.ExistingObjectInTenant},
@@ -160,6 +216,17 @@ func TestDWServiceCreation(t *testing.T) {
 				if fullName != expectedName {
 					t.Errorf("%s: Expected %s to be created, got %s", k, expectedName, fullName)
 				}
+				if tc.ExpectedIPFamilies != nil && !equality.Semantic.DeepEqual(createdSVC.Spec.IPFamilies, tc.ExpectedIPFamilies) {
+					t.Errorf("%s: Expected ipFamilies %v, got %v", k, tc.ExpectedIPFamilies, createdSVC.Spec.IPFamilies)
+				}
+				if tc.ExpectedExternalName != "" {
+					if createdSVC.Spec.Type != corev1.ServiceTypeExternalName || createdSVC.Spec.ExternalName != tc.ExpectedExternalName {
+						t.Errorf("%s: Expected ExternalName service to %s, got type %s to %s", k, tc.ExpectedExternalName, createdSVC.Spec.Type, createdSVC.Spec.ExternalName)
+					}
+					if _, ok := createdSVC.Annotations[constants.LabelClusterIP]; ok {
+						t.Errorf("%s: Expected no cluster IP annotation on ExternalName service", k)
+					}
+				}
 			}
 		})
 	}
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 )
 
 // StartUWS starts the upward syncer
@@ -91,6 +92,10 @@ func (c *controller) BackPopulate(key string) error {
 		return fmt.Errorf("backPopulated pService %s/%s delegated UID is different from updated object", pService.Namespace, pService.Name)
 	}
 
+	if mc.IsSyncPaused(vService) {
+		return nil
+	}
+
 	tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
 	if err != nil {
 		return pkgerr.Wrapf(err, "failed to create client from cluster %s config", clusterName)
@@ -112,6 +117,7 @@ func (c *controller) BackPopulate(key string) error {
 			// Add clusterIP to ExternalIPs if it hasn't been set on purpose
 			newService.Spec.ExternalIPs = []string{updatedMeta.Annotations[constants.LabelSuperClusterIP]}
 		}
+		c.UpwardController.WaitForTenantWrite()
 		if _, err = tenantClient.CoreV1().Services(vService.Namespace).Update(context.TODO(), newService, metav1.UpdateOptions{}); err != nil {
 			return fmt.Errorf("failed to back populate service %s/%s meta update for cluster %s: %v", vService.Namespace, vService.Name, clusterName, err)
 		}
@@ -127,6 +133,7 @@ func (c *controller) BackPopulate(key string) error {
 			}
 		}
 		newService.Status = pService.Status
+		c.UpwardController.WaitForTenantWrite()
 		if _, err = tenantClient.CoreV1().Services(vService.Namespace).UpdateStatus(context.TODO(), newService, metav1.UpdateOptions{}); err != nil {
 			return fmt.Errorf("failed to back populate service %s/%s status update for cluster %s: %v", vService.Namespace, vService.Name, clusterName, err)
 		}
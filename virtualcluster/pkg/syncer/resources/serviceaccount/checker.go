@@ -21,13 +21,16 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol/differ"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
@@ -93,13 +96,42 @@ func (c *controller) PatrollerDo() {
 		v := vObj.Object.(*corev1.ServiceAccount)
 		p := pObj.Object.(*corev1.ServiceAccount)
 
+		if c.skipSyncNames.Has(v.Name) {
+			// a skipped pServiceAccount is adopted again rather than deleted, the super control plane
+			// would recreate it anyway.
+			if p.Annotations[constants.LabelUID] != string(v.UID) ||
+				!equality.Semantic.DeepEqual(p.AutomountServiceAccountToken, v.AutomountServiceAccountToken) ||
+				!equality.Semantic.DeepEqual(p.ImagePullSecrets, v.ImagePullSecrets) {
+				d.OnAdd(vObj)
+			}
+			return
+		}
+
 		if p.Annotations[constants.LabelUID] != string(v.UID) {
 			klog.Warningf("Found pServiceAccount %s delegated UID is different from tenant object", pObj.Key)
 			d.OnDelete(pObj)
 			return
 		}
+
+		if !equality.Semantic.DeepEqual(p.AutomountServiceAccountToken, v.AutomountServiceAccountToken) {
+			if err := c.MultiClusterController.RequeueObject(vObj.OwnerCluster, vObj.Object); err != nil {
+				klog.Errorf("error requeue vServiceAccount %s in cluster %s: %v", vObj.Key, vObj.GetOwnerCluster(), err)
+			} else {
+				metrics.CheckerRemedyStats.WithLabelValues("RequeuedTenantServiceAccounts").Inc()
+			}
+		}
 	}
 	d.DeleteFunc = func(pObj differ.ClusterObject) {
+		if c.skipSyncNames.Has(pObj.GetName()) {
+			return
+		}
+		clusterName, vNamespace := conversion.GetVirtualOwner(pObj)
+		if !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "serviceaccount", clusterName, vNamespace+"/"+pObj.GetName(), pObj.GetAnnotations()[constants.LabelUID],
+			util.TenantObjectGetter(c.MultiClusterController, clusterName, func(tenantClient clientset.Interface) (metav1.Object, error) {
+				return tenantClient.CoreV1().ServiceAccounts(vNamespace).Get(context.TODO(), pObj.GetName(), metav1.GetOptions{})
+			})) {
+			return
+		}
 		deleteOptions := &metav1.DeleteOptions{}
 		deleteOptions.Preconditions = metav1.NewUIDPreconditions(string(pObj.GetUID()))
 		if err = c.saClient.ServiceAccounts(pObj.GetNamespace()).Delete(context.TODO(), pObj.GetName(), *deleteOptions); err != nil {
@@ -47,14 +47,15 @@ func TestServiceAccountPatrol(t *testing.T) {
 	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
 
 	testcases := map[string]struct {
-		ExistingObjectInSuper  []runtime.Object
-		ExistingObjectInTenant []runtime.Object
-		ExpectedDeletedPObject []string
-		ExpectedCreatedPObject []string
-		ExpectedUpdatedPObject []runtime.Object
-		ExpectedNoOperation    bool
-		WaitDWS                bool // Make sure to set this flag if the test involves DWS.
-		WaitUWS                bool // Make sure to set this flag if the test involves UWS.
+		ExistingObjectInSuper   []runtime.Object
+		ExistingObjectInTenant  []runtime.Object
+		SkipSyncServiceAccounts []string
+		ExpectedDeletedPObject  []string
+		ExpectedCreatedPObject  []string
+		ExpectedUpdatedPObject  []runtime.Object
+		ExpectedNoOperation     bool
+		WaitDWS                 bool // Make sure to set this flag if the test involves DWS.
+		WaitUWS                 bool // Make sure to set this flag if the test involves UWS.
 	}{
 		"pServiceAccount not created by vc": {
 			ExistingObjectInSuper: []runtime.Object{
@@ -99,11 +100,44 @@ func TestServiceAccountPatrol(t *testing.T) {
 			},
 			WaitDWS: true,
 		},
+		"skipped pServiceAccount exists, vServiceAccount does not exists": {
+			ExistingObjectInSuper: []runtime.Object{
+				superServiceAccount("default", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedNoOperation:     true,
+		},
+		"skipped pServiceAccount exists, vServiceAccount exists with different uid": {
+			ExistingObjectInSuper: []runtime.Object{
+				superServiceAccount("default", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantServiceAccount("default", "default", "123456"),
+			},
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedUpdatedPObject: []runtime.Object{
+				superServiceAccount("default", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			WaitDWS: true,
+		},
+		"skipped pServiceAccount exists, vServiceAccount exists with different image pull secrets": {
+			ExistingObjectInSuper: []runtime.Object{
+				superServiceAccount("default", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				imagePullSecrets(tenantServiceAccount("default", "default", "12345"), "registry-1"),
+			},
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedUpdatedPObject: []runtime.Object{
+				imagePullSecrets(superServiceAccount("default", superDefaultNSName, "12345", defaultClusterKey), "registry-1"),
+			},
+			WaitDWS: true,
+		},
 	}
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			tenantActions, superActions, err := util.RunPatrol(NewServiceAccountController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
+			tenantActions, superActions, err := util.RunPatrol(skippingServiceAccounts(tc.SkipSyncServiceAccounts...), testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, nil, tc.WaitDWS, tc.WaitUWS, nil)
 			if err != nil {
 				t.Errorf("%s: error running patrol: %v", k, err)
 				return
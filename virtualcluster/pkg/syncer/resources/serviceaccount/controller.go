@@ -18,6 +18,7 @@ package serviceaccount
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -29,6 +30,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
 )
@@ -49,6 +51,9 @@ type controller struct {
 	// super control plane sa lister/synced function
 	saLister listersv1.ServiceAccountLister
 	saSynced cache.InformerSynced
+	// skipSyncNames are the names of the service accounts created by the super control plane
+	// rather than by the syncer
+	skipSyncNames sets.String
 }
 
 func NewServiceAccountController(config *config.SyncerConfiguration,
@@ -58,11 +63,15 @@ func NewServiceAccountController(config *config.SyncerConfiguration,
 	vcInformer vcinformers.VirtualClusterInformer,
 	options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
 	c := &controller{
-		saClient: client.CoreV1(),
+		BaseResourceSyncer: manager.BaseResourceSyncer{
+			Config: config,
+		},
+		saClient:      client.CoreV1(),
+		skipSyncNames: sets.NewString(config.SkipSyncServiceAccounts...),
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&corev1.ServiceAccount{}, &corev1.ServiceAccountList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&corev1.ServiceAccount{}, &corev1.ServiceAccountList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -71,6 +80,7 @@ func NewServiceAccountController(config *config.SyncerConfiguration,
 	if options.IsFake {
 		c.saSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(informer.Core().V1().ServiceAccounts().Informer(), "serviceaccounts")
 		c.saSynced = informer.Core().V1().ServiceAccounts().Informer().HasSynced
 	}
 
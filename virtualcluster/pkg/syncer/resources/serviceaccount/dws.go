@@ -19,8 +19,10 @@ package serviceaccount
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
@@ -28,9 +30,14 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
+// skippedServiceAccountRequeuePeriod is how often a skipped tenant service account is requeued
+// until the super control plane creates its counterpart.
+const skippedServiceAccountRequeuePeriod = 30 * time.Second
+
 func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 	if !cache.WaitForCacheSync(stopCh, c.saSynced) {
 		return fmt.Errorf("failed to wait for sa caches to sync")
@@ -40,7 +47,7 @@ func (c *controller) StartDWS(stopCh <-chan struct{}) error {
 
 // The reconcile logic for tenant control plane service account informer
 func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, error) {
-	klog.V(4).Infof("reconcile service account %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
+	logsampling.V(4, "serviceaccount").Infof("reconcile service account %s/%s for cluster %s", request.Namespace, request.Name, request.ClusterName)
 	targetNamespace := conversion.ToSuperClusterNamespace(request.ClusterName, request.Namespace)
 	pSa, err := c.saLister.ServiceAccounts(targetNamespace).Get(request.Name)
 	pExists := true
@@ -62,17 +69,31 @@ func (c *controller) Reconcile(request reconciler.Request) (reconciler.Result, e
 
 	switch {
 	case vExists && !pExists:
+		if c.skipSyncNames.Has(request.Name) {
+			// the super control plane creates it, and the patroller does not requeue super control
+			// plane service accounts not adopted yet.
+			logsampling.V(4, "serviceaccount").Infof("service account %s/%s of cluster %s is skipped, waiting for %s/%s", request.Namespace, request.Name, request.ClusterName, targetNamespace, request.Name)
+			return reconciler.Result{RequeueAfter: skippedServiceAccountRequeuePeriod}, nil
+		}
+		if paused := c.MultiClusterController.CreationPausedFor(request.ClusterName); paused > 0 {
+			return reconciler.Result{RequeueAfter: paused}, nil
+		}
 		err := c.reconcileServiceAccountCreate(request.ClusterName, targetNamespace, request.UID, vSa)
 		if err != nil {
 			klog.Errorf("failed reconcile serviceaccount %s/%s CREATE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
 	case !vExists && pExists:
+		if c.skipSyncNames.Has(request.Name) {
+			// left to the super control plane, which recreates it anyway.
+			return reconciler.Result{}, nil
+		}
 		err := c.reconcileServiceAccountRemove(request.ClusterName, targetNamespace, request.UID, request.Name, pSa)
 		if err != nil {
 			klog.Errorf("failed reconcile serviceaccount %s/%s DELETE of cluster %s %v", request.Namespace, request.Name, request.ClusterName, err)
 			return reconciler.Result{Requeue: true}, err
 		}
+		c.MultiClusterController.SuperClusterWritten(request.ClusterName, request.Namespace, request.Name)
 	case vExists && pExists:
 		err := c.reconcileServiceAccountUpdate(request.ClusterName, targetNamespace, request.UID, pSa, vSa)
 		if err != nil {
@@ -102,30 +123,44 @@ func (c *controller) reconcileServiceAccountCreate(clusterName, targetNamespace,
 		}
 		return fmt.Errorf("pServiceAccount %s/%s exists but its delegated UID is different", targetNamespace, pServiceAccount.Name)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, vSa.Namespace, vSa.Name)
+	return nil
 }
 
 func (c *controller) reconcileServiceAccountUpdate(clusterName, targetNamespace, requestUID string, pSa, vSa *corev1.ServiceAccount) error {
+	updatedSa := pSa.DeepCopy()
 	// Just mark the default service account of super control plane namespace, created by super control plane service account controller, as a tenant related resource.
-	if vSa.Name == "default" {
-		if len(pSa.Annotations) == 0 {
-			pSa.Annotations = make(map[string]string)
-		}
-		var err error
-		if pSa.Annotations[constants.LabelCluster] != clusterName || pSa.Annotations[constants.LabelUID] != string(vSa.UID) || pSa.Annotations[constants.LabelNamespace] != vSa.Namespace {
-			pSa.Annotations[constants.LabelCluster] = clusterName
-			pSa.Annotations[constants.LabelUID] = string(vSa.UID)
-			pSa.Annotations[constants.LabelNamespace] = vSa.Namespace
-			_, err = c.saClient.ServiceAccounts(targetNamespace).Update(context.TODO(), pSa, metav1.UpdateOptions{})
+	if vSa.Name == "default" || c.skipSyncNames.Has(vSa.Name) {
+		if len(updatedSa.Annotations) == 0 {
+			updatedSa.Annotations = make(map[string]string)
 		}
-		return err
+		updatedSa.Annotations[constants.LabelCluster] = clusterName
+		updatedSa.Annotations[constants.LabelUID] = string(vSa.UID)
+		updatedSa.Annotations[constants.LabelNamespace] = vSa.Namespace
+	} else if pSa.Annotations[constants.LabelUID] != requestUID {
+		return fmt.Errorf("pServiceAccount %s/%s delegated UID is different from updated object", targetNamespace, pSa.Name)
 	}
 
-	if pSa.Annotations[constants.LabelUID] != requestUID {
-		return fmt.Errorf("pServiceAccount %s/%s delegated UID is different from updated object", targetNamespace, pSa.Name)
+	// The super control plane admission falls back to the service account setting for pods that
+	// do not specify automountServiceAccountToken, so keep it in line with the tenant one.
+	updatedSa.AutomountServiceAccountToken = vSa.AutomountServiceAccountToken
+	// Skipped service accounts are never built from the tenant one, so their imagePullSecrets, if
+	// any, are only synced here.
+	if c.skipSyncNames.Has(vSa.Name) {
+		updatedSa.ImagePullSecrets = vSa.ImagePullSecrets
 	}
 
-	// do nothing.
+	if equality.Semantic.DeepEqual(pSa, updatedSa) {
+		return nil
+	}
+	_, err := c.saClient.ServiceAccounts(targetNamespace).Update(context.TODO(), updatedSa, metav1.UpdateOptions{})
+	if err != nil {
+		return err
+	}
+	c.MultiClusterController.SuperClusterWritten(clusterName, vSa.Namespace, vSa.Name)
 	return nil
 }
 
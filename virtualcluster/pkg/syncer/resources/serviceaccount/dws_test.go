@@ -25,13 +25,19 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	core "k8s.io/client-go/testing"
 
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 )
 
 func tenantServiceAccount(name, namespace, uid string) *corev1.ServiceAccount {
@@ -66,6 +72,26 @@ func superServiceAccount(name, namespace, uid, clusterKey string) *corev1.Servic
 	}
 }
 
+// skippingServiceAccounts returns a NewServiceAccountController skipping the sync of the named service accounts.
+func skippingServiceAccounts(names ...string) manager.ResourceSyncerNew {
+	return func(config *config.SyncerConfiguration,
+		client clientset.Interface,
+		informer informers.SharedInformerFactory,
+		vcClient vcclient.Interface,
+		vcInformer vcinformers.VirtualClusterInformer,
+		options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+		config.SkipSyncServiceAccounts = names
+		return NewServiceAccountController(config, client, informer, vcClient, vcInformer, options)
+	}
+}
+
+func imagePullSecrets(sa *corev1.ServiceAccount, names ...string) *corev1.ServiceAccount {
+	for _, name := range names {
+		sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+	return sa
+}
+
 func TestDWServiceAccountCreation(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -83,11 +109,12 @@ func TestDWServiceAccountCreation(t *testing.T) {
 	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
 
 	testcases := map[string]struct {
-		ExistingObjectInSuper  []runtime.Object
-		ExistingObjectInTenant []runtime.Object
-		ExpectedCreatedPObject []string
-		ExpectedNoOperation    bool
-		ExpectedError          string
+		ExistingObjectInSuper   []runtime.Object
+		ExistingObjectInTenant  []runtime.Object
+		SkipSyncServiceAccounts []string
+		ExpectedCreatedPObject  []string
+		ExpectedNoOperation     bool
+		ExpectedError           string
 	}{
 		"new sa": {
 			ExistingObjectInSuper: []runtime.Object{},
@@ -114,11 +141,27 @@ func TestDWServiceAccountCreation(t *testing.T) {
 			},
 			ExpectedError: "delegated UID is different",
 		},
+		"skipped sa is left to the super control plane": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantServiceAccount("default", "default", "12345"),
+			},
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedNoOperation:     true,
+		},
+		"sa not skipped": {
+			ExistingObjectInSuper: []runtime.Object{},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantServiceAccount("sa-1", "default", "12345"),
+			},
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedCreatedPObject:  []string{superDefaultNSName + "/sa-1"},
+		},
 	}
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			actions, reconcileErr, err := util.RunDownwardSync(NewServiceAccountController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			actions, reconcileErr, err := util.RunDownwardSync(skippingServiceAccounts(tc.SkipSyncServiceAccounts...), testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
 			if err != nil {
 				t.Errorf("%s: error running downward sync: %v", k, err)
 				return
@@ -180,12 +223,13 @@ func TestDWServiceAccountDeletion(t *testing.T) {
 	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
 
 	testcases := map[string]struct {
-		ExistingObjectInSuper  []runtime.Object
-		ExistingObjectInTenant []runtime.Object
-		EnqueueObject          *corev1.ServiceAccount
-		ExpectedDeletedPObject []string
-		ExpectedNoOperation    bool
-		ExpectedError          string
+		ExistingObjectInSuper   []runtime.Object
+		ExistingObjectInTenant  []runtime.Object
+		EnqueueObject           *corev1.ServiceAccount
+		SkipSyncServiceAccounts []string
+		ExpectedDeletedPObject  []string
+		ExpectedNoOperation     bool
+		ExpectedError           string
 	}{
 		"delete sa": {
 			ExistingObjectInSuper: []runtime.Object{
@@ -206,11 +250,19 @@ func TestDWServiceAccountDeletion(t *testing.T) {
 			EnqueueObject: tenantServiceAccount("sa-3", "default", "12345"),
 			ExpectedError: "delegated UID is different",
 		},
+		"skipped sa is not deleted": {
+			ExistingObjectInSuper: []runtime.Object{
+				superServiceAccount("default", superDefaultNSName, "12345", defaultClusterKey),
+			},
+			EnqueueObject:           tenantServiceAccount("default", "default", "12345"),
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedNoOperation:     true,
+		},
 	}
 
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			actions, reconcileErr, err := util.RunDownwardSync(NewServiceAccountController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueueObject, nil)
+			actions, reconcileErr, err := util.RunDownwardSync(skippingServiceAccounts(tc.SkipSyncServiceAccounts...), testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.EnqueueObject, nil)
 			if err != nil {
 				t.Errorf("%s: error running downward sync: %v", k, err)
 				return
@@ -254,6 +306,11 @@ func TestDWServiceAccountDeletion(t *testing.T) {
 	}
 }
 
+func automountServiceAccount(sa *corev1.ServiceAccount, automount bool) *corev1.ServiceAccount {
+	sa.AutomountServiceAccountToken = &automount
+	return sa
+}
+
 func TestDWServiceAccountUpdate(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -271,11 +328,12 @@ func TestDWServiceAccountUpdate(t *testing.T) {
 	superDefaultNSName := conversion.ToSuperClusterNamespace(defaultClusterKey, "default")
 
 	testcases := map[string]struct {
-		ExistingObjectInSuper  []runtime.Object
-		ExistingObjectInTenant []runtime.Object
-		ExpectedUpdatedPObject []runtime.Object
-		ExpectedNoOperation    bool
-		ExpectedError          string
+		ExistingObjectInSuper   []runtime.Object
+		ExistingObjectInTenant  []runtime.Object
+		SkipSyncServiceAccounts []string
+		ExpectedUpdatedPObject  []runtime.Object
+		ExpectedNoOperation     bool
+		ExpectedError           string
 	}{
 		"add vc annotation to default pSA created by super kcm": {
 			ExistingObjectInSuper: []runtime.Object{
@@ -288,10 +346,117 @@ func TestDWServiceAccountUpdate(t *testing.T) {
 				superServiceAccount("default", superDefaultNSName, "123456", defaultClusterKey),
 			},
 		},
+		"sync automountServiceAccountToken to default pSA": {
+			ExistingObjectInSuper: []runtime.Object{
+				superServiceAccount("default", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				automountServiceAccount(tenantServiceAccount("default", "default", "123456"), false),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				automountServiceAccount(superServiceAccount("default", superDefaultNSName, "123456", defaultClusterKey), false),
+			},
+		},
+		"sync automountServiceAccountToken to pSA": {
+			ExistingObjectInSuper: []runtime.Object{
+				automountServiceAccount(superServiceAccount("sa", superDefaultNSName, "123456", defaultClusterKey), true),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				automountServiceAccount(tenantServiceAccount("sa", "default", "123456"), false),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				automountServiceAccount(superServiceAccount("sa", superDefaultNSName, "123456", defaultClusterKey), false),
+			},
+		},
+		"unset automountServiceAccountToken of pSA": {
+			ExistingObjectInSuper: []runtime.Object{
+				automountServiceAccount(superServiceAccount("sa", superDefaultNSName, "123456", defaultClusterKey), false),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantServiceAccount("sa", "default", "123456"),
+			},
+			ExpectedUpdatedPObject: []runtime.Object{
+				superServiceAccount("sa", superDefaultNSName, "123456", defaultClusterKey),
+			},
+		},
+		"pSA in line with vSA": {
+			ExistingObjectInSuper: []runtime.Object{
+				automountServiceAccount(superServiceAccount("sa", superDefaultNSName, "123456", defaultClusterKey), false),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				automountServiceAccount(tenantServiceAccount("sa", "default", "123456"), false),
+			},
+			ExpectedNoOperation: true,
+		},
+		"pSA with different uid": {
+			ExistingObjectInSuper: []runtime.Object{
+				superServiceAccount("sa", superDefaultNSName, "654321", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				automountServiceAccount(tenantServiceAccount("sa", "default", "123456"), false),
+			},
+			ExpectedError: "delegated UID is different",
+		},
+		"adopt skipped pSA created by super kcm with its image pull secrets": {
+			ExistingObjectInSuper: []runtime.Object{
+				tenantServiceAccount("builder", superDefaultNSName, ""),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				imagePullSecrets(automountServiceAccount(tenantServiceAccount("builder", "default", "123456"), false), "registry-1", "registry-2"),
+			},
+			SkipSyncServiceAccounts: []string{"builder"},
+			ExpectedUpdatedPObject: []runtime.Object{
+				imagePullSecrets(automountServiceAccount(superServiceAccount("builder", superDefaultNSName, "123456", defaultClusterKey), false), "registry-1", "registry-2"),
+			},
+		},
+		"skipped pSA adopted again after vSA recreation": {
+			ExistingObjectInSuper: []runtime.Object{
+				superServiceAccount("default", superDefaultNSName, "654321", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantServiceAccount("default", "default", "123456"),
+			},
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedUpdatedPObject: []runtime.Object{
+				superServiceAccount("default", superDefaultNSName, "123456", defaultClusterKey),
+			},
+		},
+		"remove image pull secrets of skipped pSA": {
+			ExistingObjectInSuper: []runtime.Object{
+				imagePullSecrets(superServiceAccount("default", superDefaultNSName, "123456", defaultClusterKey), "registry-1"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				tenantServiceAccount("default", "default", "123456"),
+			},
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedUpdatedPObject: []runtime.Object{
+				superServiceAccount("default", superDefaultNSName, "123456", defaultClusterKey),
+			},
+		},
+		"skipped pSA in line with vSA": {
+			ExistingObjectInSuper: []runtime.Object{
+				imagePullSecrets(superServiceAccount("default", superDefaultNSName, "123456", defaultClusterKey), "registry-1"),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				imagePullSecrets(tenantServiceAccount("default", "default", "123456"), "registry-1"),
+			},
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedNoOperation:     true,
+		},
+		"image pull secrets of pSA not skipped are kept": {
+			ExistingObjectInSuper: []runtime.Object{
+				superServiceAccount("sa", superDefaultNSName, "123456", defaultClusterKey),
+			},
+			ExistingObjectInTenant: []runtime.Object{
+				imagePullSecrets(tenantServiceAccount("sa", "default", "123456"), "registry-1"),
+			},
+			SkipSyncServiceAccounts: []string{"default"},
+			ExpectedNoOperation:     true,
+		},
 	}
 	for k, tc := range testcases {
 		t.Run(k, func(t *testing.T) {
-			actions, reconcileErr, err := util.RunDownwardSync(NewServiceAccountController, testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
+			actions, reconcileErr, err := util.RunDownwardSync(skippingServiceAccounts(tc.SkipSyncServiceAccounts...), testTenant, tc.ExistingObjectInSuper, tc.ExistingObjectInTenant, tc.ExistingObjectInTenant[0], nil)
 			if err != nil {
 				t.Errorf("%s: error running downward sync: %v", k, err)
 				return
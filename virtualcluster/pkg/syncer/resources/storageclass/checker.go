@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 )
 
 var numMissMatchedStorageClasses uint64
@@ -100,6 +101,11 @@ func (c *controller) checkStorageClassOfTenantCluster(clusterName string) {
 		pStorageClass, err := c.storageclassLister.Get(vStorageClass.Name)
 		if apierrors.IsNotFound(err) {
 			// super control plane is the source of the truth for sc object, delete tenant control plane obj
+			if !util.ConfirmMissing(c.Config.ExistenceDisagreementPolicy, "storageclass", clusterName, vStorageClass.Name, "", func() (metav1.Object, error) {
+				return c.client.StorageClasses().Get(context.TODO(), vStorageClass.Name, metav1.GetOptions{})
+			}) {
+				continue
+			}
 			tenantClient, err := c.MultiClusterController.GetClusterClient(clusterName)
 			if err != nil {
 				klog.Errorf("error getting cluster %s clientset: %v", clusterName, err)
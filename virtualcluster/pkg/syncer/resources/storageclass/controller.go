@@ -35,6 +35,7 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	pa "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/patrol"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	uw "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/uwcontroller"
 	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/plugin"
@@ -74,7 +75,7 @@ func NewStorageClassController(config *config.SyncerConfiguration,
 	}
 
 	var err error
-	c.MultiClusterController, err = mc.NewMCController(&v1.StorageClass{}, &v1.StorageClassList{}, c, mc.WithOptions(options.MCOptions))
+	c.MultiClusterController, err = mc.NewMCController(&v1.StorageClass{}, &v1.StorageClassList{}, c, mc.WithSyncerConfig(config), mc.WithOptions(options.MCOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -83,10 +84,12 @@ func NewStorageClassController(config *config.SyncerConfiguration,
 	if options.IsFake {
 		c.storageclassSynced = func() bool { return true }
 	} else {
+		util.TrackWatchErrors(informer.Storage().V1().StorageClasses().Informer(), "storageclasses")
 		c.storageclassSynced = informer.Storage().V1().StorageClasses().Informer().HasSynced
 	}
 
-	c.UpwardController, err = uw.NewUWController(&v1.StorageClass{}, c, uw.WithOptions(options.UWOptions))
+	c.UpwardController, err = uw.NewUWController(&v1.StorageClass{}, c,
+		uw.WithSyncerConfig(config), uw.WithOptions(options.UWOptions))
 	if err != nil {
 		return nil, err
 	}
@@ -27,6 +27,7 @@ import (
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -63,6 +64,7 @@ func (c *controller) BackPopulate(key string) error {
 			if op == reconciler.AddEvent {
 				// Available in super, hence create a new in tenant control plane
 				vStorageClass := conversion.BuildVirtualStorageClass(clusterName, pStorageClass)
+				c.UpwardController.WaitForTenantWrite()
 				_, err := tenantClient.StorageV1().StorageClasses().Create(context.TODO(), vStorageClass, metav1.CreateOptions{})
 				if err != nil {
 					return err
@@ -74,21 +76,33 @@ func (c *controller) BackPopulate(key string) error {
 	}
 
 	if op == reconciler.DeleteEvent {
+		if !util.IsSyncedFromSuper(vStorageClass, "") {
+			// The tenant storageclass was not synced from the deleted one.
+			return nil
+		}
 		opts := &metav1.DeleteOptions{
 			PropagationPolicy: &constants.DefaultDeletionPolicy,
 		}
-		err := tenantClient.StorageV1().StorageClasses().Delete(context.TODO(), scName, *opts)
-		if err != nil {
+		c.UpwardController.WaitForTenantWrite()
+		return tenantClient.StorageV1().StorageClasses().Delete(context.TODO(), scName, *opts)
+	}
+
+	updatedStorageClass := conversion.Equality(c.Config, nil).CheckStorageClassEquality(pStorageClass, vStorageClass)
+	if !util.IsSyncedFromSuper(vStorageClass, pStorageClass.UID) {
+		// The tenant has created a storageclass with the name of a public one.
+		adopt, err := util.ResolveClusterScopedConflict(c.Config.OnClusterScopedConflict, "storageclass", clusterName, scName)
+		if err != nil || !adopt {
 			return err
 		}
-	} else {
-		updatedStorageClass := conversion.Equality(c.Config, nil).CheckStorageClassEquality(pStorageClass, vStorageClass)
-		if updatedStorageClass != nil {
-			_, err := tenantClient.StorageV1().StorageClasses().Update(context.TODO(), updatedStorageClass, metav1.UpdateOptions{})
-			if err != nil {
-				return err
-			}
+		if updatedStorageClass == nil {
+			updatedStorageClass = vStorageClass.DeepCopy()
 		}
+		util.MarkSyncedFromSuper(updatedStorageClass, pStorageClass)
+	}
+	if updatedStorageClass != nil {
+		c.UpwardController.WaitForTenantWrite()
+		_, err := tenantClient.StorageV1().StorageClasses().Update(context.TODO(), updatedStorageClass, metav1.UpdateOptions{})
+		return err
 	}
 	return nil
 }
@@ -26,10 +26,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
 	core "k8s.io/client-go/testing"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
+	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	util "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/test"
 )
 
@@ -55,6 +62,10 @@ func makeStorageClass(name, uid string, mFuncs ...func(*v1.StorageClass)) *v1.St
 	return sc
 }
 
+func markPublic(class *v1.StorageClass) {
+	class.Labels = map[string]string{constants.PublicObjectKey: "true"}
+}
+
 func TestUWPVCreation(t *testing.T) {
 	testTenant := &v1alpha1.VirtualCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -91,7 +102,7 @@ func TestUWPVCreation(t *testing.T) {
 				makeStorageClass("sc", "12345"),
 			},
 			ExistingObjectInTenant: []runtime.Object{
-				makeStorageClass("sc", "123456"),
+				makeStorageClass("sc", "123456", markPublic),
 			},
 			EnqueuedKey:         defaultClusterKey + "/sc",
 			ExpectedNoOperation: true,
@@ -176,13 +187,13 @@ func TestUWPVUpdate(t *testing.T) {
 				}),
 			},
 			ExistingObjectInTenant: []runtime.Object{
-				makeStorageClass("sc", "123456", func(class *v1.StorageClass) {
+				makeStorageClass("sc", "123456", markPublic, func(class *v1.StorageClass) {
 					class.Provisioner = "b"
 				}),
 			},
 			EnqueuedKey: defaultClusterKey + "/sc",
 			ExpectedUpdatedObject: []runtime.Object{
-				makeStorageClass("sc", "123456", func(class *v1.StorageClass) {
+				makeStorageClass("sc", "123456", markPublic, func(class *v1.StorageClass) {
 					class.ResourceVersion = "999"
 					class.Provisioner = "a"
 				}),
@@ -265,13 +276,20 @@ func TestUWPVDeletion(t *testing.T) {
 	}{
 		"pSC not found, vSC exists": {
 			ExistingObjectInTenant: []runtime.Object{
-				makeStorageClass("sc", "12345"),
+				makeStorageClass("sc", "12345", markPublic),
 			},
 			EnqueuedKey: defaultClusterKey + "/sc",
 			ExpectedDeletedObject: []string{
 				"sc",
 			},
 		},
+		"pSC not found, vSC created by tenant": {
+			ExistingObjectInTenant: []runtime.Object{
+				makeStorageClass("sc", "12345"),
+			},
+			EnqueuedKey:         defaultClusterKey + "/sc",
+			ExpectedNoOperation: true,
+		},
 	}
 
 	for k, tc := range testcases {
@@ -322,3 +340,91 @@ func TestUWPVDeletion(t *testing.T) {
 		})
 	}
 }
+
+func TestUWSCConflict(t *testing.T) {
+	testTenant := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+			UID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+		},
+	}
+
+	defaultClusterKey := conversion.ToClusterKey(testTenant)
+	superObject := makeStorageClass("sc", "12345", markPublic, func(class *v1.StorageClass) {
+		class.Provisioner = "a"
+	})
+	tenantObject := makeStorageClass("sc", "123456", func(class *v1.StorageClass) {
+		class.Provisioner = "b"
+	})
+
+	testcases := map[string]struct {
+		Policy                string
+		ExpectedUpdatedObject runtime.Object
+		ExpectedError         string
+		ExpectedNoOperation   bool
+	}{
+		"adopt": {
+			Policy: constants.ClusterScopedConflictAdopt,
+			ExpectedUpdatedObject: makeStorageClass("sc", "123456", markPublic, func(class *v1.StorageClass) {
+				class.ResourceVersion = "999"
+				class.Annotations = map[string]string{constants.LabelUID: "12345"}
+				class.Provisioner = "a"
+			}),
+		},
+		"skip": {
+			Policy:              constants.ClusterScopedConflictSkip,
+			ExpectedNoOperation: true,
+		},
+		"fail": {
+			Policy:        constants.ClusterScopedConflictFail,
+			ExpectedError: "was not synced from the super control plane",
+		},
+	}
+
+	for k, tc := range testcases {
+		t.Run(k, func(t *testing.T) {
+			actions, reconcileErr, err := util.RunUpwardSync(func(config *config.SyncerConfiguration,
+				client clientset.Interface,
+				informer informers.SharedInformerFactory,
+				vcClient vcclient.Interface,
+				vcInformer vcinformers.VirtualClusterInformer,
+				options manager.ResourceSyncerOptions) (manager.ResourceSyncer, error) {
+				config.OnClusterScopedConflict = tc.Policy
+				return NewStorageClassController(config, client, informer, vcClient, vcInformer, options)
+			}, testTenant, []runtime.Object{superObject.DeepCopy()}, []runtime.Object{tenantObject.DeepCopy()}, defaultClusterKey+"/sc", nil)
+			if err != nil {
+				t.Errorf("%s: error running upward sync: %v", k, err)
+				return
+			}
+
+			if tc.ExpectedError != "" {
+				if reconcileErr == nil || !strings.Contains(reconcileErr.Error(), tc.ExpectedError) {
+					t.Errorf("%s: expected error msg \"%s\", but got \"%v\"", k, tc.ExpectedError, reconcileErr)
+				}
+			} else if reconcileErr != nil {
+				t.Errorf("%s: expected no error, but got \"%v\"", k, reconcileErr)
+			}
+
+			if tc.ExpectedUpdatedObject == nil {
+				if len(actions) != 0 {
+					t.Errorf("%s: Expect no operation, got %v", k, actions)
+				}
+				return
+			}
+			if len(actions) != 1 || !actions[0].Matches("update", "storageclasses") {
+				t.Errorf("%s: Expect one update of storageclass, got %v", k, actions)
+				return
+			}
+			actionObj := actions[0].(core.UpdateAction).GetObject()
+			if !equality.Semantic.DeepEqual(tc.ExpectedUpdatedObject, actionObj) {
+				exp, _ := json.Marshal(tc.ExpectedUpdatedObject)
+				got, _ := json.Marshal(actionObj)
+				t.Errorf("%s: Expected updated storageclass is %v, got %v", k, string(exp), string(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+const (
+	// SuperClusterUnreachableReason is the reason of the VirtualCluster condition set while the
+	// super cluster apiserver is unreachable.
+	SuperClusterUnreachableReason = "SuperClusterUnreachable"
+
+	superClusterUnreachableMessage = "The super cluster apiserver is unreachable, the objects of the virtual cluster are not synced."
+	superClusterReachableMessage   = "The super cluster apiserver is reachable."
+)
+
+// superClusterHealthPatrol checks if the super cluster apiserver can be reached. After
+// SuperClusterFailureThreshold consecutive failures, the managed VirtualClusters get a
+// SuperClusterUnreachable condition, which is cleared once a check succeeds.
+func (s *Syncer) superClusterHealthPatrol() {
+	if err := s.superClusterHealthCheck(); err != nil {
+		s.superClusterFailures++
+		klog.Warningf("super cluster apiserver check failed (%d/%d): %v", s.superClusterFailures, s.config.SuperClusterFailureThreshold, err)
+		if s.superClusterFailures < s.config.SuperClusterFailureThreshold {
+			return
+		}
+		if !s.superClusterUnreachable {
+			klog.Errorf("super cluster apiserver is unreachable after %d consecutive failed checks", s.superClusterFailures)
+		}
+		s.superClusterUnreachable = true
+		metrics.SuperClusterUnreachable.Set(1)
+		// VirtualClusters registered since the last check are marked as well.
+		if err := s.updateSuperClusterCondition(SuperClusterUnreachableReason, corev1.ConditionTrue, superClusterUnreachableMessage); err != nil {
+			klog.Errorf("failed to mark virtual clusters with the %s condition: %v", SuperClusterUnreachableReason, err)
+		}
+		return
+	}
+
+	s.superClusterFailures = 0
+	if !s.superClusterUnreachable {
+		return
+	}
+	klog.Infof("super cluster apiserver is reachable again")
+	metrics.SuperClusterUnreachable.Set(0)
+	if err := s.updateSuperClusterCondition(SuperClusterUnreachableReason, corev1.ConditionFalse, superClusterReachableMessage); err != nil {
+		// Keep the unreachable state so that the condition is cleared by the next check.
+		klog.Errorf("failed to clear the %s condition of virtual clusters: %v", SuperClusterUnreachableReason, err)
+		return
+	}
+	s.superClusterUnreachable = false
+}
+
+// updateSuperClusterCondition sets the condition with the reason, e.g. SuperClusterUnreachable, of
+// the managed VirtualClusters.
+func (s *Syncer) updateSuperClusterCondition(reason string, status corev1.ConditionStatus, message string) error {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.clusterSet))
+	for key := range s.clusterSet {
+		keys = append(keys, key)
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	for _, key := range keys {
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			vc, err := s.vcClient.TenancyV1alpha1().VirtualClusters(namespace).Get(name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if !setSuperClusterCondition(vc, reason, status, message) {
+				return nil
+			}
+			_, err = s.vcClient.TenancyV1alpha1().VirtualClusters(namespace).Update(vc)
+			return err
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("virtual cluster %s: %v", key, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// setSuperClusterCondition sets the condition with the reason of the VirtualCluster and returns
+// whether it changed. A VirtualCluster without the condition is not given a False one.
+func setSuperClusterCondition(vc *v1alpha1.VirtualCluster, reason string, status corev1.ConditionStatus, message string) bool {
+	for i := range vc.Status.Conditions {
+		condition := &vc.Status.Conditions[i]
+		if condition.Reason != reason {
+			continue
+		}
+		if condition.Status == status && condition.Message == message {
+			return false
+		}
+		if condition.Status != status {
+			condition.LastTransitionTime = metav1.Now()
+		}
+		condition.Status = status
+		condition.Message = message
+		return true
+	}
+	if status != corev1.ConditionTrue {
+		return false
+	}
+	vc.Status.Conditions = append(vc.Status.Conditions, v1alpha1.ClusterCondition{
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	})
+	return true
+}
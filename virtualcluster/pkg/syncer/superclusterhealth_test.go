@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	fakevcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+func TestSuperClusterHealthPatrol(t *testing.T) {
+	vc := &v1alpha1.VirtualCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "tenant-1",
+		},
+		Status: v1alpha1.VirtualClusterStatus{
+			Phase: v1alpha1.ClusterRunning,
+			Conditions: []v1alpha1.ClusterCondition{
+				{Status: corev1.ConditionTrue, Reason: "TenantMasterRunning"},
+			},
+		},
+	}
+	vcClient := fakevcclient.NewSimpleClientset(vc)
+
+	var superClusterErr error
+	s := &Syncer{
+		config:     &config.SyncerConfiguration{SuperClusterFailureThreshold: 2},
+		vcClient:   vcClient,
+		clusterSet: map[string]mc.ClusterInterface{"tenant-1/test": nil},
+		superClusterHealthCheck: func() error {
+			return superClusterErr
+		},
+	}
+
+	superClusterCondition := func() *v1alpha1.ClusterCondition {
+		got, err := vcClient.TenancyV1alpha1().VirtualClusters("tenant-1").Get("test", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get virtual cluster: %v", err)
+		}
+		var found *v1alpha1.ClusterCondition
+		for i := range got.Status.Conditions {
+			if got.Status.Conditions[i].Reason == SuperClusterUnreachableReason {
+				if found != nil {
+					t.Fatalf("found more than one %s condition: %v", SuperClusterUnreachableReason, got.Status.Conditions)
+				}
+				found = &got.Status.Conditions[i]
+			}
+		}
+		return found
+	}
+
+	steps := []struct {
+		name            string
+		err             error
+		expectedStatus  corev1.ConditionStatus
+		expectedMetric  float64
+		expectedUpdates int
+	}{
+		{
+			name: "healthy super cluster",
+		},
+		{
+			name: "first failure below the threshold",
+			err:  errors.New("connection refused"),
+		},
+		{
+			name:            "sustained failures",
+			err:             errors.New("connection refused"),
+			expectedStatus:  corev1.ConditionTrue,
+			expectedMetric:  1,
+			expectedUpdates: 1,
+		},
+		{
+			name:            "still unreachable",
+			err:             errors.New("connection refused"),
+			expectedStatus:  corev1.ConditionTrue,
+			expectedMetric:  1,
+			expectedUpdates: 1,
+		},
+		{
+			name:            "recovered",
+			expectedStatus:  corev1.ConditionFalse,
+			expectedUpdates: 2,
+		},
+		{
+			name:            "single failure after recovery",
+			err:             errors.New("connection refused"),
+			expectedStatus:  corev1.ConditionFalse,
+			expectedUpdates: 2,
+		},
+	}
+	for _, step := range steps {
+		superClusterErr = step.err
+		s.superClusterHealthPatrol()
+
+		condition := superClusterCondition()
+		switch {
+		case step.expectedStatus == "" && condition != nil:
+			t.Errorf("%s: expected no %s condition, got %v", step.name, SuperClusterUnreachableReason, condition)
+		case step.expectedStatus != "" && condition == nil:
+			t.Errorf("%s: expected a %s condition", step.name, SuperClusterUnreachableReason)
+		case condition != nil && condition.Status != step.expectedStatus:
+			t.Errorf("%s: expected condition status %s, got %s", step.name, step.expectedStatus, condition.Status)
+		}
+		if got := testutil.ToFloat64(metrics.SuperClusterUnreachable); got != step.expectedMetric {
+			t.Errorf("%s: expected metric %v, got %v", step.name, step.expectedMetric, got)
+		}
+		updates := 0
+		for _, action := range vcClient.Actions() {
+			if action.Matches("update", "virtualclusters") {
+				updates++
+			}
+		}
+		if updates != step.expectedUpdates {
+			t.Errorf("%s: expected %d virtual cluster updates, got %d", step.name, step.expectedUpdates, updates)
+		}
+	}
+}
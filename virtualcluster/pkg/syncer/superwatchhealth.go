@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+const (
+	// SuperClusterWatchFailingReason is the reason of the VirtualCluster condition set while a super
+	// or meta cluster informer keeps failing.
+	SuperClusterWatchFailingReason = "SuperClusterWatchFailing"
+
+	superClusterWatchRecoveredMessage = "The informers are watching."
+)
+
+// superWatchHealthPatrol checks the super and meta cluster informers which failed
+// SuperWatchErrorThreshold consecutive list and watch attempts. While any is failing, the managed
+// VirtualClusters get a SuperClusterWatchFailing condition listing them, which is cleared once they
+// recover. With the restart policy, the syncer asks to be stopped like on a termination signal, so
+// that it is restarted with new informers.
+func (s *Syncer) superWatchHealthPatrol() {
+	failing := s.watchErrors.Failing(s.config.SuperWatchErrorThreshold)
+
+	resources := make([]string, 0, len(failing))
+	for _, f := range failing {
+		resources = append(resources, f.Resource)
+		metrics.RecordSuperWatchFailing(f.Resource, true)
+		if !s.failingWatches.Has(f.Resource) {
+			klog.Errorf("informer of %s failed %d consecutive times, last error: %s", f.Resource, f.Errors, f.LastErr)
+		}
+	}
+	for _, resource := range s.failingWatches.Difference(sets.NewString(resources...)).List() {
+		klog.Infof("informer of %s recovered", resource)
+		metrics.RecordSuperWatchFailing(resource, false)
+	}
+
+	if len(failing) == 0 {
+		if s.failingWatches.Len() == 0 {
+			return
+		}
+		if err := s.updateSuperClusterCondition(SuperClusterWatchFailingReason, corev1.ConditionFalse, superClusterWatchRecoveredMessage); err != nil {
+			// Keep the failing state so that the condition is cleared by the next check.
+			klog.Errorf("failed to clear the %s condition of virtual clusters: %v", SuperClusterWatchFailingReason, err)
+			return
+		}
+		s.failingWatches = sets.NewString()
+		return
+	}
+
+	s.failingWatches = sets.NewString(resources...)
+	message := fmt.Sprintf("The informers of %s keep failing, their objects of the virtual cluster are not synced.", strings.Join(resources, ", "))
+	if err := s.updateSuperClusterCondition(SuperClusterWatchFailingReason, corev1.ConditionTrue, message); err != nil {
+		klog.Errorf("failed to mark virtual clusters with the %s condition: %v", SuperClusterWatchFailingReason, err)
+	}
+	if s.config.OnPersistentWatchErrors == constants.PersistentWatchErrorsRestart {
+		klog.Errorf("restarting the syncer to recreate the failing informers of %s", strings.Join(resources, ", "))
+		s.requestRestart()
+	}
+}
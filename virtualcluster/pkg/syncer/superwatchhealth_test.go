@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/apis/tenancy/v1alpha1"
+	fakevcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned/fake"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+func TestSuperWatchHealthPatrol(t *testing.T) {
+	for _, policy := range []string{constants.PersistentWatchErrorsDegrade, constants.PersistentWatchErrorsRestart} {
+		t.Run(policy, func(t *testing.T) {
+			vc := &v1alpha1.VirtualCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "tenant-1",
+				},
+			}
+			vcClient := fakevcclient.NewSimpleClientset(vc)
+			s := &Syncer{
+				config:      &config.SyncerConfiguration{SuperWatchErrorThreshold: 2, OnPersistentWatchErrors: policy},
+				vcClient:    vcClient,
+				clusterSet:  map[string]mc.ClusterInterface{"tenant-1/test": nil},
+				watchErrors: util.NewWatchErrorTracker(),
+				restart:     make(chan struct{}),
+			}
+
+			watchCondition := func() *v1alpha1.ClusterCondition {
+				got, err := vcClient.TenancyV1alpha1().VirtualClusters("tenant-1").Get("test", metav1.GetOptions{})
+				if err != nil {
+					t.Fatalf("failed to get virtual cluster: %v", err)
+				}
+				for i := range got.Status.Conditions {
+					if got.Status.Conditions[i].Reason == SuperClusterWatchFailingReason {
+						return &got.Status.Conditions[i]
+					}
+				}
+				return nil
+			}
+			failing := func() float64 {
+				return testutil.ToFloat64(metrics.SuperWatchFailing.WithLabelValues("pods"))
+			}
+
+			// a single error is below the threshold.
+			s.watchErrors.RecordError("pods", errors.New("pods is forbidden"))
+			s.superWatchHealthPatrol()
+			if condition := watchCondition(); condition != nil {
+				t.Errorf("expected no %s condition, got %v", SuperClusterWatchFailingReason, condition)
+			}
+
+			// persistent errors degrade the virtual clusters.
+			s.watchErrors.RecordError("pods", errors.New("pods is forbidden"))
+			s.superWatchHealthPatrol()
+			condition := watchCondition()
+			if condition == nil || condition.Status != corev1.ConditionTrue || !strings.Contains(condition.Message, "pods") {
+				t.Errorf("expected a True %s condition naming pods, got %v", SuperClusterWatchFailingReason, condition)
+			}
+			if got := failing(); got != 1 {
+				t.Errorf("expected failing metric 1, got %v", got)
+			}
+			restarted := false
+			select {
+			case <-s.Restart():
+				restarted = true
+			default:
+			}
+			if expectRestart := policy == constants.PersistentWatchErrorsRestart; restarted != expectRestart {
+				t.Errorf("expected restart %v, got %v", expectRestart, restarted)
+			}
+
+			// the informer recovers.
+			s.watchErrors = util.NewWatchErrorTracker()
+			s.superWatchHealthPatrol()
+			condition = watchCondition()
+			if condition == nil || condition.Status != corev1.ConditionFalse {
+				t.Errorf("expected a False %s condition, got %v", SuperClusterWatchFailingReason, condition)
+			}
+			if got := failing(); got != 0 {
+				t.Errorf("expected failing metric 0, got %v", got)
+			}
+		})
+	}
+}
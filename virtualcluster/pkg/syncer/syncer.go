@@ -18,6 +18,7 @@ package syncer
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
@@ -33,8 +34,10 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
@@ -48,8 +51,11 @@ import (
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/conversion"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/lifecycle"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/manager"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/tracing"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/cluster"
 	utilconst "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
@@ -64,11 +70,14 @@ var (
 )
 
 type Syncer struct {
-	config            *config.SyncerConfiguration
-	metaClient        clientset.Interface
-	superClient       clientset.Interface
-	recorder          record.EventRecorder
-	controllerManager *manager.ControllerManager
+	config      *config.SyncerConfiguration
+	metaClient  clientset.Interface
+	superClient clientset.Interface
+	// superDynamicClient lists and deletes the objects of the disabled syncers, with
+	// PruneOnFeatureDisable.
+	superDynamicClient dynamic.Interface
+	recorder           record.EventRecorder
+	controllerManager  *manager.ControllerManager
 	// lister that can list virtual clusters from a shared cache
 	lister vclisters.VirtualClusterLister
 	// returns true when the namespace cache is ready
@@ -76,9 +85,32 @@ type Syncer struct {
 	// virtual cluster that have been queued up for processing by workers
 	queue   workqueue.RateLimitingInterface
 	workers int
+	// syncHandler registers or removes the virtual cluster of the given key, it is syncVirtualCluster
+	// except in tests.
+	syncHandler func(key string) error
 	// clusterSet holds the cluster collection in which cluster is running.
 	mu         sync.Mutex
 	clusterSet map[string]mc.ClusterInterface
+	// lifecycleNotifier sends the sync lifecycle events to the lifecycle webhook, if configured.
+	lifecycleNotifier *lifecycle.WebhookNotifier
+	// tracingExporter exports the spans of the reconciles to the tracing endpoint, if configured.
+	tracingExporter *tracing.OTLPExporter
+	vcClient        vcclient.Interface
+	// superClusterHealthCheck returns an error if the super cluster apiserver cannot be reached,
+	// it queries the apiserver version except in tests.
+	superClusterHealthCheck func() error
+	// superClusterFailures is the number of consecutive failed super cluster health checks.
+	superClusterFailures int
+	// superClusterUnreachable is set once the failures reach the threshold, until the managed
+	// VirtualClusters are marked reachable again.
+	superClusterUnreachable bool
+	// watchErrors tracks the list and watch errors of the super and meta cluster informers.
+	watchErrors *util.WatchErrorTracker
+	// failingWatches are the resources whose informer is failing.
+	failingWatches sets.String
+	// restart is closed once the syncer asks to be restarted.
+	restart     chan struct{}
+	restartOnce sync.Once
 }
 
 type virtualclusterGetter struct {
@@ -99,6 +131,9 @@ func (v *virtualclusterGetter) GetObject(namespace, name string) (client.Object,
 type Bootstrap interface {
 	ListenAndServe(address, certFile, keyFile string)
 	Run(<-chan struct{})
+	// Restart returns a channel closed once the syncer asks to be restarted. The syncer is then
+	// expected to be stopped like on a termination signal.
+	Restart() <-chan struct{}
 }
 
 func New(
@@ -107,6 +142,7 @@ func New(
 	virtualClusterInformer vcinformers.VirtualClusterInformer,
 	metaClusterClient clientset.Interface,
 	superClusterClient clientset.Interface,
+	superClusterRestConfig *restclient.Config,
 	superClusterInformers informers.SharedInformerFactory,
 	recorder record.EventRecorder,
 ) (*Syncer, error) {
@@ -118,6 +154,48 @@ func New(
 		queue:       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "virtual_cluster"),
 		workers:     constants.UwsControllerWorkerLow,
 		clusterSet:  make(map[string]mc.ClusterInterface),
+		vcClient:    virtualClusterClient,
+		watchErrors: util.DefaultWatchErrorTracker,
+		restart:     make(chan struct{}),
+	}
+	syncer.syncHandler = syncer.syncVirtualCluster
+	syncer.superClusterHealthCheck = func() error {
+		_, err := superClusterClient.Discovery().ServerVersion()
+		return err
+	}
+	if config.PruneOnFeatureDisable {
+		superDynamicClient, err := dynamic.NewForConfig(superClusterRestConfig)
+		if err != nil {
+			return nil, err
+		}
+		syncer.superDynamicClient = superDynamicClient
+	}
+	mc.DefaultClusterWorkerLimiter.SetLimit(config.PerClusterWorkerLimit)
+	metrics.SetMaxVCCardinality(config.MetricsMaxVCCardinality)
+	if config.VirtualClusterRegistrationConcurrency > 0 {
+		syncer.workers = config.VirtualClusterRegistrationConcurrency
+	}
+	if config.LifecycleWebhookURL != "" {
+		notifier, err := lifecycle.NewWebhookNotifier(lifecycle.WebhookOptions{
+			URL:        config.LifecycleWebhookURL,
+			EventTypes: config.LifecycleWebhookEventTypes,
+			QPS:        config.LifecycleWebhookQPS,
+			Burst:      config.LifecycleWebhookBurst,
+			MaxRetries: config.LifecycleWebhookMaxRetries,
+		})
+		if err != nil {
+			return nil, err
+		}
+		syncer.lifecycleNotifier = notifier
+		lifecycle.SetNotifier(notifier)
+	}
+	if config.Tracing.Endpoint != "" {
+		exporter, err := tracing.NewOTLPExporter(config.Tracing.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		syncer.tracingExporter = exporter
+		tracing.SetExporter(exporter, config.Tracing.SamplingRatePerMillion)
 	}
 
 	// Handle VirtualCluster add&delete
@@ -135,6 +213,7 @@ func New(
 			DeleteFunc: syncer.enqueueVirtualCluster,
 		},
 	)
+	util.TrackWatchErrors(virtualClusterInformer.Informer(), "virtualclusters")
 	syncer.lister = virtualClusterInformer.Lister()
 	syncer.virtualClusterSynced = virtualClusterInformer.Informer().HasSynced
 
@@ -143,15 +222,20 @@ func New(
 	syncer.controllerManager = multiClusterControllerManager
 
 	plugins := LoadPlugins(config)
+	if err := checkSuperClusterPermissions(superClusterClient, plugins, config); err != nil {
+		return nil, err
+	}
 	initContext := &plugin.InitContext{
 		Context:    context.Background(),
 		Config:     config,
 		Client:     superClusterClient,
+		RestConfig: superClusterRestConfig,
 		Informer:   superClusterInformers,
 		VCClient:   virtualClusterClient,
 		VCInformer: virtualClusterInformer,
 	}
 
+	extraSyncingResources := sets.NewString(config.ExtraSyncingResources...)
 	for _, p := range plugins {
 		klog.Infof("loading plugin %q...", p.ID)
 
@@ -164,7 +248,15 @@ func New(
 
 		s, ok := instance.(manager.ResourceSyncer)
 		if ok {
-			multiClusterControllerManager.AddResourceSyncer(s)
+			if p.Disable {
+				// an extra syncing resource, only synced for the virtual clusters requesting it.
+				s = newExtraResourceSyncer(s, p.ID, extraSyncingResources)
+			}
+			direction := config.SyncDirections[p.ID]
+			if !direction.SyncsDown() || !direction.SyncsUp() {
+				klog.Infof("plugin %q only syncs %s", p.ID, direction)
+			}
+			multiClusterControllerManager.AddResourceSyncer(s, direction)
 		} else {
 			klog.Warningf("unrecognized plugin %q", p.ID)
 		}
@@ -227,12 +319,27 @@ func (s *Syncer) Run(stopChan <-chan struct{}) {
 			os.Exit(1)
 		}
 	}
+	if s.config.PruneOnFeatureDisable {
+		s.pruneDisabledResources(DisabledPlugins(s.config))
+	}
+	if s.lifecycleNotifier != nil {
+		go s.lifecycleNotifier.Run(stopChan)
+	}
+	if s.tracingExporter != nil {
+		go s.tracingExporter.Run(stopChan)
+	}
 	go func() {
 		if err := s.controllerManager.Start(stopChan); err != nil {
 			klog.V(1).Infof("controller manager exit: %v", err)
 		}
 	}()
 	go wait.Until(s.healthPatrol, 1*time.Minute, stopChan)
+	if s.config.SuperClusterFailureThreshold > 0 && s.config.SuperClusterHealthCheckPeriod.Duration > 0 {
+		go wait.Until(s.superClusterHealthPatrol, s.config.SuperClusterHealthCheckPeriod.Duration, stopChan)
+	}
+	if s.config.SuperWatchErrorThreshold > 0 && s.config.SuperClusterHealthCheckPeriod.Duration > 0 {
+		go wait.Until(s.superWatchHealthPatrol, s.config.SuperClusterHealthCheckPeriod.Duration, stopChan)
+	}
 	go func() {
 		defer utilruntime.HandleCrash()
 		defer s.queue.ShutDown()
@@ -244,19 +351,30 @@ func (s *Syncer) Run(stopChan <-chan struct{}) {
 			return
 		}
 
-		klog.V(5).Infof("starting workers")
-		for i := 0; i < s.workers; i++ {
-			go wait.Until(s.run, 1*time.Second, stopChan)
-		}
+		s.startWorkers(stopChan)
 		<-stopChan
 	}()
 }
 
+// Restart returns a channel closed once the syncer asks to be restarted.
+func (s *Syncer) Restart() <-chan struct{} {
+	return s.restart
+}
+
+// requestRestart asks the syncer to be restarted through its normal shutdown, which drains the
+// requests in flight and releases the leadership.
+func (s *Syncer) requestRestart() {
+	s.restartOnce.Do(func() {
+		close(s.restart)
+	})
+}
+
 // ListenAndServe initializes a server to respond to HTTP network requests on the syncer.
 func (s *Syncer) ListenAndServe(address, certFile, keyFile string) {
 	metrics.Register()
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/deadletters", s.serveDeadLetters)
 	if certFile != "" && keyFile != "" {
 		klog.Fatal(http.ListenAndServeTLS(address, certFile, keyFile, mux))
 	} else {
@@ -264,6 +382,27 @@ func (s *Syncer) ListenAndServe(address, certFile, keyFile string) {
 	}
 }
 
+// serveDeadLetters lists the tenant objects whose sync has been taken out of the retry loop.
+func (s *Syncer) serveDeadLetters(w http.ResponseWriter, _ *http.Request) {
+	dls := s.controllerManager.DeadLetters()
+	if dls == nil {
+		dls = []mc.DeadLetter{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(dls); err != nil {
+		klog.Errorf("failed to write dead letters: %v", err)
+	}
+}
+
+// startWorkers starts the workers registering the virtual clusters. Virtual clusters are registered
+// in parallel by at most s.workers workers.
+func (s *Syncer) startWorkers(stopChan <-chan struct{}) {
+	klog.V(5).Infof("starting %d workers", s.workers)
+	for i := 0; i < s.workers; i++ {
+		go wait.Until(s.run, 1*time.Second, stopChan)
+	}
+}
+
 // run runs a run thread that just dequeues items, processes them, and marks them done.
 // It enforces that the syncHandler is never invoked concurrently with the same key.
 func (s *Syncer) run() {
@@ -278,7 +417,7 @@ func (s *Syncer) processNextWorkItem() bool {
 	}
 	defer s.queue.Done(key)
 
-	err := s.syncVirtualCluster(key.(string))
+	err := s.syncHandler(key.(string))
 	if err == nil {
 		s.queue.Forget(key)
 		return true
@@ -339,6 +478,7 @@ func (s *Syncer) removeCluster(key string) {
 	for _, clusterChangeListener := range listener.Listeners {
 		clusterChangeListener.RemoveCluster(vc)
 	}
+	metrics.ForgetVC(vc.GetClusterName())
 
 	delete(s.clusterSet, key)
 }
@@ -364,6 +504,10 @@ func (s *Syncer) addCluster(key string, vc *v1alpha1.VirtualCluster) error {
 	if err != nil {
 		return fmt.Errorf("failed to new tenant cluster %s/%s: %v", vc.Namespace, vc.Name, err)
 	}
+	// the writes to the super cluster are made dry runs by the super cluster client given to New.
+	if s.config.DryRun {
+		tenantCluster.RestConfig = util.WithDryRun(tenantCluster.RestConfig, clusterName)
+	}
 
 	// for each resource type of the newly added VirtualCluster, we add the object to informer cache.
 	for _, clusterChangeListener := range listener.Listeners {
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestRegistrationConcurrency(t *testing.T) {
+	for _, workers := range []int{1, 2, 4} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			const clusters = 8
+
+			var (
+				mu        sync.Mutex
+				running   int
+				maxSeen   int
+				processed sync.WaitGroup
+			)
+			release := make(chan struct{})
+			s := &Syncer{
+				queue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+				workers: workers,
+			}
+			s.syncHandler = func(key string) error {
+				defer processed.Done()
+				mu.Lock()
+				running++
+				if running > maxSeen {
+					maxSeen = running
+				}
+				mu.Unlock()
+
+				<-release
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			}
+
+			processed.Add(clusters)
+			for i := 0; i < clusters; i++ {
+				s.queue.Add(fmt.Sprintf("ns-%d/vc-%d", i, i))
+			}
+
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			defer s.queue.ShutDown()
+			s.startWorkers(stopCh)
+
+			// wait until all workers are busy registering a cluster.
+			if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				return running == workers, nil
+			}); err != nil {
+				t.Fatalf("registration did not reach %d parallel workers: %v", workers, err)
+			}
+			// give extra workers, if any, the chance to start.
+			time.Sleep(100 * time.Millisecond)
+			close(release)
+			processed.Wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if maxSeen != workers {
+				t.Errorf("expected %d clusters registered in parallel at most, got %d", workers, maxSeen)
+			}
+		})
+	}
+}
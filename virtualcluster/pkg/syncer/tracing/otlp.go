@@ -0,0 +1,242 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/version"
+)
+
+const (
+	// otlpTracesPath is the path of the OTLP/HTTP traces endpoint, appended to an endpoint without path.
+	otlpTracesPath = "/v1/traces"
+	// otlpQueueSize is the number of spans waiting for export before new spans are dropped.
+	otlpQueueSize = 2048
+	// otlpBatchSize is the maximum number of spans exported in one request.
+	otlpBatchSize = 512
+	// otlpBatchPeriod is how long the spans wait for a batch to fill up before they are exported.
+	otlpBatchPeriod = 5 * time.Second
+	// otlpTimeout is the timeout of a single export request.
+	otlpTimeout = 10 * time.Second
+
+	serviceName = "vc-syncer"
+	scopeName   = "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer"
+
+	// spanKindInternal, statusCodeOK and statusCodeError are the OTLP enum values of the spans.
+	spanKindInternal = 1
+	statusCodeOK     = 1
+	statusCodeError  = 2
+)
+
+// OTLPExporter exports spans in batches to an OpenTelemetry collector, e.g. Tempo, with the
+// OTLP/HTTP protocol and its JSON encoding. Spans are queued and exported by Run, a failed export
+// drops its spans.
+type OTLPExporter struct {
+	url    string
+	client *http.Client
+	spans  chan *Span
+}
+
+// NewOTLPExporter validates the endpoint, e.g. http://tempo:4318, and creates an OTLPExporter. The
+// spans are POSTed to the endpoint, at /v1/traces if it has no path.
+func NewOTLPExporter(endpoint string) (*OTLPExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tracing endpoint %q: %v", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid tracing endpoint %q: scheme must be http or https", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpTracesPath
+	}
+	return &OTLPExporter{
+		url:    u.String(),
+		client: &http.Client{Timeout: otlpTimeout},
+		spans:  make(chan *Span, otlpQueueSize),
+	}, nil
+}
+
+// Export queues the span for export. The span is dropped if the queue is full.
+func (e *OTLPExporter) Export(span *Span) {
+	select {
+	case e.spans <- span:
+	default:
+		klog.V(4).Infof("tracing queue is full, drop span %s of trace %s", span.Name, hex.EncodeToString(span.TraceID[:]))
+	}
+}
+
+// Run exports the queued spans until stopCh is closed, then exports the spans still queued.
+func (e *OTLPExporter) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(otlpBatchPeriod)
+	defer ticker.Stop()
+	batch := make([]*Span, 0, otlpBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.send(batch); err != nil {
+			klog.Warningf("drop %d spans: %v", len(batch), err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case <-stopCh:
+			for {
+				select {
+				case span := <-e.spans:
+					batch = append(batch, span)
+					if len(batch) == otlpBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		case span := <-e.spans:
+			batch = append(batch, span)
+			if len(batch) == otlpBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// send POSTs the spans once.
+func (e *OTLPExporter) send(spans []*Span) error {
+	body, err := json.Marshal(newOTLPRequest(spans))
+	if err != nil {
+		return fmt.Errorf("failed to marshal spans: %v", err)
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("tracing endpoint %s responded %s", e.url, resp.Status)
+	}
+	return nil
+}
+
+// The types below are the JSON encoding of an OTLP ExportTraceServiceRequest, limited to the fields
+// the syncer sets.
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func newOTLPRequest(spans []*Span) *otlpRequest {
+	scopeSpans := otlpScopeSpans{Scope: otlpScope{Name: scopeName}}
+	for _, span := range spans {
+		status := otlpStatus{Code: statusCodeOK}
+		if span.Err != nil {
+			status = otlpStatus{Code: statusCodeError, Message: span.Err.Error()}
+		}
+		scopeSpans.Spans = append(scopeSpans.Spans, otlpSpan{
+			TraceID:           hex.EncodeToString(span.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.SpanID[:]),
+			Name:              span.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            status,
+		})
+	}
+	return &otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: otlpAttributes(map[string]string{
+				"service.name":    serviceName,
+				"service.version": version.Get().GitVersion,
+			})},
+			ScopeSpans: []otlpScopeSpans{scopeSpans},
+		}},
+	}
+}
+
+// otlpAttributes returns the attributes sorted by key.
+func otlpAttributes(attributes map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, key := range keys {
+		kvs = append(kvs, otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: attributes[key]}})
+	}
+	return kvs
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewOTLPExporter(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		endpoint    string
+		expectedURL string
+		expectedErr bool
+	}{
+		{
+			name:        "endpoint without path",
+			endpoint:    "http://tempo:4318",
+			expectedURL: "http://tempo:4318/v1/traces",
+		},
+		{
+			name:        "endpoint with path",
+			endpoint:    "https://collector.example.com/otlp/v1/traces",
+			expectedURL: "https://collector.example.com/otlp/v1/traces",
+		},
+		{
+			name:        "grpc endpoint",
+			endpoint:    "tempo:4317",
+			expectedErr: true,
+		},
+		{
+			name:        "invalid url",
+			endpoint:    "http://tempo:port",
+			expectedErr: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			e, err := NewOTLPExporter(tt.endpoint)
+			if (err != nil) != tt.expectedErr {
+				tc.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err == nil && e.url != tt.expectedURL {
+				tc.Errorf("expected the spans to be POSTed to %s, got %s", tt.expectedURL, e.url)
+			}
+		})
+	}
+}
+
+func TestOTLPExporterRun(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests []otlpRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != otlpTracesPath || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		requests = append(requests, req)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	e, err := NewOTLPExporter(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	start := time.Unix(1600000000, 0)
+	e.Export(&Span{
+		TraceID:    TraceID{0x73, 0x74, 0xa1, 0x72, 0xc3, 0x5d, 0x45, 0xb1, 0x9c, 0x8e, 0xbf, 0x5c, 0x5b, 0x61, 0x49, 0x37},
+		SpanID:     SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		Name:       "dws Pod",
+		StartTime:  start,
+		EndTime:    start.Add(time.Second),
+		Attributes: map[string]string{AttributeResult: ResultError, AttributeCluster: "tenant"},
+		Err:        errors.New("conflict"),
+	})
+
+	// the queued spans are exported when the exporter stops.
+	stopCh := make(chan struct{})
+	close(stopCh)
+	e.Run(stopCh)
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(requests) != 1 || len(requests[0].ResourceSpans) != 1 || len(requests[0].ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("expected an export request of a resource and scope, got %+v", requests)
+	}
+	resource := requests[0].ResourceSpans[0].Resource
+	if len(resource.Attributes) == 0 || resource.Attributes[0].Key != "service.name" || resource.Attributes[0].Value.StringValue != serviceName {
+		t.Errorf("expected the %s service name, got %+v", serviceName, resource.Attributes)
+	}
+	spans := requests[0].ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %+v", spans)
+	}
+	expected := otlpSpan{
+		TraceID:           "7374a172c35d45b19c8ebf5c5b614937",
+		SpanID:            "0102030405060708",
+		Name:              "dws Pod",
+		Kind:              spanKindInternal,
+		StartTimeUnixNano: "1600000000000000000",
+		EndTimeUnixNano:   "1600000001000000000",
+		Attributes: []otlpKeyValue{
+			{Key: AttributeCluster, Value: otlpAnyValue{StringValue: "tenant"}},
+			{Key: AttributeResult, Value: otlpAnyValue{StringValue: ResultError}},
+		},
+		Status: otlpStatus{Code: statusCodeError, Message: "conflict"},
+	}
+	got, _ := json.Marshal(spans[0])
+	want, _ := json.Marshal(expected)
+	if string(got) != string(want) {
+		t.Errorf("expected span %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing traces the reconciles of the syncing controllers as OpenTelemetry spans.
+package tracing
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Directions of the traced operations.
+const (
+	DirectionDWS = "dws"
+	DirectionUWS = "uws"
+)
+
+// Attributes of the reconcile spans.
+const (
+	AttributeCluster   = "vc.cluster"
+	AttributeResource  = "vc.resource"
+	AttributeDirection = "vc.direction"
+	AttributeNamespace = "vc.namespace"
+	AttributeName      = "vc.name"
+	AttributeUID       = "vc.uid"
+	AttributeResult    = "vc.result"
+)
+
+// Results of the traced operations.
+const (
+	ResultOK    = "OK"
+	ResultError = "Error"
+)
+
+// MaxSamplingRatePerMillion samples all the traces.
+const MaxSamplingRatePerMillion = 1000000
+
+// TraceID is the id of the trace of an object.
+type TraceID [16]byte
+
+// SpanID is the id of a span in its trace.
+type SpanID [8]byte
+
+// Object identifies the object an operation is traced for.
+type Object struct {
+	Cluster   string
+	Namespace string
+	Name      string
+	UID       string
+}
+
+// Span is a traced operation. A nil Span, returned when tracing is disabled, does nothing.
+type Span struct {
+	TraceID    TraceID
+	SpanID     SpanID
+	Name       string
+	StartTime  time.Time
+	EndTime    time.Time
+	Attributes map[string]string
+	// Err is the error the operation failed with, if any.
+	Err error
+}
+
+// Exporter exports the ended spans.
+type Exporter interface {
+	Export(span *Span)
+}
+
+var (
+	exporterLock sync.RWMutex
+	exporter     Exporter
+	// samplingBound is the bound of the sampled trace ids, see sampled.
+	samplingBound uint64
+)
+
+// SetExporter sets the exporter of the spans and the number of traces per million sampled. A nil
+// exporter disables tracing.
+func SetExporter(e Exporter, samplingRatePerMillion int32) {
+	exporterLock.Lock()
+	defer exporterLock.Unlock()
+	exporter = e
+	samplingBound = uint64(float64(samplingRatePerMillion) / MaxSamplingRatePerMillion * (1 << 63))
+	if samplingRatePerMillion >= MaxSamplingRatePerMillion {
+		samplingBound = 1 << 63
+	}
+}
+
+// Enabled returns true if an exporter is set.
+func Enabled() bool {
+	exporterLock.RLock()
+	defer exporterLock.RUnlock()
+	return exporter != nil
+}
+
+// Start starts the span of a reconcile of the object. The spans of an object are in the same trace,
+// derived from its UID, or from its cluster, namespace and name if the UID is unknown. It returns nil
+// if tracing is disabled or the trace is not sampled.
+func Start(direction, resource string, obj Object) *Span {
+	exporterLock.RLock()
+	enabled, bound := exporter != nil, samplingBound
+	exporterLock.RUnlock()
+	if !enabled {
+		return nil
+	}
+	traceID := traceIDOf(obj)
+	if !sampled(traceID, bound) {
+		return nil
+	}
+
+	span := &Span{
+		TraceID:   traceID,
+		SpanID:    newSpanID(),
+		Name:      direction + " " + resource,
+		StartTime: time.Now(),
+		Attributes: map[string]string{
+			AttributeDirection: direction,
+			AttributeResource:  resource,
+			AttributeName:      obj.Name,
+		},
+	}
+	for key, value := range map[string]string{
+		AttributeCluster:   obj.Cluster,
+		AttributeNamespace: obj.Namespace,
+		AttributeUID:       obj.UID,
+	} {
+		if value != "" {
+			span.Attributes[key] = value
+		}
+	}
+	return span
+}
+
+// End ends the span with the result of the operation and hands it to the exporter.
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.EndTime = time.Now()
+	s.Err = err
+	s.Attributes[AttributeResult] = ResultOK
+	if err != nil {
+		s.Attributes[AttributeResult] = ResultError
+	}
+
+	exporterLock.RLock()
+	e := exporter
+	exporterLock.RUnlock()
+	if e != nil {
+		e.Export(s)
+	}
+}
+
+// traceIDOf returns the trace id of the object: its UID, a UUID, as is, or a hash of its cluster,
+// namespace and name.
+func traceIDOf(obj Object) TraceID {
+	var id TraceID
+	if uid, err := hex.DecodeString(strings.ReplaceAll(obj.UID, "-", "")); err == nil && len(uid) == len(id) {
+		copy(id[:], uid)
+		return id
+	}
+	sum := sha256.Sum256([]byte(obj.Cluster + "/" + obj.Namespace + "/" + obj.Name))
+	copy(id[:], sum[:])
+	return id
+}
+
+// sampled tells whether the trace is sampled, like the OpenTelemetry TraceIdRatioBased sampler, so
+// that all the spans of a trace are sampled or none. The sampler compares the trace id bits to the
+// bound, which assumes random bits, but the trace id of an object is its UID, whose version and
+// variant bits are fixed, so the bits of a hash of the trace id are compared instead.
+func sampled(id TraceID, bound uint64) bool {
+	sum := sha256.Sum256(id[:])
+	return binary.BigEndian.Uint64(sum[:8])>>1 < bound
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for id == (SpanID{}) {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// recordingExporter records the exported spans.
+type recordingExporter struct {
+	sync.Mutex
+	spans []*Span
+}
+
+func (e *recordingExporter) Export(span *Span) {
+	e.Lock()
+	defer e.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func setExporter(t *testing.T, e Exporter, samplingRatePerMillion int32) {
+	SetExporter(e, samplingRatePerMillion)
+	t.Cleanup(func() { SetExporter(nil, 0) })
+}
+
+func TestStartDisabled(t *testing.T) {
+	setExporter(t, nil, MaxSamplingRatePerMillion)
+
+	span := Start(DirectionDWS, "Pod", Object{Cluster: "tenant", Namespace: "default", Name: "pod"})
+	if span != nil {
+		t.Fatalf("expected no span with tracing disabled, got %+v", span)
+	}
+	// ending a nil span is a no-op.
+	span.End(errors.New("conflict"))
+}
+
+func TestSpan(t *testing.T) {
+	exporter := &recordingExporter{}
+	setExporter(t, exporter, MaxSamplingRatePerMillion)
+
+	obj := Object{Cluster: "tenant", Namespace: "default", Name: "pod", UID: "7374a172-c35d-45b1-9c8e-bf5c5b614937"}
+	Start(DirectionDWS, "Pod", obj).End(nil)
+	Start(DirectionDWS, "Pod", obj).End(errors.New("conflict"))
+	Start(DirectionUWS, "Pod", Object{Name: "default/pod"}).End(nil)
+
+	if len(exporter.spans) != 3 {
+		t.Fatalf("expected 3 exported spans, got %d", len(exporter.spans))
+	}
+	synced, failed, uws := exporter.spans[0], exporter.spans[1], exporter.spans[2]
+	expectedTraceID := TraceID{0x73, 0x74, 0xa1, 0x72, 0xc3, 0x5d, 0x45, 0xb1, 0x9c, 0x8e, 0xbf, 0x5c, 0x5b, 0x61, 0x49, 0x37}
+	if synced.TraceID != expectedTraceID || failed.TraceID != expectedTraceID {
+		t.Errorf("expected the spans of the object to be in the trace of its uid %x, got %x and %x", expectedTraceID, synced.TraceID, failed.TraceID)
+	}
+	if synced.SpanID == failed.SpanID {
+		t.Errorf("expected distinct span ids, got %x twice", synced.SpanID)
+	}
+	if synced.Name != "dws Pod" || uws.Name != "uws Pod" {
+		t.Errorf("expected the spans to be named after the direction and resource, got %q and %q", synced.Name, uws.Name)
+	}
+	for key, expected := range map[string]string{
+		AttributeCluster:   "tenant",
+		AttributeResource:  "Pod",
+		AttributeDirection: DirectionDWS,
+		AttributeNamespace: "default",
+		AttributeName:      "pod",
+		AttributeUID:       obj.UID,
+		AttributeResult:    ResultOK,
+	} {
+		if got := synced.Attributes[key]; got != expected {
+			t.Errorf("expected attribute %s to be %q, got %q", key, expected, got)
+		}
+	}
+	if failed.Attributes[AttributeResult] != ResultError || failed.Err == nil {
+		t.Errorf("expected the failed span to have the %s result and its error, got %q and %v", ResultError, failed.Attributes[AttributeResult], failed.Err)
+	}
+	if _, ok := uws.Attributes[AttributeCluster]; ok {
+		t.Errorf("expected no cluster attribute for an unknown cluster, got %v", uws.Attributes)
+	}
+	if again := traceIDOf(Object{Name: "default/pod"}); again != uws.TraceID {
+		t.Errorf("expected the trace id of an object without uid to be derived from its key, got %x and %x", uws.TraceID, again)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		rate     int32
+		uids     bool
+		min, max int
+	}{
+		{name: "none", rate: 0, min: 0, max: 0},
+		{name: "tenth", rate: MaxSamplingRatePerMillion / 10, min: 50, max: 150},
+		{name: "half", rate: MaxSamplingRatePerMillion / 2, min: 400, max: 600},
+		{name: "all", rate: MaxSamplingRatePerMillion, min: 1000, max: 1000},
+		// the version and variant bits of the UIDs do not bias the sampling.
+		{name: "none of the uids", rate: 0, uids: true, min: 0, max: 0},
+		{name: "tenth of the uids", rate: MaxSamplingRatePerMillion / 10, uids: true, min: 50, max: 150},
+		{name: "half of the uids", rate: MaxSamplingRatePerMillion / 2, uids: true, min: 400, max: 600},
+		{name: "all the uids", rate: MaxSamplingRatePerMillion, uids: true, min: 1000, max: 1000},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := &recordingExporter{}
+			setExporter(t, exporter, tt.rate)
+			for i := 0; i < 1000; i++ {
+				obj := Object{Cluster: "tenant", Namespace: "default", Name: fmt.Sprintf("pod-%d", i)}
+				if tt.uids {
+					obj.UID = string(uuid.NewUUID())
+				}
+				Start(DirectionDWS, "Pod", obj).End(nil)
+				// the spans of a trace are sampled together.
+				Start(DirectionDWS, "Pod", obj).End(nil)
+			}
+			if traces := len(exporter.spans) / 2; traces < tt.min || traces > tt.max || len(exporter.spans)%2 != 0 {
+				t.Errorf("expected between %d and %d sampled traces of 2 spans, got %d spans", tt.min, tt.max, len(exporter.spans))
+			}
+		})
+	}
+}
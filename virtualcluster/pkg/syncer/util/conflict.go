@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// IsSyncedFromSuper tells whether the tenant cluster scoped object vObj was synced from the super
+// control plane public object of the same name and uid pUID, rather than created by the tenant.
+// Synced objects carry the public label of the super object and the super object uid in the
+// LabelUID annotation. pUID is empty if the super object is gone.
+func IsSyncedFromSuper(vObj metav1.Object, pUID types.UID) bool {
+	if pUID != "" && vObj.GetAnnotations()[constants.LabelUID] == string(pUID) {
+		return true
+	}
+	return vObj.GetLabels()[constants.PublicObjectKey] == "true"
+}
+
+// MarkSyncedFromSuper stamps the tenant cluster scoped object vObj as synced from the super control
+// plane public object pObj.
+func MarkSyncedFromSuper(vObj, pObj metav1.Object) {
+	labels := vObj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[constants.PublicObjectKey] = "true"
+	vObj.SetLabels(labels)
+
+	anno := vObj.GetAnnotations()
+	if anno == nil {
+		anno = make(map[string]string)
+	}
+	anno[constants.LabelUID] = string(pObj.GetUID())
+	vObj.SetAnnotations(anno)
+}
+
+// ResolveClusterScopedConflict tells, according to the OnClusterScopedConflict policy, whether the
+// upward syncer takes over the tenant cluster scoped object key of cluster clusterName, which has
+// the name of a super control plane public object but was not synced from it. It returns an error
+// with the ClusterScopedConflictFail policy.
+func ResolveClusterScopedConflict(policy, resource, clusterName, key string) (bool, error) {
+	switch policy {
+	case constants.ClusterScopedConflictSkip:
+		klog.Warningf("%s %s of cluster %s was not synced from the super control plane %s of the same name, skipping it", resource, key, clusterName, resource)
+		metrics.RecordClusterScopedConflict(resource, clusterName, constants.ClusterScopedConflictSkip)
+		return false, nil
+	case constants.ClusterScopedConflictFail:
+		metrics.RecordClusterScopedConflict(resource, clusterName, constants.ClusterScopedConflictFail)
+		return false, fmt.Errorf("%s %s of cluster %s was not synced from the super control plane %s of the same name", resource, key, clusterName, resource)
+	default:
+		klog.Infof("%s %s of cluster %s was not synced from the super control plane %s of the same name, adopting it", resource, key, clusterName, resource)
+		metrics.RecordClusterScopedConflict(resource, clusterName, constants.ClusterScopedConflictAdopt)
+		return true, nil
+	}
+}
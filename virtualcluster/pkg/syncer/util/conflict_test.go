@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+func TestIsSyncedFromSuper(t *testing.T) {
+	pObj := &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{
+		Name:   "high",
+		UID:    "uid-1",
+		Labels: map[string]string{constants.PublicObjectKey: "true"},
+	}}
+
+	for _, tt := range []struct {
+		name     string
+		vObj     *schedulingv1.PriorityClass
+		pUID     types.UID
+		expected bool
+	}{
+		{
+			name:     "created by the tenant",
+			vObj:     &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}},
+			pUID:     pObj.UID,
+			expected: false,
+		},
+		{
+			name: "synced with the public label",
+			vObj: &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{
+				Name:   "high",
+				Labels: map[string]string{constants.PublicObjectKey: "true"},
+			}},
+			pUID:     pObj.UID,
+			expected: true,
+		},
+		{
+			name: "synced with the super uid",
+			vObj: &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{
+				Name:        "high",
+				Annotations: map[string]string{constants.LabelUID: "uid-1"},
+			}},
+			pUID:     pObj.UID,
+			expected: true,
+		},
+		{
+			name: "uid of another super object",
+			vObj: &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{
+				Name:        "high",
+				Annotations: map[string]string{constants.LabelUID: "uid-2"},
+			}},
+			pUID:     pObj.UID,
+			expected: false,
+		},
+		{
+			name:     "super object gone",
+			vObj:     &schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}},
+			expected: false,
+		},
+		{
+			name:     "marked as synced",
+			vObj:     markedSyncedFromSuper(&schedulingv1.PriorityClass{ObjectMeta: metav1.ObjectMeta{Name: "high"}}, pObj),
+			pUID:     pObj.UID,
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsSyncedFromSuper(tt.vObj, tt.pUID); got != tt.expected {
+				t.Errorf("expected synced %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func markedSyncedFromSuper(vObj, pObj *schedulingv1.PriorityClass) *schedulingv1.PriorityClass {
+	MarkSyncedFromSuper(vObj, pObj)
+	return vObj
+}
+
+func TestResolveClusterScopedConflict(t *testing.T) {
+	for _, tt := range []struct {
+		name               string
+		policy             string
+		expectedAdopt      bool
+		expectedErr        bool
+		expectedResolution string
+	}{
+		{
+			name:               "adopt",
+			policy:             constants.ClusterScopedConflictAdopt,
+			expectedAdopt:      true,
+			expectedResolution: constants.ClusterScopedConflictAdopt,
+		},
+		{
+			name:               "default policy adopts",
+			expectedAdopt:      true,
+			expectedResolution: constants.ClusterScopedConflictAdopt,
+		},
+		{
+			name:               "skip",
+			policy:             constants.ClusterScopedConflictSkip,
+			expectedResolution: constants.ClusterScopedConflictSkip,
+		},
+		{
+			name:               "fail",
+			policy:             constants.ClusterScopedConflictFail,
+			expectedErr:        true,
+			expectedResolution: constants.ClusterScopedConflictFail,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := "cluster-" + tt.name
+			counter := metrics.ClusterScopedConflicts.WithLabelValues("priorityclass", cluster, tt.expectedResolution)
+			before := testutil.ToFloat64(counter)
+
+			adopt, err := ResolveClusterScopedConflict(tt.policy, "priorityclass", cluster, "high")
+			if adopt != tt.expectedAdopt {
+				t.Errorf("expected adopt %v, got %v", tt.expectedAdopt, adopt)
+			}
+			if (err != nil) != tt.expectedErr {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("expected one %s conflict to be recorded, got %v", tt.expectedResolution, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// dryRunStatusSuccess is the response of the deletes skipped by the dry run.
+const dryRunStatusSuccess = `{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Success"}`
+
+// WithDryRun returns a copy of config whose write requests, i.e. creates, updates, patches and
+// deletes, are sent as server-side dry runs (dryRun=All): the apiserver validates and admits them
+// and responds as if they were applied, without persisting them. Every write is logged and counted.
+// The deletes that cannot be sent as dry runs, i.e. whose options are not encoded as JSON or whose
+// resource does not support dry runs, are skipped and respond with success.
+func WithDryRun(config *rest.Config, clusterName string) *rest.Config {
+	config = rest.CopyConfig(config)
+	wrapTransport := config.WrapTransport
+	config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrapTransport != nil {
+			rt = wrapTransport(rt)
+		}
+		return &dryRunRoundTripper{clusterName: clusterName, rt: rt}
+	}
+	return config
+}
+
+type dryRunRoundTripper struct {
+	clusterName string
+	rt          http.RoundTripper
+}
+
+func (d *dryRunRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var verb string
+	switch req.Method {
+	case http.MethodPost:
+		verb = "create"
+	case http.MethodPut:
+		verb = "update"
+	case http.MethodPatch:
+		verb = "patch"
+	case http.MethodDelete:
+		verb = "delete"
+	default:
+		return d.rt.RoundTrip(req)
+	}
+	resource, namespace, name := parseResourcePath(req.URL.Path)
+	if verb == "delete" && name == "" {
+		verb = "deletecollection"
+	}
+	metrics.RecordDryRunOperation(resource, verb)
+	keysAndValues := []interface{}{"verb", verb, "resource", resource, "namespace", namespace, "name", name, "cluster", d.clusterName}
+
+	dryRunReq, err := withDryRunAll(req)
+	if err != nil {
+		return nil, err
+	}
+	if dryRunReq == nil {
+		klog.InfoS("Dry run skipped, the delete options cannot be rewritten", keysAndValues...)
+		return dryRunResponse(req, http.StatusOK, "application/json", []byte(dryRunStatusSuccess)), nil
+	}
+	resp, err := d.rt.RoundTrip(dryRunReq)
+	if err != nil {
+		return nil, err
+	}
+	if req.Method == http.MethodDelete && resp.StatusCode == http.StatusBadRequest {
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if lower := strings.ToLower(string(body)); strings.Contains(lower, "dryrun") || strings.Contains(lower, "dry run") {
+			klog.InfoS("Dry run skipped, the resource does not support dry runs", keysAndValues...)
+			return dryRunResponse(req, http.StatusOK, "application/json", []byte(dryRunStatusSuccess)), nil
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	klog.InfoS("Dry run", append(keysAndValues, "code", resp.StatusCode)...)
+	return resp, nil
+}
+
+func (d *dryRunRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return d.rt
+}
+
+// withDryRunAll returns a copy of the write request sent as a dry run, or nil if it cannot be. The
+// apiserver reads the options of a delete from its body if it has one, ignoring the query, so the
+// dryRun of a delete is set in its body, which is only possible for JSON encoded options.
+func withDryRunAll(req *http.Request) (*http.Request, error) {
+	// Round trippers must not modify the request, Clone copies the URL and the headers as well.
+	dryRunReq := req.Clone(req.Context())
+	query := dryRunReq.URL.Query()
+	query.Set("dryRun", metav1.DryRunAll)
+	dryRunReq.URL.RawQuery = query.Encode()
+	if req.Method != http.MethodDelete || req.Body == nil || req.Body == http.NoBody {
+		return dryRunReq, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(body) != 0 {
+		if !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+			return nil, nil
+		}
+		options := &metav1.DeleteOptions{}
+		if err := json.Unmarshal(body, options); err != nil {
+			return nil, nil
+		}
+		options.DryRun = []string{metav1.DryRunAll}
+		if body, err = json.Marshal(options); err != nil {
+			return nil, err
+		}
+	}
+	dryRunReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	dryRunReq.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	dryRunReq.ContentLength = int64(len(body))
+	return dryRunReq, nil
+}
+
+func dryRunResponse(req *http.Request, code int, contentType string, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{contentType}},
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// parseResourcePath returns the resource, with its subresource if any, the namespace and the name
+// of an API request path, e.g. pods/status, default and test for
+// /api/v1/namespaces/default/pods/test/status.
+func parseResourcePath(path string) (resource, namespace, name string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	for i, part := range parts {
+		if part == "api" && len(parts) > i+1 {
+			parts = parts[i+2:]
+			break
+		}
+		if part == "apis" && len(parts) > i+2 {
+			parts = parts[i+3:]
+			break
+		}
+	}
+	// The status and finalize subresources of a namespace are not namespaced resources.
+	if len(parts) > 2 && parts[0] == "namespaces" && (len(parts) > 3 || (parts[2] != "status" && parts[2] != "finalize")) {
+		namespace, parts = parts[1], parts[2:]
+	}
+	if len(parts) == 0 {
+		return "", namespace, ""
+	}
+	resource = parts[0]
+	if len(parts) > 1 {
+		name = parts[1]
+	}
+	if len(parts) > 2 {
+		resource += "/" + strings.Join(parts[2:], "/")
+	}
+	return resource, namespace, name
+}
@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+func TestWithDryRun(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		requests []string
+		bodies   []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		lock.Lock()
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		bodies = append(bodies, string(body))
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.Path, "/widgets/") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","metadata":{},"status":"Failure","message":"dryRun is not supported","reason":"BadRequest","code":400}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm","namespace":"default","resourceVersion":"1"},"data":{"key":"server"}}`))
+	}))
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL}
+	client, err := kubernetes.NewForConfig(WithDryRun(WithFieldManager(config, "vc-syncer"), "super"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if config.WrapTransport != nil {
+		t.Errorf("expected the original config not to be modified")
+	}
+
+	ctx := context.TODO()
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}, Data: map[string]string{"key": "sent"}}
+	count := func(resource, verb string) float64 {
+		return testutil.ToFloat64(metrics.DryRunOperations.WithLabelValues(resource, verb))
+	}
+
+	tests := []struct {
+		name             string
+		call             func() (*corev1.ConfigMap, error)
+		resource, verb   string
+		expectedData     string
+		expectedRequests []string
+		expectedBody     string
+	}{
+		{
+			name: "get",
+			call: func() (*corev1.ConfigMap, error) {
+				return client.CoreV1().ConfigMaps("default").Get(ctx, "cm", metav1.GetOptions{})
+			},
+			expectedData:     "server",
+			expectedRequests: []string{"GET /api/v1/namespaces/default/configmaps/cm"},
+		},
+		{
+			name: "create",
+			call: func() (*corev1.ConfigMap, error) {
+				return client.CoreV1().ConfigMaps("default").Create(ctx, cm, metav1.CreateOptions{})
+			},
+			resource:         "configmaps",
+			verb:             "create",
+			expectedData:     "server",
+			expectedRequests: []string{"POST /api/v1/namespaces/default/configmaps?dryRun=All&fieldManager=vc-syncer"},
+		},
+		{
+			name: "update",
+			call: func() (*corev1.ConfigMap, error) {
+				return client.CoreV1().ConfigMaps("default").Update(ctx, cm, metav1.UpdateOptions{})
+			},
+			resource:         "configmaps",
+			verb:             "update",
+			expectedData:     "server",
+			expectedRequests: []string{"PUT /api/v1/namespaces/default/configmaps/cm?dryRun=All&fieldManager=vc-syncer"},
+		},
+		{
+			name: "patch",
+			call: func() (*corev1.ConfigMap, error) {
+				return client.CoreV1().ConfigMaps("default").Patch(ctx, "cm", types.MergePatchType, []byte(`{"data":{"key":"patched"}}`), metav1.PatchOptions{})
+			},
+			resource:         "configmaps",
+			verb:             "patch",
+			expectedData:     "server",
+			expectedRequests: []string{"PATCH /api/v1/namespaces/default/configmaps/cm?dryRun=All&fieldManager=vc-syncer"},
+		},
+		{
+			name: "delete",
+			call: func() (*corev1.ConfigMap, error) {
+				return nil, client.CoreV1().ConfigMaps("default").Delete(ctx, "cm", metav1.DeleteOptions{})
+			},
+			resource:         "configmaps",
+			verb:             "delete",
+			expectedRequests: []string{"DELETE /api/v1/namespaces/default/configmaps/cm?dryRun=All"},
+			expectedBody:     `"dryRun":["All"]`,
+		},
+		{
+			name: "delete collection",
+			call: func() (*corev1.ConfigMap, error) {
+				return nil, client.CoreV1().ConfigMaps("default").DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{})
+			},
+			resource:         "configmaps",
+			verb:             "deletecollection",
+			expectedRequests: []string{"DELETE /api/v1/namespaces/default/configmaps?dryRun=All"},
+			expectedBody:     `"dryRun":["All"]`,
+		},
+		{
+			name: "delete of a resource not supporting dry runs",
+			call: func() (*corev1.ConfigMap, error) {
+				return nil, client.CoreV1().RESTClient().Delete().AbsPath("/apis/example.com/v1/namespaces/default/widgets/w").Body(&metav1.DeleteOptions{}).Do(ctx).Error()
+			},
+			resource:         "widgets",
+			verb:             "delete",
+			expectedRequests: []string{"DELETE /apis/example.com/v1/namespaces/default/widgets/w?dryRun=All"},
+			expectedBody:     `"dryRun":["All"]`,
+		},
+		{
+			name: "delete with options not encoded as JSON",
+			call: func() (*corev1.ConfigMap, error) {
+				return nil, client.CoreV1().RESTClient().Delete().AbsPath("/api/v1/namespaces/default/configmaps/cm").
+					SetHeader("Content-Type", "application/vnd.kubernetes.protobuf").Body([]byte("k8s\x00")).Do(ctx).Error()
+			},
+			resource: "configmaps",
+			verb:     "delete",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lock.Lock()
+			requests, bodies = nil, nil
+			lock.Unlock()
+			var before float64
+			if tc.verb != "" {
+				before = count(tc.resource, tc.verb)
+			}
+
+			got, err := tc.call()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != nil && got.Data["key"] != tc.expectedData {
+				t.Errorf("expected data %q, got %v", tc.expectedData, got.Data)
+			}
+			lock.Lock()
+			if len(requests) != len(tc.expectedRequests) || (len(requests) != 0 && requests[0] != tc.expectedRequests[0]) {
+				t.Errorf("expected the requests %v to reach the server, got %v", tc.expectedRequests, requests)
+			}
+			if tc.expectedBody != "" && (len(bodies) == 0 || !strings.Contains(bodies[0], tc.expectedBody)) {
+				t.Errorf("expected the body to contain %s, got %v", tc.expectedBody, bodies)
+			}
+			lock.Unlock()
+			if tc.verb != "" {
+				if after := count(tc.resource, tc.verb); after != before+1 {
+					t.Errorf("expected the %s of %s to be counted once, got %v", tc.verb, tc.resource, after-before)
+				}
+			}
+		})
+	}
+}
+
+func TestParseResourcePath(t *testing.T) {
+	for _, tt := range []struct {
+		path                      string
+		resource, namespace, name string
+	}{
+		{path: "/api/v1/namespaces/default/pods", resource: "pods", namespace: "default"},
+		{path: "/api/v1/namespaces/default/pods/test", resource: "pods", namespace: "default", name: "test"},
+		{path: "/api/v1/namespaces/default/pods/test/status", resource: "pods/status", namespace: "default", name: "test"},
+		{path: "/api/v1/namespaces/default", resource: "namespaces", name: "default"},
+		{path: "/api/v1/namespaces/default/finalize", resource: "namespaces/finalize", name: "default"},
+		{path: "/api/v1/nodes/node-1", resource: "nodes", name: "node-1"},
+		{path: "/apis/scheduling.k8s.io/v1/priorityclasses/high", resource: "priorityclasses", name: "high"},
+		{path: "/apis/apps/v1/namespaces/default/deployments/web/scale", resource: "deployments/scale", namespace: "default", name: "web"},
+		{path: "/prefix/apis/apps/v1/namespaces/default/deployments", resource: "deployments", namespace: "default"},
+	} {
+		resource, namespace, name := parseResourcePath(tt.path)
+		if resource != tt.resource || namespace != tt.namespace || name != tt.name {
+			t.Errorf("parseResourcePath(%q): expected %q %q %q, got %q %q %q", tt.path, tt.resource, tt.namespace, tt.name, resource, namespace, name)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	mc "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/mccontroller"
+)
+
+const (
+	// ExistenceDisagreementConfirmed means the authoritative apiserver does not have the object either.
+	ExistenceDisagreementConfirmed = "confirmed"
+	// ExistenceDisagreementKept means the authoritative apiserver still has the object, or could not
+	// be read, so its copy is kept.
+	ExistenceDisagreementKept = "kept"
+)
+
+// ObjectGetter reads an object from its apiserver, bypassing the informer caches.
+type ObjectGetter func() (metav1.Object, error)
+
+// TenantObjectGetter returns an ObjectGetter reading a tenant object with the client of its cluster.
+func TenantObjectGetter(mc mc.MultiClusterInterface, clusterName string, get func(clientset.Interface) (metav1.Object, error)) ObjectGetter {
+	return func() (metav1.Object, error) {
+		tenantClient, err := mc.GetClusterClient(clusterName)
+		if err != nil {
+			return nil, err
+		}
+		return get(tenantClient)
+	}
+}
+
+// ConfirmMissing tells whether a periodic checker can delete the copy of an object missing from
+// the informer cache of the side authoritative for its existence. Informer caches lag behind their
+// apiserver, e.g. while a watch is re-established, so with the ExistenceDisagreementConfirm policy
+// the object is read from the authoritative apiserver with get. It is missing if the apiserver does
+// not have it, or has an object with the same name but a uid other than uid, when uid is set. If it
+// exists, or cannot be read, the copy is kept and the checker tries again on its next run.
+func ConfirmMissing(policy, resource, clusterName, key, uid string, get ObjectGetter) bool {
+	if policy == constants.ExistenceDisagreementTrustCache {
+		metrics.RecordExistenceDisagreement(resource, clusterName, ExistenceDisagreementConfirmed)
+		return true
+	}
+
+	obj, err := get()
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		klog.Warningf("%s %s of cluster %s is missing from the informer cache, failed to confirm it with the apiserver, keeping its copy: %v", resource, key, clusterName, err)
+		metrics.RecordExistenceDisagreement(resource, clusterName, ExistenceDisagreementKept)
+		return false
+	case uid != "" && string(obj.GetUID()) != uid:
+		klog.V(4).Infof("%s %s of cluster %s has been recreated with uid %s, its copy of uid %s is an orphan", resource, key, clusterName, obj.GetUID(), uid)
+	default:
+		klog.Warningf("%s %s of cluster %s is missing from the informer cache but exists in the apiserver, keeping its copy until the cache catches up", resource, key, clusterName)
+		metrics.RecordExistenceDisagreement(resource, clusterName, ExistenceDisagreementKept)
+		return false
+	}
+	metrics.RecordExistenceDisagreement(resource, clusterName, ExistenceDisagreementConfirmed)
+	return true
+}
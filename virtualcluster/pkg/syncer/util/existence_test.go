@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+func TestConfirmMissing(t *testing.T) {
+	existing := func(uid string) ObjectGetter {
+		return func() (metav1.Object, error) {
+			return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default", UID: types.UID(uid)}}, nil
+		}
+	}
+	notFound := func() (metav1.Object, error) {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "cm")
+	}
+	failing := func() (metav1.Object, error) {
+		return nil, fmt.Errorf("connection refused")
+	}
+
+	for _, tt := range []struct {
+		name               string
+		policy             string
+		uid                string
+		get                ObjectGetter
+		expectedMissing    bool
+		expectedResolution string
+	}{
+		{
+			name:               "missing from the apiserver",
+			policy:             constants.ExistenceDisagreementConfirm,
+			uid:                "uid-1",
+			get:                notFound,
+			expectedMissing:    true,
+			expectedResolution: ExistenceDisagreementConfirmed,
+		},
+		{
+			name:               "cache lags behind the apiserver",
+			policy:             constants.ExistenceDisagreementConfirm,
+			uid:                "uid-1",
+			get:                existing("uid-1"),
+			expectedResolution: ExistenceDisagreementKept,
+		},
+		{
+			name:               "recreated with another uid",
+			policy:             constants.ExistenceDisagreementConfirm,
+			uid:                "uid-1",
+			get:                existing("uid-2"),
+			expectedMissing:    true,
+			expectedResolution: ExistenceDisagreementConfirmed,
+		},
+		{
+			name:               "existing without uid check",
+			policy:             constants.ExistenceDisagreementConfirm,
+			get:                existing("uid-2"),
+			expectedResolution: ExistenceDisagreementKept,
+		},
+		{
+			name:               "apiserver read fails",
+			policy:             constants.ExistenceDisagreementConfirm,
+			uid:                "uid-1",
+			get:                failing,
+			expectedResolution: ExistenceDisagreementKept,
+		},
+		{
+			name:               "default policy confirms",
+			uid:                "uid-1",
+			get:                existing("uid-1"),
+			expectedResolution: ExistenceDisagreementKept,
+		},
+		{
+			name:               "trust cache",
+			policy:             constants.ExistenceDisagreementTrustCache,
+			uid:                "uid-1",
+			get:                existing("uid-1"),
+			expectedMissing:    true,
+			expectedResolution: ExistenceDisagreementConfirmed,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := "cluster-" + tt.name
+			counter := metrics.ExistenceDisagreements.WithLabelValues("configmap", cluster, tt.expectedResolution)
+			before := testutil.ToFloat64(counter)
+
+			if missing := ConfirmMissing(tt.policy, "configmap", cluster, "default/cm", tt.uid, tt.get); missing != tt.expectedMissing {
+				t.Errorf("expected missing %v, got %v", tt.expectedMissing, missing)
+			}
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("expected one %s disagreement to be recorded, got %v", tt.expectedResolution, got)
+			}
+		})
+	}
+}
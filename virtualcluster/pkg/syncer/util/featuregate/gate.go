@@ -99,6 +99,11 @@ const (
 	// Although rare, this situation can arise due to potential bugs and race conditions.
 	// This feature allows users to perform separate investigation and resolution.
 	SyncTenantPVCStatusPhase = "SyncTenantPVCStatusPhase"
+
+	// ScopedTenantClusterRBAC is an experimental feature that allows the syncer to
+	// sync tenant ClusterRoleBindings of ServiceAccounts as Roles and RoleBindings
+	// confined to the super cluster namespaces of the tenant.
+	ScopedTenantClusterRBAC = "ScopedTenantClusterRBAC"
 )
 
 var defaultFeatures = FeatureList{
@@ -116,6 +121,7 @@ var defaultFeatures = FeatureList{
 	VServiceExternalIP:              {Default: false},
 	KubeAPIAccessSupport:            {Default: false},
 	SyncTenantPVCStatusPhase:        {Default: false},
+	ScopedTenantClusterRBAC:         {Default: false},
 }
 
 type Feature string
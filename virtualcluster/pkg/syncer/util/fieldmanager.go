@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"net/http"
+
+	"k8s.io/client-go/rest"
+)
+
+// WithFieldManager returns a copy of config whose write requests, e.g. creates, updates and
+// server-side apply patches, are sent with the given field manager unless they name their own.
+func WithFieldManager(config *rest.Config, fieldManager string) *rest.Config {
+	config = rest.CopyConfig(config)
+	if fieldManager == "" {
+		return config
+	}
+	wrapTransport := config.WrapTransport
+	config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrapTransport != nil {
+			rt = wrapTransport(rt)
+		}
+		return &fieldManagerRoundTripper{fieldManager: fieldManager, rt: rt}
+	}
+	return config
+}
+
+type fieldManagerRoundTripper struct {
+	fieldManager string
+	rt           http.RoundTripper
+}
+
+func (f *fieldManagerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return f.rt.RoundTrip(req)
+	}
+	query := req.URL.Query()
+	if query.Get("fieldManager") != "" {
+		return f.rt.RoundTrip(req)
+	}
+	query.Set("fieldManager", f.fieldManager)
+	// Round trippers must not modify the request, Clone copies the URL as well.
+	req = req.Clone(req.Context())
+	req.URL.RawQuery = query.Encode()
+	return f.rt.RoundTrip(req)
+}
+
+func (f *fieldManagerRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return f.rt
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestWithFieldManager(t *testing.T) {
+	var (
+		lock          sync.Mutex
+		fieldManagers = map[string]string{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		fieldManagers[r.Method+" "+r.Header.Get("Content-Type")] = r.URL.Query().Get("fieldManager")
+		lock.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"ConfigMap","apiVersion":"v1","metadata":{"name":"cm","namespace":"default"}}`))
+	}))
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL}
+	client, err := kubernetes.NewForConfig(WithFieldManager(config, "vc-syncer"))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	if config.WrapTransport != nil {
+		t.Errorf("expected the original config not to be modified")
+	}
+
+	ctx := context.TODO()
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}}
+	applyPatch := []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm"}}`)
+
+	tests := []struct {
+		name   string
+		call   func() error
+		key    string
+		expect string
+	}{
+		{
+			name: "get",
+			call: func() error {
+				_, err := client.CoreV1().ConfigMaps("default").Get(ctx, "cm", metav1.GetOptions{})
+				return err
+			},
+			key:    "GET ",
+			expect: "",
+		},
+		{
+			name: "create",
+			call: func() error {
+				_, err := client.CoreV1().ConfigMaps("default").Create(ctx, cm, metav1.CreateOptions{})
+				return err
+			},
+			key:    "POST application/json",
+			expect: "vc-syncer",
+		},
+		{
+			name: "update",
+			call: func() error {
+				_, err := client.CoreV1().ConfigMaps("default").Update(ctx, cm, metav1.UpdateOptions{})
+				return err
+			},
+			key:    "PUT application/json",
+			expect: "vc-syncer",
+		},
+		{
+			name: "apply",
+			call: func() error {
+				_, err := client.CoreV1().ConfigMaps("default").Patch(ctx, "cm", types.ApplyPatchType, applyPatch, metav1.PatchOptions{})
+				return err
+			},
+			key:    "PATCH " + string(types.ApplyPatchType),
+			expect: "vc-syncer",
+		},
+		{
+			name: "apply with own field manager",
+			call: func() error {
+				_, err := client.CoreV1().ConfigMaps("default").Patch(ctx, "cm", types.ApplyPatchType, applyPatch, metav1.PatchOptions{FieldManager: "other"})
+				return err
+			},
+			key:    "PATCH " + string(types.ApplyPatchType),
+			expect: "other",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			got, exists := fieldManagers[tc.key]
+			if !exists {
+				t.Fatalf("expected a %q request, got %v", tc.key, fieldManagers)
+			}
+			if got != tc.expect {
+				t.Errorf("expected field manager %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
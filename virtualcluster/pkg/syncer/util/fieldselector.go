@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// filteredInformer builds a super cluster informer listing and watching the objects matching the
+// tweaked list options only.
+type filteredInformer struct {
+	obj runtime.Object
+	new func(client clientset.Interface, resync time.Duration, indexers cache.Indexers, tweak func(*metav1.ListOptions)) cache.SharedIndexInformer
+}
+
+// fieldSelectorInformers are the super cluster informers that can be filtered by field selector,
+// by resource name. They are the informers of the resources whose super cluster objects are never
+// checked for existence: a missing event is not synced upward and a missing node leaves the status
+// of its tenant nodes as is.
+var fieldSelectorInformers = map[string]filteredInformer{
+	"event": {&corev1.Event{}, func(c clientset.Interface, r time.Duration, i cache.Indexers, t func(*metav1.ListOptions)) cache.SharedIndexInformer {
+		return coreinformers.NewFilteredEventInformer(c, metav1.NamespaceAll, r, i, t)
+	}},
+	"node": {&corev1.Node{}, func(c clientset.Interface, r time.Duration, i cache.Indexers, t func(*metav1.ListOptions)) cache.SharedIndexInformer {
+		return coreinformers.NewFilteredNodeInformer(c, r, i, t)
+	}},
+}
+
+// existenceCheckedResources are the resources whose super cluster objects the syncers and the
+// patrollers check for existence, e.g. to create the super pod of a tenant pod again or to delete
+// the tenant copy of a super persistent volume. An informer filtering them would make the objects
+// not matching the selector look deleted, so they cannot be filtered.
+var existenceCheckedResources = sets.NewString(
+	"configmap",
+	"endpoints",
+	"namespace",
+	"persistentvolume",
+	"persistentvolumeclaim",
+	"pod",
+	"secret",
+	"service",
+	"serviceaccount",
+)
+
+// ApplyInformerFieldSelectors makes the informers of the factory list and watch the super cluster
+// objects matching the field selector of their resource only, e.g. "type!=Normal" for "event". It must be called before any informer of these resources is requested from the factory.
+func ApplyInformerFieldSelectors(factory informers.SharedInformerFactory, selectors map[string]string) error {
+	resources := make([]string, 0, len(selectors))
+	for resource := range selectors {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+
+	for _, resource := range resources {
+		if existenceCheckedResources.Has(resource) {
+			return fmt.Errorf("field selectors are not supported for resource %q, the syncer would consider its super cluster objects not matching the selector deleted", resource)
+		}
+		informer, ok := fieldSelectorInformers[resource]
+		if !ok {
+			return fmt.Errorf("field selectors are not supported for resource %q, supported resources are %s", resource, strings.Join(FieldSelectorResources(), ", "))
+		}
+		selector, err := fields.ParseSelector(selectors[resource])
+		if err != nil {
+			return fmt.Errorf("invalid field selector of resource %s: %v", resource, err)
+		}
+		fieldSelector := selector.String()
+		tweak := func(options *metav1.ListOptions) {
+			options.FieldSelector = fieldSelector
+		}
+		newInformer := informer.new
+		factory.InformerFor(informer.obj, func(client clientset.Interface, resync time.Duration) cache.SharedIndexInformer {
+			return newInformer(client, resync, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, tweak)
+		})
+	}
+	return nil
+}
+
+// FieldSelectorResources returns the resources whose super cluster informers can be filtered by
+// field selector.
+func FieldSelectorResources() []string {
+	resources := make([]string, 0, len(fieldSelectorInformers))
+	for resource := range fieldSelectorInformers {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	return resources
+}
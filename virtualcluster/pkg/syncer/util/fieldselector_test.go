@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestApplyInformerFieldSelectors(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	factory := informers.NewSharedInformerFactory(client, 0)
+	if err := ApplyInformerFieldSelectors(factory, map[string]string{"event": "type!=Normal"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	eventInformer := factory.Core().V1().Events().Informer()
+	serviceInformer := factory.Core().V1().Services().Informer()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, eventInformer.HasSynced, serviceInformer.HasSynced) {
+		t.Fatalf("failed to sync the informers")
+	}
+
+	selectors := map[string]string{}
+	for _, action := range client.Actions() {
+		if list, ok := action.(core.ListAction); ok {
+			selectors[list.GetResource().Resource] = list.GetListRestrictions().Fields.String()
+		}
+	}
+	if selector, ok := selectors["events"]; !ok || selector != "type!=Normal" {
+		t.Errorf("expected events to be listed with field selector type!=Normal, got %q", selector)
+	}
+	if selector, ok := selectors["services"]; !ok || selector != "" {
+		t.Errorf("expected services to be listed without field selector, got %q", selector)
+	}
+}
+
+func TestApplyInformerFieldSelectorsErrors(t *testing.T) {
+	for name, selectors := range map[string]map[string]string{
+		"unknown resource":           {"deployment": "metadata.name=test"},
+		"existence checked resource": {"pod": "status.phase!=Succeeded"},
+		"invalid selector":           {"event": "type"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+			if err := ApplyInformerFieldSelectors(factory, selectors); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import "strings"
+
+// resourceAliases maps the short names accepted for the extra syncing resources to the syncer IDs.
+var resourceAliases = map[string]string{
+	"pvc": "persistentvolumeclaim",
+}
+
+// NormalizeResourceName returns the syncer ID of an extra syncing resource name, which is matched
+// case-insensitively and may be a short name, e.g. pvc.
+func NormalizeResourceName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if id, ok := resourceAliases[name]; ok {
+		return id
+	}
+	return name
+}
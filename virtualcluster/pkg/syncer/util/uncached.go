@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/pager"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+// uncachedResources are the resources whose syncers can read the super cluster objects with direct
+// requests instead of an informer cache.
+var uncachedResources = map[string]bool{
+	"configmap": true,
+	"secret":    true,
+}
+
+// UncachedResources returns the resources whose syncers can read the super cluster objects with
+// direct requests instead of an informer cache.
+func UncachedResources() []string {
+	resources := make([]string, 0, len(uncachedResources))
+	for resource := range uncachedResources {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// IsUncached returns whether the syncers read the super cluster objects of the resource with direct
+// requests instead of an informer cache.
+func IsUncached(config *config.SyncerConfiguration, resource string) bool {
+	if config == nil || !uncachedResources[resource] {
+		return false
+	}
+	for _, r := range config.UncachedResources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// eachPage lists the objects page by page, so that a list of many large objects does not have to be
+// held by the apiserver and the syncer at once.
+func eachPage(selector labels.Selector, list func(opts metav1.ListOptions) (runtime.Object, error), fn func(obj runtime.Object) error) error {
+	return pager.New(pager.SimplePageFunc(list)).EachListItem(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()}, fn)
+}
+
+// NewUncachedSecretLister returns a secret lister getting and listing the secrets from the apiserver.
+func NewUncachedSecretLister(client v1core.SecretsGetter) listersv1.SecretLister {
+	return &uncachedSecretLister{client: client}
+}
+
+type uncachedSecretLister struct {
+	client v1core.SecretsGetter
+}
+
+func (l *uncachedSecretLister) List(selector labels.Selector) ([]*corev1.Secret, error) {
+	return listSecrets(l.client, metav1.NamespaceAll, selector)
+}
+
+func (l *uncachedSecretLister) Secrets(namespace string) listersv1.SecretNamespaceLister {
+	return &uncachedSecretNamespaceLister{client: l.client, namespace: namespace}
+}
+
+type uncachedSecretNamespaceLister struct {
+	client    v1core.SecretsGetter
+	namespace string
+}
+
+func (l *uncachedSecretNamespaceLister) List(selector labels.Selector) ([]*corev1.Secret, error) {
+	return listSecrets(l.client, l.namespace, selector)
+}
+
+func (l *uncachedSecretNamespaceLister) Get(name string) (*corev1.Secret, error) {
+	return l.client.Secrets(l.namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func listSecrets(client v1core.SecretsGetter, namespace string, selector labels.Selector) ([]*corev1.Secret, error) {
+	var secrets []*corev1.Secret
+	err := eachPage(selector, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.Secrets(namespace).List(context.TODO(), opts)
+	}, func(obj runtime.Object) error {
+		secrets = append(secrets, obj.(*corev1.Secret))
+		return nil
+	})
+	return secrets, err
+}
+
+// NewUncachedConfigMapLister returns a configmap lister getting and listing the configmaps from the
+// apiserver.
+func NewUncachedConfigMapLister(client v1core.ConfigMapsGetter) listersv1.ConfigMapLister {
+	return &uncachedConfigMapLister{client: client}
+}
+
+type uncachedConfigMapLister struct {
+	client v1core.ConfigMapsGetter
+}
+
+func (l *uncachedConfigMapLister) List(selector labels.Selector) ([]*corev1.ConfigMap, error) {
+	return listConfigMaps(l.client, metav1.NamespaceAll, selector)
+}
+
+func (l *uncachedConfigMapLister) ConfigMaps(namespace string) listersv1.ConfigMapNamespaceLister {
+	return &uncachedConfigMapNamespaceLister{client: l.client, namespace: namespace}
+}
+
+type uncachedConfigMapNamespaceLister struct {
+	client    v1core.ConfigMapsGetter
+	namespace string
+}
+
+func (l *uncachedConfigMapNamespaceLister) List(selector labels.Selector) ([]*corev1.ConfigMap, error) {
+	return listConfigMaps(l.client, l.namespace, selector)
+}
+
+func (l *uncachedConfigMapNamespaceLister) Get(name string) (*corev1.ConfigMap, error) {
+	return l.client.ConfigMaps(l.namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+func listConfigMaps(client v1core.ConfigMapsGetter, namespace string, selector labels.Selector) ([]*corev1.ConfigMap, error) {
+	var configMaps []*corev1.ConfigMap
+	err := eachPage(selector, func(opts metav1.ListOptions) (runtime.Object, error) {
+		return client.ConfigMaps(namespace).List(context.TODO(), opts)
+	}, func(obj runtime.Object) error {
+		configMaps = append(configMaps, obj.(*corev1.ConfigMap))
+		return nil
+	})
+	return configMaps, err
+}
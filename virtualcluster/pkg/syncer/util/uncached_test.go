@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+)
+
+// pagedSecrets serves the lists of secrets in pages, continued by the index of the next page.
+type pagedSecrets struct {
+	v1core.SecretInterface
+	pages      [][]corev1.Secret
+	namespaces []string
+	requests   []metav1.ListOptions
+}
+
+func (p *pagedSecrets) Secrets(namespace string) v1core.SecretInterface {
+	p.namespaces = append(p.namespaces, namespace)
+	return p
+}
+
+func (p *pagedSecrets) List(_ context.Context, opts metav1.ListOptions) (*corev1.SecretList, error) {
+	p.requests = append(p.requests, opts)
+	page := 0
+	if opts.Continue != "" {
+		page, _ = strconv.Atoi(opts.Continue)
+	}
+	list := &corev1.SecretList{Items: p.pages[page]}
+	if page+1 < len(p.pages) {
+		list.Continue = strconv.Itoa(page + 1)
+	}
+	return list, nil
+}
+
+func secret(name string) corev1.Secret {
+	return corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+func TestUncachedSecretListerPages(t *testing.T) {
+	client := &pagedSecrets{
+		pages: [][]corev1.Secret{
+			{secret("a"), secret("b")},
+			{secret("c")},
+			{secret("d"), secret("e")},
+		},
+	}
+	selector := labels.SelectorFromSet(map[string]string{"foo": "bar"})
+
+	secrets, err := NewUncachedSecretLister(client).Secrets("ns").List(selector)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, s := range secrets {
+		names = append(names, s.Name)
+	}
+	if expected := []string{"a", "b", "c", "d", "e"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the secrets of all the pages %v, got %v", expected, names)
+	}
+	if len(client.requests) != 3 {
+		t.Fatalf("expected 3 page requests, got %d", len(client.requests))
+	}
+	for i, opts := range client.requests {
+		if opts.LabelSelector != "foo=bar" || opts.Limit == 0 {
+			t.Errorf("expected page request %d to be limited and selected by foo=bar, got %+v", i, opts)
+		}
+	}
+	for _, ns := range client.namespaces {
+		if ns != "ns" {
+			t.Errorf("expected the secrets of namespace ns to be listed, got %q", ns)
+		}
+	}
+}
+
+func TestIsUncached(t *testing.T) {
+	cfg := &config.SyncerConfiguration{UncachedResources: []string{"secret", "pod"}}
+	for resource, expected := range map[string]bool{
+		"secret":    true,
+		"configmap": false,
+		// Pods do not support it.
+		"pod": false,
+	} {
+		if got := IsUncached(cfg, resource); got != expected {
+			t.Errorf("expected IsUncached(%s) to be %v, got %v", resource, expected, got)
+		}
+	}
+	if IsUncached(nil, "secret") {
+		t.Errorf("expected a nil config to cache secrets")
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// WatchErrorRecoveryPeriod is how long the informer of a resource must not fail for its watch errors
+// to be forgotten. It is longer than the maximum backoff of the reflector between two attempts.
+const WatchErrorRecoveryPeriod = time.Minute
+
+// WatchErrorTracker counts the consecutive list and watch errors of the super and meta cluster informers,
+// which otherwise only show up in the logs while the informer keeps retrying.
+type WatchErrorTracker struct {
+	lock   sync.Mutex
+	errors map[string]*watchErrors
+	// now is time.Now except in tests.
+	now func() time.Time
+}
+
+type watchErrors struct {
+	count   int
+	last    time.Time
+	lastErr string
+}
+
+// FailingWatch is the informer of a resource which keeps failing.
+type FailingWatch struct {
+	Resource string
+	Errors   int
+	LastErr  string
+}
+
+// NewWatchErrorTracker returns an empty WatchErrorTracker.
+func NewWatchErrorTracker() *WatchErrorTracker {
+	return &WatchErrorTracker{
+		errors: make(map[string]*watchErrors),
+		now:    time.Now,
+	}
+}
+
+// DefaultWatchErrorTracker tracks the watch errors of the super and meta cluster informers of the syncer.
+var DefaultWatchErrorTracker = NewWatchErrorTracker()
+
+// TrackWatchErrors reports the watch errors of the super or meta cluster informer of the resource to the
+// DefaultWatchErrorTracker. It must be called before the informer is started.
+func TrackWatchErrors(informer cache.SharedInformer, resource string) {
+	if err := informer.SetWatchErrorHandler(DefaultWatchErrorTracker.WatchErrorHandler(resource)); err != nil {
+		klog.Warningf("failed to track the watch errors of the %s informer: %v", resource, err)
+	}
+}
+
+// WatchErrorHandler returns a cache.WatchErrorHandler recording the errors of the informer of the
+// resource, after logging them like the default handler.
+func (t *WatchErrorTracker) WatchErrorHandler(resource string) cache.WatchErrorHandler {
+	return func(r *cache.Reflector, err error) {
+		cache.DefaultWatchErrorHandler(r, err)
+		t.RecordError(resource, err)
+	}
+}
+
+// RecordError records an error of the informer of the resource. The expected ends of a watch, e.g.
+// an expired resource version, are not errors.
+func (t *WatchErrorTracker) RecordError(resource string, err error) {
+	if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF || apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+		return
+	}
+	metrics.RecordSuperWatchError(resource)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.now()
+	e, ok := t.errors[resource]
+	if !ok || now.Sub(e.last) >= WatchErrorRecoveryPeriod {
+		e = &watchErrors{}
+		t.errors[resource] = e
+	}
+	e.count++
+	e.last = now
+	e.lastErr = err.Error()
+}
+
+// Failing returns the informers which failed at least threshold consecutive times, sorted by
+// resource. Informers which have not failed for the WatchErrorRecoveryPeriod are forgotten.
+func (t *WatchErrorTracker) Failing(threshold int) []FailingWatch {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.now()
+	var failing []FailingWatch
+	for resource, e := range t.errors {
+		if now.Sub(e.last) >= WatchErrorRecoveryPeriod {
+			delete(t.errors, resource)
+			continue
+		}
+		if e.count >= threshold {
+			failing = append(failing, FailingWatch{Resource: resource, Errors: e.count, LastErr: e.lastErr})
+		}
+	}
+	sort.Slice(failing, func(i, j int) bool {
+		return failing[i].Resource < failing[j].Resource
+	})
+	return failing
+}
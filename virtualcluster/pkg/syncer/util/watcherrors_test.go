@@ -0,0 +1,73 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+func TestWatchErrorTracker(t *testing.T) {
+	now := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	tracker := NewWatchErrorTracker()
+	tracker.now = func() time.Time { return now }
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("RBAC revoked"))
+
+	// the expected ends of a watch are not errors.
+	tracker.RecordError("pods", io.EOF)
+	tracker.RecordError("pods", apierrors.NewResourceExpired("too old resource version"))
+	if failing := tracker.Failing(1); len(failing) != 0 {
+		t.Errorf("expected no failing informer, got %v", failing)
+	}
+
+	for i := 0; i < 3; i++ {
+		now = now.Add(10 * time.Second)
+		tracker.RecordError("pods", forbidden)
+	}
+	tracker.RecordError("secrets", forbidden)
+	if got := testutil.ToFloat64(metrics.SuperWatchErrors.WithLabelValues("pods")); got != 3 {
+		t.Errorf("expected 3 recorded pod watch errors, got %v", got)
+	}
+
+	failing := tracker.Failing(3)
+	if len(failing) != 1 || failing[0].Resource != "pods" || failing[0].Errors != 3 || failing[0].LastErr != forbidden.Error() {
+		t.Errorf("expected the pods informer to be failing, got %v", failing)
+	}
+	if failing := tracker.Failing(1); len(failing) != 2 || failing[0].Resource != "pods" || failing[1].Resource != "secrets" {
+		t.Errorf("expected the pods and secrets informers to be failing, got %v", failing)
+	}
+
+	// an informer that has not failed for the recovery period is forgotten.
+	now = now.Add(WatchErrorRecoveryPeriod)
+	if failing := tracker.Failing(1); len(failing) != 0 {
+		t.Errorf("expected the informers to have recovered, got %v", failing)
+	}
+
+	// the count starts over after a recovery.
+	tracker.RecordError("pods", forbidden)
+	if failing := tracker.Failing(2); len(failing) != 0 {
+		t.Errorf("expected no informer to fail twice since the recovery, got %v", failing)
+	}
+}
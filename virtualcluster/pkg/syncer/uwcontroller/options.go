@@ -19,8 +19,11 @@ package uwcontroller
 import (
 	"time"
 
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/workqueue"
 
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/ratelimiter"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -37,6 +40,11 @@ func WithOptions(o *Options) OptConfig {
 		WithWorkQueue(o.Queue)(options)
 		WithJitterPeriod(o.JitterPeriod)(options)
 		WithMaxConcurrentReconciles(o.MaxConcurrentReconciles)(options)
+		WithWriteLimiter(o.WriteLimiter)(options)
+		WithCoalescePeriod(o.CoalescePeriod)(options)
+		WithMaxRetries(o.MaxRetries)(options)
+		WithRateLimiter(o.RateLimiter)(options)
+		WithObjectGetter(o.ObjectGetter)(options)
 	}
 }
 
@@ -84,3 +92,85 @@ func WithMaxConcurrentReconciles(n int) OptConfig {
 		}
 	}
 }
+
+// WithWriteRateLimit limits the back populations of the controller to qps per second if valid.
+func WithWriteRateLimit(qps float32, burst int) OptConfig {
+	return func(options *Options) {
+		if qps <= 0 {
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		options.WriteLimiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+	}
+}
+
+// WithWriteLimiter set the WriteLimiter.
+func WithWriteLimiter(l flowcontrol.RateLimiter) OptConfig {
+	return func(options *Options) {
+		if l != nil {
+			options.WriteLimiter = l
+		}
+	}
+}
+
+// WithCoalescePeriod set CoalescePeriod if valid.
+func WithCoalescePeriod(t time.Duration) OptConfig {
+	return func(options *Options) {
+		if t > 0 {
+			options.CoalescePeriod = t
+		}
+	}
+}
+
+// WithMaxRetries set MaxRetries if valid.
+func WithMaxRetries(n int) OptConfig {
+	return func(options *Options) {
+		if n > 0 {
+			options.MaxRetries = n
+		}
+	}
+}
+
+// WithRateLimiter set the RateLimiter of the default queue if not nil.
+func WithRateLimiter(l workqueue.RateLimiter) OptConfig {
+	return func(options *Options) {
+		if l != nil {
+			options.RateLimiter = l
+		}
+	}
+}
+
+// WithRetries set MaxRetries, and the RateLimiter to an exponential back-off from baseDelay to
+// maxDelay, if valid.
+func WithRetries(maxRetries int, baseDelay, maxDelay time.Duration) OptConfig {
+	return func(options *Options) {
+		WithMaxRetries(maxRetries)(options)
+		if baseDelay > 0 && maxDelay >= baseDelay {
+			options.RateLimiter = ratelimiter.NewRetryRateLimiter(baseDelay, maxDelay)
+		}
+	}
+}
+
+// WithObjectGetter set the ObjectGetter if not nil.
+func WithObjectGetter(g ObjectGetter) OptConfig {
+	return func(options *Options) {
+		if g != nil {
+			options.ObjectGetter = g
+		}
+	}
+}
+
+// WithSyncerConfig set the write rate limit, the coalesce period and the retries of the syncer
+// configuration.
+func WithSyncerConfig(c *config.SyncerConfiguration) OptConfig {
+	return func(options *Options) {
+		if c == nil {
+			return
+		}
+		WithWriteRateLimit(c.UWSQPS, c.UWSBurst)(options)
+		WithCoalescePeriod(c.UWSCoalescePeriod.Duration)(options)
+		WithRetries(c.SyncMaxRetries, c.SyncBaseDelay.Duration, c.SyncMaxDelay.Duration)(options)
+	}
+}
@@ -21,16 +21,21 @@ import (
 	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/flowcontrol"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/tracing"
 	utilconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/drain"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -42,6 +47,9 @@ type UpwardController struct {
 	// objectKind is the kind of target object this controller watched.
 	objectKind string
 
+	// observedTimes are the times the changes of the queued keys were first observed.
+	observedTimes *metrics.ObservedTimes
+
 	Options
 }
 
@@ -54,10 +62,30 @@ type Options struct {
 	Reconciler reconciler.UWReconciler
 	// Queue can be used to override the default queue.
 	Queue workqueue.RateLimitingInterface
+	// WriteLimiter, if set, limits the rate of the writes of the back populations to the tenant control
+	// planes, see WaitForTenantWrite.
+	WriteLimiter flowcontrol.RateLimiter
+	// CoalescePeriod delays the back population of an object by this period after its first change,
+	// the other changes of the object during this period are back populated together.
+	CoalescePeriod time.Duration
+	// MaxRetries is the number of retries after which a failing back population is dropped. 0 means
+	// MaxReconcileRetryAttempts.
+	MaxRetries int
+	// RateLimiter, if set, delays the retries of the default Queue instead of the default controller
+	// rate limiter. It is not used if Queue is set.
+	RateLimiter workqueue.RateLimiter
+	// ObjectGetter, if set, gets the super cluster objects of the queued keys, whose annotations give
+	// the tenant object the back populations are traced for.
+	ObjectGetter ObjectGetter
 
 	name string
 }
 
+// ObjectGetter gets the super cluster object of a queued key, e.g. the indexer of its informer.
+type ObjectGetter interface {
+	GetByKey(key string) (item interface{}, exists bool, err error)
+}
+
 func NewUWController(objectType client.Object, rc reconciler.UWReconciler, opts ...OptConfig) (*UpwardController, error) {
 	kinds, _, err := scheme.Scheme.ObjectKinds(objectType)
 	if err != nil || len(kinds) == 0 {
@@ -66,14 +94,14 @@ func NewUWController(objectType client.Object, rc reconciler.UWReconciler, opts
 
 	name := fmt.Sprintf("%s-upward-controller", strings.ToLower(kinds[0].Kind))
 	c := &UpwardController{
-		objectType: objectType,
-		objectKind: kinds[0].Kind,
+		objectType:    objectType,
+		objectKind:    kinds[0].Kind,
+		observedTimes: metrics.NewObservedTimes(),
 		Options: Options{
 			name:                    name,
 			JitterPeriod:            1 * time.Second,
 			MaxConcurrentReconciles: constants.UwsControllerWorkerLow,
 			Reconciler:              rc,
-			Queue:                   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
 		},
 	}
 
@@ -81,6 +109,14 @@ func NewUWController(objectType client.Object, rc reconciler.UWReconciler, opts
 		opt(&c.Options)
 	}
 
+	if c.Queue == nil {
+		limiter := c.RateLimiter
+		if limiter == nil {
+			limiter = workqueue.DefaultControllerRateLimiter()
+		}
+		c.Queue = workqueue.NewNamedRateLimitingQueue(limiter, c.name)
+	}
+
 	if c.Reconciler == nil {
 		return nil, fmt.Errorf("uwcontroller %q: must specify UW Reconciler", c.objectKind)
 	}
@@ -103,6 +139,13 @@ func (c *UpwardController) Start(stop <-chan struct{}) error {
 }
 
 func (c *UpwardController) AddToQueue(key string) {
+	c.observedTimes.Observe(key)
+	if c.CoalescePeriod > 0 {
+		// The queue keeps a single, earliest, entry for a key waiting to be added. The back
+		// population reads the latest super cluster object, so the last change is never lost.
+		c.Queue.AddAfter(key, c.CoalescePeriod)
+		return
+	}
 	c.Queue.Add(key)
 }
 
@@ -118,18 +161,32 @@ func (c *UpwardController) processNextWorkItem() bool {
 	}
 	defer c.Queue.Done(obj)
 
+	// the syncer is shutting down, leave the key to the next syncer.
+	if !drain.DefaultTracker.Start() {
+		return false
+	}
+	defer drain.DefaultTracker.Done()
+
 	key, ok := obj.(string)
 	if !ok {
 		c.Queue.Forget(obj)
 		return true
 	}
+	metrics.RecordQueueDepth(c.name, c.Queue.Len())
+	// the changes observed from now on are back populated by the next processing of the key.
+	observed, hasObserved := c.observedTimes.Take(key)
 
 	defer metrics.RecordUWSOperationDuration(c.objectKind, time.Now())
 
-	klog.V(4).Infof("%s back populate %+v", c.name, key)
+	logsampling.V(4, c.objectKind).Infof("%s back populate %+v", c.name, key)
+	span := tracing.Start(tracing.DirectionUWS, c.objectKind, c.tracedObject(key))
 	err := c.Reconciler.BackPopulate(key)
+	span.End(err)
 	if err == nil {
 		metrics.RecordUWSOperationStatus(c.objectKind, utilconstants.StatusCodeOK)
+		if hasObserved {
+			metrics.RecordSyncLatency(c.objectKind, metrics.SyncDirectionUWS, observed)
+		}
 		c.Queue.Forget(obj)
 		return true
 	}
@@ -142,13 +199,63 @@ func (c *UpwardController) processNextWorkItem() bool {
 	}
 
 	utilruntime.HandleError(fmt.Errorf("%s error processing %s (will retry): %v", c.name, key, err))
-	if c.Queue.NumRequeues(key) >= utilconstants.MaxReconcileRetryAttempts {
+	if c.Queue.NumRequeues(key) >= c.maxRetries() {
 		metrics.RecordUWSOperationStatus(c.objectKind, utilconstants.StatusCodeExceedMaxRetryAttempts)
-		klog.Warningf("%s uws request is dropped due to reaching max retry limit: %s", c.name, key)
+		metrics.RecordQueueDrop(c.name)
+		klog.Errorf("%s uws request is dropped due to reaching max retry limit: %s: %v", c.name, key, err)
 		c.Queue.Forget(obj)
 		return true
 	}
 	metrics.RecordUWSOperationStatus(c.objectKind, utilconstants.StatusCodeError)
+	if hasObserved {
+		c.observedTimes.Restore(key, observed)
+	}
+	metrics.RecordQueueRetry(c.name)
 	c.Queue.AddRateLimited(obj)
 	return true
 }
+
+// WaitForTenantWrite blocks until the WriteLimiter, if set, admits a write to a tenant control plane.
+// The reconcilers call it right before each tenant write, so the back populations finding the tenant
+// object up to date do not wait for the limiter.
+func (c *UpwardController) WaitForTenantWrite() {
+	if c.WriteLimiter != nil {
+		c.WriteLimiter.Accept()
+	}
+}
+
+// tracedObject returns the object the back population of the key is traced for: the tenant object
+// of the super cluster object, known from its annotations, so that the uws spans are in the trace of
+// the dws spans of the object, or else the key.
+func (c *UpwardController) tracedObject(key string) tracing.Object {
+	obj := tracing.Object{Name: key}
+	if c.ObjectGetter == nil || !tracing.Enabled() {
+		return obj
+	}
+	item, exists, err := c.ObjectGetter.GetByKey(key)
+	if err != nil || !exists {
+		return obj
+	}
+	pObj, err := meta.Accessor(item)
+	if err != nil {
+		return obj
+	}
+	annotations := pObj.GetAnnotations()
+	if annotations[constants.LabelCluster] == "" || annotations[constants.LabelUID] == "" {
+		return obj
+	}
+	return tracing.Object{
+		Cluster:   annotations[constants.LabelCluster],
+		Namespace: annotations[constants.LabelNamespace],
+		Name:      pObj.GetName(),
+		UID:       annotations[constants.LabelUID],
+	}
+}
+
+// maxRetries returns the number of retries after which a failing back population is dropped.
+func (c *UpwardController) maxRetries() int {
+	if c.MaxRetries > 0 {
+		return c.MaxRetries
+	}
+	return utilconstants.MaxReconcileRetryAttempts
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uwcontroller
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/tracing"
+)
+
+// fakeUWReconciler back populates the latest super cluster state of an object to the tenant.
+type fakeUWReconciler struct {
+	sync.Mutex
+	c      *UpwardController
+	super  map[string]int
+	tenant map[string]int
+	writes int
+	// err, if set, fails the back populations.
+	err error
+}
+
+func newFakeUWReconciler() *fakeUWReconciler {
+	return &fakeUWReconciler{super: make(map[string]int), tenant: make(map[string]int)}
+}
+
+func (r *fakeUWReconciler) BackPopulate(key string) error {
+	r.Lock()
+	defer r.Unlock()
+	r.writes++
+	if r.err != nil {
+		return r.err
+	}
+	if state, ok := r.tenant[key]; ok && state == r.super[key] {
+		return nil
+	}
+	r.c.WaitForTenantWrite()
+	r.tenant[key] = r.super[key]
+	return nil
+}
+
+func (r *fakeUWReconciler) set(key string, state int) {
+	r.Lock()
+	defer r.Unlock()
+	r.super[key] = state
+}
+
+func (r *fakeUWReconciler) synced(key string) bool {
+	r.Lock()
+	defer r.Unlock()
+	return r.tenant[key] == r.super[key]
+}
+
+func (r *fakeUWReconciler) writeCount() int {
+	r.Lock()
+	defer r.Unlock()
+	return r.writes
+}
+
+func startController(t *testing.T, rc *fakeUWReconciler, opts ...OptConfig) (*UpwardController, chan struct{}) {
+	c, err := NewUWController(&corev1.Pod{}, rc, opts...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rc.c = c
+	stop := make(chan struct{})
+	go c.Start(stop)
+	return c, stop
+}
+
+func TestCoalescePeriod(t *testing.T) {
+	const (
+		key    = "ns/pod"
+		flips  = 50
+		period = 200 * time.Millisecond
+	)
+	rc := newFakeUWReconciler()
+	c, stop := startController(t, rc, WithCoalescePeriod(period))
+	defer close(stop)
+
+	start := time.Now()
+	for i := 1; i <= flips; i++ {
+		rc.set(key, i)
+		c.AddToQueue(key)
+		time.Sleep(time.Millisecond)
+	}
+	elapsed := time.Since(start)
+
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return rc.synced(key) && c.Queue.Len() == 0, nil
+	}); err != nil {
+		t.Fatalf("the last state was not back populated: %v", err)
+	}
+	// let a pending coalesced request, if any, run.
+	time.Sleep(2 * period)
+
+	// one write per coalesce period plus the one of the change that arrives while a write is in progress.
+	maxWrites := int(elapsed/period) + 2
+	if writes := rc.writeCount(); writes > maxWrites {
+		t.Errorf("expected at most %d writes for %d changes in %v, got %d", maxWrites, flips, elapsed, writes)
+	}
+	if !rc.synced(key) {
+		t.Errorf("the last state was not back populated")
+	}
+}
+
+func TestNoCoalescePeriod(t *testing.T) {
+	rc := newFakeUWReconciler()
+	c, stop := startController(t, rc)
+	defer close(stop)
+
+	rc.set("ns/pod", 1)
+	c.AddToQueue("ns/pod")
+	if err := wait.PollImmediate(10*time.Millisecond, time.Second, func() (bool, error) {
+		return rc.synced("ns/pod") && rc.writeCount() == 1, nil
+	}); err != nil {
+		t.Errorf("expected an immediate back population: %v", err)
+	}
+}
+
+func TestWriteRateLimit(t *testing.T) {
+	const keys = 5
+	rc := newFakeUWReconciler()
+	c, stop := startController(t, rc, WithWriteRateLimit(10, 1))
+	defer close(stop)
+
+	start := time.Now()
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("ns/pod-%d", i)
+		rc.set(key, 1)
+		c.AddToQueue(key)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return rc.writeCount() == keys, nil
+	}); err != nil {
+		t.Fatalf("expected %d writes, got %d", keys, rc.writeCount())
+	}
+	// the first write uses the burst, the others wait 100ms each.
+	if elapsed := time.Since(start); elapsed < 350*time.Millisecond {
+		t.Errorf("expected %d writes at 10 qps to take at least 400ms, took %v", keys, elapsed)
+	}
+}
+
+func TestWriteRateLimitUpToDate(t *testing.T) {
+	const keys = 5
+	rc := newFakeUWReconciler()
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("ns/pod-%d", i)
+		rc.super[key] = 1
+		rc.tenant[key] = 1
+	}
+	c, stop := startController(t, rc, WithWriteRateLimit(1, 1))
+	defer close(stop)
+
+	start := time.Now()
+	for i := 0; i < keys; i++ {
+		c.AddToQueue(fmt.Sprintf("ns/pod-%d", i))
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return rc.writeCount() == keys, nil
+	}); err != nil {
+		t.Fatalf("expected %d back populations, got %d", keys, rc.writeCount())
+	}
+	// nothing is written to the tenant, so no back population waits for the limiter.
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the back populations of up to date objects not to be rate limited, took %v", elapsed)
+	}
+}
+
+func TestMaxRetries(t *testing.T) {
+	rc := newFakeUWReconciler()
+	rc.err = errors.New("tenant apiserver unavailable")
+	c, stop := startController(t, rc, WithControllerName("max-retries-upward-controller"), WithRetries(2, time.Millisecond, time.Millisecond))
+	defer close(stop)
+	drops := metrics.QueueDrops.WithLabelValues("max-retries-upward-controller")
+
+	c.AddToQueue("ns/pod")
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) {
+		return testutil.ToFloat64(drops) == 1, nil
+	}); err != nil {
+		t.Fatalf("expected the request to be dropped after 2 retries: %v", err)
+	}
+	// the dropped request is not retried anymore.
+	time.Sleep(100 * time.Millisecond)
+	if writes := rc.writeCount(); writes != 3 {
+		t.Errorf("expected 3 back populations, got %d", writes)
+	}
+	if got := c.Queue.NumRequeues("ns/pod"); got != 0 {
+		t.Errorf("expected the request to be forgotten, got %d requeues", got)
+	}
+}
+
+// discardExporter enables tracing without exporting the spans.
+type discardExporter struct{}
+
+func (discardExporter) Export(*tracing.Span) {}
+
+func TestTracedObject(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, pod := range []*corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-default", Name: "synced", Annotations: map[string]string{
+			constants.LabelCluster:   "tenant",
+			constants.LabelNamespace: "default",
+			constants.LabelUID:       "7374a172-c35d-45b1-9c8e-bf5c5b614937",
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "tenant-default", Name: "unsynced"}},
+	} {
+		if err := indexer.Add(pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	c, err := NewUWController(&corev1.Pod{}, newFakeUWReconciler(), WithObjectGetter(indexer))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tracing.SetExporter(discardExporter{}, tracing.MaxSamplingRatePerMillion)
+	defer tracing.SetExporter(nil, 0)
+	for key, expected := range map[string]tracing.Object{
+		"tenant-default/synced":   {Cluster: "tenant", Namespace: "default", Name: "synced", UID: "7374a172-c35d-45b1-9c8e-bf5c5b614937"},
+		"tenant-default/unsynced": {Name: "tenant-default/unsynced"},
+		"tenant-default/deleted":  {Name: "tenant-default/deleted"},
+	} {
+		if got := c.tracedObject(key); got != expected {
+			t.Errorf("expected the back population of %s to be traced for %+v, got %+v", key, expected, got)
+		}
+	}
+}
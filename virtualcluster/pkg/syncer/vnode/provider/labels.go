@@ -0,0 +1,180 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// labelRule is an entry of the labels to sync. The pattern is a label key, or a glob of label keys
+// if it contains *, which matches any characters. The value, if set, overrides the value of the
+// super cluster node label.
+type labelRule struct {
+	pattern string
+	value   *string
+}
+
+func (r labelRule) isGlob() bool {
+	return strings.Contains(r.pattern, "*")
+}
+
+// literals is the number of characters of the pattern other than *, the more the more specific.
+func (r labelRule) literals() int {
+	return len(r.pattern) - strings.Count(r.pattern, "*")
+}
+
+// matches tells whether the rule applies to the label key. Labels of the reserved kubernetes.io
+// and k8s.io domains only match keys and globs which name their domain without *.
+func (r labelRule) matches(key string) bool {
+	if !r.isGlob() {
+		return r.pattern == key
+	}
+	if isReservedLabel(key) {
+		i := strings.Index(r.pattern, "/")
+		if i < 0 || strings.Contains(r.pattern[:i], "*") {
+			return false
+		}
+	}
+	return matchGlob(r.pattern, key)
+}
+
+// ParseLabelToSync parses an entry of the labels to sync, "key", "glob" or "key=value".
+func ParseLabelToSync(entry string) (pattern string, value *string, err error) {
+	r, err := parseLabelRule(entry)
+	if err != nil {
+		return "", nil, err
+	}
+	return r.pattern, r.value, nil
+}
+
+func parseLabelRule(entry string) (labelRule, error) {
+	var r labelRule
+	r.pattern = entry
+	if i := strings.Index(entry, "="); i >= 0 {
+		value := entry[i+1:]
+		r.pattern, r.value = entry[:i], &value
+		if errs := validation.IsValidLabelValue(value); len(errs) != 0 {
+			return r, fmt.Errorf("invalid label value %q: %s", value, strings.Join(errs, "; "))
+		}
+	}
+	if r.pattern == "" {
+		return r, fmt.Errorf("missing label key")
+	}
+	// A glob is valid if it would be a valid key with its * replaced.
+	if errs := validation.IsQualifiedName(strings.ReplaceAll(r.pattern, "*", "x")); len(errs) != 0 {
+		return r, fmt.Errorf("invalid label key %q: %s", r.pattern, strings.Join(errs, "; "))
+	}
+	return r, nil
+}
+
+// labelRules parses the labels to sync, skipping the invalid ones, and sorts them from the most
+// specific: keys before globs, then globs by decreasing number of literal characters, then
+// overrides before copies. The remaining ties are sorted by pattern and value, so that the first
+// rule matching a label key is always the same one.
+func labelRules(labelsToSync map[string]struct{}) []labelRule {
+	rules := make([]labelRule, 0, len(labelsToSync))
+	for entry := range labelsToSync {
+		r, err := parseLabelRule(entry)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if a.isGlob() != b.isGlob() {
+			return !a.isGlob()
+		}
+		if a.literals() != b.literals() {
+			return a.literals() > b.literals()
+		}
+		if (a.value != nil) != (b.value != nil) {
+			return a.value != nil
+		}
+		if a.pattern != b.pattern {
+			return a.pattern < b.pattern
+		}
+		return a.value != nil && *a.value < *b.value
+	})
+	return rules
+}
+
+// syncLabels returns the labels of the super cluster node to sync to the vNode, along with the
+// overrides of label keys the node does not have.
+func syncLabels(rules []labelRule, nodeLabels map[string]string) map[string]string {
+	labels := make(map[string]string)
+	for _, r := range rules {
+		if !r.isGlob() && r.value != nil {
+			if _, found := labels[r.pattern]; !found {
+				labels[r.pattern] = *r.value
+			}
+		}
+	}
+	for k, v := range nodeLabels {
+		for _, r := range rules {
+			if !r.matches(k) {
+				continue
+			}
+			if r.value != nil {
+				v = *r.value
+			}
+			labels[k] = v
+			break
+		}
+	}
+	return labels
+}
+
+// isReservedLabel tells whether the label key has a kubernetes.io or k8s.io domain prefix.
+func isReservedLabel(key string) bool {
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	domain := key[:i]
+	for _, reserved := range []string{"kubernetes.io", "k8s.io"} {
+		if domain == reserved || strings.HasSuffix(domain, "."+reserved) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob tells whether s matches the pattern, where * matches any characters.
+func matchGlob(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	first, last := parts[0], parts[len(parts)-1]
+	if !strings.HasPrefix(s, first) {
+		return false
+	}
+	s = s[len(first):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, last)
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/utils/pointer"
+)
+
+func TestSyncLabels(t *testing.T) {
+	nodeLabels := map[string]string{
+		"kubernetes.io/hostname":         "node-1",
+		"node-role.kubernetes.io/worker": "",
+		"topology.k8s.io/zone":           "us-east-1a",
+		"node.example.com/gpu":           "a100",
+		"node.example.com/gpu-count":     "8",
+		"node.example.com/cpu":           "epyc",
+		"node.example.com/internal":      "r12",
+		"team.example.com/owner":         "infra",
+		"unrelated":                      "x",
+	}
+
+	for _, tt := range []struct {
+		name         string
+		labelsToSync []string
+		expected     map[string]string
+	}{
+		{
+			name:         "keys",
+			labelsToSync: []string{"kubernetes.io/hostname", "node.example.com/gpu", "missing"},
+			expected: map[string]string{
+				"kubernetes.io/hostname": "node-1",
+				"node.example.com/gpu":   "a100",
+			},
+		},
+		{
+			name:         "prefix glob",
+			labelsToSync: []string{"node.example.com/*"},
+			expected: map[string]string{
+				"node.example.com/gpu":       "a100",
+				"node.example.com/gpu-count": "8",
+				"node.example.com/cpu":       "epyc",
+				"node.example.com/internal":  "r12",
+			},
+		},
+		{
+			name:         "glob in the middle",
+			labelsToSync: []string{"*.example.com/*u"},
+			expected: map[string]string{
+				"node.example.com/gpu": "a100",
+				"node.example.com/cpu": "epyc",
+			},
+		},
+		{
+			name:         "override of a missing label",
+			labelsToSync: []string{"node.example.com/pool=gpu"},
+			expected: map[string]string{
+				"node.example.com/pool": "gpu",
+			},
+		},
+		{
+			name:         "override of an existing label",
+			labelsToSync: []string{"node.example.com/gpu=nvidia", "node.example.com/gpu"},
+			expected: map[string]string{
+				"node.example.com/gpu": "nvidia",
+			},
+		},
+		{
+			name:         "key wins over overlapping globs",
+			labelsToSync: []string{"node.example.com/*=hidden", "node.example.com/gpu*=gpu", "node.example.com/gpu"},
+			expected: map[string]string{
+				"node.example.com/gpu":       "a100",
+				"node.example.com/gpu-count": "gpu",
+				"node.example.com/cpu":       "hidden",
+				"node.example.com/internal":  "hidden",
+			},
+		},
+		{
+			name:         "longest glob wins",
+			labelsToSync: []string{"node.example.com/*", "node.example.com/int*=redacted", "*=other"},
+			expected: map[string]string{
+				"node.example.com/gpu":       "a100",
+				"node.example.com/gpu-count": "8",
+				"node.example.com/cpu":       "epyc",
+				"node.example.com/internal":  "redacted",
+				"team.example.com/owner":     "other",
+				"unrelated":                  "other",
+			},
+		},
+		{
+			name:         "equally specific globs",
+			labelsToSync: []string{"node.example.com/g*", "*de.example.com/gpu=x", "node.example.com/g*=y"},
+			expected: map[string]string{
+				"node.example.com/gpu":       "x",
+				"node.example.com/gpu-count": "y",
+			},
+		},
+		{
+			name:         "reserved labels need their domain",
+			labelsToSync: []string{"*", "*.kubernetes.io/*", "topology.k8s.io/*"},
+			expected: map[string]string{
+				"node.example.com/gpu":       "a100",
+				"node.example.com/gpu-count": "8",
+				"node.example.com/cpu":       "epyc",
+				"node.example.com/internal":  "r12",
+				"team.example.com/owner":     "infra",
+				"unrelated":                  "x",
+				"topology.k8s.io/zone":       "us-east-1a",
+			},
+		},
+		{
+			name:         "reserved label key",
+			labelsToSync: []string{"node-role.kubernetes.io/worker", "*"},
+			expected: map[string]string{
+				"node-role.kubernetes.io/worker": "",
+				"node.example.com/gpu":           "a100",
+				"node.example.com/gpu-count":     "8",
+				"node.example.com/cpu":           "epyc",
+				"node.example.com/internal":      "r12",
+				"team.example.com/owner":         "infra",
+				"unrelated":                      "x",
+			},
+		},
+		{
+			name:         "invalid entries are skipped",
+			labelsToSync: []string{"=x", "node.example.com/gpu=not valid", "node.example.com/cpu"},
+			expected: map[string]string{
+				"node.example.com/cpu": "epyc",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			labelsToSync := make(map[string]struct{})
+			for _, entry := range tt.labelsToSync {
+				labelsToSync[entry] = struct{}{}
+			}
+			// The result must not depend on the map order.
+			for i := 0; i < 10; i++ {
+				if got := syncLabels(labelRules(labelsToSync), nodeLabels); !reflect.DeepEqual(got, tt.expected) {
+					t.Fatalf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLabelToSync(t *testing.T) {
+	for _, tt := range []struct {
+		entry           string
+		expectedPattern string
+		expectedValue   *string
+		expectedError   string
+	}{
+		{entry: "node.example.com/gpu", expectedPattern: "node.example.com/gpu"},
+		{entry: "node.example.com/*", expectedPattern: "node.example.com/*"},
+		{entry: "*", expectedPattern: "*"},
+		{entry: "pool=gpu", expectedPattern: "pool", expectedValue: pointer.StringPtr("gpu")},
+		{entry: "pool=", expectedPattern: "pool", expectedValue: pointer.StringPtr("")},
+		{entry: "", expectedError: "missing label key"},
+		{entry: "=gpu", expectedError: "missing label key"},
+		{entry: "pool=a=b", expectedError: `invalid label value "a=b"`},
+		{entry: "no spaces/*", expectedError: `invalid label key "no spaces/*"`},
+		{entry: "a/b/c", expectedError: `invalid label key "a/b/c"`},
+	} {
+		t.Run(tt.entry, func(t *testing.T) {
+			pattern, value, err := ParseLabelToSync(tt.entry)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error containing %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pattern != tt.expectedPattern || !reflect.DeepEqual(value, tt.expectedValue) {
+				t.Errorf("expected %q %v, got %q %v", tt.expectedPattern, tt.expectedValue, pattern, value)
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, s string
+		expected   bool
+	}{
+		{"a", "a", true},
+		{"a", "ab", false},
+		{"*", "", true},
+		{"a*", "abc", true},
+		{"*c", "abc", true},
+		{"a*c", "ac", true},
+		{"a*c", "abcbc", true},
+		{"a*c", "abcb", false},
+		{"a*b*c", "axbyc", true},
+		{"a*b*c", "axcyb", false},
+		{"ab*b", "ab", false},
+	} {
+		if got := matchGlob(tt.pattern, tt.s); got != tt.expected {
+			t.Errorf("matchGlob(%q, %q): expected %v, got %v", tt.pattern, tt.s, tt.expected, got)
+		}
+	}
+}
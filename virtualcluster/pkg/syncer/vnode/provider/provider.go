@@ -33,22 +33,16 @@ type VirtualNodeProvider interface {
 	GetTaintsToSync() map[string]struct{}
 }
 
-// GetNodeLabels is used to sync allowed node labels to vNode
+// GetNodeLabels is used to sync allowed node labels to vNode. The labels to sync are label keys,
+// globs of label keys and key=value overrides, see ParseLabelToSync. A label matched by several of
+// them is synced by the most specific one.
 func GetNodeLabels(p VirtualNodeProvider, node *corev1.Node) map[string]string {
-	labels := map[string]string{
-		constants.LabelVirtualNode: "true",
-	}
+	labels := syncLabels(labelRules(p.GetLabelsToSync()), node.GetLabels())
 
+	labels[constants.LabelVirtualNode] = "true"
 	if featuregate.DefaultFeatureGate.Enabled(featuregate.SuperClusterPooling) {
 		labels[constants.LabelSuperClusterID] = utilconstants.SuperClusterID
 	}
-
-	labelsToSync := p.GetLabelsToSync()
-	for k, v := range node.GetLabels() {
-		if _, found := labelsToSync[k]; found {
-			labels[k] = v
-		}
-	}
 	return labels
 }
 
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package drain tracks the requests being reconciled by the syncer controllers, so that the syncer
+// can let them finish instead of leaving half-synced objects when it stops.
+package drain
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTracker is the tracker of the downward and upward syncing controllers.
+var DefaultTracker = NewTracker()
+
+// Tracker counts the requests in flight and stops accepting new ones once draining.
+type Tracker struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight int
+	// idle is closed once draining with no request in flight.
+	idle chan struct{}
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{idle: make(chan struct{})}
+}
+
+// Start marks a request in flight. It returns false once draining, the request must then be left
+// as is and Done must not be called.
+func (t *Tracker) Start() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.inFlight++
+	return true
+}
+
+// Done marks a request started by Start finished.
+func (t *Tracker) Done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight--
+	if t.draining && t.inFlight == 0 {
+		close(t.idle)
+	}
+}
+
+// InFlight returns the number of requests in flight.
+func (t *Tracker) InFlight() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.inFlight
+}
+
+// Drain stops accepting new requests and waits for the requests in flight to finish, at most for
+// the timeout. It returns whether they all finished. Draining cannot be undone, so Drain may be
+// called again, e.g. by several shutdown paths, but requests are never accepted again.
+func (t *Tracker) Drain(timeout time.Duration) bool {
+	t.mu.Lock()
+	if !t.draining {
+		t.draining = true
+		if t.inFlight == 0 {
+			close(t.idle)
+		}
+	}
+	t.mu.Unlock()
+
+	if timeout <= 0 {
+		select {
+		case <-t.idle:
+			return true
+		default:
+			return false
+		}
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-t.idle:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDrainWaitsForRequestsInFlight(t *testing.T) {
+	tracker := NewTracker()
+	for i := 0; i < 2; i++ {
+		if !tracker.Start() {
+			t.Fatalf("expected the request to be accepted before draining")
+		}
+	}
+
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		tracker.Done()
+		time.Sleep(100 * time.Millisecond)
+		close(released)
+		tracker.Done()
+	}()
+
+	start := time.Now()
+	if !tracker.Drain(10 * time.Second) {
+		t.Fatalf("expected the requests to be drained")
+	}
+	select {
+	case <-released:
+	default:
+		t.Errorf("expected the drain to block until the last request is done, returned after %v", time.Since(start))
+	}
+	if got := tracker.InFlight(); got != 0 {
+		t.Errorf("expected no request in flight, got %d", got)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	tracker := NewTracker()
+	if !tracker.Start() {
+		t.Fatalf("expected the request to be accepted before draining")
+	}
+
+	start := time.Now()
+	if tracker.Drain(100 * time.Millisecond) {
+		t.Fatalf("expected the drain to time out with a stuck request")
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected the drain to wait for the timeout, returned after %v", elapsed)
+	}
+	if got := tracker.InFlight(); got != 1 {
+		t.Errorf("expected the stuck request in flight, got %d", got)
+	}
+
+	// the stuck request finishing late is not an error.
+	tracker.Done()
+	if !tracker.Drain(0) {
+		t.Errorf("expected the requests to be drained once the stuck request is done")
+	}
+}
+
+func TestDrainStopsAcceptingRequests(t *testing.T) {
+	tracker := NewTracker()
+	if !tracker.Drain(0) {
+		t.Fatalf("expected a tracker without requests to be drained right away")
+	}
+	if tracker.Start() {
+		t.Errorf("expected the request to be refused while draining")
+	}
+	if got := tracker.InFlight(); got != 0 {
+		t.Errorf("expected no request in flight, got %d", got)
+	}
+	if !tracker.Drain(time.Second) {
+		t.Errorf("expected draining again to succeed")
+	}
+}
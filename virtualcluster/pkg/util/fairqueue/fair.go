@@ -122,6 +122,44 @@ func (q *fairQueue) Add(obj interface{}) {
 	q.cond.Signal()
 }
 
+// AddPriority adds an item like Add, but at the head of the queue of its group, moving it there if
+// it is queued already, so that it is the next item of its group to be processed. The groups are
+// still served in turn.
+func (q *fairQueue) AddPriority(obj interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	item, ok := obj.(Item)
+	if !ok {
+		return
+	}
+
+	if q.processing.has(item) {
+		// Done queues it again once processed.
+		q.dirty.insert(item)
+		return
+	}
+
+	group := item.GroupName()
+	fifo, exists := q.queueGroup[group]
+	if !exists {
+		fifo = NewFifoQueue()
+		q.queueGroup[group] = fifo
+		q.balancer.Add(group, 1)
+	}
+
+	if q.dirty.has(item) && fifo.Remove(item) {
+		q.length--
+	}
+	q.dirty.insert(item)
+	fifo.AddFront(item)
+	q.length++
+
+	q.cond.Signal()
+}
+
 func (q *fairQueue) Len() int {
 	q.cond.L.Lock()
 	defer q.cond.L.Unlock()
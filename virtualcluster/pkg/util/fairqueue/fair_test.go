@@ -128,6 +128,41 @@ func TestLen(t *testing.T) {
 	}
 }
 
+func TestAddPriority(t *testing.T) {
+	q := NewRateLimitingFairQueue().(*fairQueue)
+	first, second, third := groupItemWrapper("foo"), groupItemWrapper("foo"), groupItemWrapper("foo")
+	first.Name, second.Name, third.Name = "first", "second", "third"
+
+	q.Add(first)
+	q.Add(second)
+	q.AddPriority(third)
+	// moves the queued item to the head without increasing the queue length.
+	q.AddPriority(second)
+	if e, a := 3, q.Len(); e != a {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+
+	for _, expected := range []string{"second", "third", "first"} {
+		i, _ := q.Get()
+		if v := i.(*reconciler.Request); v.Name != expected {
+			t.Errorf("Expected %v, got %v", expected, v.Name)
+		}
+		q.Done(i)
+	}
+
+	// an item being processed is queued again once done.
+	q.Add(first)
+	i, _ := q.Get()
+	q.AddPriority(i)
+	if e, a := 0, q.Len(); e != a {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+	q.Done(i)
+	if e, a := 1, q.Len(); e != a {
+		t.Errorf("Expected %v, got %v", e, a)
+	}
+}
+
 func TestReinsert(t *testing.T) {
 	q := NewRateLimitingFairQueue()
 	foo := groupItemWrapper("foo")
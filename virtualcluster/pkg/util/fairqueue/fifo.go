@@ -58,6 +58,23 @@ func (q *FifoQueue) Add(item interface{}) {
 	q.lastActiveTime = time.Now()
 }
 
+// AddFront adds an item at the head of the queue.
+func (q *FifoQueue) AddFront(item interface{}) {
+	q.queue = append([]t{item}, q.queue...)
+	q.lastActiveTime = time.Now()
+}
+
+// Remove removes an item from the queue, and indicates whether it was queued.
+func (q *FifoQueue) Remove(item interface{}) bool {
+	for i := range q.queue {
+		if q.queue[i] == item {
+			q.queue = append(q.queue[:i], q.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
 // Get pop the queue head. indicate whether the queue is empty.
 func (q *FifoQueue) Get() (item interface{}, empty bool) {
 	if len(q.queue) == 0 {
@@ -26,9 +26,18 @@ type EnqueueRequestForObject struct {
 	ClusterName string
 	Queue       Queue
 	AttachUID   bool
+	// Prioritize, if set, tells the updates to add ahead of the queued requests, if the Queue is a
+	// PriorityQueue.
+	Prioritize func(oldObj, newObj interface{}) bool
+	// Observe, if set, is called with each request before it is added to the Queue.
+	Observe func(r reconciler.Request)
 }
 
 func (e *EnqueueRequestForObject) enqueue(obj interface{}) {
+	e.add(obj, false)
+}
+
+func (e *EnqueueRequestForObject) add(obj interface{}, priority bool) {
 	o, err := meta.Accessor(obj)
 	if err != nil {
 		return
@@ -42,6 +51,13 @@ func (e *EnqueueRequestForObject) enqueue(obj interface{}) {
 		r.UID = string(o.GetUID())
 	}
 
+	if e.Observe != nil {
+		e.Observe(r)
+	}
+	if q, ok := e.Queue.(PriorityQueue); ok && priority {
+		q.AddPriority(r)
+		return
+	}
 	e.Queue.Add(r)
 }
 
@@ -50,7 +66,7 @@ func (e *EnqueueRequestForObject) OnAdd(obj interface{}) {
 }
 
 func (e *EnqueueRequestForObject) OnUpdate(oldObj, newObj interface{}) {
-	e.enqueue(newObj)
+	e.add(newObj, e.Prioritize != nil && e.Prioritize(oldObj, newObj))
 }
 
 func (e *EnqueueRequestForObject) OnDelete(obj interface{}) {
@@ -116,3 +116,39 @@ func TestEnqueueRequestForObject(t *testing.T) {
 		t.Errorf("expected enqueue %v, got %v", expectedEnqueuedRequest, obj)
 	}
 }
+
+type priorityQueue struct {
+	fifoQueue
+}
+
+func (q *priorityQueue) AddPriority(item interface{}) {
+	q.queue = append([]interface{}{item}, q.queue...)
+}
+
+func TestEnqueueRequestForObjectPrioritize(t *testing.T) {
+	internalQueue := &priorityQueue{}
+	queue := &EnqueueRequestForObject{
+		ClusterName: "test-cluster",
+		Queue:       internalQueue,
+		Prioritize: func(oldObj, newObj interface{}) bool {
+			return newObj.(*corev1.Pod).Name == "urgent"
+		},
+	}
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+	}
+
+	queue.OnUpdate(pod("n1"), pod("n1"))
+	queue.OnUpdate(pod("urgent"), pod("urgent"))
+	// adds and deletes are never prioritized.
+	queue.OnAdd(pod("urgent"))
+	for _, expected := range []string{"urgent", "n1", "urgent"} {
+		obj, err := internalQueue.Get()
+		if err != nil {
+			t.Fatalf("expected %s, got empty queue", expected)
+		}
+		if name := obj.(reconciler.Request).Name; name != expected {
+			t.Errorf("expected %s to be dequeued, got %s", expected, name)
+		}
+	}
+}
@@ -19,3 +19,9 @@ package handler
 type Queue interface {
 	Add(item interface{})
 }
+
+// PriorityQueue is a Queue that can add an item ahead of the queued ones.
+type PriorityQueue interface {
+	Queue
+	AddPriority(item interface{})
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logsampling samples repetitive info logs, e.g. the per-request logs of the syncer, so
+// that a high churn does not overwhelm the logging backend. Errors are never sampled.
+package logsampling
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// Sampler lets the first of every N info logs of each key through.
+type Sampler struct {
+	lock   sync.Mutex
+	every  uint64
+	counts map[string]uint64
+
+	// infoDepth and errorDepth write the logs, they are replaced in tests.
+	infoDepth  func(depth int, args ...interface{})
+	errorDepth func(depth int, args ...interface{})
+}
+
+// NewSampler returns a sampler letting one in every n info logs of each key through. An n of 0 or
+// 1 disables sampling.
+func NewSampler(n int) *Sampler {
+	s := &Sampler{
+		counts:     make(map[string]uint64),
+		infoDepth:  klog.InfoDepth,
+		errorDepth: klog.ErrorDepth,
+	}
+	s.SetRate(n)
+	return s
+}
+
+// SetRate lets one in every n info logs of each key through. An n of 0 or 1 disables sampling.
+func (s *Sampler) SetRate(n int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.every = 1
+	if n > 1 {
+		s.every = uint64(n)
+	}
+	s.counts = make(map[string]uint64)
+}
+
+// sample returns true if the next info log of the key is to be written.
+func (s *Sampler) sample(key string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.every == 1 {
+		return true
+	}
+	count := s.counts[key]
+	s.counts[key] = count + 1
+	return count%s.every == 0
+}
+
+// Verbose is a sampled klog.Verbose.
+type Verbose struct {
+	sampler *Sampler
+	enabled bool
+	key     string
+}
+
+// V returns a Verbose writing the info logs of the key at the level, sampled.
+func (s *Sampler) V(level klog.Level, key string) Verbose {
+	return Verbose{sampler: s, enabled: klog.V(level).Enabled(), key: key}
+}
+
+// Infof writes the log if the level is enabled and the log is sampled.
+func (v Verbose) Infof(format string, args ...interface{}) {
+	if v.enabled && v.sampler.sample(v.key) {
+		v.sampler.infoDepth(1, fmt.Sprintf(format, args...))
+	}
+}
+
+// Errorf always writes the error log.
+func (s *Sampler) Errorf(format string, args ...interface{}) {
+	s.errorDepth(1, fmt.Sprintf(format, args...))
+}
+
+// DefaultSampler is the sampler of the syncer, configured by --log-sampling.
+var DefaultSampler = NewSampler(1)
+
+// SetRate lets one in every n info logs of each key of the DefaultSampler through.
+func SetRate(n int) {
+	DefaultSampler.SetRate(n)
+}
+
+// V returns a Verbose of the DefaultSampler.
+func V(level klog.Level, key string) Verbose {
+	return DefaultSampler.V(level, key)
+}
+
+// Errorf always writes the error log.
+func Errorf(format string, args ...interface{}) {
+	DefaultSampler.errorDepth(1, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logsampling
+
+import (
+	"testing"
+)
+
+func TestSampler(t *testing.T) {
+	for _, tc := range []struct {
+		name           string
+		rate           int
+		expectedInfos  int
+		expectedErrors int
+	}{
+		{name: "disabled", rate: 0, expectedInfos: 40, expectedErrors: 40},
+		{name: "one in one", rate: 1, expectedInfos: 40, expectedErrors: 40},
+		{name: "one in ten", rate: 10, expectedInfos: 4, expectedErrors: 40},
+		{name: "one in a hundred", rate: 100, expectedInfos: 2, expectedErrors: 40},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewSampler(tc.rate)
+			var infos, errors int
+			s.infoDepth = func(int, ...interface{}) { infos++ }
+			s.errorDepth = func(int, ...interface{}) { errors++ }
+
+			for i := 0; i < 20; i++ {
+				for _, key := range []string{"pod", "service"} {
+					s.V(0, key).Infof("reconcile %s %d", key, i)
+					s.Errorf("failed to reconcile %s %d", key, i)
+				}
+			}
+			if infos != tc.expectedInfos {
+				t.Errorf("expected %d info logs, got %d", tc.expectedInfos, infos)
+			}
+			if errors != tc.expectedErrors {
+				t.Errorf("expected %d error logs, got %d", tc.expectedErrors, errors)
+			}
+		})
+	}
+}
+
+func TestSamplerDisabledLevel(t *testing.T) {
+	s := NewSampler(1)
+	var infos int
+	s.infoDepth = func(int, ...interface{}) { infos++ }
+	s.V(10, "pod").Infof("reconcile pod")
+	if infos != 0 {
+		t.Errorf("expected no info log above the verbosity, got %d", infos)
+	}
+}
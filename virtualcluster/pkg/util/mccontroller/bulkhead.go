@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// acquireClusterWorker takes a worker of the request's cluster from the ClusterWorkerLimiter. It
+// returns false if the cluster already has clusterWorkerLimit workers of this controller, or the
+// limit of the ClusterWorkerLimiter shared with the other controllers, the request is then put back
+// in the queue once a worker of the cluster is released.
+func (c *MultiClusterController) acquireClusterWorker(req reconciler.Request) bool {
+	return c.ClusterWorkerLimiter.acquire(c, req, c.clusterWorkerLimit(req.ClusterName))
+}
+
+// releaseClusterWorker gives back a worker taken by acquireClusterWorker.
+func (c *MultiClusterController) releaseClusterWorker(clusterName string) {
+	c.ClusterWorkerLimiter.release(c, clusterName)
+}
+
+// clusterWorkerLimit returns the number of workers of this controller that may reconcile requests
+// of the cluster, MaxConcurrentReconcilesPerCluster or the onboarding limit while the cluster is
+// onboarding, whichever is lower. 0 means no limit.
+func (c *MultiClusterController) clusterWorkerLimit(clusterName string) int {
+	limit := c.MaxConcurrentReconcilesPerCluster
+	if onboardingLimit := c.onboardingWorkerLimit(clusterName); onboardingLimit > 0 && (limit <= 0 || onboardingLimit < limit) {
+		limit = onboardingLimit
+	}
+	return limit
+}
+
+// ActiveWorkers returns the number of workers of this controller reconciling requests of the cluster.
+func (c *MultiClusterController) ActiveWorkers(clusterName string) int {
+	l := c.ClusterWorkerLimiter
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.active[c][clusterName]
+}
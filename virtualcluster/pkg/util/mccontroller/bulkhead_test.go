@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// blockingReconciler blocks the requests of the hot cluster until released and
+// records the number of requests reconciled in parallel per cluster.
+type blockingReconciler struct {
+	sync.Mutex
+	hot      string
+	release  chan struct{}
+	running  map[string]int
+	maxSeen  map[string]int
+	finished map[string]int
+}
+
+func (r *blockingReconciler) Reconcile(req reconciler.Request) (reconciler.Result, error) {
+	r.Lock()
+	r.running[req.ClusterName]++
+	if r.running[req.ClusterName] > r.maxSeen[req.ClusterName] {
+		r.maxSeen[req.ClusterName] = r.running[req.ClusterName]
+	}
+	r.Unlock()
+
+	if req.ClusterName == r.hot {
+		<-r.release
+	}
+
+	r.Lock()
+	r.running[req.ClusterName]--
+	r.finished[req.ClusterName]++
+	r.Unlock()
+	return reconciler.Result{}, nil
+}
+
+func (r *blockingReconciler) get(m map[string]int, cluster string) int {
+	r.Lock()
+	defer r.Unlock()
+	return m[cluster]
+}
+
+func TestMaxConcurrentReconcilesPerCluster(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		workers    int
+		perCluster int
+		expectMax  int
+	}{
+		{
+			name:       "no per cluster limit",
+			workers:    3,
+			perCluster: 0,
+			expectMax:  3,
+		},
+		{
+			name:       "per cluster limit",
+			workers:    3,
+			perCluster: 1,
+			expectMax:  1,
+		},
+		{
+			name:       "per cluster limit above workers",
+			workers:    2,
+			perCluster: 5,
+			expectMax:  2,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			rc := &blockingReconciler{
+				hot:      "hot",
+				release:  make(chan struct{}),
+				running:  map[string]int{},
+				maxSeen:  map[string]int{},
+				finished: map[string]int{},
+			}
+			c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc,
+				WithMaxConcurrentReconciles(tt.workers), WithMaxConcurrentReconcilesPerCluster(tt.perCluster))
+			if err != nil {
+				tc.Fatalf("unexpected error: %v", err)
+			}
+			c.clusters["hot"] = &fakeCluster{}
+			c.clusters["cold"] = &fakeCluster{}
+
+			for i := 0; i < 10; i++ {
+				c.Queue.Add(reconciler.Request{ClusterName: "hot", NamespacedName: types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("hot-%d", i)}})
+			}
+			for i := 0; i < 2; i++ {
+				c.Queue.Add(reconciler.Request{ClusterName: "cold", NamespacedName: types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("cold-%d", i)}})
+			}
+
+			stop := make(chan struct{})
+			defer close(stop)
+			go c.Start(stop)
+
+			if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+				return rc.get(rc.running, "hot") == tt.expectMax, nil
+			}); err != nil {
+				tc.Fatalf("expected %d workers on the hot cluster, got %d", tt.expectMax, rc.get(rc.running, "hot"))
+			}
+			if tt.perCluster > 0 && tt.perCluster < tt.workers {
+				// the hot cluster is capped, the spare workers serve the other cluster.
+				if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+					return rc.get(rc.finished, "cold") == 2, nil
+				}); err != nil {
+					tc.Fatalf("expected the cold cluster not to be starved, %d requests reconciled", rc.get(rc.finished, "cold"))
+				}
+			}
+			if active := c.ActiveWorkers("hot"); active != tt.expectMax {
+				tc.Errorf("expected %d active workers for the hot cluster, got %d", tt.expectMax, active)
+			}
+
+			close(rc.release)
+			if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+				return rc.get(rc.finished, "hot") == 10 && rc.get(rc.finished, "cold") == 2, nil
+			}); err != nil {
+				tc.Fatalf("expected all requests to be reconciled, hot %d, cold %d", rc.get(rc.finished, "hot"), rc.get(rc.finished, "cold"))
+			}
+			if maxSeen := rc.get(rc.maxSeen, "hot"); maxSeen != tt.expectMax {
+				tc.Errorf("expected at most %d parallel reconciles of the hot cluster, got %d", tt.expectMax, maxSeen)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"sync"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// ClusterWorkerLimiter caps the number of workers that reconcile requests of the same cluster at the
+// same time, both per controller, with the MaxConcurrentReconcilesPerCluster and the onboarding
+// limits of the controller, and across all the controllers sharing it, so that one busy tenant cannot
+// take the workers of every controller. A request refused a worker waits in the limiter until a
+// worker of its cluster is released, which hands it over to the request and puts the request back
+// in the queue of its controller.
+type ClusterWorkerLimiter struct {
+	lock  sync.Mutex
+	limit int
+	// inFlight is the number of workers of all the controllers reconciling requests per cluster.
+	inFlight map[string]int
+	// active is the number of workers of each controller reconciling requests per cluster.
+	active map[*MultiClusterController]map[string]int
+	// waiting are the requests refused a worker per cluster, in arrival order.
+	waiting    map[string][]waitingRequest
+	waitingSet map[waitingRequest]struct{}
+	// handed are the requests handed over a released worker, until a worker of their controller
+	// picks them from the queue.
+	handed map[waitingRequest]struct{}
+}
+
+// waitingRequest is a request of a controller waiting for a worker.
+type waitingRequest struct {
+	c   *MultiClusterController
+	req reconciler.Request
+}
+
+// DefaultClusterWorkerLimiter is shared by the controllers not given another limiter. It has no
+// limit across the controllers until SetLimit is called.
+var DefaultClusterWorkerLimiter = NewClusterWorkerLimiter(0)
+
+// NewClusterWorkerLimiter creates a limiter of limit workers per cluster across the controllers
+// sharing it. 0 means no limit.
+func NewClusterWorkerLimiter(limit int) *ClusterWorkerLimiter {
+	return &ClusterWorkerLimiter{
+		limit:      limit,
+		inFlight:   make(map[string]int),
+		active:     make(map[*MultiClusterController]map[string]int),
+		waiting:    make(map[string][]waitingRequest),
+		waitingSet: make(map[waitingRequest]struct{}),
+		handed:     make(map[waitingRequest]struct{}),
+	}
+}
+
+// SetLimit changes the number of workers per cluster across the controllers. The workers already
+// in flight are not interrupted. 0 means no limit.
+func (l *ClusterWorkerLimiter) SetLimit(limit int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.limit = limit
+}
+
+// acquire takes a worker of the request's cluster for the controller, which may use up to
+// controllerLimit workers of the cluster, 0 meaning no limit. A request handed over a released
+// worker already has one. It returns false if the cluster has no worker left, the request then
+// waits until a worker of the cluster is released.
+func (l *ClusterWorkerLimiter) acquire(c *MultiClusterController, req reconciler.Request, controllerLimit int) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	w := waitingRequest{c: c, req: req}
+	if _, ok := l.handed[w]; ok {
+		delete(l.handed, w)
+		return true
+	}
+	if !l.available(c, req.ClusterName, controllerLimit) {
+		if _, ok := l.waitingSet[w]; !ok {
+			l.waitingSet[w] = struct{}{}
+			l.waiting[req.ClusterName] = append(l.waiting[req.ClusterName], w)
+		}
+		return false
+	}
+	l.take(c, req.ClusterName)
+	return true
+}
+
+// release gives back a worker taken by acquire, and hands the workers left over to the requests
+// waiting for them.
+func (l *ClusterWorkerLimiter) release(c *MultiClusterController, clusterName string) {
+	l.lock.Lock()
+	inFlight := l.inFlight[clusterName] - 1
+	if inFlight <= 0 {
+		delete(l.inFlight, clusterName)
+		inFlight = 0
+	}
+	metrics.RecordClusterInFlightWorkers(clusterName, inFlight)
+	active := l.active[c][clusterName] - 1
+	if active <= 0 {
+		delete(l.active[c], clusterName)
+		active = 0
+	} else {
+		l.active[c][clusterName] = active
+	}
+	metrics.RecordDWSActiveWorkers(c.objectKind, clusterName, active)
+	handed := l.handOver(clusterName)
+	l.lock.Unlock()
+
+	for _, w := range handed {
+		w.c.Queue.Add(w.req)
+	}
+}
+
+// handOver takes the available workers of the cluster for the requests waiting for them, in arrival
+// order, and returns the requests to put back in the queue of their controller.
+func (l *ClusterWorkerLimiter) handOver(clusterName string) []waitingRequest {
+	var handed, waiting []waitingRequest
+	for _, w := range l.waiting[clusterName] {
+		if !l.available(w.c, clusterName, w.c.clusterWorkerLimit(clusterName)) {
+			waiting = append(waiting, w)
+			continue
+		}
+		l.take(w.c, clusterName)
+		delete(l.waitingSet, w)
+		l.handed[w] = struct{}{}
+		handed = append(handed, w)
+	}
+	if len(waiting) == 0 {
+		delete(l.waiting, clusterName)
+	} else {
+		l.waiting[clusterName] = waiting
+	}
+	return handed
+}
+
+// forget drops the waiting requests of a cluster removed from the controller and gives back the
+// workers handed over to them.
+func (l *ClusterWorkerLimiter) forget(c *MultiClusterController, clusterName string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var waiting []waitingRequest
+	for _, w := range l.waiting[clusterName] {
+		if w.c == c {
+			delete(l.waitingSet, w)
+			continue
+		}
+		waiting = append(waiting, w)
+	}
+	if len(waiting) == 0 {
+		delete(l.waiting, clusterName)
+	} else {
+		l.waiting[clusterName] = waiting
+	}
+	for w := range l.handed {
+		if w.c != c || w.req.ClusterName != clusterName {
+			continue
+		}
+		delete(l.handed, w)
+		if l.inFlight[clusterName]--; l.inFlight[clusterName] <= 0 {
+			delete(l.inFlight, clusterName)
+		}
+		if l.active[c][clusterName]--; l.active[c][clusterName] <= 0 {
+			delete(l.active[c], clusterName)
+		}
+	}
+}
+
+// available returns whether the controller may take another worker of the cluster.
+func (l *ClusterWorkerLimiter) available(c *MultiClusterController, clusterName string, controllerLimit int) bool {
+	if controllerLimit > 0 && l.active[c][clusterName] >= controllerLimit {
+		return false
+	}
+	return l.limit <= 0 || l.inFlight[clusterName] < l.limit
+}
+
+// take counts a worker of the cluster taken by the controller.
+func (l *ClusterWorkerLimiter) take(c *MultiClusterController, clusterName string) {
+	l.inFlight[clusterName]++
+	metrics.RecordClusterInFlightWorkers(clusterName, l.inFlight[clusterName])
+	if l.active[c] == nil {
+		l.active[c] = make(map[string]int)
+	}
+	l.active[c][clusterName]++
+	metrics.RecordDWSActiveWorkers(c.objectKind, clusterName, l.active[c][clusterName])
+}
+
+// InFlight returns the number of workers of all the controllers reconciling requests of the cluster.
+func (l *ClusterWorkerLimiter) InFlight(clusterName string) int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.inFlight[clusterName]
+}
+
+// Waiting returns the number of requests of the cluster waiting for a worker.
+func (l *ClusterWorkerLimiter) Waiting(clusterName string) int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.waiting[clusterName])
+}
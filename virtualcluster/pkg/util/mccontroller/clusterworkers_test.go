@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+func TestClusterWorkerLimiter(t *testing.T) {
+	l := NewClusterWorkerLimiter(2)
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, &blockingReconciler{}, WithClusterWorkerLimiter(l))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request := func(cluster, name string) reconciler.Request {
+		return reconciler.Request{ClusterName: cluster, NamespacedName: types.NamespacedName{Namespace: "default", Name: name}}
+	}
+
+	if !c.acquireClusterWorker(request("a", "1")) || !c.acquireClusterWorker(request("a", "2")) {
+		t.Fatalf("expected two workers for cluster a")
+	}
+	if c.acquireClusterWorker(request("a", "3")) || c.acquireClusterWorker(request("a", "3")) {
+		t.Errorf("expected no third worker for cluster a")
+	}
+	if got := l.Waiting("a"); got != 1 {
+		t.Errorf("expected 1 request waiting for cluster a, got %d", got)
+	}
+	if !c.acquireClusterWorker(request("b", "1")) {
+		t.Errorf("expected a worker for cluster b")
+	}
+
+	// the released worker is handed over to the waiting request and the request is put back in the queue.
+	c.releaseClusterWorker("a")
+	if got := l.Waiting("a"); got != 0 {
+		t.Errorf("expected no request waiting for cluster a, got %d", got)
+	}
+	if got := c.Queue.Len(); got != 1 {
+		t.Fatalf("expected the waiting request to be queued, got %d requests queued", got)
+	}
+	if got := l.InFlight("a"); got != 2 {
+		t.Errorf("expected 2 workers in flight for cluster a, got %d", got)
+	}
+	if !c.acquireClusterWorker(request("a", "3")) {
+		t.Errorf("expected the handed over worker to be acquired")
+	}
+	if got := l.InFlight("a"); got != 2 {
+		t.Errorf("expected 2 workers in flight for cluster a, got %d", got)
+	}
+
+	// the requests of a removed cluster do not wait for the workers anymore.
+	if c.acquireClusterWorker(request("a", "4")) {
+		t.Errorf("expected no third worker for cluster a")
+	}
+	l.forget(c, "a")
+	if got := l.Waiting("a"); got != 0 {
+		t.Errorf("expected no request waiting for cluster a, got %d", got)
+	}
+
+	l.SetLimit(0)
+	for i := 0; i < 10; i++ {
+		if !c.acquireClusterWorker(request("a", fmt.Sprintf("%d", i+5))) {
+			t.Fatalf("expected no limit")
+		}
+	}
+	if got := l.InFlight("a"); got != 12 {
+		t.Errorf("expected 12 workers in flight for cluster a, got %d", got)
+	}
+	if got := c.ActiveWorkers("a"); got != 12 {
+		t.Errorf("expected 12 active workers for cluster a, got %d", got)
+	}
+}
+
+func TestClusterWorkerLimiterSharedByControllers(t *testing.T) {
+	rc := &blockingReconciler{
+		hot:      "hot",
+		release:  make(chan struct{}),
+		running:  map[string]int{},
+		maxSeen:  map[string]int{},
+		finished: map[string]int{},
+	}
+	limiter := NewClusterWorkerLimiter(2)
+	configMaps, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc, WithMaxConcurrentReconciles(3), WithClusterWorkerLimiter(limiter))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secrets, err := NewMCController(&corev1.Secret{}, &corev1.SecretList{}, rc, WithMaxConcurrentReconciles(3), WithClusterWorkerLimiter(limiter))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	controllers := []*MultiClusterController{configMaps, secrets}
+	for _, c := range controllers {
+		c.clusters["hot"] = &fakeCluster{}
+		c.clusters["cold"] = &fakeCluster{}
+		for i := 0; i < 5; i++ {
+			c.Queue.Add(reconciler.Request{ClusterName: "hot", NamespacedName: types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("hot-%d", i)}})
+		}
+		c.Queue.Add(reconciler.Request{ClusterName: "cold", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cold"}})
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	for _, c := range controllers {
+		go c.Start(stop)
+	}
+
+	// the hot cluster gets 2 of the 6 workers of both controllers, the other workers serve the cold cluster.
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return rc.get(rc.running, "hot") == 2 && rc.get(rc.finished, "cold") == 2, nil
+	}); err != nil {
+		t.Fatalf("expected 2 workers on the hot cluster and the cold cluster not to be starved, got %d and %d requests reconciled", rc.get(rc.running, "hot"), rc.get(rc.finished, "cold"))
+	}
+	if inFlight := limiter.InFlight("hot"); inFlight != 2 {
+		t.Errorf("expected 2 workers in flight for the hot cluster, got %d", inFlight)
+	}
+
+	close(rc.release)
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return rc.get(rc.finished, "hot") == 10, nil
+	}); err != nil {
+		t.Fatalf("expected all requests to be reconciled, hot %d", rc.get(rc.finished, "hot"))
+	}
+	if maxSeen := rc.get(rc.maxSeen, "hot"); maxSeen != 2 {
+		t.Errorf("expected at most 2 parallel reconciles of the hot cluster, got %d", maxSeen)
+	}
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return limiter.InFlight("hot") == 0, nil
+	}); err != nil {
+		t.Errorf("expected no worker in flight for the hot cluster, got %d", limiter.InFlight("hot"))
+	}
+}
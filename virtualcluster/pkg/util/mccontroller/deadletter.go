@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	utilconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// DeadLetter is a tenant object whose sync kept failing and has been taken out of the retry loop.
+type DeadLetter struct {
+	Cluster   string    `json:"cluster"`
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	UID       string    `json:"uid,omitempty"`
+	Error     string    `json:"error"`
+	Since     time.Time `json:"since"`
+
+	// resourceVersion is the version of the tenant object that failed to sync.
+	resourceVersion string
+}
+
+// deadLetterThreshold returns the number of retries after which a failing request is dead-lettered.
+func (c *MultiClusterController) deadLetterThreshold() int {
+	if c.DeadLetterRetryThreshold > 0 {
+		return c.DeadLetterRetryThreshold
+	}
+	if c.MaxRetries > 0 {
+		return c.MaxRetries
+	}
+	return utilconstants.MaxReconcileRetryAttempts
+}
+
+// tenantResourceVersion returns the resource version of the tenant object of the request, or an
+// empty string if it cannot be read.
+func (c *MultiClusterController) tenantResourceVersion(req reconciler.Request) string {
+	obj := c.objectType.DeepCopyObject().(client.Object)
+	if err := c.Get(req.ClusterName, req.Namespace, req.Name, obj); err != nil {
+		return ""
+	}
+	return obj.GetResourceVersion()
+}
+
+// addDeadLetter takes the failing request out of the retry loop. The tenant is told with an event
+// the first time. If DeadLetterRetryPeriod is set, the request is retried once after the period.
+func (c *MultiClusterController) addDeadLetter(req reconciler.Request, err error) {
+	key := types.NamespacedName{Namespace: req.Namespace, Name: req.Name}
+	c.deadLettersLock.Lock()
+	objects := c.deadLetters[req.ClusterName]
+	if objects == nil {
+		objects = make(map[types.NamespacedName]*DeadLetter)
+		c.deadLetters[req.ClusterName] = objects
+	}
+	dl, exists := objects[key]
+	if !exists {
+		dl = &DeadLetter{
+			Cluster:   req.ClusterName,
+			Kind:      c.objectKind,
+			Namespace: req.Namespace,
+			Name:      req.Name,
+			UID:       req.UID,
+			Since:     time.Now(),
+		}
+		objects[key] = dl
+	}
+	dl.Error = err.Error()
+	dl.resourceVersion = c.tenantResourceVersion(req)
+	count := len(objects)
+	c.deadLettersLock.Unlock()
+
+	if !exists {
+		metrics.RecordDeadLetters(c.objectKind, req.ClusterName, count)
+		klog.Warningf("%s %s/%s of cluster %s keeps failing to sync, taking it out of the retry loop: %v", c.objectKind, req.Namespace, req.Name, req.ClusterName, err)
+		if eventErr := c.Eventf(req.ClusterName, &corev1.ObjectReference{
+			Kind:      c.objectKind,
+			Namespace: req.Namespace,
+			Name:      req.Name,
+			UID:       types.UID(req.UID),
+		}, corev1.EventTypeWarning, "SyncFailed", "The object keeps failing to sync to the super cluster: %v", err); eventErr != nil {
+			klog.Warningf("failed to send sync failure event for %s %s/%s of cluster %s: %v", c.objectKind, req.Namespace, req.Name, req.ClusterName, eventErr)
+		}
+	}
+
+	if c.DeadLetterRetryPeriod > 0 {
+		c.Queue.AddAfter(req, c.DeadLetterRetryPeriod)
+	}
+}
+
+// isDeadLetter returns true if the request has been dead-lettered and its tenant object has not
+// changed since. A changed or deleted object leaves the dead-letter set, so that its sync is retried
+// as usual.
+func (c *MultiClusterController) isDeadLetter(req reconciler.Request) bool {
+	c.deadLettersLock.Lock()
+	dl, ok := c.deadLetters[req.ClusterName][types.NamespacedName{Namespace: req.Namespace, Name: req.Name}]
+	var resourceVersion string
+	if ok {
+		resourceVersion = dl.resourceVersion
+	}
+	c.deadLettersLock.Unlock()
+	if !ok {
+		return false
+	}
+
+	if c.tenantResourceVersion(req) == resourceVersion {
+		return true
+	}
+	c.removeDeadLetter(req)
+	return false
+}
+
+// removeDeadLetter puts the request back in the retry loop, e.g. once it has been synced.
+func (c *MultiClusterController) removeDeadLetter(req reconciler.Request) {
+	key := types.NamespacedName{Namespace: req.Namespace, Name: req.Name}
+	c.deadLettersLock.Lock()
+	defer c.deadLettersLock.Unlock()
+	objects := c.deadLetters[req.ClusterName]
+	if _, ok := objects[key]; !ok {
+		return
+	}
+	delete(objects, key)
+	klog.Infof("%s %s/%s of cluster %s leaves the dead-letter set", c.objectKind, req.Namespace, req.Name, req.ClusterName)
+	metrics.RecordDeadLetters(c.objectKind, req.ClusterName, len(objects))
+}
+
+// forgetDeadLetters drops the dead-lettered objects of a removed cluster.
+func (c *MultiClusterController) forgetDeadLetters(clusterName string) {
+	c.deadLettersLock.Lock()
+	defer c.deadLettersLock.Unlock()
+	delete(c.deadLetters, clusterName)
+	metrics.DeleteDeadLetters(c.objectKind, clusterName)
+}
+
+// DeadLetters returns the tenant objects whose sync has been taken out of the retry loop, sorted by
+// cluster, namespace and name.
+func (c *MultiClusterController) DeadLetters() []DeadLetter {
+	c.deadLettersLock.Lock()
+	var dls []DeadLetter
+	for _, objects := range c.deadLetters {
+		for _, dl := range objects {
+			dls = append(dls, *dl)
+		}
+	}
+	c.deadLettersLock.Unlock()
+
+	sort.Slice(dls, func(i, j int) bool {
+		if dls[i].Cluster != dls[j].Cluster {
+			return dls[i].Cluster < dls[j].Cluster
+		}
+		if dls[i].Namespace != dls[j].Namespace {
+			return dls[i].Namespace < dls[j].Namespace
+		}
+		return dls[i].Name < dls[j].Name
+	})
+	return dls
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+type failingReconciler struct {
+	err      error
+	attempts int
+}
+
+func (r *failingReconciler) Reconcile(reconciler.Request) (reconciler.Result, error) {
+	r.attempts++
+	return reconciler.Result{}, r.err
+}
+
+type fakeTenantCluster struct {
+	ClusterInterface
+	client    client.Client
+	clientset clientset.Interface
+}
+
+func (f *fakeTenantCluster) GetDelegatingClient() (client.Client, error) {
+	return f.client, nil
+}
+
+func (f *fakeTenantCluster) GetClientSet() (clientset.Interface, error) {
+	return f.clientset, nil
+}
+
+func TestDeadLetter(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm", UID: "cm-uid"}}
+	tenantClient := fakeClient.NewClientBuilder().WithObjects(cm).Build()
+	tenantClientset := fake.NewSimpleClientset()
+	rc := &failingReconciler{err: errors.New("invalid spec")}
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc, WithDeadLetter(2, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.clusters["dead-letter"] = &fakeTenantCluster{client: tenantClient, clientset: tenantClientset}
+	req := reconciler.Request{ClusterName: "dead-letter", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}, UID: "cm-uid"}
+	deadLetters := metrics.DeadLetters.WithLabelValues("ConfigMap", "dead-letter")
+
+	check := func(expectedAttempts int, expectedDeadLetters int, expectedRequeues int) {
+		t.Helper()
+		if rc.attempts != expectedAttempts {
+			t.Errorf("expected %d reconciles, got %d", expectedAttempts, rc.attempts)
+		}
+		if got := len(c.DeadLetters()); got != expectedDeadLetters {
+			t.Errorf("expected %d dead letters, got %v", expectedDeadLetters, c.DeadLetters())
+		}
+		if got := testutil.ToFloat64(deadLetters); got != float64(expectedDeadLetters) {
+			t.Errorf("expected dead letter gauge %d, got %v", expectedDeadLetters, got)
+		}
+		if got := c.Queue.NumRequeues(req); got != expectedRequeues {
+			t.Errorf("expected %d requeues, got %d", expectedRequeues, got)
+		}
+	}
+	process := func() {
+		t.Helper()
+		if !c.processNextWorkItem() {
+			t.Fatalf("expected worker to continue")
+		}
+	}
+
+	// the request is retried until the threshold, then dead-lettered.
+	c.Queue.Add(req)
+	for i := 0; i < 3; i++ {
+		process()
+	}
+	check(3, 1, 0)
+	if dl := c.DeadLetters()[0]; dl.Kind != "ConfigMap" || dl.Name != "cm" || dl.UID != "cm-uid" || dl.Error != "invalid spec" {
+		t.Errorf("unexpected dead letter %+v", dl)
+	}
+	events, err := tenantClientset.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events.Items) != 1 || events.Items[0].Reason != "SyncFailed" || events.Items[0].InvolvedObject.Name != "cm" {
+		t.Errorf("expected one SyncFailed event for the configmap, got %+v", events.Items)
+	}
+
+	// the unchanged object is tried once, without entering the retry loop again.
+	c.Queue.Add(req)
+	process()
+	check(4, 1, 0)
+
+	// the changed object leaves the dead-letter set and is retried as usual.
+	cm = &corev1.ConfigMap{}
+	if err := tenantClient.Get(context.TODO(), req.NamespacedName, cm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cm.Data = map[string]string{"fixed": "true"}
+	if err := tenantClient.Update(context.TODO(), cm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Queue.Add(req)
+	process()
+	check(5, 0, 1)
+
+	events, err = tenantClientset.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events.Items) != 1 {
+		t.Errorf("expected no event for the retries, got %+v", events.Items)
+	}
+}
+
+func TestDeadLetterRetryPeriod(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}}
+	rc := &failingReconciler{err: errors.New("invalid spec")}
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc, WithDeadLetter(1, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.clusters["retry"] = &fakeTenantCluster{client: fakeClient.NewClientBuilder().WithObjects(cm).Build(), clientset: fake.NewSimpleClientset()}
+	req := reconciler.Request{ClusterName: "retry", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}}
+
+	c.Queue.Add(req)
+	for i := 0; i < 2; i++ {
+		if !c.processNextWorkItem() {
+			t.Fatalf("expected worker to continue")
+		}
+	}
+	if len(c.DeadLetters()) != 1 {
+		t.Fatalf("expected the request to be dead-lettered, got %v", c.DeadLetters())
+	}
+
+	// the dead letter is retried after the retry period and leaves the set once synced.
+	rc.err = nil
+	if !c.processNextWorkItem() {
+		t.Fatalf("expected worker to continue")
+	}
+	if rc.attempts != 3 {
+		t.Errorf("expected 3 reconciles, got %d", rc.attempts)
+	}
+	if len(c.DeadLetters()) != 0 {
+		t.Errorf("expected no dead letters, got %v", c.DeadLetters())
+	}
+	if got := testutil.ToFloat64(metrics.DeadLetters.WithLabelValues("ConfigMap", "retry")); got != 0 {
+		t.Errorf("expected dead letter gauge 0, got %v", got)
+	}
+}
+
+func TestMaxRetries(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm", UID: "cm-uid"}}
+	rc := &failingReconciler{err: errors.New("apiserver unavailable")}
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc, WithControllerName("max-retries-mccontroller"), WithRetries(2, time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.clusters["max-retries"] = &fakeTenantCluster{client: fakeClient.NewClientBuilder().WithObjects(cm).Build(), clientset: fake.NewSimpleClientset()}
+	req := reconciler.Request{ClusterName: "max-retries", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}, UID: "cm-uid"}
+	drops := metrics.QueueDrops.WithLabelValues("max-retries-mccontroller")
+
+	// the request is retried twice, then taken out of the queue.
+	c.Queue.Add(req)
+	for i := 0; i < 3; i++ {
+		if !c.processNextWorkItem() {
+			t.Fatalf("expected worker to continue")
+		}
+	}
+	if rc.attempts != 3 {
+		t.Errorf("expected 3 reconciles, got %d", rc.attempts)
+	}
+	if got := c.Queue.NumRequeues(req); got != 0 {
+		t.Errorf("expected the request to be forgotten, got %d requeues", got)
+	}
+	if got := len(c.DeadLetters()); got != 1 {
+		t.Errorf("expected the object to be dead-lettered, got %v", c.DeadLetters())
+	}
+	if got := testutil.ToFloat64(drops); got != 1 {
+		t.Errorf("expected 1 dropped request, got %v", got)
+	}
+}
+
+func TestRetryBaseDelay(t *testing.T) {
+	rc := &failingReconciler{err: errors.New("apiserver unavailable")}
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc, WithRetries(2, time.Hour, time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.clusters["base-delay"] = &fakeTenantCluster{client: fakeClient.NewClientBuilder().Build(), clientset: fake.NewSimpleClientset()}
+	req := reconciler.Request{ClusterName: "base-delay", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}}
+
+	c.Queue.Add(req)
+	if !c.processNextWorkItem() {
+		t.Fatalf("expected worker to continue")
+	}
+	// the retry waits for the configured base delay instead of the default 5ms.
+	time.Sleep(100 * time.Millisecond)
+	if got := c.Queue.Len(); got != 0 {
+		t.Errorf("expected the retry to be delayed by the base delay, got %d queued requests", got)
+	}
+	if got := c.Queue.NumRequeues(req); got != 1 {
+		t.Errorf("expected 1 requeue, got %d", got)
+	}
+}
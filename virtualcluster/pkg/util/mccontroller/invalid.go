@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// InvalidObjectError is returned by a Reconciler when the syncer finds that the super cluster object
+// converted from a tenant object is invalid, e.g. a tenant label value is too long. Retrying won't
+// help until the tenant object is changed, so the request is not retried and the tenant is told
+// which fields are invalid. Invalid errors returned by the super cluster are retried as usual.
+type InvalidObjectError struct {
+	err error
+}
+
+// NewInvalidObjectError wraps the validation error of a super cluster object.
+func NewInvalidObjectError(err error) error {
+	return &InvalidObjectError{err: err}
+}
+
+func (e *InvalidObjectError) Error() string {
+	return e.err.Error()
+}
+
+func (e *InvalidObjectError) Unwrap() error {
+	return e.err
+}
+
+// IsInvalidObjectError returns true if err is, or wraps, an InvalidObjectError.
+func IsInvalidObjectError(err error) bool {
+	var invalid *InvalidObjectError
+	return errors.As(err, &invalid)
+}
+
+// handleInvalidError tells the tenant which fields, e.g. labels, make the super cluster object invalid.
+// Only the syncer's own validation errors are sent, never the messages of the super cluster.
+func (c *MultiClusterController) handleInvalidError(req reconciler.Request, err error) {
+	if eventErr := c.Eventf(req.ClusterName, &corev1.ObjectReference{
+		Kind:      c.objectKind,
+		Namespace: req.Namespace,
+		Name:      req.Name,
+		UID:       types.UID(req.UID),
+	}, corev1.EventTypeWarning, "InvalidSuperClusterObject", "The object cannot be synced to the super cluster: %v", err); eventErr != nil {
+		klog.Warningf("failed to send invalid object event for %s %s/%s of cluster %s: %v", c.objectKind, req.Namespace, req.Name, req.ClusterName, eventErr)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/lifecycle"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// syncedObjectKey identifies a tenant object.
+type syncedObjectKey struct {
+	cluster   string
+	namespace string
+	name      string
+}
+
+// notifyReconciled sends a Synced lifecycle event once a successful reconcile of an existing tenant
+// object wrote to the super cluster, and a CleanedUp event once a successful reconcile of a tenant
+// object deleted in the tenant cluster removed its super cluster copy. The reconciles finding the
+// super cluster in sync are not notified. A Synced event is not sent again for the same object while
+// the previous one waits for delivery.
+func (c *MultiClusterController) notifyReconciled(req reconciler.Request, written bool) {
+	if !lifecycle.Enabled() || !written {
+		return
+	}
+	obj := c.objectType.DeepCopyObject().(client.Object)
+	err := c.Get(req.ClusterName, req.Namespace, req.Name, obj)
+	if apierrors.IsNotFound(err) {
+		c.notify(req, lifecycle.EventCleanedUp, req.UID, lifecycle.OutcomeOK, "", nil)
+		return
+	}
+	if err != nil {
+		klog.V(4).Infof("failed to get %s %s/%s of cluster %s for lifecycle notification: %v", c.objectKind, req.Namespace, req.Name, req.ClusterName, err)
+		return
+	}
+
+	key := syncedObjectKey{cluster: req.ClusterName, namespace: req.Namespace, name: req.Name}
+	uid := string(obj.GetUID())
+	c.syncedObjectsLock.Lock()
+	pendingUID, pending := c.syncedObjects[key]
+	pending = pending && pendingUID == uid
+	c.syncedObjects[key] = uid
+	c.syncedObjectsLock.Unlock()
+	if !pending {
+		c.notify(req, lifecycle.EventSynced, uid, lifecycle.OutcomeOK, "", func() { c.syncedObjectDelivered(key, uid) })
+	}
+}
+
+// syncedObjectDelivered forgets the tenant object once its Synced event is delivered or dropped.
+func (c *MultiClusterController) syncedObjectDelivered(key syncedObjectKey, uid string) {
+	c.syncedObjectsLock.Lock()
+	defer c.syncedObjectsLock.Unlock()
+	if c.syncedObjects[key] == uid {
+		delete(c.syncedObjects, key)
+	}
+}
+
+// notifyFailed sends a Failed lifecycle event for a request the controller gives up on.
+func (c *MultiClusterController) notifyFailed(req reconciler.Request, outcome string, err error) {
+	if !lifecycle.Enabled() {
+		return
+	}
+	c.notify(req, lifecycle.EventFailed, req.UID, outcome, err.Error(), nil)
+}
+
+func (c *MultiClusterController) notify(req reconciler.Request, eventType lifecycle.EventType, uid, outcome, message string, done func()) {
+	lifecycle.Notify(lifecycle.Event{
+		Type:      eventType,
+		Cluster:   req.ClusterName,
+		Resource:  c.objectKind,
+		Namespace: req.Namespace,
+		Name:      req.Name,
+		UID:       uid,
+		Outcome:   outcome,
+		Message:   message,
+		Done:      done,
+	})
+}
+
+// forgetSyncedObjects drops the synced objects of a removed cluster, whose Synced events wait for delivery.
+func (c *MultiClusterController) forgetSyncedObjects(clusterName string) {
+	c.syncedObjectsLock.Lock()
+	defer c.syncedObjectsLock.Unlock()
+	for key := range c.syncedObjects {
+		if key.cluster == clusterName {
+			delete(c.syncedObjects, key)
+		}
+	}
+}
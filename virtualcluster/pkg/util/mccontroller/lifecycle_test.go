@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/lifecycle"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+type fakeNotifier struct {
+	events []lifecycle.Event
+}
+
+func (n *fakeNotifier) Notify(event lifecycle.Event) {
+	n.events = append(n.events, event)
+}
+
+type fakeDelegatingCluster struct {
+	ClusterInterface
+	client client.Client
+}
+
+func (f *fakeDelegatingCluster) GetDelegatingClient() (client.Client, error) {
+	return f.client, nil
+}
+
+func TestLifecycleNotification(t *testing.T) {
+	notifier := &fakeNotifier{}
+	lifecycle.SetNotifier(notifier)
+	defer lifecycle.SetNotifier(nil)
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm", UID: "cm-uid"}}
+	tenantClient := fakeClient.NewClientBuilder().WithObjects(cm).Build()
+	rc := &writingReconciler{write: "cm"}
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rc.c = c
+	c.clusters["tenant"] = &fakeDelegatingCluster{client: tenantClient}
+	req := reconciler.Request{ClusterName: "tenant", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}, UID: "cm-uid"}
+
+	var events []lifecycle.Event
+	process := func(expected ...lifecycle.EventType) {
+		t.Helper()
+		notifier.events = nil
+		c.Queue.Add(req)
+		if !c.processNextWorkItem() {
+			t.Fatalf("expected worker to continue")
+		}
+		if len(notifier.events) != len(expected) {
+			t.Fatalf("expected events %v, got %+v", expected, notifier.events)
+		}
+		for i, event := range notifier.events {
+			if event.Type != expected[i] || event.Cluster != "tenant" || event.Resource != "ConfigMap" ||
+				event.Namespace != "default" || event.Name != "cm" || event.UID != "cm-uid" {
+				t.Errorf("expected %s event of the configmap, got %+v", expected[i], event)
+			}
+		}
+		events = notifier.events
+	}
+
+	// a sync is notified once while its event waits for delivery.
+	process(lifecycle.EventSynced)
+	done := events[0].Done
+	process()
+	// the object is forgotten once the event is delivered, the next sync is notified.
+	done()
+	if len(c.syncedObjects) != 0 {
+		t.Errorf("expected the delivered object to be forgotten, got %v", c.syncedObjects)
+	}
+	process(lifecycle.EventSynced)
+	// the objects of a removed cluster are forgotten.
+	c.forgetSyncedObjects("tenant")
+	if len(c.syncedObjects) != 0 {
+		t.Errorf("expected the objects of the removed cluster to be forgotten, got %v", c.syncedObjects)
+	}
+
+	// a reconcile without a write is not notified.
+	rc.write = ""
+	process()
+
+	// persistent failures are notified.
+	rc.err = apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "cm", errors.New("denied by webhook"))
+	process(lifecycle.EventFailed)
+	if outcome := events[0].Outcome; outcome != lifecycle.OutcomeRejected {
+		t.Errorf("expected outcome %s, got %s", lifecycle.OutcomeRejected, outcome)
+	}
+
+	// the clean up of a synced object is notified.
+	rc.err = nil
+	rc.write = "cm"
+	if err := tenantClient.Delete(context.TODO(), cm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	process(lifecycle.EventCleanedUp)
+	rc.write = ""
+	process()
+}
@@ -29,6 +29,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	clientset "k8s.io/client-go/kubernetes"
 	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -40,13 +41,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/lifecycle"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/tracing"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/featuregate"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/util/scheme"
 	utilconstants "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/drain"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/errors"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/fairqueue"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/handler"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/logsampling"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/record"
 )
@@ -104,12 +109,47 @@ type MultiClusterController struct {
 	// objectType is the type of object to watch.  e.g. &corev1.Pod{}
 	objectType client.Object
 
+	// objectListType is the list type of objectType. e.g. &corev1.PodList{}
+	objectListType client.ObjectList
+
 	// objectKind is the kind of target object this controller watched.
 	objectKind string
 
 	// clusters is the internal cluster set this controller watches.
 	clusters map[string]ClusterInterface
 
+	// quotaPausedUntil is the time until which object creation is paused per cluster
+	// after an object count quota rejection.
+	quotaPausedUntil map[string]time.Time
+
+	// syncedObjects are the uids of the tenant objects whose Synced lifecycle event waits for delivery.
+	syncedObjectsLock sync.Mutex
+	syncedObjects     map[syncedObjectKey]string
+
+	// objectCounts is the number of tenant objects per cluster, kept up to date by the informer
+	// events and corrected by the periodic recount.
+	objectCountsLock sync.Mutex
+	objectCounts     map[string]int
+
+	// pausedObjects are the tenant objects per cluster whose sync is paused by annotation.
+	pausedObjectsLock sync.Mutex
+	pausedObjects     map[string]map[types.NamespacedName]struct{}
+
+	// onboardings are the clusters whose initial sync is in progress.
+	onboardingsLock sync.Mutex
+	onboardings     map[string]*onboarding
+
+	// deadLetters are the tenant objects per cluster whose sync has been taken out of the retry loop.
+	deadLettersLock sync.Mutex
+	deadLetters     map[string]map[types.NamespacedName]*DeadLetter
+
+	// observedTimes are the times the changes of the queued requests were first observed.
+	observedTimes *metrics.ObservedTimes
+
+	// written are the tenant objects whose reconcile in progress wrote to the super cluster.
+	writtenLock sync.Mutex
+	written     map[syncedObjectKey]struct{}
+
 	Options
 }
 
@@ -121,11 +161,55 @@ type Options struct {
 	// MaxConcurrentReconciles is the number of concurrent control loops.
 	MaxConcurrentReconciles int
 
+	// MaxConcurrentReconcilesPerCluster is the maximum number of control loops that reconcile requests
+	// of the same cluster at the same time. 0 means a cluster can use all the control loops.
+	MaxConcurrentReconcilesPerCluster int
+
+	// ClusterWorkerLimiter caps the workers reconciling requests of the same cluster across all the
+	// controllers sharing it. It defaults to DefaultClusterWorkerLimiter.
+	ClusterWorkerLimiter *ClusterWorkerLimiter
+
 	Reconciler reconciler.DWReconciler
 
 	// Queue can be used to override the default queue.
 	Queue workqueue.RateLimitingInterface
 
+	// ObjectCountQuotaPausePeriod is how long object creation is paused for a cluster after the super
+	// cluster rejected an object due to an object count quota. 0 disables the pause and drops the request.
+	ObjectCountQuotaPausePeriod time.Duration
+
+	// ObjectCountRecountInterval is how often the tenant object counts are rebuilt from the informer
+	// caches to correct the drift caused by missed events. 0 disables the recount.
+	ObjectCountRecountInterval time.Duration
+
+	// OnboardingMaxConcurrentReconciles is the maximum number of control loops that reconcile requests
+	// of a newly watched cluster at the same time, until all its tenant objects have been reconciled
+	// once. 0 disables the onboarding limit.
+	OnboardingMaxConcurrentReconciles int
+
+	// OnboardingRampUpPeriod is how often the onboarding limit doubles. 0 keeps it constant.
+	OnboardingRampUpPeriod time.Duration
+
+	// MaxRetries is the number of retries after which a failing request is taken out of the retry
+	// loop. 0 means MaxReconcileRetryAttempts.
+	MaxRetries int
+
+	// RateLimiter, if set, delays the retries of the default Queue instead of the default controller
+	// rate limiter. It is not used if Queue is set.
+	RateLimiter workqueue.RateLimiter
+
+	// DeadLetterRetryThreshold is the number of retries after which a failing request is taken out of
+	// the retry loop and its object added to the dead-letter set. 0 means MaxRetries.
+	DeadLetterRetryThreshold int
+
+	// DeadLetterRetryPeriod is how often the requests of the dead-letter set are retried. 0 retries
+	// them only when their object changes.
+	DeadLetterRetryPeriod time.Duration
+
+	// PrioritizeUpdate, if set, tells the tenant object updates whose requests go ahead of the queued
+	// requests of their cluster, if the Queue supports it. The clusters are still served in turn.
+	PrioritizeUpdate func(oldObj, newObj interface{}) bool
+
 	// name is used to uniquely identify a Controller in tracing, logging and monitoring.  Name is required.
 	name string
 }
@@ -138,15 +222,24 @@ func NewMCController(objectType client.Object, objectListType client.ObjectList,
 	}
 
 	c := &MultiClusterController{
-		objectType: objectType,
-		objectKind: kinds[0].Kind,
-		clusters:   make(map[string]ClusterInterface),
+		objectType:       objectType,
+		objectListType:   objectListType,
+		objectKind:       kinds[0].Kind,
+		clusters:         make(map[string]ClusterInterface),
+		quotaPausedUntil: make(map[string]time.Time),
+		syncedObjects:    make(map[syncedObjectKey]string),
+		objectCounts:     make(map[string]int),
+		pausedObjects:    make(map[string]map[types.NamespacedName]struct{}),
+		onboardings:      make(map[string]*onboarding),
+		deadLetters:      make(map[string]map[types.NamespacedName]*DeadLetter),
+		observedTimes:    metrics.NewObservedTimes(),
+		written:          make(map[syncedObjectKey]struct{}),
 		Options: Options{
 			name:                    fmt.Sprintf("%s-mccontroller", strings.ToLower(kinds[0].Kind)),
 			JitterPeriod:            1 * time.Second,
 			MaxConcurrentReconciles: constants.DwsControllerWorkerLow,
+			ClusterWorkerLimiter:    DefaultClusterWorkerLimiter,
 			Reconciler:              rc,
-			Queue:                   fairqueue.NewRateLimitingFairQueue(),
 		},
 	}
 
@@ -154,6 +247,14 @@ func NewMCController(objectType client.Object, objectListType client.ObjectList,
 		opt(&c.Options)
 	}
 
+	if c.Queue == nil {
+		var queueOpts []fairqueue.OptConfig
+		if c.RateLimiter != nil {
+			queueOpts = append(queueOpts, fairqueue.WithRateLimiter(c.RateLimiter))
+		}
+		c.Queue = fairqueue.NewRateLimitingFairQueue(queueOpts...)
+	}
+
 	if c.Reconciler == nil {
 		return nil, fmt.Errorf("mccontroller %q: must specify DW Reconciler", c.objectKind)
 	}
@@ -181,8 +282,12 @@ func (c *MultiClusterController) WatchClusterResource(cluster ClusterInterface,
 		return nil
 	}
 
-	h := &handler.EnqueueRequestForObject{ClusterName: cluster.GetClusterName(), Queue: c.Queue, AttachUID: o.AttachUID}
-	return cluster.AddEventHandler(c.objectType, h)
+	c.startOnboarding(cluster.GetClusterName())
+	h := &handler.EnqueueRequestForObject{ClusterName: cluster.GetClusterName(), Queue: c.Queue, AttachUID: o.AttachUID, Prioritize: c.PrioritizeUpdate, Observe: c.observe}
+	if err := cluster.AddEventHandler(c.objectType, h); err != nil {
+		return err
+	}
+	return cluster.AddEventHandler(c.objectType, c.objectCountHandler(cluster.GetClusterName()))
 }
 
 // RegisterClusterResource get the informer *before* trying to wait for the
@@ -209,6 +314,12 @@ func (c *MultiClusterController) TeardownClusterResource(cluster ClusterInterfac
 	c.Lock()
 	defer c.Unlock()
 	delete(c.clusters, cluster.GetClusterName())
+	c.forgetSyncedObjects(cluster.GetClusterName())
+	c.forgetObjectCount(cluster.GetClusterName())
+	c.forgetPausedObjects(cluster.GetClusterName())
+	c.forgetOnboarding(cluster.GetClusterName())
+	c.ClusterWorkerLimiter.forget(c, cluster.GetClusterName())
+	c.forgetDeadLetters(cluster.GetClusterName())
 }
 
 // Start starts the ClustersController's control loops (as many as MaxConcurrentReconciles) in separate channels
@@ -221,6 +332,9 @@ func (c *MultiClusterController) Start(stop <-chan struct{}) error {
 	for i := 0; i < c.MaxConcurrentReconciles; i++ {
 		go wait.Until(c.worker, c.JitterPeriod, stop)
 	}
+	if c.ObjectCountRecountInterval > 0 {
+		go wait.Until(c.RecountObjects, c.ObjectCountRecountInterval, stop)
+	}
 
 	<-stop
 	return nil
@@ -374,10 +488,16 @@ func (c *MultiClusterController) RequeueObject(clusterName string, obj interface
 	r.Name = o.GetName()
 	r.UID = string(o.GetUID())
 
+	c.observe(r)
 	c.Queue.Add(r)
 	return nil
 }
 
+// observe records that a change of the tenant object of the request is observed, for the sync latency.
+func (c *MultiClusterController) observe(req reconciler.Request) {
+	c.observedTimes.Observe(req)
+}
+
 // worker runs a worker thread that just dequeues items, processes them, and marks them done.
 // It enforces that the reconcileHandler is never invoked concurrently with the same object.
 func (c *MultiClusterController) worker() {
@@ -407,6 +527,12 @@ func (c *MultiClusterController) processNextWorkItem() bool {
 	// period.
 	defer c.Queue.Done(obj)
 
+	// the syncer is shutting down, leave the request to the next syncer.
+	if !drain.DefaultTracker.Start() {
+		return false
+	}
+	defer drain.DefaultTracker.Done()
+
 	var req reconciler.Request
 	var ok bool
 	if req, ok = obj.(reconciler.Request); !ok {
@@ -418,6 +544,9 @@ func (c *MultiClusterController) processNextWorkItem() bool {
 		// Return true, don't take a break
 		return true
 	}
+	metrics.RecordQueueDepth(c.name, c.Queue.Len())
+	// the changes observed from now on are synced by the next reconcile of the request.
+	observed, hasObserved := c.observedTimes.Take(req)
 	if c.GetCluster(req.ClusterName) == nil {
 		// The virtual cluster has been removed, do not reconcile for its dws requests.
 		klog.Warningf("The cluster %s has been removed, drop the dws request %v", req.ClusterName, req)
@@ -425,6 +554,14 @@ func (c *MultiClusterController) processNextWorkItem() bool {
 		return true
 	}
 
+	// the cluster has used up its share of workers, the request is put back in the queue once a
+	// worker of the cluster is released.
+	if !c.acquireClusterWorker(req) {
+		c.restoreObserved(req, observed, hasObserved)
+		return true
+	}
+	defer c.releaseClusterWorker(req.ClusterName)
+
 	if featuregate.DefaultFeatureGate.Enabled(featuregate.SuperClusterPooling) {
 		if c.FilterObjectFromSchedulingResult(req) {
 			c.Queue.Forget(req)
@@ -434,49 +571,113 @@ func (c *MultiClusterController) processNextWorkItem() bool {
 		}
 	}
 
+	// the tenant object is paused by annotation, leave it and its super control plane counterpart as is.
+	if c.syncPaused(req) {
+		c.Queue.Forget(obj)
+		return true
+	}
+
 	defer metrics.RecordDWSOperationDuration(c.objectKind, req.ClusterName, time.Now())
 
 	// RunInformersAndControllers the syncHandler, passing it the cluster/namespace/Name
 	// string of the resource to be synced.
+	span := tracing.Start(tracing.DirectionDWS, c.objectKind, tracing.Object{Cluster: req.ClusterName, Namespace: req.Namespace, Name: req.Name, UID: req.UID})
 	result, err := c.Reconciler.Reconcile(req)
+	span.End(err)
+	written := c.takeWritten(req)
+	c.onboardingReconciled(req)
 	if err == nil {
 		metrics.RecordDWSOperationStatus(c.objectKind, req.ClusterName, utilconstants.StatusCodeOK)
+		// the changes synced without a write were already in sync, e.g. an update of the status.
+		if hasObserved && written {
+			metrics.RecordSyncLatency(c.objectKind, metrics.SyncDirectionDWS, observed)
+		}
 		if result.RequeueAfter > 0 {
 			c.Queue.AddAfter(req, result.RequeueAfter)
 		} else if result.Requeue {
+			metrics.RecordQueueRetry(c.name)
 			c.Queue.AddRateLimited(req)
 		}
 		// if no error occurs we Forget this item so it does not
 		// get queued again until another change happens.
 		c.Queue.Forget(obj)
+		c.removeDeadLetter(req)
+		c.notifyReconciled(req, written)
+		return true
+	}
+
+	// rejected by an object count quota of the super cluster namespace, retried once the creation
+	// pause is over or, without a pause, like the other failures.
+	quotaExceeded := IsObjectCountQuotaError(err)
+	if quotaExceeded && c.handleObjectCountQuotaError(req, err) {
+		metrics.RecordDWSOperationStatus(c.objectKind, req.ClusterName, utilconstants.StatusCodeBadRequest)
+		c.Queue.Forget(obj)
+		c.restoreObserved(req, observed, hasObserved)
+		return true
+	}
+
+	// the tenant object converts to an invalid super cluster object, e.g. a tenant label value is
+	// too long, retrying won't help until the tenant object is changed.
+	if IsInvalidObjectError(err) {
+		metrics.RecordDWSOperationStatus(c.objectKind, req.ClusterName, utilconstants.StatusCodeBadRequest)
+		klog.Errorf("%s dws request is invalid: %v", c.name, err)
+		c.Queue.Forget(obj)
+		c.handleInvalidError(req, err)
+		c.notifyFailed(req, lifecycle.OutcomeRejected, err)
 		return true
 	}
 
 	// rejected by apiserver(maybe rejected by webhook or other admission plugins)
 	// we take a negative attitude on this situation and fail fast.
-	if apierr, ok := err.(apierrors.APIStatus); ok {
+	if apierr, ok := err.(apierrors.APIStatus); ok && !quotaExceeded {
 		if code := apierr.Status().Code; code == http.StatusBadRequest || code == http.StatusForbidden {
 			metrics.RecordDWSOperationStatus(c.objectKind, req.ClusterName, utilconstants.StatusCodeBadRequest)
 			klog.Errorf("%s dws request is rejected: %v", c.name, err)
 			c.Queue.Forget(obj)
+			c.notifyFailed(req, lifecycle.OutcomeRejected, err)
 			return true
 		}
 	}
 
+	// the object is still failing since it was dead-lettered, leave it out of the retry loop.
+	if c.isDeadLetter(req) {
+		metrics.RecordDWSOperationStatus(c.objectKind, req.ClusterName, utilconstants.StatusCodeExceedMaxRetryAttempts)
+		c.Queue.Forget(obj)
+		c.addDeadLetter(req, err)
+		return true
+	}
+
 	// exceed max retry
-	if c.Queue.NumRequeues(obj) >= utilconstants.MaxReconcileRetryAttempts {
+	if c.Queue.NumRequeues(obj) >= c.deadLetterThreshold() {
 		metrics.RecordDWSOperationStatus(c.objectKind, req.ClusterName, utilconstants.StatusCodeExceedMaxRetryAttempts)
+		metrics.RecordQueueDrop(c.name)
 		c.Queue.Forget(obj)
-		klog.Warningf("%s dws request is dropped due to reaching max retry limit: %+v", c.name, obj)
+		klog.Errorf("%s dws request is dead-lettered due to reaching max retry limit: %+v: %v", c.name, obj, err)
+		outcome := lifecycle.OutcomeExceededMaxRetryAttempts
+		if quotaExceeded {
+			outcome = lifecycle.OutcomeExceededObjectCountQuota
+		}
+		c.notifyFailed(req, outcome, err)
+		c.addDeadLetter(req, err)
 		return true
 	}
 
 	metrics.RecordDWSOperationStatus(c.objectKind, req.ClusterName, utilconstants.StatusCodeError)
+	c.restoreObserved(req, observed, hasObserved)
+	metrics.RecordQueueRetry(c.name)
 	c.Queue.AddRateLimited(req)
-	klog.Errorf("%s dws request reconcile failed: %v", req, err)
+	logsampling.Errorf("%s dws request reconcile failed: %v", req, err)
 	return true
 }
 
+// restoreObserved puts back the observed time of a request retried later, so that its sync latency
+// includes the failed or postponed attempts.
+func (c *MultiClusterController) restoreObserved(req reconciler.Request, observed time.Time, hasObserved bool) {
+	if hasObserved {
+		c.observedTimes.Restore(req, observed)
+	}
+}
+
 func (c *MultiClusterController) FilterObjectFromSchedulingResult(req reconciler.Request) bool {
 	var nsName string
 	if c.objectKind == "Namespace" {
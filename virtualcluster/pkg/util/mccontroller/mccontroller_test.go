@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/fake"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/handler"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+func TestInvalidObjectRejection(t *testing.T) {
+	tenantClient := fake.NewSimpleClientset()
+	invalid := NewInvalidObjectError(apierrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "cm", field.ErrorList{
+		field.Invalid(field.NewPath("metadata", "labels"), strings.Repeat("a", 64), "must be no more than 63 characters"),
+	}))
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, &fakeQuotaReconciler{err: invalid})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.clusters["tenant"] = &fakeCluster{client: tenantClient}
+
+	req := reconciler.Request{ClusterName: "tenant", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}}
+	c.Queue.Add(req)
+	if !c.processNextWorkItem() {
+		t.Fatalf("expected worker to continue")
+	}
+
+	events, err := tenantClient.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events.Items) != 1 || events.Items[0].Reason != "InvalidSuperClusterObject" || events.Items[0].InvolvedObject.Name != "cm" {
+		t.Fatalf("expected one InvalidSuperClusterObject event for the configmap, got %+v", events.Items)
+	}
+	if !strings.Contains(events.Items[0].Message, "metadata.labels") {
+		t.Errorf("expected the event to name the offending field, got %q", events.Items[0].Message)
+	}
+	if n := c.Queue.NumRequeues(req); n != 0 {
+		t.Errorf("expected the request not to be retried, got %d requeues", n)
+	}
+	if n := c.Queue.Len(); n != 0 {
+		t.Errorf("expected an empty queue, got %d", n)
+	}
+}
+
+func TestSuperClusterInvalidErrorRetried(t *testing.T) {
+	tenantClient := fake.NewSimpleClientset()
+	invalid := apierrors.NewInvalid(schema.GroupKind{Kind: "ConfigMap"}, "cm", field.ErrorList{
+		field.Invalid(field.NewPath("data"), "", "denied by super cluster webhook"),
+	})
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, &fakeQuotaReconciler{err: invalid})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.clusters["tenant"] = &fakeCluster{client: tenantClient}
+
+	req := reconciler.Request{ClusterName: "tenant", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}}
+	c.Queue.Add(req)
+	if !c.processNextWorkItem() {
+		t.Fatalf("expected worker to continue")
+	}
+
+	events, err := tenantClient.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events.Items) != 0 {
+		t.Errorf("expected the super cluster error not to be sent to the tenant, got %+v", events.Items)
+	}
+	if n := c.Queue.NumRequeues(req); n != 1 {
+		t.Errorf("expected the request to be retried, got %d requeues", n)
+	}
+}
+
+// scrapeSample scrapes the metrics served by the syncer and returns the value of the sample of the
+// series, e.g. `syncer_queue_depth{controller="pod-mccontroller"}`.
+func scrapeSample(t *testing.T, series string) (float64, bool) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if !strings.HasPrefix(line, series+" ") {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimPrefix(line, series+" "), 64)
+		if err != nil {
+			t.Fatalf("failed to parse sample %q: %v", line, err)
+		}
+		return value, true
+	}
+	return 0, false
+}
+
+// writingReconciler fails with err, or writes to the super cluster for the requests named write.
+type writingReconciler struct {
+	c     *MultiClusterController
+	err   error
+	write string
+}
+
+func (r *writingReconciler) Reconcile(req reconciler.Request) (reconciler.Result, error) {
+	if r.err == nil && req.Name == r.write {
+		r.c.SuperClusterWritten(req.ClusterName, req.Namespace, req.Name)
+	}
+	return reconciler.Result{}, r.err
+}
+
+func TestControllerMetrics(t *testing.T) {
+	metrics.Register()
+	metrics.SyncLatency.Reset()
+	metrics.QueueDepth.Reset()
+	metrics.QueueRetries.Reset()
+
+	rc := &writingReconciler{err: errors.New("conflict"), write: "cm"}
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rc.c = c
+	c.clusters["tenant"] = &fakeDelegatingCluster{client: fakeClient.NewClientBuilder().Build()}
+	h := &handler.EnqueueRequestForObject{ClusterName: "tenant", Queue: c.Queue, Observe: c.observe}
+	h.OnAdd(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}})
+	h.OnAdd(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"}})
+
+	const (
+		lag         = 50 * time.Millisecond
+		depthSeries = `syncer_queue_depth{controller="configmap-mccontroller"}`
+		countSeries = `vc_syncer_sync_latency_seconds_count{direction="dws",resource="ConfigMap"}`
+		sumSeries   = `vc_syncer_sync_latency_seconds_sum{direction="dws",resource="ConfigMap"}`
+		retrySeries = `syncer_queue_retries_total{controller="configmap-mccontroller"}`
+	)
+	time.Sleep(lag)
+	// the first attempt fails and is retried, the latency counts from the first observation.
+	if !c.processNextWorkItem() {
+		t.Fatalf("expected worker to continue")
+	}
+	if depth, _ := scrapeSample(t, depthSeries); depth != 1 {
+		t.Errorf("expected a queue depth of 1 with the other request queued, got %v", depth)
+	}
+	if _, ok := scrapeSample(t, countSeries); ok {
+		t.Errorf("expected no sync latency for a failed reconcile")
+	}
+	if retries, _ := scrapeSample(t, retrySeries); retries != 1 {
+		t.Errorf("expected 1 retry of the failed reconcile, got %v", retries)
+	}
+
+	rc.err = nil
+	for i := 0; i < 2; i++ {
+		if !c.processNextWorkItem() {
+			t.Fatalf("expected worker to continue")
+		}
+	}
+	// the other request is reconciled without a super cluster write, it was already in sync.
+	if count, _ := scrapeSample(t, countSeries); count != 1 {
+		t.Errorf("expected the sync latency of the written request only, got %v", count)
+	}
+	if sum, _ := scrapeSample(t, sumSeries); sum < lag.Seconds() {
+		t.Errorf("expected the sync latencies to include the %v before the reconciles, got a sum of %vs", lag, sum)
+	}
+	if depth, _ := scrapeSample(t, depthSeries); depth != 0 {
+		t.Errorf("expected an empty queue, got a depth of %v", depth)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	clientgocache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+// objectCountHandler counts the tenant objects of the cluster as the informer adds and deletes them.
+func (c *MultiClusterController) objectCountHandler(clusterName string) clientgocache.ResourceEventHandler {
+	return clientgocache.ResourceEventHandlerFuncs{
+		AddFunc: func(interface{}) {
+			c.adjustObjectCount(clusterName, 1)
+		},
+		DeleteFunc: func(interface{}) {
+			c.adjustObjectCount(clusterName, -1)
+		},
+	}
+}
+
+func (c *MultiClusterController) adjustObjectCount(clusterName string, delta int) {
+	c.objectCountsLock.Lock()
+	defer c.objectCountsLock.Unlock()
+	count := c.objectCounts[clusterName] + delta
+	if count < 0 {
+		count = 0
+	}
+	c.objectCounts[clusterName] = count
+	metrics.RecordObjectCount(c.objectKind, clusterName, count)
+}
+
+// ObjectCount returns the number of tenant objects of the cluster.
+func (c *MultiClusterController) ObjectCount(clusterName string) int {
+	c.objectCountsLock.Lock()
+	defer c.objectCountsLock.Unlock()
+	return c.objectCounts[clusterName]
+}
+
+// RecountObjects rebuilds the tenant object counts of all clusters from the informer caches and
+// corrects the counts that drifted.
+func (c *MultiClusterController) RecountObjects() {
+	if c.objectListType == nil {
+		return
+	}
+	for _, clusterName := range c.GetClusterNames() {
+		list := c.objectListType.DeepCopyObject().(client.ObjectList)
+		if err := c.List(clusterName, list); err != nil {
+			klog.Warningf("failed to recount %s objects of cluster %s: %v", c.objectKind, clusterName, err)
+			continue
+		}
+		actual := meta.LenList(list)
+
+		if c.GetCluster(clusterName) == nil {
+			// the cluster has been removed while listing.
+			continue
+		}
+		c.objectCountsLock.Lock()
+		counted := c.objectCounts[clusterName]
+		c.objectCounts[clusterName] = actual
+		c.objectCountsLock.Unlock()
+
+		metrics.RecordObjectCount(c.objectKind, clusterName, actual)
+		if counted != actual {
+			klog.V(4).Infof("corrected %s object count of cluster %s from %d to %d", c.objectKind, clusterName, counted, actual)
+			metrics.RecordObjectCountCorrection(c.objectKind, clusterName)
+		}
+	}
+}
+
+// forgetObjectCount drops the object count of a removed cluster.
+func (c *MultiClusterController) forgetObjectCount(clusterName string) {
+	c.objectCountsLock.Lock()
+	defer c.objectCountsLock.Unlock()
+	delete(c.objectCounts, clusterName)
+	metrics.DeleteObjectCount(c.objectKind, clusterName)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+)
+
+func TestRecountObjects(t *testing.T) {
+	tenantClient := fakeClient.NewClientBuilder().WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm-1"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm-2"}},
+	).Build()
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, &fakeQuotaReconciler{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.clusters["recount"] = &fakeDelegatingCluster{client: tenantClient}
+
+	handler := c.objectCountHandler("recount")
+	for i := 0; i < 5; i++ {
+		handler.OnAdd(&corev1.ConfigMap{})
+	}
+	handler.OnDelete(&corev1.ConfigMap{})
+	if count := c.ObjectCount("recount"); count != 4 {
+		t.Fatalf("expected drifted count 4, got %d", count)
+	}
+
+	corrections := metrics.ObjectCountCorrections.WithLabelValues("ConfigMap", "recount")
+	before := testutil.ToFloat64(corrections)
+
+	c.RecountObjects()
+	if count := c.ObjectCount("recount"); count != 2 {
+		t.Errorf("expected corrected count 2, got %d", count)
+	}
+	if got := testutil.ToFloat64(corrections) - before; got != 1 {
+		t.Errorf("expected 1 correction, got %v", got)
+	}
+
+	// a count in line with the cache is not a correction.
+	c.RecountObjects()
+	if got := testutil.ToFloat64(corrections) - before; got != 1 {
+		t.Errorf("expected 1 correction, got %v", got)
+	}
+}
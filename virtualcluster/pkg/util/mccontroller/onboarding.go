@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// onboarding is the initial sync of a newly watched cluster, during which the informer enqueues
+// all the existing tenant objects at once.
+type onboarding struct {
+	start time.Time
+	// reconciled are the tenant objects reconciled at least once since the start.
+	reconciled map[types.NamespacedName]struct{}
+}
+
+// startOnboarding limits the workers of the cluster until all its tenant objects are reconciled.
+func (c *MultiClusterController) startOnboarding(clusterName string) {
+	if c.OnboardingMaxConcurrentReconciles <= 0 {
+		return
+	}
+	c.onboardingsLock.Lock()
+	defer c.onboardingsLock.Unlock()
+	c.onboardings[clusterName] = &onboarding{
+		start:      time.Now(),
+		reconciled: make(map[types.NamespacedName]struct{}),
+	}
+}
+
+// onboardingWorkerLimit returns the number of workers the cluster may use while it is onboarding,
+// or 0 if it is not. The limit starts at OnboardingMaxConcurrentReconciles and doubles every
+// OnboardingRampUpPeriod, the onboarding ends once it no longer limits the cluster.
+func (c *MultiClusterController) onboardingWorkerLimit(clusterName string) int {
+	c.onboardingsLock.Lock()
+	defer c.onboardingsLock.Unlock()
+	o, ok := c.onboardings[clusterName]
+	if !ok {
+		return 0
+	}
+
+	maxWorkers := c.MaxConcurrentReconciles
+	if c.MaxConcurrentReconcilesPerCluster > 0 && c.MaxConcurrentReconcilesPerCluster < maxWorkers {
+		maxWorkers = c.MaxConcurrentReconcilesPerCluster
+	}
+	limit := c.OnboardingMaxConcurrentReconciles
+	if c.OnboardingRampUpPeriod > 0 {
+		for steps := time.Since(o.start) / c.OnboardingRampUpPeriod; steps > 0 && limit < maxWorkers; steps-- {
+			limit *= 2
+		}
+	}
+	if limit >= maxWorkers {
+		klog.Infof("%s onboarding of cluster %s ramped up after %v", c.objectKind, clusterName, time.Since(o.start))
+		delete(c.onboardings, clusterName)
+		return 0
+	}
+	return limit
+}
+
+// onboardingReconciled records a request reconciled while its cluster is onboarding. The onboarding
+// ends once every tenant object of the cluster has been reconciled.
+func (c *MultiClusterController) onboardingReconciled(req reconciler.Request) {
+	if !c.IsOnboarding(req.ClusterName) {
+		return
+	}
+	// the object count is only complete once the informer has listed all the tenant objects.
+	listed := false
+	if cluster := c.GetCluster(req.ClusterName); cluster != nil && c.objectType != nil {
+		if informer, err := cluster.GetInformer(c.objectType); err == nil {
+			listed = informer.HasSynced()
+		}
+	}
+
+	c.onboardingsLock.Lock()
+	defer c.onboardingsLock.Unlock()
+	o, ok := c.onboardings[req.ClusterName]
+	if !ok {
+		return
+	}
+	o.reconciled[types.NamespacedName{Namespace: req.Namespace, Name: req.Name}] = struct{}{}
+
+	total := c.ObjectCount(req.ClusterName)
+	metrics.RecordOnboardingProgress(c.objectKind, req.ClusterName, len(o.reconciled), total)
+	if listed && len(o.reconciled) >= total {
+		klog.Infof("%s onboarding of cluster %s completed, %d objects reconciled in %v", c.objectKind, req.ClusterName, len(o.reconciled), time.Since(o.start))
+		delete(c.onboardings, req.ClusterName)
+	}
+}
+
+// IsOnboarding returns whether the initial sync of the cluster is limited.
+func (c *MultiClusterController) IsOnboarding(clusterName string) bool {
+	c.onboardingsLock.Lock()
+	defer c.onboardingsLock.Unlock()
+	_, ok := c.onboardings[clusterName]
+	return ok
+}
+
+// forgetOnboarding drops the onboarding of a removed cluster.
+func (c *MultiClusterController) forgetOnboarding(clusterName string) {
+	c.onboardingsLock.Lock()
+	defer c.onboardingsLock.Unlock()
+	delete(c.onboardings, clusterName)
+	metrics.DeleteOnboardingProgress(c.objectKind, clusterName)
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+type fakeInformerCluster struct {
+	ClusterInterface
+	informer *controllertest.FakeInformer
+}
+
+func (f *fakeInformerCluster) GetInformer(client.Object) (cache.Informer, error) {
+	return f.informer, nil
+}
+
+func TestOnboardingWorkerLimit(t *testing.T) {
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, &fakeQuotaReconciler{},
+		WithMaxConcurrentReconciles(8), WithOnboarding(1, time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if limit := c.onboardingWorkerLimit("new"); limit != 0 {
+		t.Errorf("expected no limit for a cluster not onboarding, got %d", limit)
+	}
+
+	c.startOnboarding("new")
+	if limit := c.onboardingWorkerLimit("new"); limit != 1 {
+		t.Errorf("expected onboarding limit 1, got %d", limit)
+	}
+
+	c.onboardings["new"].start = time.Now().Add(-2*time.Minute - time.Second)
+	if limit := c.onboardingWorkerLimit("new"); limit != 4 {
+		t.Errorf("expected onboarding limit 4 after two ramp up periods, got %d", limit)
+	}
+
+	c.onboardings["new"].start = time.Now().Add(-3*time.Minute - time.Second)
+	if limit := c.onboardingWorkerLimit("new"); limit != 0 {
+		t.Errorf("expected the onboarding to end once the limit reaches the workers, got %d", limit)
+	}
+	if c.IsOnboarding("new") {
+		t.Errorf("expected the onboarding to be over")
+	}
+}
+
+func TestOnboardingDisabled(t *testing.T) {
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, &fakeQuotaReconciler{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.startOnboarding("new")
+	if c.IsOnboarding("new") {
+		t.Errorf("expected no onboarding without an onboarding limit")
+	}
+}
+
+func TestOnboardingProgress(t *testing.T) {
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, &fakeQuotaReconciler{},
+		WithMaxConcurrentReconciles(8), WithOnboarding(2, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	informer := &controllertest.FakeInformer{}
+	c.clusters["new"] = &fakeInformerCluster{informer: informer}
+	c.startOnboarding("new")
+	c.adjustObjectCount("new", 2)
+
+	first := reconciler.Request{ClusterName: "new", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm-1"}}
+	second := reconciler.Request{ClusterName: "new", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm-2"}}
+
+	// the informer has not listed all the objects yet.
+	c.onboardingReconciled(first)
+	c.onboardingReconciled(second)
+	if !c.IsOnboarding("new") {
+		t.Fatalf("expected the onboarding to wait for the informer")
+	}
+
+	c.forgetOnboarding("new")
+	c.startOnboarding("new")
+	informer.Synced = true
+
+	c.onboardingReconciled(first)
+	c.onboardingReconciled(first)
+	if !c.IsOnboarding("new") {
+		t.Fatalf("expected the onboarding to go on until every object is reconciled")
+	}
+	if reconciled := testutil.ToFloat64(metrics.OnboardingReconciled.WithLabelValues("ConfigMap", "new")); reconciled != 1 {
+		t.Errorf("expected 1 reconciled object, got %v", reconciled)
+	}
+	if total := testutil.ToFloat64(metrics.OnboardingObjects.WithLabelValues("ConfigMap", "new")); total != 2 {
+		t.Errorf("expected 2 objects to onboard, got %v", total)
+	}
+
+	c.onboardingReconciled(second)
+	if c.IsOnboarding("new") {
+		t.Errorf("expected the onboarding to end once every object is reconciled")
+	}
+	if reconciled := testutil.ToFloat64(metrics.OnboardingReconciled.WithLabelValues("ConfigMap", "new")); reconciled != 2 {
+		t.Errorf("expected 2 reconciled objects, got %v", reconciled)
+	}
+	if limit := c.onboardingWorkerLimit("new"); limit != 0 {
+		t.Errorf("expected no limit after the onboarding, got %d", limit)
+	}
+}
+
+func TestOnboardingMaxConcurrentReconciles(t *testing.T) {
+	rc := &blockingReconciler{
+		hot:      "hot",
+		release:  make(chan struct{}),
+		running:  map[string]int{},
+		maxSeen:  map[string]int{},
+		finished: map[string]int{},
+	}
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc,
+		WithMaxConcurrentReconciles(3), WithOnboarding(1, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.clusters["hot"] = &fakeInformerCluster{informer: &controllertest.FakeInformer{}}
+	c.startOnboarding("hot")
+
+	for i := 0; i < 5; i++ {
+		c.Queue.Add(reconciler.Request{ClusterName: "hot", NamespacedName: types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("hot-%d", i)}})
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go c.Start(stop)
+
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return rc.get(rc.running, "hot") == 1, nil
+	}); err != nil {
+		t.Fatalf("expected 1 worker on the onboarding cluster, got %d", rc.get(rc.running, "hot"))
+	}
+
+	close(rc.release)
+	if err := wait.PollImmediate(10*time.Millisecond, wait.ForeverTestTimeout, func() (bool, error) {
+		return rc.get(rc.finished, "hot") == 5, nil
+	}); err != nil {
+		t.Fatalf("expected all requests to be reconciled, got %d", rc.get(rc.finished, "hot"))
+	}
+	if maxSeen := rc.get(rc.maxSeen, "hot"); maxSeen != 1 {
+		t.Errorf("expected at most 1 parallel reconcile of the onboarding cluster, got %d", maxSeen)
+	}
+}
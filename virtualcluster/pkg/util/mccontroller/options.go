@@ -21,6 +21,8 @@ import (
 
 	"k8s.io/client-go/util/workqueue"
 
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/apis/config"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/ratelimiter"
 	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
 )
 
@@ -37,6 +39,15 @@ func WithOptions(o *Options) OptConfig {
 		WithWorkQueue(o.Queue)(options)
 		WithJitterPeriod(o.JitterPeriod)(options)
 		WithMaxConcurrentReconciles(o.MaxConcurrentReconciles)(options)
+		WithObjectCountQuotaPausePeriod(o.ObjectCountQuotaPausePeriod)(options)
+		WithMaxConcurrentReconcilesPerCluster(o.MaxConcurrentReconcilesPerCluster)(options)
+		WithClusterWorkerLimiter(o.ClusterWorkerLimiter)(options)
+		WithObjectCountRecountInterval(o.ObjectCountRecountInterval)(options)
+		WithOnboarding(o.OnboardingMaxConcurrentReconciles, o.OnboardingRampUpPeriod)(options)
+		WithMaxRetries(o.MaxRetries)(options)
+		WithRateLimiter(o.RateLimiter)(options)
+		WithDeadLetter(o.DeadLetterRetryThreshold, o.DeadLetterRetryPeriod)(options)
+		WithPrioritizedUpdates(o.PrioritizeUpdate)(options)
 	}
 }
 
@@ -84,3 +95,118 @@ func WithMaxConcurrentReconciles(n int) OptConfig {
 		}
 	}
 }
+
+// WithObjectCountQuotaPausePeriod set ObjectCountQuotaPausePeriod if valid.
+func WithObjectCountQuotaPausePeriod(t time.Duration) OptConfig {
+	return func(options *Options) {
+		if t > 0 {
+			options.ObjectCountQuotaPausePeriod = t
+		}
+	}
+}
+
+// WithMaxConcurrentReconcilesPerCluster set MaxConcurrentReconcilesPerCluster if valid.
+func WithMaxConcurrentReconcilesPerCluster(n int) OptConfig {
+	return func(options *Options) {
+		if n > 0 {
+			options.MaxConcurrentReconcilesPerCluster = n
+		}
+	}
+}
+
+// WithClusterWorkerLimiter set ClusterWorkerLimiter if not nil.
+func WithClusterWorkerLimiter(l *ClusterWorkerLimiter) OptConfig {
+	return func(options *Options) {
+		if l != nil {
+			options.ClusterWorkerLimiter = l
+		}
+	}
+}
+
+// WithObjectCountRecountInterval set ObjectCountRecountInterval if valid.
+func WithObjectCountRecountInterval(t time.Duration) OptConfig {
+	return func(options *Options) {
+		if t > 0 {
+			options.ObjectCountRecountInterval = t
+		}
+	}
+}
+
+// WithOnboarding set OnboardingMaxConcurrentReconciles and OnboardingRampUpPeriod if valid.
+func WithOnboarding(n int, rampUpPeriod time.Duration) OptConfig {
+	return func(options *Options) {
+		if n > 0 {
+			options.OnboardingMaxConcurrentReconciles = n
+		}
+		if rampUpPeriod > 0 {
+			options.OnboardingRampUpPeriod = rampUpPeriod
+		}
+	}
+}
+
+// WithMaxRetries set MaxRetries if valid.
+func WithMaxRetries(n int) OptConfig {
+	return func(options *Options) {
+		if n > 0 {
+			options.MaxRetries = n
+		}
+	}
+}
+
+// WithRateLimiter set the RateLimiter of the default queue if not nil.
+func WithRateLimiter(l workqueue.RateLimiter) OptConfig {
+	return func(options *Options) {
+		if l != nil {
+			options.RateLimiter = l
+		}
+	}
+}
+
+// WithRetries set MaxRetries, and the RateLimiter to an exponential back-off from baseDelay to
+// maxDelay, if valid.
+func WithRetries(maxRetries int, baseDelay, maxDelay time.Duration) OptConfig {
+	return func(options *Options) {
+		WithMaxRetries(maxRetries)(options)
+		if baseDelay > 0 && maxDelay >= baseDelay {
+			options.RateLimiter = ratelimiter.NewRetryRateLimiter(baseDelay, maxDelay)
+		}
+	}
+}
+
+// WithDeadLetter set DeadLetterRetryThreshold and DeadLetterRetryPeriod if valid.
+func WithDeadLetter(threshold int, retryPeriod time.Duration) OptConfig {
+	return func(options *Options) {
+		if threshold > 0 {
+			options.DeadLetterRetryThreshold = threshold
+		}
+		if retryPeriod > 0 {
+			options.DeadLetterRetryPeriod = retryPeriod
+		}
+	}
+}
+
+// WithPrioritizedUpdates set PrioritizeUpdate.
+func WithPrioritizedUpdates(prioritize func(oldObj, newObj interface{}) bool) OptConfig {
+	return func(options *Options) {
+		if prioritize != nil {
+			options.PrioritizeUpdate = prioritize
+		}
+	}
+}
+
+// WithSyncerConfig set the object count quota pause period, the per cluster and onboarding worker
+// limits, the object count recount interval, the retries and the dead letters of the syncer
+// configuration.
+func WithSyncerConfig(c *config.SyncerConfiguration) OptConfig {
+	return func(options *Options) {
+		if c == nil {
+			return
+		}
+		WithObjectCountQuotaPausePeriod(c.ObjectCountQuotaPausePeriod.Duration)(options)
+		WithMaxConcurrentReconcilesPerCluster(c.DWSMaxConcurrentReconcilesPerCluster)(options)
+		WithOnboarding(c.DWSOnboardingMaxConcurrentReconciles, c.DWSOnboardingRampUpPeriod.Duration)(options)
+		WithObjectCountRecountInterval(c.ObjectCountRecountInterval.Duration)(options)
+		WithRetries(c.SyncMaxRetries, c.SyncBaseDelay.Duration, c.SyncMaxDelay.Duration)(options)
+		WithDeadLetter(c.DWSDeadLetterRetryThreshold, c.DWSDeadLetterRetryPeriod.Duration)(options)
+	}
+}
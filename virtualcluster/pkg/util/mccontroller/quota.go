@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// legacyObjectCountResources are the object count quota resources that predate the count/<resource> syntax.
+var legacyObjectCountResources = sets.NewString(
+	string(corev1.ResourcePods),
+	string(corev1.ResourceServices),
+	string(corev1.ResourceServicesLoadBalancers),
+	string(corev1.ResourceServicesNodePorts),
+	string(corev1.ResourceConfigMaps),
+	string(corev1.ResourceSecrets),
+	string(corev1.ResourcePersistentVolumeClaims),
+	string(corev1.ResourceReplicationControllers),
+	string(corev1.ResourceQuotas),
+)
+
+// IsObjectCountQuotaError returns true if err is a super cluster rejection because a ResourceQuota
+// limiting the number of objects, e.g. count/configmaps or pods, is exceeded. Rejections due to
+// compute resource quotas, e.g. requests.cpu, are not object count quota errors.
+func IsObjectCountQuotaError(err error) bool {
+	if !apierrors.IsForbidden(err) {
+		return false
+	}
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return false
+	}
+	// The quota admission message looks like
+	// "exceeded quota: <name>, requested: count/configmaps=1, used: count/configmaps=10, limited: count/configmaps=10"
+	msg := status.Status().Message
+	if !strings.Contains(msg, "exceeded quota") {
+		return false
+	}
+	start := strings.Index(msg, "requested: ")
+	if start < 0 {
+		return false
+	}
+	requested := msg[start+len("requested: "):]
+	if end := strings.Index(requested, ", used: "); end >= 0 {
+		requested = requested[:end]
+	}
+	for _, item := range strings.Split(requested, ",") {
+		resource := strings.TrimSpace(strings.SplitN(item, "=", 2)[0])
+		if strings.HasPrefix(resource, "count/") || legacyObjectCountResources.Has(resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleObjectCountQuotaError tells the tenant that the object cannot be synced because of the super
+// cluster object count quota and, if ObjectCountQuotaPausePeriod is set, pauses the creation of this
+// resource type for the cluster and retries the request once the pause is over. It returns false if
+// there is no pause, the request is then retried like the other failures.
+func (c *MultiClusterController) handleObjectCountQuotaError(req reconciler.Request, err error) bool {
+	if eventErr := c.Eventf(req.ClusterName, &corev1.ObjectReference{
+		Kind:      c.objectKind,
+		Namespace: req.Namespace,
+		Name:      req.Name,
+		UID:       types.UID(req.UID),
+	}, corev1.EventTypeWarning, "ExceededObjectCountQuota", "The super cluster object count quota is exceeded: %v", err); eventErr != nil {
+		klog.Warningf("failed to send object count quota event for %s %s/%s of cluster %s: %v", c.objectKind, req.Namespace, req.Name, req.ClusterName, eventErr)
+	}
+
+	if c.ObjectCountQuotaPausePeriod <= 0 {
+		klog.Errorf("%s dws request is rejected by object count quota (will retry): %v", c.name, err)
+		return false
+	}
+
+	c.Lock()
+	if c.quotaPausedUntil == nil {
+		c.quotaPausedUntil = make(map[string]time.Time)
+	}
+	c.quotaPausedUntil[req.ClusterName] = time.Now().Add(c.ObjectCountQuotaPausePeriod)
+	c.Unlock()
+
+	klog.Warningf("%s creation is paused for cluster %s for %v due to object count quota: %v", c.objectKind, req.ClusterName, c.ObjectCountQuotaPausePeriod, err)
+	c.Queue.AddAfter(req, c.ObjectCountQuotaPausePeriod)
+	return true
+}
+
+// CreationPausedFor returns how long the creation of objects in the super cluster remains paused
+// for the cluster because of an object count quota rejection, or 0 if it is not paused.
+func (c *MultiClusterController) CreationPausedFor(clusterName string) time.Duration {
+	c.Lock()
+	defer c.Unlock()
+	until, ok := c.quotaPausedUntil[clusterName]
+	if !ok {
+		return 0
+	}
+	remaining := time.Until(until)
+	if remaining <= 0 {
+		delete(c.quotaPausedUntil, clusterName)
+		return 0
+	}
+	return remaining
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+func quotaError(resource, requested string) error {
+	return apierrors.NewForbidden(schema.GroupResource{Resource: resource}, "obj",
+		fmt.Errorf("exceeded quota: quota, requested: %s, used: %s, limited: %s", requested, requested, requested))
+}
+
+func TestIsObjectCountQuotaError(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{
+			name:     "nil",
+			err:      nil,
+			expected: false,
+		},
+		{
+			name:     "not forbidden",
+			err:      errors.New("exceeded quota: quota, requested: count/configmaps=1"),
+			expected: false,
+		},
+		{
+			name:     "forbidden without quota",
+			err:      apierrors.NewForbidden(schema.GroupResource{Resource: "configmaps"}, "obj", errors.New("denied by webhook")),
+			expected: false,
+		},
+		{
+			name:     "object count quota",
+			err:      quotaError("configmaps", "count/configmaps=1"),
+			expected: true,
+		},
+		{
+			name:     "legacy object count quota",
+			err:      quotaError("pods", "pods=1,requests.cpu=100m"),
+			expected: true,
+		},
+		{
+			name:     "compute resource quota",
+			err:      quotaError("pods", "limits.memory=1Gi,requests.cpu=100m"),
+			expected: false,
+		},
+		{
+			name:     "wrapped object count quota",
+			err:      fmt.Errorf("failed to create: %w", quotaError("secrets", "count/secrets=1")),
+			expected: true,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			if got := IsObjectCountQuotaError(tt.err); got != tt.expected {
+				tc.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+type fakeQuotaReconciler struct {
+	err error
+}
+
+func (r *fakeQuotaReconciler) Reconcile(reconciler.Request) (reconciler.Result, error) {
+	return reconciler.Result{}, r.err
+}
+
+type fakeCluster struct {
+	ClusterInterface
+	client clientset.Interface
+}
+
+func (f *fakeCluster) GetClientSet() (clientset.Interface, error) {
+	return f.client, nil
+}
+
+func (f *fakeCluster) GetDelegatingClient() (client.Client, error) {
+	return fakeClient.NewClientBuilder().Build(), nil
+}
+
+func TestObjectCountQuotaRejection(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		objName     string
+		pausePeriod time.Duration
+		expectPause bool
+		// expectRetry is whether the request is retried with the backoff of the queue.
+		expectRetry bool
+	}{
+		{
+			name:        "pause disabled",
+			objName:     "cm-1",
+			pausePeriod: 0,
+			expectPause: false,
+			expectRetry: true,
+		},
+		{
+			name:        "pause enabled",
+			objName:     "cm-2",
+			pausePeriod: time.Minute,
+			expectPause: true,
+			expectRetry: false,
+		},
+	} {
+		t.Run(tt.name, func(tc *testing.T) {
+			tenantClient := fake.NewSimpleClientset()
+			c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{},
+				&fakeQuotaReconciler{err: quotaError("configmaps", "count/configmaps=1")},
+				WithObjectCountQuotaPausePeriod(tt.pausePeriod))
+			if err != nil {
+				tc.Fatalf("unexpected error: %v", err)
+			}
+			c.clusters["tenant"] = &fakeCluster{client: tenantClient}
+
+			req := reconciler.Request{ClusterName: "tenant", NamespacedName: types.NamespacedName{Namespace: "default", Name: tt.objName}}
+			c.Queue.Add(req)
+			if !c.processNextWorkItem() {
+				tc.Fatalf("expected worker to continue")
+			}
+
+			events, err := tenantClient.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				tc.Fatalf("unexpected error: %v", err)
+			}
+			if len(events.Items) != 1 || events.Items[0].Reason != "ExceededObjectCountQuota" || events.Items[0].InvolvedObject.Kind != "ConfigMap" {
+				tc.Errorf("expected one ExceededObjectCountQuota event for the configmap, got %+v", events.Items)
+			}
+
+			if paused := c.CreationPausedFor("tenant") > 0; paused != tt.expectPause {
+				tc.Errorf("expected creation paused %v, got %v", tt.expectPause, paused)
+			}
+			if paused := c.CreationPausedFor("other") > 0; paused {
+				tc.Errorf("expected creation of other clusters not to be paused")
+			}
+			if retried := c.Queue.NumRequeues(req) > 0; retried != tt.expectRetry {
+				tc.Errorf("expected the request retried %v, got %v", tt.expectRetry, retried)
+			}
+		})
+	}
+}
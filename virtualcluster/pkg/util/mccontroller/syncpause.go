@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// IsSyncPaused returns true if the tenant object is annotated to pause its sync.
+func IsSyncPaused(obj metav1.Object) bool {
+	return obj.GetAnnotations()[constants.LabelSyncPause] == "true"
+}
+
+// syncPaused returns true if the tenant object of the request is paused, and keeps track of the
+// paused objects of the cluster. Requests of objects that cannot be read are not paused, so that
+// the reconciler handles deleted objects.
+func (c *MultiClusterController) syncPaused(req reconciler.Request) bool {
+	obj := c.objectType.DeepCopyObject().(client.Object)
+	err := c.Get(req.ClusterName, req.Namespace, req.Name, obj)
+	if err != nil && !apierrors.IsNotFound(err) {
+		klog.V(4).Infof("failed to get %s %s/%s of cluster %s to check sync pause: %v", c.objectKind, req.Namespace, req.Name, req.ClusterName, err)
+	}
+	paused := err == nil && IsSyncPaused(obj)
+
+	key := types.NamespacedName{Namespace: req.Namespace, Name: req.Name}
+	c.pausedObjectsLock.Lock()
+	defer c.pausedObjectsLock.Unlock()
+	objects := c.pausedObjects[req.ClusterName]
+	if _, tracked := objects[key]; tracked == paused {
+		return paused
+	}
+	if paused {
+		if objects == nil {
+			objects = make(map[types.NamespacedName]struct{})
+			c.pausedObjects[req.ClusterName] = objects
+		}
+		objects[key] = struct{}{}
+		klog.Infof("sync of %s %s/%s of cluster %s is paused", c.objectKind, req.Namespace, req.Name, req.ClusterName)
+	} else {
+		delete(objects, key)
+		klog.Infof("sync of %s %s/%s of cluster %s is resumed", c.objectKind, req.Namespace, req.Name, req.ClusterName)
+	}
+	metrics.RecordPausedObjects(c.objectKind, req.ClusterName, len(objects))
+	return paused
+}
+
+// forgetPausedObjects drops the paused objects of a removed cluster.
+func (c *MultiClusterController) forgetPausedObjects(clusterName string) {
+	c.pausedObjectsLock.Lock()
+	defer c.pausedObjectsLock.Unlock()
+	delete(c.pausedObjects, clusterName)
+	metrics.DeletePausedObjects(c.objectKind, clusterName)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakeClient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/constants"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/syncer/metrics"
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+type countingReconciler struct {
+	reconciled int
+}
+
+func (r *countingReconciler) Reconcile(reconciler.Request) (reconciler.Result, error) {
+	r.reconciled++
+	return reconciler.Result{}, nil
+}
+
+func TestSyncPause(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "default",
+		Name:        "cm",
+		Annotations: map[string]string{constants.LabelSyncPause: "true"},
+	}}
+	tenantClient := fakeClient.NewClientBuilder().WithObjects(cm).Build()
+	rc := &countingReconciler{}
+	c, err := NewMCController(&corev1.ConfigMap{}, &corev1.ConfigMapList{}, rc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.clusters["paused"] = &fakeDelegatingCluster{client: tenantClient}
+	req := reconciler.Request{ClusterName: "paused", NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}}
+	pausedObjects := metrics.PausedObjects.WithLabelValues("ConfigMap", "paused")
+
+	process := func(expectedReconciled int, expectedPaused float64) {
+		t.Helper()
+		c.Queue.Add(req)
+		if !c.processNextWorkItem() {
+			t.Fatalf("expected worker to continue")
+		}
+		if rc.reconciled != expectedReconciled {
+			t.Errorf("expected %d reconciles, got %d", expectedReconciled, rc.reconciled)
+		}
+		if got := testutil.ToFloat64(pausedObjects); got != expectedPaused {
+			t.Errorf("expected %v paused objects, got %v", expectedPaused, got)
+		}
+	}
+
+	// a paused object is not reconciled.
+	process(0, 1)
+	process(0, 1)
+
+	setPause := func(value string) {
+		t.Helper()
+		cm = &corev1.ConfigMap{}
+		if err := tenantClient.Get(context.TODO(), req.NamespacedName, cm); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cm.Annotations[constants.LabelSyncPause] = value
+		if err := tenantClient.Update(context.TODO(), cm); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// the object is reconciled again once the annotation is removed.
+	setPause("false")
+	process(1, 0)
+
+	// deleted objects are reconciled.
+	setPause("true")
+	process(1, 1)
+	if err := tenantClient.Delete(context.TODO(), cm); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	process(2, 0)
+}
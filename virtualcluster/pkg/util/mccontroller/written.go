@@ -0,0 +1,41 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mccontroller
+
+import (
+	"sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/util/reconciler"
+)
+
+// SuperClusterWritten records that the reconcile of the tenant object of the cluster wrote to the
+// super cluster. It must be called by the reconciler after each successful write. The sync latency
+// is only observed for the reconciles which wrote, the others found the super cluster object in sync.
+func (c *MultiClusterController) SuperClusterWritten(clusterName, namespace, name string) {
+	c.writtenLock.Lock()
+	defer c.writtenLock.Unlock()
+	c.written[syncedObjectKey{cluster: clusterName, namespace: namespace, name: name}] = struct{}{}
+}
+
+// takeWritten returns whether the reconcile of the request wrote to the super cluster, and forgets
+// it for the next reconcile.
+func (c *MultiClusterController) takeWritten(req reconciler.Request) bool {
+	key := syncedObjectKey{cluster: req.ClusterName, namespace: req.Namespace, name: req.Name}
+	c.writtenLock.Lock()
+	defer c.writtenLock.Unlock()
+	_, written := c.written[key]
+	delete(c.written, key)
+	return written
+}
@@ -21,6 +21,7 @@ import (
 
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
 
 	vcclient "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/clientset/versioned"
 	vcinformers "sigs.k8s.io/cluster-api-provider-nested/virtualcluster/pkg/client/informers/externalversions/tenancy/v1alpha1"
@@ -31,6 +32,7 @@ type InitContext struct {
 	Context    context.Context
 	Config     interface{}
 	Client     clientset.Interface
+	RestConfig *restclient.Config
 	Informer   informers.SharedInformerFactory
 	VCClient   vcclient.Interface
 	VCInformer vcinformers.VirtualClusterInformer
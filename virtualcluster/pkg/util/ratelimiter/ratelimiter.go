@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ratelimiter builds the rate limiters of the work queues of the syncing controllers.
+package ratelimiter
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	// overallQPS and overallBurst limit the retries of all the requests of a queue together, as
+	// workqueue.DefaultControllerRateLimiter does.
+	overallQPS   = 10
+	overallBurst = 100
+)
+
+// NewRetryRateLimiter returns the rate limiter of a work queue that retries a failing request after
+// baseDelay, doubles the delay with every retry up to maxDelay, and retries all the requests at no
+// more than 10 per second with a burst of 100. With a baseDelay of 5ms and a maxDelay of 1000s it is
+// workqueue.DefaultControllerRateLimiter.
+func NewRetryRateLimiter(baseDelay, maxDelay time.Duration) workqueue.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(overallQPS), overallBurst)},
+	)
+}
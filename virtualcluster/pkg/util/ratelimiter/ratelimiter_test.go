@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRetryRateLimiter(t *testing.T) {
+	limiter := NewRetryRateLimiter(10*time.Millisecond, 50*time.Millisecond)
+
+	expected := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+	for i, delay := range expected {
+		if got := limiter.When("one"); got != delay {
+			t.Errorf("retry %d: expected delay %v, got %v", i, delay, got)
+		}
+	}
+	if got := limiter.NumRequeues("one"); got != len(expected) {
+		t.Errorf("expected %d requeues, got %d", len(expected), got)
+	}
+
+	// the back-off of a request does not delay the others.
+	if got := limiter.When("two"); got != 10*time.Millisecond {
+		t.Errorf("expected delay %v for another request, got %v", 10*time.Millisecond, got)
+	}
+
+	limiter.Forget("one")
+	if got := limiter.NumRequeues("one"); got != 0 {
+		t.Errorf("expected no requeues after forget, got %d", got)
+	}
+	if got := limiter.When("one"); got != 10*time.Millisecond {
+		t.Errorf("expected delay %v after forget, got %v", 10*time.Millisecond, got)
+	}
+}
+
+func TestNewRetryRateLimiterOverallLimit(t *testing.T) {
+	limiter := NewRetryRateLimiter(time.Millisecond, time.Millisecond)
+
+	// the burst of retries is not delayed beyond the base delay.
+	for i := 0; i < overallBurst; i++ {
+		if got := limiter.When(i); got != time.Millisecond {
+			t.Fatalf("retry %d: expected delay %v within the burst, got %v", i, time.Millisecond, got)
+		}
+	}
+	// the retries beyond the burst are limited to overallQPS.
+	if got := limiter.When(overallBurst); got <= time.Millisecond {
+		t.Errorf("expected a retry beyond the burst to be delayed, got %v", got)
+	}
+}